@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditMaxLimit 单次查询审计记录的最大条数
+const auditMaxLimit = 200
+
+// handleAuditLog AI审计记录：默认返回最近的记录，提供 order_id / client_order_id 时返回产生该订单的记录
+func (s *Server) handleAuditLog(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	auditLogger := trader.GetAuditLogger()
+
+	orderID, _ := strconv.ParseInt(c.Query("order_id"), 10, 64)
+	if clientOrderID := c.Query("client_order_id"); orderID != 0 || clientOrderID != "" {
+		record, err := auditLogger.FindByOrder(orderID, clientOrderID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, record)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > auditMaxLimit {
+		limit = auditMaxLimit
+	}
+	records, err := auditLogger.Records(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取审计记录失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// handleAuditVerify 校验审计日志的哈希链是否完整
+func (s *Server) handleAuditVerify(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	count, err := trader.GetAuditLogger().Verify()
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"valid": false, "verified": count, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valid": true, "verified": count})
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"nofx/manager"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleBalanceReconcile 对账交易员账户某个交易日的余额变动（?date=YYYY-MM-DD，默认前一交易日）
+func (s *Server) handleBalanceReconcile(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+	if _, err := s.traderManager.GetTrader(traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	day, err := manager.ParseTradingDay(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, errs, err := s.traderManager.ReconcileBalances([]string{traderID}, day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(results) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errs[traderID]})
+		return
+	}
+	c.JSON(http.StatusOK, results[0])
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"nofx/manager"
+	"nofx/trader"
+
+	"github.com/gin-gonic/gin"
+)
+
+// closeAllRequest 批量平仓请求
+type closeAllRequest struct {
+	trader.CloseFilter
+	TraderIDs []string `json:"trader_ids"` // 要平仓的交易员（空=当前用户的全部交易员）
+}
+
+// loadedUserTraderIDs 当前用户已加载到内存的交易员ID（requested非空时只保留其中的交易员）
+func (s *Server) loadedUserTraderIDs(userID string, requested []string) ([]string, error) {
+	if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+		log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+	}
+	userTraders, err := s.database.GetTraders(userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易员列表失败: %w", err)
+	}
+	selected := make(map[string]bool, len(requested))
+	for _, id := range requested {
+		selected[id] = true
+	}
+	traderIDs := make([]string, 0, len(userTraders))
+	for _, record := range userTraders {
+		if len(selected) > 0 && !selected[record.ID] {
+			continue
+		}
+		if _, err := s.traderManager.GetTrader(record.ID); err == nil {
+			traderIDs = append(traderIDs, record.ID)
+		}
+	}
+	return traderIDs, nil
+}
+
+// handleCloseAll 并发平掉当前用户交易员的全部持仓或按币种/方向过滤的持仓，并清理残留的止盈止损单
+func (s *Server) handleCloseAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req closeAllRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	traderIDs, err := s.loadedUserTraderIDs(userID, req.TraderIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(traderIDs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "没有可平仓的交易员"})
+		return
+	}
+
+	// 名义价值合计达到两人规则阈值时，需另一位运维人员确认后才执行
+	report, pending, err := s.traderManager.SubmitCloseAll(traderIDs, req.CloseFilter, "HTTP", "HTTP:"+c.GetString("email"), manager.OperatorIdentity(c.GetString("email")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if pending {
+		c.JSON(http.StatusAccepted, gin.H{"pending": true, "message": "平仓名义价值超过两人规则阈值，已通知其他运维人员确认"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"nofx/manager"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleExecutionReport 当前用户各交易员的开仓执行质量（成交率、耗时、滑点、maker占比，按交易所比较执行算法）
+// period: 24h / 7d / 30d / all（默认7d）
+func (s *Server) handleExecutionReport(c *gin.Context) {
+	userID := c.GetString("user_id")
+	since, err := parseReportPeriod(c.DefaultQuery("period", "7d"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	traders, err := s.database.GetTraders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取交易员列表失败: %v", err)})
+		return
+	}
+	if len(traders) == 0 {
+		c.JSON(http.StatusOK, manager.BuildExecutionReport(since, nil))
+		return
+	}
+	traderIDs := make([]string, 0, len(traders))
+	for _, t := range traders {
+		traderIDs = append(traderIDs, t.ID)
+	}
+	report, err := manager.GetExecutionReport(s.database, since, traderIDs...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleExposure 标准化敞口快照（各交易员及按交易对汇总的名义价值、杠杆、强平距离、VaR），供外部风控系统监控多个实例
+// trader_id 为空时统计当前用户的全部交易员；每次请求实时查询交易所持仓
+func (s *Server) handleExposure(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+		log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+	}
+	traders, err := s.database.GetTraders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取交易员列表失败: %v", err)})
+		return
+	}
+
+	requested := c.Query("trader_id")
+	traderIDs := make([]string, 0, len(traders))
+	for _, t := range traders {
+		if requested == "" || t.ID == requested {
+			traderIDs = append(traderIDs, t.ID)
+		}
+	}
+	if requested != "" && len(traderIDs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "交易员不存在"})
+		return
+	}
+	if len(traderIDs) == 0 {
+		c.JSON(http.StatusOK, s.traderManager.GetExposure("")) // 没有交易员时返回空快照
+		return
+	}
+	c.JSON(http.StatusOK, s.traderManager.GetExposure(traderIDs...))
+}
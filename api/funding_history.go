@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"nofx/manager"
+	"nofx/market"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleFundingHistory 交易对已记录的资金费率历史及统计（平均费率、年化、空头累计收取）
+// period: 24h / 7d / 30d / all（默认7d）
+func (s *Server) handleFundingHistory(c *gin.Context) {
+	symbol := strings.TrimSpace(c.Query("symbol"))
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少symbol参数"})
+		return
+	}
+	since, err := parseReportPeriod(c.DefaultQuery("period", "7d"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, rates, err := manager.GetFundingStats(s.database, market.Normalize(symbol), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats, "rates": rates})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"nofx/jobs"
+	"nofx/manager"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// submitJobRequest 提交后台任务请求
+type submitJobRequest struct {
+	Type     string `json:"type" binding:"required"` // spec_refresh / funding_history / risk_report / execution_report
+	Symbol   string `json:"symbol"`                  // funding_history
+	TraderID string `json:"trader_id"`               // risk_report
+	Period   string `json:"period"`                  // execution_report: 24h / 7d / 30d / all（默认7d）
+}
+
+// handleSubmitJob 提交后台任务（交易规则刷新、资金费率历史下载、报告生成），立即返回任务ID，通过 GET /api/jobs/:id 查询结果
+func (s *Server) handleSubmitJob(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req submitJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	jobReq := manager.JobRequest{Type: req.Type, Owner: userID, Symbol: req.Symbol, TraderID: req.TraderID, Params: map[string]string{}}
+	if req.Symbol != "" {
+		jobReq.Params["symbol"] = req.Symbol
+	}
+	switch req.Type {
+	case manager.JobRiskReport, manager.JobExecutionReport:
+		if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+			log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+		}
+		traders, err := s.database.GetTraders(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取交易员列表失败: %v", err)})
+			return
+		}
+		for _, t := range traders {
+			jobReq.TraderIDs = append(jobReq.TraderIDs, t.ID)
+		}
+	}
+	switch req.Type {
+	case manager.JobRiskReport:
+		owned := false
+		for _, id := range jobReq.TraderIDs {
+			owned = owned || id == req.TraderID
+		}
+		if !owned {
+			c.JSON(http.StatusNotFound, gin.H{"error": "交易员不存在"})
+			return
+		}
+		jobReq.Params["trader_id"] = req.TraderID
+	case manager.JobExecutionReport:
+		if req.Period == "" {
+			req.Period = "7d"
+		}
+		since, err := parseReportPeriod(req.Period)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		jobReq.Since = since
+		jobReq.Params["period"] = req.Period
+	}
+
+	job, err := s.traderManager.SubmitJob(s.database, jobReq)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, jobs.ErrQueueFull) {
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// handleListJobs 最近的后台任务（当前用户提交的任务及系统任务），type 过滤任务类型，limit 默认50
+func (s *Server) handleListJobs(c *gin.Context) {
+	q := s.traderManager.JobQueue()
+	if q == nil {
+		c.JSON(http.StatusOK, []jobs.Job{})
+		return
+	}
+	userID := c.GetString("user_id")
+	jobType := c.Query("type")
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	c.JSON(http.StatusOK, q.List(func(j jobs.Job) bool {
+		return (j.Owner == "" || j.Owner == userID) && (jobType == "" || j.Type == jobType)
+	}, limit))
+}
+
+// handleGetJob 任务状态及结果
+func (s *Server) handleGetJob(c *gin.Context) {
+	q := s.traderManager.JobQueue()
+	if q == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	job, ok := q.Get(c.Param("id"))
+	if !ok || (job.Owner != "" && job.Owner != c.GetString("user_id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
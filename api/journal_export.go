@@ -0,0 +1,56 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"nofx/export"
+	"nofx/manager"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleJournalExport 导出已平仓交易，供导入Tradervue/Edgewonk等第三方交易日志
+// format: tradervue / edgewonk / json（默认tradervue），period: 24h / 7d / 30d / all（默认30d）
+func (s *Server) handleJournalExport(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := export.ParseJournalFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	since, err := parseReportPeriod(c.DefaultQuery("period", "30d"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	trades, err := manager.ClosedTrades(s.database, trader, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteJournal(&buf, format, trades); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("生成导出文件失败: %v", err)})
+		return
+	}
+	if format == export.JournalJSON {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", buf.Bytes())
+		return
+	}
+	filename := fmt.Sprintf("nofx_%s_%s_%s.csv", traderID, format, time.Now().UTC().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
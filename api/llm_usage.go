@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// llmUsageMaxDays 单次查询AI用量的最大天数
+const llmUsageMaxDays = 90
+
+// handleLLMUsage AI调用token用量、估算成本及每日预算状态（days: 返回最近N天，默认7）
+func (s *Server) handleLLMUsage(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if days <= 0 || days > llmUsageMaxDays {
+		days = llmUsageMaxDays
+	}
+	c.JSON(http.StatusOK, trader.GetLLMUsage(days))
+}
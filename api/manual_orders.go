@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"nofx/auth"
+	"nofx/manager"
+
+	"github.com/gin-gonic/gin"
+)
+
+// confirmManualOrderRequest 两人规则确认请求
+type confirmManualOrderRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ownedTraders 当前用户有权操作的交易员
+func (s *Server) ownedTraders(userID string) (func(traderID string) bool, error) {
+	traders, err := s.database.GetTraders(userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易员列表失败: %w", err)
+	}
+	owned := make(map[string]bool, len(traders))
+	for _, t := range traders {
+		owned[t.ID] = true
+	}
+	return func(traderID string) bool { return owned[traderID] }, nil
+}
+
+// handlePendingManualOrders 当前用户可以确认的待确认人工订单及确认码（发起人本人看不到自己的订单，需trade角色）
+func (s *Server) handlePendingManualOrders(c *gin.Context) {
+	if !auth.Role(c.GetString("role")).Allows(auth.RoleTrade) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足: 该接口需要 trade 角色"})
+		return
+	}
+	userID := c.GetString("user_id")
+	allowed, err := s.ownedTraders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pending": s.traderManager.PendingManualOrders(manager.OperatorIdentity(c.GetString("email")), allowed)})
+}
+
+// handleConfirmManualOrder 凭确认码确认其他运维人员发起的大额人工订单（两人规则，按登录邮箱或API Token名称区分运维人员，
+// 只能确认所属用户有权操作的交易员）
+func (s *Server) handleConfirmManualOrder(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req confirmManualOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allowed, err := s.ownedTraders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	order, result, err := s.traderManager.ConfirmManualOrder(req.Token, "HTTP:"+c.GetString("email"), manager.OperatorIdentity(c.GetString("email")), allowed)
+	if err != nil {
+		if order == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"order": order, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order": order, "result": result})
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// panicRequest 紧急按钮请求
+type panicRequest struct {
+	TraderIDs []string `json:"trader_ids"` // 要处理的交易员（空=当前用户的全部交易员）
+	Actions   []string `json:"actions"`    // 执行的动作（空=系统配置 panic_actions）
+	Preview   bool     `json:"preview"`    // true=只返回将要执行的操作
+}
+
+// handlePanic 紧急按钮：暂停交易、撤销全部挂单、市价平掉全部持仓并呼叫值班人员（preview=true 时只预演）
+func (s *Server) handlePanic(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req panicRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	traderIDs, err := s.loadedUserTraderIDs(userID, req.TraderIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(traderIDs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "没有可处理的交易员"})
+		return
+	}
+
+	report, err := s.traderManager.Panic(traderIDs, req.Actions, req.Preview, "HTTP:"+c.GetString("email"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
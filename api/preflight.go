@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handlePreflight API Key预检结果（合约交易权限、提现权限、IP白名单；refresh=true 时重新检查）
+func (s *Server) handlePreflight(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, trader.Preflight(c.Query("refresh") == "true"))
+}
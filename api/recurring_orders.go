@@ -0,0 +1,148 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"nofx/config"
+	"nofx/market"
+	"nofx/recurring"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecurringOrderRequest 创建/更新定投计划请求
+type RecurringOrderRequest struct {
+	TraderID    string  `json:"trader_id" binding:"required"`
+	Symbol      string  `json:"symbol" binding:"required"`
+	Side        string  `json:"side"` // long / short，默认 long
+	NotionalUSD float64 `json:"notional_usd" binding:"required"`
+	Leverage    int     `json:"leverage"`                    // 默认1
+	Schedule    string  `json:"schedule" binding:"required"` // cron表达式，如 "0 9 * * MON"
+	Enabled     *bool   `json:"enabled"`                     // 默认启用
+}
+
+// recurringOrderView 定投计划（附带下一次执行时间）
+type recurringOrderView struct {
+	*config.RecurringOrder
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+}
+
+// handleGetRecurringOrders 定投计划列表
+func (s *Server) handleGetRecurringOrders(c *gin.Context) {
+	userID := c.GetString("user_id")
+	orders, err := s.database.GetRecurringOrders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取定投计划失败: %v", err)})
+		return
+	}
+
+	views := make([]recurringOrderView, 0, len(orders))
+	for _, order := range orders {
+		view := recurringOrderView{RecurringOrder: order}
+		if next, err := recurring.NextRun(order); err == nil && !next.IsZero() && order.Enabled {
+			view.NextRunAt = &next
+		}
+		views = append(views, view)
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// handleCreateRecurringOrder 创建定投计划
+func (s *Server) handleCreateRecurringOrder(c *gin.Context) {
+	userID := c.GetString("user_id")
+	order, err := s.bindRecurringOrder(c, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.database.CreateRecurringOrder(order); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("创建定投计划失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "定投计划已创建"})
+}
+
+// handleUpdateRecurringOrder 更新定投计划
+func (s *Server) handleUpdateRecurringOrder(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的定投计划ID"})
+		return
+	}
+	order, err := s.bindRecurringOrder(c, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order.ID = id
+
+	if err := s.database.UpdateRecurringOrder(order); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("更新定投计划失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "定投计划已更新"})
+}
+
+// handleDeleteRecurringOrder 删除定投计划
+func (s *Server) handleDeleteRecurringOrder(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的定投计划ID"})
+		return
+	}
+
+	if err := s.database.DeleteRecurringOrder(userID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("删除定投计划失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "定投计划已删除"})
+}
+
+// bindRecurringOrder 解析并校验定投计划请求
+func (s *Server) bindRecurringOrder(c *gin.Context, userID string) (*config.RecurringOrder, error) {
+	var req RecurringOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+
+	if _, _, _, err := s.database.GetTraderConfig(userID, req.TraderID); err != nil {
+		return nil, fmt.Errorf("交易员不存在: %s", req.TraderID)
+	}
+	side := strings.ToLower(req.Side)
+	if side == "" {
+		side = "long"
+	}
+	if side != "long" && side != "short" {
+		return nil, fmt.Errorf("无效的方向: %s（可选: long, short）", req.Side)
+	}
+	if req.NotionalUSD <= 0 {
+		return nil, fmt.Errorf("每次开仓金额必须大于0")
+	}
+	if req.Leverage <= 0 {
+		req.Leverage = 1
+	}
+	if _, err := recurring.ParseCron(req.Schedule); err != nil {
+		return nil, err
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &config.RecurringOrder{
+		UserID:      userID,
+		TraderID:    req.TraderID,
+		Symbol:      market.Normalize(req.Symbol),
+		Side:        side,
+		NotionalUSD: req.NotionalUSD,
+		Leverage:    req.Leverage,
+		Schedule:    strings.TrimSpace(req.Schedule),
+		Enabled:     enabled,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"nofx/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readRoutes read角色可用的接口（查询，不返回密钥等敏感配置，不下单也不修改状态）
+var readRoutes = map[string]bool{
+	"GET /api/traders":               true,
+	"GET /api/competition":           true,
+	"GET /api/leaderboard":           true,
+	"GET /api/benchmark":             true,
+	"GET /api/ticker":                true,
+	"GET /api/status":                true,
+	"GET /api/account":               true,
+	"GET /api/account/snapshot":      true,
+	"GET /api/account/reconcile":     true,
+	"GET /api/events":                true,
+	"GET /api/positions":             true,
+	"GET /api/positions/history":     true,
+	"GET /api/positions/export":      true,
+	"GET /api/positions/history/:id": true,
+	"GET /api/risk-report":           true,
+	"GET /api/exposure":              true,
+	"GET /api/shadow-book":           true,
+	"GET /api/preflight":             true,
+	"POST /api/simulate-order":       true,
+	"GET /api/execution-report":      true,
+	"GET /api/funding-history":       true,
+	"GET /api/jobs":                  true,
+	"GET /api/jobs/:id":              true,
+	"GET /api/recurring-orders":      true,
+	"GET /api/decisions":             true,
+	"GET /api/decisions/latest":      true,
+	"GET /api/audit":                 true,
+	"GET /api/audit/verify":          true,
+	"GET /api/llm-usage":             true,
+	"GET /api/statistics":            true,
+	"GET /api/equity-history":        true,
+	"GET /api/performance":           true,
+}
+
+// tradeRoutes trade角色可用的接口（下单、平仓、启停交易员等）
+var tradeRoutes = map[string]bool{
+	"POST /api/traders/:id/start":                      true,
+	"POST /api/traders/:id/stop":                       true,
+	"POST /api/account/transfer":                       true,
+	"POST /api/close-all":                              true,
+	"POST /api/target-position":                        true,
+	"POST /api/panic":                                  true,
+	"POST /api/positions/history/:id/notes":            true,
+	"DELETE /api/positions/history/:id/notes/:note_id": true,
+	"POST /api/route-order":                            true,
+	"GET /api/manual-orders/pending":                   true,
+	"POST /api/manual-orders/confirm":                  true,
+	"POST /api/jobs":                                   true,
+	"POST /api/recurring-orders":                       true,
+	"PUT /api/recurring-orders/:id":                    true,
+	"DELETE /api/recurring-orders/:id":                 true,
+}
+
+// requiredRole 接口所需的角色：按白名单查询为read、下单/启停等为trade，
+// 其他接口（修改配置、返回密钥等敏感配置，以及未加入白名单的新接口）需要admin
+func requiredRole(method, path string) auth.Role {
+	key := method + " " + path
+	switch {
+	case readRoutes[key]:
+		return auth.RoleRead
+	case tradeRoutes[key]:
+		return auth.RoleTrade
+	default:
+		return auth.RoleAdmin
+	}
+}
+
+// roleMiddleware 按接口校验调用方角色（需在authMiddleware之后）
+func (s *Server) roleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := auth.Role(c.GetString("role"))
+		required := requiredRole(c.Request.Method, c.FullPath())
+		if !role.Allows(required) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "权限不足: 该接口需要 " + string(required) + " 角色（当前 " + string(role) + "）"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"nofx/manager"
+	"nofx/router"
+	"nofx/trader"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeOrderRequest 智能路由请求
+type routeOrderRequest struct {
+	router.Order
+	TraderIDs []string `json:"trader_ids"` // 参与比价的交易员（空=当前用户的全部交易员）
+	Execute   bool     `json:"execute"`    // false=只返回路由方案
+}
+
+// handleRouteOrder 比较各交易所的最优挂单及手续费，将开仓订单路由（或拆分）到执行质量最优的交易所
+func (s *Server) handleRouteOrder(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req routeOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+		log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+	}
+	userTraders, err := s.database.GetTraders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	selected := make(map[string]bool, len(req.TraderIDs))
+	for _, id := range req.TraderIDs {
+		selected[id] = true
+	}
+	var venues []*trader.AutoTrader
+	for _, record := range userTraders {
+		if len(selected) > 0 && !selected[record.ID] {
+			continue
+		}
+		if at, err := s.traderManager.GetTrader(record.ID); err == nil {
+			venues = append(venues, at)
+		}
+	}
+
+	plan, err := router.BuildPlan(req.Order, venues)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Execute {
+		c.JSON(http.StatusOK, gin.H{"plan": plan})
+		return
+	}
+
+	// 名义价值达到两人规则阈值时，需另一位运维人员确认后才执行
+	var results []router.LegResult
+	traderIDs := make([]string, 0, len(plan.Legs))
+	for _, leg := range plan.Legs {
+		traderIDs = append(traderIDs, leg.TraderID)
+	}
+	_, pending, err := s.traderManager.SubmitManualOrder(&manager.ManualOrder{
+		TraderIDs:   traderIDs,
+		Symbol:      plan.Order.Symbol,
+		Action:      "open_" + plan.Order.Side,
+		NotionalUSD: plan.Order.NotionalUSD,
+		Source:      "HTTP",
+		Operator:    "HTTP:" + c.GetString("email"),
+		Identity:    manager.OperatorIdentity(c.GetString("email")),
+		Execute: func() (string, error) {
+			results = router.Execute(plan, venues)
+			return formatLegResults(results), nil
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pending {
+		c.JSON(http.StatusAccepted, gin.H{"plan": plan, "pending": true, "message": "订单名义价值超过两人规则阈值，已通知其他运维人员确认"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"plan": plan, "results": results})
+}
+
+// formatLegResults 路由执行结果摘要
+func formatLegResults(results []router.LegResult) string {
+	lines := make([]string, 0, len(results))
+	for _, r := range results {
+		if !r.Success {
+			lines = append(lines, fmt.Sprintf("❌ %s %.2f USDT: %s", r.Leg.TraderID, r.Leg.NotionalUSD, r.Error))
+		} else {
+			lines = append(lines, fmt.Sprintf("✅ %s %.2f USDT", r.Leg.TraderID, r.Leg.NotionalUSD))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -8,7 +8,11 @@ import (
 	"nofx/auth"
 	"nofx/config"
 	"nofx/decision"
+	"nofx/fx"
+	"nofx/i18n"
+	"nofx/logger"
 	"nofx/manager"
+	"nofx/trader"
 	"strconv"
 	"strings"
 	"time"
@@ -21,25 +25,45 @@ import (
 type Server struct {
 	router        *gin.Engine
 	traderManager *manager.TraderManager
-	database      *config.Database
+	database      config.Store
 	port          int
+	loginLimiter  *auth.LoginLimiter // 登录失败限流
+	tlsCertFile   string             // HTTPS证书（为空时使用HTTP）
+	tlsKeyFile    string
 }
 
 // NewServer 创建API服务器
-func NewServer(traderManager *manager.TraderManager, database *config.Database, port int) *Server {
+func NewServer(traderManager *manager.TraderManager, database config.Store, port int) *Server {
 	// 设置为Release模式（减少日志输出）
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.Default()
 
+	// 只信任配置的反向代理转发的客户端IP（默认不信任 X-Forwarded-For，避免伪造IP绕过登录限流）
+	proxiesStr, _ := database.GetSystemConfig("trusted_proxies")
+	var proxies []string
+	for _, p := range strings.Split(proxiesStr, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		log.Printf("⚠️  可信代理配置无效: %v，不信任任何代理", err)
+		router.SetTrustedProxies(nil)
+	}
+
 	// 启用CORS
 	router.Use(corsMiddleware())
 
+	// 错误信息按语言翻译
+	router.Use(localeMiddleware())
+
 	s := &Server{
 		router:        router,
 		traderManager: traderManager,
 		database:      database,
 		port:          port,
+		loginLimiter:  auth.NewLoginLimiter(loginMaxFailures, loginWindow, loginLockout),
 	}
 
 	// 设置路由
@@ -64,6 +88,43 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// localeWriter 将错误响应按请求语言翻译
+type localeWriter struct {
+	gin.ResponseWriter
+	locale string
+}
+
+func (w *localeWriter) Write(data []byte) (int, error) {
+	if w.Status() < http.StatusBadRequest {
+		return w.ResponseWriter.Write(data)
+	}
+	if _, err := w.ResponseWriter.WriteString(i18n.TranslateTo(w.locale, string(data))); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *localeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// localeMiddleware 语言中间件：?lang=en 或 Accept-Language 优先，否则使用系统配置的语言
+func localeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.NormalizeLocale(c.Query("lang"))
+		if locale == "" {
+			locale = i18n.NormalizeLocale(strings.Split(c.GetHeader("Accept-Language"), ",")[0])
+		}
+		if locale == "" {
+			locale = i18n.Locale()
+		}
+		if locale != i18n.LocaleZH {
+			c.Writer = &localeWriter{ResponseWriter: c.Writer, locale: locale}
+		}
+		c.Next()
+	}
+}
+
 // setupRoutes 设置路由
 func (s *Server) setupRoutes() {
 	// API路由组
@@ -76,6 +137,7 @@ func (s *Server) setupRoutes() {
 		api.POST("/register", s.handleRegister)
 		api.POST("/login", s.handleLogin)
 		api.POST("/verify-otp", s.handleVerifyOTP)
+		api.POST("/admin-login", s.handleAdminLogin)
 		api.POST("/complete-registration", s.handleCompleteRegistration)
 		
 		// 系统支持的模型和交易所（无需认证）
@@ -89,8 +151,8 @@ func (s *Server) setupRoutes() {
 		api.GET("/prompt-templates", s.handleGetPromptTemplates)
 		api.GET("/prompt-templates/:name", s.handleGetPromptTemplate)
 
-		// 需要认证的路由
-		protected := api.Group("/", s.authMiddleware())
+		// 需要认证的路由（read/trade角色可用的接口需登记在 roles.go 的白名单中，否则只有admin可访问）
+		protected := api.Group("/", s.authMiddleware(), s.roleMiddleware())
 		{
 			// AI交易员管理
 			protected.GET("/traders", s.handleTraderList)
@@ -117,13 +179,50 @@ func (s *Server) setupRoutes() {
 
 			// 竞赛总览
 			protected.GET("/competition", s.handleCompetition)
+			protected.GET("/leaderboard", s.handleLeaderboard)
+			protected.GET("/benchmark", s.handleBenchmark)
+			protected.GET("/ticker", s.handleTicker)
 			
 			// 指定trader的数据（使用query参数 ?trader_id=xxx）
 			protected.GET("/status", s.handleStatus)
 			protected.GET("/account", s.handleAccount)
+			protected.GET("/account/snapshot", s.handleAccountSnapshot)
+			protected.GET("/account/reconcile", s.handleBalanceReconcile)
+			protected.POST("/account/transfer", s.handleTransferMargin)
+			protected.GET("/events", s.handleEvents)
 			protected.GET("/positions", s.handlePositions)
+			protected.GET("/positions/history", s.handlePositionHistory)
+			protected.POST("/close-all", s.handleCloseAll)
+			protected.POST("/target-position", s.handleSetTargetPosition)
+			protected.POST("/panic", s.handlePanic)
+			protected.GET("/positions/export", s.handleJournalExport)
+			protected.GET("/positions/history/:id", s.handlePositionReplay)
+			protected.POST("/positions/history/:id/notes", s.handleAddTradeNote)
+			protected.DELETE("/positions/history/:id/notes/:note_id", s.handleDeleteTradeNote)
+			protected.GET("/risk-report", s.handleRiskReport)
+			protected.GET("/exposure", s.handleExposure)
+			protected.GET("/shadow-book", s.handleShadowBook)
+			protected.GET("/preflight", s.handlePreflight)
+			protected.POST("/simulate-order", s.handleSimulateOrder)
+			protected.POST("/route-order", s.handleRouteOrder)
+			protected.GET("/manual-orders/pending", s.handlePendingManualOrders)
+			protected.POST("/manual-orders/confirm", s.handleConfirmManualOrder)
+			protected.GET("/execution-report", s.handleExecutionReport)
+			protected.GET("/funding-history", s.handleFundingHistory)
+			protected.POST("/jobs", s.handleSubmitJob)
+			protected.GET("/jobs", s.handleListJobs)
+			protected.GET("/jobs/:id", s.handleGetJob)
+
+			// 定投计划
+			protected.GET("/recurring-orders", s.handleGetRecurringOrders)
+			protected.POST("/recurring-orders", s.handleCreateRecurringOrder)
+			protected.PUT("/recurring-orders/:id", s.handleUpdateRecurringOrder)
+			protected.DELETE("/recurring-orders/:id", s.handleDeleteRecurringOrder)
 			protected.GET("/decisions", s.handleDecisions)
 			protected.GET("/decisions/latest", s.handleLatestDecisions)
+			protected.GET("/audit", s.handleAuditLog)
+			protected.GET("/audit/verify", s.handleAuditVerify)
+			protected.GET("/llm-usage", s.handleLLMUsage)
 			protected.GET("/statistics", s.handleStatistics)
 			protected.GET("/equity-history", s.handleEquityHistory)
 			protected.GET("/performance", s.handlePerformance)
@@ -168,9 +267,11 @@ func (s *Server) handleGetSystemConfig(c *gin.Context) {
 	
 	c.JSON(http.StatusOK, gin.H{
 		"admin_mode": auth.IsAdminMode(),
+		"admin_login_required": auth.IsAdminMode() && auth.AdminPasswordRequired(),
 		"default_coins": defaultCoins,
 		"btc_eth_leverage": btcEthLeverage,
 		"altcoin_leverage": altcoinLeverage,
+		"quote_currency": fx.QuoteCurrency(),
 	})
 }
 
@@ -204,21 +305,64 @@ func (s *Server) getTraderFromQuery(c *gin.Context) (*manager.TraderManager, str
 	return s.traderManager, traderID, nil
 }
 
+// ownedTraderFromQuery 从query参数获取当前用户拥有的交易员ID（写操作及敏感数据接口使用），失败时写入错误响应并返回false
+func (s *Server) ownedTraderFromQuery(c *gin.Context) (string, bool) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	owned, err := s.ownedTraders(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if !owned(traderID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("无权操作交易员 %s", traderID)})
+		return "", false
+	}
+	return traderID, true
+}
+
 // AI交易员管理相关结构体
 type CreateTraderRequest struct {
-	Name                 string  `json:"name" binding:"required"`
-	AIModelID            string  `json:"ai_model_id" binding:"required"`
-	ExchangeID           string  `json:"exchange_id" binding:"required"`
-	InitialBalance       float64 `json:"initial_balance"`
-	BTCETHLeverage       int     `json:"btc_eth_leverage"`
-	AltcoinLeverage      int     `json:"altcoin_leverage"`
-	TradingSymbols       string  `json:"trading_symbols"`
-	CustomPrompt         string  `json:"custom_prompt"`
-	OverrideBasePrompt   bool    `json:"override_base_prompt"`
-	SystemPromptTemplate string  `json:"system_prompt_template"` // 系统提示词模板名称
-	IsCrossMargin        *bool   `json:"is_cross_margin"`        // 指针类型，nil表示使用默认值true
-	UseCoinPool          bool    `json:"use_coin_pool"`
-	UseOITop             bool    `json:"use_oi_top"`
+	Name                      string  `json:"name" binding:"required"`
+	AIModelID                 string  `json:"ai_model_id" binding:"required"`
+	ExchangeID                string  `json:"exchange_id" binding:"required"`
+	InitialBalance            float64 `json:"initial_balance"`
+	BTCETHLeverage            int     `json:"btc_eth_leverage"`
+	AltcoinLeverage           int     `json:"altcoin_leverage"`
+	TradingSymbols            string  `json:"trading_symbols"`
+	CustomPrompt              string  `json:"custom_prompt"`
+	OverrideBasePrompt        bool    `json:"override_base_prompt"`
+	SystemPromptTemplate      string  `json:"system_prompt_template"`         // 系统提示词模板名称
+	IsCrossMargin             *bool   `json:"is_cross_margin"`                // 指针类型，nil表示使用默认值true
+	SymbolMarginModes         string  `json:"symbol_margin_modes"`            // 按币种仓位模式，如 BTCUSDT:cross,ETHUSDT:isolated
+	DryRun                    bool    `json:"dry_run"`                        // 预演模式：只记录决策不下单
+	WatchOnly                 bool    `json:"watch_only"`                     // 观察模式：只读API Key，只跟踪余额/持仓/盈亏
+	AllocationPct             float64 `json:"allocation_pct"`                 // 多策略共用账户时最多占用的净值百分比（0=不限制）
+	NettingRule               string  `json:"netting_rule"`                   // 与其他策略反向持仓时的处理: reject（默认）/ allow
+	MaxTradesPerHour          int     `json:"max_trades_per_hour"`            // 每小时最多开仓次数（0=不限制）
+	MaxTradesPerDay           int     `json:"max_trades_per_day"`             // 每24小时最多开仓次数（0=不限制）
+	MaxEntriesPerSymbolPerDay int     `json:"max_entries_per_symbol_per_day"` // 单币种每24小时最多开仓次数（0=不限制）
+	StopLossCooldownMinutes   int     `json:"stop_loss_cooldown_minutes"`     // 止损后该币种禁止再次开仓的分钟数（0=关闭）
+	FundingAvoidMinutes       int     `json:"funding_avoid_minutes"`          // 资金费结算前后禁止开仓/平仓的分钟数（0=关闭）
+	FundingAdverseThreshold   float64 `json:"funding_adverse_threshold"`      // 需支付的资金费率超过该百分比时结算前平仓（0=关闭）
+	TrailingStopMode          string  `json:"trailing_stop_mode"`             // 跟踪止损算法: chandelier / swing（空=关闭）
+	TrailingInterval          string  `json:"trailing_interval"`              // 跟踪止损K线周期（空=1h）
+	TrailingLookback          int     `json:"trailing_lookback"`              // 跟踪止损回看K线数（0=默认）
+	TrailingATRMultiplier     float64 `json:"trailing_atr_multiplier"`        // 吊灯止损ATR倍数（0=默认3）
+	RequireApproval           bool    `json:"require_approval"`               // 决策需运维人员通过Telegram确认后才执行
+	ApprovalTimeoutSeconds    int     `json:"approval_timeout_seconds"`       // 等待确认的超时秒数（0=默认300）
+	SymbolWhitelist           string  `json:"symbol_whitelist"`               // 币种白名单（逗号分隔，空=不限制）
+	SymbolBlacklist           string  `json:"symbol_blacklist"`               // 币种黑名单（逗号分隔）
+	SymbolMaxNotional         string  `json:"symbol_max_notional"`            // 按币种最大持仓名义价值，如 BTCUSDT:5000,*:1000
+	EnsembleModelIDs          string  `json:"ensemble_model_ids"`             // 参与投票的附加AI模型ID（逗号分隔）
+	EnsembleMode              string  `json:"ensemble_mode"`                  // 多模型投票方式: majority / confidence
+	ExecutionPolicy           string  `json:"execution_policy"`               // 开仓执行算法，如 market、passive:wait=30s、twap:duration=30m,slices=6（空=市价单）
+	OrderTagPrefix            string  `json:"order_tag_prefix"`               // 订单clientOrderId/text前缀（1-4位小写字母或数字，空=nx）
+	UseCoinPool               bool    `json:"use_coin_pool"`
+	UseOITop                  bool    `json:"use_oi_top"`
 }
 
 type ModelConfig struct {
@@ -293,6 +437,57 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 		}
 	}
 
+	// 校验按币种仓位模式配置
+	if _, err := trader.ParseSymbolMarginModes(req.SymbolMarginModes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 校验资金分配及净额规则
+	if req.AllocationPct < 0 || req.AllocationPct > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "资金分配比例必须在0-100之间"})
+		return
+	}
+	nettingRule, err := trader.NormalizeNettingRule(req.NettingRule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 校验交易频率限制
+	if req.MaxTradesPerHour < 0 || req.MaxTradesPerDay < 0 || req.MaxEntriesPerSymbolPerDay < 0 || req.StopLossCooldownMinutes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "交易频率限制不能为负数"})
+		return
+	}
+	if req.FundingAvoidMinutes < 0 || req.FundingAdverseThreshold < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "资金费规避配置不能为负数"})
+		return
+	}
+	if err := trader.ValidateTrailingStop(req.TrailingStopMode, req.TrailingInterval, req.TrailingLookback, req.TrailingATRMultiplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ApprovalTimeoutSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "确认超时秒数不能为负数"})
+		return
+	}
+	if _, err := trader.ParseSymbolNotionalCaps(req.SymbolMaxNotional); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.validateEnsemble(userID, req.EnsembleModelIDs, req.EnsembleMode); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := trader.ParseExecutionPolicy(req.ExecutionPolicy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := trader.ValidateOrderTagPrefix(req.OrderTagPrefix); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 生成交易员ID
 	traderID := fmt.Sprintf("%s_%s_%d", req.ExchangeID, req.AIModelID, time.Now().Unix())
 	
@@ -349,12 +544,36 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 		OverrideBasePrompt:   req.OverrideBasePrompt,
 		SystemPromptTemplate: systemPromptTemplate,
 		IsCrossMargin:        isCrossMargin,
+		SymbolMarginModes:    req.SymbolMarginModes,
+		DryRun:               req.DryRun,
+		WatchOnly:            req.WatchOnly,
+		AllocationPct:        req.AllocationPct,
+		NettingRule:          nettingRule,
+		MaxTradesPerHour:          req.MaxTradesPerHour,
+		MaxTradesPerDay:           req.MaxTradesPerDay,
+		MaxEntriesPerSymbolPerDay: req.MaxEntriesPerSymbolPerDay,
+		StopLossCooldownMinutes:   req.StopLossCooldownMinutes,
+		FundingAvoidMinutes:       req.FundingAvoidMinutes,
+		FundingAdverseThreshold:   req.FundingAdverseThreshold,
+		TrailingStopMode:          req.TrailingStopMode,
+		TrailingInterval:          req.TrailingInterval,
+		TrailingLookback:          req.TrailingLookback,
+		TrailingATRMultiplier:     req.TrailingATRMultiplier,
+		RequireApproval:           req.RequireApproval,
+		ApprovalTimeoutSeconds:    req.ApprovalTimeoutSeconds,
+		SymbolWhitelist:           req.SymbolWhitelist,
+		SymbolBlacklist:           req.SymbolBlacklist,
+		SymbolMaxNotional:         req.SymbolMaxNotional,
+		EnsembleModelIDs:          req.EnsembleModelIDs,
+		EnsembleMode:              req.EnsembleMode,
+		ExecutionPolicy:           req.ExecutionPolicy,
+		OrderTagPrefix:            req.OrderTagPrefix,
 		ScanIntervalMinutes:  3, // 默认3分钟
 		IsRunning:           false,
 	}
 
 	// 保存到数据库
-	err := s.database.CreateTrader(trader)
+	err = s.database.CreateTrader(trader)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("创建交易员失败: %v", err)})
 		return
@@ -379,16 +598,68 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 
 // UpdateTraderRequest 更新交易员请求
 type UpdateTraderRequest struct {
-	Name            string  `json:"name" binding:"required"`
-	AIModelID       string  `json:"ai_model_id" binding:"required"`
-	ExchangeID      string  `json:"exchange_id" binding:"required"`
-	InitialBalance  float64 `json:"initial_balance"`
-	BTCETHLeverage  int     `json:"btc_eth_leverage"`
-	AltcoinLeverage int     `json:"altcoin_leverage"`
-	TradingSymbols  string  `json:"trading_symbols"`
-	CustomPrompt    string  `json:"custom_prompt"`
-	OverrideBasePrompt bool `json:"override_base_prompt"`
-	IsCrossMargin   *bool   `json:"is_cross_margin"`
+	Name                      string   `json:"name" binding:"required"`
+	AIModelID                 string   `json:"ai_model_id" binding:"required"`
+	ExchangeID                string   `json:"exchange_id" binding:"required"`
+	InitialBalance            float64  `json:"initial_balance"`
+	BTCETHLeverage            int      `json:"btc_eth_leverage"`
+	AltcoinLeverage           int      `json:"altcoin_leverage"`
+	TradingSymbols            string   `json:"trading_symbols"`
+	CustomPrompt              string   `json:"custom_prompt"`
+	OverrideBasePrompt        bool     `json:"override_base_prompt"`
+	IsCrossMargin             *bool    `json:"is_cross_margin"`
+	SymbolMarginModes         *string  `json:"symbol_margin_modes"`            // nil表示保持原值
+	DryRun                    *bool    `json:"dry_run"`                        // nil表示保持原值
+	WatchOnly                 *bool    `json:"watch_only"`                     // nil表示保持原值
+	AllocationPct             *float64 `json:"allocation_pct"`                 // nil表示保持原值
+	NettingRule               *string  `json:"netting_rule"`                   // nil表示保持原值
+	MaxTradesPerHour          *int     `json:"max_trades_per_hour"`            // nil表示保持原值
+	MaxTradesPerDay           *int     `json:"max_trades_per_day"`             // nil表示保持原值
+	MaxEntriesPerSymbolPerDay *int     `json:"max_entries_per_symbol_per_day"` // nil表示保持原值
+	StopLossCooldownMinutes   *int     `json:"stop_loss_cooldown_minutes"`     // nil表示保持原值
+	FundingAvoidMinutes       *int     `json:"funding_avoid_minutes"`          // nil表示保持原值
+	FundingAdverseThreshold   *float64 `json:"funding_adverse_threshold"`      // nil表示保持原值
+	TrailingStopMode          *string  `json:"trailing_stop_mode"`             // nil表示保持原值，空字符串关闭
+	TrailingInterval          *string  `json:"trailing_interval"`              // nil表示保持原值
+	TrailingLookback          *int     `json:"trailing_lookback"`              // nil表示保持原值
+	TrailingATRMultiplier     *float64 `json:"trailing_atr_multiplier"`        // nil表示保持原值
+	RequireApproval           *bool    `json:"require_approval"`               // nil表示保持原值
+	ApprovalTimeoutSeconds    *int     `json:"approval_timeout_seconds"`       // nil表示保持原值
+	SymbolWhitelist           *string  `json:"symbol_whitelist"`               // nil表示保持原值
+	SymbolBlacklist           *string  `json:"symbol_blacklist"`               // nil表示保持原值
+	SymbolMaxNotional         *string  `json:"symbol_max_notional"`            // nil表示保持原值
+	EnsembleModelIDs          *string  `json:"ensemble_model_ids"`             // nil表示保持原值
+	EnsembleMode              *string  `json:"ensemble_mode"`                  // nil表示保持原值
+	ExecutionPolicy           *string  `json:"execution_policy"`               // nil表示保持原值，空字符串恢复市价单
+	OrderTagPrefix            *string  `json:"order_tag_prefix"`               // nil表示保持原值，空字符串恢复默认前缀
+}
+
+// validateEnsemble 校验多模型投票配置（模型须属于该用户）
+func (s *Server) validateEnsemble(userID, modelIDs, mode string) error {
+	if err := decision.ValidateEnsembleMode(mode); err != nil {
+		return err
+	}
+	if strings.TrimSpace(modelIDs) == "" {
+		return nil
+	}
+	models, err := s.database.GetAIModels(userID)
+	if err != nil {
+		return fmt.Errorf("获取AI模型配置失败: %w", err)
+	}
+	for _, id := range strings.Split(modelIDs, ",") {
+		id = strings.TrimSpace(id)
+		found := false
+		for _, m := range models {
+			if id == "" || m.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("投票模型 %s 不存在", id)
+		}
+	}
+	return nil
 }
 
 // handleUpdateTrader 更新交易员配置
@@ -428,6 +699,158 @@ func (s *Server) handleUpdateTrader(c *gin.Context) {
 		isCrossMargin = *req.IsCrossMargin
 	}
 	
+	symbolMarginModes := existingTrader.SymbolMarginModes // 保持原值
+	if req.SymbolMarginModes != nil {
+		if _, err := trader.ParseSymbolMarginModes(*req.SymbolMarginModes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		symbolMarginModes = *req.SymbolMarginModes
+	}
+
+	dryRun := existingTrader.DryRun // 保持原值
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+	watchOnly := existingTrader.WatchOnly // 保持原值
+	if req.WatchOnly != nil {
+		watchOnly = *req.WatchOnly
+	}
+
+	allocationPct := existingTrader.AllocationPct // 保持原值
+	if req.AllocationPct != nil {
+		if *req.AllocationPct < 0 || *req.AllocationPct > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "资金分配比例必须在0-100之间"})
+			return
+		}
+		allocationPct = *req.AllocationPct
+	}
+
+	nettingRule := existingTrader.NettingRule // 保持原值
+	if req.NettingRule != nil {
+		if nettingRule, err = trader.NormalizeNettingRule(*req.NettingRule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// 交易频率限制及止损冷却（nil保持原值）
+	maxTradesPerHour := existingTrader.MaxTradesPerHour
+	maxTradesPerDay := existingTrader.MaxTradesPerDay
+	maxEntriesPerSymbolPerDay := existingTrader.MaxEntriesPerSymbolPerDay
+	stopLossCooldownMinutes := existingTrader.StopLossCooldownMinutes
+	fundingAvoidMinutes := existingTrader.FundingAvoidMinutes
+	for _, limit := range []struct {
+		value  *int
+		target *int
+	}{
+		{req.MaxTradesPerHour, &maxTradesPerHour},
+		{req.MaxTradesPerDay, &maxTradesPerDay},
+		{req.MaxEntriesPerSymbolPerDay, &maxEntriesPerSymbolPerDay},
+		{req.StopLossCooldownMinutes, &stopLossCooldownMinutes},
+		{req.FundingAvoidMinutes, &fundingAvoidMinutes},
+	} {
+		if limit.value == nil {
+			continue
+		}
+		if *limit.value < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "交易频率限制不能为负数"})
+			return
+		}
+		*limit.target = *limit.value
+	}
+
+	fundingAdverseThreshold := existingTrader.FundingAdverseThreshold // 保持原值
+	if req.FundingAdverseThreshold != nil {
+		if *req.FundingAdverseThreshold < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "资金费规避配置不能为负数"})
+			return
+		}
+		fundingAdverseThreshold = *req.FundingAdverseThreshold
+	}
+
+	// 跟踪止损配置（未提供的项保持原值）
+	trailingStopMode, trailingInterval := existingTrader.TrailingStopMode, existingTrader.TrailingInterval
+	trailingLookback, trailingATRMultiplier := existingTrader.TrailingLookback, existingTrader.TrailingATRMultiplier
+	if req.TrailingStopMode != nil {
+		trailingStopMode = *req.TrailingStopMode
+	}
+	if req.TrailingInterval != nil {
+		trailingInterval = *req.TrailingInterval
+	}
+	if req.TrailingLookback != nil {
+		trailingLookback = *req.TrailingLookback
+	}
+	if req.TrailingATRMultiplier != nil {
+		trailingATRMultiplier = *req.TrailingATRMultiplier
+	}
+	if err := trader.ValidateTrailingStop(trailingStopMode, trailingInterval, trailingLookback, trailingATRMultiplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 人工确认配置（未提供的项保持原值）
+	requireApproval, approvalTimeoutSeconds := existingTrader.RequireApproval, existingTrader.ApprovalTimeoutSeconds
+	if req.RequireApproval != nil {
+		requireApproval = *req.RequireApproval
+	}
+	if req.ApprovalTimeoutSeconds != nil {
+		if *req.ApprovalTimeoutSeconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "确认超时秒数不能为负数"})
+			return
+		}
+		approvalTimeoutSeconds = *req.ApprovalTimeoutSeconds
+	}
+
+	// 币种白名单/黑名单及名义价值上限（未提供的项保持原值）
+	symbolWhitelist, symbolBlacklist, symbolMaxNotional := existingTrader.SymbolWhitelist, existingTrader.SymbolBlacklist, existingTrader.SymbolMaxNotional
+	if req.SymbolWhitelist != nil {
+		symbolWhitelist = *req.SymbolWhitelist
+	}
+	if req.SymbolBlacklist != nil {
+		symbolBlacklist = *req.SymbolBlacklist
+	}
+	if req.SymbolMaxNotional != nil {
+		if _, err := trader.ParseSymbolNotionalCaps(*req.SymbolMaxNotional); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		symbolMaxNotional = *req.SymbolMaxNotional
+	}
+
+	// 多模型投票（未提供的项保持原值）
+	ensembleModelIDs, ensembleMode := existingTrader.EnsembleModelIDs, existingTrader.EnsembleMode
+	if req.EnsembleModelIDs != nil {
+		ensembleModelIDs = *req.EnsembleModelIDs
+	}
+	if req.EnsembleMode != nil {
+		ensembleMode = *req.EnsembleMode
+	}
+	if err := s.validateEnsemble(userID, ensembleModelIDs, ensembleMode); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 开仓执行算法（未提供时保持原值）
+	executionPolicy := existingTrader.ExecutionPolicy
+	if req.ExecutionPolicy != nil {
+		if _, err := trader.ParseExecutionPolicy(*req.ExecutionPolicy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		executionPolicy = *req.ExecutionPolicy
+	}
+
+	// 订单前缀（未提供时保持原值，重启交易员后生效）
+	orderTagPrefix := existingTrader.OrderTagPrefix
+	if req.OrderTagPrefix != nil {
+		if err := trader.ValidateOrderTagPrefix(*req.OrderTagPrefix); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		orderTagPrefix = *req.OrderTagPrefix
+	}
+
 	// 设置杠杆默认值
 	btcEthLeverage := req.BTCETHLeverage
 	altcoinLeverage := req.AltcoinLeverage
@@ -452,6 +875,30 @@ func (s *Server) handleUpdateTrader(c *gin.Context) {
 		CustomPrompt:        req.CustomPrompt,
 		OverrideBasePrompt:  req.OverrideBasePrompt,
 		IsCrossMargin:       isCrossMargin,
+		SymbolMarginModes:   symbolMarginModes,
+		DryRun:              dryRun,
+		WatchOnly:           watchOnly,
+		AllocationPct:       allocationPct,
+		NettingRule:         nettingRule,
+		MaxTradesPerHour:          maxTradesPerHour,
+		MaxTradesPerDay:           maxTradesPerDay,
+		MaxEntriesPerSymbolPerDay: maxEntriesPerSymbolPerDay,
+		StopLossCooldownMinutes:   stopLossCooldownMinutes,
+		FundingAvoidMinutes:       fundingAvoidMinutes,
+		FundingAdverseThreshold:   fundingAdverseThreshold,
+		TrailingStopMode:          trailingStopMode,
+		TrailingInterval:          trailingInterval,
+		TrailingLookback:          trailingLookback,
+		TrailingATRMultiplier:     trailingATRMultiplier,
+		RequireApproval:           requireApproval,
+		ApprovalTimeoutSeconds:    approvalTimeoutSeconds,
+		SymbolWhitelist:           symbolWhitelist,
+		SymbolBlacklist:           symbolBlacklist,
+		SymbolMaxNotional:         symbolMaxNotional,
+		EnsembleModelIDs:          ensembleModelIDs,
+		EnsembleMode:              ensembleMode,
+		ExecutionPolicy:           executionPolicy,
+		OrderTagPrefix:            orderTagPrefix,
 		ScanIntervalMinutes: existingTrader.ScanIntervalMinutes, // 保持原值
 		IsRunning:           existingTrader.IsRunning,           // 保持原值
 	}
@@ -520,6 +967,12 @@ func (s *Server) handleStartTrader(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "交易员已在运行中"})
 		return
 	}
+
+	// API Key预检未通过时拒绝启动
+	if err := trader.CheckPreflight(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	
 	// 启动交易员
 	go func() {
@@ -644,7 +1097,8 @@ func (s *Server) handleUpdateModelConfigs(c *gin.Context) {
 		// 这里不返回错误，因为模型配置已经成功更新到数据库
 	}
 
-	log.Printf("✓ AI模型配置已更新: %+v", req.Models)
+	log.Printf("✓ AI模型配置已更新: %d 个", len(req.Models))
+	logger.Debugf("AI模型配置: %+v", req.Models)
 	c.JSON(http.StatusOK, gin.H{"message": "模型配置已更新"})
 }
 
@@ -688,7 +1142,8 @@ func (s *Server) handleUpdateExchangeConfigs(c *gin.Context) {
 		// 这里不返回错误，因为交易所配置已经成功更新到数据库
 	}
 
-	log.Printf("✓ 交易所配置已更新: %+v", req.Exchanges)
+	log.Printf("✓ 交易所配置已更新: %d 个", len(req.Exchanges))
+	logger.Debugf("交易所配置: %+v", req.Exchanges)
 	c.JSON(http.StatusOK, gin.H{"message": "交易所配置已更新"})
 }
 
@@ -770,6 +1225,9 @@ func (s *Server) handleTraderList(c *gin.Context) {
 			"exchange_id":     trader.ExchangeID,
 			"is_running":      isRunning,
 			"initial_balance": trader.InitialBalance,
+			"dry_run":         trader.DryRun,
+			"watch_only":      trader.WatchOnly,
+			"allocation_pct":  trader.AllocationPct,
 		})
 	}
 
@@ -862,6 +1320,12 @@ func (s *Server) handleAccount(c *gin.Context) {
 		return
 	}
 
+	quote, err := quoteFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	log.Printf("📊 收到账户信息请求 [%s]", trader.GetName())
 	account, err := trader.GetAccountInfo()
 	if err != nil {
@@ -878,9 +1342,122 @@ func (s *Server) handleAccount(c *gin.Context) {
 		account["available_balance"],
 		account["total_pnl"],
 		account["total_pnl_pct"])
+
+	// 金额字段折算为报告币种
+	for _, key := range accountAmountFields {
+		if value, ok := account[key].(float64); ok {
+			account[key] = quote.Convert(value)
+		}
+	}
+	account["currency"] = quote.Currency
+	account["fx_rate"] = quote.Rate
 	c.JSON(http.StatusOK, account)
 }
 
+// accountAmountFields 账户信息中以USDT计价的金额字段
+var accountAmountFields = []string{
+	"total_equity", "wallet_balance", "unrealized_profit", "available_balance",
+	"total_pnl", "total_unrealized_pnl", "initial_balance", "daily_pnl",
+	"margin_used", "strategy_unrealized_pnl",
+}
+
+// quoteFromQuery 解析?currency=报告币种（未指定时使用系统配置的报告币种，汇率不可用时退回USDT）
+func quoteFromQuery(c *gin.Context) (fx.Quote, error) {
+	currency := c.Query("currency")
+	if currency == "" {
+		return fx.Current(), nil
+	}
+	quote, err := fx.QuoteFor(currency)
+	if err != nil {
+		return fx.Quote{}, fmt.Errorf("获取 %s 汇率失败: %w", strings.ToUpper(currency), err)
+	}
+	return quote, nil
+}
+
+// handleAccountSnapshot 多币种账户快照（?currency=USDT 指定报告币种）
+func (s *Server) handleAccountSnapshot(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := trader.GetAccountSnapshot(c.DefaultQuery("currency", "USDT"))
+	if err != nil {
+		log.Printf("❌ 获取账户快照失败 [%s]: %v", trader.GetName(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("获取账户快照失败: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// TransferMarginRequest 钱包划转请求
+type TransferMarginRequest struct {
+	From     string  `json:"from" binding:"required"` // "spot" 或 "futures"
+	To       string  `json:"to" binding:"required"`
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount" binding:"required"`
+}
+
+// handleTransferMargin 现货与合约钱包之间划转
+func (s *Server) handleTransferMargin(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+
+	var req TransferMarginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Currency == "" {
+		req.Currency = "USDT"
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := trader.TransferMargin(req.From, req.To, req.Currency, req.Amount); err != nil {
+		log.Printf("❌ 钱包划转失败 [%s]: %v", trader.GetName(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("钱包划转失败: %v", err),
+		})
+		return
+	}
+
+	log.Printf("✓ 钱包划转成功 [%s]: %s → %s %.4f %s", trader.GetName(), req.From, req.To, req.Amount, req.Currency)
+	c.JSON(http.StatusOK, gin.H{"message": "划转成功"})
+}
+
+// handleEvents 最近的执行事件（下单、成交、撤单、止盈止损触发、错误）
+func (s *Server) handleEvents(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 100
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = val
+	}
+
+	c.JSON(http.StatusOK, s.traderManager.GetRecentEvents(traderID, limit))
+}
+
 // handlePositions 持仓列表
 func (s *Server) handlePositions(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -906,6 +1483,155 @@ func (s *Server) handlePositions(c *gin.Context) {
 	c.JSON(http.StatusOK, positions)
 }
 
+// positionReplayLookback 重建持仓历史时读取的决策记录数（3分钟周期约20天）
+const positionReplayLookback = 10000
+
+// handlePositionHistory 历史持仓列表（从决策日志重建，按开仓时间倒序，可按symbol过滤）
+func (s *Server) handlePositionHistory(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	lifecycles, err := trader.GetDecisionLogger().ReplayPositions(positionReplayLookback)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("重建持仓历史失败: %v", err),
+		})
+		return
+	}
+
+	symbol := strings.ToUpper(c.Query("symbol"))
+	result := make([]*logger.PositionLifecycle, 0, limit)
+	for _, lc := range lifecycles {
+		if symbol != "" && lc.Symbol != symbol {
+			continue
+		}
+		result = append(result, lc)
+		if len(result) >= limit {
+			break
+		}
+	}
+	s.attachTradeNotes(traderID, result...)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handlePositionReplay 单个历史持仓的完整生命周期（开仓成交、止盈止损调整、部分平仓、最终盈亏）
+func (s *Server) handlePositionReplay(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	lifecycle, err := trader.GetDecisionLogger().ReplayPosition(c.Param("id"), positionReplayLookback)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	s.attachTradeNotes(traderID, lifecycle)
+
+	c.JSON(http.StatusOK, lifecycle)
+}
+
+// handleRiskReport 风险报告（VaR及压力测试）
+func (s *Server) handleRiskReport(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := trader.GetRiskReport(c.Query("refresh") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("生成风险报告失败: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// handleShadowBook 影子账本快照（本地维护的持仓、挂单及版本号）
+func (s *Server) handleShadowBook(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	book := trader.GetShadowBook()
+	if book == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未启用影子账本（shadow_book_reconcile）"})
+		return
+	}
+	c.JSON(http.StatusOK, book)
+}
+
+// handleSimulateOrder 模拟假设订单（what-if）：返回保证金占用、强平价、手续费及风控检查结果，不下单
+func (s *Server) handleSimulateOrder(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+
+	var req trader.WhatIfOrder
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	at, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := at.SimulateOrder(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("模拟订单失败: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // handleDecisions 决策日志列表
 func (s *Server) handleDecisions(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -1009,6 +1735,89 @@ func (s *Server) handleCompetition(c *gin.Context) {
 	c.JSON(http.StatusOK, competition)
 }
 
+// handleLeaderboard 当前用户各交易员的表现排行榜（收益率、最大回撤、交易次数、手续费）
+// period: 24h / 7d / 30d / all（默认all）
+func (s *Server) handleLeaderboard(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	since, err := parseReportPeriod(c.DefaultQuery("period", "all"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quote, err := quoteFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+		log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+	}
+	traders, err := s.database.GetTraders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("获取交易员列表失败: %v", err)})
+		return
+	}
+	if len(traders) == 0 {
+		c.JSON(http.StatusOK, []*trader.PerformanceSummary{})
+		return
+	}
+	traderIDs := make([]string, 0, len(traders))
+	for _, t := range traders {
+		traderIDs = append(traderIDs, t.ID)
+	}
+
+	board := s.traderManager.GetLeaderboard(since, traderIDs...)
+	for i, summary := range board {
+		board[i] = summary.InQuote(quote)
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+// parseReportPeriod 解析统计区间参数，返回区间起点（all返回零值）
+func parseReportPeriod(period string) (time.Time, error) {
+	switch period {
+	case "all":
+		return time.Time{}, nil
+	case "24h", "1d":
+		return time.Now().Add(-24 * time.Hour), nil
+	case "7d":
+		return time.Now().AddDate(0, 0, -7), nil
+	case "30d":
+		return time.Now().AddDate(0, 0, -30), nil
+	default:
+		return time.Time{}, fmt.Errorf("无效的统计区间，可选: 24h, 7d, 30d, all")
+	}
+}
+
+// handleBenchmark 策略净值与买入持有（交易过的币种、BTC）的对比报告
+func (s *Server) handleBenchmark(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	since, err := parseReportPeriod(c.DefaultQuery("period", "all"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := trader.GetBenchmarkReport(since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("生成基准对比失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // handleEquityHistory 收益率历史数据
 func (s *Server) handleEquityHistory(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -1023,6 +1832,12 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		return
 	}
 
+	quote, err := quoteFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 获取尽可能多的历史数据（几天的数据）
 	// 每3分钟一个周期：10000条 = 约20天的数据
 	records, err := trader.GetDecisionLogger().GetLatestRecords(10000)
@@ -1033,7 +1848,7 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		return
 	}
 
-	// 构建收益率历史数据点
+	// 构建收益率历史数据点（金额按当前汇率折算为报告币种）
 	type EquityPoint struct {
 		Timestamp        string  `json:"timestamp"`
 		TotalEquity      float64 `json:"total_equity"`      // 账户净值（wallet + unrealized）
@@ -1082,9 +1897,9 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 
 		history = append(history, EquityPoint{
 			Timestamp:        record.Timestamp.Format("2006-01-02 15:04:05"),
-			TotalEquity:      totalEquity,
-			AvailableBalance: record.AccountState.AvailableBalance,
-			TotalPnL:         totalPnL,
+			TotalEquity:      quote.Convert(totalEquity),
+			AvailableBalance: quote.Convert(record.AccountState.AvailableBalance),
+			TotalPnL:         quote.Convert(totalPnL),
 			TotalPnLPct:      totalPnLPct,
 			PositionCount:    record.AccountState.PositionCount,
 			MarginUsedPct:    record.AccountState.MarginUsedPct,
@@ -1122,13 +1937,22 @@ func (s *Server) handlePerformance(c *gin.Context) {
 	c.JSON(http.StatusOK, performance)
 }
 
-// authMiddleware JWT认证中间件
+// authMiddleware JWT认证中间件（同时接受静态API Token，按Token角色限制权限）
 func (s *Server) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 如果是管理员模式，直接使用admin用户
-		if auth.IsAdminMode() {
+		if token, ok := auth.LookupAPIToken(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")); ok {
+			c.Set("user_id", token.UserID)
+			c.Set("email", "token:"+token.Name)
+			c.Set("role", string(token.Role))
+			c.Next()
+			return
+		}
+
+		// 如果是管理员模式且未设置管理员密码，直接使用admin用户
+		if auth.IsAdminMode() && !auth.AdminPasswordRequired() {
 			c.Set("user_id", "admin")
 			c.Set("email", "admin@localhost")
+			c.Set("role", string(auth.RoleAdmin))
 			c.Next()
 			return
 		}
@@ -1155,10 +1979,16 @@ func (s *Server) authMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if auth.IsAdminMode() && claims.UserID != "admin" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "管理员模式下请使用管理员密码登录"})
+			c.Abort()
+			return
+		}
 
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", string(auth.RoleAdmin))
 		c.Next()
 	}
 }
@@ -1287,16 +2117,21 @@ func (s *Server) handleLogin(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !s.checkLoginRate(c) {
+		return
+	}
 
 	// 获取用户信息
 	user, err := s.database.GetUserByEmail(req.Email)
 	if err != nil {
+		s.loginLimiter.RecordFailure(c.ClientIP())
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "邮箱或密码错误"})
 		return
 	}
 
 	// 验证密码
 	if !auth.CheckPassword(req.Password, user.PasswordHash) {
+		s.loginLimiter.RecordFailure(c.ClientIP())
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "邮箱或密码错误"})
 		return
 	}
@@ -1331,19 +2166,25 @@ func (s *Server) handleVerifyOTP(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !s.checkLoginRate(c) {
+		return
+	}
 
 	// 获取用户信息
 	user, err := s.database.GetUserByID(req.UserID)
 	if err != nil {
+		s.loginLimiter.RecordFailure(c.ClientIP())
 		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
 		return
 	}
 
 	// 验证OTP
 	if !auth.VerifyOTP(user.OTPSecret, req.OTPCode) {
+		s.loginLimiter.RecordFailure(c.ClientIP())
 		c.JSON(http.StatusBadRequest, gin.H{"error": "验证码错误"})
 		return
 	}
+	s.loginLimiter.Reset(c.ClientIP())
 
 	// 生成JWT token
 	token, err := auth.GenerateJWT(user.ID, user.Email)
@@ -1397,7 +2238,11 @@ func (s *Server) handleGetSupportedExchanges(c *gin.Context) {
 // Start 启动服务器
 func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.port)
-	log.Printf("🌐 API服务器启动在 http://localhost%s", addr)
+	scheme := "http"
+	if s.tlsCertFile != "" && s.tlsKeyFile != "" {
+		scheme = "https"
+	}
+	log.Printf("🌐 API服务器启动在 %s://localhost%s", scheme, addr)
 	log.Printf("📊 API文档:")
 	log.Printf("  • GET  /api/health           - 健康检查")
 	log.Printf("  • GET  /api/traders          - AI交易员列表")
@@ -1411,14 +2256,37 @@ func (s *Server) Start() error {
 	log.Printf("  • PUT  /api/exchanges        - 更新交易所配置")
 	log.Printf("  • GET  /api/status?trader_id=xxx     - 指定trader的系统状态")
 	log.Printf("  • GET  /api/account?trader_id=xxx    - 指定trader的账户信息")
+	log.Printf("  • GET  /api/account/snapshot?trader_id=xxx&currency=USDT - 指定trader的多币种余额")
+	log.Printf("  • POST /api/account/transfer?trader_id=xxx - 现货/合约钱包划转")
+	log.Printf("  • GET  /api/account/reconcile?trader_id=xxx&date=YYYY-MM-DD - 交易日余额变动与交易/资金费/手续费对账")
+	log.Printf("  • GET  /api/events?trader_id=xxx&limit=100 - 指定trader的执行事件")
 	log.Printf("  • GET  /api/positions?trader_id=xxx  - 指定trader的持仓列表")
+	log.Printf("  • POST /api/close-all          - 批量平仓（全部或按币种/方向过滤，可指定trader_ids）")
+	log.Printf("  • POST /api/target-position?trader_id=xxx - 调整交易对到目标净仓位（多为正、空为负、0=平仓）")
+	log.Printf("  • POST /api/panic              - 紧急按钮：暂停交易/撤销挂单/平掉全部持仓/呼叫值班（preview=true 预演）")
+	log.Printf("  • GET  /api/positions/export?trader_id=xxx&format=tradervue - 导出已平仓交易（tradervue/edgewonk CSV或json）")
+	log.Printf("  • GET  /api/risk-report?trader_id=xxx&refresh=true - 指定trader的VaR及压力测试报告")
+	log.Printf("  • GET  /api/exposure?trader_id=xxx   - 敞口快照（名义价值/杠杆/强平距离/VaR，trader_id为空时为全部交易员）")
+	log.Printf("  • GET  /api/shadow-book?trader_id=xxx - 影子账本（本地持仓/挂单及版本号）")
 	log.Printf("  • GET  /api/decisions?trader_id=xxx  - 指定trader的决策日志")
 	log.Printf("  • GET  /api/decisions/latest?trader_id=xxx - 指定trader的最新决策")
 	log.Printf("  • GET  /api/statistics?trader_id=xxx - 指定trader的统计信息")
 	log.Printf("  • GET  /api/equity-history?trader_id=xxx - 指定trader的收益率历史数据")
 	log.Printf("  • GET  /api/performance?trader_id=xxx - 指定trader的AI学习表现分析")
+	log.Printf("  • GET  /api/ticker?symbol=BTCUSDT - 合约24小时行情及资金费率")
+	log.Printf("  • POST /api/route-order      - 跨交易所智能路由开仓订单")
+	log.Printf("  • POST /api/manual-orders/confirm - 确认大额人工订单（两人规则）")
+	log.Printf("  • POST /api/admin-login      - 管理员模式使用管理员密码登录")
+	log.Printf("  • GET  /api/execution-report?period=7d - 开仓执行质量报告（成交率/滑点/maker占比）")
+	log.Printf("  • GET  /api/funding-history?symbol=BTCUSDT&period=7d - 资金费率历史及平均/年化统计")
+	log.Printf("  • POST /api/jobs                     - 提交后台任务（spec_refresh/funding_history/risk_report/execution_report）")
+	log.Printf("  • GET  /api/jobs/:id                 - 后台任务状态及结果")
+	log.Printf("  • GET  /api/preflight?trader_id=xxx - API Key权限及IP白名单预检")
 	log.Println()
 
+	if scheme == "https" {
+		return s.router.RunTLS(addr, s.tlsCertFile, s.tlsKeyFile)
+	}
 	return s.router.Run(addr)
 }
 
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"nofx/auth"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 登录限流：同一IP在15分钟内失败5次后锁定15分钟
+const (
+	loginMaxFailures = 5
+	loginWindow      = 15 * time.Minute
+	loginLockout     = 15 * time.Minute
+)
+
+// SetTLS 设置HTTPS证书及私钥文件（均非空时以HTTPS提供API及控制台）
+func (s *Server) SetTLS(certFile, keyFile string) {
+	s.tlsCertFile = certFile
+	s.tlsKeyFile = keyFile
+}
+
+// checkLoginRate 登录限流检查，被锁定时直接返回429
+func (s *Server) checkLoginRate(c *gin.Context) bool {
+	if remaining, ok := s.loginLimiter.Allow(c.ClientIP()); !ok {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("登录失败次数过多，请在 %s 后重试", remaining.Round(time.Second))})
+		return false
+	}
+	return true
+}
+
+// handleAdminLogin 管理员模式下使用管理员密码（PIN）登录，返回admin用户的JWT
+func (s *Server) handleAdminLogin(c *gin.Context) {
+	if !auth.IsAdminMode() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未启用管理员模式，请使用邮箱密码登录"})
+		return
+	}
+	if !s.checkLoginRate(c) {
+		return
+	}
+	var req struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !auth.AdminPasswordRequired() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未设置管理员密码，无需登录"})
+		return
+	}
+	if !auth.CheckAdminPassword(req.Password) {
+		s.loginLimiter.RecordFailure(c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "管理员密码错误"})
+		return
+	}
+	s.loginLimiter.Reset(c.ClientIP())
+
+	token, err := auth.GenerateJWT("admin", "admin@localhost")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成token失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":   token,
+		"user_id": "admin",
+		"email":   "admin@localhost",
+		"message": "登录成功",
+	})
+}
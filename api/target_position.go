@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"nofx/manager"
+	"nofx/trader"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// targetPositionRequest 目标仓位请求
+type targetPositionRequest struct {
+	Symbol string   `json:"symbol" binding:"required"`
+	Target *float64 `json:"target" binding:"required"` // 目标净仓位（多为正、空为负、0=全部平仓）
+}
+
+// handleSetTargetPosition 将交易对的净仓位调整到目标数量（名义价值达到两人规则阈值时需另一位运维人员确认）
+func (s *Server) handleSetTargetPosition(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+	var req targetPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	at, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	symbol := strings.ToUpper(req.Symbol)
+	notional, notionalErr := at.TargetNotional(symbol, *req.Target)
+	var result *trader.TargetPositionResult
+	_, pending, err := s.traderManager.SubmitManualOrder(&manager.ManualOrder{
+		TraderIDs:       []string{traderID},
+		Symbol:          symbol,
+		Action:          fmt.Sprintf("target %.6f", *req.Target),
+		NotionalUSD:     notional,
+		NotionalUnknown: notionalErr != nil,
+		Source:          "HTTP",
+		Operator:        "HTTP:" + c.GetString("email"),
+		Identity:        manager.OperatorIdentity(c.GetString("email")),
+		Execute: func() (string, error) {
+			var err error
+			if result, err = at.SetTargetPosition(symbol, *req.Target); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("✅ %s %s 已调整到目标仓位 %.6f（%d 笔订单）", traderID, symbol, *req.Target, len(result.Orders)), nil
+		},
+	})
+	if pending {
+		c.JSON(http.StatusAccepted, gin.H{"pending": true, "message": "名义价值超过两人规则阈值，已通知其他运维人员确认"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"result": result, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
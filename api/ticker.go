@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"nofx/market"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleTicker 单个合约的24小时涨跌幅、最高最低价、成交量及资金费率（symbol: 如 BTCUSDT）
+func (s *Server) handleTicker(c *gin.Context) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 symbol 参数"})
+		return
+	}
+	ticker, err := market.GetTicker(symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ticker)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nofx/config"
+	"nofx/logger"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 复盘笔记大小限制
+const (
+	maxTradeNoteLen       = 10000
+	maxTradeAttachmentLen = 64 << 10
+)
+
+// tradeNoteRequest 添加复盘笔记请求
+type tradeNoteRequest struct {
+	Note       string          `json:"note"`
+	ImageURL   string          `json:"image_url"`  // 截图链接（http/https）
+	Attachment json.RawMessage `json:"attachment"` // 自定义上下文（任意JSON，如指标截图数据、外部分析）
+}
+
+// handleAddTradeNote 为历史持仓添加复盘笔记
+func (s *Server) handleAddTradeNote(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+	var req tradeNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	attachment := strings.TrimSpace(string(req.Attachment))
+	if attachment == "null" {
+		attachment = ""
+	}
+	switch {
+	case req.Note == "" && req.ImageURL == "" && attachment == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "笔记内容、截图链接和附加上下文不能都为空"})
+		return
+	case len(req.Note) > maxTradeNoteLen:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("笔记不能超过 %d 字节", maxTradeNoteLen)})
+		return
+	case len(attachment) > maxTradeAttachmentLen:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("附加上下文不能超过 %d KB", maxTradeAttachmentLen>>10)})
+		return
+	}
+	if req.ImageURL != "" {
+		if u, err := url.Parse(req.ImageURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "截图链接必须是 http/https 地址"})
+			return
+		}
+	}
+
+	note := &config.TradeNote{
+		TraderID:   traderID,
+		PositionID: c.Param("id"),
+		Author:     c.GetString("email"),
+		Note:       req.Note,
+		ImageURL:   req.ImageURL,
+		Attachment: attachment,
+	}
+	if err := s.database.CreateTradeNote(note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("保存复盘笔记失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "复盘笔记已保存"})
+}
+
+// handleDeleteTradeNote 删除持仓的复盘笔记（笔记不属于该持仓时返回404）
+func (s *Server) handleDeleteTradeNote(c *gin.Context) {
+	traderID, ok := s.ownedTraderFromQuery(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("note_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的笔记ID"})
+		return
+	}
+	deleted, err := s.database.DeleteTradeNote(traderID, c.Param("id"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("删除复盘笔记失败: %v", err)})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "复盘笔记不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "复盘笔记已删除"})
+}
+
+// attachTradeNotes 把复盘笔记附加到持仓生命周期上（读取失败时不影响持仓历史）
+func (s *Server) attachTradeNotes(traderID string, lifecycles ...*logger.PositionLifecycle) {
+	positionID := ""
+	if len(lifecycles) == 1 {
+		positionID = lifecycles[0].ID
+	}
+	notes, err := s.database.GetTradeNotes(traderID, positionID)
+	if err != nil || len(notes) == 0 {
+		return
+	}
+	byPosition := make(map[string][]logger.TradeNote)
+	for _, n := range notes {
+		byPosition[n.PositionID] = append(byPosition[n.PositionID], logger.TradeNote{
+			ID:         n.ID,
+			PositionID: n.PositionID,
+			Author:     n.Author,
+			Note:       n.Note,
+			ImageURL:   n.ImageURL,
+			Attachment: n.Attachment,
+			CreatedAt:  n.CreatedAt,
+		})
+	}
+	for _, lc := range lifecycles {
+		lc.Notes = byPosition[lc.ID]
+	}
+}
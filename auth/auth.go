@@ -54,6 +54,12 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
+// IsPasswordHash 是否为 HashPassword 生成的bcrypt哈希
+func IsPasswordHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
 // GenerateOTPSecret 生成OTP密钥
 func GenerateOTPSecret() (string, error) {
 	secret := make([]byte, 20)
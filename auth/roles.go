@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Role 控制API的访问角色（权限从低到高）
+type Role string
+
+const (
+	RoleRead  Role = "read"  // 只读：查询状态/持仓/报告，适合监控集成
+	RoleTrade Role = "trade" // 交易：另可启停交易员、下单/平仓、划转
+	RoleAdmin Role = "admin" // 管理：另可修改交易员/模型/交易所配置（登录用户均为admin）
+)
+
+// roleLevels 角色权限级别
+var roleLevels = map[Role]int{RoleRead: 1, RoleTrade: 2, RoleAdmin: 3}
+
+// Allows 当前角色是否具有required角色的权限
+func (r Role) Allows(required Role) bool {
+	return roleLevels[r] >= roleLevels[required]
+}
+
+// APIToken 静态API Token（供监控、脚本等集成使用，不需要登录）
+type APIToken struct {
+	Name   string `json:"name"`
+	Token  string `json:"token"`
+	Role   Role   `json:"role"`
+	UserID string `json:"user_id,omitempty"` // Token所属用户（默认admin）
+}
+
+var apiTokens = struct {
+	sync.RWMutex
+	tokens []APIToken
+}{}
+
+// ParseAPITokens 解析API Token配置（JSON数组，如 [{"name":"grafana","token":"xxx","role":"read"}]）
+func ParseAPITokens(s string) ([]APIToken, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var tokens []APIToken
+	if err := json.Unmarshal([]byte(s), &tokens); err != nil {
+		return nil, fmt.Errorf("解析api_tokens失败: %w", err)
+	}
+	for i := range tokens {
+		t := &tokens[i]
+		t.Role = Role(strings.ToLower(string(t.Role)))
+		if _, ok := roleLevels[t.Role]; !ok {
+			return nil, fmt.Errorf("API Token %s 的角色无效: %q（可选: read, trade, admin）", t.Name, t.Role)
+		}
+		if len(t.Token) < 16 {
+			return nil, fmt.Errorf("API Token %s 过短（至少16个字符）", t.Name)
+		}
+		if t.UserID == "" {
+			t.UserID = "admin"
+		}
+	}
+	return tokens, nil
+}
+
+// SetAPITokens 设置静态API Token
+func SetAPITokens(tokens []APIToken) {
+	apiTokens.Lock()
+	apiTokens.tokens = tokens
+	apiTokens.Unlock()
+}
+
+// LookupAPIToken 查找静态API Token（常量时间比较）
+func LookupAPIToken(token string) (*APIToken, bool) {
+	apiTokens.RLock()
+	defer apiTokens.RUnlock()
+	for i := range apiTokens.tokens {
+		t := &apiTokens.tokens[i]
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.Token)) == 1 {
+			result := *t
+			return &result, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"sync"
+	"time"
+)
+
+// adminPasswordHash 管理员模式下访问控制台/API需要的密码的bcrypt哈希（为空时管理员模式无需登录）
+var adminPasswordHash string
+
+// SetAdminPasswordHash 设置管理员模式登录密码（PIN）的哈希（见 HashPassword）
+func SetAdminPasswordHash(hash string) {
+	adminPasswordHash = hash
+}
+
+// AdminPasswordRequired 管理员模式是否需要登录
+func AdminPasswordRequired() bool {
+	return adminPasswordHash != ""
+}
+
+// CheckAdminPassword 校验管理员模式的登录密码（未迁移的明文按常量时间比较）
+func CheckAdminPassword(password string) bool {
+	if adminPasswordHash == "" {
+		return false
+	}
+	if !IsPasswordHash(adminPasswordHash) {
+		return subtle.ConstantTimeCompare([]byte(password), []byte(adminPasswordHash)) == 1
+	}
+	return CheckPassword(password, adminPasswordHash)
+}
+
+// LoginLimiter 登录失败限流：同一来源在窗口期内失败次数达到上限后锁定一段时间
+type LoginLimiter struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+	attempts    map[string]*loginAttempts
+}
+
+// loginAttempts 某个来源的登录失败记录
+type loginAttempts struct {
+	failures    int
+	firstFailed time.Time
+	lockedUntil time.Time
+}
+
+// NewLoginLimiter 创建登录限流器（window内失败maxFailures次后锁定lockout）
+func NewLoginLimiter(maxFailures int, window, lockout time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		maxFailures: maxFailures,
+		window:      window,
+		lockout:     lockout,
+		attempts:    make(map[string]*loginAttempts),
+	}
+}
+
+// Allow 是否允许该来源尝试登录，被锁定时返回剩余锁定时长
+func (l *LoginLimiter) Allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a, ok := l.attempts[key]
+	if !ok {
+		return 0, true
+	}
+	if remaining := time.Until(a.lockedUntil); remaining > 0 {
+		return remaining, false
+	}
+	return 0, true
+}
+
+// RecordFailure 记录一次登录失败
+func (l *LoginLimiter) RecordFailure(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for k, a := range l.attempts {
+		if now.Sub(a.firstFailed) > l.window && now.After(a.lockedUntil) {
+			delete(l.attempts, k)
+		}
+	}
+	a, ok := l.attempts[key]
+	if !ok {
+		a = &loginAttempts{firstFailed: now}
+		l.attempts[key] = a
+	}
+	a.failures++
+	if a.failures >= l.maxFailures {
+		a.lockedUntil = now.Add(l.lockout)
+		a.failures = 0
+		a.firstFailed = now
+	}
+}
+
+// Reset 登录成功后清除失败记录
+func (l *LoginLimiter) Reset(key string) {
+	l.mu.Lock()
+	delete(l.attempts, key)
+	l.mu.Unlock()
+}
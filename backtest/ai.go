@@ -0,0 +1,286 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// aiKlineWindow 每个周期交给AI的K线数量（与实盘 WSMonitor 缓存的数量一致，指标计算结果相同）
+const aiKlineWindow = 100
+
+// AIStep AI策略在一个决策周期的输入及输出
+type AIStep struct {
+	Time         time.Time           `json:"time"`
+	UserPrompt   string              `json:"user_prompt"`
+	RawResponse  string              `json:"raw_response"`
+	CoTTrace     string              `json:"cot_trace"`
+	Decisions    []decision.Decision `json:"decisions"`
+	GuardrailLog []string            `json:"guardrail_log,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// AIStrategy 把每个决策周期的历史K线快照构造成与实盘相同的AI输入，交给模型离线决策
+// 模型可以是真实的 *mcp.Client（只调用AI不下单），也可以是 ReplayModel / StubModel（完全离线、结果可复现）
+// 历史数据不含持仓量及资金费率，prompt中这两项为空
+type AIStrategy struct {
+	Model           decision.Caller
+	CustomPrompt    string
+	OverrideBase    bool
+	Template        string  // 系统提示词模板（空=default）
+	BTCETHLeverage  int     // 0=5
+	AltcoinLeverage int     // 0=5
+	MaxPositionUSD  float64 // 单笔开仓名义价值硬上限（0=只按净值倍数限制）
+
+	IntradayInterval string // 日内数据周期（空=3m），须包含在 Config.Series 中
+	LongInterval     string // 长周期数据（空=4h）
+	StopOnError      bool   // AI调用或解析失败时终止回测（默认记录错误并跳过该周期）
+
+	Steps []AIStep // 每个周期的prompt、响应及决策
+
+	initialEquity float64
+	startTime     time.Time
+}
+
+// Decide 实现 Strategy
+func (s *AIStrategy) Decide(snapshot *Snapshot) ([]decision.Decision, error) {
+	if s.Model == nil {
+		return nil, fmt.Errorf("未设置AI模型")
+	}
+	ctx, err := s.buildContext(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	full, err := decision.GetFullDecisionFromContext(ctx, s.Model, s.CustomPrompt, s.OverrideBase, s.template())
+	step := AIStep{Time: snapshot.Time}
+	if full != nil {
+		step.UserPrompt = full.UserPrompt
+		step.RawResponse = full.RawResponse
+		step.CoTTrace = full.CoTTrace
+		step.GuardrailLog = full.GuardrailLog
+	}
+	if err != nil {
+		step.Error = err.Error()
+		s.Steps = append(s.Steps, step)
+		if s.StopOnError {
+			return nil, err
+		}
+		return nil, nil
+	}
+	step.Decisions = full.Decisions
+	s.Steps = append(s.Steps, step)
+	return full.Decisions, nil
+}
+
+// template 系统提示词模板名称
+func (s *AIStrategy) template() string {
+	if s.Template == "" {
+		return "default"
+	}
+	return s.Template
+}
+
+// buildContext 由回测快照构造AI决策上下文（与实盘 buildTradingContext 对应）
+func (s *AIStrategy) buildContext(snapshot *Snapshot) (*decision.Context, error) {
+	if n := len(s.Steps); n > 0 && !snapshot.Time.After(s.Steps[n-1].Time) {
+		s.Steps, s.initialEquity = nil, 0 // 同一策略再次回测（如 RecordRun 后 CheckParity）时重新开始
+	}
+	if s.initialEquity == 0 {
+		s.initialEquity = snapshot.Equity
+		s.startTime = snapshot.Time
+	}
+	intraday, long := s.IntradayInterval, s.LongInterval
+	if intraday == "" {
+		intraday = "3m"
+	}
+	if long == "" {
+		long = "4h"
+	}
+	btcEthLeverage, altcoinLeverage := s.BTCETHLeverage, s.AltcoinLeverage
+	if btcEthLeverage <= 0 {
+		btcEthLeverage = 5
+	}
+	if altcoinLeverage <= 0 {
+		altcoinLeverage = 5
+	}
+
+	ctx := &decision.Context{
+		CurrentTime:     snapshot.Time.Format("2006-01-02 15:04:05"),
+		RuntimeMinutes:  int(snapshot.Time.Sub(s.startTime).Minutes()),
+		CallCount:       len(s.Steps) + 1,
+		MarketDataMap:   make(map[string]*market.Data),
+		OITopDataMap:    make(map[string]*decision.OITopData),
+		BTCETHLeverage:  btcEthLeverage,
+		AltcoinLeverage: altcoinLeverage,
+		MaxPositionUSD:  s.MaxPositionUSD,
+		Now:             snapshot.Time,
+		Offline:         true,
+	}
+
+	symbols := make([]string, 0, len(snapshot.Series))
+	for symbol := range snapshot.Series {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		intervals := snapshot.Series[symbol]
+		if _, ok := intervals[intraday]; !ok {
+			return nil, fmt.Errorf("%s 缺少日内周期 %s 的K线", symbol, intraday)
+		}
+		data, err := market.FromKlines(symbol, lastKlines(intervals[intraday]), lastKlines(intervals[long]))
+		if err != nil {
+			continue // 预热阶段该币种可能还没有已收盘的K线
+		}
+		ctx.MarketDataMap[symbol] = data
+		ctx.CandidateCoins = append(ctx.CandidateCoins, decision.CandidateCoin{Symbol: symbol})
+	}
+
+	marginUsed := 0.0
+	for _, pos := range snapshot.Positions {
+		price := snapshot.Prices[pos.Symbol]
+		pnl := pos.pnl(price)
+		margin := pos.margin()
+		info := decision.PositionInfo{
+			Symbol:           pos.Symbol,
+			Side:             pos.Side,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        price,
+			Quantity:         pos.Quantity,
+			Leverage:         pos.Leverage,
+			UnrealizedPnL:    pnl,
+			LiquidationPrice: pos.EntryPrice * (1 - 1/float64(pos.Leverage)),
+			MarginUsed:       margin,
+			UpdateTime:       pos.EntryTime.UnixMilli(),
+		}
+		if pos.Side == "short" {
+			info.LiquidationPrice = pos.EntryPrice * (1 + 1/float64(pos.Leverage))
+		}
+		if margin > 0 {
+			info.UnrealizedPnLPct = pnl / margin * 100
+		}
+		marginUsed += margin
+		ctx.Positions = append(ctx.Positions, info)
+	}
+
+	ctx.Account = decision.AccountInfo{
+		TotalEquity:      snapshot.Equity,
+		AvailableBalance: snapshot.Available,
+		TotalPnL:         snapshot.Equity - s.initialEquity,
+		MarginUsed:       marginUsed,
+		PositionCount:    len(snapshot.Positions),
+	}
+	if s.initialEquity > 0 {
+		ctx.Account.TotalPnLPct = ctx.Account.TotalPnL / s.initialEquity * 100
+	}
+	if snapshot.Equity > 0 {
+		ctx.Account.MarginUsedPct = marginUsed / snapshot.Equity * 100
+	}
+	return ctx, nil
+}
+
+// lastKlines 最近 aiKlineWindow 根K线
+func lastKlines(klines []market.Kline) []market.Kline {
+	if len(klines) > aiKlineWindow {
+		return klines[len(klines)-aiKlineWindow:]
+	}
+	return klines
+}
+
+// promptKey 录制响应的键（与审计日志的 prompt_hash 相同）
+func promptKey(systemPrompt, userPrompt string) string {
+	return logger.HashText(systemPrompt + "\n" + userPrompt)
+}
+
+// StubModel 桩模型：由函数根据prompt直接生成AI响应（如固定返回 wait），用于不依赖AI的离线测试
+type StubModel func(systemPrompt, userPrompt string) (string, error)
+
+// CallWithMessages 实现 decision.Caller
+func (f StubModel) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return f(systemPrompt, userPrompt)
+}
+
+// RecordingModel 包装真实模型并录制每个prompt的响应，保存后可用 ReplayModel 确定性地回放
+type RecordingModel struct {
+	Model decision.Caller
+
+	mu        sync.Mutex
+	responses map[string]string
+}
+
+// CallWithMessages 实现 decision.Caller
+func (m *RecordingModel) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	response, err := m.Model.CallWithMessages(systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.responses == nil {
+		m.responses = make(map[string]string)
+	}
+	m.responses[promptKey(systemPrompt, userPrompt)] = response
+	return response, nil
+}
+
+// Save 保存录制的响应（JSON: prompt哈希 -> 原始响应）
+func (m *RecordingModel) Save(path string) error {
+	m.mu.Lock()
+	data, err := json.MarshalIndent(m.responses, "", "  ")
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化录制响应失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("保存录制响应失败: %w", err)
+	}
+	return nil
+}
+
+// ReplayModel 按prompt哈希回放录制的AI响应：prompt构造或市场数据计算的任何变化都会导致未命中，可用于回归测试
+type ReplayModel struct {
+	Responses map[string]string // prompt哈希 -> 原始响应
+	Fallback  decision.Caller   // 未录制的prompt交给该模型（nil=返回错误）
+}
+
+// LoadReplayModel 读取 RecordingModel.Save 保存的响应
+func LoadReplayModel(path string) (*ReplayModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取录制响应失败: %w", err)
+	}
+	m := &ReplayModel{}
+	if err := json.Unmarshal(data, &m.Responses); err != nil {
+		return nil, fmt.Errorf("解析录制响应失败: %w", err)
+	}
+	return m, nil
+}
+
+// NewReplayModelFromAudit 用AI审计日志中的prompt及原始响应构建回放模型
+func NewReplayModelFromAudit(records []*logger.AuditRecord) *ReplayModel {
+	m := &ReplayModel{Responses: make(map[string]string, len(records))}
+	for _, r := range records {
+		if r.Error == "" && r.RawResponse != "" {
+			m.Responses[r.PromptHash] = r.RawResponse
+		}
+	}
+	return m
+}
+
+// CallWithMessages 实现 decision.Caller
+func (m *ReplayModel) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	key := promptKey(systemPrompt, userPrompt)
+	if response, ok := m.Responses[key]; ok {
+		return response, nil
+	}
+	if m.Fallback != nil {
+		return m.Fallback.CallWithMessages(systemPrompt, userPrompt)
+	}
+	return "", fmt.Errorf("没有录制该prompt的响应 (prompt_hash %s)", key[:12])
+}
@@ -0,0 +1,397 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"nofx/decision"
+	"nofx/market"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFeeRate = 0.0005 // 默认taker费率
+	defaultWarmup  = 50     // 默认预热K线数（策略开始决策前的历史长度）
+)
+
+// Strategy 回测策略：根据截至当前K线收盘的数据给出决策（与实盘AI决策格式一致）
+type Strategy interface {
+	Decide(snapshot *Snapshot) ([]decision.Decision, error)
+}
+
+// StrategyFunc 函数形式的策略
+type StrategyFunc func(snapshot *Snapshot) ([]decision.Decision, error)
+
+// Decide 实现 Strategy
+func (f StrategyFunc) Decide(snapshot *Snapshot) ([]decision.Decision, error) {
+	return f(snapshot)
+}
+
+// Snapshot 决策时点的行情及账户状态（只包含已收盘的K线，避免未来函数）
+type Snapshot struct {
+	Time      time.Time
+	Symbol    string                               // 主币种（单币种回测时即回测币种）
+	Bars      []market.Kline                       // 主币种在决策周期上截至当前K线（含）的历史
+	Series    map[string]map[string][]market.Kline // symbol -> interval -> 截至当前时间已收盘的K线
+	Prices    map[string]float64                   // symbol -> 最新收盘价
+	Equity    float64
+	Available float64
+	Positions []Position
+}
+
+// Position 模拟持仓
+type Position struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"` // "long" 或 "short"
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	Leverage   int       `json:"leverage"`
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	EntryTime  time.Time `json:"entry_time"`
+	EntryFee   float64   `json:"entry_fee"`
+}
+
+// Trade 已平仓的交易
+type Trade struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	PnL        float64   `json:"pnl"` // 扣除手续费后的盈亏
+	Fee        float64   `json:"fee"`
+	Reason     string    `json:"reason"` // signal, stop_loss, take_profit, end
+}
+
+// EquityPoint 净值曲线上的点（每根K线收盘）
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// Config 回测配置
+// 单币种回测只需设置 Symbol/Interval/Klines；多币种多周期回测设置 Series，
+// 并用 Interval 指定决策周期（与实盘扫描间隔对应），所有币种共用一个模拟账户
+type Config struct {
+	Symbol         string
+	Interval       string                               // 决策周期（也用于报告）
+	Klines         []market.Kline                       // 单币种：按时间升序的历史K线
+	Series         map[string]map[string][]market.Kline // 多币种：symbol -> interval -> 按时间升序的K线
+	InitialBalance float64
+	FeeRate        float64 // 0=使用默认taker费率
+	Warmup         int     // 决策周期上的预热K线数，0=使用默认值
+}
+
+// series 整理回测数据：单币种配置转换为 Series 形式，返回排序后的币种列表
+func (cfg Config) series() (map[string]map[string][]market.Kline, []string, error) {
+	series := cfg.Series
+	if len(series) == 0 {
+		if cfg.Symbol == "" {
+			return nil, nil, fmt.Errorf("未指定回测币种")
+		}
+		series = map[string]map[string][]market.Kline{cfg.Symbol: {cfg.Interval: cfg.Klines}}
+	}
+	symbols := make([]string, 0, len(series))
+	for symbol, intervals := range series {
+		if _, ok := intervals[cfg.Interval]; !ok {
+			return nil, nil, fmt.Errorf("%s 缺少决策周期 %s 的K线", symbol, cfg.Interval)
+		}
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return series, symbols, nil
+}
+
+// Result 回测结果
+type Result struct {
+	Symbol         string        `json:"symbol"`
+	Interval       string        `json:"interval"`
+	InitialBalance float64       `json:"initial_balance"`
+	Trades         []Trade       `json:"trades"`
+	Equity         []EquityPoint `json:"equity"`
+	Rejected       []string      `json:"rejected,omitempty"` // 被拒绝的决策（余额不足、重复开仓等）
+}
+
+// account 回测模拟账户
+type account struct {
+	wallet    float64
+	feeRate   float64
+	positions map[string]*Position // symbol_side -> 持仓
+	result    *Result
+}
+
+// Run 执行回测：每个决策周期先按各币种K线的最高/最低价处理止损止盈，
+// 再在收盘时把所有币种、所有周期已收盘的K线交给策略，按收盘价成交（与实盘每个周期统一决策一致）
+func Run(cfg Config, strategy Strategy) (*Result, error) {
+	if cfg.InitialBalance <= 0 {
+		return nil, fmt.Errorf("初始资金必须大于0")
+	}
+	series, symbols, err := cfg.series()
+	if err != nil {
+		return nil, err
+	}
+	warmup := cfg.Warmup
+	if warmup <= 0 {
+		warmup = defaultWarmup
+	}
+
+	// 决策时间轴：所有币种决策周期K线收盘时间的并集
+	seen := make(map[int64]bool)
+	var timeline []int64
+	for _, symbol := range symbols {
+		for _, bar := range series[symbol][cfg.Interval] {
+			if !seen[bar.CloseTime] {
+				seen[bar.CloseTime] = true
+				timeline = append(timeline, bar.CloseTime)
+			}
+		}
+	}
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i] < timeline[j] })
+	if len(timeline) <= warmup {
+		return nil, fmt.Errorf("K线数量(%d)不足，至少需要 %d 根", len(timeline), warmup+1)
+	}
+
+	primary := cfg.Symbol
+	if _, ok := series[primary]; !ok {
+		primary = symbols[0]
+	}
+	acc := newAccount(cfg.InitialBalance, cfg.FeeRate, &Result{
+		Symbol:         strings.Join(symbols, ","),
+		Interval:       cfg.Interval,
+		InitialBalance: cfg.InitialBalance,
+	})
+
+	// 每个序列已收盘K线数量的游标（时间单调递增，只需向前推进）
+	cursors := make(map[string]map[string]int)
+	for _, symbol := range symbols {
+		cursors[symbol] = make(map[string]int)
+	}
+	prices := make(map[string]float64)
+
+	for step, closeTime := range timeline {
+		visible := make(map[string]map[string][]market.Kline, len(symbols))
+		for _, symbol := range symbols {
+			visible[symbol] = make(map[string][]market.Kline)
+			for interval, klines := range series[symbol] {
+				n := cursors[symbol][interval]
+				for n < len(klines) && klines[n].CloseTime <= closeTime {
+					n++
+				}
+				cursors[symbol][interval] = n
+				visible[symbol][interval] = klines[:n]
+			}
+
+			// 本周期收盘的K线：先检查止损止盈，再更新价格
+			bars := visible[symbol][cfg.Interval]
+			if len(bars) > 0 && bars[len(bars)-1].CloseTime == closeTime {
+				acc.checkTriggers(symbol, bars[len(bars)-1])
+				prices[symbol] = bars[len(bars)-1].Close
+			}
+		}
+		if step < warmup {
+			continue
+		}
+
+		at := time.UnixMilli(closeTime)
+		current := make(map[string]float64, len(prices))
+		for symbol, price := range prices {
+			current[symbol] = price
+		}
+		snapshot := &Snapshot{
+			Time:      at,
+			Symbol:    primary,
+			Bars:      visible[primary][cfg.Interval],
+			Series:    visible,
+			Prices:    current,
+			Equity:    acc.equity(prices),
+			Available: acc.available(prices),
+			Positions: acc.positionList(),
+		}
+		decisions, err := strategy.Decide(snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("策略在 %s 决策失败: %w", at.Format(time.RFC3339), err)
+		}
+		for _, d := range decisions {
+			price, ok := prices[d.Symbol]
+			if !ok {
+				acc.reject(at, d, "没有该币种的行情")
+				continue
+			}
+			acc.execute(d, price, at, prices)
+		}
+
+		acc.result.Equity = append(acc.result.Equity, EquityPoint{Time: at, Equity: acc.equity(prices)})
+	}
+
+	acc.closeAll(prices, time.UnixMilli(timeline[len(timeline)-1]))
+	return acc.result, nil
+}
+
+// newAccount 创建模拟账户
+func newAccount(balance, feeRate float64, result *Result) *account {
+	if feeRate <= 0 {
+		feeRate = defaultFeeRate
+	}
+	return &account{
+		wallet:    balance,
+		feeRate:   feeRate,
+		positions: make(map[string]*Position),
+		result:    result,
+	}
+}
+
+// equity 账户净值（钱包余额 + 未实现盈亏）
+func (a *account) equity(prices map[string]float64) float64 {
+	equity := a.wallet
+	for _, pos := range a.positions {
+		equity += pos.pnl(prices[pos.Symbol])
+	}
+	return equity
+}
+
+// available 可用余额（浮亏计入，浮盈不计入）
+func (a *account) available(prices map[string]float64) float64 {
+	available := a.wallet
+	for _, pos := range a.positions {
+		available += math.Min(pos.pnl(prices[pos.Symbol]), 0) - pos.margin()
+	}
+	return available
+}
+
+// positionList 当前持仓列表（按symbol_side排序，保证回测结果可复现）
+func (a *account) positionList() []Position {
+	list := make([]Position, 0, len(a.positions))
+	for _, posKey := range a.sortedKeys() {
+		list = append(list, *a.positions[posKey])
+	}
+	return list
+}
+
+// sortedKeys 排序后的持仓键
+func (a *account) sortedKeys() []string {
+	keys := make([]string, 0, len(a.positions))
+	for posKey := range a.positions {
+		keys = append(keys, posKey)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pnl 持仓在指定价格下的未实现盈亏
+func (p *Position) pnl(price float64) float64 {
+	if p.Side == "short" {
+		return (p.EntryPrice - price) * p.Quantity
+	}
+	return (price - p.EntryPrice) * p.Quantity
+}
+
+// margin 持仓占用保证金
+func (p *Position) margin() float64 {
+	return p.Quantity * p.EntryPrice / float64(p.Leverage)
+}
+
+// execute 按成交价执行决策（与实盘相同：同方向已有持仓时拒绝开仓）
+func (a *account) execute(d decision.Decision, price float64, at time.Time, prices map[string]float64) {
+	switch d.Action {
+	case "open_long", "open_short":
+		side := "long"
+		if d.Action == "open_short" {
+			side = "short"
+		}
+		posKey := d.Symbol + "_" + side
+		if _, ok := a.positions[posKey]; ok {
+			a.reject(at, d, "已有同方向持仓")
+			return
+		}
+		if d.Leverage <= 0 || d.PositionSizeUSD <= 0 || price <= 0 {
+			a.reject(at, d, "杠杆或仓位大小无效")
+			return
+		}
+		fee := d.PositionSizeUSD * a.feeRate
+		if need := d.PositionSizeUSD/float64(d.Leverage) + fee; need > a.available(prices) {
+			a.reject(at, d, fmt.Sprintf("可用余额不足（需要 %.2f USDT）", need))
+			return
+		}
+		a.wallet -= fee
+		a.positions[posKey] = &Position{
+			Symbol:     d.Symbol,
+			Side:       side,
+			Quantity:   d.PositionSizeUSD / price,
+			EntryPrice: price,
+			Leverage:   d.Leverage,
+			StopLoss:   d.StopLoss,
+			TakeProfit: d.TakeProfit,
+			EntryTime:  at,
+			EntryFee:   fee,
+		}
+	case "close_long", "close_short":
+		side := "long"
+		if d.Action == "close_short" {
+			side = "short"
+		}
+		if _, ok := a.positions[d.Symbol+"_"+side]; !ok {
+			a.reject(at, d, "没有对应持仓")
+			return
+		}
+		a.close(d.Symbol+"_"+side, price, at, "signal")
+	}
+}
+
+// reject 记录被拒绝的决策
+func (a *account) reject(at time.Time, d decision.Decision, reason string) {
+	a.result.Rejected = append(a.result.Rejected, fmt.Sprintf("%s %s %s: %s", at.Format("2006-01-02 15:04"), d.Action, d.Symbol, reason))
+}
+
+// checkTriggers 用K线推断该币种持仓的止损/止盈触发（与模拟盘使用相同的推断规则）
+func (a *account) checkTriggers(symbol string, bar market.Kline) {
+	for _, side := range []string{"long", "short"} {
+		posKey := symbol + "_" + side
+		pos, ok := a.positions[posKey]
+		if !ok {
+			continue
+		}
+		purpose, price, hit := trader.InferBarTrigger(side, pos.StopLoss, pos.TakeProfit, bar)
+		if !hit {
+			continue
+		}
+		reason := "take_profit"
+		if purpose == trader.OrderPurposeStopLoss {
+			reason = "stop_loss"
+		}
+		a.close(posKey, price, time.UnixMilli(bar.CloseTime), reason)
+	}
+}
+
+// close 平仓并记录交易
+func (a *account) close(posKey string, price float64, at time.Time, reason string) {
+	pos := a.positions[posKey]
+	exitFee := pos.Quantity * price * a.feeRate
+	pnl := pos.pnl(price)
+	a.wallet += pnl - exitFee
+	delete(a.positions, posKey)
+
+	a.result.Trades = append(a.result.Trades, Trade{
+		Symbol:     pos.Symbol,
+		Side:       pos.Side,
+		Quantity:   pos.Quantity,
+		EntryPrice: pos.EntryPrice,
+		ExitPrice:  price,
+		EntryTime:  pos.EntryTime,
+		ExitTime:   at,
+		PnL:        pnl - pos.EntryFee - exitFee,
+		Fee:        pos.EntryFee + exitFee,
+		Reason:     reason,
+	})
+}
+
+// closeAll 回测结束时按最新价格平掉所有持仓
+func (a *account) closeAll(prices map[string]float64, at time.Time) {
+	for _, posKey := range a.sortedKeys() {
+		a.close(posKey, prices[a.positions[posKey].Symbol], at, "end")
+	}
+}
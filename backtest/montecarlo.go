@@ -0,0 +1,205 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// 蒙特卡洛抽样方式
+const (
+	MethodShuffle   = "shuffle"   // 打乱交易顺序（交易集合不变，只改变路径）
+	MethodBootstrap = "bootstrap" // 有放回抽样（交易集合也随机）
+)
+
+// 仓位计算方式
+const (
+	SizingFixed    = "fixed"    // 每笔交易盈亏保持回测中的USDT金额
+	SizingFraction = "fraction" // 每笔交易名义价值 = 当前净值 × Fraction（复利）
+)
+
+// Sizing 蒙特卡洛模拟使用的仓位方案
+type Sizing struct {
+	Mode     string  `json:"mode"`
+	Fraction float64 `json:"fraction,omitempty"` // SizingFraction: 名义价值占净值的比例（如 0.5 或 2.0）
+}
+
+// MonteCarloConfig 蒙特卡洛配置
+type MonteCarloConfig struct {
+	Runs            int     // 模拟次数（0=1000）
+	Method          string  // MethodShuffle 或 MethodBootstrap（空=shuffle）
+	Sizing          Sizing  // 空=SizingFixed
+	RuinDrawdownPct float64 // 回撤达到该百分比视为破产（0=50%）
+	Seed            int64   // 随机种子（0=固定种子1，保证可复现）
+}
+
+// Distribution 分布的分位数
+type Distribution struct {
+	Mean float64 `json:"mean"`
+	P5   float64 `json:"p5"`
+	P25  float64 `json:"p25"`
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+}
+
+// HistogramBucket 直方图区间
+type HistogramBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// MonteCarloResult 蒙特卡洛结果
+type MonteCarloResult struct {
+	Runs              int               `json:"runs"`
+	Method            string            `json:"method"`
+	Sizing            Sizing            `json:"sizing"`
+	TradeCount        int               `json:"trade_count"`
+	MaxDrawdownPct    Distribution      `json:"max_drawdown_pct"`
+	FinalEquity       Distribution      `json:"final_equity"`
+	RuinDrawdownPct   float64           `json:"ruin_drawdown_pct"`
+	RuinProbability   float64           `json:"ruin_probability"` // 百分比
+	DrawdownHistogram []HistogramBucket `json:"drawdown_histogram"`
+}
+
+// MonteCarlo 对回测交易序列做打乱/自助抽样，得到最大回撤、最终净值及破产概率的分布
+func MonteCarlo(result *Result, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if len(result.Trades) == 0 {
+		return nil, fmt.Errorf("回测没有交易，无法进行蒙特卡洛分析")
+	}
+	if cfg.Runs <= 0 {
+		cfg.Runs = 1000
+	}
+	if cfg.Method == "" {
+		cfg.Method = MethodShuffle
+	}
+	if cfg.Method != MethodShuffle && cfg.Method != MethodBootstrap {
+		return nil, fmt.Errorf("无效的抽样方式: %s（可选: shuffle, bootstrap）", cfg.Method)
+	}
+	if cfg.Sizing.Mode == "" {
+		cfg.Sizing.Mode = SizingFixed
+	}
+	if cfg.Sizing.Mode == SizingFraction && cfg.Sizing.Fraction <= 0 {
+		return nil, fmt.Errorf("按净值比例计算仓位时 Fraction 必须大于0")
+	}
+	if cfg.Sizing.Mode != SizingFixed && cfg.Sizing.Mode != SizingFraction {
+		return nil, fmt.Errorf("无效的仓位方案: %s（可选: fixed, fraction）", cfg.Sizing.Mode)
+	}
+	if cfg.RuinDrawdownPct <= 0 {
+		cfg.RuinDrawdownPct = 50
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = 1
+	}
+
+	// 每笔交易的收益：fixed用USDT盈亏，fraction用相对名义价值的收益率
+	n := len(result.Trades)
+	pnls := make([]float64, n)
+	returns := make([]float64, n)
+	for i, t := range result.Trades {
+		pnls[i] = t.PnL
+		if notional := t.Quantity * t.EntryPrice; notional > 0 {
+			returns[i] = t.PnL / notional
+		}
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	order := make([]int, n)
+	drawdowns := make([]float64, cfg.Runs)
+	finals := make([]float64, cfg.Runs)
+	ruined := 0
+
+	for run := 0; run < cfg.Runs; run++ {
+		for i := range order {
+			order[i] = i
+		}
+		if cfg.Method == MethodShuffle {
+			rng.Shuffle(n, func(i, j int) { order[i], order[j] = order[j], order[i] })
+		} else {
+			for i := range order {
+				order[i] = rng.Intn(n)
+			}
+		}
+
+		equity := result.InitialBalance
+		peak, maxDD := equity, 0.0
+		for _, idx := range order {
+			if cfg.Sizing.Mode == SizingFraction {
+				equity += equity * cfg.Sizing.Fraction * returns[idx]
+			} else {
+				equity += pnls[idx]
+			}
+			peak = math.Max(peak, equity)
+			if peak > 0 {
+				maxDD = math.Max(maxDD, (peak-equity)/peak*100)
+			}
+			if equity <= 0 {
+				equity, maxDD = 0, 100
+				break
+			}
+		}
+		drawdowns[run] = maxDD
+		finals[run] = equity
+		if maxDD >= cfg.RuinDrawdownPct {
+			ruined++
+		}
+	}
+
+	return &MonteCarloResult{
+		Runs:              cfg.Runs,
+		Method:            cfg.Method,
+		Sizing:            cfg.Sizing,
+		TradeCount:        n,
+		MaxDrawdownPct:    distribution(drawdowns),
+		FinalEquity:       distribution(finals),
+		RuinDrawdownPct:   cfg.RuinDrawdownPct,
+		RuinProbability:   float64(ruined) / float64(cfg.Runs) * 100,
+		DrawdownHistogram: histogram(drawdowns, 10),
+	}, nil
+}
+
+// distribution 计算均值及分位数（会对values排序）
+func distribution(values []float64) Distribution {
+	sort.Float64s(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	q := func(p float64) float64 {
+		return values[int(math.Round(p*float64(len(values)-1)))]
+	}
+	return Distribution{
+		Mean: sum / float64(len(values)),
+		P5:   q(0.05),
+		P25:  q(0.25),
+		P50:  q(0.50),
+		P75:  q(0.75),
+		P95:  q(0.95),
+		P99:  q(0.99),
+	}
+}
+
+// histogram 等宽直方图（values已排序）
+func histogram(values []float64, buckets int) []HistogramBucket {
+	lo, hi := values[0], values[len(values)-1]
+	if hi == lo {
+		return []HistogramBucket{{From: lo, To: hi, Count: len(values)}}
+	}
+	width := (hi - lo) / float64(buckets)
+	result := make([]HistogramBucket, buckets)
+	for i := range result {
+		result[i].From = lo + float64(i)*width
+		result[i].To = lo + float64(i+1)*width
+	}
+	for _, v := range values {
+		i := int((v - lo) / width)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		result[i].Count++
+	}
+	return result
+}
@@ -0,0 +1,260 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 偏差类型
+const (
+	DivergenceMismatch  = "mismatch"   // 同一周期决策不同
+	DivergenceEarly     = "early"      // 回测比实盘早一个周期做出相同决策（疑似未来函数，或实盘延迟）
+	DivergenceLate      = "late"       // 回测比实盘晚一个周期做出相同决策（实盘使用了未收盘K线）
+	DivergenceFillPrice = "fill_price" // 成交价偏差超过容差（延迟/滑点）
+)
+
+// Fill 实盘成交
+type Fill struct {
+	Symbol   string  `json:"symbol"`
+	Action   string  `json:"action"`
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// LiveRecord 一个实盘决策周期的记录
+type LiveRecord struct {
+	Time      time.Time           `json:"time"`
+	Decisions []decision.Decision `json:"decisions"`
+	Fills     []Fill              `json:"fills,omitempty"`
+}
+
+// Divergence 实盘与回测的偏差
+type Divergence struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Symbol   string    `json:"symbol,omitempty"`
+	Live     string    `json:"live"`
+	Backtest string    `json:"backtest"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// ParityConfig 一致性检查配置
+type ParityConfig struct {
+	FillTolerancePct float64 // 成交价相对回测价格的允许偏差（百分比，0=0.5%）
+}
+
+// ParityReport 一致性检查结果
+type ParityReport struct {
+	Compared    int          `json:"compared"` // 对比的周期数
+	Matched     int          `json:"matched"`  // 决策一致的周期数
+	Divergences []Divergence `json:"divergences"`
+}
+
+// OK 是否完全一致
+func (r *ParityReport) OK() bool {
+	return len(r.Divergences) == 0
+}
+
+// stepDecisions 回测中某个决策周期的决策及成交价
+type stepDecisions struct {
+	time      time.Time
+	decisions []decision.Decision
+	prices    map[string]float64
+}
+
+// recorder 记录策略在每个周期的决策
+type recorder struct {
+	strategy Strategy
+	steps    []stepDecisions
+}
+
+// Decide 实现 Strategy
+func (r *recorder) Decide(snapshot *Snapshot) ([]decision.Decision, error) {
+	decisions, err := r.strategy.Decide(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	r.steps = append(r.steps, stepDecisions{time: snapshot.Time, decisions: decisions, prices: snapshot.Prices})
+	return decisions, nil
+}
+
+// RecordRun 执行回测并记录每个周期的决策（可作为一致性检查的基准，或离线复现实盘行为）
+func RecordRun(cfg Config, strategy Strategy) ([]LiveRecord, *Result, error) {
+	rec := &recorder{strategy: strategy}
+	result, err := Run(cfg, rec)
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([]LiveRecord, 0, len(rec.steps))
+	for _, step := range rec.steps {
+		record := LiveRecord{Time: step.time, Decisions: step.decisions}
+		for _, d := range step.decisions {
+			if price, ok := step.prices[d.Symbol]; ok && isTrade(d.Action) {
+				record.Fills = append(record.Fills, Fill{Symbol: d.Symbol, Action: d.Action, Price: price})
+			}
+		}
+		records = append(records, record)
+	}
+	return records, result, nil
+}
+
+// LiveRecordsFromLogs 从决策日志还原实盘记录（决策取自AI输出，成交取自执行成功的操作）
+func LiveRecordsFromLogs(records []*logger.DecisionRecord) []LiveRecord {
+	var live []LiveRecord
+	for _, record := range records {
+		item := LiveRecord{Time: record.Timestamp}
+		if record.DecisionJSON != "" {
+			json.Unmarshal([]byte(record.DecisionJSON), &item.Decisions)
+		}
+		for _, action := range record.Decisions {
+			if action.Success && action.Price > 0 {
+				item.Fills = append(item.Fills, Fill{Symbol: action.Symbol, Action: action.Action, Price: action.Price, Quantity: action.Quantity})
+			}
+		}
+		live = append(live, item)
+	}
+	return live
+}
+
+// CheckParity 用实盘录制的K线回放策略，逐周期对比实盘与回测的决策及成交价
+// 实盘周期按时间对齐到不晚于它的最近一个回测周期（即实盘决策时已收盘的最后一根K线）
+func CheckParity(cfg Config, strategy Strategy, live []LiveRecord, pcfg ParityConfig) (*ParityReport, error) {
+	if pcfg.FillTolerancePct <= 0 {
+		pcfg.FillTolerancePct = 0.5
+	}
+
+	rec := &recorder{strategy: strategy}
+	if _, err := Run(cfg, rec); err != nil {
+		return nil, err
+	}
+	steps := rec.steps
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("回测没有产生任何决策周期")
+	}
+
+	// 实盘记录对齐到回测周期
+	liveAt := make(map[int]LiveRecord)
+	for _, record := range live {
+		i := sort.Search(len(steps), func(i int) bool { return steps[i].time.After(record.Time) }) - 1
+		if i < 0 {
+			continue
+		}
+		liveAt[i] = record
+	}
+
+	indexes := make([]int, 0, len(liveAt))
+	for i := range liveAt {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	btSetAt := func(i int) map[string]bool {
+		if i < 0 || i >= len(steps) {
+			return nil
+		}
+		return actionSet(steps[i].decisions)
+	}
+	liveSetAt := func(i int) map[string]bool {
+		record, ok := liveAt[i]
+		if !ok {
+			return nil
+		}
+		return actionSet(record.Decisions)
+	}
+
+	report := &ParityReport{}
+	for _, i := range indexes {
+		record := liveAt[i]
+		report.Compared++
+		liveSet, btSet := liveSetAt(i), btSetAt(i)
+		if equalSets(liveSet, btSet) {
+			report.Matched++
+		}
+
+		for _, key := range sortedSet(liveSet) {
+			switch {
+			case btSet[key]:
+			case btSetAt(i - 1)[key]:
+				report.add(record.Time, DivergenceEarly, key, key, "", "回测提前一个周期做出该决策，检查策略是否使用了未收盘K线或实盘是否存在延迟")
+			case btSetAt(i + 1)[key]:
+				report.add(record.Time, DivergenceLate, key, key, "", "回测晚一个周期才做出该决策，实盘可能使用了未收盘K线")
+			default:
+				report.add(record.Time, DivergenceMismatch, key, key, "", "回测在该周期没有此决策")
+			}
+		}
+		// 只有回测做出的决策：相邻周期的实盘有相同决策时已记为提前/滞后
+		for _, key := range sortedSet(btSet) {
+			if !liveSet[key] && !liveSetAt(i + 1)[key] && !liveSetAt(i - 1)[key] {
+				report.add(record.Time, DivergenceMismatch, key, "", key, "实盘在该周期没有此决策")
+			}
+		}
+
+		// 成交价对比（相对回测收盘价）
+		for _, fill := range record.Fills {
+			expected, ok := steps[i].prices[fill.Symbol]
+			if !ok || expected <= 0 || fill.Price <= 0 {
+				continue
+			}
+			if diff := (fill.Price - expected) / expected * 100; math.Abs(diff) > pcfg.FillTolerancePct {
+				report.add(record.Time, DivergenceFillPrice, fill.Action+" "+fill.Symbol,
+					fmt.Sprintf("%.4f", fill.Price), fmt.Sprintf("%.4f", expected),
+					fmt.Sprintf("成交价偏差 %+.2f%%，超过容差 %.2f%%", diff, pcfg.FillTolerancePct))
+			}
+		}
+	}
+	return report, nil
+}
+
+// add 记录偏差
+func (r *ParityReport) add(at time.Time, kind, key, live, backtest, detail string) {
+	symbol := key
+	if parts := strings.SplitN(key, " ", 2); len(parts) == 2 {
+		symbol = parts[1]
+	}
+	r.Divergences = append(r.Divergences, Divergence{Time: at, Kind: kind, Symbol: symbol, Live: live, Backtest: backtest, Detail: detail})
+}
+
+// actionSet 决策集合（只比较会产生订单的操作，hold/wait忽略）
+func actionSet(decisions []decision.Decision) map[string]bool {
+	set := make(map[string]bool)
+	for _, d := range decisions {
+		if isTrade(d.Action) {
+			set[d.Action+" "+d.Symbol] = true
+		}
+	}
+	return set
+}
+
+// sortedSet 排序后的集合元素（保证偏差报告顺序稳定）
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isTrade 是否为开平仓操作
+func isTrade(action string) bool {
+	return strings.HasPrefix(action, "open_") || strings.HasPrefix(action, "close_")
+}
+
+// equalSets 两个集合是否相同
+func equalSets(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+	return true
+}
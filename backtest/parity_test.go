@@ -0,0 +1,195 @@
+package backtest
+
+import (
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"testing"
+	"time"
+)
+
+// syntheticKlines 生成确定性的正弦走势K线
+func syntheticKlines(n int) []market.Kline {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]market.Kline, n)
+	prev := 100.0
+	for i := range klines {
+		price := 100 + 10*math.Sin(float64(i)/15)
+		open := base.Add(time.Duration(i) * time.Hour)
+		klines[i] = market.Kline{
+			OpenTime:  open.UnixMilli(),
+			CloseTime: open.Add(time.Hour).UnixMilli() - 1,
+			Open:      prev,
+			High:      math.Max(prev, price) + 0.3,
+			Low:       math.Min(prev, price) - 0.3,
+			Close:     price,
+		}
+		prev = price
+	}
+	return klines
+}
+
+// crossSignal 均线交叉信号（不依赖持仓状态，便于对比决策）
+func crossSignal(bars []market.Kline) []decision.Decision {
+	if len(bars) < 21 {
+		return nil
+	}
+	sma := func(end, period int) float64 {
+		sum := 0.0
+		for _, bar := range bars[end-period : end] {
+			sum += bar.Close
+		}
+		return sum / float64(period)
+	}
+	n := len(bars)
+	prevFast, prevSlow := sma(n-1, 5), sma(n-1, 20)
+	fast, slow := sma(n, 5), sma(n, 20)
+	price := bars[n-1].Close
+
+	switch {
+	case prevFast <= prevSlow && fast > slow:
+		return []decision.Decision{{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 500, StopLoss: price * 0.9, TakeProfit: price * 1.2}}
+	case prevFast >= prevSlow && fast < slow:
+		return []decision.Decision{{Symbol: "BTCUSDT", Action: "close_long"}}
+	}
+	return nil
+}
+
+func parityConfig(klines []market.Kline) Config {
+	return Config{Symbol: "BTCUSDT", Interval: "1h", Klines: klines, InitialBalance: 1000, Warmup: 30}
+}
+
+func TestParityIdenticalStrategy(t *testing.T) {
+	klines := syntheticKlines(400)
+	strategy := StrategyFunc(func(s *Snapshot) ([]decision.Decision, error) { return crossSignal(s.Bars), nil })
+
+	live, _, err := RecordRun(parityConfig(klines), strategy)
+	if err != nil {
+		t.Fatalf("RecordRun failed: %v", err)
+	}
+	report, err := CheckParity(parityConfig(klines), strategy, live, ParityConfig{})
+	if err != nil {
+		t.Fatalf("CheckParity failed: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("identical strategy diverged: %+v", report.Divergences)
+	}
+	if report.Compared != len(live) || report.Matched != report.Compared {
+		t.Fatalf("compared=%d matched=%d, want %d", report.Compared, report.Matched, len(live))
+	}
+}
+
+func TestParityFlagsLookahead(t *testing.T) {
+	klines := syntheticKlines(400)
+	honest := StrategyFunc(func(s *Snapshot) ([]decision.Decision, error) { return crossSignal(s.Bars), nil })
+	// 偷看下一根K线：比实盘早一个周期发出信号
+	peeking := StrategyFunc(func(s *Snapshot) ([]decision.Decision, error) {
+		n := len(s.Bars) + 1
+		if n > len(klines) {
+			n = len(klines)
+		}
+		return crossSignal(klines[:n]), nil
+	})
+
+	live, _, err := RecordRun(parityConfig(klines), honest)
+	if err != nil {
+		t.Fatalf("RecordRun failed: %v", err)
+	}
+	report, err := CheckParity(parityConfig(klines), peeking, live, ParityConfig{})
+	if err != nil {
+		t.Fatalf("CheckParity failed: %v", err)
+	}
+
+	early := 0
+	for _, d := range report.Divergences {
+		switch d.Kind {
+		case DivergenceEarly:
+			early++
+		case DivergenceMismatch:
+			t.Errorf("unexpected mismatch (should be classified as early): %+v", d)
+		}
+	}
+	if early == 0 {
+		t.Fatalf("lookahead strategy not flagged: %+v", report.Divergences)
+	}
+}
+
+func TestParityFlagsLateLiveSignal(t *testing.T) {
+	klines := syntheticKlines(400)
+	honest := StrategyFunc(func(s *Snapshot) ([]decision.Decision, error) { return crossSignal(s.Bars), nil })
+	// 实盘滞后一个周期（如决策延迟到下一根K线）
+	lagging := StrategyFunc(func(s *Snapshot) ([]decision.Decision, error) { return crossSignal(s.Bars[:len(s.Bars)-1]), nil })
+
+	live, _, err := RecordRun(parityConfig(klines), honest)
+	if err != nil {
+		t.Fatalf("RecordRun failed: %v", err)
+	}
+	report, err := CheckParity(parityConfig(klines), lagging, live, ParityConfig{})
+	if err != nil {
+		t.Fatalf("CheckParity failed: %v", err)
+	}
+	for _, d := range report.Divergences {
+		if d.Kind != DivergenceLate {
+			t.Errorf("divergence kind = %s, want %s: %+v", d.Kind, DivergenceLate, d)
+		}
+	}
+	if len(report.Divergences) == 0 {
+		t.Fatal("lagging strategy not flagged")
+	}
+}
+
+func TestParityFlagsFillSlippage(t *testing.T) {
+	klines := syntheticKlines(400)
+	strategy := StrategyFunc(func(s *Snapshot) ([]decision.Decision, error) { return crossSignal(s.Bars), nil })
+
+	live, _, err := RecordRun(parityConfig(klines), strategy)
+	if err != nil {
+		t.Fatalf("RecordRun failed: %v", err)
+	}
+	slipped := -1
+	for i := range live {
+		if len(live[i].Fills) > 0 {
+			live[i].Fills[0].Price *= 1.01
+			slipped = i
+			break
+		}
+	}
+	if slipped < 0 {
+		t.Fatal("no fills recorded")
+	}
+
+	report, err := CheckParity(parityConfig(klines), strategy, live, ParityConfig{FillTolerancePct: 0.5})
+	if err != nil {
+		t.Fatalf("CheckParity failed: %v", err)
+	}
+	if len(report.Divergences) != 1 || report.Divergences[0].Kind != DivergenceFillPrice {
+		t.Fatalf("divergences = %+v, want one %s", report.Divergences, DivergenceFillPrice)
+	}
+	if !report.Divergences[0].Time.Equal(live[slipped].Time) {
+		t.Errorf("divergence time = %v, want %v", report.Divergences[0].Time, live[slipped].Time)
+	}
+}
+
+func TestLiveRecordsFromLogs(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []*logger.DecisionRecord{{
+		Timestamp:    now,
+		DecisionJSON: `[{"symbol":"ETHUSDT","action":"open_short","leverage":3,"position_size_usd":200}]`,
+		Decisions: []logger.DecisionAction{
+			{Action: "open_short", Symbol: "ETHUSDT", Price: 3000, Quantity: 0.066, Success: true},
+			{Action: "close_long", Symbol: "BTCUSDT", Price: 90000, Success: false},
+		},
+	}}
+
+	live := LiveRecordsFromLogs(records)
+	if len(live) != 1 || !live[0].Time.Equal(now) {
+		t.Fatalf("live = %+v", live)
+	}
+	if len(live[0].Decisions) != 1 || live[0].Decisions[0].Action != "open_short" {
+		t.Errorf("decisions = %+v", live[0].Decisions)
+	}
+	if len(live[0].Fills) != 1 || live[0].Fills[0].Price != 3000 {
+		t.Errorf("fills = %+v, want only the successful open_short", live[0].Fills)
+	}
+}
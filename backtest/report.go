@@ -0,0 +1,334 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Summary 回测统计
+type Summary struct {
+	InitialBalance float64 `json:"initial_balance"`
+	FinalEquity    float64 `json:"final_equity"`
+	TotalReturnPct float64 `json:"total_return_pct"`
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"`
+	TradeCount     int     `json:"trade_count"`
+	WinRate        float64 `json:"win_rate"`      // 百分比
+	ProfitFactor   float64 `json:"profit_factor"` // 总盈利/总亏损（无亏损时为0）
+	AvgTradePnL    float64 `json:"avg_trade_pnl"`
+	TotalFees      float64 `json:"total_fees"`
+}
+
+// DrawdownPoint 回撤曲线上的点
+type DrawdownPoint struct {
+	Time time.Time `json:"time"`
+	Pct  float64   `json:"pct"` // 相对历史最高净值的回撤百分比（负数）
+}
+
+// MonthlyReturn 月度收益
+type MonthlyReturn struct {
+	Month     string  `json:"month"` // 2006-01
+	ReturnPct float64 `json:"return_pct"`
+}
+
+// Report 回测报告（可导出为JSON/HTML）
+type Report struct {
+	Symbol      string          `json:"symbol"`
+	Interval    string          `json:"interval"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Summary     Summary         `json:"summary"`
+	Equity      []EquityPoint   `json:"equity"`
+	Drawdown    []DrawdownPoint `json:"drawdown"`
+	Monthly     []MonthlyReturn `json:"monthly_returns"`
+	Trades      []Trade         `json:"trades"`
+	Rejected    []string        `json:"rejected,omitempty"`
+
+	MonteCarlo *MonteCarloResult `json:"monte_carlo,omitempty"` // 可选：蒙特卡洛稳健性分析
+}
+
+// BuildReport 根据回测结果计算统计、回撤曲线和月度收益
+func BuildReport(result *Result) *Report {
+	report := &Report{
+		Symbol:      result.Symbol,
+		Interval:    result.Interval,
+		GeneratedAt: time.Now(),
+		Equity:      result.Equity,
+		Trades:      result.Trades,
+		Rejected:    result.Rejected,
+	}
+
+	s := &report.Summary
+	s.InitialBalance = result.InitialBalance
+	s.FinalEquity = result.InitialBalance
+	if len(result.Equity) > 0 {
+		s.FinalEquity = result.Equity[len(result.Equity)-1].Equity
+	}
+	// 结束时强制平仓会产生额外手续费，以交易记录为准
+	realized := result.InitialBalance
+	for _, t := range result.Trades {
+		realized += t.PnL
+	}
+	if len(result.Trades) > 0 {
+		s.FinalEquity = realized
+	}
+	if s.InitialBalance > 0 {
+		s.TotalReturnPct = (s.FinalEquity - s.InitialBalance) / s.InitialBalance * 100
+	}
+
+	// 回撤曲线
+	peak := result.InitialBalance
+	for _, p := range result.Equity {
+		peak = math.Max(peak, p.Equity)
+		dd := 0.0
+		if peak > 0 {
+			dd = (p.Equity - peak) / peak * 100
+		}
+		report.Drawdown = append(report.Drawdown, DrawdownPoint{Time: p.Time, Pct: dd})
+		s.MaxDrawdownPct = math.Max(s.MaxDrawdownPct, -dd)
+	}
+
+	// 交易统计
+	wins, grossProfit, grossLoss := 0, 0.0, 0.0
+	for _, t := range result.Trades {
+		s.TotalFees += t.Fee
+		if t.PnL > 0 {
+			wins++
+			grossProfit += t.PnL
+		} else {
+			grossLoss -= t.PnL
+		}
+	}
+	s.TradeCount = len(result.Trades)
+	if s.TradeCount > 0 {
+		s.WinRate = float64(wins) / float64(s.TradeCount) * 100
+		s.AvgTradePnL = (grossProfit - grossLoss) / float64(s.TradeCount)
+	}
+	if grossLoss > 0 {
+		s.ProfitFactor = grossProfit / grossLoss
+	}
+
+	report.Monthly = monthlyReturns(result.InitialBalance, result.Equity)
+	return report
+}
+
+// monthlyReturns 按月末净值计算月度收益
+func monthlyReturns(initial float64, equity []EquityPoint) []MonthlyReturn {
+	var months []MonthlyReturn
+	start := initial
+	for i, p := range equity {
+		month := p.Time.UTC().Format("2006-01")
+		if i+1 < len(equity) && equity[i+1].Time.UTC().Format("2006-01") == month {
+			continue
+		}
+		ret := 0.0
+		if start > 0 {
+			ret = (p.Equity - start) / start * 100
+		}
+		months = append(months, MonthlyReturn{Month: month, ReturnPct: ret})
+		start = p.Equity
+	}
+	return months
+}
+
+// WriteJSON 导出JSON报告
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化回测报告失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入回测报告失败: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML 导出独立的HTML报告（内联SVG图表，无需外部依赖）
+func (r *Report) WriteHTML(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建回测报告失败: %w", err)
+	}
+	defer f.Close()
+
+	if err := reportTemplate.Execute(f, r); err != nil {
+		return fmt.Errorf("生成HTML报告失败: %w", err)
+	}
+	return nil
+}
+
+// Export 在目录下导出 <name>.json 和 <name>.html，返回两个文件路径
+func Export(result *Result, dir, name string) (string, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	report := BuildReport(result)
+	jsonPath := filepath.Join(dir, name+".json")
+	htmlPath := filepath.Join(dir, name+".html")
+	if err := report.WriteJSON(jsonPath); err != nil {
+		return "", "", err
+	}
+	if err := report.WriteHTML(htmlPath); err != nil {
+		return "", "", err
+	}
+	return jsonPath, htmlPath, nil
+}
+
+// 图表尺寸
+const (
+	chartWidth  = 960.0
+	chartHeight = 240.0
+)
+
+// polyline 将序列缩放为SVG折线坐标
+func polyline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	var sb strings.Builder
+	for i, v := range values {
+		x := 0.0
+		if len(values) > 1 {
+			x = float64(i) / float64(len(values)-1) * chartWidth
+		}
+		y := chartHeight - (v-lo)/(hi-lo)*chartHeight
+		fmt.Fprintf(&sb, "%.1f,%.1f ", x, y)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// monthlyRow 月度收益表的一行（年 × 12个月）
+type monthlyRow struct {
+	Year   string
+	Months [12]*float64
+	Total  float64
+}
+
+// monthlyTable 月度收益按年分行
+func monthlyTable(monthly []MonthlyReturn) []monthlyRow {
+	var rows []monthlyRow
+	for _, m := range monthly {
+		t, err := time.Parse("2006-01", m.Month)
+		if err != nil {
+			continue
+		}
+		year := t.Format("2006")
+		if len(rows) == 0 || rows[len(rows)-1].Year != year {
+			rows = append(rows, monthlyRow{Year: year, Total: 1})
+		}
+		row := &rows[len(rows)-1]
+		ret := m.ReturnPct
+		row.Months[t.Month()-1] = &ret
+		row.Total *= 1 + ret/100
+	}
+	for i := range rows {
+		rows[i].Total = (rows[i].Total - 1) * 100
+	}
+	return rows
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"equityLine": func(points []EquityPoint) string {
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.Equity
+		}
+		return polyline(values)
+	},
+	"drawdownLine": func(points []DrawdownPoint) string {
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.Pct
+		}
+		return polyline(values)
+	},
+	"monthlyTable": monthlyTable,
+	"pct":          func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+	"num":          func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"price":        func(v float64) string { return fmt.Sprintf("%.4f", v) },
+	"ts":           func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+	"cls": func(v float64) string {
+		if v < 0 {
+			return "neg"
+		}
+		return "pos"
+	},
+	"width":  func() float64 { return chartWidth },
+	"height": func() float64 { return chartHeight },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>回测报告 {{.Symbol}} {{.Interval}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 24px; background: #0b0e11; color: #eaecef; }
+h1, h2 { font-weight: 600; }
+table { border-collapse: collapse; margin: 12px 0 24px; font-size: 13px; }
+th, td { border: 1px solid #2b3139; padding: 4px 8px; text-align: right; }
+th { background: #1e2329; }
+.pos { color: #0ecb81; } .neg { color: #f6465d; }
+svg { background: #161a1e; border: 1px solid #2b3139; }
+.summary td:first-child { text-align: left; color: #848e9c; }
+</style>
+</head>
+<body>
+<h1>回测报告 {{.Symbol}} {{.Interval}}</h1>
+<p>生成时间: {{ts .GeneratedAt}} UTC</p>
+
+<h2>统计</h2>
+<table class="summary">
+<tr><td>初始资金</td><td>{{num .Summary.InitialBalance}}</td></tr>
+<tr><td>最终净值</td><td>{{num .Summary.FinalEquity}}</td></tr>
+<tr><td>总收益</td><td class="{{cls .Summary.TotalReturnPct}}">{{pct .Summary.TotalReturnPct}}</td></tr>
+<tr><td>最大回撤</td><td class="neg">{{num .Summary.MaxDrawdownPct}}%</td></tr>
+<tr><td>交易次数</td><td>{{.Summary.TradeCount}}</td></tr>
+<tr><td>胜率</td><td>{{num .Summary.WinRate}}%</td></tr>
+<tr><td>盈亏比（Profit Factor）</td><td>{{num .Summary.ProfitFactor}}</td></tr>
+<tr><td>平均每笔盈亏</td><td class="{{cls .Summary.AvgTradePnL}}">{{num .Summary.AvgTradePnL}}</td></tr>
+<tr><td>手续费合计</td><td>{{num .Summary.TotalFees}}</td></tr>
+</table>
+
+<h2>净值曲线</h2>
+<svg width="{{width}}" height="{{height}}" viewBox="0 0 {{width}} {{height}}"><polyline fill="none" stroke="#f0b90b" stroke-width="1.5" points="{{equityLine .Equity}}"/></svg>
+
+<h2>回撤</h2>
+<svg width="{{width}}" height="{{height}}" viewBox="0 0 {{width}} {{height}}"><polyline fill="none" stroke="#f6465d" stroke-width="1.5" points="{{drawdownLine .Drawdown}}"/></svg>
+
+<h2>月度收益</h2>
+<table>
+<tr><th>年份</th><th>1月</th><th>2月</th><th>3月</th><th>4月</th><th>5月</th><th>6月</th><th>7月</th><th>8月</th><th>9月</th><th>10月</th><th>11月</th><th>12月</th><th>全年</th></tr>
+{{range monthlyTable .Monthly}}<tr><th>{{.Year}}</th>{{range .Months}}{{if .}}<td class="{{cls .}}">{{pct .}}</td>{{else}}<td></td>{{end}}{{end}}<td class="{{cls .Total}}">{{pct .Total}}</td></tr>
+{{end}}</table>
+
+<h2>交易明细</h2>
+<table>
+<tr><th>#</th><th>币种</th><th>方向</th><th>开仓时间</th><th>开仓价</th><th>平仓时间</th><th>平仓价</th><th>数量</th><th>盈亏</th><th>手续费</th><th>原因</th></tr>
+{{range $i, $t := .Trades}}<tr><td>{{$i}}</td><td>{{$t.Symbol}}</td><td>{{$t.Side}}</td><td>{{ts $t.EntryTime}}</td><td>{{price $t.EntryPrice}}</td><td>{{ts $t.ExitTime}}</td><td>{{price $t.ExitPrice}}</td><td>{{price $t.Quantity}}</td><td class="{{cls $t.PnL}}">{{num $t.PnL}}</td><td>{{num $t.Fee}}</td><td>{{$t.Reason}}</td></tr>
+{{end}}</table>
+{{with .MonteCarlo}}
+<h2>蒙特卡洛分析（{{.Method}}，{{.Runs}}次，仓位方案 {{.Sizing.Mode}}）</h2>
+<table>
+<tr><th></th><th>均值</th><th>P5</th><th>P25</th><th>P50</th><th>P75</th><th>P95</th><th>P99</th></tr>
+<tr><th>最大回撤%</th><td>{{num .MaxDrawdownPct.Mean}}</td><td>{{num .MaxDrawdownPct.P5}}</td><td>{{num .MaxDrawdownPct.P25}}</td><td>{{num .MaxDrawdownPct.P50}}</td><td>{{num .MaxDrawdownPct.P75}}</td><td>{{num .MaxDrawdownPct.P95}}</td><td>{{num .MaxDrawdownPct.P99}}</td></tr>
+<tr><th>最终净值</th><td>{{num .FinalEquity.Mean}}</td><td>{{num .FinalEquity.P5}}</td><td>{{num .FinalEquity.P25}}</td><td>{{num .FinalEquity.P50}}</td><td>{{num .FinalEquity.P75}}</td><td>{{num .FinalEquity.P95}}</td><td>{{num .FinalEquity.P99}}</td></tr>
+</table>
+<p>破产概率（回撤 ≥ {{num .RuinDrawdownPct}}%）: {{num .RuinProbability}}%</p>
+{{end}}
+{{if .Rejected}}
+<h2>被拒绝的决策</h2>
+<ul>{{range .Rejected}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,349 @@
+package basis
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/market"
+	"nofx/trader"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FundingHistory 已保存的资金费率历史
+type FundingHistory interface {
+	GetFundingRates(symbol string, since time.Time) ([]market.FundingRate, error)
+}
+
+// Config 期现套利配置
+type Config struct {
+	TraderID        string  `json:"trader_id"`          // 提供交易所账户的交易员（需支持现货下单）
+	Symbol          string  `json:"symbol"`             // 交易对，如 BTCUSDT（现货与永续同名）
+	NotionalUSD     float64 `json:"notional_usd"`       // 每条腿的名义价值
+	EntryAnnualPct  float64 `json:"entry_annual_pct"`   // 年化基差高于该值时建仓（默认15）
+	ExitAnnualPct   float64 `json:"exit_annual_pct"`    // 年化基差低于该值时平仓（默认3）
+	Leverage        int     `json:"leverage"`           // 永续空单杠杆（默认2）
+	IntervalSeconds int     `json:"interval_seconds"`   // 检查间隔（默认300秒）
+	MaxHoldHours    int     `json:"max_hold_hours"`     // 最长持有时间（0=不限）
+	MinAvgAnnualPct float64 `json:"min_avg_annual_pct"` // 近期平均资金费率年化低于该值时不建仓（0=不检查，避免追单期费率尖峰）
+	AvgWindowHours  int     `json:"avg_window_hours"`   // 平均资金费率统计窗口（默认72小时）
+}
+
+// ParseConfigs 解析期现套利配置（JSON数组）
+func ParseConfigs(s string) ([]Config, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var configs []Config
+	if err := json.Unmarshal([]byte(s), &configs); err != nil {
+		return nil, fmt.Errorf("解析期现套利配置失败: %w", err)
+	}
+	for i := range configs {
+		c := &configs[i]
+		c.Symbol = market.Normalize(c.Symbol)
+		if c.TraderID == "" || c.NotionalUSD <= 0 {
+			return nil, fmt.Errorf("期现套利配置 #%d 缺少 trader_id 或 notional_usd", i+1)
+		}
+		if c.EntryAnnualPct == 0 {
+			c.EntryAnnualPct = 15
+		}
+		if c.ExitAnnualPct == 0 {
+			c.ExitAnnualPct = 3
+		}
+		if c.ExitAnnualPct >= c.EntryAnnualPct {
+			return nil, fmt.Errorf("期现套利配置 #%d 的 exit_annual_pct 必须小于 entry_annual_pct", i+1)
+		}
+		if c.Leverage <= 0 {
+			c.Leverage = 2
+		}
+		if c.IntervalSeconds <= 0 {
+			c.IntervalSeconds = 300
+		}
+		if c.AvgWindowHours <= 0 {
+			c.AvgWindowHours = 72
+		}
+	}
+	return configs, nil
+}
+
+// Snapshot 一次基差观测
+type Snapshot struct {
+	Time          time.Time `json:"time"`
+	SpotPrice     float64   `json:"spot_price"`
+	PerpPrice     float64   `json:"perp_price"`
+	PremiumPct    float64   `json:"premium_pct"`    // (永续-现货)/现货
+	FundingRate   float64   `json:"funding_rate"`   // 当期资金费率
+	AnnualizedPct float64   `json:"annualized_pct"` // 年化基差（资金费率 × 每年结算次数）
+	AvgAnnualPct  float64   `json:"avg_annual_pct"` // 统计窗口内平均资金费率年化（无历史数据时为0）
+	AvgSamples    int       `json:"avg_samples"`    // 平均值使用的结算次数
+}
+
+// Trader 期现套利的下单通道（由交易员的 trader.StrategyTrader 提供，
+// 下单经过模拟交易、风控检查及下单意图日志，永续空单归属于套利策略）
+type Trader interface {
+	trader.SpotTrader
+	IsDryRun() bool
+	SupportsSpot() bool
+	GetMarketPrice(symbol string) (float64, error)
+	GetPositions() ([]map[string]interface{}, error)
+	OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	CloseShort(symbol string, quantity float64) (map[string]interface{}, error)
+}
+
+var _ Trader = (*trader.StrategyTrader)(nil)
+
+// Strategy 期现套利：年化基差足够高时买入现货并做空等量永续收取资金费，
+// 基差收敛或到期后同时平掉两条腿
+// 永续空单归属于套利策略，该交易员的AI策略不会平掉或加仓
+type Strategy struct {
+	config  Config
+	perp    Trader
+	spot    trader.SpotTrader
+	history FundingHistory
+
+	mu       sync.Mutex
+	open     bool
+	spotQty  float64
+	perpQty  float64
+	entry    Snapshot
+	openedAt time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewStrategy 创建期现套利策略（交易所需支持现货下单）
+func NewStrategy(config Config, t Trader) (*Strategy, error) {
+	if !t.SupportsSpot() {
+		return nil, fmt.Errorf("交易员 %s 的交易所不支持现货下单", config.TraderID)
+	}
+	return &Strategy{
+		config: config,
+		perp:   t,
+		spot:   t,
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+// SetFundingHistory 设置资金费率历史，用于建仓前检查近期平均费率及平仓时统计累计收取的资金费
+func (s *Strategy) SetFundingHistory(h FundingHistory) {
+	s.history = h
+}
+
+// fundingStats 统计since之后的资金费率，未设置历史时返回false
+func (s *Strategy) fundingStats(since time.Time) (market.FundingStats, bool) {
+	if s.history == nil {
+		return market.FundingStats{}, false
+	}
+	rates, err := s.history.GetFundingRates(s.config.Symbol, since)
+	if err != nil {
+		log.Printf("⚠️  [期现套利 %s] 获取资金费率历史失败: %v", s.config.Symbol, err)
+		return market.FundingStats{}, false
+	}
+	return market.ComputeFundingStats(s.config.Symbol, rates), true
+}
+
+// Start 恢复已有仓位并启动定期检查
+func (s *Strategy) Start() {
+	if err := s.recover(); err != nil {
+		log.Printf("⚠️  [期现套利 %s] 恢复仓位失败: %v", s.config.Symbol, err)
+	}
+
+	interval := time.Duration(s.config.IntervalSeconds) * time.Second
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.Tick(time.Now()); err != nil {
+				log.Printf("⚠️  [期现套利 %s] %v", s.config.Symbol, err)
+			}
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("🔁 期现套利已启动: %s %.0f USDT（年化 ≥%.1f%% 建仓，≤%.1f%% 平仓，每 %s 检查一次）",
+		s.config.Symbol, s.config.NotionalUSD, s.config.EntryAnnualPct, s.config.ExitAnnualPct, interval)
+}
+
+// Stop 停止检查（保留仓位，重启后自动恢复）
+func (s *Strategy) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.wg.Wait()
+}
+
+// recover 根据交易所已有的永续空单恢复套利状态（假定现货腿数量与空单相同）
+func (s *Strategy) recover() error {
+	short, err := s.perpShort()
+	if err != nil {
+		return err
+	}
+	if short <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.open, s.spotQty, s.perpQty, s.openedAt = true, short, short, time.Now()
+	log.Printf("🔁 [期现套利 %s] 发现已有永续空单 %.6f，按已建仓继续管理", s.config.Symbol, short)
+	return nil
+}
+
+// Observe 获取当前基差
+func (s *Strategy) Observe(now time.Time) (*Snapshot, error) {
+	spotPrice, err := s.spot.GetSpotPrice(s.config.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	perpPrice, err := s.perp.GetMarketPrice(s.config.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取永续价格失败: %w", err)
+	}
+	funding, err := market.GetFunding(s.config.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+	if spotPrice <= 0 {
+		return nil, fmt.Errorf("现货价格无效: %v", spotPrice)
+	}
+	snap := &Snapshot{
+		Time:          now,
+		SpotPrice:     spotPrice,
+		PerpPrice:     perpPrice,
+		PremiumPct:    (perpPrice - spotPrice) / spotPrice * 100,
+		FundingRate:   funding.Rate,
+		AnnualizedPct: funding.Rate * market.FundingPeriodsPerYear * 100,
+	}
+	if stats, ok := s.fundingStats(now.Add(-time.Duration(s.config.AvgWindowHours) * time.Hour)); ok {
+		snap.AvgAnnualPct, snap.AvgSamples = stats.AnnualizedPct, stats.Samples
+	}
+	return snap, nil
+}
+
+// Tick 检查基差并按需建仓、平仓或修复单腿仓位
+func (s *Strategy) Tick(now time.Time) error {
+	snap, err := s.Observe(now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.open {
+		if snap.AnnualizedPct < s.config.EntryAnnualPct || snap.PremiumPct < 0 {
+			return nil
+		}
+		if s.config.MinAvgAnnualPct > 0 && snap.AvgSamples > 0 && snap.AvgAnnualPct < s.config.MinAvgAnnualPct {
+			log.Printf("🔁 [期现套利 %s] 当期年化 %.2f%% 达标，但近 %dh 平均年化 %.2f%% < %.2f%%，暂不建仓",
+				s.config.Symbol, snap.AnnualizedPct, s.config.AvgWindowHours, snap.AvgAnnualPct, s.config.MinAvgAnnualPct)
+			return nil
+		}
+		return s.enter(snap)
+	}
+
+	// 上次平仓时永续腿已平、现货卖出失败：立即重试卖出现货
+	if s.perpQty == 0 && s.spotQty > 0 {
+		log.Printf("⚠️  [期现套利 %s] 永续腿已平仓，重试卖出现货 %.6f", s.config.Symbol, s.spotQty)
+		return s.exit(snap, "重试卖出现货")
+	}
+
+	// 永续腿被强平或人工平仓时，卖出剩余现货，避免留下裸多头
+	short, err := s.perpShort()
+	if err != nil {
+		return err
+	}
+	if short < s.perpQty*0.5 {
+		log.Printf("⚠️  [期现套利 %s] 永续空单 %.6f 少于预期 %.6f，平掉现货腿", s.config.Symbol, short, s.perpQty)
+		s.perpQty = short
+		return s.exit(snap, "永续腿丢失")
+	}
+
+	log.Printf("🔁 [期现套利 %s] 年化 %.2f%% 溢价 %.3f%%（建仓时 %.2f%% / %.3f%%）",
+		s.config.Symbol, snap.AnnualizedPct, snap.PremiumPct, s.entry.AnnualizedPct, s.entry.PremiumPct)
+	switch {
+	case snap.AnnualizedPct <= s.config.ExitAnnualPct:
+		return s.exit(snap, "基差收敛")
+	case s.config.MaxHoldHours > 0 && now.Sub(s.openedAt) >= time.Duration(s.config.MaxHoldHours)*time.Hour:
+		return s.exit(snap, "达到最长持有时间")
+	}
+	return nil
+}
+
+// enter 买入现货并做空等量永续；空单失败时卖回现货
+func (s *Strategy) enter(snap *Snapshot) error {
+	log.Printf("🔁 [期现套利 %s] 年化基差 %.2f%% ≥ %.2f%%，建仓 %.0f USDT",
+		s.config.Symbol, snap.AnnualizedPct, s.config.EntryAnnualPct, s.config.NotionalUSD)
+	if s.perp.IsDryRun() {
+		log.Printf("🧪 [期现套利 %s] 模拟交易，WOULD 买入现货 %.0f USDT 并做空等量永续（%dx），未下单",
+			s.config.Symbol, s.config.NotionalUSD, s.config.Leverage)
+		return nil
+	}
+
+	fill, err := s.spot.SpotBuy(s.config.Symbol, s.config.NotionalUSD)
+	if err != nil {
+		return err
+	}
+	if _, err := s.perp.OpenShort(s.config.Symbol, fill.Quantity, s.config.Leverage); err != nil {
+		if _, sellErr := s.spot.SpotSell(s.config.Symbol, fill.Quantity); sellErr != nil {
+			return fmt.Errorf("永续开空失败: %v；回滚现货也失败，请人工处理: %w", err, sellErr)
+		}
+		return fmt.Errorf("永续开空失败，已卖回现货: %w", err)
+	}
+
+	s.open, s.spotQty, s.perpQty = true, fill.Quantity, fill.Quantity
+	s.entry, s.openedAt = *snap, snap.Time
+	return nil
+}
+
+// exit 平掉永续空单并卖出现货
+func (s *Strategy) exit(snap *Snapshot, reason string) error {
+	log.Printf("🔁 [期现套利 %s] %s，平仓（年化 %.2f%%，溢价 %.3f%% → %.3f%%）",
+		s.config.Symbol, reason, snap.AnnualizedPct, s.entry.PremiumPct, snap.PremiumPct)
+	if stats, ok := s.fundingStats(s.openedAt); ok && stats.Samples > 0 {
+		log.Printf("💸 [期现套利 %s] 持仓期间 %d 次结算，累计收取资金费 %.4f%%（约 %.2f USDT），平均年化 %.2f%%",
+			s.config.Symbol, stats.Samples, stats.CarryPct, stats.CarryPct/100*s.config.NotionalUSD, stats.AnnualizedPct)
+	}
+	if s.perp.IsDryRun() {
+		log.Printf("🧪 [期现套利 %s] 模拟交易，WOULD 平永续空单 %.6f 并卖出现货 %.6f，未下单", s.config.Symbol, s.perpQty, s.spotQty)
+		return nil
+	}
+
+	if s.perpQty > 0 {
+		if _, err := s.perp.CloseShort(s.config.Symbol, s.perpQty); err != nil {
+			return fmt.Errorf("平永续空单失败: %w", err)
+		}
+		s.perpQty = 0
+	}
+	if s.spotQty > 0 {
+		if _, err := s.spot.SpotSell(s.config.Symbol, s.spotQty); err != nil {
+			return fmt.Errorf("卖出现货失败: %w", err)
+		}
+		s.spotQty = 0
+	}
+	s.open = false
+	return nil
+}
+
+// perpShort 当前永续空单数量
+func (s *Strategy) perpShort() (float64, error) {
+	positions, err := s.perp.GetPositions()
+	if err != nil {
+		return 0, fmt.Errorf("获取持仓失败: %w", err)
+	}
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		if symbol == s.config.Symbol && side == "short" {
+			amt, _ := pos["positionAmt"].(float64)
+			return math.Abs(amt), nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,257 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Window 禁止开仓的时间窗口（如FOMC、CPI公布前后）
+type Window struct {
+	Name      string    `json:"name"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	ReducePct float64   `json:"reduce_pct,omitempty"` // 进入窗口时减仓的百分比（0=不减仓）
+}
+
+// Contains 时间是否处于窗口内
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Key 窗口唯一标识（用于记录是否已减仓）
+func (w Window) Key() string {
+	return w.Name + "@" + w.Start.UTC().Format(time.RFC3339)
+}
+
+// BlackoutConfig 禁止开仓窗口配置
+type BlackoutConfig struct {
+	Windows   []Window      // 静态窗口
+	SourceURL string        // 经济日历API（JSON数组），为空则只使用静态窗口
+	Countries []string      // 只关注这些国家/货币的事件（如 USD），为空表示全部
+	Padding   time.Duration // 日历事件前后的禁止开仓时长
+	ReducePct float64       // 日历事件窗口的减仓百分比
+	Refresh   time.Duration // 日历刷新间隔
+	Timeout   time.Duration
+}
+
+var (
+	mu             sync.RWMutex
+	blackoutConfig = BlackoutConfig{
+		Countries: []string{"USD"},
+		Padding:   30 * time.Minute,
+		Refresh:   time.Hour,
+		Timeout:   30 * time.Second,
+	}
+
+	// 日历事件缓存（后台刷新，下单路径只读取缓存）
+	calendarWindows    []Window
+	calendarFetchedAt  time.Time
+	calendarRefreshing bool
+	calendarVersion    int // 配置变更时递增，丢弃按旧配置请求到的结果
+)
+
+// SetWindows 设置静态禁止开仓窗口
+func SetWindows(windows []Window) {
+	mu.Lock()
+	defer mu.Unlock()
+	blackoutConfig.Windows = windows
+}
+
+// SetCalendarSource 设置经济日历API及关注的国家/货币
+func SetCalendarSource(url string, countries []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	blackoutConfig.SourceURL = strings.TrimSpace(url)
+	blackoutConfig.Countries = countries
+	resetCalendar()
+}
+
+// SetCalendarWindow 设置日历事件前后的禁止开仓时长及减仓比例
+func SetCalendarWindow(padding time.Duration, reducePct float64) {
+	mu.Lock()
+	defer mu.Unlock()
+	blackoutConfig.Padding = padding
+	blackoutConfig.ReducePct = reducePct
+	resetCalendar()
+}
+
+// resetCalendar 配置变更后立即在后台重新获取日历（调用方持有锁）
+func resetCalendar() {
+	calendarFetchedAt = time.Time{}
+	calendarVersion++
+	refreshCalendarLocked()
+}
+
+// ParseWindows 解析JSON格式的静态窗口，如 [{"name":"FOMC","start":"2025-01-29T18:30:00Z","end":"2025-01-29T20:00:00Z","reduce_pct":50}]
+func ParseWindows(raw string) ([]Window, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var windows []Window
+	if err := json.Unmarshal([]byte(raw), &windows); err != nil {
+		return nil, fmt.Errorf("解析禁止开仓窗口失败: %w", err)
+	}
+	for _, w := range windows {
+		if !w.End.After(w.Start) {
+			return nil, fmt.Errorf("禁止开仓窗口 %s 的结束时间必须晚于开始时间", w.Name)
+		}
+		if w.ReducePct < 0 || w.ReducePct > 100 {
+			return nil, fmt.Errorf("禁止开仓窗口 %s 的减仓比例必须在0-100之间", w.Name)
+		}
+	}
+	return windows, nil
+}
+
+// Active 获取当前生效的禁止开仓窗口（多个窗口重叠时返回减仓比例最大的）
+func Active(now time.Time) (Window, bool) {
+	mu.RLock()
+	windows := append([]Window{}, blackoutConfig.Windows...)
+	mu.RUnlock()
+	windows = append(windows, calendarEvents()...)
+
+	var active Window
+	found := false
+	for _, w := range windows {
+		if w.Contains(now) && (!found || w.ReducePct > active.ReducePct) {
+			active = w
+			found = true
+		}
+	}
+	return active, found
+}
+
+// calendarEvents 获取缓存的日历事件窗口，缓存过期时在后台刷新（不阻塞下单，刷新完成前沿用上次结果）
+func calendarEvents() []Window {
+	mu.Lock()
+	defer mu.Unlock()
+	if blackoutConfig.SourceURL == "" {
+		return nil
+	}
+	refreshCalendarLocked()
+	return calendarWindows
+}
+
+// refreshCalendarLocked 缓存过期且没有进行中的刷新时，启动后台刷新（调用方持有锁）
+func refreshCalendarLocked() {
+	cfg := blackoutConfig
+	if cfg.SourceURL == "" || calendarRefreshing || time.Since(calendarFetchedAt) < cfg.Refresh {
+		return
+	}
+	calendarRefreshing = true
+	go refreshCalendar(cfg, calendarVersion)
+}
+
+// refreshCalendar 请求经济日历并更新缓存（请求失败时沿用上次结果）
+func refreshCalendar(cfg BlackoutConfig, version int) {
+	windows, err := fetchCalendar(cfg)
+	mu.Lock()
+	defer mu.Unlock()
+	calendarRefreshing = false
+	if version != calendarVersion {
+		// 请求期间配置已变更，按新配置重新获取
+		refreshCalendarLocked()
+		return
+	}
+	calendarFetchedAt = time.Now()
+	if err != nil {
+		log.Printf("⚠️  获取经济日历失败: %v，沿用上次数据（%d个事件）", err, len(calendarWindows))
+		return
+	}
+	calendarWindows = windows
+	log.Printf("📅 已更新经济日历（%d个重要事件）", len(windows))
+}
+
+// calendarEvent 经济日历事件（兼容 ForexFactory 等常见JSON格式）
+type calendarEvent struct {
+	Title   string `json:"title"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+	Date    string `json:"date"`
+	Time    string `json:"time"`
+	Impact  string `json:"impact"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// fetchCalendar 请求经济日历，转换为禁止开仓窗口
+func fetchCalendar(cfg BlackoutConfig) ([]Window, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	resp, err := client.Get(cfg.SourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("请求经济日历API失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var events []calendarEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %w", err)
+	}
+
+	var windows []Window
+	for _, e := range events {
+		// 只关注高影响事件（未提供impact字段时视为重要）
+		if e.Impact != "" && !strings.EqualFold(e.Impact, "high") {
+			continue
+		}
+		if !matchCountry(e.Country, cfg.Countries) {
+			continue
+		}
+
+		name := e.Title
+		if name == "" {
+			name = e.Name
+		}
+		w := Window{Name: name, ReducePct: cfg.ReducePct}
+		if start, end := parseTime(e.Start), parseTime(e.End); !start.IsZero() && end.After(start) {
+			w.Start, w.End = start, end
+		} else {
+			at := parseTime(e.Date)
+			if at.IsZero() {
+				at = parseTime(e.Time)
+			}
+			if at.IsZero() {
+				continue
+			}
+			w.Start, w.End = at.Add(-cfg.Padding), at.Add(cfg.Padding)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// matchCountry 事件是否属于关注的国家/货币
+func matchCountry(country string, countries []string) bool {
+	if len(countries) == 0 || country == "" {
+		return true
+	}
+	for _, c := range countries {
+		if strings.EqualFold(strings.TrimSpace(c), country) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime 解析RFC3339时间，失败返回零值
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,110 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 内置时区数据（Windows及精简镜像没有系统时区库）
+)
+
+// 交易日在 dayLocation 的零点切换：日盈亏、每日AI预算及业绩日报都按交易日统计
+var (
+	dayMu       sync.RWMutex
+	dayLocation = time.UTC
+)
+
+// SetDayLocation 设置交易日切换的时区（nil为UTC）
+func SetDayLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	dayMu.Lock()
+	defer dayMu.Unlock()
+	dayLocation = loc
+}
+
+// DayLocation 交易日切换的时区
+func DayLocation() *time.Location {
+	dayMu.RLock()
+	defer dayMu.RUnlock()
+	return dayLocation
+}
+
+// LoadDayLocation 解析交易日切换时区：空/UTC、IANA时区名（如 Asia/Shanghai）或固定偏移（如 +08:00、UTC-5）
+func LoadDayLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "UTC") || strings.EqualFold(name, "Z") {
+		return time.UTC, nil
+	}
+	offset := strings.TrimPrefix(strings.TrimPrefix(name, "UTC"), "GMT")
+	if offset != "" && (offset[0] == '+' || offset[0] == '-') {
+		seconds, err := parseOffset(offset)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区偏移 %s: %w", name, err)
+		}
+		return time.FixedZone("UTC"+offset, seconds), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时区 %s: %w", name, err)
+	}
+	return loc, nil
+}
+
+// parseOffset 解析 +8、+08、+0800、+08:00 形式的偏移（秒）
+func parseOffset(s string) (int, error) {
+	sign := 1
+	if s[0] == '-' {
+		sign = -1
+	}
+	s = strings.ReplaceAll(s[1:], ":", "")
+	if len(s) > 2 && len(s) != 4 {
+		return 0, fmt.Errorf("格式应为 ±HH:MM")
+	}
+	hours, minutes := s, "0"
+	if len(s) == 4 {
+		hours, minutes = s[:2], s[2:]
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h > 14 {
+		return 0, fmt.Errorf("小时应为 0-14")
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m > 59 {
+		return 0, fmt.Errorf("分钟应为 0-59")
+	}
+	return sign * (h*3600 + m*60), nil
+}
+
+// DayStart t所在交易日的起点
+func DayStart(t time.Time) time.Time {
+	t = t.In(DayLocation())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// NextDayStart t之后下一个交易日的起点（按日历日计算，夏令时切换当天不是24小时）
+func NextDayStart(t time.Time) time.Time {
+	return DayStart(t).AddDate(0, 0, 1)
+}
+
+// DayKey t所在交易日（2006-01-02）
+func DayKey(t time.Time) string {
+	return t.In(DayLocation()).Format("2006-01-02")
+}
+
+// SameDay 两个时间是否属于同一交易日
+func SameDay(a, b time.Time) bool {
+	return DayKey(a) == DayKey(b)
+}
+
+// NextAt now之后下一个交易日时区的 hour:00
+func NextAt(now time.Time, hour int) time.Time {
+	start := DayStart(now)
+	next := time.Date(start.Year(), start.Month(), start.Day(), hour, 0, 0, 0, start.Location())
+	if !next.After(now) {
+		next = time.Date(start.Year(), start.Month(), start.Day()+1, hour, 0, 0, 0, start.Location())
+	}
+	return next
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"nofx/config"
+	"nofx/logger"
+	"nofx/manager"
+	"nofx/trader"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const closeAllUsage = `用法:
+  nofx close-all [-trader 交易员ID,...] [-symbols BTCUSDT,...] [-side long|short] [-db 数据库] [-yes]
+
+按数据库中的交易员配置连接交易所，并发平掉全部或按币种/方向过滤的持仓，并清理已平完币种残留的止盈止损单。
+不带 -yes 时只列出将要平掉的持仓。服务运行中时交易员可能在平仓后重新开仓，请先停止服务，
+或改用 POST /api/close-all、Telegram /closeall 由运行中的实例平仓`
+
+// runCloseAllCommand 执行 nofx close-all 子命令，全部持仓平掉时返回0
+func runCloseAllCommand(args []string) int {
+	fs := flag.NewFlagSet("close-all", flag.ContinueOnError)
+	traderIDs := fs.String("trader", "", "只平这些交易员的持仓（逗号分隔，默认全部交易员）")
+	symbols := fs.String("symbols", "", "只平这些币种（逗号分隔，默认全部币种）")
+	side := fs.String("side", "", "只平该方向（long/short，默认两个方向）")
+	dbPath := fs.String("db", defaultDBPath(envOrDefault("NOFX_DATA_DIR", ".")), "配置数据库")
+	yes := fs.Bool("yes", false, "确认执行平仓")
+	fs.Usage = func() { fmt.Println(closeAllUsage) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	filter, err := trader.CloseFilter{Symbols: splitList(*symbols), Side: *side}.Normalize()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+
+	database, err := config.NewDatabase(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 打开配置数据库失败: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	logger.SetLogRoot(filepath.Join(envOrDefault("NOFX_DATA_DIR", "."), "decision_logs"))
+	tm := manager.NewTraderManager()
+	tm.SetOrderJournal(manager.NewOrderJournal(database))
+	if err := tm.LoadTradersFromDatabase(database); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 加载交易员失败: %v\n", err)
+		return 1
+	}
+
+	ids := splitList(*traderIDs)
+	if !*yes {
+		return previewCloseAll(tm, ids, filter)
+	}
+	report, err := tm.CloseAllPositions(ids, filter, "命令行批量平仓")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Println(manager.FormatCloseAllReport(report))
+	if report.Failed > 0 || len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// previewCloseAll 列出将要平掉的持仓
+func previewCloseAll(tm *manager.TraderManager, ids []string, filter trader.CloseFilter) int {
+	if len(ids) == 0 {
+		ids = tm.GetTraderIDs()
+	}
+	count := 0
+	for _, id := range ids {
+		at, err := tm.GetTrader(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		positions, err := at.GetPositions()
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", id, err)
+			continue
+		}
+		for _, pos := range positions {
+			symbol, _ := pos["symbol"].(string)
+			side, _ := pos["side"].(string)
+			if !filter.Match(symbol, side) {
+				continue
+			}
+			quantity, _ := pos["quantity"].(float64)
+			markPrice, _ := pos["mark_price"].(float64)
+			pnl, _ := pos["unrealized_pnl"].(float64)
+			fmt.Printf("  %s %s %s 数量 %.4f 名义价值 %.2f 盈亏 %+.2f\n", id, symbol, side, quantity, math.Abs(quantity)*markPrice, pnl)
+			count++
+		}
+	}
+	if count == 0 {
+		fmt.Println("没有符合条件的持仓")
+		return 0
+	}
+	fmt.Printf("\n共 %d 个持仓（%s），加上 -yes 执行平仓\n", count, filter)
+	return 1
+}
+
+// splitList 解析逗号分隔的参数
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
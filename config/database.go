@@ -17,17 +17,30 @@ import (
 
 // Database 配置数据库
 type Database struct {
-	db *sql.DB
+	db             *sql.DB
+	dialect        dialect
+	booleanColumns map[string]bool
 }
 
 // NewDatabase 创建配置数据库
-func NewDatabase(dbPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+// dsn 为SQLite文件路径，或 postgres:// / mysql:// 地址（多实例部署时共用同一个中心数据库）
+func NewDatabase(dsn string) (*Database, error) {
+	dialect, driver, driverDSN, err := openParams(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driver, driverDSN)
 	if err != nil {
 		return nil, fmt.Errorf("打开数据库失败: %w", err)
 	}
+	if dialect != dialectSQLite {
+		if err := db.Ping(); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("连接数据库失败: %w", err)
+		}
+	}
 
-	database := &Database{db: db}
+	database := &Database{db: db, dialect: dialect, booleanColumns: map[string]bool{}}
 	if err := database.createTables(); err != nil {
 		return nil, fmt.Errorf("创建表失败: %w", err)
 	}
@@ -39,6 +52,21 @@ func NewDatabase(dbPath string) (*Database, error) {
 	return database, nil
 }
 
+// exec 按方言转换后执行SQL
+func (d *Database) exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.db.Exec(d.translate(query), args...)
+}
+
+// query 按方言转换后查询多行
+func (d *Database) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.Query(d.translate(query), args...)
+}
+
+// queryRow 按方言转换后查询单行
+func (d *Database) queryRow(query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRow(d.translate(query), args...)
+}
+
 // createTables 创建数据库表
 func (d *Database) createTables() error {
 	queries := []string{
@@ -57,7 +85,7 @@ func (d *Database) createTables() error {
 
 		// 交易所配置表
 		`CREATE TABLE IF NOT EXISTS exchanges (
-			id TEXT PRIMARY KEY,
+			id TEXT NOT NULL,
 			user_id TEXT NOT NULL DEFAULT 'default',
 			name TEXT NOT NULL,
 			type TEXT NOT NULL, -- 'cex' or 'dex'
@@ -73,6 +101,7 @@ func (d *Database) createTables() error {
 			aster_private_key TEXT DEFAULT '',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id, user_id),
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 
@@ -121,6 +150,83 @@ func (d *Database) createTables() error {
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
 
+		// 定投计划表（按cron表达式定期以固定名义价值开仓）
+		`CREATE TABLE IF NOT EXISTS recurring_orders (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			trader_id TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			side TEXT NOT NULL,
+			notional_usd REAL NOT NULL,
+			leverage INTEGER DEFAULT 1,
+			schedule TEXT NOT NULL,
+			enabled BOOLEAN DEFAULT 1,
+			run_count INTEGER DEFAULT 0,
+			last_run_at DATETIME,
+			last_status TEXT DEFAULT '',
+			last_message TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (trader_id) REFERENCES traders(id) ON DELETE CASCADE
+		)`,
+
+		// 开仓执行质量记录表（成交率、耗时、滑点、maker占比，用于比较执行算法）
+		`CREATE TABLE IF NOT EXISTS execution_records (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trader_id TEXT NOT NULL,
+			exchange TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			side TEXT NOT NULL,
+			policy TEXT NOT NULL,
+			requested_qty REAL NOT NULL,
+			filled_qty REAL NOT NULL,
+			maker_qty REAL DEFAULT 0,
+			arrival_price REAL DEFAULT 0,
+			avg_price REAL DEFAULT 0,
+			slippage_bps REAL DEFAULT 0,
+			duration_ms INTEGER DEFAULT 0,
+			error_message TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// 下单意图日志（提交订单前写入，重启后按clientOrderId对账未确认的订单）
+		`CREATE TABLE IF NOT EXISTS order_intents (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trader_id TEXT NOT NULL,
+			exchange TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			action TEXT NOT NULL,
+			quantity REAL DEFAULT 0,
+			client_order_id VARCHAR(64) NOT NULL UNIQUE,
+			status TEXT NOT NULL,
+			order_id BIGINT DEFAULT 0,
+			error_message TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// 历史持仓复盘笔记（文字、截图链接、自定义上下文）
+		`CREATE TABLE IF NOT EXISTS trade_notes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trader_id TEXT NOT NULL,
+			position_id TEXT NOT NULL,
+			author TEXT DEFAULT '',
+			note TEXT NOT NULL,
+			image_url TEXT DEFAULT '',
+			attachment TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// 资金费率历史（id 为 交易对:结算毫秒时间戳）
+		`CREATE TABLE IF NOT EXISTS funding_rates (
+			id TEXT PRIMARY KEY,
+			symbol TEXT NOT NULL,
+			funding_time DATETIME NOT NULL,
+			rate REAL NOT NULL,
+			mark_price REAL DEFAULT 0
+		)`,
+
 		// 系统配置表
 		`CREATE TABLE IF NOT EXISTS system_config (
 			key TEXT PRIMARY KEY,
@@ -164,10 +270,20 @@ func (d *Database) createTables() error {
 			BEGIN
 				UPDATE system_config SET updated_at = CURRENT_TIMESTAMP WHERE key = NEW.key;
 			END`,
+
+		`CREATE TRIGGER IF NOT EXISTS update_recurring_orders_updated_at
+			AFTER UPDATE ON recurring_orders
+			BEGIN
+				UPDATE recurring_orders SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+			END`,
 	}
 
 	for _, query := range queries {
-		if _, err := d.db.Exec(query); err != nil {
+		// 触发器为SQLite语法，其他数据库不创建（MySQL由 ON UPDATE CURRENT_TIMESTAMP 维护 updated_at）
+		if d.dialect != dialectSQLite && strings.HasPrefix(query, "CREATE TRIGGER") {
+			continue
+		}
+		if _, err := d.exec(query); err != nil {
 			return fmt.Errorf("执行SQL失败 [%s]: %w", query, err)
 		}
 	}
@@ -180,29 +296,54 @@ func (d *Database) createTables() error {
 		`ALTER TABLE exchanges ADD COLUMN aster_private_key TEXT DEFAULT ''`,
 		`ALTER TABLE traders ADD COLUMN custom_prompt TEXT DEFAULT ''`,
 		`ALTER TABLE traders ADD COLUMN override_base_prompt BOOLEAN DEFAULT 0`,
-		`ALTER TABLE traders ADD COLUMN is_cross_margin BOOLEAN DEFAULT 1`,             // 默认为全仓模式
-		`ALTER TABLE traders ADD COLUMN use_default_coins BOOLEAN DEFAULT 1`,           // 默认使用默认币种
-		`ALTER TABLE traders ADD COLUMN custom_coins TEXT DEFAULT ''`,                  // 自定义币种列表（JSON格式）
-		`ALTER TABLE traders ADD COLUMN btc_eth_leverage INTEGER DEFAULT 5`,            // BTC/ETH杠杆倍数
-		`ALTER TABLE traders ADD COLUMN altcoin_leverage INTEGER DEFAULT 5`,            // 山寨币杠杆倍数
-		`ALTER TABLE traders ADD COLUMN trading_symbols TEXT DEFAULT ''`,               // 交易币种，逗号分隔
-		`ALTER TABLE traders ADD COLUMN use_coin_pool BOOLEAN DEFAULT 0`,               // 是否使用COIN POOL信号源
-		`ALTER TABLE traders ADD COLUMN use_oi_top BOOLEAN DEFAULT 0`,                  // 是否使用OI TOP信号源
-		`ALTER TABLE traders ADD COLUMN use_inside_coins BOOLEAN DEFAULT 0`,            // 是否使用内置AI评分信号源
-		`ALTER TABLE traders ADD COLUMN system_prompt_template TEXT DEFAULT 'default'`, // 系统提示词模板名称
-		`ALTER TABLE ai_models ADD COLUMN custom_api_url TEXT DEFAULT ''`,              // 自定义API地址
-		`ALTER TABLE ai_models ADD COLUMN custom_model_name TEXT DEFAULT ''`,           // 自定义模型名称
+		`ALTER TABLE traders ADD COLUMN is_cross_margin BOOLEAN DEFAULT 1`,                // 默认为全仓模式
+		`ALTER TABLE traders ADD COLUMN use_default_coins BOOLEAN DEFAULT 1`,              // 默认使用默认币种
+		`ALTER TABLE traders ADD COLUMN custom_coins TEXT DEFAULT ''`,                     // 自定义币种列表（JSON格式）
+		`ALTER TABLE traders ADD COLUMN btc_eth_leverage INTEGER DEFAULT 5`,               // BTC/ETH杠杆倍数
+		`ALTER TABLE traders ADD COLUMN altcoin_leverage INTEGER DEFAULT 5`,               // 山寨币杠杆倍数
+		`ALTER TABLE traders ADD COLUMN trading_symbols TEXT DEFAULT ''`,                  // 交易币种，逗号分隔
+		`ALTER TABLE traders ADD COLUMN use_coin_pool BOOLEAN DEFAULT 0`,                  // 是否使用COIN POOL信号源
+		`ALTER TABLE traders ADD COLUMN use_oi_top BOOLEAN DEFAULT 0`,                     // 是否使用OI TOP信号源
+		`ALTER TABLE traders ADD COLUMN use_inside_coins BOOLEAN DEFAULT 0`,               // 是否使用内置AI评分信号源
+		`ALTER TABLE traders ADD COLUMN system_prompt_template TEXT DEFAULT 'default'`,    // 系统提示词模板名称
+		`ALTER TABLE traders ADD COLUMN symbol_margin_modes TEXT DEFAULT ''`,              // 按币种仓位模式，如 BTCUSDT:cross,ETHUSDT:isolated
+		`ALTER TABLE traders ADD COLUMN dry_run BOOLEAN DEFAULT 0`,                        // 是否为预演模式（只记录决策不下单）
+		`ALTER TABLE traders ADD COLUMN allocation_pct REAL DEFAULT 0`,                    // 多策略共用账户时本策略最多占用的净值百分比（0=不限制）
+		`ALTER TABLE traders ADD COLUMN netting_rule TEXT DEFAULT 'reject'`,               // 与其他策略反向持仓时的处理（reject/allow）
+		`ALTER TABLE traders ADD COLUMN max_trades_per_hour INTEGER DEFAULT 0`,            // 每小时最多开仓次数（0=不限制）
+		`ALTER TABLE traders ADD COLUMN max_trades_per_day INTEGER DEFAULT 0`,             // 每24小时最多开仓次数（0=不限制）
+		`ALTER TABLE traders ADD COLUMN max_entries_per_symbol_per_day INTEGER DEFAULT 0`, // 单币种每24小时最多开仓次数（0=不限制）
+		`ALTER TABLE traders ADD COLUMN stop_loss_cooldown_minutes INTEGER DEFAULT 0`,     // 止损后该币种禁止再次开仓的分钟数（0=关闭）
+		`ALTER TABLE traders ADD COLUMN funding_avoid_minutes INTEGER DEFAULT 0`,          // 资金费结算前后禁止开仓/平仓的分钟数（0=关闭）
+		`ALTER TABLE traders ADD COLUMN funding_adverse_threshold REAL DEFAULT 0`,         // 需支付的资金费率超过该百分比时结算前平仓（0=关闭）
+		`ALTER TABLE traders ADD COLUMN trailing_stop_mode TEXT DEFAULT ''`,               // 跟踪止损算法（chandelier/swing，空=关闭）
+		`ALTER TABLE traders ADD COLUMN trailing_interval TEXT DEFAULT ''`,                // 跟踪止损使用的K线周期（空=1h）
+		`ALTER TABLE traders ADD COLUMN trailing_lookback INTEGER DEFAULT 0`,              // 跟踪止损回看K线数（0=默认）
+		`ALTER TABLE traders ADD COLUMN trailing_atr_multiplier REAL DEFAULT 0`,           // 吊灯止损的ATR倍数（0=默认3）
+		`ALTER TABLE traders ADD COLUMN require_approval BOOLEAN DEFAULT 0`,               // 决策需运维人员确认后才执行
+		`ALTER TABLE traders ADD COLUMN approval_timeout_seconds INTEGER DEFAULT 0`,       // 等待确认的超时秒数（0=默认300，超时视为拒绝）
+		`ALTER TABLE traders ADD COLUMN symbol_whitelist TEXT DEFAULT ''`,                 // 币种白名单（逗号分隔，空=不限制）
+		`ALTER TABLE traders ADD COLUMN symbol_blacklist TEXT DEFAULT ''`,                 // 币种黑名单（逗号分隔）
+		`ALTER TABLE traders ADD COLUMN symbol_max_notional TEXT DEFAULT ''`,              // 按币种最大持仓名义价值，如 BTCUSDT:5000,*:1000
+		`ALTER TABLE traders ADD COLUMN ensemble_model_ids TEXT DEFAULT ''`,               // 参与投票的附加AI模型ID（逗号分隔，空=只用主模型）
+		`ALTER TABLE traders ADD COLUMN ensemble_mode TEXT DEFAULT ''`,                    // 多模型投票方式（majority/confidence，空=majority）
+		`ALTER TABLE traders ADD COLUMN execution_policy TEXT DEFAULT ''`,                 // 开仓执行算法（如 twap:duration=30m,slices=6，空=市价单）
+		`ALTER TABLE traders ADD COLUMN watch_only BOOLEAN DEFAULT 0`,                     // 观察模式（只读API Key，只跟踪余额/持仓/盈亏，不下单）
+		`ALTER TABLE traders ADD COLUMN order_tag_prefix TEXT DEFAULT ''`,                 // 订单clientOrderId/text前缀（空=nx）
+		`ALTER TABLE ai_models ADD COLUMN custom_api_url TEXT DEFAULT ''`,                 // 自定义API地址
+		`ALTER TABLE ai_models ADD COLUMN custom_model_name TEXT DEFAULT ''`,              // 自定义模型名称
 	}
 
 	for _, query := range alterQueries {
 		// 忽略已存在字段的错误
-		d.db.Exec(query)
+		d.exec(query)
 	}
 
-	// 检查是否需要迁移exchanges表的主键结构
-	err := d.migrateExchangesTable()
-	if err != nil {
-		log.Printf("⚠️ 迁移exchanges表失败: %v", err)
+	// 检查是否需要迁移exchanges表的主键结构（仅旧版SQLite数据库需要，其他数据库建表即为复合主键）
+	if d.dialect == dialectSQLite {
+		if err := d.migrateExchangesTable(); err != nil {
+			log.Printf("⚠️ 迁移exchanges表失败: %v", err)
+		}
 	}
 
 	return nil
@@ -219,10 +360,10 @@ func (d *Database) initDefaultData() error {
 	}
 
 	for _, model := range aiModels {
-		_, err := d.db.Exec(`
+		_, err := d.exec(`
 			INSERT OR IGNORE INTO ai_models (id, user_id, name, provider, enabled) 
-			VALUES (?, 'default', ?, ?, 0)
-		`, model.id, model.name, model.provider)
+			VALUES (?, 'default', ?, ?, ?)
+		`, model.id, model.name, model.provider, false)
 		if err != nil {
 			return fmt.Errorf("初始化AI模型失败: %w", err)
 		}
@@ -236,13 +377,14 @@ func (d *Database) initDefaultData() error {
 		{"hyperliquid", "Hyperliquid", "hyperliquid"},
 		{"aster", "Aster DEX", "aster"},
 		{"gate", "Gate.io Futures", "gate"},
+		{"paper", "Paper Trading", "paper"},
 	}
 
 	for _, exchange := range exchanges {
-		_, err := d.db.Exec(`
+		_, err := d.exec(`
 			INSERT OR IGNORE INTO exchanges (id, user_id, name, type, enabled) 
-			VALUES (?, 'default', ?, ?, 0)
-		`, exchange.id, exchange.name, exchange.typ)
+			VALUES (?, 'default', ?, ?, ?)
+		`, exchange.id, exchange.name, exchange.typ, false)
 		if err != nil {
 			return fmt.Errorf("初始化交易所失败: %w", err)
 		}
@@ -250,20 +392,110 @@ func (d *Database) initDefaultData() error {
 
 	// 初始化系统配置 - 创建所有字段，设置默认值，后续由config.json同步更新
 	systemConfigs := map[string]string{
-		"admin_mode":           "true",                                                                                // 默认开启管理员模式，便于首次使用
-		"api_server_port":      "8080",                                                                                // 默认API端口
-		"use_default_coins":    "true",                                                                                // 默认使用内置币种列表
-		"default_coins":        `["BTCUSDT","ETHUSDT","SOLUSDT","BNBUSDT","XRPUSDT","DOGEUSDT","ADAUSDT","HYPEUSDT"]`, // 默认币种列表（JSON格式）
-		"max_daily_loss":       "10.0",                                                                                // 最大日损失百分比
-		"max_drawdown":         "20.0",                                                                                // 最大回撤百分比
-		"stop_trading_minutes": "60",                                                                                  // 停止交易时间（分钟）
-		"btc_eth_leverage":     "5",                                                                                   // BTC/ETH杠杆倍数
-		"altcoin_leverage":     "5",                                                                                   // 山寨币杠杆倍数
-		"jwt_secret":           "",                                                                                    // JWT密钥，默认为空，由config.json或系统生成
+		"admin_mode":                   "true",                                                                                // 默认开启管理员模式，便于首次使用
+		"api_server_port":              "8080",                                                                                // 默认API端口
+		"trusted_proxies":              "",                                                                                    // 可信反向代理（逗号分隔的IP/CIDR，为空时不信任X-Forwarded-For，登录限流按连接IP计算）
+		"use_default_coins":            "true",                                                                                // 默认使用内置币种列表
+		"default_coins":                `["BTCUSDT","ETHUSDT","SOLUSDT","BNBUSDT","XRPUSDT","DOGEUSDT","ADAUSDT","HYPEUSDT"]`, // 默认币种列表（JSON格式）
+		"max_daily_loss":               "10.0",                                                                                // 最大日损失百分比
+		"max_drawdown":                 "20.0",                                                                                // 最大回撤百分比
+		"stop_trading_minutes":         "60",                                                                                  // 停止交易时间（分钟）
+		"btc_eth_leverage":             "5",                                                                                   // BTC/ETH杠杆倍数
+		"altcoin_leverage":             "5",                                                                                   // 山寨币杠杆倍数
+		"margin_topup_threshold":       "0",                                                                                   // 保证金占用率超过该百分比时从现货自动划转（0=关闭）
+		"margin_topup_amount":          "0",                                                                                   // 每次自动划转的USDT金额
+		"debug_log":                    "false",                                                                               // 是否输出调试日志（敏感信息始终屏蔽）
+		"locale":                       "zh",                                                                                  // 日志及错误信息语言: zh / en
+		"blackout_windows":             "[]",                                                                                  // 静态禁止开仓窗口（JSON数组）
+		"economic_calendar_url":        "",                                                                                    // 经济日历API，为空则只使用静态窗口
+		"economic_calendar_countries":  "USD",                                                                                 // 关注的国家/货币（逗号分隔）
+		"blackout_padding_minutes":     "30",                                                                                  // 日历事件前后的禁止开仓分钟数
+		"blackout_reduce_pct":          "0",                                                                                   // 日历事件窗口开始时的减仓百分比（0=不减仓）
+		"correlation_groups":           "{}",                                                                                  // 相关性分组（JSON: 分组名 -> 币种列表）
+		"max_group_exposure_pct":       "0",                                                                                   // 同一分组方向性敞口上限（净值百分比，0=不限制）
+		"risk_report_interval_minutes": "60",                                                                                  // VaR及压力测试报告生成间隔（分钟，0=关闭）
+		"grpc_port":                    "0",                                                                                   // gRPC控制接口端口（0=关闭）
+		"signal_ingest_url":            "",                                                                                    // 外部信号源地址（redis://... 或 mqtt/tcp://...，空=关闭）
+		"signal_ingest_topic":          "nofx/signals",                                                                        // 外部信号Redis频道/MQTT主题
+		"event_export_url":             "",                                                                                    // 执行事件导出地址（kafka://broker:9092 或 nats://host:4222，空=关闭）
+		"event_export_topic":           "nofx.events",                                                                         // 执行事件导出的Kafka主题/NATS主题前缀
+		"recurring_scheduler_enabled":  "true",                                                                                // 是否运行定投调度器（多实例共用数据库时只在一个实例上开启）
+		"telegram_bot_token":           "",                                                                                    // Telegram机器人Token（为空则不启用）
+		"telegram_operator_ids":        "",                                                                                    // 允许执行Telegram指令的用户ID（逗号分隔）
+		"telegram_operator_users":      "",                                                                                    // Telegram运维人员关联的用户（逗号分隔的 Telegram用户ID=用户ID），两人规则按关联用户校验交易员权限
+		"leaderboard_summary_hour":     "0",                                                                                   // 每日策略排行榜通知的发布时刻（交易日时区的小时，-1=关闭）
+		"quote_currency":               "USDT",                                                                                // 净值、盈亏及通知的报告币种（如 EUR、CNY）
+		"fx_rate_url":                  "https://open.er-api.com/v6/latest/USD",                                               // 汇率API（以USD为基准）
+		"fx_static_rates":              "",                                                                                    // 固定汇率（如 EUR=0.92,CNY=7.2），设置后不再请求汇率API
+		"delta_hedges":                 "[]",                                                                                  // 期权Delta对冲配置（JSON数组）
+		"basis_strategies":             "[]",                                                                                  // 期现套利配置（JSON数组）
+		"market_makers":                "[]",                                                                                  // 做市配置（JSON数组）
+		"depeg_coins":                  "USDT,USDC",                                                                           // 监控脱锚的稳定币
+		"depeg_alert_pct":              "0",                                                                                   // 稳定币偏离1美元超过该百分比时告警并暂停开仓（0=关闭）
+		"depeg_reduce_at_pct":          "0",                                                                                   // 稳定币偏离超过该百分比时减仓（0=不减仓）
+		"depeg_reduce_pct":             "0",                                                                                   // 稳定币脱锚时的减仓比例
+		"maintenance_windows":          "[]",                                                                                  // 静态交易所维护窗口（JSON数组）
+		"maintenance_feed_url":         "",                                                                                    // 维护公告JSON源（为空不使用）
+		"maintenance_binance_status":   "true",                                                                                // 轮询币安系统状态，维护中暂停币安交易员开仓
+		"maintenance_lead_minutes":     "15",                                                                                  // 维护开始前提前暂停开仓的分钟数
+		"maintenance_hedge_trader_id":  "",                                                                                    // 维护期间执行跨交易所对冲的交易员（为空不对冲）
+		"maintenance_hedge_leverage":   "2",                                                                                   // 维护对冲仓位杠杆
+		"delisting_close_hours":        "24",                                                                                  // 合约计划下架/交割前多少小时强制平仓（-1=只告警不平仓）
+		"delisting_migrate_trader_id":  "",                                                                                    // 下架合约平仓后迁移到该交易员账户（为空不迁移）
+		"delisting_migrate_leverage":   "2",                                                                                   // 迁移仓位杠杆
+		"new_listing_exchanges":        "",                                                                                    // 扫描新上线永续合约的交易所，逗号分隔（为空不扫描）
+		"new_listing_scan_minutes":     "10",                                                                                  // 新上线合约扫描间隔（分钟）
+		"new_listing_auto_add_traders": "",                                                                                    // 自动将新合约加入候选池的交易员ID，逗号分隔（为空只通知）
+		"new_listing_min_age_hours":    "24",                                                                                  // 新合约上线满该小时数后才加入候选池
+		"new_listing_max_per_trader":   "3",                                                                                   // 每个交易员最多自动加入的新合约数
+		"new_listing_max_leverage":     "3",                                                                                   // 新合约开仓杠杆上限
+		"new_listing_max_position_usd": "100",                                                                                 // 新合约单笔最大名义价值（USDT，0=不限制）
+		"universes":                    "[]",                                                                                  // 动态选币配置（JSON数组，按成交额/波动率/价差定期更新交易员的交易币种）
+		"ai_max_position_usd":          "0",                                                                                   // AI单笔开仓名义价值硬上限（USDT，超出时收紧，0=只按净值倍数限制）
+		"feature_providers":            "[]",                                                                                  // AI特征源配置（JSON数组：funding / open_interest / fear_greed / news，带缓存及限流，内容写入AI prompt）
+		"llm_daily_budget_usd":         "0",                                                                                   // 每个交易员每个交易日AI调用估算成本上限（USD，达到后暂停AI决策至下一个交易日，0=不限制）
+		"llm_prices":                   "",                                                                                    // 模型单价覆盖（USD每百万token，如 deepseek-chat=0.27/1.10,*=1/3；为空使用内置价格）
+		"execution_report_hour":        "0",                                                                                   // 每日开仓执行质量报告的发布时刻（交易日时区的小时，-1=关闭）
+		"api_key_preflight":            "strict",                                                                              // API Key预检模式（strict=未通过时拒绝启动交易员, warn=只告警, off=不检查）
+		"refuse_withdrawal_keys":       "true",                                                                                // API Key开启了提现权限时拒绝运行交易员（强烈建议保持开启；Gate、Aster 无法查询提现权限，strict模式下需在确认已关闭提现权限后设为false）
+		"two_person_threshold_usd":     "0",                                                                                   // 人工订单（HTTP/Telegram/gRPC）名义价值达到该值（USDT）时需另一位运维人员凭确认码确认（0=关闭）
+		"admin_password":               "",                                                                                    // 管理员模式的登录密码（PIN，保存bcrypt哈希，config.json及环境变量中的明文在同步时哈希；为空时管理员模式无需登录）
+		"api_tls_cert":                 "",                                                                                    // API服务器HTTPS证书文件（与api_tls_key均设置时启用HTTPS）
+		"api_tls_key":                  "",                                                                                    // API服务器HTTPS私钥文件
+		"api_tokens":                   "",                                                                                    // 静态API Token（JSON数组，如 [{"name":"grafana","token":"...","role":"read"}]，角色 read/trade/admin）
+		"replay_log":                   "false",                                                                               // 记录每个决策周期的行情输入及AI响应到回放日志（nofx replay 离线复现）
+		"fault_injection":              "",                                                                                    // 故障注入（混沌测试，如 delay=200ms-2s,error=0.05,drop=0.02，空=关闭，勿用于实盘）
+		"funding_history_symbols":      "",                                                                                    // 额外记录资金费率历史的交易对，逗号分隔（期现套利的交易对自动记录）
+		"market_order_guard":           "",                                                                                    // 市价开仓前的行情保护（如 deviation_bps=50,spread_bps=20,action=limit,wait=30s，空=关闭）
+		"max_slippage_ticks":           "0",                                                                                   // 市价单最大滑点（价格步进数，>0时市价单改为限价穿价的IOC单，0=普通市价单）
+		"instance_id":                  "",                                                                                    // 敞口快照（/api/exposure、gRPC GetExposure）中的实例标识，外部风控系统汇总多个实例时区分来源（为空时使用主机名）
+		"journal_webhook_url":          "",                                                                                    // 已平仓交易推送的通用webhook地址（POST JSON，如Zapier/Make或自建交易日志服务，空=关闭）
+		"job_workers":                  "2",                                                                                   // 后台任务（交易规则刷新、历史数据下载、报告生成）的并发worker数（0=关闭后台队列，慢操作在交易周期内同步执行）
+		"request_budget":               "",                                                                                    // 交易所请求频率预算（如 rate=10,burst=20,reserve=5,max_wait=10s，限频紧张时优先撤单/平仓，空=不限制）
+		"heartbeat_interval_minutes":   "0",                                                                                   // 存活通知（净值/持仓/最近成交/错误数）的发布间隔（分钟，0=关闭）
+		"performance_digest":           "",                                                                                    // 业绩日报/周报计划（如 daily,weekly,hour=8,weekday=mon，空=关闭）
+		"dashboard_url":                "",                                                                                    // 仪表盘访问地址（通知中的链接，如 https://nofx.example.com，空=不附链接）
+		"smtp_url":                     "",                                                                                    // SMTP邮件通知服务器（smtps://用户名:密码@主机:465 或 smtp://用户名:密码@主机:587，为空则不启用）
+		"smtp_from":                    "",                                                                                    // 邮件发件人（为空时使用SMTP用户名）
+		"smtp_to":                      "",                                                                                    // 邮件收件人（逗号分隔）
+		"smtp_events":                  "",                                                                                    // 邮件通知的事件类型（逗号分隔，如 performance_digest,heartbeat，空=与Telegram相同）
+		"liquidation_alert_pct":        "5",                                                                                   // 标记价格距强平价格低于该百分比时发送紧急告警（0=关闭）
+		"pagerduty_routing_key":        "",                                                                                    // PagerDuty Events API v2 Integration Key（紧急告警呼叫值班人员，为空则不启用）
+		"opsgenie_api_key":             "",                                                                                    // Opsgenie API Key（紧急告警呼叫值班人员，为空则不启用）
+		"opsgenie_api_url":             "",                                                                                    // Opsgenie API地址（为空=https://api.opsgenie.com，EU账户为 https://api.eu.opsgenie.com）
+		"webhook_url":                  "",                                                                                    // 事件webhook地址（每个执行事件以JSON POST，为空则不启用）
+		"webhook_secret":               "",                                                                                    // 事件webhook签名密钥（HMAC-SHA256，为空则不签名）
+		"otel_endpoint":                "",                                                                                    // OpenTelemetry OTLP/HTTP地址（如 http://localhost:4318，为空则不追踪交易链路）
+		"otel_sample_ratio":            "1",                                                                                   // 交易链路追踪采样比例（0-1）
+		"shadow_book_reconcile":        "",                                                                                    // 影子账本REST对账间隔（如 30s，空=关闭；开启后持仓/挂单由交易所推送实时更新，各模块读取本地账本）
+		"panic_actions":                "",                                                                                    // 紧急按钮动作（逗号分隔：disable_trading,cancel_orders,close_positions,page，空=全部）
+		"day_reset_timezone":           "",                                                                                    // 交易日切换时区（日盈亏、每日AI预算及业绩日报按该时区零点切换，如 Asia/Shanghai、+08:00，空=UTC）
+		"balance_reconcile_tolerance":  "",                                                                                    // 每日余额对账容差（如 5、0.5%、5,0.5%，取较大者；余额变动与记录的交易/资金费/手续费差额超过时告警，空=关闭）
+		"jwt_secret":                   "",                                                                                    // JWT密钥，默认为空，由config.json或系统生成
 	}
 
 	for key, value := range systemConfigs {
-		_, err := d.db.Exec(`
+		_, err := d.exec(`
 			INSERT OR IGNORE INTO system_config (key, value) 
 			VALUES (?, ?)
 		`, key, value)
@@ -279,7 +511,7 @@ func (d *Database) initDefaultData() error {
 func (d *Database) migrateExchangesTable() error {
 	// 检查是否已经迁移过
 	var count int
-	err := d.db.QueryRow(`
+	err := d.queryRow(`
 		SELECT COUNT(*) FROM sqlite_master 
 		WHERE type='table' AND name='exchanges_new'
 	`).Scan(&count)
@@ -295,7 +527,7 @@ func (d *Database) migrateExchangesTable() error {
 	log.Printf("🔄 开始迁移exchanges表...")
 
 	// 创建新的exchanges表，使用复合主键
-	_, err = d.db.Exec(`
+	_, err = d.exec(`
 		CREATE TABLE exchanges_new (
 			id TEXT NOT NULL,
 			user_id TEXT NOT NULL DEFAULT 'default',
@@ -320,7 +552,7 @@ func (d *Database) migrateExchangesTable() error {
 	}
 
 	// 复制数据到新表
-	_, err = d.db.Exec(`
+	_, err = d.exec(`
 		INSERT INTO exchanges_new 
 		SELECT * FROM exchanges
 	`)
@@ -329,19 +561,19 @@ func (d *Database) migrateExchangesTable() error {
 	}
 
 	// 删除旧表
-	_, err = d.db.Exec(`DROP TABLE exchanges`)
+	_, err = d.exec(`DROP TABLE exchanges`)
 	if err != nil {
 		return fmt.Errorf("删除旧表失败: %w", err)
 	}
 
 	// 重命名新表
-	_, err = d.db.Exec(`ALTER TABLE exchanges_new RENAME TO exchanges`)
+	_, err = d.exec(`ALTER TABLE exchanges_new RENAME TO exchanges`)
 	if err != nil {
 		return fmt.Errorf("重命名表失败: %w", err)
 	}
 
 	// 重新创建触发器
-	_, err = d.db.Exec(`
+	_, err = d.exec(`
 		CREATE TRIGGER IF NOT EXISTS update_exchanges_updated_at
 			AFTER UPDATE ON exchanges
 			BEGIN
@@ -404,26 +636,50 @@ type ExchangeConfig struct {
 
 // TraderRecord 交易员配置（数据库实体）
 type TraderRecord struct {
-	ID                   string    `json:"id"`
-	UserID               string    `json:"user_id"`
-	Name                 string    `json:"name"`
-	AIModelID            string    `json:"ai_model_id"`
-	ExchangeID           string    `json:"exchange_id"`
-	InitialBalance       float64   `json:"initial_balance"`
-	ScanIntervalMinutes  int       `json:"scan_interval_minutes"`
-	IsRunning            bool      `json:"is_running"`
-	BTCETHLeverage       int       `json:"btc_eth_leverage"`       // BTC/ETH杠杆倍数
-	AltcoinLeverage      int       `json:"altcoin_leverage"`       // 山寨币杠杆倍数
-	TradingSymbols       string    `json:"trading_symbols"`        // 交易币种，逗号分隔
-	UseCoinPool          bool      `json:"use_coin_pool"`          // 是否使用COIN POOL信号源
-	UseOITop             bool      `json:"use_oi_top"`             // 是否使用OI TOP信号源
-	UseInsideCoins       bool      `json:"use_inside_coins"`       // 是否使用内置评分信号源
-	CustomPrompt         string    `json:"custom_prompt"`          // 自定义交易策略prompt
-	OverrideBasePrompt   bool      `json:"override_base_prompt"`   // 是否覆盖基础prompt
-	SystemPromptTemplate string    `json:"system_prompt_template"` // 系统提示词模板名称
-	IsCrossMargin        bool      `json:"is_cross_margin"`        // 是否为全仓模式（true=全仓，false=逐仓）
-	CreatedAt            time.Time `json:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at"`
+	ID                        string    `json:"id"`
+	UserID                    string    `json:"user_id"`
+	Name                      string    `json:"name"`
+	AIModelID                 string    `json:"ai_model_id"`
+	ExchangeID                string    `json:"exchange_id"`
+	InitialBalance            float64   `json:"initial_balance"`
+	ScanIntervalMinutes       int       `json:"scan_interval_minutes"`
+	IsRunning                 bool      `json:"is_running"`
+	BTCETHLeverage            int       `json:"btc_eth_leverage"`               // BTC/ETH杠杆倍数
+	AltcoinLeverage           int       `json:"altcoin_leverage"`               // 山寨币杠杆倍数
+	TradingSymbols            string    `json:"trading_symbols"`                // 交易币种，逗号分隔
+	UseCoinPool               bool      `json:"use_coin_pool"`                  // 是否使用COIN POOL信号源
+	UseOITop                  bool      `json:"use_oi_top"`                     // 是否使用OI TOP信号源
+	UseInsideCoins            bool      `json:"use_inside_coins"`               // 是否使用内置评分信号源
+	CustomPrompt              string    `json:"custom_prompt"`                  // 自定义交易策略prompt
+	OverrideBasePrompt        bool      `json:"override_base_prompt"`           // 是否覆盖基础prompt
+	SystemPromptTemplate      string    `json:"system_prompt_template"`         // 系统提示词模板名称
+	IsCrossMargin             bool      `json:"is_cross_margin"`                // 是否为全仓模式（true=全仓，false=逐仓）
+	SymbolMarginModes         string    `json:"symbol_margin_modes"`            // 按币种覆盖的仓位模式，如 BTCUSDT:cross,ETHUSDT:isolated
+	DryRun                    bool      `json:"dry_run"`                        // 是否为预演模式（只记录决策不下单）
+	AllocationPct             float64   `json:"allocation_pct"`                 // 多策略共用账户时本策略最多占用的净值百分比（0=不限制）
+	NettingRule               string    `json:"netting_rule"`                   // 与其他策略反向持仓时的处理（reject/allow）
+	MaxTradesPerHour          int       `json:"max_trades_per_hour"`            // 每小时最多开仓次数（0=不限制）
+	MaxTradesPerDay           int       `json:"max_trades_per_day"`             // 每24小时最多开仓次数（0=不限制）
+	MaxEntriesPerSymbolPerDay int       `json:"max_entries_per_symbol_per_day"` // 单币种每24小时最多开仓次数（0=不限制）
+	StopLossCooldownMinutes   int       `json:"stop_loss_cooldown_minutes"`     // 止损后该币种禁止再次开仓的分钟数（0=关闭）
+	FundingAvoidMinutes       int       `json:"funding_avoid_minutes"`          // 资金费结算前后禁止开仓/平仓的分钟数（0=关闭）
+	FundingAdverseThreshold   float64   `json:"funding_adverse_threshold"`      // 需支付的资金费率超过该百分比时结算前平仓（0=关闭）
+	TrailingStopMode          string    `json:"trailing_stop_mode"`             // 跟踪止损算法（chandelier/swing，空=关闭）
+	TrailingInterval          string    `json:"trailing_interval"`              // 跟踪止损使用的K线周期（空=1h）
+	TrailingLookback          int       `json:"trailing_lookback"`              // 跟踪止损回看K线数（0=默认）
+	TrailingATRMultiplier     float64   `json:"trailing_atr_multiplier"`        // 吊灯止损的ATR倍数（0=默认3）
+	RequireApproval           bool      `json:"require_approval"`               // 决策需运维人员确认后才执行
+	ApprovalTimeoutSeconds    int       `json:"approval_timeout_seconds"`       // 等待确认的超时秒数（0=默认300，超时视为拒绝）
+	SymbolWhitelist           string    `json:"symbol_whitelist"`               // 币种白名单（逗号分隔，空=不限制）
+	SymbolBlacklist           string    `json:"symbol_blacklist"`               // 币种黑名单（逗号分隔）
+	SymbolMaxNotional         string    `json:"symbol_max_notional"`            // 按币种最大持仓名义价值（USDT），如 BTCUSDT:5000,*:1000
+	EnsembleModelIDs          string    `json:"ensemble_model_ids"`             // 参与投票的附加AI模型ID（逗号分隔，空=只用主模型）
+	EnsembleMode              string    `json:"ensemble_mode"`                  // 多模型投票方式（majority/confidence，空=majority）
+	ExecutionPolicy           string    `json:"execution_policy"`               // 开仓执行算法（market/passive/twap/vwap及参数，空=市价单）
+	WatchOnly                 bool      `json:"watch_only"`                     // 观察模式（只读API Key，只跟踪余额/持仓/盈亏，不下单）
+	OrderTagPrefix            string    `json:"order_tag_prefix"`               // 订单clientOrderId/text前缀（空=nx）
+	CreatedAt                 time.Time `json:"created_at"`
+	UpdatedAt                 time.Time `json:"updated_at"`
 }
 
 // UserSignalSource 用户信号源配置
@@ -448,7 +704,7 @@ func GenerateOTPSecret() (string, error) {
 
 // CreateUser 创建用户
 func (d *Database) CreateUser(user *User) error {
-	_, err := d.db.Exec(`
+	_, err := d.exec(`
 		INSERT INTO users (id, email, password_hash, otp_secret, otp_verified)
 		VALUES (?, ?, ?, ?, ?)
 	`, user.ID, user.Email, user.PasswordHash, user.OTPSecret, user.OTPVerified)
@@ -459,7 +715,7 @@ func (d *Database) CreateUser(user *User) error {
 func (d *Database) EnsureAdminUser() error {
 	// 检查admin用户是否已存在
 	var count int
-	err := d.db.QueryRow(`SELECT COUNT(*) FROM users WHERE id = 'admin'`).Scan(&count)
+	err := d.queryRow(`SELECT COUNT(*) FROM users WHERE id = 'admin'`).Scan(&count)
 	if err != nil {
 		return err
 	}
@@ -484,7 +740,7 @@ func (d *Database) EnsureAdminUser() error {
 // GetUserByEmail 通过邮箱获取用户
 func (d *Database) GetUserByEmail(email string) (*User, error) {
 	var user User
-	err := d.db.QueryRow(`
+	err := d.queryRow(`
 		SELECT id, email, password_hash, otp_secret, otp_verified, created_at, updated_at
 		FROM users WHERE email = ?
 	`, email).Scan(
@@ -500,7 +756,7 @@ func (d *Database) GetUserByEmail(email string) (*User, error) {
 // GetUserByID 通过ID获取用户
 func (d *Database) GetUserByID(userID string) (*User, error) {
 	var user User
-	err := d.db.QueryRow(`
+	err := d.queryRow(`
 		SELECT id, email, password_hash, otp_secret, otp_verified, created_at, updated_at
 		FROM users WHERE id = ?
 	`, userID).Scan(
@@ -515,7 +771,7 @@ func (d *Database) GetUserByID(userID string) (*User, error) {
 
 // GetAllUsers 获取所有用户ID列表
 func (d *Database) GetAllUsers() ([]string, error) {
-	rows, err := d.db.Query(`SELECT id FROM users ORDER BY id`)
+	rows, err := d.query(`SELECT id FROM users ORDER BY id`)
 	if err != nil {
 		return nil, err
 	}
@@ -534,13 +790,13 @@ func (d *Database) GetAllUsers() ([]string, error) {
 
 // UpdateUserOTPVerified 更新用户OTP验证状态
 func (d *Database) UpdateUserOTPVerified(userID string, verified bool) error {
-	_, err := d.db.Exec(`UPDATE users SET otp_verified = ? WHERE id = ?`, verified, userID)
+	_, err := d.exec(`UPDATE users SET otp_verified = ? WHERE id = ?`, verified, userID)
 	return err
 }
 
 // GetAIModels 获取用户的AI模型配置
 func (d *Database) GetAIModels(userID string) ([]*AIModelConfig, error) {
-	rows, err := d.db.Query(`
+	rows, err := d.query(`
 		SELECT id, user_id, name, provider, enabled, api_key,
 		       COALESCE(custom_api_url, '') as custom_api_url,
 		       COALESCE(custom_model_name, '') as custom_model_name,
@@ -574,14 +830,14 @@ func (d *Database) GetAIModels(userID string) ([]*AIModelConfig, error) {
 func (d *Database) UpdateAIModel(userID, id string, enabled bool, apiKey, customAPIURL, customModelName string) error {
 	// 先尝试精确匹配 ID（新版逻辑，支持多个相同 provider 的模型）
 	var existingID string
-	err := d.db.QueryRow(`
+	err := d.queryRow(`
 		SELECT id FROM ai_models WHERE user_id = ? AND id = ? LIMIT 1
 	`, userID, id).Scan(&existingID)
 
 	if err == nil {
 		// 找到了现有配置（精确匹配 ID），更新它
-		_, err = d.db.Exec(`
-			UPDATE ai_models SET enabled = ?, api_key = ?, custom_api_url = ?, custom_model_name = ?, updated_at = datetime('now')
+		_, err = d.exec(`
+			UPDATE ai_models SET enabled = ?, api_key = ?, custom_api_url = ?, custom_model_name = ?, updated_at = CURRENT_TIMESTAMP
 			WHERE id = ? AND user_id = ?
 		`, enabled, apiKey, customAPIURL, customModelName, existingID, userID)
 		return err
@@ -589,15 +845,15 @@ func (d *Database) UpdateAIModel(userID, id string, enabled bool, apiKey, custom
 
 	// ID 不存在，尝试兼容旧逻辑：将 id 作为 provider 查找
 	provider := id
-	err = d.db.QueryRow(`
+	err = d.queryRow(`
 		SELECT id FROM ai_models WHERE user_id = ? AND provider = ? LIMIT 1
 	`, userID, provider).Scan(&existingID)
 
 	if err == nil {
 		// 找到了现有配置（通过 provider 匹配，兼容旧版），更新它
 		log.Printf("⚠️  使用旧版 provider 匹配更新模型: %s -> %s", provider, existingID)
-		_, err = d.db.Exec(`
-			UPDATE ai_models SET enabled = ?, api_key = ?, custom_api_url = ?, custom_model_name = ?, updated_at = datetime('now')
+		_, err = d.exec(`
+			UPDATE ai_models SET enabled = ?, api_key = ?, custom_api_url = ?, custom_model_name = ?, updated_at = CURRENT_TIMESTAMP
 			WHERE id = ? AND user_id = ?
 		`, enabled, apiKey, customAPIURL, customModelName, existingID, userID)
 		return err
@@ -620,7 +876,7 @@ func (d *Database) UpdateAIModel(userID, id string, enabled bool, apiKey, custom
 
 	// 获取模型的基本信息
 	var name string
-	err = d.db.QueryRow(`
+	err = d.queryRow(`
 		SELECT name FROM ai_models WHERE provider = ? LIMIT 1
 	`, provider).Scan(&name)
 	if err != nil {
@@ -643,9 +899,9 @@ func (d *Database) UpdateAIModel(userID, id string, enabled bool, apiKey, custom
 	}
 
 	log.Printf("✓ 创建新的 AI 模型配置: ID=%s, Provider=%s, Name=%s", newModelID, provider, name)
-	_, err = d.db.Exec(`
+	_, err = d.exec(`
 		INSERT INTO ai_models (id, user_id, name, provider, enabled, api_key, custom_api_url, custom_model_name, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`, newModelID, userID, name, provider, enabled, apiKey, customAPIURL, customModelName)
 
 	return err
@@ -653,7 +909,7 @@ func (d *Database) UpdateAIModel(userID, id string, enabled bool, apiKey, custom
 
 // GetExchanges 获取用户的交易所配置
 func (d *Database) GetExchanges(userID string) ([]*ExchangeConfig, error) {
-	rows, err := d.db.Query(`
+	rows, err := d.query(`
 		SELECT id, user_id, name, type, enabled, api_key, secret_key, testnet, 
 		       COALESCE(hyperliquid_wallet_addr, '') as hyperliquid_wallet_addr,
 		       COALESCE(aster_user, '') as aster_user,
@@ -692,9 +948,9 @@ func (d *Database) UpdateExchange(userID, id string, enabled bool, apiKey, secre
 	log.Printf("🔧 UpdateExchange: userID=%s, id=%s, enabled=%v", userID, id, enabled)
 
 	// 首先尝试更新现有的用户配置
-	result, err := d.db.Exec(`
+	result, err := d.exec(`
 		UPDATE exchanges SET enabled = ?, api_key = ?, secret_key = ?, testnet = ?, 
-		       hyperliquid_wallet_addr = ?, aster_user = ?, aster_signer = ?, aster_private_key = ?, updated_at = datetime('now')
+		       hyperliquid_wallet_addr = ?, aster_user = ?, aster_signer = ?, aster_private_key = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND user_id = ?
 	`, enabled, apiKey, secretKey, testnet, hyperliquidWalletAddr, asterUser, asterSigner, asterPrivateKey, id, userID)
 	if err != nil {
@@ -734,10 +990,10 @@ func (d *Database) UpdateExchange(userID, id string, enabled bool, apiKey, secre
 		log.Printf("🆕 UpdateExchange: 创建新记录 ID=%s, name=%s, type=%s", id, name, typ)
 
 		// 创建用户特定的配置，使用原始的交易所ID
-		_, err = d.db.Exec(`
+		_, err = d.exec(`
 			INSERT INTO exchanges (id, user_id, name, type, enabled, api_key, secret_key, testnet, 
 			                       hyperliquid_wallet_addr, aster_user, aster_signer, aster_private_key, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		`, id, userID, name, typ, enabled, apiKey, secretKey, testnet, hyperliquidWalletAddr, asterUser, asterSigner, asterPrivateKey)
 
 		if err != nil {
@@ -754,7 +1010,7 @@ func (d *Database) UpdateExchange(userID, id string, enabled bool, apiKey, secre
 
 // CreateAIModel 创建AI模型配置
 func (d *Database) CreateAIModel(userID, id, name, provider string, enabled bool, apiKey, customAPIURL string) error {
-	_, err := d.db.Exec(`
+	_, err := d.exec(`
 		INSERT OR IGNORE INTO ai_models (id, user_id, name, provider, enabled, api_key, custom_api_url) 
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`, id, userID, name, provider, enabled, apiKey, customAPIURL)
@@ -763,7 +1019,7 @@ func (d *Database) CreateAIModel(userID, id, name, provider string, enabled bool
 
 // CreateExchange 创建交易所配置
 func (d *Database) CreateExchange(userID, id, name, typ string, enabled bool, apiKey, secretKey string, testnet bool, hyperliquidWalletAddr, asterUser, asterSigner, asterPrivateKey string) error {
-	_, err := d.db.Exec(`
+	_, err := d.exec(`
 		INSERT OR IGNORE INTO exchanges (id, user_id, name, type, enabled, api_key, secret_key, testnet, hyperliquid_wallet_addr, aster_user, aster_signer, aster_private_key) 
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, id, userID, name, typ, enabled, apiKey, secretKey, testnet, hyperliquidWalletAddr, asterUser, asterSigner, asterPrivateKey)
@@ -772,23 +1028,47 @@ func (d *Database) CreateExchange(userID, id, name, typ string, enabled bool, ap
 
 // CreateTrader 创建交易员
 func (d *Database) CreateTrader(trader *TraderRecord) error {
-	_, err := d.db.Exec(`
-		INSERT INTO traders (id, user_id, name, ai_model_id, exchange_id, initial_balance, scan_interval_minutes, is_running, btc_eth_leverage, altcoin_leverage, trading_symbols, use_coin_pool, use_oi_top, use_inside_coins, custom_prompt, override_base_prompt, system_prompt_template, is_cross_margin)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,?, ?, ?, ?, ?, ?)
-	`, trader.ID, trader.UserID, trader.Name, trader.AIModelID, trader.ExchangeID, trader.InitialBalance, trader.ScanIntervalMinutes, trader.IsRunning, trader.BTCETHLeverage, trader.AltcoinLeverage, trader.TradingSymbols, trader.UseCoinPool, trader.UseOITop, trader.UseInsideCoins, trader.CustomPrompt, trader.OverrideBasePrompt, trader.SystemPromptTemplate, trader.IsCrossMargin)
+	_, err := d.exec(`
+		INSERT INTO traders (id, user_id, name, ai_model_id, exchange_id, initial_balance, scan_interval_minutes, is_running, btc_eth_leverage, altcoin_leverage, trading_symbols, use_coin_pool, use_oi_top, use_inside_coins, custom_prompt, override_base_prompt, system_prompt_template, is_cross_margin, symbol_margin_modes, dry_run, allocation_pct, netting_rule, max_trades_per_hour, max_trades_per_day, max_entries_per_symbol_per_day, stop_loss_cooldown_minutes, funding_avoid_minutes, funding_adverse_threshold, trailing_stop_mode, trailing_interval, trailing_lookback, trailing_atr_multiplier, require_approval, approval_timeout_seconds, symbol_whitelist, symbol_blacklist, symbol_max_notional, ensemble_model_ids, ensemble_mode, execution_policy, watch_only, order_tag_prefix)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, trader.ID, trader.UserID, trader.Name, trader.AIModelID, trader.ExchangeID, trader.InitialBalance, trader.ScanIntervalMinutes, trader.IsRunning, trader.BTCETHLeverage, trader.AltcoinLeverage, trader.TradingSymbols, trader.UseCoinPool, trader.UseOITop, trader.UseInsideCoins, trader.CustomPrompt, trader.OverrideBasePrompt, trader.SystemPromptTemplate, trader.IsCrossMargin, trader.SymbolMarginModes, trader.DryRun, trader.AllocationPct, trader.NettingRule, trader.MaxTradesPerHour, trader.MaxTradesPerDay, trader.MaxEntriesPerSymbolPerDay, trader.StopLossCooldownMinutes, trader.FundingAvoidMinutes, trader.FundingAdverseThreshold, trader.TrailingStopMode, trader.TrailingInterval, trader.TrailingLookback, trader.TrailingATRMultiplier, trader.RequireApproval, trader.ApprovalTimeoutSeconds, trader.SymbolWhitelist, trader.SymbolBlacklist, trader.SymbolMaxNotional, trader.EnsembleModelIDs, trader.EnsembleMode, trader.ExecutionPolicy, trader.WatchOnly, trader.OrderTagPrefix)
 	return err
 }
 
 // GetTraders 获取用户的交易员
 func (d *Database) GetTraders(userID string) ([]*TraderRecord, error) {
-	rows, err := d.db.Query(`
+	rows, err := d.query(`
 		SELECT id, user_id, name, ai_model_id, exchange_id, initial_balance, scan_interval_minutes, is_running,
 		       COALESCE(btc_eth_leverage, 5) as btc_eth_leverage, COALESCE(altcoin_leverage, 5) as altcoin_leverage,
 		       COALESCE(trading_symbols, '') as trading_symbols,
 		       COALESCE(use_coin_pool, 0) as use_coin_pool, COALESCE(use_oi_top, 0) as use_oi_top,COALESCE(use_inside_coins, 0) as use_inside_coins,
 		       COALESCE(custom_prompt, '') as custom_prompt, COALESCE(override_base_prompt, 0) as override_base_prompt,
 		       COALESCE(system_prompt_template, 'default') as system_prompt_template,
-		       COALESCE(is_cross_margin, 1) as is_cross_margin, created_at, updated_at
+		       COALESCE(is_cross_margin, 1) as is_cross_margin,
+		       COALESCE(symbol_margin_modes, '') as symbol_margin_modes,
+		       COALESCE(dry_run, 0) as dry_run,
+		       COALESCE(allocation_pct, 0) as allocation_pct,
+		       COALESCE(netting_rule, 'reject') as netting_rule,
+		       COALESCE(max_trades_per_hour, 0) as max_trades_per_hour,
+		       COALESCE(max_trades_per_day, 0) as max_trades_per_day,
+		       COALESCE(max_entries_per_symbol_per_day, 0) as max_entries_per_symbol_per_day,
+		       COALESCE(stop_loss_cooldown_minutes, 0) as stop_loss_cooldown_minutes,
+		       COALESCE(funding_avoid_minutes, 0) as funding_avoid_minutes,
+		       COALESCE(funding_adverse_threshold, 0) as funding_adverse_threshold,
+		       COALESCE(trailing_stop_mode, '') as trailing_stop_mode,
+		       COALESCE(trailing_interval, '') as trailing_interval,
+		       COALESCE(trailing_lookback, 0) as trailing_lookback,
+		       COALESCE(trailing_atr_multiplier, 0) as trailing_atr_multiplier,
+		       COALESCE(require_approval, 0) as require_approval,
+		       COALESCE(approval_timeout_seconds, 0) as approval_timeout_seconds,
+		       COALESCE(symbol_whitelist, '') as symbol_whitelist,
+		       COALESCE(symbol_blacklist, '') as symbol_blacklist,
+		       COALESCE(symbol_max_notional, '') as symbol_max_notional,
+		       COALESCE(ensemble_model_ids, '') as ensemble_model_ids,
+		       COALESCE(ensemble_mode, '') as ensemble_mode,
+		       COALESCE(execution_policy, '') as execution_policy,
+		       COALESCE(watch_only, 0) as watch_only,
+		       COALESCE(order_tag_prefix, '') as order_tag_prefix, created_at, updated_at
 		FROM traders WHERE user_id = ? ORDER BY created_at DESC
 	`, userID)
 	if err != nil {
@@ -805,7 +1085,12 @@ func (d *Database) GetTraders(userID string) ([]*TraderRecord, error) {
 			&trader.BTCETHLeverage, &trader.AltcoinLeverage, &trader.TradingSymbols,
 			&trader.UseCoinPool, &trader.UseOITop, &trader.UseInsideCoins,
 			&trader.CustomPrompt, &trader.OverrideBasePrompt, &trader.SystemPromptTemplate,
-			&trader.IsCrossMargin,
+			&trader.IsCrossMargin, &trader.SymbolMarginModes, &trader.DryRun, &trader.AllocationPct, &trader.NettingRule, &trader.MaxTradesPerHour, &trader.MaxTradesPerDay, &trader.MaxEntriesPerSymbolPerDay, &trader.StopLossCooldownMinutes, &trader.FundingAvoidMinutes, &trader.FundingAdverseThreshold,
+			&trader.TrailingStopMode, &trader.TrailingInterval, &trader.TrailingLookback, &trader.TrailingATRMultiplier,
+			&trader.RequireApproval, &trader.ApprovalTimeoutSeconds,
+			&trader.SymbolWhitelist, &trader.SymbolBlacklist, &trader.SymbolMaxNotional,
+			&trader.EnsembleModelIDs, &trader.EnsembleMode,
+			&trader.ExecutionPolicy, &trader.WatchOnly, &trader.OrderTagPrefix,
 			&trader.CreatedAt, &trader.UpdatedAt,
 		)
 		if err != nil {
@@ -819,35 +1104,88 @@ func (d *Database) GetTraders(userID string) ([]*TraderRecord, error) {
 
 // UpdateTraderStatus 更新交易员状态
 func (d *Database) UpdateTraderStatus(userID, id string, isRunning bool) error {
-	_, err := d.db.Exec(`UPDATE traders SET is_running = ? WHERE id = ? AND user_id = ?`, isRunning, id, userID)
+	_, err := d.exec(`UPDATE traders SET is_running = ? WHERE id = ? AND user_id = ?`, isRunning, id, userID)
 	return err
 }
 
 // UpdateTrader 更新交易员配置
 func (d *Database) UpdateTrader(trader *TraderRecord) error {
-	_, err := d.db.Exec(`
+	_, err := d.exec(`
 		UPDATE traders SET
 			name = ?, ai_model_id = ?, exchange_id = ?, initial_balance = ?,
 			scan_interval_minutes = ?, btc_eth_leverage = ?, altcoin_leverage = ?,
 			trading_symbols = ?, custom_prompt = ?, override_base_prompt = ?,
-			system_prompt_template = ?, is_cross_margin = ?, updated_at = CURRENT_TIMESTAMP
+			system_prompt_template = ?, is_cross_margin = ?, symbol_margin_modes = ?,
+			dry_run = ?,
+			allocation_pct = ?,
+			netting_rule = ?,
+			max_trades_per_hour = ?,
+			max_trades_per_day = ?,
+			max_entries_per_symbol_per_day = ?,
+			stop_loss_cooldown_minutes = ?,
+			funding_avoid_minutes = ?,
+			funding_adverse_threshold = ?,
+			trailing_stop_mode = ?,
+			trailing_interval = ?,
+			trailing_lookback = ?,
+			trailing_atr_multiplier = ?,
+			require_approval = ?,
+			approval_timeout_seconds = ?,
+			symbol_whitelist = ?,
+			symbol_blacklist = ?,
+			symbol_max_notional = ?,
+			ensemble_model_ids = ?,
+			ensemble_mode = ?,
+			execution_policy = ?,
+			watch_only = ?,
+			order_tag_prefix = ?,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND user_id = ?
 	`, trader.Name, trader.AIModelID, trader.ExchangeID, trader.InitialBalance,
 		trader.ScanIntervalMinutes, trader.BTCETHLeverage, trader.AltcoinLeverage,
 		trader.TradingSymbols, trader.CustomPrompt, trader.OverrideBasePrompt,
-		trader.SystemPromptTemplate, trader.IsCrossMargin, trader.ID, trader.UserID)
+		trader.SystemPromptTemplate, trader.IsCrossMargin, trader.SymbolMarginModes,
+		trader.DryRun,
+		trader.AllocationPct,
+		trader.NettingRule,
+		trader.MaxTradesPerHour,
+		trader.MaxTradesPerDay,
+		trader.MaxEntriesPerSymbolPerDay,
+		trader.StopLossCooldownMinutes,
+		trader.FundingAvoidMinutes,
+		trader.FundingAdverseThreshold,
+		trader.TrailingStopMode,
+		trader.TrailingInterval,
+		trader.TrailingLookback,
+		trader.TrailingATRMultiplier,
+		trader.RequireApproval,
+		trader.ApprovalTimeoutSeconds,
+		trader.SymbolWhitelist,
+		trader.SymbolBlacklist,
+		trader.SymbolMaxNotional,
+		trader.EnsembleModelIDs,
+		trader.EnsembleMode,
+		trader.ExecutionPolicy,
+		trader.WatchOnly,
+		trader.OrderTagPrefix,
+		trader.ID, trader.UserID)
 	return err
 }
 
 // UpdateTraderCustomPrompt 更新交易员自定义Prompt
 func (d *Database) UpdateTraderCustomPrompt(userID, id string, customPrompt string, overrideBase bool) error {
-	_, err := d.db.Exec(`UPDATE traders SET custom_prompt = ?, override_base_prompt = ? WHERE id = ? AND user_id = ?`, customPrompt, overrideBase, id, userID)
+	_, err := d.exec(`UPDATE traders SET custom_prompt = ?, override_base_prompt = ? WHERE id = ? AND user_id = ?`, customPrompt, overrideBase, id, userID)
 	return err
 }
 
 // DeleteTrader 删除交易员
 func (d *Database) DeleteTrader(userID, id string) error {
-	_, err := d.db.Exec(`DELETE FROM traders WHERE id = ? AND user_id = ?`, id, userID)
+	_, err := d.exec(`DELETE FROM traders WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+	// 同时删除该交易员的定投计划
+	_, err = d.exec(`DELETE FROM recurring_orders WHERE trader_id = ? AND user_id = ?`, id, userID)
 	return err
 }
 
@@ -857,7 +1195,7 @@ func (d *Database) GetTraderConfig(userID, traderID string) (*TraderRecord, *AIM
 	var aiModel AIModelConfig
 	var exchange ExchangeConfig
 
-	err := d.db.QueryRow(`
+	err := d.queryRow(`
 		SELECT 
 			t.id, t.user_id, t.name, t.ai_model_id, t.exchange_id, t.initial_balance, t.scan_interval_minutes, t.is_running, t.created_at, t.updated_at,
 			a.id, a.user_id, a.name, a.provider, a.enabled, a.api_key, a.created_at, a.updated_at,
@@ -893,31 +1231,29 @@ func (d *Database) GetTraderConfig(userID, traderID string) (*TraderRecord, *AIM
 // GetSystemConfig 获取系统配置
 func (d *Database) GetSystemConfig(key string) (string, error) {
 	var value string
-	err := d.db.QueryRow(`SELECT value FROM system_config WHERE key = ?`, key).Scan(&value)
+	err := d.queryRow(`SELECT value FROM system_config WHERE key = ?`, key).Scan(&value)
 	return value, err
 }
 
 // SetSystemConfig 设置系统配置
 func (d *Database) SetSystemConfig(key, value string) error {
-	_, err := d.db.Exec(`
-		INSERT OR REPLACE INTO system_config (key, value) VALUES (?, ?)
-	`, key, value)
+	_, err := d.exec(d.dialect.upsert("system_config", "key", []string{"key", "value"}, []string{"?", "?"}), key, value)
 	return err
 }
 
 // CreateUserSignalSource 创建用户信号源配置
 func (d *Database) CreateUserSignalSource(userID, coinPoolURL, oiTopURL string) error {
-	_, err := d.db.Exec(`
-		INSERT OR REPLACE INTO user_signal_sources (user_id, coin_pool_url, oi_top_url, updated_at)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
-	`, userID, coinPoolURL, oiTopURL)
+	_, err := d.exec(d.dialect.upsert("user_signal_sources", "user_id",
+		[]string{"user_id", "coin_pool_url", "oi_top_url", "updated_at"},
+		[]string{"?", "?", "?", "CURRENT_TIMESTAMP"},
+	), userID, coinPoolURL, oiTopURL)
 	return err
 }
 
 // GetUserSignalSource 获取用户信号源配置
 func (d *Database) GetUserSignalSource(userID string) (*UserSignalSource, error) {
 	var source UserSignalSource
-	err := d.db.QueryRow(`
+	err := d.queryRow(`
 		SELECT id, user_id, coin_pool_url, oi_top_url, created_at, updated_at
 		FROM user_signal_sources WHERE user_id = ?
 	`, userID).Scan(
@@ -932,7 +1268,7 @@ func (d *Database) GetUserSignalSource(userID string) (*UserSignalSource, error)
 
 // UpdateUserSignalSource 更新用户信号源配置
 func (d *Database) UpdateUserSignalSource(userID, coinPoolURL, oiTopURL string) error {
-	_, err := d.db.Exec(`
+	_, err := d.exec(`
 		UPDATE user_signal_sources SET coin_pool_url = ?, oi_top_url = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE user_id = ?
 	`, coinPoolURL, oiTopURL, userID)
@@ -943,9 +1279,9 @@ func (d *Database) UpdateUserSignalSource(userID, coinPoolURL, oiTopURL string)
 func (d *Database) GetCustomCoins() []string {
 	var symbol string
 	var symbols []string
-	_ = d.db.QueryRow(`
-		SELECT GROUP_CONCAT(custom_coins , ',') as symbol
-		FROM main.traders where custom_coins != ''
+	_ = d.queryRow(`
+		SELECT ` + d.dialect.groupConcat("custom_coins") + ` as symbol
+		FROM traders where custom_coins != ''
 	`).Scan(&symbol)
 	// 检测用户是否未配置币种 - 兼容性
 	if symbol == "" {
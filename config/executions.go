@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// ExecutionRecord 一笔开仓的执行质量记录（数据库实体）
+type ExecutionRecord struct {
+	ID           int64     `json:"id"`
+	TraderID     string    `json:"trader_id"`
+	Exchange     string    `json:"exchange"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`   // long / short
+	Policy       string    `json:"policy"` // 执行算法: market / passive / twap / vwap
+	RequestedQty float64   `json:"requested_qty"`
+	FilledQty    float64   `json:"filled_qty"`
+	MakerQty     float64   `json:"maker_qty"`     // 以maker成交的数量
+	ArrivalPrice float64   `json:"arrival_price"` // 开始执行时的价格
+	AvgPrice     float64   `json:"avg_price"`     // 成交均价
+	SlippageBps  float64   `json:"slippage_bps"`  // 相对开始执行时价格的滑点（基点，正数=不利）
+	DurationMs   int64     `json:"duration_ms"`   // 开始执行到执行完成的耗时
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const executionRecordColumns = `id, trader_id, exchange, symbol, side, policy, requested_qty, filled_qty, COALESCE(maker_qty, 0),
+	COALESCE(arrival_price, 0), COALESCE(avg_price, 0), COALESCE(slippage_bps, 0), COALESCE(duration_ms, 0), COALESCE(error_message, ''), created_at`
+
+// RecordExecution 保存一笔开仓的执行质量记录
+func (d *Database) RecordExecution(rec *ExecutionRecord) error {
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	_, err := d.exec(`
+		INSERT INTO execution_records (trader_id, exchange, symbol, side, policy, requested_qty, filled_qty, maker_qty,
+			arrival_price, avg_price, slippage_bps, duration_ms, error_message, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rec.TraderID, rec.Exchange, rec.Symbol, rec.Side, rec.Policy, rec.RequestedQty, rec.FilledQty, rec.MakerQty,
+		rec.ArrivalPrice, rec.AvgPrice, rec.SlippageBps, rec.DurationMs, rec.Error, rec.CreatedAt.UTC())
+	return err
+}
+
+// GetExecutionRecords 获取since之后的执行质量记录（traderIDs为空时返回全部交易员）
+func (d *Database) GetExecutionRecords(since time.Time, traderIDs ...string) ([]*ExecutionRecord, error) {
+	query := `SELECT ` + executionRecordColumns + ` FROM execution_records WHERE created_at >= ?`
+	args := []interface{}{since.UTC()}
+	if len(traderIDs) > 0 {
+		query += ` AND trader_id IN (?` + strings.Repeat(", ?", len(traderIDs)-1) + `)`
+		for _, id := range traderIDs {
+			args = append(args, id)
+		}
+	}
+	rows, err := d.query(query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*ExecutionRecord, 0)
+	for rows.Next() {
+		var rec ExecutionRecord
+		if err := rows.Scan(
+			&rec.ID, &rec.TraderID, &rec.Exchange, &rec.Symbol, &rec.Side, &rec.Policy, &rec.RequestedQty, &rec.FilledQty, &rec.MakerQty,
+			&rec.ArrivalPrice, &rec.AvgPrice, &rec.SlippageBps, &rec.DurationMs, &rec.Error, &rec.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, &rec)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"nofx/market"
+	"time"
+)
+
+// SaveFundingRates 保存已结算的资金费率（同一交易对同一结算时间只保存一次），返回新增条数
+func (d *Database) SaveFundingRates(rates []market.FundingRate) (int, error) {
+	saved := 0
+	for _, r := range rates {
+		id := fmt.Sprintf("%s:%d", r.Symbol, r.Time.UnixMilli())
+		res, err := d.exec(`
+			INSERT OR IGNORE INTO funding_rates (id, symbol, funding_time, rate, mark_price)
+			VALUES (?, ?, ?, ?, ?)
+		`, id, r.Symbol, r.Time.UTC(), r.Rate, r.MarkPrice)
+		if err != nil {
+			return saved, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			saved++
+		}
+	}
+	return saved, nil
+}
+
+// GetFundingRates 获取交易对在since之后（含）的资金费率，按结算时间升序
+func (d *Database) GetFundingRates(symbol string, since time.Time) ([]market.FundingRate, error) {
+	rows, err := d.query(`
+		SELECT symbol, funding_time, rate, COALESCE(mark_price, 0) FROM funding_rates
+		WHERE symbol = ? AND funding_time >= ? ORDER BY funding_time
+	`, symbol, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]market.FundingRate, 0)
+	for rows.Next() {
+		var r market.FundingRate
+		if err := rows.Scan(&r.Symbol, &r.Time, &r.Rate, &r.MarkPrice); err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, rows.Err()
+}
+
+// GetLatestFundingTime 交易对最近一次已保存的结算时间（没有记录时返回零值）
+func (d *Database) GetLatestFundingTime(symbol string) (time.Time, error) {
+	rows, err := d.query(`SELECT funding_time FROM funding_rates WHERE symbol = ? ORDER BY funding_time DESC LIMIT 1`, symbol)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer rows.Close()
+
+	var latest time.Time
+	if rows.Next() {
+		if err := rows.Scan(&latest); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return latest, rows.Err()
+}
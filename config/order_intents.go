@@ -0,0 +1,64 @@
+package config
+
+import "time"
+
+// OrderIntent 下单意图（数据库实体）：提交订单前写入，提交后回写结果
+type OrderIntent struct {
+	ID            int64     `json:"id"`
+	TraderID      string    `json:"trader_id"`
+	Exchange      string    `json:"exchange"`
+	Symbol        string    `json:"symbol"`
+	Action        string    `json:"action"`
+	Quantity      float64   `json:"quantity"`
+	ClientOrderID string    `json:"client_order_id"`
+	Status        string    `json:"status"` // pending / submitted / failed / recovered / not_submitted / unverified
+	OrderID       int64     `json:"order_id"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreateOrderIntent 写入下单意图
+func (d *Database) CreateOrderIntent(intent *OrderIntent) error {
+	now := time.Now().UTC()
+	_, err := d.exec(`
+		INSERT INTO order_intents (trader_id, exchange, symbol, action, quantity, client_order_id, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, intent.TraderID, intent.Exchange, intent.Symbol, intent.Action, intent.Quantity, intent.ClientOrderID, intent.Status, now, now)
+	return err
+}
+
+// UpdateOrderIntent 回写下单意图的结果
+func (d *Database) UpdateOrderIntent(clientOrderID, status string, orderID int64, errMsg string) error {
+	_, err := d.exec(`
+		UPDATE order_intents SET status = ?, order_id = ?, error_message = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE client_order_id = ?
+	`, status, orderID, errMsg, clientOrderID)
+	return err
+}
+
+// GetPendingOrderIntents 获取交易员未确认结果的下单意图
+func (d *Database) GetPendingOrderIntents(traderID string) ([]*OrderIntent, error) {
+	rows, err := d.query(`
+		SELECT id, trader_id, exchange, symbol, action, COALESCE(quantity, 0), client_order_id, status,
+			COALESCE(order_id, 0), COALESCE(error_message, ''), created_at, updated_at
+		FROM order_intents WHERE trader_id = ? AND status = 'pending' ORDER BY id
+	`, traderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intents := make([]*OrderIntent, 0)
+	for rows.Next() {
+		var intent OrderIntent
+		if err := rows.Scan(
+			&intent.ID, &intent.TraderID, &intent.Exchange, &intent.Symbol, &intent.Action, &intent.Quantity, &intent.ClientOrderID, &intent.Status,
+			&intent.OrderID, &intent.Error, &intent.CreatedAt, &intent.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		intents = append(intents, &intent)
+	}
+	return intents, rows.Err()
+}
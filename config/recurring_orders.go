@@ -0,0 +1,104 @@
+package config
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RecurringOrder 定投计划（数据库实体）
+type RecurringOrder struct {
+	ID          int64      `json:"id"`
+	UserID      string     `json:"user_id"`
+	TraderID    string     `json:"trader_id"`
+	Symbol      string     `json:"symbol"`
+	Side        string     `json:"side"`         // long / short
+	NotionalUSD float64    `json:"notional_usd"` // 每次开仓的名义价值（USDT）
+	Leverage    int        `json:"leverage"`
+	Schedule    string     `json:"schedule"` // cron表达式（分 时 日 月 周），如 "0 9 * * MON"
+	Enabled     bool       `json:"enabled"`
+	RunCount    int        `json:"run_count"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastStatus  string     `json:"last_status"` // success / failed / skipped
+	LastMessage string     `json:"last_message"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+const recurringOrderColumns = `id, user_id, trader_id, symbol, side, notional_usd, COALESCE(leverage, 1), schedule,
+	COALESCE(enabled, 1), COALESCE(run_count, 0), last_run_at, COALESCE(last_status, ''), COALESCE(last_message, ''),
+	created_at, updated_at`
+
+// CreateRecurringOrder 创建定投计划
+func (d *Database) CreateRecurringOrder(order *RecurringOrder) error {
+	_, err := d.exec(`
+		INSERT INTO recurring_orders (user_id, trader_id, symbol, side, notional_usd, leverage, schedule, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, order.UserID, order.TraderID, order.Symbol, order.Side, order.NotionalUSD, order.Leverage, order.Schedule, order.Enabled)
+	return err
+}
+
+// GetRecurringOrders 获取用户的定投计划
+func (d *Database) GetRecurringOrders(userID string) ([]*RecurringOrder, error) {
+	return d.queryRecurringOrders(`SELECT `+recurringOrderColumns+` FROM recurring_orders WHERE user_id = ? ORDER BY id`, userID)
+}
+
+// GetEnabledRecurringOrders 获取所有用户已启用的定投计划（调度器使用）
+func (d *Database) GetEnabledRecurringOrders() ([]*RecurringOrder, error) {
+	return d.queryRecurringOrders(`SELECT `+recurringOrderColumns+` FROM recurring_orders WHERE COALESCE(enabled, 1) = ? ORDER BY id`, true)
+}
+
+// UpdateRecurringOrder 更新定投计划
+func (d *Database) UpdateRecurringOrder(order *RecurringOrder) error {
+	_, err := d.exec(`
+		UPDATE recurring_orders SET symbol = ?, side = ?, notional_usd = ?, leverage = ?, schedule = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?
+	`, order.Symbol, order.Side, order.NotionalUSD, order.Leverage, order.Schedule, order.Enabled, order.ID, order.UserID)
+	return err
+}
+
+// DeleteRecurringOrder 删除定投计划
+func (d *Database) DeleteRecurringOrder(userID string, id int64) error {
+	_, err := d.exec(`DELETE FROM recurring_orders WHERE id = ? AND user_id = ?`, id, userID)
+	return err
+}
+
+// RecordRecurringRun 记录定投计划的一次执行结果（跳过的执行不计入次数）
+func (d *Database) RecordRecurringRun(id int64, runAt time.Time, status, message string) error {
+	executed := 0
+	if status != "skipped" {
+		executed = 1
+	}
+	_, err := d.exec(`
+		UPDATE recurring_orders SET last_run_at = ?, last_status = ?, last_message = ?, run_count = COALESCE(run_count, 0) + ?
+		WHERE id = ?
+	`, runAt, status, message, executed, id)
+	return err
+}
+
+// queryRecurringOrders 查询并扫描定投计划
+func (d *Database) queryRecurringOrders(query string, args ...interface{}) ([]*RecurringOrder, error) {
+	rows, err := d.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*RecurringOrder, 0)
+	for rows.Next() {
+		var order RecurringOrder
+		var lastRunAt sql.NullTime
+		if err := rows.Scan(
+			&order.ID, &order.UserID, &order.TraderID, &order.Symbol, &order.Side, &order.NotionalUSD, &order.Leverage, &order.Schedule,
+			&order.Enabled, &order.RunCount, &lastRunAt, &order.LastStatus, &order.LastMessage,
+			&order.CreatedAt, &order.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if lastRunAt.Valid {
+			t := lastRunAt.Time
+			order.LastRunAt = &t
+		}
+		orders = append(orders, &order)
+	}
+	return orders, rows.Err()
+}
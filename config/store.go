@@ -0,0 +1,77 @@
+package config
+
+import (
+	"nofx/market"
+	"time"
+)
+
+// Store 配置存储接口（SQLite / PostgreSQL / MySQL 均由 Database 实现）
+type Store interface {
+	// 用户
+	CreateUser(user *User) error
+	EnsureAdminUser() error
+	GetUserByEmail(email string) (*User, error)
+	GetUserByID(userID string) (*User, error)
+	GetAllUsers() ([]string, error)
+	UpdateUserOTPVerified(userID string, verified bool) error
+
+	// AI模型
+	GetAIModels(userID string) ([]*AIModelConfig, error)
+	CreateAIModel(userID, id, name, provider string, enabled bool, apiKey, customAPIURL string) error
+	UpdateAIModel(userID, id string, enabled bool, apiKey, customAPIURL, customModelName string) error
+
+	// 交易所
+	GetExchanges(userID string) ([]*ExchangeConfig, error)
+	CreateExchange(userID, id, name, typ string, enabled bool, apiKey, secretKey string, testnet bool, hyperliquidWalletAddr, asterUser, asterSigner, asterPrivateKey string) error
+	UpdateExchange(userID, id string, enabled bool, apiKey, secretKey string, testnet bool, hyperliquidWalletAddr, asterUser, asterSigner, asterPrivateKey string) error
+
+	// 交易员
+	CreateTrader(trader *TraderRecord) error
+	GetTraders(userID string) ([]*TraderRecord, error)
+	GetTraderConfig(userID, traderID string) (*TraderRecord, *AIModelConfig, *ExchangeConfig, error)
+	UpdateTrader(trader *TraderRecord) error
+	UpdateTraderStatus(userID, id string, isRunning bool) error
+	UpdateTraderCustomPrompt(userID, id string, customPrompt string, overrideBase bool) error
+	DeleteTrader(userID, id string) error
+	GetCustomCoins() []string
+
+	// 系统配置
+	GetSystemConfig(key string) (string, error)
+	SetSystemConfig(key, value string) error
+
+	// 用户信号源
+	CreateUserSignalSource(userID, coinPoolURL, oiTopURL string) error
+	GetUserSignalSource(userID string) (*UserSignalSource, error)
+	UpdateUserSignalSource(userID, coinPoolURL, oiTopURL string) error
+
+	// 定投计划
+	CreateRecurringOrder(order *RecurringOrder) error
+	GetRecurringOrders(userID string) ([]*RecurringOrder, error)
+	GetEnabledRecurringOrders() ([]*RecurringOrder, error)
+	UpdateRecurringOrder(order *RecurringOrder) error
+	DeleteRecurringOrder(userID string, id int64) error
+	RecordRecurringRun(id int64, runAt time.Time, status, message string) error
+
+	// 执行质量
+	RecordExecution(rec *ExecutionRecord) error
+	GetExecutionRecords(since time.Time, traderIDs ...string) ([]*ExecutionRecord, error)
+
+	// 下单意图日志
+	CreateOrderIntent(intent *OrderIntent) error
+	UpdateOrderIntent(clientOrderID, status string, orderID int64, errMsg string) error
+	GetPendingOrderIntents(traderID string) ([]*OrderIntent, error)
+
+	// 复盘笔记
+	CreateTradeNote(note *TradeNote) error
+	GetTradeNotes(traderID, positionID string) ([]*TradeNote, error)
+	DeleteTradeNote(traderID, positionID string, id int64) (bool, error)
+
+	// 资金费率历史
+	SaveFundingRates(rates []market.FundingRate) (int, error)
+	GetFundingRates(symbol string, since time.Time) ([]market.FundingRate, error)
+	GetLatestFundingTime(symbol string) (time.Time, error)
+
+	Close() error
+}
+
+var _ Store = (*Database)(nil)
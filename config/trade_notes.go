@@ -0,0 +1,66 @@
+package config
+
+import "time"
+
+// TradeNote 历史持仓的复盘笔记（数据库实体）
+type TradeNote struct {
+	ID         int64     `json:"id"`
+	TraderID   string    `json:"trader_id"`
+	PositionID string    `json:"position_id"` // 持仓生命周期ID（symbol_side_开仓毫秒时间戳）
+	Author     string    `json:"author"`
+	Note       string    `json:"note"`
+	ImageURL   string    `json:"image_url"`
+	Attachment string    `json:"attachment"` // 自定义上下文（JSON文本）
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateTradeNote 添加复盘笔记
+func (d *Database) CreateTradeNote(note *TradeNote) error {
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = time.Now()
+	}
+	_, err := d.exec(`
+		INSERT INTO trade_notes (trader_id, position_id, author, note, image_url, attachment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, note.TraderID, note.PositionID, note.Author, note.Note, note.ImageURL, note.Attachment, note.CreatedAt.UTC())
+	return err
+}
+
+// GetTradeNotes 获取交易员的复盘笔记（positionID为空时返回全部持仓的笔记）
+func (d *Database) GetTradeNotes(traderID, positionID string) ([]*TradeNote, error) {
+	query := `SELECT id, trader_id, position_id, COALESCE(author, ''), note, COALESCE(image_url, ''), COALESCE(attachment, ''), created_at
+		FROM trade_notes WHERE trader_id = ?`
+	args := []interface{}{traderID}
+	if positionID != "" {
+		query += ` AND position_id = ?`
+		args = append(args, positionID)
+	}
+	rows, err := d.query(query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make([]*TradeNote, 0)
+	for rows.Next() {
+		var note TradeNote
+		if err := rows.Scan(&note.ID, &note.TraderID, &note.PositionID, &note.Author, &note.Note, &note.ImageURL, &note.Attachment, &note.CreatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, &note)
+	}
+	return notes, rows.Err()
+}
+
+// DeleteTradeNote 删除持仓的复盘笔记，返回是否有笔记被删除（笔记不存在或不属于该持仓时为false）
+func (d *Database) DeleteTradeNote(traderID, positionID string, id int64) (bool, error) {
+	result, err := d.exec(`DELETE FROM trade_notes WHERE id = ? AND trader_id = ? AND position_id = ?`, id, traderID, positionID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
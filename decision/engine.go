@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"nofx/features"
 	"nofx/market"
 	"nofx/mcp"
 	"nofx/pool"
@@ -66,6 +67,15 @@ type Context struct {
 	Performance     interface{}             `json:"-"` // 历史表现分析（logger.PerformanceAnalysis）
 	BTCETHLeverage  int                     `json:"-"` // BTC/ETH杠杆倍数（从配置读取）
 	AltcoinLeverage int                     `json:"-"` // 山寨币杠杆倍数（从配置读取）
+	MaxPositionUSD  float64                 `json:"-"` // 单笔开仓名义价值硬上限（0=只按净值倍数限制）
+	Now             time.Time               `json:"-"` // 决策时刻（零值=当前时间；回测回放时为K线收盘时间，保证prompt可复现）
+	Offline         bool                    `json:"-"` // 离线回放：不拼接实时特征源
+	Features        string                  `json:"-"` // 市场情绪与特征文本（为空时实时构建；回放时使用录制值）
+}
+
+// Caller AI调用接口（*mcp.Client 实现；回测回放时可替换为录制/桩模型）
+type Caller interface {
+	CallWithMessages(systemPrompt, userPrompt string) (string, error)
 }
 
 // Decision AI的交易决策
@@ -76,8 +86,12 @@ type Decision struct {
 	PositionSizeUSD float64 `json:"position_size_usd,omitempty"`
 	StopLoss        float64 `json:"stop_loss,omitempty"`
 	TakeProfit      float64 `json:"take_profit,omitempty"`
-	Confidence      int     `json:"confidence,omitempty"` // 信心度 (0-100)
-	RiskUSD         float64 `json:"risk_usd,omitempty"`   // 最大美元风险
+	StopLossPct     float64 `json:"stop_loss_pct,omitempty"`   // 止损距入场价的百分比（优先于stop_loss）
+	StopLossATR     float64 `json:"stop_loss_atr,omitempty"`   // 止损距离为入场时ATR14(1h)的倍数（优先于stop_loss）
+	TakeProfitPct   float64 `json:"take_profit_pct,omitempty"` // 止盈距入场价的百分比（优先于take_profit）
+	TakeProfitR     float64 `json:"take_profit_r,omitempty"`   // 止盈为止损距离的R倍（优先于take_profit）
+	Confidence      int     `json:"confidence,omitempty"`      // 信心度 (0-100)
+	RiskUSD         float64 `json:"risk_usd,omitempty"`        // 最大美元风险
 	Reasoning       string  `json:"reasoning"`
 }
 
@@ -88,6 +102,13 @@ type FullDecision struct {
 	CoTTrace     string     `json:"cot_trace"`     // 思维链分析（AI输出）
 	Decisions    []Decision `json:"decisions"`     // 具体决策列表
 	Timestamp    time.Time  `json:"timestamp"`
+	RawResponse  string     `json:"raw_response"` // AI原始响应（用于审计复现）
+	RequestedAt  time.Time  `json:"requested_at"` // 发起AI请求的时间
+
+	Models      []ModelResult `json:"models,omitempty"`       // 多模型投票时各模型的结果
+	EnsembleLog []string      `json:"ensemble_log,omitempty"` // 多模型投票明细
+
+	GuardrailLog []string `json:"guardrail_log,omitempty"` // 被风控策略拒绝或收紧的决策及原因
 }
 
 // GetFullDecision 获取AI的完整交易决策（批量分析所有币种和持仓）
@@ -106,21 +127,37 @@ func GetFullDecisionWithCustomPrompt(ctx *Context, mcpClient *mcp.Client, custom
 	systemPrompt := buildSystemPromptWithCustom(ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage, customPrompt, overrideBase, templateName)
 	userPrompt := buildUserPrompt(ctx)
 
+	return requestDecision(ctx, mcpClient, systemPrompt, userPrompt)
+}
+
+// GetFullDecisionFromContext 使用调用方已填充的 ctx.MarketDataMap 获取决策（不请求实时行情，用于回测回放）
+func GetFullDecisionFromContext(ctx *Context, caller Caller, customPrompt string, overrideBase bool, templateName string) (*FullDecision, error) {
+	systemPrompt := buildSystemPromptWithCustom(ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage, customPrompt, overrideBase, templateName)
+	userPrompt := buildUserPrompt(ctx)
+	return requestDecision(ctx, caller, systemPrompt, userPrompt)
+}
+
+// requestDecision 调用AI并解析决策（市场数据须已获取）
+func requestDecision(ctx *Context, mcpClient Caller, systemPrompt, userPrompt string) (*FullDecision, error) {
 	// 3. 调用AI API（使用 system + user prompt）
+	requestedAt := time.Now()
 	aiResponse, err := mcpClient.CallWithMessages(systemPrompt, userPrompt)
 	if err != nil {
-		return nil, fmt.Errorf("调用AI API失败: %w", err)
+		// 返回prompt供审计记录
+		return &FullDecision{SystemPrompt: systemPrompt, UserPrompt: userPrompt, RequestedAt: requestedAt, Timestamp: time.Now()},
+			fmt.Errorf("调用AI API失败: %w", err)
 	}
 
 	// 4. 解析AI响应
-	decision, err := parseFullDecisionResponse(aiResponse, ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage)
-	if err != nil {
-		return decision, fmt.Errorf("解析AI响应失败: %w", err)
-	}
-
+	decision, err := parseFullDecisionResponse(aiResponse, ctx)
 	decision.Timestamp = time.Now()
 	decision.SystemPrompt = systemPrompt // 保存系统prompt
 	decision.UserPrompt = userPrompt     // 保存输入prompt
+	decision.RawResponse = aiResponse
+	decision.RequestedAt = requestedAt
+	if err != nil {
+		return decision, fmt.Errorf("解析AI响应失败: %w", err)
+	}
 	return decision, nil
 }
 
@@ -280,7 +317,8 @@ func buildSystemPrompt(accountEquity float64, btcEthLeverage, altcoinLeverage in
 	sb.WriteString("字段说明:\n")
 	sb.WriteString("- `action`: open_long | open_short | close_long | close_short | hold | wait\n")
 	sb.WriteString("- `confidence`: 0-100（开仓建议≥75）\n")
-	sb.WriteString("- 开仓时必填: leverage, position_size_usd, stop_loss, take_profit, confidence, risk_usd, reasoning\n\n")
+	sb.WriteString("- 开仓时必填: leverage, position_size_usd, stop_loss, take_profit, confidence, risk_usd, reasoning\n")
+	sb.WriteString("- 止损止盈也可用 `stop_loss_pct`/`take_profit_pct`（距入场价百分比）、`stop_loss_atr`（1小时ATR14的倍数，如2）或 `take_profit_r`（止损距离的R倍）代替价格，系统按实际成交价换算\n\n")
 
 	return sb.String()
 }
@@ -316,7 +354,11 @@ func buildUserPrompt(ctx *Context) string {
 			// 计算持仓时长
 			holdingDuration := ""
 			if pos.UpdateTime > 0 {
-				durationMs := time.Now().UnixMilli() - pos.UpdateTime
+				now := ctx.Now
+				if now.IsZero() {
+					now = time.Now()
+				}
+				durationMs := now.UnixMilli() - pos.UpdateTime
 				durationMin := durationMs / (1000 * 60) // 转换为分钟
 				if durationMin < 60 {
 					holdingDuration = fmt.Sprintf(" | 持仓时长%d分钟", durationMin)
@@ -366,6 +408,16 @@ func buildUserPrompt(ctx *Context) string {
 	}
 	sb.WriteString("\n")
 
+	// 市场情绪与特征（各特征源带缓存，多模型投票时只构建一次）
+	if ctx.Features == "" && !ctx.Offline && features.Enabled() {
+		ctx.Features = features.Render()
+	}
+	if ctx.Features != "" {
+		sb.WriteString("## 🌡️ 市场情绪与特征\n\n")
+		sb.WriteString(ctx.Features)
+		sb.WriteString("\n")
+	}
+
 	// 夏普比率（直接传值，不要复杂格式化）
 	if ctx.Performance != nil {
 		// 直接从interface{}中提取SharpeRatio
@@ -387,12 +439,13 @@ func buildUserPrompt(ctx *Context) string {
 }
 
 // parseFullDecisionResponse 解析AI的完整决策响应
-func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthLeverage, altcoinLeverage int) (*FullDecision, error) {
+// 不符合格式或风控策略的决策逐条拒绝（越权的杠杆和仓位收紧），原因记录在 GuardrailLog 中，不影响其他决策
+func parseFullDecisionResponse(aiResponse string, ctx *Context) (*FullDecision, error) {
 	// 1. 提取思维链
 	cotTrace := extractCoTTrace(aiResponse)
 
 	// 2. 提取JSON决策列表
-	decisions, err := extractDecisions(aiResponse)
+	decisions, rejected, err := extractDecisions(aiResponse)
 	if err != nil {
 		return &FullDecision{
 			CoTTrace:  cotTrace,
@@ -400,17 +453,17 @@ func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthL
 		}, fmt.Errorf("提取决策失败: %w", err)
 	}
 
-	// 3. 验证决策
-	if err := validateDecisions(decisions, accountEquity, btcEthLeverage, altcoinLeverage); err != nil {
-		return &FullDecision{
-			CoTTrace:  cotTrace,
-			Decisions: decisions,
-		}, fmt.Errorf("决策验证失败: %w", err)
+	// 3. 换算相对止损止盈并按风控策略校验决策
+	accepted, guardLog := ApplyGuardrails(decisions, ctx)
+	guardLog = append(rejected, guardLog...)
+	for _, msg := range guardLog {
+		log.Print(msg)
 	}
 
 	return &FullDecision{
-		CoTTrace:  cotTrace,
-		Decisions: decisions,
+		CoTTrace:     cotTrace,
+		Decisions:    accepted,
+		GuardrailLog: guardLog,
 	}, nil
 }
 
@@ -428,18 +481,18 @@ func extractCoTTrace(response string) string {
 	return strings.TrimSpace(response)
 }
 
-// extractDecisions 提取JSON决策列表
-func extractDecisions(response string) ([]Decision, error) {
+// extractDecisions 提取JSON决策列表（同时返回不符合格式而被拒绝的条目）
+func extractDecisions(response string) ([]Decision, []string, error) {
 	// 直接查找JSON数组 - 找第一个完整的JSON数组
 	arrayStart := strings.Index(response, "[")
 	if arrayStart == -1 {
-		return nil, fmt.Errorf("无法找到JSON数组起始")
+		return nil, nil, fmt.Errorf("无法找到JSON数组起始")
 	}
 
 	// 从 [ 开始，匹配括号找到对应的 ]
 	arrayEnd := findMatchingBracket(response, arrayStart)
 	if arrayEnd == -1 {
-		return nil, fmt.Errorf("无法找到JSON数组结束")
+		return nil, nil, fmt.Errorf("无法找到JSON数组结束")
 	}
 
 	jsonContent := strings.TrimSpace(response[arrayStart : arrayEnd+1])
@@ -450,13 +503,13 @@ func extractDecisions(response string) ([]Decision, error) {
 	// 使用简单的字符串扫描而不是正则表达式
 	jsonContent = fixMissingQuotes(jsonContent)
 
-	// 解析JSON
-	var decisions []Decision
-	if err := json.Unmarshal([]byte(jsonContent), &decisions); err != nil {
-		return nil, fmt.Errorf("JSON解析失败: %w\nJSON内容: %s", err, jsonContent)
+	// 按严格schema逐条解析
+	decisions, rejected, err := decodeDecisionsStrict(jsonContent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("JSON解析失败: %w\nJSON内容: %s", err, jsonContent)
 	}
 
-	return decisions, nil
+	return decisions, rejected, nil
 }
 
 // fixMissingQuotes 替换中文引号为英文引号（避免输入法自动转换）
@@ -468,16 +521,6 @@ func fixMissingQuotes(jsonStr string) string {
 	return jsonStr
 }
 
-// validateDecisions 验证所有决策（需要账户信息和杠杆配置）
-func validateDecisions(decisions []Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int) error {
-	for i, decision := range decisions {
-		if err := validateDecision(&decision, accountEquity, btcEthLeverage, altcoinLeverage); err != nil {
-			return fmt.Errorf("决策 #%d 验证失败: %w", i+1, err)
-		}
-	}
-	return nil
-}
-
 // findMatchingBracket 查找匹配的右括号
 func findMatchingBracket(s string, start int) int {
 	if start >= len(s) || s[start] != '[' {
@@ -500,6 +543,11 @@ func findMatchingBracket(s string, start int) int {
 	return -1
 }
 
+// ValidateDecision 按AI决策的同一套规则验证外部决策（如外部信号）
+func ValidateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int) error {
+	return validateDecision(d, accountEquity, btcEthLeverage, altcoinLeverage)
+}
+
 // validateDecision 验证单个决策的有效性
 func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int) error {
 	// 验证action
@@ -519,12 +567,7 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 	// 开仓操作必须提供完整参数
 	if d.Action == "open_long" || d.Action == "open_short" {
 		// 根据币种使用配置的杠杆上限
-		maxLeverage := altcoinLeverage          // 山寨币使用配置的杠杆
-		maxPositionValue := accountEquity * 1.5 // 山寨币最多1.5倍账户净值
-		if d.Symbol == "BTCUSDT" || d.Symbol == "ETHUSDT" {
-			maxLeverage = btcEthLeverage          // BTC和ETH使用配置的杠杆
-			maxPositionValue = accountEquity * 10 // BTC/ETH最多10倍账户净值
-		}
+		maxLeverage, maxPositionValue := positionLimits(d.Symbol, accountEquity, btcEthLeverage, altcoinLeverage)
 
 		if d.Leverage <= 0 || d.Leverage > maxLeverage {
 			return fmt.Errorf("杠杆必须在1-%d之间（%s，当前配置上限%d倍）: %d", maxLeverage, d.Symbol, maxLeverage, d.Leverage)
@@ -0,0 +1,240 @@
+package decision
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 多模型投票方式
+const (
+	EnsembleMajority   = "majority"   // 超过半数模型给出相同操作才执行
+	EnsembleConfidence = "confidence" // 按信心度加权，支持权重超过总权重一半才执行
+)
+
+// ensembleDefaultConfidence 模型未给出信心度时的默认权重
+const ensembleDefaultConfidence = 50
+
+// ValidateEnsembleMode 校验投票方式（空=majority）
+func ValidateEnsembleMode(mode string) error {
+	switch mode {
+	case "", EnsembleMajority, EnsembleConfidence:
+		return nil
+	default:
+		return fmt.Errorf("无效的投票方式: %s（可选: majority, confidence）", mode)
+	}
+}
+
+// EnsembleMember 参与投票的模型
+type EnsembleMember struct {
+	Name   string
+	Client Caller // *mcp.Client；离线回放时为录制的响应
+}
+
+// ModelResult 单个模型的决策结果
+type ModelResult struct {
+	Model     string     `json:"model"`
+	CoTTrace  string     `json:"cot_trace"`
+	Decisions []Decision `json:"decisions"`
+	Error     string     `json:"error,omitempty"`
+
+	RawResponse  string   `json:"raw_response,omitempty"`  // 该模型的原始响应（用于回放）
+	GuardrailLog []string `json:"guardrail_log,omitempty"` // 该模型被风控策略拒绝或收紧的决策
+}
+
+// GetEnsembleDecision 使用相同的prompt并发请求多个模型，按投票结果合并决策
+// 未返回有效决策的模型视为对所有操作投"不操作"，因此任一模型幻觉或失败都不会单独触发交易
+func GetEnsembleDecision(ctx *Context, members []EnsembleMember, mode string, customPrompt string, overrideBase bool, templateName string) (*FullDecision, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("未配置投票模型")
+	}
+	if err := fetchMarketDataForContext(ctx); err != nil {
+		return nil, fmt.Errorf("获取市场数据失败: %w", err)
+	}
+	return GetEnsembleDecisionFromContext(ctx, members, mode, customPrompt, overrideBase, templateName)
+}
+
+// GetEnsembleDecisionFromContext 使用调用方已填充的 ctx.MarketDataMap 进行多模型投票（不请求实时行情，用于离线回放）
+func GetEnsembleDecisionFromContext(ctx *Context, members []EnsembleMember, mode string, customPrompt string, overrideBase bool, templateName string) (*FullDecision, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("未配置投票模型")
+	}
+	systemPrompt := buildSystemPromptWithCustom(ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage, customPrompt, overrideBase, templateName)
+	userPrompt := buildUserPrompt(ctx)
+
+	requestedAt := time.Now()
+	results := make([]ModelResult, len(members))
+	responses := make([]string, len(members))
+	var wg sync.WaitGroup
+	for i, m := range members {
+		wg.Add(1)
+		go func(i int, m EnsembleMember) {
+			defer wg.Done()
+			results[i].Model = m.Name
+			d, err := requestDecision(ctx, m.Client, systemPrompt, userPrompt)
+			if d != nil {
+				results[i].CoTTrace = d.CoTTrace
+				results[i].GuardrailLog = d.GuardrailLog
+				results[i].RawResponse = d.RawResponse
+				responses[i] = d.RawResponse
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Decisions = d.Decisions
+		}(i, m)
+	}
+	wg.Wait()
+
+	full := &FullDecision{
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		RequestedAt:  requestedAt,
+		Timestamp:    time.Now(),
+		Models:       results,
+	}
+	var cot, raw strings.Builder
+	succeeded := 0
+	for i, r := range results {
+		fmt.Fprintf(&cot, "===== [%s] =====\n%s\n\n", r.Model, r.CoTTrace)
+		fmt.Fprintf(&raw, "===== [%s] =====\n%s\n\n", r.Model, responses[i])
+		if r.Error == "" {
+			succeeded++
+		}
+		for _, msg := range r.GuardrailLog {
+			full.GuardrailLog = append(full.GuardrailLog, fmt.Sprintf("[%s] %s", r.Model, msg))
+		}
+	}
+	full.CoTTrace = strings.TrimSpace(cot.String())
+	full.RawResponse = strings.TrimSpace(raw.String())
+	if succeeded == 0 {
+		return full, fmt.Errorf("所有投票模型均未返回有效决策: %s", results[0].Error)
+	}
+
+	full.Decisions, full.EnsembleLog = CombineVotes(results, mode)
+	return full, nil
+}
+
+// CombineVotes 按 (币种, 操作) 统计各模型的投票，返回通过的决策及投票明细
+// 通过的决策以支持者中信心度最高的一个为准（保证止损止盈成套），仓位和杠杆取支持者中的最小值
+func CombineVotes(results []ModelResult, mode string) ([]Decision, []string) {
+	type ballot struct {
+		symbol, action string
+		supporters     []Decision
+		models         []string
+	}
+	ballots := make(map[string]*ballot)
+	var keys []string
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		seen := make(map[string]bool) // 同一模型对同一操作只计一票
+		for _, d := range r.Decisions {
+			if d.Action == "hold" || d.Action == "wait" {
+				continue
+			}
+			key := d.Symbol + "|" + d.Action
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			b, ok := ballots[key]
+			if !ok {
+				b = &ballot{symbol: d.Symbol, action: d.Action}
+				ballots[key] = b
+				keys = append(keys, key)
+			}
+			b.supporters = append(b.supporters, d)
+			b.models = append(b.models, r.Model)
+		}
+	}
+
+	total := len(results)
+	var passed []*ballot
+	var voteLog []string
+	for _, key := range keys {
+		b := ballots[key]
+		var ok bool
+		var detail string
+		if mode == EnsembleConfidence {
+			weight := 0.0
+			for _, d := range b.supporters {
+				weight += float64(voteConfidence(d))
+			}
+			share := weight / float64(total*100)
+			ok = share > 0.5
+			detail = fmt.Sprintf("信心度加权 %.0f%%", share*100)
+		} else {
+			ok = len(b.supporters)*2 > total
+			detail = fmt.Sprintf("%d/%d 票", len(b.supporters), total)
+		}
+		mark := "❌"
+		if ok {
+			mark = "✅"
+			passed = append(passed, b)
+		}
+		voteLog = append(voteLog, fmt.Sprintf("🗳️ %s %s %s: %s（支持: %s）", mark, b.symbol, b.action, detail, strings.Join(b.models, ", ")))
+	}
+
+	// 同一币种多空开仓同时通过说明模型严重分歧，全部放弃
+	opens := make(map[string]int)
+	for _, b := range passed {
+		if b.action == "open_long" || b.action == "open_short" {
+			opens[b.symbol]++
+		}
+	}
+
+	var decisions []Decision
+	for _, b := range passed {
+		if opens[b.symbol] > 1 && (b.action == "open_long" || b.action == "open_short") {
+			voteLog = append(voteLog, fmt.Sprintf("🗳️ ⚠️ %s 多空开仓同时通过，放弃 %s", b.symbol, b.action))
+			continue
+		}
+		decisions = append(decisions, mergeSupporters(b.supporters, len(b.supporters), total))
+	}
+	sort.SliceStable(decisions, func(i, j int) bool { return decisions[i].Symbol < decisions[j].Symbol })
+	return decisions, voteLog
+}
+
+// voteConfidence 决策的投票权重（0-100）
+func voteConfidence(d Decision) int {
+	if d.Confidence <= 0 {
+		return ensembleDefaultConfidence
+	}
+	if d.Confidence > 100 {
+		return 100
+	}
+	return d.Confidence
+}
+
+// mergeSupporters 合并同一操作的多个支持决策
+func mergeSupporters(supporters []Decision, votes, total int) Decision {
+	best := supporters[0]
+	confidenceSum := 0
+	for _, d := range supporters {
+		if voteConfidence(d) > voteConfidence(best) {
+			best = d
+		}
+		confidenceSum += voteConfidence(d)
+	}
+	merged := best
+	for _, d := range supporters {
+		if d.PositionSizeUSD > 0 && d.PositionSizeUSD < merged.PositionSizeUSD {
+			merged.PositionSizeUSD = d.PositionSizeUSD
+		}
+		if d.Leverage > 0 && d.Leverage < merged.Leverage {
+			merged.Leverage = d.Leverage
+		}
+		if d.RiskUSD > 0 && d.RiskUSD < merged.RiskUSD {
+			merged.RiskUSD = d.RiskUSD
+		}
+	}
+	merged.Confidence = int(math.Round(float64(confidenceSum) / float64(len(supporters))))
+	merged.Reasoning = fmt.Sprintf("[投票 %d/%d] %s", votes, total, best.Reasoning)
+	return merged
+}
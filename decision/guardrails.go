@@ -0,0 +1,141 @@
+package decision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// positionLimits 按币种返回杠杆上限及单币种仓位价值上限
+func positionLimits(symbol string, accountEquity float64, btcEthLeverage, altcoinLeverage int) (int, float64) {
+	if symbol == "BTCUSDT" || symbol == "ETHUSDT" {
+		return btcEthLeverage, accountEquity * 10 // BTC/ETH最多10倍账户净值
+	}
+	return altcoinLeverage, accountEquity * 1.5 // 山寨币最多1.5倍账户净值
+}
+
+// decodeDecisionsStrict 按严格schema逐条解析决策（未知字段、类型错误、缺少symbol/action的条目被拒绝）
+// 返回通过解析的决策及被拒绝条目的原因；整体不是JSON数组时返回错误
+func decodeDecisionsStrict(jsonContent string) ([]Decision, []string, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal([]byte(jsonContent), &raws); err != nil {
+		return nil, nil, err
+	}
+
+	var decisions []Decision
+	var rejected []string
+	for i, raw := range raws {
+		var d Decision
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&d); err != nil {
+			rejected = append(rejected, fmt.Sprintf("🛡️ 拒绝决策 #%d: 不符合决策格式: %v", i+1, err))
+			continue
+		}
+		d.Symbol = strings.ToUpper(strings.TrimSpace(d.Symbol))
+		if d.Symbol == "" || d.Action == "" {
+			rejected = append(rejected, fmt.Sprintf("🛡️ 拒绝决策 #%d: 缺少 symbol 或 action", i+1))
+			continue
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, rejected, nil
+}
+
+// ApplyGuardrails 在执行前逐条检查AI决策：越权的杠杆和仓位收紧到上限，其余违规决策拒绝，每项处理都记录原因
+//   - 开仓币种必须是候选币种或已有持仓，平仓必须有对应方向的持仓
+//   - 开仓必须带止损止盈（可为相对形式，按当前价换算），并满足 ValidateDecision 的全部规则
+//   - 同一币种同一操作只保留第一条
+func ApplyGuardrails(decisions []Decision, ctx *Context) ([]Decision, []string) {
+	allowed := make(map[string]bool)
+	for _, c := range ctx.CandidateCoins {
+		allowed[c.Symbol] = true
+	}
+	held := make(map[string]bool) // 币种_方向
+	for _, pos := range ctx.Positions {
+		allowed[pos.Symbol] = true
+		held[pos.Symbol+"_"+pos.Side] = true
+	}
+	marketPrice := func(symbol string) float64 {
+		if data, ok := ctx.MarketDataMap[symbol]; ok {
+			return data.CurrentPrice
+		}
+		return 0
+	}
+
+	var accepted []Decision
+	var logs []string
+	seen := make(map[string]bool)
+	reject := func(d Decision, format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf("🛡️ 拒绝 %s %s: %s", d.Symbol, d.Action, fmt.Sprintf(format, args...)))
+	}
+	for _, d := range decisions {
+		key := d.Symbol + "|" + d.Action
+		if seen[key] {
+			reject(d, "重复决策")
+			continue
+		}
+		seen[key] = true
+
+		switch d.Action {
+		case "hold", "wait":
+			accepted = append(accepted, d)
+			continue
+		case "close_long", "close_short":
+			side := strings.TrimPrefix(d.Action, "close_")
+			if !held[d.Symbol+"_"+side] {
+				reject(d, "没有对应的%s仓", map[string]string{"long": "多", "short": "空"}[side])
+				continue
+			}
+			accepted = append(accepted, d)
+			continue
+		case "open_long", "open_short":
+		default:
+			reject(d, "无效的action")
+			continue
+		}
+
+		if !allowed[d.Symbol] {
+			reject(d, "不在候选币种或持仓中")
+			continue
+		}
+		if d.HasRelativeLevels() { // 用于校验；实际挂单时按成交价重新换算
+			if err := d.ResolveLevels(marketPrice(d.Symbol)); err != nil {
+				reject(d, "止损止盈换算失败: %v", err)
+				continue
+			}
+		}
+		if d.StopLoss <= 0 {
+			reject(d, "缺少止损")
+			continue
+		}
+		if d.Leverage <= 0 || d.PositionSizeUSD <= 0 {
+			reject(d, "缺少杠杆或仓位大小")
+			continue
+		}
+
+		maxLeverage, maxPositionValue := positionLimits(d.Symbol, ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage)
+		if ctx.MaxPositionUSD > 0 && ctx.MaxPositionUSD < maxPositionValue {
+			maxPositionValue = ctx.MaxPositionUSD
+		}
+		if d.Leverage > maxLeverage {
+			logs = append(logs, fmt.Sprintf("🛡️ 收紧 %s %s: 杠杆 %dx → %dx", d.Symbol, d.Action, d.Leverage, maxLeverage))
+			d.Leverage = maxLeverage
+		}
+		if d.PositionSizeUSD > maxPositionValue {
+			logs = append(logs, fmt.Sprintf("🛡️ 收紧 %s %s: 仓位 %.2f → %.2f USDT", d.Symbol, d.Action, d.PositionSizeUSD, maxPositionValue))
+			if d.RiskUSD > 0 {
+				d.RiskUSD *= maxPositionValue / d.PositionSizeUSD
+			}
+			d.PositionSizeUSD = maxPositionValue
+		}
+
+		if err := validateDecision(&d, ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage); err != nil {
+			reject(d, "%v", err)
+			continue
+		}
+		accepted = append(accepted, d)
+	}
+	return accepted, logs
+}
@@ -0,0 +1,77 @@
+package decision
+
+import (
+	"fmt"
+	"nofx/market"
+)
+
+// ATR止损使用的K线周期及ATR周期（如 stop_loss_atr=2 表示止损距离为 2×ATR14(1h)）
+const (
+	ATRStopInterval = "1h"
+	ATRStopPeriod   = 14
+)
+
+// HasRelativeLevels 止损/止盈是否以百分比、ATR倍数或R倍数给出（需按成交价换算为触发价）
+func (d *Decision) HasRelativeLevels() bool {
+	return d.StopLossPct > 0 || d.StopLossATR > 0 || d.TakeProfitPct > 0 || d.TakeProfitR > 0
+}
+
+// ResolveLevels 以入场价将百分比/ATR倍数/R倍数形式的止损止盈换算为绝对触发价
+// stop_loss_pct / take_profit_pct 为距入场价的百分比；stop_loss_atr 为入场时ATR的倍数；
+// take_profit_r 为止损距离的倍数（需同时给出止损）。相对形式优先于绝对价格；未给出相对形式的一侧保持原值
+func (d *Decision) ResolveLevels(entryPrice float64) error {
+	if !d.HasRelativeLevels() {
+		return nil
+	}
+	if entryPrice <= 0 {
+		return fmt.Errorf("入场价无效，无法换算止损止盈: %.4f", entryPrice)
+	}
+	var sign float64
+	switch d.Action {
+	case "open_long":
+		sign = 1
+	case "open_short":
+		sign = -1
+	default:
+		return nil
+	}
+	if d.StopLossPct >= 100 || d.StopLossPct < 0 || d.StopLossATR < 0 || d.TakeProfitPct < 0 || d.TakeProfitR < 0 {
+		return fmt.Errorf("止损百分比必须在0-100之间，ATR倍数、止盈百分比及R倍数不能为负")
+	}
+	if d.StopLossPct > 0 && d.StopLossATR > 0 {
+		return fmt.Errorf("stop_loss_pct 与 stop_loss_atr 只能二选一")
+	}
+	if d.TakeProfitPct > 0 && d.TakeProfitR > 0 {
+		return fmt.Errorf("take_profit_pct 与 take_profit_r 只能二选一")
+	}
+
+	switch {
+	case d.StopLossPct > 0:
+		d.StopLoss = entryPrice * (1 - sign*d.StopLossPct/100)
+	case d.StopLossATR > 0:
+		atr, err := market.GetATR(d.Symbol, ATRStopInterval, ATRStopPeriod)
+		if err != nil {
+			return err
+		}
+		d.StopLoss = entryPrice - sign*d.StopLossATR*atr
+		if d.StopLoss <= 0 {
+			return fmt.Errorf("ATR止损价无效: %.4f（ATR%d=%.4f）", d.StopLoss, ATRStopPeriod, atr)
+		}
+	}
+	switch {
+	case d.TakeProfitPct > 0:
+		d.TakeProfit = entryPrice * (1 + sign*d.TakeProfitPct/100)
+	case d.TakeProfitR > 0:
+		risk := sign * (entryPrice - d.StopLoss)
+		if d.StopLoss <= 0 || risk <= 0 {
+			return fmt.Errorf("按R倍数设置止盈需要位于入场价亏损一侧的止损: 入场 %.4f, 止损 %.4f", entryPrice, d.StopLoss)
+		}
+		d.TakeProfit = entryPrice + sign*d.TakeProfitR*risk
+	default:
+		return nil
+	}
+	if d.TakeProfit <= 0 {
+		return fmt.Errorf("换算后的止盈价无效: %.4f", d.TakeProfit)
+	}
+	return nil
+}
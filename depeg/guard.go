@@ -0,0 +1,197 @@
+package depeg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSourceURL 默认稳定币价格来源（Kraken公开行情，?pair=USDTUSD,USDCUSD）
+const DefaultSourceURL = "https://api.kraken.com/0/public/Ticker"
+
+// Config 稳定币脱锚保护配置
+type Config struct {
+	Coins       []string      // 监控的稳定币
+	AlertPct    float64       // 偏离1美元超过该百分比时告警并暂停开仓（0=关闭保护）
+	ReduceAtPct float64       // 偏离超过该百分比时按比例减仓（0=不减仓）
+	ReducePct   float64       // 减仓比例
+	Refresh     time.Duration // 价格刷新间隔
+}
+
+// Status 稳定币价格状态
+type Status struct {
+	Coin         string    `json:"coin"`
+	Price        float64   `json:"price"`         // 美元价格
+	DeviationPct float64   `json:"deviation_pct"` // |价格-1| × 100
+	Alert        bool      `json:"alert"`         // 超过告警阈值（暂停开仓）
+	Reduce       bool      `json:"reduce"`        // 超过减仓阈值
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PriceSource 稳定币美元价格来源
+type PriceSource interface {
+	Prices(ctx context.Context, coins []string) (map[string]float64, error)
+}
+
+// KrakenSource 从Kraken公开行情获取稳定币兑USD价格
+type KrakenSource struct {
+	URL string
+}
+
+// Prices 实现 PriceSource
+func (s *KrakenSource) Prices(ctx context.Context, coins []string) (map[string]float64, error) {
+	pairs := make([]string, len(coins))
+	for i, coin := range coins {
+		pairs[i] = coin + "USD"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"?pair="+strings.Join(pairs, ","), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建稳定币价格请求失败: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求稳定币价格失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取稳定币价格失败: %w", err)
+	}
+
+	var result struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			Last []string `json:"c"` // [最新成交价, 成交量]
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析稳定币价格失败: %w", err)
+	}
+	if len(result.Error) > 0 {
+		return nil, fmt.Errorf("稳定币价格API返回错误: %s", strings.Join(result.Error, "; "))
+	}
+
+	// Kraken的交易对名称不固定（如 USDTZUSD、USDCUSD），按币种前缀匹配
+	prices := make(map[string]float64)
+	for pair, ticker := range result.Result {
+		for _, coin := range coins {
+			if strings.HasPrefix(pair, coin) && len(ticker.Last) > 0 {
+				if price, err := strconv.ParseFloat(ticker.Last[0], 64); err == nil && price > 0 {
+					prices[coin] = price
+				}
+			}
+		}
+	}
+	return prices, nil
+}
+
+var (
+	mu     sync.RWMutex
+	config = Config{
+		Coins:   []string{"USDT", "USDC"},
+		Refresh: time.Minute,
+	}
+	source PriceSource = &KrakenSource{URL: DefaultSourceURL}
+
+	// 价格缓存
+	statuses  map[string]Status
+	fetchedAt time.Time
+)
+
+// SetConfig 设置脱锚保护阈值（AlertPct为0时关闭保护）
+func SetConfig(cfg Config) {
+	if len(cfg.Coins) == 0 {
+		cfg.Coins = []string{"USDT", "USDC"}
+	}
+	for i, coin := range cfg.Coins {
+		cfg.Coins[i] = strings.ToUpper(strings.TrimSpace(coin))
+	}
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = time.Minute
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	config = cfg
+	statuses, fetchedAt = nil, time.Time{}
+}
+
+// SetSource 设置价格来源
+func SetSource(src PriceSource) {
+	mu.Lock()
+	defer mu.Unlock()
+	source = src
+	statuses, fetchedAt = nil, time.Time{}
+}
+
+// Enabled 是否启用了脱锚保护
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.AlertPct > 0
+}
+
+// ReducePct 脱锚超过减仓阈值时的减仓比例
+func ReducePct() float64 {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.ReducePct
+}
+
+// Check 获取指定稳定币的当前状态（按刷新间隔缓存，请求失败时沿用上次结果）
+func Check(coin string) (Status, bool) {
+	all := Statuses()
+	s, ok := all[strings.ToUpper(coin)]
+	return s, ok
+}
+
+// Statuses 获取所有监控稳定币的当前状态（未启用保护时返回nil）
+func Statuses() map[string]Status {
+	mu.RLock()
+	cfg, src, cached := config, source, statuses
+	fresh := time.Since(fetchedAt) < cfg.Refresh
+	mu.RUnlock()
+
+	if cfg.AlertPct <= 0 {
+		return nil
+	}
+	if cached != nil && fresh {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	prices, err := src.Prices(ctx, cfg.Coins)
+	if err != nil {
+		log.Printf("⚠️  获取稳定币价格失败: %v", err)
+		mu.Lock()
+		fetchedAt = time.Now() // 失败后同样等待一个刷新间隔再重试
+		mu.Unlock()
+		return cached
+	}
+
+	now := time.Now()
+	result := make(map[string]Status, len(prices))
+	for coin, price := range prices {
+		deviation := math.Abs(price-1) * 100
+		result[coin] = Status{
+			Coin:         coin,
+			Price:        price,
+			DeviationPct: deviation,
+			Alert:        deviation >= cfg.AlertPct,
+			Reduce:       cfg.ReduceAtPct > 0 && deviation >= cfg.ReduceAtPct,
+			UpdatedAt:    now,
+		}
+	}
+	mu.Lock()
+	statuses, fetchedAt = result, now
+	mu.Unlock()
+	return result
+}
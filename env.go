@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envConfigKeys 环境变量 -> 系统配置项（用于无config.json的容器部署）
+var envConfigKeys = map[string]string{
+	"NOFX_ADMIN_MODE":                   "admin_mode",
+	"NOFX_API_SERVER_PORT":              "api_server_port",
+	"NOFX_TRUSTED_PROXIES":              "trusted_proxies",
+	"NOFX_GRPC_PORT":                    "grpc_port",
+	"NOFX_INSTANCE_ID":                  "instance_id",
+	"NOFX_USE_DEFAULT_COINS":            "use_default_coins",
+	"NOFX_COIN_POOL_API_URL":            "coin_pool_api_url",
+	"NOFX_OI_TOP_API_URL":               "oi_top_api_url",
+	"NOFX_INSIDE_COINS":                 "inside_coins",
+	"NOFX_MAX_DAILY_LOSS":               "max_daily_loss",
+	"NOFX_MAX_DRAWDOWN":                 "max_drawdown",
+	"NOFX_STOP_TRADING_MINUTES":         "stop_trading_minutes",
+	"NOFX_BTC_ETH_LEVERAGE":             "btc_eth_leverage",
+	"NOFX_ALTCOIN_LEVERAGE":             "altcoin_leverage",
+	"NOFX_JWT_SECRET":                   "jwt_secret",
+	"NOFX_ADMIN_PASSWORD":               "admin_password",
+	"NOFX_API_TLS_CERT":                 "api_tls_cert",
+	"NOFX_API_TLS_KEY":                  "api_tls_key",
+	"NOFX_API_TOKENS":                   "api_tokens",
+	"NOFX_MARGIN_TOPUP_THRESHOLD":       "margin_topup_threshold",
+	"NOFX_MARGIN_TOPUP_AMOUNT":          "margin_topup_amount",
+	"NOFX_DEBUG_LOG":                    "debug_log",
+	"NOFX_LOCALE":                       "locale",
+	"NOFX_BLACKOUT_WINDOWS":             "blackout_windows",
+	"NOFX_ECONOMIC_CALENDAR_URL":        "economic_calendar_url",
+	"NOFX_ECONOMIC_CALENDAR_COUNTRIES":  "economic_calendar_countries",
+	"NOFX_BLACKOUT_PADDING_MINUTES":     "blackout_padding_minutes",
+	"NOFX_BLACKOUT_REDUCE_PCT":          "blackout_reduce_pct",
+	"NOFX_CORRELATION_GROUPS":           "correlation_groups",
+	"NOFX_MAX_GROUP_EXPOSURE_PCT":       "max_group_exposure_pct",
+	"NOFX_RISK_REPORT_INTERVAL_MINUTES": "risk_report_interval_minutes",
+	"NOFX_REPLAY_LOG":                   "replay_log",
+	"NOFX_FUNDING_HISTORY_SYMBOLS":      "funding_history_symbols",
+	"NOFX_FAULT_INJECTION":              "fault_injection",
+	"NOFX_MARKET_ORDER_GUARD":           "market_order_guard",
+	"NOFX_REQUEST_BUDGET":               "request_budget",
+	"NOFX_MAX_SLIPPAGE_TICKS":           "max_slippage_ticks",
+	"NOFX_AI_MAX_POSITION_USD":          "ai_max_position_usd",
+	"NOFX_API_KEY_PREFLIGHT":            "api_key_preflight",
+	"NOFX_REFUSE_WITHDRAWAL_KEYS":       "refuse_withdrawal_keys",
+	"NOFX_TWO_PERSON_THRESHOLD_USD":     "two_person_threshold_usd",
+	"NOFX_LLM_DAILY_BUDGET_USD":         "llm_daily_budget_usd",
+	"NOFX_LLM_PRICES":                   "llm_prices",
+	"NOFX_FEATURE_PROVIDERS":            "feature_providers",
+	"NOFX_SIGNAL_INGEST_URL":            "signal_ingest_url",
+	"NOFX_SIGNAL_INGEST_TOPIC":          "signal_ingest_topic",
+	"NOFX_EVENT_EXPORT_URL":             "event_export_url",
+	"NOFX_EVENT_EXPORT_TOPIC":           "event_export_topic",
+	"NOFX_JOURNAL_WEBHOOK_URL":          "journal_webhook_url",
+	"NOFX_JOB_WORKERS":                  "job_workers",
+	"NOFX_RECURRING_SCHEDULER_ENABLED":  "recurring_scheduler_enabled",
+	"NOFX_LEADERBOARD_SUMMARY_HOUR":     "leaderboard_summary_hour",
+	"NOFX_EXECUTION_REPORT_HOUR":        "execution_report_hour",
+	"NOFX_HEARTBEAT_INTERVAL_MINUTES":   "heartbeat_interval_minutes",
+	"NOFX_PERFORMANCE_DIGEST":           "performance_digest",
+	"NOFX_DASHBOARD_URL":                "dashboard_url",
+	"NOFX_DEPEG_COINS":                  "depeg_coins",
+	"NOFX_DEPEG_ALERT_PCT":              "depeg_alert_pct",
+	"NOFX_DEPEG_REDUCE_AT_PCT":          "depeg_reduce_at_pct",
+	"NOFX_DEPEG_REDUCE_PCT":             "depeg_reduce_pct",
+	"NOFX_MAINTENANCE_WINDOWS":          "maintenance_windows",
+	"NOFX_MAINTENANCE_FEED_URL":         "maintenance_feed_url",
+	"NOFX_MAINTENANCE_BINANCE_STATUS":   "maintenance_binance_status",
+	"NOFX_MAINTENANCE_LEAD_MINUTES":     "maintenance_lead_minutes",
+	"NOFX_MAINTENANCE_HEDGE_TRADER_ID":  "maintenance_hedge_trader_id",
+	"NOFX_MAINTENANCE_HEDGE_LEVERAGE":   "maintenance_hedge_leverage",
+	"NOFX_DELISTING_CLOSE_HOURS":        "delisting_close_hours",
+	"NOFX_DELISTING_MIGRATE_TRADER_ID":  "delisting_migrate_trader_id",
+	"NOFX_DELISTING_MIGRATE_LEVERAGE":   "delisting_migrate_leverage",
+	"NOFX_NEW_LISTING_EXCHANGES":        "new_listing_exchanges",
+	"NOFX_NEW_LISTING_SCAN_MINUTES":     "new_listing_scan_minutes",
+	"NOFX_NEW_LISTING_AUTO_ADD_TRADERS": "new_listing_auto_add_traders",
+	"NOFX_NEW_LISTING_MIN_AGE_HOURS":    "new_listing_min_age_hours",
+	"NOFX_NEW_LISTING_MAX_PER_TRADER":   "new_listing_max_per_trader",
+	"NOFX_NEW_LISTING_MAX_LEVERAGE":     "new_listing_max_leverage",
+	"NOFX_NEW_LISTING_MAX_POSITION_USD": "new_listing_max_position_usd",
+	"NOFX_QUOTE_CURRENCY":               "quote_currency",
+	"NOFX_FX_RATE_URL":                  "fx_rate_url",
+	"NOFX_FX_STATIC_RATES":              "fx_static_rates",
+	"NOFX_DELTA_HEDGES":                 "delta_hedges",
+	"NOFX_BASIS_STRATEGIES":             "basis_strategies",
+	"NOFX_MARKET_MAKERS":                "market_makers",
+	"NOFX_UNIVERSES":                    "universes",
+	"NOFX_TELEGRAM_BOT_TOKEN":           "telegram_bot_token",
+	"NOFX_TELEGRAM_OPERATOR_IDS":        "telegram_operator_ids",
+	"NOFX_TELEGRAM_OPERATOR_USERS":      "telegram_operator_users",
+	"NOFX_SMTP_URL":                     "smtp_url",
+	"NOFX_SMTP_FROM":                    "smtp_from",
+	"NOFX_SMTP_TO":                      "smtp_to",
+	"NOFX_SMTP_EVENTS":                  "smtp_events",
+	"NOFX_LIQUIDATION_ALERT_PCT":        "liquidation_alert_pct",
+	"NOFX_PAGERDUTY_ROUTING_KEY":        "pagerduty_routing_key",
+	"NOFX_OPSGENIE_API_KEY":             "opsgenie_api_key",
+	"NOFX_OPSGENIE_API_URL":             "opsgenie_api_url",
+	"NOFX_WEBHOOK_URL":                  "webhook_url",
+	"NOFX_WEBHOOK_SECRET":               "webhook_secret",
+	"NOFX_OTEL_ENDPOINT":                "otel_endpoint",
+	"NOFX_OTEL_SAMPLE_RATIO":            "otel_sample_ratio",
+	"NOFX_SHADOW_BOOK_RECONCILE":        "shadow_book_reconcile",
+	"NOFX_PANIC_ACTIONS":                "panic_actions",
+	"NOFX_DAY_RESET_TIMEZONE":           "day_reset_timezone",
+	"NOFX_BALANCE_RECONCILE_TOLERANCE":  "balance_reconcile_tolerance",
+}
+
+// envSystemConfigs 读取已设置的NOFX_*环境变量
+func envSystemConfigs() map[string]string {
+	configs := make(map[string]string)
+	for env, key := range envConfigKeys {
+		if value, ok := os.LookupEnv(env); ok {
+			configs[key] = strings.TrimSpace(value)
+		}
+	}
+
+	// NOFX_DEFAULT_COINS 使用逗号分隔，存储为JSON数组
+	if value, ok := os.LookupEnv("NOFX_DEFAULT_COINS"); ok {
+		var coins []string
+		for _, coin := range strings.Split(value, ",") {
+			if coin = strings.ToUpper(strings.TrimSpace(coin)); coin != "" {
+				coins = append(coins, coin)
+			}
+		}
+		if data, err := json.Marshal(coins); err == nil && len(coins) > 0 {
+			configs["default_coins"] = string(data)
+		}
+	}
+	return configs
+}
+
+// envAIProviders 可通过 NOFX_<PROVIDER>_API_KEY / _API_URL / _MODEL 配置的AI模型
+var envAIProviders = []string{"deepseek", "qwen"}
+
+// envExchangeIDs 可通过 NOFX_<EXCHANGE>_* 配置凭证的交易所
+var envExchangeIDs = []string{"binance", "gate", "hyperliquid", "aster"}
+
+// envRecordsUser 环境变量中的AI模型、交易所及交易员所属的用户（默认为管理员模式的admin）
+func envRecordsUser() string {
+	return envOrDefault("NOFX_USER_ID", "admin")
+}
+
+// lookupEnv 读取已设置的环境变量（去除首尾空白）
+func lookupEnv(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	return strings.TrimSpace(value), ok
+}
+
+// syncEnvRecords 从环境变量同步AI模型密钥、交易所凭证及交易员，容器部署不需要config.json或Web界面：
+//   - AI模型: NOFX_DEEPSEEK_API_KEY、NOFX_QWEN_API_KEY（可选 _API_URL、_MODEL）
+//   - 交易所: NOFX_BINANCE_API_KEY/_SECRET_KEY、NOFX_GATE_API_KEY/_API_SECRET、
+//     NOFX_HYPERLIQUID_PRIVATE_KEY/_WALLET_ADDR、NOFX_ASTER_USER/_SIGNER/_PRIVATE_KEY，NOFX_<交易所>_TESTNET=true 使用测试网
+//   - 交易员: NOFX_TRADERS，交易员配置的JSON数组（字段同数据库实体 TraderRecord），按ID创建或更新
+//
+// 只覆盖设置了的字段，其余沿用数据库中的值；AI模型ID为 <用户>_<provider>，交易员的 ai_model_id 可直接写provider
+func syncEnvRecords(database config.Store) error {
+	userID := envRecordsUser()
+	models, err := syncEnvAIModels(database, userID)
+	if err != nil {
+		return err
+	}
+	exchanges, err := syncEnvExchanges(database, userID)
+	if err != nil {
+		return err
+	}
+	traders, err := syncEnvTraders(database, userID)
+	if err != nil {
+		return err
+	}
+	if models+exchanges+traders == 0 {
+		return nil
+	}
+	log.Printf("🔄 从环境变量同步 %d 个AI模型、%d 个交易所、%d 个交易员（用户 %s）", models, exchanges, traders, userID)
+	if _, err := database.GetUserByID(userID); err != nil {
+		log.Printf("⚠️  用户 %s 不存在，环境变量中的交易员不会被加载（请开启管理员模式或通过 NOFX_USER_ID 指定已注册的用户）", userID)
+	}
+	return nil
+}
+
+// syncEnvAIModels 同步 NOFX_<PROVIDER>_* 中的AI模型配置（设置了API Key即启用）
+func syncEnvAIModels(database config.Store, userID string) (int, error) {
+	existing, err := database.GetAIModels(userID)
+	if err != nil {
+		return 0, fmt.Errorf("读取AI模型配置失败: %w", err)
+	}
+	count := 0
+	for _, provider := range envAIProviders {
+		prefix := "NOFX_" + strings.ToUpper(provider) + "_"
+		apiKey, ok := lookupEnv(prefix + "API_KEY")
+		if !ok {
+			continue
+		}
+		id := userID + "_" + provider
+		apiURL, modelName := "", ""
+		for _, m := range existing {
+			if m.ID == id {
+				apiURL, modelName = m.CustomAPIURL, m.CustomModelName
+			}
+		}
+		if value, ok := lookupEnv(prefix + "API_URL"); ok {
+			apiURL = value
+		}
+		if value, ok := lookupEnv(prefix + "MODEL"); ok {
+			modelName = value
+		}
+		if err := database.UpdateAIModel(userID, id, apiKey != "", apiKey, apiURL, modelName); err != nil {
+			return count, fmt.Errorf("同步AI模型 %s 失败: %w", provider, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// syncEnvExchanges 同步 NOFX_<EXCHANGE>_* 中的交易所凭证（Hyperliquid的私钥与数据库一致存放在API Key字段）
+func syncEnvExchanges(database config.Store, userID string) (int, error) {
+	existing, err := database.GetExchanges(userID)
+	if err != nil {
+		return 0, fmt.Errorf("读取交易所配置失败: %w", err)
+	}
+	count := 0
+	for _, id := range envExchangeIDs {
+		e := &config.ExchangeConfig{ID: id}
+		for _, current := range existing {
+			if current.ID == id {
+				e = current
+			}
+		}
+		prefix := "NOFX_" + strings.ToUpper(id) + "_"
+		fields := map[string]*string{
+			"API_KEY":     &e.APIKey,
+			"SECRET_KEY":  &e.SecretKey,
+			"API_SECRET":  &e.SecretKey,
+			"PRIVATE_KEY": &e.AsterPrivateKey,
+			"WALLET_ADDR": &e.HyperliquidWalletAddr,
+			"USER":        &e.AsterUser,
+			"SIGNER":      &e.AsterSigner,
+		}
+		if id == "hyperliquid" {
+			fields["PRIVATE_KEY"] = &e.APIKey
+		}
+		changed := false
+		for suffix, field := range fields {
+			if value, ok := lookupEnv(prefix + suffix); ok {
+				*field, changed = value, true
+			}
+		}
+		if value, ok := lookupEnv(prefix + "TESTNET"); ok {
+			e.Testnet, changed = value == "true", true
+		}
+		if !changed {
+			continue
+		}
+		enabled := e.APIKey != "" || e.AsterPrivateKey != ""
+		if err := database.UpdateExchange(userID, id, enabled, e.APIKey, e.SecretKey, e.Testnet,
+			e.HyperliquidWalletAddr, e.AsterUser, e.AsterSigner, e.AsterPrivateKey); err != nil {
+			return count, fmt.Errorf("同步交易所 %s 失败: %w", id, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// syncEnvTraders 同步 NOFX_TRADERS 中的交易员（已存在的按ID更新）
+func syncEnvTraders(database config.Store, userID string) (int, error) {
+	value, ok := lookupEnv("NOFX_TRADERS")
+	if !ok || value == "" {
+		return 0, nil
+	}
+	var traders []*config.TraderRecord
+	if err := json.Unmarshal([]byte(value), &traders); err != nil {
+		return 0, fmt.Errorf("解析NOFX_TRADERS失败: %w", err)
+	}
+	existing, err := database.GetTraders(userID)
+	if err != nil {
+		return 0, fmt.Errorf("读取交易员配置失败: %w", err)
+	}
+	exists := make(map[string]bool)
+	for _, t := range existing {
+		exists[t.ID] = true
+	}
+
+	for i, t := range traders {
+		if t.ID == "" || t.AIModelID == "" || t.ExchangeID == "" {
+			return i, fmt.Errorf("NOFX_TRADERS 第%d个交易员缺少 id、ai_model_id 或 exchange_id", i+1)
+		}
+		t.UserID = userID
+		for _, provider := range envAIProviders {
+			if t.AIModelID == provider {
+				t.AIModelID = userID + "_" + provider
+			}
+		}
+		if t.Name == "" {
+			t.Name = t.ID
+		}
+		if t.ScanIntervalMinutes <= 0 {
+			t.ScanIntervalMinutes = 3
+		}
+		if t.BTCETHLeverage <= 0 {
+			t.BTCETHLeverage = 5
+		}
+		if t.AltcoinLeverage <= 0 {
+			t.AltcoinLeverage = 5
+		}
+		if t.SystemPromptTemplate == "" {
+			t.SystemPromptTemplate = "default"
+		}
+		if exists[t.ID] {
+			err = database.UpdateTrader(t)
+		} else {
+			err = database.CreateTrader(t)
+		}
+		if err != nil {
+			return i, fmt.Errorf("同步交易员 %s 失败: %w", t.ID, err)
+		}
+	}
+	return len(traders), nil
+}
+
+// envOrDefault 读取环境变量，未设置时返回默认值
+func envOrDefault(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// defaultDBPath 配置数据库地址：NOFX_DATABASE_URL > NOFX_DB_PATH > 数据目录下的config.db
+// NOFX_DATABASE_URL 支持 postgres://... 或 mysql://...，多实例部署时共用同一个中心数据库
+func defaultDBPath(dataDir string) string {
+	return envOrDefault("NOFX_DATABASE_URL", envOrDefault("NOFX_DB_PATH", filepath.Join(dataDir, "config.db")))
+}
+
+// prepareWritableDir 确保数据目录可写；只读文件系统下退回临时目录，保证程序仍可运行
+func prepareWritableDir(dir string) string {
+	if isWritableDir(dir) {
+		return dir
+	}
+
+	fallback := filepath.Join(os.TempDir(), "nofx", filepath.Base(dir))
+	if isWritableDir(fallback) {
+		log.Printf("⚠️  目录 %s 不可写（只读文件系统？），临时使用 %s（重启后数据丢失，建议通过 NOFX_DATA_DIR 挂载数据卷）", dir, fallback)
+		return fallback
+	}
+
+	log.Printf("⚠️  目录 %s 不可写，相关数据将无法持久化", dir)
+	return dir
+}
+
+// isWritableDir 目录存在（或可创建）且可写
+func isWritableDir(dir string) bool {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	f.Close()
+	os.Remove(name)
+	return true
+}
@@ -0,0 +1,181 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Type 事件类型
+type Type string
+
+const (
+	OrderPlaced    Type = "order_placed"    // 订单已提交
+	OrderFilled    Type = "order_filled"    // 订单已成交
+	OrderCancelled Type = "order_cancelled" // 挂单已撤销
+	StopLossHit    Type = "stop_loss_hit"   // 止损触发
+	TakeProfitHit  Type = "take_profit_hit" // 止盈触发
+	Error          Type = "error"           // 执行错误
+	RiskReport     Type = "risk_report"     // 定期风险报告（VaR/压力测试）
+	PnLSnapshot    Type = "pnl_snapshot"    // 每个决策周期的账户净值及盈亏快照
+	RecurringOrder Type = "recurring_order" // 定投计划执行（成功/失败/跳过）
+	StopLossMoved  Type = "stop_loss_moved" // 跟踪止损收紧
+
+	LeaderboardSummary  Type = "leaderboard_summary"   // 每日策略排行榜（不属于单个trader）
+	StablecoinDepeg     Type = "stablecoin_depeg"      // 稳定币脱锚告警/恢复（不属于单个trader）
+	ExchangeMaintenance Type = "exchange_maintenance"  // 交易所维护窗口开始/结束（不属于单个trader）
+	ContractDelisting   Type = "contract_delisting"    // 持仓合约已安排下架/交割，及强制平仓/迁移结果
+	NewListing          Type = "new_listing"           // 发现新上线永续合约/自动加入候选池
+	ExecutionReport     Type = "execution_report"      // 每日开仓执行质量报告（不属于单个trader）
+	ManualOrderPending  Type = "manual_order_pending"  // 大额人工订单等待第二人确认（含确认码）
+	OrderRecovered      Type = "order_recovered"       // 崩溃恢复时找回已提交但未记录的订单
+	ContractSpecChanged Type = "contract_spec_changed" // 合约价格步进/最小下单量等交易规则变化（不属于单个trader）
+	Heartbeat           Type = "heartbeat"             // 定期存活通知（不属于单个trader）
+	PerformanceDigest   Type = "performance_digest"    // 业绩日报/周报（Action为daily/weekly，不属于单个trader）
+	BalanceMismatch     Type = "balance_mismatch"      // 每日余额变动与记录的交易/资金费/手续费对不上（如账外手动交易）
+
+	// 紧急告警（Action为resolved时表示恢复）
+	LiquidationRisk Type = "liquidation_risk" // 持仓接近强平
+	KillSwitch      Type = "kill_switch"      // 紧急停止交易
+	AuthFailure     Type = "auth_failure"     // 连续API认证失败
+)
+
+// subscriberBufferSize 每个订阅者的事件缓冲，写满后丢弃新事件，避免慢订阅者阻塞交易逻辑
+const subscriberBufferSize = 256
+
+// Event 交易执行事件
+type Event struct {
+	Type          Type        `json:"type"`
+	TraderID      string      `json:"trader_id"`
+	Exchange      string      `json:"exchange,omitempty"`
+	Symbol        string      `json:"symbol,omitempty"`
+	Side          string      `json:"side,omitempty"`   // "long" 或 "short"
+	Action        string      `json:"action,omitempty"` // open_long, close_short 等
+	Quantity      float64     `json:"quantity,omitempty"`
+	Price         float64     `json:"price,omitempty"`
+	OrderID       int64       `json:"order_id,omitempty"`
+	ClientOrderID string      `json:"client_order_id,omitempty"` // 含策略归属标识
+	Message       string      `json:"message,omitempty"`
+	Equity        float64     `json:"equity,omitempty"`  // PnL快照：账户净值
+	PnL           float64     `json:"pnl,omitempty"`     // PnL快照：总盈亏（净值 - 初始余额）
+	Payload       interface{} `json:"payload,omitempty"` // 结构化内容（业绩摘要为 *manager.Digest，供邮件等渠道渲染）
+	Timestamp     time.Time   `json:"timestamp"`
+}
+
+// Handler 事件处理函数
+type Handler func(Event)
+
+type subscriber struct {
+	name    string
+	types   map[Type]bool // 为空表示订阅全部类型
+	ch      chan Event
+	done    chan struct{}
+	dropped int
+}
+
+// Bus 进程内事件总线：交易逻辑只负责发布，存储/通知/指标等副作用由订阅者异步处理
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[int]*subscriber
+	nextID      int
+	closed      bool
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Subscribe 注册订阅者，types为空时接收所有事件，返回取消订阅函数
+// 每个订阅者在独立goroutine中按发布顺序处理事件
+func (b *Bus) Subscribe(name string, handler Handler, types ...Type) func() {
+	sub := &subscriber{
+		name:  name,
+		types: make(map[Type]bool),
+		ch:    make(chan Event, subscriberBufferSize),
+		done:  make(chan struct{}),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.mu.Unlock()
+
+	go func() {
+		defer close(sub.done)
+		for event := range sub.ch {
+			b.dispatch(sub, handler, event)
+		}
+	}()
+
+	return func() {
+		b.mu.Lock()
+		if _, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(sub.ch)
+		}
+		b.mu.Unlock()
+		<-sub.done
+	}
+}
+
+// dispatch 调用处理函数，订阅者panic不影响其他订阅者
+func (b *Bus) dispatch(sub *subscriber, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ 事件订阅者 %s 处理 %s 时panic: %v", sub.name, event.Type, r)
+		}
+	}()
+	handler(event)
+}
+
+// Publish 发布事件（非阻塞）
+func (b *Bus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	for _, sub := range b.subscribers {
+		if len(sub.types) > 0 && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped++
+			log.Printf("⚠️ 事件订阅者 %s 缓冲已满，丢弃事件 %s (累计丢弃 %d)", sub.name, event.Type, sub.dropped)
+		}
+	}
+}
+
+// Close 关闭事件总线，等待所有订阅者处理完已缓冲的事件
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	subs := b.subscribers
+	b.subscribers = make(map[int]*subscriber)
+	for _, sub := range subs {
+		close(sub.ch)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		<-sub.done
+	}
+}
@@ -0,0 +1,53 @@
+package events
+
+import "sync"
+
+// Recorder 保存最近事件的环形缓冲（供仪表盘查询）
+type Recorder struct {
+	mu     sync.RWMutex
+	events []Event
+	next   int
+	full   bool
+}
+
+// NewRecorder 创建保留最近capacity条事件的记录器
+func NewRecorder(capacity int) *Recorder {
+	if capacity <= 0 {
+		capacity = 500
+	}
+	return &Recorder{events: make([]Event, capacity)}
+}
+
+// Handle 作为订阅者处理事件
+func (r *Recorder) Handle(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[r.next] = event
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Recent 获取最近的事件（按时间倒序），traderID为空时返回所有交易员的事件
+func (r *Recorder) Recent(traderID string, limit int) []Event {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.events)
+	}
+
+	result := make([]Event, 0)
+	for i := 0; i < count && (limit <= 0 || len(result) < limit); i++ {
+		idx := (r.next - 1 - i + len(r.events)) % len(r.events)
+		event := r.events[idx]
+		if traderID != "" && event.TraderID != traderID {
+			continue
+		}
+		result = append(result, event)
+	}
+	return result
+}
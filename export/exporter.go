@@ -0,0 +1,160 @@
+// Package export 把执行事件（下单/成交/止盈止损/PnL快照等）发布到Kafka或NATS，便于接入外部数据管道；
+// 并把已平仓交易导出到第三方交易日志（Tradervue/Edgewonk CSV或通用webhook）
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"nofx/events"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// publishTimeout 单条事件的发布超时
+const publishTimeout = 5 * time.Second
+
+// Publisher 消息发布目标
+type Publisher interface {
+	// Name 目标描述（用于日志）
+	Name() string
+	// Publish 发布一条事件
+	Publish(ctx context.Context, event events.Event, payload []byte) error
+	// Close 刷新缓冲并关闭连接
+	Close() error
+}
+
+// NewPublisher 按URL协议创建发布目标
+// kafka://broker1:9092,broker2:9092 写入Kafka主题（消息key为trader_id，保证同一交易员的事件有序）
+// nats://host:4222 发布到NATS主题 <topic>.<事件类型>（如 nofx.events.order_filled），便于按类型订阅
+func NewPublisher(rawURL, topic string) (Publisher, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("事件导出主题不能为空")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析事件导出地址失败: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "kafka":
+		brokers := strings.Split(u.Host, ",")
+		return &kafkaPublisher{
+			brokers: brokers,
+			writer: &kafka.Writer{
+				Addr:                   kafka.TCP(brokers...),
+				Topic:                  topic,
+				Balancer:               &kafka.Hash{},
+				RequiredAcks:           kafka.RequireOne,
+				AllowAutoTopicCreation: true,
+				BatchTimeout:           100 * time.Millisecond,
+			},
+		}, nil
+	case "nats", "tls":
+		// 启动时NATS不可用也不影响交易，后台持续重连
+		conn, err := nats.Connect(rawURL, nats.Name("nofx-events"), nats.MaxReconnects(-1), nats.RetryOnFailedConnect(true))
+		if err != nil {
+			return nil, fmt.Errorf("连接NATS失败: %w", err)
+		}
+		return &natsPublisher{conn: conn, server: u.Redacted(), subject: topic}, nil
+	default:
+		return nil, fmt.Errorf("不支持的事件导出协议: %s（可选: kafka, nats, tls）", u.Scheme)
+	}
+}
+
+// Exporter 订阅事件总线并转发到发布目标
+type Exporter struct {
+	publisher   Publisher
+	unsubscribe func()
+	failures    int
+}
+
+// Start 订阅事件总线开始导出（总线按订阅者缓冲，导出慢时丢弃事件而不阻塞交易逻辑）
+func Start(bus *events.Bus, publisher Publisher) *Exporter {
+	e := &Exporter{publisher: publisher}
+	e.unsubscribe = bus.Subscribe("export", e.handle)
+	log.Printf("📤 执行事件导出到 %s", publisher.Name())
+	return e
+}
+
+// Stop 取消订阅并关闭发布目标（等待已接收的事件发布完成）
+func (e *Exporter) Stop() {
+	e.unsubscribe()
+	if err := e.publisher.Close(); err != nil {
+		log.Printf("⚠️  关闭事件导出失败: %v", err)
+	}
+}
+
+// handle 发布单个事件，连续失败时只在首次及每100次打印日志
+func (e *Exporter) handle(event events.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := e.publisher.Publish(ctx, event, payload); err != nil {
+		if e.failures%100 == 0 {
+			log.Printf("⚠️  导出事件 %s 失败（已连续失败 %d 次）: %v", event.Type, e.failures+1, err)
+		}
+		e.failures++
+		return
+	}
+	if e.failures > 0 {
+		log.Printf("✓ 事件导出已恢复（此前失败 %d 次）", e.failures)
+		e.failures = 0
+	}
+}
+
+// kafkaPublisher Kafka主题
+type kafkaPublisher struct {
+	brokers []string
+	writer  *kafka.Writer
+}
+
+func (k *kafkaPublisher) Name() string {
+	return fmt.Sprintf("kafka://%s/%s", strings.Join(k.brokers, ","), k.writer.Topic)
+}
+
+func (k *kafkaPublisher) Publish(ctx context.Context, event events.Event, payload []byte) error {
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.TraderID),
+		Value: payload,
+		Time:  event.Timestamp,
+		Headers: []kafka.Header{
+			{Key: "type", Value: []byte(event.Type)},
+		},
+	})
+}
+
+func (k *kafkaPublisher) Close() error {
+	return k.writer.Close()
+}
+
+// natsPublisher NATS主题
+type natsPublisher struct {
+	conn    *nats.Conn
+	server  string
+	subject string
+}
+
+func (n *natsPublisher) Name() string {
+	return fmt.Sprintf("%s/%s.*", n.server, n.subject)
+}
+
+func (n *natsPublisher) Publish(_ context.Context, event events.Event, payload []byte) error {
+	return n.conn.Publish(n.subject+"."+string(event.Type), payload)
+}
+
+func (n *natsPublisher) Close() error {
+	if n.conn.IsConnected() {
+		return n.conn.Drain()
+	}
+	n.conn.Close() // 未连接时无法刷新缓冲，直接关闭
+	return nil
+}
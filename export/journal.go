@@ -0,0 +1,176 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"nofx/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 交易日志导出格式
+const (
+	JournalTradervue = "tradervue" // Tradervue通用导入格式（每笔成交一行）
+	JournalEdgewonk  = "edgewonk"  // Edgewonk自定义导入格式（每笔交易一行）
+	JournalJSON      = "json"      // 标准化JSON（与webhook推送内容相同）
+)
+
+// JournalTrade 已平仓交易（导出到第三方交易日志的标准化格式）
+type JournalTrade struct {
+	TraderID    string    `json:"trader_id"`
+	TraderName  string    `json:"trader_name"`
+	Exchange    string    `json:"exchange"`
+	PositionID  string    `json:"position_id"`
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"` // long / short
+	Leverage    int       `json:"leverage"`
+	Quantity    float64   `json:"quantity"` // 持仓期间的最大数量
+	EntryTime   time.Time `json:"entry_time"`
+	ExitTime    time.Time `json:"exit_time"`
+	EntryPrice  float64   `json:"entry_price"` // 平均开仓价
+	ExitPrice   float64   `json:"exit_price"`  // 平均平仓价
+	PricePnL    float64   `json:"price_pnl"`
+	FundingPnL  float64   `json:"funding_pnl"` // 正数=收取，负数=支付
+	Fees        float64   `json:"fees"`
+	NetPnL      float64   `json:"net_pnl"`
+	PnLPct      float64   `json:"pnl_pct"`
+	CloseReason string    `json:"close_reason,omitempty"`
+	Notes       []string  `json:"notes,omitempty"` // 复盘笔记
+}
+
+// NewJournalTrade 由已平仓的持仓生命周期生成导出记录
+func NewJournalTrade(traderID, traderName, exchange string, lc *logger.PositionLifecycle) JournalTrade {
+	trade := JournalTrade{
+		TraderID:    traderID,
+		TraderName:  traderName,
+		Exchange:    exchange,
+		PositionID:  lc.ID,
+		Symbol:      lc.Symbol,
+		Side:        lc.Side,
+		Leverage:    lc.Leverage,
+		Quantity:    lc.MaxQuantity,
+		EntryTime:   lc.OpenTime,
+		ExitTime:    lc.CloseTime,
+		EntryPrice:  lc.EntryPrice,
+		ExitPrice:   lc.ExitPrice,
+		PricePnL:    lc.PricePnL,
+		FundingPnL:  lc.FundingPnL,
+		Fees:        lc.Fees,
+		NetPnL:      lc.RealizedPnL,
+		PnLPct:      lc.PnLPct,
+		CloseReason: lc.CloseReason,
+	}
+	for _, n := range lc.Notes {
+		trade.Notes = append(trade.Notes, n.Note)
+	}
+	return trade
+}
+
+// ParseJournalFormat 校验导出格式（空字符串为tradervue）
+func ParseJournalFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", JournalTradervue:
+		return JournalTradervue, nil
+	case JournalEdgewonk:
+		return JournalEdgewonk, nil
+	case JournalJSON:
+		return JournalJSON, nil
+	default:
+		return "", fmt.Errorf("不支持的交易日志格式: %s（可选: tradervue, edgewonk, json）", format)
+	}
+}
+
+// WriteJournal 按格式写出已平仓交易（时间均为UTC）
+// tradervue: 开仓、平仓各一行成交（Buy/Sell/Short/Cover），手续费记在平仓行的Commission，资金费记在TransFee（支付为正、收取为负）
+// edgewonk: 每笔交易一行，资金费记在Swap列（正数=收取），复盘笔记写入Notes列
+func WriteJournal(w io.Writer, format string, trades []JournalTrade) error {
+	switch format {
+	case JournalJSON:
+		return json.NewEncoder(w).Encode(trades)
+	case JournalTradervue:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"Date", "Time", "Symbol", "Quantity", "Price", "Side", "Commission", "TransFee"})
+		for _, t := range trades {
+			entrySide, exitSide := "Buy", "Sell"
+			if t.Side == "short" {
+				entrySide, exitSide = "Short", "Cover"
+			}
+			cw.Write([]string{usDate(t.EntryTime), clock(t.EntryTime), t.Symbol, num(t.Quantity), num(t.EntryPrice), entrySide, "0", "0"})
+			cw.Write([]string{usDate(t.ExitTime), clock(t.ExitTime), t.Symbol, num(t.Quantity), num(t.ExitPrice), exitSide, num(t.Fees), num(-t.FundingPnL)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case JournalEdgewonk:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"Instrument", "Direction", "Entry Date", "Entry Time", "Entry Price", "Exit Date", "Exit Time", "Exit Price",
+			"Position Size", "Leverage", "Commission", "Swap", "Profit/Loss", "Notes"})
+		for _, t := range trades {
+			direction := "Long"
+			if t.Side == "short" {
+				direction = "Short"
+			}
+			cw.Write([]string{t.Symbol, direction, isoDate(t.EntryTime), clock(t.EntryTime), num(t.EntryPrice), isoDate(t.ExitTime), clock(t.ExitTime), num(t.ExitPrice),
+				num(t.Quantity), strconv.Itoa(t.Leverage), num(t.Fees), num(t.FundingPnL), num(t.NetPnL), strings.Join(t.Notes, " | ")})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("不支持的交易日志格式: %s", format)
+	}
+}
+
+func usDate(t time.Time) string  { return t.UTC().Format("01/02/2006") }
+func isoDate(t time.Time) string { return t.UTC().Format("2006-01-02") }
+func clock(t time.Time) string   { return t.UTC().Format("15:04:05") }
+func num(v float64) string       { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+// JournalWebhook 把已平仓交易以JSON POST到通用webhook（如Zapier/Make或自建的交易日志服务）
+type JournalWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewJournalWebhook 创建交易日志webhook
+func NewJournalWebhook(rawURL string) (*JournalWebhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("无效的交易日志webhook地址: %s（需为http/https地址）", rawURL)
+	}
+	return &JournalWebhook{url: rawURL, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Name 目标描述（用于日志，不含查询参数中可能携带的token）
+func (j *JournalWebhook) Name() string {
+	u, _ := url.Parse(j.url)
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+// Push 推送一笔已平仓交易，非2xx响应视为失败
+func (j *JournalWebhook) Push(ctx context.Context, trade JournalTrade) error {
+	payload, err := json.Marshal(trade)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchTimeout 单个特征源的请求超时（避免拖慢决策周期）
+const fetchTimeout = 10 * time.Second
+
+// Provider 市场特征源：返回一段可直接放入AI prompt的文本
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) (string, error)
+}
+
+// Config 特征源配置
+type Config struct {
+	Name               string   `json:"name"`                 // funding / open_interest / fear_greed / news
+	TTLMinutes         int      `json:"ttl_minutes"`          // 缓存时长（默认15分钟）
+	MinIntervalSeconds int      `json:"min_interval_seconds"` // 两次请求的最小间隔（限流，默认60秒；请求失败后同样等待）
+	URL                string   `json:"url"`                  // news: 新闻接口地址（CryptoPanic 格式或 [{title, source, published_at}]）
+	Symbols            []string `json:"symbols"`              // open_interest: 统计的合约（默认BTCUSDT、ETHUSDT）
+	Limit              int      `json:"limit"`                // funding: 列出的极值数量；news: 标题数量（默认5）
+}
+
+// ParseConfigs 解析特征源配置（JSON数组）
+func ParseConfigs(s string) ([]Config, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var configs []Config
+	if err := json.Unmarshal([]byte(s), &configs); err != nil {
+		return nil, fmt.Errorf("解析特征源配置失败: %w", err)
+	}
+	return configs, nil
+}
+
+// NewProvider 按配置创建特征源
+func NewProvider(cfg Config) (Provider, error) {
+	limit := cfg.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	switch cfg.Name {
+	case "funding":
+		return &FundingProvider{URL: BinancePremiumIndexURL, Limit: limit}, nil
+	case "open_interest":
+		symbols := cfg.Symbols
+		if len(symbols) == 0 {
+			symbols = []string{"BTCUSDT", "ETHUSDT"}
+		}
+		return &OpenInterestProvider{URL: BinanceOIHistoryURL, Symbols: symbols}, nil
+	case "fear_greed":
+		return &FearGreedProvider{URL: FearGreedURL}, nil
+	case "news":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("news 特征源缺少 url")
+		}
+		return &NewsProvider{URL: cfg.URL, Limit: limit}, nil
+	default:
+		return nil, fmt.Errorf("不支持的特征源: %s（可选: funding, open_interest, fear_greed, news）", cfg.Name)
+	}
+}
+
+// entry 单个特征源的缓存及限流状态
+type entry struct {
+	provider    Provider
+	ttl         time.Duration
+	minInterval time.Duration
+
+	mu          sync.Mutex
+	value       string
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// get 返回缓存内容，过期且不在限流间隔内时重新请求（失败时沿用上次结果）
+func (e *entry) get(now time.Time) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.fetchedAt.IsZero() && now.Sub(e.fetchedAt) < e.ttl {
+		return e.value
+	}
+	if now.Sub(e.lastAttempt) < e.minInterval {
+		return e.value
+	}
+	e.lastAttempt = now
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	value, err := e.provider.Fetch(ctx)
+	if err != nil {
+		log.Printf("⚠️  [特征源 %s] %v，沿用上次结果", e.provider.Name(), err)
+		return e.value
+	}
+	e.value, e.fetchedAt = strings.TrimSpace(value), now
+	return e.value
+}
+
+var (
+	providersMu sync.RWMutex
+	entries     []*entry
+)
+
+// SetProviders 设置启用的特征源（替换已有配置）
+func SetProviders(configs []Config) error {
+	var next []*entry
+	for _, cfg := range configs {
+		p, err := NewProvider(cfg)
+		if err != nil {
+			return err
+		}
+		e := &entry{provider: p, ttl: 15 * time.Minute, minInterval: time.Minute}
+		if cfg.TTLMinutes > 0 {
+			e.ttl = time.Duration(cfg.TTLMinutes) * time.Minute
+		}
+		if cfg.MinIntervalSeconds > 0 {
+			e.minInterval = time.Duration(cfg.MinIntervalSeconds) * time.Second
+		}
+		next = append(next, e)
+	}
+	providersMu.Lock()
+	entries = next
+	providersMu.Unlock()
+	return nil
+}
+
+// Enabled 是否启用了任一特征源
+func Enabled() bool {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return len(entries) > 0
+}
+
+// Render 拼接所有特征源的内容（各特征源并发获取，无内容的跳过）
+func Render() string {
+	providersMu.RLock()
+	current := entries
+	providersMu.RUnlock()
+
+	now := time.Now()
+	values := make([]string, len(current))
+	var wg sync.WaitGroup
+	for i, e := range current {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			values[i] = e.get(now)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var sb strings.Builder
+	for _, v := range values {
+		if v != "" {
+			sb.WriteString(v)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,250 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 公开数据接口
+const (
+	BinancePremiumIndexURL = "https://fapi.binance.com/fapi/v1/premiumIndex"
+	BinanceOIHistoryURL    = "https://fapi.binance.com/futures/data/openInterestHist"
+	FearGreedURL           = "https://api.alternative.me/fng/?limit=2"
+)
+
+// FundingProvider 全市场资金费率概览（均值及极值）
+type FundingProvider struct {
+	URL   string
+	Limit int
+}
+
+// Name 实现 Provider
+func (p *FundingProvider) Name() string { return "funding" }
+
+// Fetch 实现 Provider
+func (p *FundingProvider) Fetch(ctx context.Context) (string, error) {
+	var raw []struct {
+		Symbol          string `json:"symbol"`
+		LastFundingRate string `json:"lastFundingRate"`
+	}
+	if err := getJSON(ctx, p.URL, &raw); err != nil {
+		return "", err
+	}
+	type rate struct {
+		symbol string
+		value  float64
+	}
+	var rates []rate
+	sum := 0.0
+	for _, r := range raw {
+		if !strings.HasSuffix(r.Symbol, "USDT") {
+			continue
+		}
+		v, err := strconv.ParseFloat(r.LastFundingRate, 64)
+		if err != nil {
+			continue
+		}
+		rates = append(rates, rate{r.Symbol, v * 100})
+		sum += v * 100
+	}
+	if len(rates) == 0 {
+		return "", fmt.Errorf("资金费率为空")
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].value > rates[j].value })
+	n := p.Limit
+	if n > len(rates)/2 {
+		n = len(rates) / 2
+	}
+	format := func(list []rate) string {
+		parts := make([]string, len(list))
+		for i, r := range list {
+			parts[i] = fmt.Sprintf("%s %+.4f%%", r.symbol, r.value)
+		}
+		return strings.Join(parts, ", ")
+	}
+	lowest := make([]rate, 0, n)
+	for i := len(rates) - 1; i >= len(rates)-n; i-- {
+		lowest = append(lowest, rates[i])
+	}
+	return fmt.Sprintf("资金费率: 全市场均值 %+.4f%%（%d个合约）| 最高: %s | 最低: %s",
+		sum/float64(len(rates)), len(rates), format(rates[:n]), format(lowest)), nil
+}
+
+// OpenInterestProvider 主要合约的24小时持仓量变化
+type OpenInterestProvider struct {
+	URL     string
+	Symbols []string
+}
+
+// Name 实现 Provider
+func (p *OpenInterestProvider) Name() string { return "open_interest" }
+
+// Fetch 实现 Provider
+func (p *OpenInterestProvider) Fetch(ctx context.Context) (string, error) {
+	var parts []string
+	for _, symbol := range p.Symbols {
+		var hist []struct {
+			SumOpenInterestValue string `json:"sumOpenInterestValue"`
+		}
+		url := fmt.Sprintf("%s?symbol=%s&period=1h&limit=25", p.URL, symbol)
+		if err := getJSON(ctx, url, &hist); err != nil {
+			return "", err
+		}
+		if len(hist) < 2 {
+			continue
+		}
+		first, _ := strconv.ParseFloat(hist[0].SumOpenInterestValue, 64)
+		last, _ := strconv.ParseFloat(hist[len(hist)-1].SumOpenInterestValue, 64)
+		if first <= 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %.2f亿USDT（24h %+.2f%%）", symbol, last/1e8, (last-first)/first*100))
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("持仓量数据为空")
+	}
+	return "持仓量: " + strings.Join(parts, " | "), nil
+}
+
+// FearGreedProvider 加密货币恐慌与贪婪指数（alternative.me）
+type FearGreedProvider struct {
+	URL string
+}
+
+// Name 实现 Provider
+func (p *FearGreedProvider) Name() string { return "fear_greed" }
+
+// Fetch 实现 Provider
+func (p *FearGreedProvider) Fetch(ctx context.Context) (string, error) {
+	var resp struct {
+		Data []struct {
+			Value          string `json:"value"`
+			Classification string `json:"value_classification"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, p.URL, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Data) == 0 {
+		return "", fmt.Errorf("恐慌与贪婪指数为空")
+	}
+	line := fmt.Sprintf("恐慌与贪婪指数: %s（%s）", resp.Data[0].Value, resp.Data[0].Classification)
+	if len(resp.Data) > 1 {
+		line += fmt.Sprintf(" | 昨日 %s（%s）", resp.Data[1].Value, resp.Data[1].Classification)
+	}
+	return line, nil
+}
+
+// NewsProvider 新闻标题（支持 CryptoPanic 的 {"results": [...]} 或 [{title, source, published_at}]）
+type NewsProvider struct {
+	URL   string
+	Limit int
+}
+
+// Name 实现 Provider
+func (p *NewsProvider) Name() string { return "news" }
+
+// newsItem 一条新闻（source 在 CryptoPanic 中为对象，这里兼容两种写法）
+type newsItem struct {
+	Title       string          `json:"title"`
+	Source      json.RawMessage `json:"source"`
+	PublishedAt time.Time       `json:"published_at"`
+}
+
+// sourceName 新闻来源名称
+func (n newsItem) sourceName() string {
+	var name string
+	if json.Unmarshal(n.Source, &name) == nil {
+		return name
+	}
+	var obj struct {
+		Title string `json:"title"`
+	}
+	if json.Unmarshal(n.Source, &obj) == nil {
+		return obj.Title
+	}
+	return ""
+}
+
+// Fetch 实现 Provider
+func (p *NewsProvider) Fetch(ctx context.Context) (string, error) {
+	body, err := get(ctx, p.URL)
+	if err != nil {
+		return "", err
+	}
+	var items []newsItem
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		err = json.Unmarshal(body, &items)
+	} else {
+		var resp struct {
+			Results []newsItem `json:"results"`
+		}
+		err = json.Unmarshal(body, &resp)
+		items = resp.Results
+	}
+	if err != nil {
+		return "", fmt.Errorf("解析新闻失败: %w", err)
+	}
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("最新新闻:\n")
+	for i, item := range items {
+		if i >= p.Limit {
+			break
+		}
+		age := ""
+		if !item.PublishedAt.IsZero() {
+			age = fmt.Sprintf("[%.0f分钟前] ", math.Max(0, time.Since(item.PublishedAt).Minutes()))
+		}
+		source := item.sourceName()
+		if source != "" {
+			source = " - " + source
+		}
+		fmt.Fprintf(&sb, "- %s%s%s\n", age, strings.TrimSpace(item.Title), source)
+	}
+	return sb.String(), nil
+}
+
+// getJSON 请求并解析JSON
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	body, err := get(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// get 发送GET请求
+func get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
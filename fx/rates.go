@@ -0,0 +1,227 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BaseCurrency 系统内部记账币种（所有净值、盈亏均以USDT计）
+const BaseCurrency = "USDT"
+
+// DefaultRateURL 默认汇率API（以USD为基准，返回 {"rates": {"EUR": 0.92, ...}}）
+const DefaultRateURL = "https://open.er-api.com/v6/latest/USD"
+
+// 视为与USD 1:1 的币种
+var usdEquivalents = map[string]bool{
+	"USD":   true,
+	"USDT":  true,
+	"USDC":  true,
+	"BUSD":  true,
+	"FDUSD": true,
+}
+
+// RateSource 汇率来源：返回1 USD折合各币种的数量
+type RateSource interface {
+	Rates(ctx context.Context) (map[string]float64, error)
+}
+
+// StaticRates 固定汇率（如 EUR=0.92），适用于无法访问外部API的部署
+type StaticRates map[string]float64
+
+// Rates 实现 RateSource
+func (r StaticRates) Rates(ctx context.Context) (map[string]float64, error) {
+	return r, nil
+}
+
+// HTTPSource 从HTTP API获取汇率（兼容 open.er-api.com / frankfurter.app 等以USD为基准的返回格式）
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+}
+
+// Rates 实现 RateSource
+func (s *HTTPSource) Rates(ctx context.Context) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建汇率请求失败: %w", err)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求汇率API失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取汇率响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("汇率API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析汇率响应失败: %w", err)
+	}
+	if len(result.Rates) == 0 {
+		return nil, fmt.Errorf("汇率响应中没有数据")
+	}
+	return result.Rates, nil
+}
+
+// ParseStaticRates 解析 "EUR=0.92,CNY=7.2" 格式的固定汇率
+func ParseStaticRates(s string) (StaticRates, error) {
+	rates := make(StaticRates)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的汇率配置: %s（格式: EUR=0.92）", item)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("无效的汇率: %s", item)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
+
+// Quote 报告币种及其汇率（1 USDT折合的数量）
+type Quote struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+}
+
+// Convert 将USDT金额折算为报告币种
+func (q Quote) Convert(usdt float64) float64 {
+	return usdt * q.Rate
+}
+
+// Format 格式化为带币种的金额文本（用于通知）
+func (q Quote) Format(usdt float64) string {
+	return fmt.Sprintf("%.2f %s", q.Convert(usdt), q.Currency)
+}
+
+// FormatSigned 格式化为带符号的金额文本（用于盈亏）
+func (q Quote) FormatSigned(usdt float64) string {
+	return fmt.Sprintf("%+.2f %s", q.Convert(usdt), q.Currency)
+}
+
+var (
+	mu            sync.RWMutex
+	quoteCurrency = BaseCurrency
+	source        RateSource
+	refresh       = time.Hour
+	timeout       = 10 * time.Second
+
+	// 汇率缓存
+	cachedRates map[string]float64
+	fetchedAt   time.Time
+)
+
+// SetQuoteCurrency 设置默认报告币种（为空时使用USDT）
+func SetQuoteCurrency(currency string) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = BaseCurrency
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	quoteCurrency = currency
+}
+
+// QuoteCurrency 当前默认报告币种
+func QuoteCurrency() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return quoteCurrency
+}
+
+// SetSource 设置汇率来源，并清空缓存
+func SetSource(src RateSource) {
+	mu.Lock()
+	defer mu.Unlock()
+	source = src
+	cachedRates = nil
+	fetchedAt = time.Time{}
+}
+
+// Rate 获取1 USDT折合currency的汇率
+// 刷新失败时沿用上一次获取的汇率
+func Rate(currency string) (float64, error) {
+	currency = strings.ToUpper(currency)
+	if usdEquivalents[currency] {
+		return 1, nil
+	}
+
+	mu.RLock()
+	src, rates, stale := source, cachedRates, time.Since(fetchedAt) > refresh
+	mu.RUnlock()
+	if src == nil {
+		return 0, fmt.Errorf("未配置汇率来源，无法折算为 %s", currency)
+	}
+
+	if rates == nil || stale {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		fetched, err := src.Rates(ctx)
+		cancel()
+		if err != nil {
+			if rates == nil {
+				return 0, err
+			}
+			log.Printf("⚠️  刷新汇率失败，沿用缓存汇率: %v", err)
+		} else {
+			rates = fetched
+			mu.Lock()
+			cachedRates, fetchedAt = fetched, time.Now()
+			mu.Unlock()
+		}
+	}
+
+	rate, ok := rates[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("汇率来源中没有 %s", currency)
+	}
+	return rate, nil
+}
+
+// QuoteFor 获取指定币种的报告汇率（为空时使用默认报告币种）
+func QuoteFor(currency string) (Quote, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = QuoteCurrency()
+	}
+	rate, err := Rate(currency)
+	if err != nil {
+		return Quote{}, err
+	}
+	return Quote{Currency: currency, Rate: rate}, nil
+}
+
+// Current 默认报告币种的汇率，获取失败时退回USDT（用于通知等不能失败的场景）
+func Current() Quote {
+	q, err := QuoteFor("")
+	if err != nil {
+		log.Printf("⚠️  获取 %s 汇率失败，使用 %s: %v", QuoteCurrency(), BaseCurrency, err)
+		return Quote{Currency: BaseCurrency, Rate: 1}
+	}
+	return q
+}
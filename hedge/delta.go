@@ -0,0 +1,61 @@
+package hedge
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// OptionPosition 期权持仓（期权本身在外部平台持有，这里只用于计算Delta）
+type OptionPosition struct {
+	Symbol     string    `json:"symbol"`          // 期权合约，如 BTC-27DEC25-100000-C
+	Underlying string    `json:"underlying"`      // 标的，如 BTC
+	Type       string    `json:"type"`            // "call" 或 "put"
+	Strike     float64   `json:"strike"`          // 行权价
+	Expiry     time.Time `json:"expiry"`          // 到期时间
+	Quantity   float64   `json:"quantity"`        // 持仓数量（以标的数量计，卖出为负）
+	IV         float64   `json:"iv"`              // 隐含波动率（年化，0.6 = 60%）
+	Delta      *float64  `json:"delta,omitempty"` // 平台提供的单位Delta（设置后不再用模型计算）
+}
+
+// IsCall 是否为看涨期权
+func (o OptionPosition) IsCall() bool {
+	t := strings.ToLower(o.Type)
+	return t == "call" || t == "c"
+}
+
+// UnitDelta 单位期权Delta（Black-Scholes，无风险利率取0）
+// 到期后或波动率缺失时按内在价值取 0/±1
+func (o OptionPosition) UnitDelta(spot float64, now time.Time) float64 {
+	if o.Delta != nil {
+		return *o.Delta
+	}
+
+	years := o.Expiry.Sub(now).Hours() / (24 * 365)
+	if years <= 0 || o.IV <= 0 || spot <= 0 || o.Strike <= 0 {
+		switch {
+		case o.IsCall() && spot > o.Strike:
+			return 1
+		case !o.IsCall() && spot < o.Strike:
+			return -1
+		default:
+			return 0
+		}
+	}
+
+	d1 := (math.Log(spot/o.Strike) + 0.5*o.IV*o.IV*years) / (o.IV * math.Sqrt(years))
+	if o.IsCall() {
+		return normCDF(d1)
+	}
+	return normCDF(d1) - 1
+}
+
+// PositionDelta 持仓Delta（以标的数量计）
+func (o OptionPosition) PositionDelta(spot float64, now time.Time) float64 {
+	return o.UnitDelta(spot, now) * o.Quantity
+}
+
+// normCDF 标准正态分布累积分布函数
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
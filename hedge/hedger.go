@@ -0,0 +1,234 @@
+package hedge
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/trader"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config 单个标的的Delta对冲配置
+type Config struct {
+	TraderID        string  `json:"trader_id"`        // 提供交易所账户的交易员
+	Underlying      string  `json:"underlying"`       // 对冲的期权标的，如 BTC
+	PerpSymbol      string  `json:"perp_symbol"`      // 对冲用永续合约（默认 标的+USDT）
+	OptionsFile     string  `json:"options_file"`     // 期权持仓JSON文件
+	Band            float64 `json:"band"`             // 允许的净Delta区间（±标的数量），超出时对冲回0
+	IntervalSeconds int     `json:"interval_seconds"` // 检查间隔（默认60秒）
+	Leverage        int     `json:"leverage"`         // 永续杠杆（默认1）
+	MinAdjust       float64 `json:"min_adjust"`       // 最小调整数量，低于该值不下单
+}
+
+// ParseConfigs 解析Delta对冲配置（JSON数组）
+func ParseConfigs(s string) ([]Config, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var configs []Config
+	if err := json.Unmarshal([]byte(s), &configs); err != nil {
+		return nil, fmt.Errorf("解析Delta对冲配置失败: %w", err)
+	}
+	for i := range configs {
+		c := &configs[i]
+		c.Underlying = strings.ToUpper(strings.TrimSpace(c.Underlying))
+		if c.TraderID == "" || c.Underlying == "" {
+			return nil, fmt.Errorf("Delta对冲配置 #%d 缺少 trader_id 或 underlying", i+1)
+		}
+		if c.Band < 0 {
+			return nil, fmt.Errorf("Delta对冲配置 #%d 的 band 不能为负数", i+1)
+		}
+		if c.PerpSymbol == "" {
+			c.PerpSymbol = c.Underlying + "USDT"
+		}
+		c.PerpSymbol = strings.ToUpper(c.PerpSymbol)
+		if c.IntervalSeconds <= 0 {
+			c.IntervalSeconds = 60
+		}
+		if c.Leverage <= 0 {
+			c.Leverage = 1
+		}
+	}
+	return configs, nil
+}
+
+// Result 一次对冲检查的结果
+type Result struct {
+	Time        time.Time `json:"time"`
+	Spot        float64   `json:"spot"`
+	OptionDelta float64   `json:"option_delta"` // 期权持仓Delta（标的数量）
+	PerpQty     float64   `json:"perp_qty"`     // 调整前永续持仓（多为正、空为负）
+	NetDelta    float64   `json:"net_delta"`    // 调整前净Delta
+	Adjusted    float64   `json:"adjusted"`     // 本次调整的永续数量（买入为正、卖出为负）
+}
+
+// PerpTrader 对冲用永续合约的下单通道（由交易员的 trader.StrategyTrader 提供，
+// 下单经过模拟交易、风控检查及下单意图日志，持仓归属于对冲策略）
+type PerpTrader interface {
+	IsDryRun() bool
+	GetMarketPrice(symbol string) (float64, error)
+	GetPositions() ([]map[string]interface{}, error)
+	OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	CloseLong(symbol string, quantity float64) (map[string]interface{}, error)
+	CloseShort(symbol string, quantity float64) (map[string]interface{}, error)
+}
+
+var _ PerpTrader = (*trader.StrategyTrader)(nil)
+
+// Hedger 定期计算期权持仓的净Delta，并通过永续合约调整使其保持在配置区间内
+// 对冲持仓归属于对冲策略，该交易员的AI策略不会平掉或加仓
+type Hedger struct {
+	config   Config
+	trader   PerpTrader
+	source   OptionSource
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewHedger 创建Delta对冲器
+func NewHedger(config Config, t PerpTrader, source OptionSource) *Hedger {
+	return &Hedger{
+		config: config,
+		trader: t,
+		source: source,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start 启动定期对冲
+func (h *Hedger) Start() {
+	interval := time.Duration(h.config.IntervalSeconds) * time.Second
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := h.Rebalance(time.Now()); err != nil {
+				log.Printf("⚠️  [Delta对冲 %s] %v", h.config.Underlying, err)
+			}
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("⚖️  Delta对冲已启动: %s 期权 → %s（区间 ±%.4f，每 %s 检查一次）",
+		h.config.Underlying, h.config.PerpSymbol, h.config.Band, interval)
+}
+
+// Stop 停止对冲（等待正在进行的调整完成）
+func (h *Hedger) Stop() {
+	h.stopOnce.Do(func() { close(h.stop) })
+	h.wg.Wait()
+}
+
+// Rebalance 计算净Delta，超出区间时调整永续持仓使净Delta回到0
+func (h *Hedger) Rebalance(now time.Time) (*Result, error) {
+	options, err := h.source.OptionPositions()
+	if err != nil {
+		return nil, err
+	}
+	spot, err := h.trader.GetMarketPrice(h.config.PerpSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 价格失败: %w", h.config.PerpSymbol, err)
+	}
+	perpQty, err := h.perpPosition()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Time: now, Spot: spot, PerpQty: perpQty}
+	for _, opt := range options {
+		if strings.EqualFold(opt.Underlying, h.config.Underlying) {
+			result.OptionDelta += opt.PositionDelta(spot, now)
+		}
+	}
+	result.NetDelta = result.OptionDelta + perpQty
+
+	if math.Abs(result.NetDelta) <= h.config.Band {
+		return result, nil
+	}
+	target := -result.OptionDelta
+	if math.Abs(target-perpQty) < h.config.MinAdjust {
+		return result, nil
+	}
+
+	log.Printf("⚖️  [Delta对冲 %s] 期权Delta %+.4f 永续 %+.4f 净Delta %+.4f 超出 ±%.4f，调整永续至 %+.4f",
+		h.config.Underlying, result.OptionDelta, perpQty, result.NetDelta, h.config.Band, target)
+	adjusted, err := h.adjust(perpQty, target)
+	result.Adjusted = adjusted
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// perpPosition 当前永续持仓（多为正、空为负；双向持仓时取净值）
+func (h *Hedger) perpPosition() (float64, error) {
+	positions, err := h.trader.GetPositions()
+	if err != nil {
+		return 0, fmt.Errorf("获取持仓失败: %w", err)
+	}
+	var qty float64
+	for _, pos := range positions {
+		if symbol, _ := pos["symbol"].(string); symbol != h.config.PerpSymbol {
+			continue
+		}
+		amt, _ := pos["positionAmt"].(float64)
+		if side, _ := pos["side"].(string); side == "short" {
+			qty -= math.Abs(amt)
+		} else {
+			qty += math.Abs(amt)
+		}
+	}
+	return qty, nil
+}
+
+// adjust 将永续持仓从current调整到target：先减少反向持仓，再按需开仓
+func (h *Hedger) adjust(current, target float64) (float64, error) {
+	const epsilon = 1e-9
+	symbol := h.config.PerpSymbol
+	start := current
+
+	if current > epsilon && target < current {
+		qty := math.Min(current-target, current)
+		if _, err := h.trader.CloseLong(symbol, qty); err != nil {
+			return current - start, fmt.Errorf("减少多仓失败: %w", err)
+		}
+		current -= qty
+	}
+	if current < -epsilon && target > current {
+		qty := math.Min(target-current, -current)
+		if _, err := h.trader.CloseShort(symbol, qty); err != nil {
+			return current - start, fmt.Errorf("减少空仓失败: %w", err)
+		}
+		current += qty
+	}
+
+	switch {
+	case target-current > epsilon:
+		if _, err := h.trader.OpenLong(symbol, target-current, h.config.Leverage); err != nil {
+			return current - start, fmt.Errorf("开多对冲失败: %w", err)
+		}
+		current = target
+	case current-target > epsilon:
+		if _, err := h.trader.OpenShort(symbol, current-target, h.config.Leverage); err != nil {
+			return current - start, fmt.Errorf("开空对冲失败: %w", err)
+		}
+		current = target
+	}
+
+	if h.trader.IsDryRun() {
+		log.Printf("🧪 [Delta对冲 %s] 模拟交易，%s 应调整 %+.4f（未下单）", h.config.Underlying, symbol, current-start)
+		return 0, nil
+	}
+	log.Printf("✓ [Delta对冲 %s] %s 调整 %+.4f", h.config.Underlying, symbol, current-start)
+	return current - start, nil
+}
@@ -0,0 +1,48 @@
+package hedge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// OptionSource 期权持仓来源
+type OptionSource interface {
+	OptionPositions() ([]OptionPosition, error)
+}
+
+// StaticOptions 固定的期权持仓
+type StaticOptions []OptionPosition
+
+// OptionPositions 实现 OptionSource
+func (s StaticOptions) OptionPositions() ([]OptionPosition, error) {
+	return s, nil
+}
+
+// FileSource 从JSON文件读取期权持仓（每次对冲前重新读取，便于外部程序同步期权平台的持仓）
+type FileSource struct {
+	Path string
+}
+
+// OptionPositions 实现 OptionSource
+func (s *FileSource) OptionPositions() ([]OptionPosition, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取期权持仓文件失败: %w", err)
+	}
+	return ParseOptionPositions(data)
+}
+
+// ParseOptionPositions 解析期权持仓JSON数组
+func ParseOptionPositions(data []byte) ([]OptionPosition, error) {
+	var positions []OptionPosition
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("解析期权持仓失败: %w", err)
+	}
+	for i, p := range positions {
+		if p.Delta == nil && (p.Strike <= 0 || p.Expiry.IsZero()) {
+			return nil, fmt.Errorf("期权持仓 #%d (%s) 缺少行权价或到期时间", i+1, p.Symbol)
+		}
+	}
+	return positions, nil
+}
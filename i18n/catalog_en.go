@@ -0,0 +1,271 @@
+package i18n
+
+// enCatalog 中文 -> 英文词条
+// 只按完整词条翻译（以标点、空格分隔的一段文本），不收录需要与其他词拼接的词语片段；
+// 含未收录中文的文本保留原文
+var enCatalog = map[string]string{
+	// ===== API 错误 =====
+	"交易员不存在":              "trader not found",
+	"交易员已在运行中":            "trader is already running",
+	"交易员已停止":              "trader is already stopped",
+	"交易员ID不能为空":           "trader ID is required",
+	"获取交易员列表失败":           "failed to get trader list",
+	"获取支持的交易所失败":          "failed to get supported exchanges",
+	"获取支持的AI模型失败":         "failed to get supported AI models",
+	"获取AI模型配置失败":          "failed to get AI model config",
+	"获取交易所配置失败":           "failed to get exchange config",
+	"获取交易员配置失败":           "failed to get trader config",
+	"获取决策日志失败":            "failed to get decision logs",
+	"获取账户快照失败":            "failed to get account snapshot",
+	"获取账户信息失败":            "failed to get account info",
+	"获取统计信息失败":            "failed to get statistics",
+	"获取历史数据失败":            "failed to get history",
+	"钱包划转失败":              "wallet transfer failed",
+	"创建交易员失败":             "failed to create trader",
+	"更新交易员失败":             "failed to update trader",
+	"删除交易员失败":             "failed to delete trader",
+	"更新交易员状态失败":           "failed to update trader status",
+	"更新用户状态失败":            "failed to update user status",
+	"无法获取初始余额":            "unable to get initial balance",
+	"山寨币杠杆必须在1-20倍之间":     "altcoin leverage must be between 1x and 20x",
+	"BTC/ETH杠杆必须在1-50倍之间": "BTC/ETH leverage must be between 1x and 50x",
+	"邮箱或密码错误":             "invalid email or password",
+	"邮箱已被注册":              "email is already registered",
+	"用户不存在":               "user not found",
+	"验证码错误":               "invalid verification code",
+	"OTP验证码错误":            "invalid OTP code",
+	"OTP密钥生成失败":           "failed to generate OTP secret",
+	"账户未完成OTP设置":          "OTP setup is not completed for this account",
+	"生成token失败":           "failed to generate token",
+	"无效的token":            "invalid token",
+	"缺少Authorization头":    "missing Authorization header",
+	"无效的Authorization格式":  "invalid Authorization format",
+	"密码处理失败":              "failed to process password",
+	"创建用户失败":              "failed to create user",
+	"没有可用的trader":         "no trader available",
+	"划转成功":                "transfer succeeded",
+	"不支持的语言":              "unsupported locale",
+
+	// ===== 交易执行 =====
+	"获取持仓失败":        "failed to get positions",
+	"获取余额失败":        "failed to get balance",
+	"获取账户余额失败":      "failed to get account balance",
+	"获取价格失败":        "failed to get price",
+	"获取行情失败":        "failed to get ticker",
+	"解析价格失败":        "failed to parse price",
+	"获取交易规则失败":      "failed to get exchange rules",
+	"获取合约交易规则失败":    "failed to get contract rules",
+	"获取合约账户失败":      "failed to get futures account",
+	"获取多币种余额失败":     "failed to get multi-currency balances",
+	"获取手续费率失败":      "failed to get fee rates",
+	"解析手续费率失败":      "failed to parse fee rates",
+	"获取持仓模式失败":      "failed to get position mode",
+	"设置杠杆失败":        "failed to set leverage",
+	"设置仓位模式失败":      "failed to set margin mode",
+	"设置止损失败":        "failed to set stop-loss",
+	"设置止盈失败":        "failed to set take-profit",
+	"创建止损单失败":       "failed to create stop-loss order",
+	"创建止盈单失败":       "failed to create take-profit order",
+	"取消挂单失败":        "failed to cancel open orders",
+	"取消旧委托单失败":      "failed to cancel previous orders",
+	"换算下单张数失败":      "failed to convert order size to contracts",
+	"换算持仓数量失败":      "failed to convert position size",
+	"开多仓失败":         "failed to open long",
+	"开空仓失败":         "failed to open short",
+	"平多仓失败":         "failed to close long",
+	"平空仓失败":         "failed to close short",
+	"开多仓成功":         "opened long",
+	"开空仓成功":         "opened short",
+	"平多仓成功":         "closed long",
+	"平空仓成功":         "closed short",
+	"开多成功":          "opened long",
+	"开仓成功":          "position opened",
+	"平仓成功":          "position closed",
+	"开多仓":           "open long",
+	"开空仓":           "open short",
+	"平多仓":           "close long",
+	"平空仓":           "close short",
+	"划转失败":          "transfer failed",
+	"预估交易成本失败":      "failed to estimate trading cost",
+	"预估交易成本":        "estimated trading cost",
+	"预估成本":          "estimated cost",
+	"不低于预期收益":       "is not below expected profit",
+	"预期收益":          "expected profit",
+	"拒绝开仓以防止仓位叠加超限": "refusing to open to avoid stacking positions beyond limits",
+	"拒绝开仓":          "refusing to open",
+	"实盘将拒绝开仓":       "live trading would refuse to open",
+	"如需换仓，请先给出":     "to switch positions, first issue a",
+	"决策":            "decision",
+	"已有多仓":          "already has a long position",
+	"已有空仓":          "already has a short position",
+	"没有找到":          "not found:",
+	"无效的平仓数量":       "invalid close quantity",
+	"计算后张数":         "contracts after conversion",
+	"数量必须大于":        "quantity must be greater than",
+	"数量和价格必须大于0":    "quantity and price must be greater than 0",
+	"小于最小张数":        "is below the minimum contract size",
+	"未找到交易对":        "symbol not found",
+	"未找到精度信息":       "precision info not found",
+	"未找到价格":         "price not found",
+	"杠杆已是":          "leverage is already",
+	"杠杆已切换为":        "leverage switched to",
+	"无需切换":          "no change needed",
+	"仓位模式已是":        "margin mode is already",
+	"仓位模式已设置为":      "margin mode set to",
+	"有持仓，无法更改仓位模式":  "has open positions, cannot change margin mode",
+	"继续使用当前模式":      "keeping current mode",
+	"止损价设置":         "stop-loss set at",
+	"止盈价设置":         "take-profit set at",
+	"秒冷却期":          "s cooldown",
+	"等待":            "waiting",
+	"全仓":            "cross",
+	"逐仓":            "isolated",
+	"双向持仓":          "hedge mode",
+	"单向持仓":          "one-way mode",
+	"按双向持仓处理":       "assuming hedge mode",
+	"账户持仓模式":        "account position mode",
+	"切换":            "switching",
+	"当前市价":          "current price",
+	"使用缓存的账户余额":     "using cached account balance",
+	"使用缓存的持仓信息":     "using cached positions",
+	"缓存过期":          "cache expired",
+	"缓存时间":          "cached",
+	"秒前":            "s ago",
+	"未知的action":     "unknown action",
+	"不支持的交易平台":      "unsupported exchange",
+	"不支持钱包划转":       "does not support wallet transfers",
+	"保证金占用率":        "margin usage",
+	"超过阈值":          "exceeds threshold",
+	"从现货划转":         "transferring from spot",
+	"自动补充保证金失败":     "automatic margin top-up failed",
+	"自动补充保证金":       "automatic margin top-up",
+	"预演模式已开启":       "dry-run mode enabled",
+	"只记录将要执行的操作":    "only logging intended actions",
+	"不会发送任何订单":      "no orders will be sent",
+	"预演":            "dry-run",
+	"未实现盈亏":         "unrealized PnL",
+	"手续费":           "fees",
+	"资金费":           "funding",
+	"价差":            "spread",
+	"费率":            "fee rate",
+	"来源":            "source",
+
+	// ===== 交易周期 =====
+	"驱动自动交易系统启动": "-driven auto trading system started",
+	"自动交易系统停止":   "auto trading system stopped",
+	"初始余额":       "initial balance",
+	"扫描间隔":       "scan interval",
+	"将全权决定杠杆、仓位大小、止损止盈等参数": "will decide leverage, position size, stop-loss and take-profit on its own",
+	"决策周期":        "decision cycle",
+	"风险控制暂停中":     "risk control pause in effect",
+	"风险控制":        "risk control",
+	"暂停交易中":       "trading paused",
+	"剩余":          "remaining",
+	"日盈亏已重置":      "daily PnL reset",
+	"账户净值":        "account equity",
+	"净值":          "equity",
+	"正在请求AI分析并决策": "requesting AI analysis and decisions",
+	"构建交易上下文失败":   "failed to build trading context",
+	"获取AI决策失败":    "failed to get AI decision",
+	"获取候选币种失败":    "failed to get candidate coins",
+	"分析历史表现失败":    "failed to analyze historical performance",
+	"执行顺序":        "execution order",
+	"已优化":         "optimized",
+	"先平仓→后开仓":     "close first, then open",
+	"执行决策失败":      "failed to execute decision",
+	"保存决策记录失败":    "failed to save decision record",
+	"执行失败":        "execution failed",
+	"决策列表":        "decision list",
+	"使用数据库默认币种":   "using default coins from database",
+	"获取合并币种池失败":   "failed to get merged coin pool",
+	"个候选币种":       "candidate coins",
+	"使用自定义币种":     "using custom coins",
+	"运行错误":        "runtime error",
+	"事件订阅者":       "event subscriber",
+	"缓冲已满，丢弃事件":   "buffer full, dropping event",
+	"累计丢弃":        "total dropped",
+
+	// ===== 启动与配置 =====
+	"初始化配置数据库":             "initializing config database",
+	"初始化数据库失败":             "failed to initialize database",
+	"配置数据库初始化成功":           "config database initialized",
+	"同步config.json到数据库失败":  "failed to sync config.json to database",
+	"开始同步config.json到数据库":  "syncing config.json to database",
+	"config.json同步完成":      "config.json sync completed",
+	"config.json不存在，跳过同步":  "config.json not found, skipping sync",
+	"读取config.json失败":      "failed to read config.json",
+	"解析config.json失败":      "failed to parse config.json",
+	"同步配置":                 "synced config",
+	"更新配置":                 "update config",
+	"使用默认JWT密钥，建议在生产环境中配置": "using default JWT secret, configure one in production",
+	"创建admin用户失败":          "failed to create admin user",
+	"管理员模式已启用，无需登录":        "admin mode enabled, no login required",
+	"加载交易员失败":              "failed to load traders",
+	"暂无配置的交易员":             "no traders configured",
+	"请通过Web界面创建":           "create one via the web UI",
+	"收到退出信号，正在停止所有trader":  "received exit signal, stopping all traders",
+	"感谢使用AI交易系统":           "thanks for using the AI trading system",
+	"服务器错误":                "server error",
+	"停止所有Trader":           "stopping all traders",
+	"已加载到内存":               "loaded into memory",
+	"创建trader失败":           "failed to create trader",
+	"加载用户":                 "loading user",
+	"配置验证失败":               "config validation failed",
+	"读取配置文件失败":             "failed to read config file",
+	"解析配置文件失败":             "failed to parse config file",
+	"打开数据库失败":              "failed to open database",
+	"创建表失败":                "failed to create tables",
+	"初始化默认数据失败":            "failed to initialize default data",
+	"初始金额必须大于0":            "initial balance must be greater than 0",
+	"初始化Hyperliquid交易器失败":  "failed to initialize Hyperliquid trader",
+	"初始化Aster交易器失败":        "failed to initialize Aster trader",
+	"初始化Gate交易器失败":         "failed to initialize Gate trader",
+	"交易器初始化成功":             "trader initialized",
+	"解析私钥失败":               "failed to parse private key",
+	"使用币安合约交易":             "using Binance futures",
+	"使用Hyperliquid交易":      "using Hyperliquid",
+	"使用Aster交易":            "using Aster",
+	"使用Gate交易":             "using Gate",
+	"按币种仓位模式配置无效":          "invalid per-symbol margin mode config",
+	"使用默认模式":               "using default mode",
+
+	// ===== 行情与网络 =====
+	"请求失败":   "request failed",
+	"读取响应失败": "failed to read response",
+	"返回失败状态": "returned error status",
+	"已重试":    "retried",
+	"次重试成功":  "retry succeeded",
+	"请求全部失败": "all requests failed",
+	"签名失败":   "signing failed",
+	"编码失败":   "encoding failed",
+
+	// ===== 常用词（单独成段时） =====
+	"交易员":  "trader",
+	"交易所":  "exchange",
+	"账户":   "account",
+	"持仓":   "position",
+	"仓位模式": "margin mode",
+	"仓位":   "position",
+	"订单":   "order",
+	"杠杆":   "leverage",
+	"数量":   "quantity",
+	"价格":   "price",
+	"精度":   "precision",
+	"止损":   "stop-loss",
+	"止盈":   "take-profit",
+	"保证金":  "margin",
+	"余额":   "balance",
+	"用户":   "user",
+	"模型":   "model",
+	"配置":   "config",
+	"币种":   "coin",
+	"分钟":   "min",
+	"小时":   "h",
+	"状态":   "status",
+	"跳过":   "skipping",
+	"警告":   "warning",
+	"总计":   "total",
+	"可用":   "available",
+	"多仓":   "long",
+	"空仓":   "short",
+}
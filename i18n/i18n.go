@@ -0,0 +1,162 @@
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 支持的语言
+const (
+	LocaleZH = "zh" // 简体中文（源码中的原始文本）
+	LocaleEN = "en" // 英文
+)
+
+var (
+	mu     sync.RWMutex
+	locale = LocaleZH
+
+	// 按原文长度倒序排列的英文词条，同一位置优先匹配较长的词条
+	enPhrases []string
+	enOnce    sync.Once
+)
+
+// NormalizeLocale 规范化语言标识（如 "en-US" -> "en"），不支持的语言返回空字符串
+func NormalizeLocale(l string) string {
+	l = strings.ToLower(strings.TrimSpace(l))
+	switch {
+	case l == "":
+		return ""
+	case strings.HasPrefix(l, "en"):
+		return LocaleEN
+	case strings.HasPrefix(l, "zh"):
+		return LocaleZH
+	default:
+		return ""
+	}
+}
+
+// SetLocale 设置全局语言（日志及API错误信息），不支持的语言返回错误
+func SetLocale(l string) error {
+	normalized := NormalizeLocale(l)
+	if normalized == "" {
+		return fmt.Errorf("不支持的语言: %s（可选: zh, en）", l)
+	}
+	mu.Lock()
+	locale = normalized
+	mu.Unlock()
+	return nil
+}
+
+// Locale 获取当前全局语言
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return locale
+}
+
+// T 按当前全局语言翻译文本
+func T(text string) string {
+	return TranslateTo(Locale(), text)
+}
+
+// Sprintf 翻译格式串后格式化（参数本身不翻译）
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// TranslateTo 将文本翻译为指定语言
+// 源码文本为中文，因此目标为中文时原样返回；英文只翻译完整词条：词条在文本中须以标点、空格或文本首尾为界
+// （如 "获取持仓失败: timeout"），翻译后仍有未收录的中文时原样返回，不用词语片段拼凑译文
+func TranslateTo(l, text string) string {
+	if l != LocaleEN || !containsHan(text) {
+		return text
+	}
+
+	enOnce.Do(func() {
+		for zh := range enCatalog {
+			enPhrases = append(enPhrases, zh)
+		}
+		sort.Slice(enPhrases, func(i, j int) bool { return len(enPhrases[i]) > len(enPhrases[j]) })
+	})
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if i == 0 || isBoundary(lastRune(text[:i])) {
+			if zh, ok := matchPhrase(text[i:]); ok {
+				b.WriteString(enCatalog[zh])
+				i += len(zh)
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(text[i : i+size])
+		i += size
+	}
+	translated := b.String()
+	if containsHan(translated) {
+		return text
+	}
+	return strings.NewReplacer("：", ": ", "，", ", ", "（", " (", "）", ")", "。", ". ").Replace(translated)
+}
+
+// matchPhrase 文本开头的最长完整词条（词条之后须为文本结尾或分隔符）
+func matchPhrase(s string) (string, bool) {
+	for _, zh := range enPhrases {
+		if strings.HasPrefix(s, zh) && (len(s) == len(zh) || isBoundary(firstRune(s[len(zh):]))) {
+			return zh, true
+		}
+	}
+	return "", false
+}
+
+// isBoundary 词条的分隔符：标点、空格、符号等（汉字、字母、数字以外的字符）
+func isBoundary(r rune) bool {
+	return !isHan(r) && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// isHan 是否为汉字
+func isHan(r rune) bool {
+	return r >= 0x4E00 && r <= 0x9FFF
+}
+
+// containsHan 文本是否包含汉字
+func containsHan(s string) bool {
+	for _, r := range s {
+		if isHan(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// translateWriter 按当前全局语言翻译写入的日志
+type translateWriter struct {
+	w io.Writer
+}
+
+func (t *translateWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(t.w, T(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// NewWriter 创建按全局语言翻译日志的输出（用于 log.SetOutput）
+func NewWriter(w io.Writer) io.Writer {
+	return &translateWriter{w: w}
+}
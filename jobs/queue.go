@@ -0,0 +1,222 @@
+// Package jobs 慢操作（交易规则刷新、历史数据下载、报告生成）的后台任务队列，避免阻塞交易周期，并提供任务状态查询
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// maxFinished 保留的已结束任务数（超出时删除最早结束的任务）
+const maxFinished = 200
+
+// ErrQueueFull 等待执行的任务已达队列容量
+var ErrQueueFull = errors.New("后台任务队列已满，请稍后再试")
+
+// Func 任务函数，返回值作为任务结果（需可JSON序列化）；ctx在队列停止时取消
+type Func func(ctx context.Context) (interface{}, error)
+
+// Job 任务状态快照
+type Job struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Key        string            `json:"key,omitempty"`   // 去重键：同键任务排队或执行中时不重复提交
+	Owner      string            `json:"owner,omitempty"` // 提交任务的用户（系统任务为空）
+	Params     map[string]string `json:"params,omitempty"`
+	Status     Status            `json:"status"`
+	Result     interface{}       `json:"result,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
+
+// Done 任务是否已结束
+func (j Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+type task struct {
+	job *Job
+	fn  Func
+}
+
+// Queue 固定数量worker的后台任务队列（并发安全）
+type Queue struct {
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	active map[string]string // 去重键 -> 排队或执行中的任务ID
+	nextID int64
+
+	pending chan task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewQueue 创建任务队列并启动workers个worker，capacity为最多等待执行的任务数
+func NewQueue(workers, capacity int) *Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		jobs:    make(map[string]*Job),
+		active:  make(map[string]string),
+		pending: make(chan task, capacity),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+	return q
+}
+
+// Submit 提交任务（job只需填写Type、Key、Owner、Params），返回任务快照
+// Key非空且同键任务排队或执行中时直接返回已有任务，不重复执行
+func (q *Queue) Submit(job Job, fn Func) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.ctx.Err() != nil {
+		return Job{}, errors.New("后台任务队列已停止")
+	}
+	if job.Key != "" {
+		if id, ok := q.active[job.Key]; ok {
+			return *q.jobs[id], nil
+		}
+	}
+
+	q.nextID++
+	j := &Job{
+		ID:        fmt.Sprintf("%s-%d-%d", job.Type, time.Now().UnixMilli(), q.nextID),
+		Type:      job.Type,
+		Key:       job.Key,
+		Owner:     job.Owner,
+		Params:    job.Params,
+		Status:    StatusQueued,
+		CreatedAt: time.Now(),
+	}
+	select {
+	case q.pending <- task{job: j, fn: fn}:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[j.ID] = j
+	if j.Key != "" {
+		q.active[j.Key] = j.ID
+	}
+	return *j, nil
+}
+
+// Get 按ID查询任务
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+// List 最近的任务（按创建时间倒序），filter为nil时返回全部
+func (q *Queue) List(filter func(Job) bool, limit int) []Job {
+	q.mu.Lock()
+	list := make([]Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		if filter == nil || filter(*j) {
+			list = append(list, *j)
+		}
+	}
+	q.mu.Unlock()
+	sort.Slice(list, func(i, k int) bool { return list[i].CreatedAt.After(list[k].CreatedAt) })
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// Stop 停止接收新任务，取消执行中任务的ctx并等待worker退出（未开始的任务不再执行）
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	q.cancel()
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *Queue) worker() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case t := <-q.pending:
+			q.run(t)
+		}
+	}
+}
+
+// run 执行任务并记录结果（任务panic时记为失败）
+func (q *Queue) run(t task) {
+	q.mu.Lock()
+	t.job.Status, t.job.StartedAt = StatusRunning, time.Now()
+	q.mu.Unlock()
+
+	var result interface{}
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("任务panic: %v", r)
+			}
+		}()
+		result, err = t.fn(q.ctx)
+	}()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t.job.FinishedAt = time.Now()
+	if err != nil {
+		t.job.Status, t.job.Error = StatusFailed, err.Error()
+		log.Printf("⚠️  后台任务 %s 失败（耗时 %s）: %v", t.job.ID, t.job.FinishedAt.Sub(t.job.StartedAt).Round(time.Millisecond), err)
+	} else {
+		t.job.Status, t.job.Result = StatusSucceeded, result
+	}
+	if t.job.Key != "" && q.active[t.job.Key] == t.job.ID {
+		delete(q.active, t.job.Key)
+	}
+	q.pruneLocked()
+}
+
+// pruneLocked 删除超出保留数量的最早结束的任务
+func (q *Queue) pruneLocked() {
+	finished := make([]*Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		if j.Done() {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= maxFinished {
+		return
+	}
+	sort.Slice(finished, func(i, k int) bool { return finished[i].FinishedAt.Before(finished[k].FinishedAt) })
+	for _, j := range finished[:len(finished)-maxFinished] {
+		delete(q.jobs, j.ID)
+	}
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitDone 等待任务结束
+func waitDone(t *testing.T, q *Queue, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := q.Get(id); ok && job.Done() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("任务 %s 未在超时前结束", id)
+	return Job{}
+}
+
+func TestQueueDeduplicatesActiveKey(t *testing.T) {
+	q := NewQueue(1, 10)
+	defer q.Stop()
+
+	release := make(chan struct{})
+	first, err := q.Submit(Job{Type: "slow", Key: "k"}, func(context.Context) (interface{}, error) {
+		<-release
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := q.Submit(Job{Type: "slow", Key: "k"}, func(context.Context) (interface{}, error) {
+		t.Error("同键任务不应重复执行")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("同键任务应返回已有任务 %s，得到 %s", first.ID, second.ID)
+	}
+
+	close(release)
+	if job := waitDone(t, q, first.ID); job.Status != StatusSucceeded || job.Result != 42 {
+		t.Fatalf("任务状态 %s 结果 %v，期望 succeeded 42", job.Status, job.Result)
+	}
+
+	// 结束后同键任务可以再次提交
+	third, err := q.Submit(Job{Type: "slow", Key: "k"}, func(context.Context) (interface{}, error) { return nil, nil })
+	if err != nil || third.ID == first.ID {
+		t.Fatalf("结束后应创建新任务: %v %s", err, third.ID)
+	}
+	waitDone(t, q, third.ID)
+}
+
+func TestQueueRecordsFailures(t *testing.T) {
+	q := NewQueue(2, 10)
+	defer q.Stop()
+
+	failed, _ := q.Submit(Job{Type: "fail"}, func(context.Context) (interface{}, error) { return nil, errors.New("boom") })
+	panicked, _ := q.Submit(Job{Type: "panic"}, func(context.Context) (interface{}, error) { panic("oops") })
+
+	if job := waitDone(t, q, failed.ID); job.Status != StatusFailed || job.Error != "boom" {
+		t.Fatalf("失败任务状态 %s 错误 %q", job.Status, job.Error)
+	}
+	if job := waitDone(t, q, panicked.ID); job.Status != StatusFailed {
+		t.Fatalf("panic任务应记为失败，得到 %s", job.Status)
+	}
+	if list := q.List(func(j Job) bool { return j.Type == "fail" }, 0); len(list) != 1 {
+		t.Fatalf("按类型过滤得到 %d 个任务，期望1个", len(list))
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	q := NewQueue(1, 1)
+	defer q.Stop()
+
+	release := make(chan struct{})
+	defer close(release)
+	block := func(context.Context) (interface{}, error) { <-release; return nil, nil }
+	running, _ := q.Submit(Job{Type: "block"}, block)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if job, _ := q.Get(running.ID); job.Status == StatusRunning {
+			break
+		}
+	}
+	if _, err := q.Submit(Job{Type: "block"}, block); err != nil {
+		t.Fatalf("队列未满时提交失败: %v", err)
+	}
+	if _, err := q.Submit(Job{Type: "block"}, block); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("队列已满时应返回 ErrQueueFull，得到 %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package listings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 各交易所公开的合约列表接口
+const (
+	BinanceExchangeInfoURL = "https://fapi.binance.com/fapi/v1/exchangeInfo"
+	GateContractsURL       = "https://api.gateio.ws/api/v4/futures/usdt/contracts"
+	HyperliquidInfoURL     = "https://api.hyperliquid.xyz/info"
+)
+
+// Listing 一个永续合约
+type Listing struct {
+	Exchange  string    `json:"exchange"`
+	Symbol    string    `json:"symbol"`              // 统一为 BTCUSDT 格式
+	ListedAt  time.Time `json:"listed_at,omitempty"` // 交易所公布的上线时间（零值=未提供）
+	FirstSeen time.Time `json:"first_seen"`          // 扫描器首次发现的时间
+}
+
+// Since 上线时长（未提供上线时间时按首次发现时间计算）
+func (l Listing) Since(now time.Time) time.Duration {
+	if !l.ListedAt.IsZero() {
+		return now.Sub(l.ListedAt)
+	}
+	return now.Sub(l.FirstSeen)
+}
+
+// Source 某个交易所当前可交易的永续合约
+type Source interface {
+	Exchange() string
+	Perpetuals(ctx context.Context) ([]Listing, error)
+}
+
+// Config 新上线扫描配置
+type Config struct {
+	Exchanges []string      // 扫描的交易所（binance / gate / hyperliquid）
+	Interval  time.Duration // 扫描间隔
+	Recent    time.Duration // 首次扫描时，上线时间在该时长内的合约也视为新上线（重启后不丢失）
+}
+
+// Scanner 定期扫描交易所合约列表，发现新上线的永续合约
+type Scanner struct {
+	config  Config
+	sources []Source
+	onNew   func(Listing)
+
+	mu       sync.RWMutex
+	known    map[string]bool // 交易所|合约
+	listings []Listing       // 已发现的新上线合约
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScanner 创建扫描器，onNew 在发现新合约时调用
+func NewScanner(config Config, onNew func(Listing)) (*Scanner, error) {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Minute
+	}
+	s := &Scanner{config: config, onNew: onNew, known: make(map[string]bool), stop: make(chan struct{})}
+	for _, exchange := range config.Exchanges {
+		src, err := NewSource(strings.TrimSpace(exchange))
+		if err != nil {
+			return nil, err
+		}
+		s.sources = append(s.sources, src)
+	}
+	return s, nil
+}
+
+// NewSource 按交易所名称创建合约列表来源
+func NewSource(exchange string) (Source, error) {
+	switch strings.ToLower(exchange) {
+	case "binance":
+		return &BinanceSource{URL: BinanceExchangeInfoURL}, nil
+	case "gate":
+		return &GateSource{URL: GateContractsURL}, nil
+	case "hyperliquid":
+		return &HyperliquidSource{URL: HyperliquidInfoURL}, nil
+	default:
+		return nil, fmt.Errorf("不支持扫描新上线合约的交易所: %s", exchange)
+	}
+}
+
+// Start 启动定期扫描（首次扫描只建立基线，不视为新上线）
+func (s *Scanner) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+		baseline := true
+		for {
+			s.Scan(time.Now(), baseline)
+			baseline = false
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("🆕 新上线合约扫描已启动: %v（每 %s 扫描一次）", s.config.Exchanges, s.config.Interval)
+}
+
+// Stop 停止扫描
+func (s *Scanner) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Scan 扫描一次所有交易所；baseline=true 时只记录已有合约（上线时间在 Recent 内的除外）
+func (s *Scanner) Scan(now time.Time, baseline bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, src := range s.sources {
+		listings, err := src.Perpetuals(ctx)
+		if err != nil {
+			log.Printf("⚠️  [新上线扫描] %s: %v", src.Exchange(), err)
+			continue
+		}
+
+		var found []Listing
+		s.mu.Lock()
+		// 某个交易所首次成功扫描时同样只建立基线，避免启动时网络失败导致把全部合约当作新上线
+		first := baseline || !s.hasExchange(src.Exchange())
+		for _, l := range listings {
+			key := l.Exchange + "|" + l.Symbol
+			if s.known[key] {
+				continue
+			}
+			s.known[key] = true
+			l.FirstSeen = now
+			if first && (l.ListedAt.IsZero() || s.config.Recent <= 0 || now.Sub(l.ListedAt) > s.config.Recent) {
+				continue
+			}
+			s.listings = append(s.listings, l)
+			found = append(found, l)
+		}
+		s.mu.Unlock()
+
+		for _, l := range found {
+			log.Printf("🆕 [新上线扫描] 发现 %s 新合约 %s", l.Exchange, l.Symbol)
+			if s.onNew != nil {
+				s.onNew(l)
+			}
+		}
+	}
+}
+
+// hasExchange 是否已扫描过该交易所（调用方持有锁）
+func (s *Scanner) hasExchange(exchange string) bool {
+	prefix := exchange + "|"
+	for key := range s.known {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Listings 已发现的新上线合约（按发现时间倒序）
+func (s *Scanner) Listings() []Listing {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := append([]Listing{}, s.listings...)
+	sort.Slice(result, func(i, j int) bool { return result[i].FirstSeen.After(result[j].FirstSeen) })
+	return result
+}
+
+// BinanceSource 币安U本位永续合约
+type BinanceSource struct {
+	URL string
+}
+
+// Exchange 实现 Source
+func (s *BinanceSource) Exchange() string { return "binance" }
+
+// Perpetuals 实现 Source
+func (s *BinanceSource) Perpetuals(ctx context.Context) ([]Listing, error) {
+	body, err := do(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		Symbols []struct {
+			Symbol       string `json:"symbol"`
+			Status       string `json:"status"`
+			ContractType string `json:"contractType"`
+			QuoteAsset   string `json:"quoteAsset"`
+			OnboardDate  int64  `json:"onboardDate"`
+		} `json:"symbols"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("解析币安合约列表失败: %w", err)
+	}
+	var result []Listing
+	for _, sym := range info.Symbols {
+		if sym.ContractType != "PERPETUAL" || sym.QuoteAsset != "USDT" || sym.Status != "TRADING" {
+			continue
+		}
+		l := Listing{Exchange: "binance", Symbol: sym.Symbol}
+		if sym.OnboardDate > 0 {
+			l.ListedAt = time.UnixMilli(sym.OnboardDate)
+		}
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+// GateSource Gate USDT永续合约
+type GateSource struct {
+	URL string
+}
+
+// Exchange 实现 Source
+func (s *GateSource) Exchange() string { return "gate" }
+
+// Perpetuals 实现 Source
+func (s *GateSource) Perpetuals(ctx context.Context) ([]Listing, error) {
+	body, err := do(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var contracts []struct {
+		Name        string  `json:"name"`
+		InDelisting bool    `json:"in_delisting"`
+		LaunchTime  int64   `json:"launch_time"`
+		CreateTime  float64 `json:"create_time"`
+	}
+	if err := json.Unmarshal(body, &contracts); err != nil {
+		return nil, fmt.Errorf("解析Gate合约列表失败: %w", err)
+	}
+	var result []Listing
+	for _, c := range contracts {
+		if c.InDelisting || !strings.HasSuffix(c.Name, "_USDT") {
+			continue
+		}
+		l := Listing{Exchange: "gate", Symbol: strings.ReplaceAll(c.Name, "_", "")}
+		if c.LaunchTime > 0 {
+			l.ListedAt = time.Unix(c.LaunchTime, 0)
+		} else if c.CreateTime > 0 {
+			l.ListedAt = time.Unix(int64(c.CreateTime), 0)
+		}
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+// HyperliquidSource Hyperliquid永续合约（接口不提供上线时间，按首次发现时间计算）
+type HyperliquidSource struct {
+	URL string
+}
+
+// Exchange 实现 Source
+func (s *HyperliquidSource) Exchange() string { return "hyperliquid" }
+
+// Perpetuals 实现 Source
+func (s *HyperliquidSource) Perpetuals(ctx context.Context) ([]Listing, error) {
+	body, err := do(ctx, http.MethodPost, s.URL, []byte(`{"type":"meta"}`))
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Universe []struct {
+			Name       string `json:"name"`
+			IsDelisted bool   `json:"isDelisted"`
+		} `json:"universe"`
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, fmt.Errorf("解析Hyperliquid合约列表失败: %w", err)
+	}
+	var result []Listing
+	for _, u := range meta.Universe {
+		if u.IsDelisted {
+			continue
+		}
+		result = append(result, Listing{Exchange: "hyperliquid", Symbol: strings.ToUpper(u.Name) + "USDT"})
+	}
+	return result, nil
+}
+
+// do 发送请求并读取响应
+func do(ctx context.Context, method, url string, payload []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求合约列表失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditDirName 审计日志子目录（位于交易员决策日志目录下，不与决策记录混在一起）
+const auditDirName = "audit"
+
+// AuditRecord AI决策审计记录：一次AI调用从prompt到下单的完整链路
+// 每条记录包含上一条记录的哈希，形成哈希链，事后修改任何一条都会被 Verify 发现
+type AuditRecord struct {
+	Sequence    int64     `json:"sequence"`     // 记录序号（从1开始连续递增）
+	Timestamp   time.Time `json:"timestamp"`    // 写入审计日志的时间
+	TraderID    string    `json:"trader_id"`    // 交易员ID
+	CycleNumber int       `json:"cycle_number"` // 对应决策记录的周期编号
+	Provider    string    `json:"provider"`     // AI提供商
+	Model       string    `json:"model"`        // 模型名称
+	RequestedAt time.Time `json:"requested_at"` // 发起AI请求的时间
+	RespondedAt time.Time `json:"responded_at"` // 收到AI响应的时间
+
+	SystemPrompt string `json:"system_prompt"` // 系统提示词
+	UserPrompt   string `json:"user_prompt"`   // 输入prompt
+	RawResponse  string `json:"raw_response"`  // AI原始响应
+	DecisionJSON string `json:"decision_json"` // 解析后的决策列表
+
+	PromptHash   string `json:"prompt_hash"`   // sha256(system_prompt + "\n" + user_prompt)
+	ResponseHash string `json:"response_hash"` // sha256(raw_response)
+	DecisionHash string `json:"decision_hash"` // sha256(decision_json)
+
+	Orders []DecisionAction `json:"orders"`          // 决策执行结果（含订单ID）
+	Error  string           `json:"error,omitempty"` // AI调用或解析失败的原因
+
+	PrevHash string `json:"prev_hash"` // 上一条记录的哈希（第一条为空）
+	Hash     string `json:"hash"`      // 本条记录的哈希（计算时 hash 字段为空）
+}
+
+// HashText 计算文本的sha256（十六进制）
+func HashText(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// computeHash 计算记录哈希（不含 hash 字段本身）
+func (r *AuditRecord) computeHash() (string, error) {
+	copied := *r
+	copied.Hash = ""
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		return "", fmt.Errorf("序列化审计记录失败: %w", err)
+	}
+	return HashText(string(data)), nil
+}
+
+// AuditLogger 追加写入的AI审计日志（按天分文件：audit_YYYYMMDD.jsonl）
+type AuditLogger struct {
+	mu       sync.Mutex
+	dir      string
+	sequence int64
+	lastHash string
+}
+
+// NewAuditLogger 创建审计日志记录器，logDir 为交易员的决策日志目录
+// 启动时读取最后一条记录以延续哈希链
+func NewAuditLogger(logDir string) *AuditLogger {
+	if logDir == "" {
+		logDir = logRoot
+	}
+	l := &AuditLogger{dir: filepath.Join(logDir, auditDirName)}
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		fmt.Printf("⚠ 创建审计日志目录失败: %v\n", err)
+	}
+
+	files, _ := l.files()
+	for i := len(files) - 1; i >= 0; i-- {
+		records, err := readAuditFile(files[i])
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		last := records[len(records)-1]
+		l.sequence, l.lastHash = last.Sequence, last.Hash
+		break
+	}
+	return l
+}
+
+// Append 计算哈希并追加一条审计记录
+func (l *AuditLogger) Append(record *AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.Timestamp = time.Now()
+	record.Sequence = l.sequence + 1
+	record.PrevHash = l.lastHash
+	record.PromptHash = HashText(record.SystemPrompt + "\n" + record.UserPrompt)
+	record.ResponseHash = HashText(record.RawResponse)
+	record.DecisionHash = HashText(record.DecisionJSON)
+	hash, err := record.computeHash()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %w", err)
+	}
+	path := filepath.Join(l.dir, fmt.Sprintf("audit_%s.jsonl", record.Timestamp.Format("20060102")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开审计日志失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+
+	l.sequence, l.lastHash = record.Sequence, record.Hash
+	return nil
+}
+
+// Records 获取最近N条审计记录（按时间倒序）
+func (l *AuditLogger) Records(n int) ([]*AuditRecord, error) {
+	files, err := l.files()
+	if err != nil {
+		return nil, err
+	}
+	var result []*AuditRecord
+	for i := len(files) - 1; i >= 0 && len(result) < n; i-- {
+		records, err := readAuditFile(files[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := len(records) - 1; j >= 0 && len(result) < n; j-- {
+			result = append(result, records[j])
+		}
+	}
+	return result, nil
+}
+
+// FindByOrder 按订单ID或客户端订单ID查找产生该订单的审计记录
+func (l *AuditLogger) FindByOrder(orderID int64, clientOrderID string) (*AuditRecord, error) {
+	files, err := l.files()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		records, err := readAuditFile(files[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := len(records) - 1; j >= 0; j-- {
+			for _, order := range records[j].Orders {
+				if (orderID != 0 && order.OrderID == orderID) || (clientOrderID != "" && order.ClientOrderID == clientOrderID) {
+					return records[j], nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("未找到订单对应的审计记录")
+}
+
+// Verify 校验全部审计记录的哈希及哈希链，返回校验通过的记录数
+func (l *AuditLogger) Verify() (int, error) {
+	files, err := l.files()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	prevHash := ""
+	var prevSeq int64
+	for _, path := range files {
+		records, err := readAuditFile(path)
+		if err != nil {
+			return count, err
+		}
+		for _, r := range records {
+			if r.PrevHash != prevHash || (prevSeq != 0 && r.Sequence != prevSeq+1) {
+				return count, fmt.Errorf("审计记录 #%d 哈希链断裂（%s）", r.Sequence, filepath.Base(path))
+			}
+			hash, err := r.computeHash()
+			if err != nil {
+				return count, err
+			}
+			if hash != r.Hash ||
+				r.PromptHash != HashText(r.SystemPrompt+"\n"+r.UserPrompt) ||
+				r.ResponseHash != HashText(r.RawResponse) ||
+				r.DecisionHash != HashText(r.DecisionJSON) {
+				return count, fmt.Errorf("审计记录 #%d 内容与哈希不符（%s）", r.Sequence, filepath.Base(path))
+			}
+			prevHash, prevSeq = r.Hash, r.Sequence
+			count++
+		}
+	}
+	return count, nil
+}
+
+// files 按日期正序列出审计日志文件
+func (l *AuditLogger) files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(l.dir, "audit_*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("查找审计日志失败: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readAuditFile 读取单个审计日志文件
+func readAuditFile(path string) ([]*AuditRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取审计日志失败: %w", err)
+	}
+	defer f.Close()
+
+	var records []*AuditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024) // prompt可能很长
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var r AuditRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, fmt.Errorf("解析审计日志 %s 失败: %w", filepath.Base(path), err)
+		}
+		records = append(records, &r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取审计日志失败: %w", err)
+	}
+	return records, nil
+}
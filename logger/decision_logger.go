@@ -46,19 +46,45 @@ type PositionSnapshot struct {
 	UnrealizedProfit float64 `json:"unrealized_profit"`
 	Leverage         float64 `json:"leverage"`
 	LiquidationPrice float64 `json:"liquidation_price"`
+	StopLoss         float64 `json:"stop_loss,omitempty"`    // 交易所止损价（本周期跟踪止损调整后）
+	TakeProfit       float64 `json:"take_profit,omitempty"`  // 交易所止盈价
+	FundingRate      float64 `json:"funding_rate,omitempty"` // 本周期的资金费率（每个结算周期）
 }
 
 // DecisionAction 决策动作
 type DecisionAction struct {
-	Action    string    `json:"action"`    // open_long, open_short, close_long, close_short
-	Symbol    string    `json:"symbol"`    // 币种
-	Quantity  float64   `json:"quantity"`  // 数量
-	Leverage  int       `json:"leverage"`  // 杠杆（开仓时）
-	Price     float64   `json:"price"`     // 执行价格
-	OrderID   int64     `json:"order_id"`  // 订单ID
-	Timestamp time.Time `json:"timestamp"` // 执行时间
-	Success   bool      `json:"success"`   // 是否成功
-	Error     string    `json:"error"`     // 错误信息
+	Action        string    `json:"action"`                    // open_long, open_short, close_long, close_short
+	Symbol        string    `json:"symbol"`                    // 币种
+	Quantity      float64   `json:"quantity"`                  // 数量
+	Leverage      int       `json:"leverage"`                  // 杠杆（开仓时）
+	Price         float64   `json:"price"`                     // 执行价格
+	OrderID       int64     `json:"order_id"`                  // 订单ID
+	ClientOrderID string    `json:"client_order_id,omitempty"` // 客户端订单ID（含策略归属标识）
+	Timestamp     time.Time `json:"timestamp"`                 // 执行时间
+	Success       bool      `json:"success"`                   // 是否成功
+	Error         string    `json:"error"`                     // 错误信息
+	DryRun        bool      `json:"dry_run,omitempty"`         // 是否为预演（未实际下单）
+	Preview       string    `json:"preview,omitempty"`         // 预演模式下将要执行的操作描述
+	StopLoss      float64   `json:"stop_loss,omitempty"`       // 开仓时设置的止损价（按成交价换算后）
+	TakeProfit    float64   `json:"take_profit,omitempty"`     // 开仓时设置的止盈价
+	FeeRate       float64   `json:"fee_rate,omitempty"`        // 成交适用的taker费率（用于手续费归因）
+
+	Context *TradeContext `json:"context,omitempty"` // 下单时的策略上下文（指标、AI理由）
+}
+
+// logRoot 决策日志根目录（每个trader在其下使用独立子目录）
+var logRoot = "decision_logs"
+
+// SetLogRoot 设置决策日志根目录（如容器中挂载的数据卷）
+func SetLogRoot(dir string) {
+	if dir != "" {
+		logRoot = dir
+	}
+}
+
+// LogRoot 获取决策日志根目录
+func LogRoot() string {
+	return logRoot
 }
 
 // DecisionLogger 决策日志记录器
@@ -70,7 +96,7 @@ type DecisionLogger struct {
 // NewDecisionLogger 创建决策日志记录器
 func NewDecisionLogger(logDir string) *DecisionLogger {
 	if logDir == "" {
-		logDir = "decision_logs"
+		logDir = logRoot
 	}
 
 	// 确保日志目录存在
@@ -0,0 +1,401 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// 持仓生命周期事件类型
+const (
+	PositionEntry        = "entry"         // 开仓成交
+	PositionAdd          = "add"           // 加仓
+	PositionStopLoss     = "stop_loss"     // 设置/调整止损
+	PositionTakeProfit   = "take_profit"   // 设置/调整止盈
+	PositionPartialClose = "partial_close" // 部分平仓（持仓快照中数量减少）
+	PositionClose        = "close"         // 平仓
+	PositionFunding      = "funding"       // 资金费结算
+)
+
+// fundingInterval 资金费结算周期（按UTC 0/8/16点结算估算）
+const fundingInterval = 8 * time.Hour
+
+// quantityTolerance 持仓数量比较的相对误差（忽略交易所精度取整）
+const quantityTolerance = 0.001
+
+// PositionEvent 持仓生命周期中的一个事件
+type PositionEvent struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Price    float64   `json:"price,omitempty"`
+	Quantity float64   `json:"quantity,omitempty"`
+	OrderID  int64     `json:"order_id,omitempty"`
+	PnL      float64   `json:"pnl,omitempty"` // 本次减仓实现的价格盈亏，或资金费（正数=收取，负数=支付）
+	Fee      float64   `json:"fee,omitempty"` // 本次成交的手续费
+	Note     string    `json:"note,omitempty"`
+
+	Context *TradeContext `json:"context,omitempty"` // 开平仓时的策略上下文
+}
+
+// PositionLifecycle 从决策日志重建的单个持仓的完整生命周期
+type PositionLifecycle struct {
+	ID          string          `json:"id"` // symbol_side_开仓毫秒时间戳
+	Symbol      string          `json:"symbol"`
+	Side        string          `json:"side"`
+	Status      string          `json:"status"` // open / closed
+	Leverage    int             `json:"leverage"`
+	OpenTime    time.Time       `json:"open_time"`
+	CloseTime   time.Time       `json:"close_time"`
+	Duration    string          `json:"duration"`
+	EntryPrice  float64         `json:"entry_price"`  // 平均开仓价
+	ExitPrice   float64         `json:"exit_price"`   // 平均平仓价
+	MaxQuantity float64         `json:"max_quantity"` // 持仓期间的最大数量
+	Quantity    float64         `json:"quantity"`     // 当前剩余数量
+	StopLoss    float64         `json:"stop_loss"`    // 最后的止损价
+	TakeProfit  float64         `json:"take_profit"`  // 最后的止盈价
+	MarkPrice   float64         `json:"mark_price"`   // 最后观察到的标记价格
+	PricePnL    float64         `json:"price_pnl"`    // 已实现价格盈亏
+	FundingPnL  float64         `json:"funding_pnl"`  // 资金费（正数=收取，负数=支付）
+	Fees        float64         `json:"fees"`         // 手续费
+	RealizedPnL float64         `json:"realized_pnl"` // 已实现净盈亏 = 价格盈亏 + 资金费 - 手续费
+	PnLPct      float64         `json:"pnl_pct"`      // 已实现净盈亏相对保证金的百分比
+	CloseReason string          `json:"close_reason,omitempty"`
+	Events      []PositionEvent `json:"events"`
+	Notes       []TradeNote     `json:"notes,omitempty"` // 复盘笔记（由调用方附加）
+
+	exitValue        float64
+	exitQuantity     float64
+	feeRate          float64   // 最近一次成交的费率（用于从快照推断的减仓）
+	fundingRate      float64   // 最近记录的资金费率
+	fundingAccruedTo time.Time // 资金费已计算到的时间
+}
+
+// RealizedBreakdown 区间内已实现盈亏的拆分（价格盈亏、资金费、手续费）
+type RealizedBreakdown struct {
+	PricePnL   float64 `json:"price_pnl"`
+	FundingPnL float64 `json:"funding_pnl"` // 正数=收取，负数=支付
+	Fees       float64 `json:"fees"`
+	NetPnL     float64 `json:"net_pnl"`
+	Trades     int     `json:"trades"`  // 平仓次数
+	Entries    int     `json:"entries"` // 开仓次数
+	Volume     float64 `json:"volume"`  // 成交额（开仓+平仓名义价值）
+}
+
+// replayLevels 决策JSON中的止盈止损（兼容未记录在执行动作中的旧日志）
+type replayLevels struct {
+	Symbol     string  `json:"symbol"`
+	Action     string  `json:"action"`
+	StopLoss   float64 `json:"stop_loss"`
+	TakeProfit float64 `json:"take_profit"`
+}
+
+// ReplayPositions 从最近lookback条决策记录重建所有持仓的生命周期（按开仓时间倒序）
+// 开平仓来自执行动作，止盈止损调整、部分平仓及交易所触发的平仓从每个周期的持仓快照推断，
+// 资金费按快照记录的费率在每个结算时刻估算
+func (l *DecisionLogger) ReplayPositions(lookback int) ([]*PositionLifecycle, error) {
+	records, err := l.GetLatestRecords(lookback)
+	if err != nil {
+		return nil, err
+	}
+	return ReplayRecords(records), nil
+}
+
+// ReplayRecords 从按时间正序排列的决策记录重建持仓生命周期（按开仓时间倒序）
+func ReplayRecords(records []*DecisionRecord) []*PositionLifecycle {
+	var result []*PositionLifecycle
+	open := make(map[string]*PositionLifecycle) // symbol_side -> 进行中的持仓
+	for _, record := range records {
+		// 只有决策周期的记录带有持仓快照（外部信号/手动平仓等记录没有）
+		if record.AccountState.TotalBalance > 0 {
+			seen := make(map[string]bool)
+			for _, pos := range record.Positions {
+				key := pos.Symbol + "_" + pos.Side
+				seen[key] = true
+				lc := open[key]
+				if lc == nil {
+					lc = newPositionLifecycle(pos.Symbol, pos.Side, record.Timestamp)
+					open[key] = lc
+					result = append(result, lc)
+					lc.addEntry(record.Timestamp, PositionEntry, pos.EntryPrice, math.Abs(pos.PositionAmt), 0, "首次在持仓快照中观察到（外部开仓或日志缺失）")
+				}
+				lc.observe(record.Timestamp, pos)
+			}
+			for key, lc := range open {
+				if !seen[key] {
+					lc.accrueFunding(record.Timestamp)
+					lc.closeByExchange(record.Timestamp)
+					delete(open, key)
+				}
+			}
+		}
+
+		for _, action := range record.Decisions {
+			if !action.Success || action.DryRun {
+				continue
+			}
+			switch action.Action {
+			case "open_long", "open_short":
+				side := action.Action[len("open_"):]
+				key := action.Symbol + "_" + side
+				lc := open[key]
+				eventType := PositionAdd
+				if lc == nil {
+					lc = newPositionLifecycle(action.Symbol, side, action.Timestamp)
+					open[key] = lc
+					result = append(result, lc)
+					eventType = PositionEntry
+				}
+				if action.Leverage > 0 {
+					lc.Leverage = action.Leverage
+				}
+				if action.FeeRate > 0 {
+					lc.feeRate = action.FeeRate
+				}
+				lc.accrueFunding(action.Timestamp)
+				lc.addEntry(action.Timestamp, eventType, action.Price, action.Quantity, action.OrderID, "")
+				lc.Events[len(lc.Events)-1].Context = action.Context
+
+				stopLoss, takeProfit := action.StopLoss, action.TakeProfit
+				if stopLoss <= 0 && takeProfit <= 0 {
+					stopLoss, takeProfit = decisionLevels(record, action.Symbol, action.Action)
+				}
+				lc.updateLevels(action.Timestamp, stopLoss, takeProfit)
+			case "close_long", "close_short":
+				key := action.Symbol + "_" + action.Action[len("close_"):]
+				lc := open[key]
+				if lc == nil {
+					continue
+				}
+				if action.FeeRate > 0 {
+					lc.feeRate = action.FeeRate
+				}
+				lc.accrueFunding(action.Timestamp)
+				n := len(lc.Events)
+				lc.reduce(action.Timestamp, PositionClose, action.Price, lc.Quantity, action.OrderID, "平仓")
+				if len(lc.Events) > n {
+					lc.Events[len(lc.Events)-1].Context = action.Context
+				}
+				reason := "decision"
+				if action.Context != nil && action.Context.Source == TradeSourceStopLoss {
+					reason = "stop_loss"
+				}
+				lc.finish(action.Timestamp, reason)
+				delete(open, key)
+			}
+		}
+	}
+
+	for _, lc := range open {
+		lc.accrueFunding(time.Now())
+		lc.Duration = time.Since(lc.OpenTime).Round(time.Minute).String()
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].OpenTime.After(result[j].OpenTime) })
+	return result
+}
+
+// SummarizeRealized 汇总since之后发生的成交及资金费（since为零值时汇总全部）
+func SummarizeRealized(lifecycles []*PositionLifecycle, since time.Time) RealizedBreakdown {
+	return SummarizeRealizedBetween(lifecycles, since, time.Time{})
+}
+
+// SummarizeRealizedBetween 汇总 [since, until) 内发生的成交及资金费（until为零值时不限截止时间）
+func SummarizeRealizedBetween(lifecycles []*PositionLifecycle, since, until time.Time) RealizedBreakdown {
+	var b RealizedBreakdown
+	for _, lc := range lifecycles {
+		for _, e := range lc.Events {
+			if e.Time.Before(since) || (!until.IsZero() && !e.Time.Before(until)) {
+				continue
+			}
+			switch e.Type {
+			case PositionEntry, PositionAdd:
+				b.Entries++
+			case PositionClose:
+				b.Trades++
+			case PositionFunding:
+				b.FundingPnL += e.PnL
+				continue
+			}
+			if e.Type == PositionPartialClose || e.Type == PositionClose {
+				b.PricePnL += e.PnL
+			}
+			b.Volume += e.Price * e.Quantity
+			b.Fees += e.Fee
+		}
+	}
+	b.NetPnL = b.PricePnL + b.FundingPnL - b.Fees
+	return b
+}
+
+// ReplayPosition 按ID重建单个持仓的生命周期
+func (l *DecisionLogger) ReplayPosition(id string, lookback int) (*PositionLifecycle, error) {
+	lifecycles, err := l.ReplayPositions(lookback)
+	if err != nil {
+		return nil, err
+	}
+	for _, lc := range lifecycles {
+		if lc.ID == id {
+			return lc, nil
+		}
+	}
+	return nil, fmt.Errorf("持仓 %s 不存在或已超出日志范围", id)
+}
+
+func newPositionLifecycle(symbol, side string, openTime time.Time) *PositionLifecycle {
+	return &PositionLifecycle{
+		ID:               fmt.Sprintf("%s_%s_%d", symbol, side, openTime.UnixMilli()),
+		Symbol:           symbol,
+		Side:             side,
+		Status:           "open",
+		OpenTime:         openTime,
+		Events:           []PositionEvent{},
+		fundingAccruedTo: openTime,
+	}
+}
+
+// direction 多仓为1，空仓为-1
+func (lc *PositionLifecycle) direction() float64 {
+	if lc.Side == "short" {
+		return -1
+	}
+	return 1
+}
+
+// addEntry 记录开仓/加仓并更新平均开仓价
+func (lc *PositionLifecycle) addEntry(t time.Time, eventType string, price, quantity float64, orderID int64, note string) {
+	if quantity > 0 && price > 0 {
+		lc.EntryPrice = (lc.EntryPrice*lc.Quantity + price*quantity) / (lc.Quantity + quantity)
+	}
+	lc.Quantity += quantity
+	lc.MaxQuantity = math.Max(lc.MaxQuantity, lc.Quantity)
+	fee := price * quantity * lc.feeRate
+	lc.Fees += fee
+	lc.RealizedPnL -= fee
+	lc.Events = append(lc.Events, PositionEvent{Time: t, Type: eventType, Price: price, Quantity: quantity, OrderID: orderID, Fee: fee, Note: note})
+}
+
+// accrueFunding 按记录的费率计算截至until的各结算时刻资金费（多仓在正费率时支付）
+func (lc *PositionLifecycle) accrueFunding(until time.Time) {
+	for settle := lc.fundingAccruedTo.Truncate(fundingInterval).Add(fundingInterval); !settle.After(until); settle = settle.Add(fundingInterval) {
+		if lc.fundingRate == 0 || lc.Quantity <= 0 || lc.MarkPrice <= 0 {
+			continue
+		}
+		funding := -lc.direction() * lc.Quantity * lc.MarkPrice * lc.fundingRate
+		lc.FundingPnL += funding
+		lc.RealizedPnL += funding
+		lc.Events = append(lc.Events, PositionEvent{
+			Time:  settle,
+			Type:  PositionFunding,
+			Price: lc.MarkPrice,
+			PnL:   funding,
+			Note:  fmt.Sprintf("资金费率 %.4f%%（按记录的费率估算）", lc.fundingRate*100),
+		})
+	}
+	if until.After(lc.fundingAccruedTo) {
+		lc.fundingAccruedTo = until
+	}
+}
+
+// observe 与周期持仓快照对比，推断部分平仓、外部加仓及止盈止损调整
+func (lc *PositionLifecycle) observe(t time.Time, pos PositionSnapshot) {
+	lc.accrueFunding(t)
+	if pos.FundingRate != 0 {
+		lc.fundingRate = pos.FundingRate
+	}
+	quantity := math.Abs(pos.PositionAmt)
+	switch {
+	case quantity < lc.Quantity*(1-quantityTolerance):
+		lc.reduce(t, PositionPartialClose, pos.MarkPrice, lc.Quantity-quantity, 0, "持仓数量减少（按标记价格估算）")
+	case quantity > lc.Quantity*(1+quantityTolerance):
+		lc.addEntry(t, PositionAdd, pos.MarkPrice, quantity-lc.Quantity, 0, "持仓数量增加（按标记价格估算）")
+	}
+	if pos.EntryPrice > 0 {
+		lc.EntryPrice = pos.EntryPrice // 以交易所计算的开仓均价为准
+	}
+	if pos.Leverage > 0 {
+		lc.Leverage = int(pos.Leverage)
+	}
+	lc.MarkPrice = pos.MarkPrice
+	lc.updateLevels(t, pos.StopLoss, pos.TakeProfit)
+}
+
+// updateLevels 记录止盈止损的设置及调整
+func (lc *PositionLifecycle) updateLevels(t time.Time, stopLoss, takeProfit float64) {
+	if stopLoss > 0 && stopLoss != lc.StopLoss {
+		note := "设置止损"
+		if lc.StopLoss > 0 {
+			note = fmt.Sprintf("止损 %.4f → %.4f", lc.StopLoss, stopLoss)
+		}
+		lc.Events = append(lc.Events, PositionEvent{Time: t, Type: PositionStopLoss, Price: stopLoss, Note: note})
+		lc.StopLoss = stopLoss
+	}
+	if takeProfit > 0 && takeProfit != lc.TakeProfit {
+		note := "设置止盈"
+		if lc.TakeProfit > 0 {
+			note = fmt.Sprintf("止盈 %.4f → %.4f", lc.TakeProfit, takeProfit)
+		}
+		lc.Events = append(lc.Events, PositionEvent{Time: t, Type: PositionTakeProfit, Price: takeProfit, Note: note})
+		lc.TakeProfit = takeProfit
+	}
+}
+
+// reduce 记录减仓并累计已实现盈亏
+func (lc *PositionLifecycle) reduce(t time.Time, eventType string, price, quantity float64, orderID int64, note string) {
+	if quantity <= 0 {
+		return
+	}
+	if price <= 0 {
+		price = lc.MarkPrice
+	}
+	pnl := (price - lc.EntryPrice) * quantity * lc.direction()
+	fee := price * quantity * lc.feeRate
+	lc.PricePnL += pnl
+	lc.Fees += fee
+	lc.RealizedPnL += pnl - fee
+	lc.exitValue += price * quantity
+	lc.exitQuantity += quantity
+	lc.Quantity = math.Max(lc.Quantity-quantity, 0)
+	lc.Events = append(lc.Events, PositionEvent{Time: t, Type: eventType, Price: price, Quantity: quantity, OrderID: orderID, PnL: pnl, Fee: fee, Note: note})
+}
+
+// closeByExchange 持仓在两个周期之间消失（止盈止损触发或外部平仓），按最接近最后标记价格的止盈止损价估算成交
+func (lc *PositionLifecycle) closeByExchange(t time.Time) {
+	price, reason := lc.MarkPrice, "external"
+	note := "持仓消失（外部平仓，按最后标记价格估算）"
+	if lc.StopLoss > 0 && (lc.TakeProfit <= 0 || math.Abs(lc.MarkPrice-lc.StopLoss) <= math.Abs(lc.MarkPrice-lc.TakeProfit)) {
+		price, reason, note = lc.StopLoss, "stop_loss", "止损触发（按止损价估算）"
+	} else if lc.TakeProfit > 0 {
+		price, reason, note = lc.TakeProfit, "take_profit", "止盈触发（按止盈价估算）"
+	}
+	lc.reduce(t, PositionClose, price, lc.Quantity, 0, note)
+	lc.finish(t, reason)
+}
+
+// finish 结束持仓并计算汇总指标
+func (lc *PositionLifecycle) finish(t time.Time, reason string) {
+	lc.Status = "closed"
+	lc.CloseTime = t
+	lc.CloseReason = reason
+	lc.Duration = t.Sub(lc.OpenTime).Round(time.Minute).String()
+	if lc.exitQuantity > 0 {
+		lc.ExitPrice = lc.exitValue / lc.exitQuantity
+	}
+	if margin := lc.EntryPrice * lc.MaxQuantity / math.Max(float64(lc.Leverage), 1); margin > 0 {
+		lc.PnLPct = lc.RealizedPnL / margin * 100
+	}
+}
+
+// decisionLevels 从决策JSON中查找对应开仓决策的止盈止损
+func decisionLevels(record *DecisionRecord, symbol, action string) (float64, float64) {
+	var decisions []replayLevels
+	if err := json.Unmarshal([]byte(record.DecisionJSON), &decisions); err != nil {
+		return 0, 0
+	}
+	for _, d := range decisions {
+		if d.Symbol == symbol && d.Action == action {
+			return d.StopLoss, d.TakeProfit
+		}
+	}
+	return 0, 0
+}
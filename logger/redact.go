@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 已知的凭证字段（key=value / key: value / "key":"value" 形式），可带交易所等前缀，如 binance_api_key、hyperliquid_private_key
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)(\b(?:[a-z]+_)?(?:api_?key|secret_?key|private_?key|api_?secret|passphrase|password|jwt_secret|webhook_secret|bot_token|routing_key|signature|x-mbx-apikey)["']?\s*[:=]\s*["']?)([^\s"',&}\]]+)`)
+
+// 已知格式的密钥：AI接口的 sk- 密钥、PEM私钥（交易所私钥等按注册的值屏蔽，不按64位十六进制匹配，以免误伤交易哈希）
+var keyFormatPattern = regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}|-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// minSecretLength 过短的字符串不作为密钥注册，避免误伤普通日志
+const minSecretLength = 8
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+
+	debugMu      sync.RWMutex
+	debugEnabled bool
+)
+
+// RegisterSecret 注册需要在日志中屏蔽的密钥（API Key、Secret、私钥等）
+func RegisterSecret(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLength {
+			continue
+		}
+		exists := false
+		for _, s := range secrets {
+			if s == v {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			secrets = append(secrets, v)
+		}
+	}
+	// 长的优先替换，避免前缀相同的密钥只被部分屏蔽
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// MaskSecret 屏蔽密钥，仅保留前4位用于辨认
+func MaskSecret(s string) string {
+	if len(s) <= minSecretLength {
+		return "****"
+	}
+	return s[:4] + "****"
+}
+
+// Redact 屏蔽文本中已注册的密钥以及疑似凭证的字段
+func Redact(text string) string {
+	secretsMu.RLock()
+	for _, s := range secrets {
+		if strings.Contains(text, s) {
+			text = strings.ReplaceAll(text, s, MaskSecret(s))
+		}
+	}
+	secretsMu.RUnlock()
+
+	text = sensitiveFieldPattern.ReplaceAllString(text, "${1}****")
+	text = keyFormatPattern.ReplaceAllStringFunc(text, MaskSecret)
+	return text
+}
+
+// redactWriter 写入前屏蔽敏感信息
+type redactWriter struct {
+	w io.Writer
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// NewRedactWriter 创建屏蔽敏感信息的输出（用于 log.SetOutput）
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+// SetDebug 设置是否输出调试日志
+func SetDebug(enabled bool) {
+	debugMu.Lock()
+	debugEnabled = enabled
+	debugMu.Unlock()
+}
+
+// IsDebug 是否开启调试日志
+func IsDebug() bool {
+	debugMu.RLock()
+	defer debugMu.RUnlock()
+	return debugEnabled
+}
+
+// Debugf 仅在调试模式下输出日志（同样经过敏感信息屏蔽）
+func Debugf(format string, args ...interface{}) {
+	if IsDebug() {
+		log.Printf("🐞 "+format, args...)
+	}
+}
@@ -0,0 +1,37 @@
+package logger
+
+import "time"
+
+// maxReasoningLen 交易上下文中保留的AI理由长度（字符）
+const maxReasoningLen = 500
+
+// TradeContext 开平仓时的策略上下文（指标数值、AI理由摘要），用于复盘时查看交易原因
+type TradeContext struct {
+	Source     string             `json:"source"`               // AI决策 / 外部信号 / 定投 等
+	Reasoning  string             `json:"reasoning,omitempty"`  // AI理由摘要
+	Confidence int                `json:"confidence,omitempty"` // AI信心度
+	Indicators map[string]float64 `json:"indicators,omitempty"` // 决策时的指标数值（价格、EMA、MACD、RSI、ATR、资金费率等）
+}
+
+// TradeSourceStopLoss 交易所止损单成交平仓的交易来源
+const TradeSourceStopLoss = "止损触发"
+
+// NewTradeContext 创建交易上下文（理由过长时截断）
+func NewTradeContext(source, reasoning string, confidence int, indicators map[string]float64) *TradeContext {
+	runes := []rune(reasoning)
+	if len(runes) > maxReasoningLen {
+		reasoning = string(runes[:maxReasoningLen]) + "…"
+	}
+	return &TradeContext{Source: source, Reasoning: reasoning, Confidence: confidence, Indicators: indicators}
+}
+
+// TradeNote 复盘笔记：运维人员附加到历史持仓上的文字、截图链接及自定义上下文
+type TradeNote struct {
+	ID         int64     `json:"id"`
+	PositionID string    `json:"position_id"`
+	Author     string    `json:"author,omitempty"`
+	Note       string    `json:"note"`
+	ImageURL   string    `json:"image_url,omitempty"`  // 截图链接
+	Attachment string    `json:"attachment,omitempty"` // 自定义上下文（JSON文本）
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -1,20 +1,46 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"nofx/api"
 	"nofx/auth"
+	"nofx/basis"
+	"nofx/calendar"
 	"nofx/config"
+	"nofx/depeg"
+	"nofx/export"
+	"nofx/features"
+	"nofx/fx"
+	"nofx/hedge"
+	"nofx/i18n"
+	"nofx/jobs"
+	"nofx/listings"
+	"nofx/logger"
+	"nofx/maintenance"
 	"nofx/manager"
 	"nofx/market"
+	"nofx/marketmaker"
+	"nofx/notify"
 	"nofx/pool"
+	"nofx/recurring"
+	"nofx/rpc"
+	"nofx/signals"
+	"nofx/telegram"
+	"nofx/tracing"
+	"nofx/trader"
+	"nofx/universe"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // LeverageConfig 杠杆配置
@@ -27,6 +53,8 @@ type LeverageConfig struct {
 type ConfigFile struct {
 	AdminMode          bool           `json:"admin_mode"`
 	APIServerPort      int            `json:"api_server_port"`
+	GRPCPort           int            `json:"grpc_port"`
+	InstanceID         string         `json:"instance_id"` // 敞口快照中的实例标识（为空时使用主机名）
 	UseDefaultCoins    bool           `json:"use_default_coins"`
 	DefaultCoins       []string       `json:"default_coins"`
 	CoinPoolAPIURL     string         `json:"coin_pool_api_url"`
@@ -38,42 +66,380 @@ type ConfigFile struct {
 	Leverage           LeverageConfig `json:"leverage"`
 	JWTSecret          string         `json:"jwt_secret"`
 	DataKLineTime      string         `json:"data_k_line_time"`
-}
 
-// syncConfigToDatabase 从config.json读取配置并同步到数据库
-func syncConfigToDatabase(database *config.Database) error {
-	// 检查config.json是否存在
-	if _, err := os.Stat("config.json"); os.IsNotExist(err) {
-		log.Printf("📄 config.json不存在，跳过同步")
-		return nil
-	}
+	// 控制台/API访问控制
+	AdminPassword string `json:"admin_password"` // 管理员模式的登录密码（PIN，为空时管理员模式无需登录）
+	APITLSCert    string `json:"api_tls_cert"`   // HTTPS证书文件（与api_tls_key均设置时启用HTTPS）
+	APITLSKey     string `json:"api_tls_key"`    // HTTPS私钥文件
+
+	APITokens []auth.APIToken `json:"api_tokens"` // 静态API Token（read=只读, trade=可下单/启停, admin=可改配置）
+
+	MarginTopUpThreshold float64 `json:"margin_topup_threshold"`
+	MarginTopUpAmount    float64 `json:"margin_topup_amount"`
+	DebugLog             bool    `json:"debug_log"`
+	Locale               string  `json:"locale"` // 日志及错误信息语言: zh / en
+
+	// 禁止开仓窗口（经济日历/静态窗口）
+	BlackoutWindows           []calendar.Window `json:"blackout_windows"`
+	EconomicCalendarURL       string            `json:"economic_calendar_url"`
+	EconomicCalendarCountries []string          `json:"economic_calendar_countries"`
+	BlackoutPaddingMinutes    int               `json:"blackout_padding_minutes"`
+	BlackoutReducePct         float64           `json:"blackout_reduce_pct"`
+
+	// 相关性分组敞口限制
+	CorrelationGroups   map[string][]string `json:"correlation_groups"`
+	MaxGroupExposurePct float64             `json:"max_group_exposure_pct"`
+
+	RiskReportIntervalMinutes *int `json:"risk_report_interval_minutes"` // 风险报告间隔（未设置时保留数据库中的值）
+
+	ReplayLog bool `json:"replay_log"` // 记录回放日志（每个决策周期的行情输入及AI响应）
+
+	FaultInjection string `json:"fault_injection"` // 故障注入（混沌测试，如 delay=200ms-2s,error=0.05,drop=0.02，空=关闭）
+
+	MarketOrderGuard string `json:"market_order_guard"` // 市价开仓前的行情保护（如 deviation_bps=50,spread_bps=20,action=limit，空=关闭）
+	MaxSlippageTicks *int   `json:"max_slippage_ticks"` // 市价单最大滑点（价格步进数，0=普通市价单；未设置时保留数据库中的值）
+	RequestBudget    string `json:"request_budget"`     // 交易所请求频率预算（如 rate=10,burst=20,reserve=5，空=不限制）
+
+	AIMaxPositionUSD float64 `json:"ai_max_position_usd"` // AI单笔开仓名义价值硬上限（0=只按净值倍数限制）
+
+	// API Key预检
+	APIKeyPreflight      string `json:"api_key_preflight"`      // strict / warn / off（未设置时保留数据库中的值）
+	RefuseWithdrawalKeys *bool  `json:"refuse_withdrawal_keys"` // API Key开启提现权限时拒绝运行（未设置时保留数据库中的值）
+
+	// 两人规则
+	TwoPersonThresholdUSD float64 `json:"two_person_threshold_usd"` // 人工订单名义价值达到该值时需另一位运维人员确认（0=关闭）
+
+	// AI调用成本预算
+	LLMDailyBudgetUSD float64 `json:"llm_daily_budget_usd"` // 每个交易员每日AI调用成本上限（USD，0=不限制）
+	LLMPrices         string  `json:"llm_prices"`           // 模型单价覆盖，如 "deepseek-chat=0.27/1.10,*=1/3"（USD每百万token）
+
+	FeatureProviders []features.Config `json:"feature_providers"` // 写入AI prompt的市场特征源（资金费率/持仓量/恐慌贪婪指数/新闻）
+
+	// 外部信号接入（Redis频道/MQTT主题）
+	SignalIngestURL   string `json:"signal_ingest_url"`
+	SignalIngestTopic string `json:"signal_ingest_topic"`
+
+	// 执行事件导出（Kafka/NATS）
+	EventExportURL   string `json:"event_export_url"`
+	EventExportTopic string `json:"event_export_topic"`
+
+	JournalWebhookURL string `json:"journal_webhook_url"` // 已平仓交易推送到第三方交易日志的webhook（空=关闭）
+
+	JobWorkers *int `json:"job_workers"` // 后台任务并发worker数（0=关闭；未设置时保留数据库中的值）
+
+	RecurringSchedulerEnabled *bool `json:"recurring_scheduler_enabled"` // 定投调度器开关（未设置时保留数据库中的值）
+	LeaderboardSummaryHour    *int  `json:"leaderboard_summary_hour"`    // 每日排行榜通知时刻（交易日时区的小时，-1=关闭；未设置时保留数据库中的值）
+	ExecutionReportHour       *int  `json:"execution_report_hour"`       // 每日执行质量报告时刻（交易日时区的小时，-1=关闭；未设置时保留数据库中的值）
+	HeartbeatIntervalMinutes  *int  `json:"heartbeat_interval_minutes"`  // 存活通知间隔（分钟，0=关闭；未设置时保留数据库中的值）
+
+	PerformanceDigest string `json:"performance_digest"` // 业绩日报/周报计划（如 daily,weekly,hour=8,weekday=mon，空=关闭）
+	DashboardURL      string `json:"dashboard_url"`      // 仪表盘访问地址（通知中的链接）
+
+	// 稳定币脱锚保护
+	DepegCoins       []string `json:"depeg_coins"`
+	DepegAlertPct    float64  `json:"depeg_alert_pct"`     // 偏离超过该百分比时告警并暂停开仓（0=关闭）
+	DepegReduceAtPct float64  `json:"depeg_reduce_at_pct"` // 偏离超过该百分比时减仓（0=不减仓）
+	DepegReducePct   float64  `json:"depeg_reduce_pct"`    // 减仓比例
+
+	// 交易所维护窗口（静态窗口/公告源/系统状态）
+	MaintenanceWindows       []maintenance.Window `json:"maintenance_windows"`
+	MaintenanceFeedURL       string               `json:"maintenance_feed_url"`
+	MaintenanceBinanceStatus *bool                `json:"maintenance_binance_status"`  // 轮询币安系统状态（未设置时保留数据库中的值）
+	MaintenanceLeadMinutes   *int                 `json:"maintenance_lead_minutes"`    // 维护开始前提前暂停开仓的分钟数
+	MaintenanceHedgeTraderID string               `json:"maintenance_hedge_trader_id"` // 维护期间在该交易员账户对冲（为空不对冲）
+	MaintenanceHedgeLeverage int                  `json:"maintenance_hedge_leverage"`
+
+	// 合约下架/交割处理
+	DelistingCloseHours      *int   `json:"delisting_close_hours"`       // 计划下架前多少小时强制平仓（-1=只告警；未设置时保留数据库中的值）
+	DelistingMigrateTraderID string `json:"delisting_migrate_trader_id"` // 平仓后迁移到该交易员账户（为空不迁移）
+	DelistingMigrateLeverage int    `json:"delisting_migrate_leverage"`
+
+	// 资金费率历史
+	FundingHistorySymbols []string `json:"funding_history_symbols"` // 额外记录的交易对（期现套利的交易对自动记录）
+
+	// 新上线合约扫描
+	NewListingExchanges      []string `json:"new_listing_exchanges"` // 扫描的交易所（为空不扫描）
+	NewListingScanMinutes    int      `json:"new_listing_scan_minutes"`
+	NewListingAutoAddTraders []string `json:"new_listing_auto_add_traders"` // 自动加入候选池的交易员（为空只通知）
+	NewListingMinAgeHours    *int     `json:"new_listing_min_age_hours"`
+	NewListingMaxPerTrader   int      `json:"new_listing_max_per_trader"`
+	NewListingMaxLeverage    int      `json:"new_listing_max_leverage"`
+	NewListingMaxPositionUSD *float64 `json:"new_listing_max_position_usd"`
+
+	// 报告币种（净值、盈亏及通知以该币种显示）
+	QuoteCurrency string `json:"quote_currency"`
+	FXRateURL     string `json:"fx_rate_url"`
+	FXStaticRates string `json:"fx_static_rates"` // 固定汇率，如 "EUR=0.92,CNY=7.2"
 
+	DeltaHedges     []hedge.Config       `json:"delta_hedges"`     // 期权持仓的Delta对冲
+	BasisStrategies []basis.Config       `json:"basis_strategies"` // 期现套利
+	MarketMakers    []marketmaker.Config `json:"market_makers"`    // 做市
+	Universes       []universe.Config    `json:"universes"`        // 按成交额/波动率/价差动态选币
+
+	// Telegram运维机器人（紧急控制指令与事件推送）
+	TelegramBotToken      string            `json:"telegram_bot_token"`
+	TelegramOperatorIDs   []int64           `json:"telegram_operator_ids"`
+	TelegramOperatorUsers map[string]string `json:"telegram_operator_users"` // Telegram用户ID -> 关联的用户ID（两人规则）
+
+	// 邮件通知（SMTP）
+	SMTPURL    string   `json:"smtp_url"`    // smtps://用户名:密码@主机:465 或 smtp://用户名:密码@主机:587（STARTTLS）
+	SMTPFrom   string   `json:"smtp_from"`   // 发件人（为空时使用SMTP用户名）
+	SMTPTo     []string `json:"smtp_to"`     // 收件人
+	SMTPEvents []string `json:"smtp_events"` // 邮件通知的事件类型（为空时与Telegram相同）
+
+	// 紧急告警（强平风险、紧急停止、连续API认证失败）呼叫值班人员
+	LiquidationAlertPct *float64 `json:"liquidation_alert_pct"` // 距强平价格低于该百分比时告警（0=关闭；未设置时保留数据库中的值）
+	PagerDutyRoutingKey string   `json:"pagerduty_routing_key"`
+	OpsgenieAPIKey      string   `json:"opsgenie_api_key"`
+	OpsgenieAPIURL      string   `json:"opsgenie_api_url"` // EU账户为 https://api.eu.opsgenie.com
+
+	// 事件webhook（每个执行事件POST JSON，供下游自动化使用）
+	WebhookURL    string `json:"webhook_url"`
+	WebhookSecret string `json:"webhook_secret"` // HMAC-SHA256签名密钥（为空则不签名）
+
+	// OpenTelemetry交易链路追踪（决策 → 风控 → 执行 → 交易所API）
+	OtelEndpoint    string   `json:"otel_endpoint"`     // OTLP/HTTP地址（为空则不追踪）
+	OtelSampleRatio *float64 `json:"otel_sample_ratio"` // 采样比例（未设置时保留数据库中的值）
+
+	// 影子账本（本地维护持仓及挂单，交易所推送实时更新并定期REST对账）
+	ShadowBookReconcile string `json:"shadow_book_reconcile"` // REST对账间隔（如 30s，空=关闭）
+	PanicActions        string `json:"panic_actions"`         // 紧急按钮动作（逗号分隔，空=全部）
+	DayResetTimezone    string `json:"day_reset_timezone"`    // 交易日切换时区（如 Asia/Shanghai、+08:00，空=UTC）
+
+	// 每日余额对账（余额变动 vs 记录的交易 + 资金费 + 手续费）
+	BalanceReconcileTolerance string `json:"balance_reconcile_tolerance"` // 容差（如 5、0.5%，空=关闭）
+}
+
+// loadConfigFile 读取config.json并转换为系统配置项
+func loadConfigFile(path string) (map[string]string, error) {
 	// 读取config.json
-	data, err := os.ReadFile("config.json")
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("读取config.json失败: %w", err)
+		return nil, fmt.Errorf("读取config.json失败: %w", err)
 	}
 
 	// 解析JSON
 	var configFile ConfigFile
 	if err := json.Unmarshal(data, &configFile); err != nil {
-		return fmt.Errorf("解析config.json失败: %w", err)
+		return nil, fmt.Errorf("解析config.json失败: %w", err)
 	}
 
 	log.Printf("🔄 开始同步config.json到数据库...")
 
 	// 同步各配置项到数据库
 	configs := map[string]string{
-		"admin_mode":           fmt.Sprintf("%t", configFile.AdminMode),
-		"api_server_port":      strconv.Itoa(configFile.APIServerPort),
-		"use_default_coins":    fmt.Sprintf("%t", configFile.UseDefaultCoins),
-		"coin_pool_api_url":    configFile.CoinPoolAPIURL,
-		"oi_top_api_url":       configFile.OITopAPIURL,
-		"inside_coins":         fmt.Sprintf("%t", configFile.InsideCoins),
-		"max_daily_loss":       fmt.Sprintf("%.1f", configFile.MaxDailyLoss),
-		"max_drawdown":         fmt.Sprintf("%.1f", configFile.MaxDrawdown),
-		"stop_trading_minutes": strconv.Itoa(configFile.StopTradingMinutes),
+		"admin_mode":             fmt.Sprintf("%t", configFile.AdminMode),
+		"api_server_port":        strconv.Itoa(configFile.APIServerPort),
+		"grpc_port":              strconv.Itoa(configFile.GRPCPort),
+		"instance_id":            configFile.InstanceID,
+		"use_default_coins":      fmt.Sprintf("%t", configFile.UseDefaultCoins),
+		"coin_pool_api_url":      configFile.CoinPoolAPIURL,
+		"oi_top_api_url":         configFile.OITopAPIURL,
+		"inside_coins":           fmt.Sprintf("%t", configFile.InsideCoins),
+		"max_daily_loss":         fmt.Sprintf("%.1f", configFile.MaxDailyLoss),
+		"max_drawdown":           fmt.Sprintf("%.1f", configFile.MaxDrawdown),
+		"stop_trading_minutes":   strconv.Itoa(configFile.StopTradingMinutes),
+		"margin_topup_threshold": fmt.Sprintf("%.1f", configFile.MarginTopUpThreshold),
+		"margin_topup_amount":    fmt.Sprintf("%.2f", configFile.MarginTopUpAmount),
+		"debug_log":              fmt.Sprintf("%t", configFile.DebugLog),
+	}
+	if configFile.Locale != "" {
+		configs["locale"] = configFile.Locale
+	}
+
+	// 同步禁止开仓窗口配置
+	if configFile.BlackoutWindows != nil {
+		if windowsJSON, err := json.Marshal(configFile.BlackoutWindows); err == nil {
+			configs["blackout_windows"] = string(windowsJSON)
+		}
+	}
+	configs["economic_calendar_url"] = configFile.EconomicCalendarURL
+	if len(configFile.EconomicCalendarCountries) > 0 {
+		configs["economic_calendar_countries"] = strings.Join(configFile.EconomicCalendarCountries, ",")
 	}
+	if configFile.BlackoutPaddingMinutes > 0 {
+		configs["blackout_padding_minutes"] = strconv.Itoa(configFile.BlackoutPaddingMinutes)
+	}
+	configs["blackout_reduce_pct"] = fmt.Sprintf("%.1f", configFile.BlackoutReducePct)
+
+	// 同步相关性分组配置
+	if configFile.CorrelationGroups != nil {
+		if groupsJSON, err := json.Marshal(configFile.CorrelationGroups); err == nil {
+			configs["correlation_groups"] = string(groupsJSON)
+		}
+	}
+	configs["max_group_exposure_pct"] = fmt.Sprintf("%.1f", configFile.MaxGroupExposurePct)
+	configs["ai_max_position_usd"] = fmt.Sprintf("%.2f", configFile.AIMaxPositionUSD)
+	if configFile.APIKeyPreflight != "" {
+		configs["api_key_preflight"] = configFile.APIKeyPreflight
+	}
+	if configFile.RefuseWithdrawalKeys != nil {
+		configs["refuse_withdrawal_keys"] = strconv.FormatBool(*configFile.RefuseWithdrawalKeys)
+	}
+	configs["two_person_threshold_usd"] = fmt.Sprintf("%.2f", configFile.TwoPersonThresholdUSD)
+	configs["replay_log"] = strconv.FormatBool(configFile.ReplayLog)
+	configs["fault_injection"] = configFile.FaultInjection
+	configs["market_order_guard"] = configFile.MarketOrderGuard
+	if configFile.MaxSlippageTicks != nil {
+		configs["max_slippage_ticks"] = strconv.Itoa(*configFile.MaxSlippageTicks)
+	}
+	configs["request_budget"] = configFile.RequestBudget
+	configs["llm_daily_budget_usd"] = fmt.Sprintf("%.2f", configFile.LLMDailyBudgetUSD)
+	configs["llm_prices"] = configFile.LLMPrices
+	if configFile.FeatureProviders != nil {
+		if providersJSON, err := json.Marshal(configFile.FeatureProviders); err == nil {
+			configs["feature_providers"] = string(providersJSON)
+		}
+	}
+	if configFile.RiskReportIntervalMinutes != nil {
+		configs["risk_report_interval_minutes"] = strconv.Itoa(*configFile.RiskReportIntervalMinutes)
+	}
+	configs["signal_ingest_url"] = configFile.SignalIngestURL
+	if configFile.SignalIngestTopic != "" {
+		configs["signal_ingest_topic"] = configFile.SignalIngestTopic
+	}
+	configs["event_export_url"] = configFile.EventExportURL
+	if configFile.EventExportTopic != "" {
+		configs["event_export_topic"] = configFile.EventExportTopic
+	}
+	configs["journal_webhook_url"] = configFile.JournalWebhookURL
+	if configFile.JobWorkers != nil {
+		configs["job_workers"] = strconv.Itoa(*configFile.JobWorkers)
+	}
+	if configFile.RecurringSchedulerEnabled != nil {
+		configs["recurring_scheduler_enabled"] = strconv.FormatBool(*configFile.RecurringSchedulerEnabled)
+	}
+	if configFile.LeaderboardSummaryHour != nil {
+		configs["leaderboard_summary_hour"] = strconv.Itoa(*configFile.LeaderboardSummaryHour)
+	}
+	if configFile.ExecutionReportHour != nil {
+		configs["execution_report_hour"] = strconv.Itoa(*configFile.ExecutionReportHour)
+	}
+	if configFile.HeartbeatIntervalMinutes != nil {
+		configs["heartbeat_interval_minutes"] = strconv.Itoa(*configFile.HeartbeatIntervalMinutes)
+	}
+	configs["performance_digest"] = configFile.PerformanceDigest
+	configs["dashboard_url"] = configFile.DashboardURL
+	if len(configFile.DepegCoins) > 0 {
+		configs["depeg_coins"] = strings.Join(configFile.DepegCoins, ",")
+	}
+	configs["depeg_alert_pct"] = fmt.Sprintf("%.2f", configFile.DepegAlertPct)
+	configs["depeg_reduce_at_pct"] = fmt.Sprintf("%.2f", configFile.DepegReduceAtPct)
+	configs["depeg_reduce_pct"] = fmt.Sprintf("%.1f", configFile.DepegReducePct)
+	if configFile.MaintenanceWindows != nil {
+		if windowsJSON, err := json.Marshal(configFile.MaintenanceWindows); err == nil {
+			configs["maintenance_windows"] = string(windowsJSON)
+		}
+	}
+	configs["maintenance_feed_url"] = configFile.MaintenanceFeedURL
+	if configFile.MaintenanceBinanceStatus != nil {
+		configs["maintenance_binance_status"] = strconv.FormatBool(*configFile.MaintenanceBinanceStatus)
+	}
+	if configFile.MaintenanceLeadMinutes != nil {
+		configs["maintenance_lead_minutes"] = strconv.Itoa(*configFile.MaintenanceLeadMinutes)
+	}
+	configs["maintenance_hedge_trader_id"] = configFile.MaintenanceHedgeTraderID
+	if configFile.MaintenanceHedgeLeverage > 0 {
+		configs["maintenance_hedge_leverage"] = strconv.Itoa(configFile.MaintenanceHedgeLeverage)
+	}
+	if configFile.DelistingCloseHours != nil {
+		configs["delisting_close_hours"] = strconv.Itoa(*configFile.DelistingCloseHours)
+	}
+	configs["delisting_migrate_trader_id"] = configFile.DelistingMigrateTraderID
+	if configFile.DelistingMigrateLeverage > 0 {
+		configs["delisting_migrate_leverage"] = strconv.Itoa(configFile.DelistingMigrateLeverage)
+	}
+	configs["funding_history_symbols"] = strings.Join(configFile.FundingHistorySymbols, ",")
+	configs["new_listing_exchanges"] = strings.Join(configFile.NewListingExchanges, ",")
+	if configFile.NewListingScanMinutes > 0 {
+		configs["new_listing_scan_minutes"] = strconv.Itoa(configFile.NewListingScanMinutes)
+	}
+	configs["new_listing_auto_add_traders"] = strings.Join(configFile.NewListingAutoAddTraders, ",")
+	if configFile.NewListingMinAgeHours != nil {
+		configs["new_listing_min_age_hours"] = strconv.Itoa(*configFile.NewListingMinAgeHours)
+	}
+	if configFile.NewListingMaxPerTrader > 0 {
+		configs["new_listing_max_per_trader"] = strconv.Itoa(configFile.NewListingMaxPerTrader)
+	}
+	if configFile.NewListingMaxLeverage > 0 {
+		configs["new_listing_max_leverage"] = strconv.Itoa(configFile.NewListingMaxLeverage)
+	}
+	if configFile.NewListingMaxPositionUSD != nil {
+		configs["new_listing_max_position_usd"] = fmt.Sprintf("%.2f", *configFile.NewListingMaxPositionUSD)
+	}
+	if configFile.QuoteCurrency != "" {
+		configs["quote_currency"] = configFile.QuoteCurrency
+	}
+	if configFile.FXRateURL != "" {
+		configs["fx_rate_url"] = configFile.FXRateURL
+	}
+	configs["fx_static_rates"] = configFile.FXStaticRates
+	if configFile.DeltaHedges != nil {
+		if hedgesJSON, err := json.Marshal(configFile.DeltaHedges); err == nil {
+			configs["delta_hedges"] = string(hedgesJSON)
+		}
+	}
+	if configFile.BasisStrategies != nil {
+		if basisJSON, err := json.Marshal(configFile.BasisStrategies); err == nil {
+			configs["basis_strategies"] = string(basisJSON)
+		}
+	}
+	if configFile.MarketMakers != nil {
+		if makersJSON, err := json.Marshal(configFile.MarketMakers); err == nil {
+			configs["market_makers"] = string(makersJSON)
+		}
+	}
+	if configFile.Universes != nil {
+		if universesJSON, err := json.Marshal(configFile.Universes); err == nil {
+			configs["universes"] = string(universesJSON)
+		}
+	}
+	if configFile.TelegramBotToken != "" {
+		configs["telegram_bot_token"] = configFile.TelegramBotToken
+	}
+	if len(configFile.TelegramOperatorIDs) > 0 {
+		ids := make([]string, len(configFile.TelegramOperatorIDs))
+		for i, id := range configFile.TelegramOperatorIDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		configs["telegram_operator_ids"] = strings.Join(ids, ",")
+	}
+	if len(configFile.TelegramOperatorUsers) > 0 {
+		links := make([]string, 0, len(configFile.TelegramOperatorUsers))
+		for id, userID := range configFile.TelegramOperatorUsers {
+			links = append(links, id+"="+userID)
+		}
+		sort.Strings(links)
+		configs["telegram_operator_users"] = strings.Join(links, ",")
+	}
+	if configFile.SMTPURL != "" {
+		configs["smtp_url"] = configFile.SMTPURL
+	}
+	configs["smtp_from"] = configFile.SMTPFrom
+	configs["smtp_to"] = strings.Join(configFile.SMTPTo, ",")
+	configs["smtp_events"] = strings.Join(configFile.SMTPEvents, ",")
+	if configFile.LiquidationAlertPct != nil {
+		configs["liquidation_alert_pct"] = fmt.Sprintf("%.2f", *configFile.LiquidationAlertPct)
+	}
+	if configFile.PagerDutyRoutingKey != "" {
+		configs["pagerduty_routing_key"] = configFile.PagerDutyRoutingKey
+	}
+	if configFile.OpsgenieAPIKey != "" {
+		configs["opsgenie_api_key"] = configFile.OpsgenieAPIKey
+	}
+	configs["opsgenie_api_url"] = configFile.OpsgenieAPIURL
+	configs["webhook_url"] = configFile.WebhookURL
+	if configFile.WebhookSecret != "" {
+		configs["webhook_secret"] = configFile.WebhookSecret
+	}
+	configs["otel_endpoint"] = configFile.OtelEndpoint
+	if configFile.OtelSampleRatio != nil {
+		configs["otel_sample_ratio"] = fmt.Sprintf("%g", *configFile.OtelSampleRatio)
+	}
+	configs["shadow_book_reconcile"] = configFile.ShadowBookReconcile
+	configs["panic_actions"] = configFile.PanicActions
+	configs["day_reset_timezone"] = configFile.DayResetTimezone
+	configs["balance_reconcile_tolerance"] = configFile.BalanceReconcileTolerance
 
 	// 同步default_coins（转换为JSON字符串存储）
 	if len(configFile.DefaultCoins) > 0 {
@@ -95,6 +461,56 @@ func syncConfigToDatabase(database *config.Database) error {
 	if configFile.JWTSecret != "" {
 		configs["jwt_secret"] = configFile.JWTSecret
 	}
+	if configFile.AdminPassword != "" {
+		configs["admin_password"] = configFile.AdminPassword
+	}
+	configs["api_tls_cert"] = configFile.APITLSCert
+	configs["api_tls_key"] = configFile.APITLSKey
+	if configFile.APITokens != nil {
+		if tokensJSON, err := json.Marshal(configFile.APITokens); err == nil {
+			configs["api_tokens"] = string(tokensJSON)
+		}
+	}
+
+	return configs, nil
+}
+
+// syncConfigToDatabase 从config.json及NOFX_*环境变量读取配置并同步到数据库（环境变量优先）
+func syncConfigToDatabase(database config.Store) error {
+	configs := make(map[string]string)
+
+	// 检查config.json是否存在（容器中可以只使用环境变量）
+	if _, err := os.Stat("config.json"); os.IsNotExist(err) {
+		log.Printf("📄 config.json不存在，跳过文件同步")
+	} else {
+		fileConfigs, err := loadConfigFile("config.json")
+		if err != nil {
+			return err
+		}
+		configs = fileConfigs
+	}
+
+	envConfigs := envSystemConfigs()
+	for key, value := range envConfigs {
+		configs[key] = value
+	}
+	if len(envConfigs) > 0 {
+		log.Printf("🔄 从环境变量读取 %d 项配置", len(envConfigs))
+	}
+
+	if len(configs) == 0 {
+		return nil
+	}
+
+	// 管理员密码只保存bcrypt哈希
+	if password := configs["admin_password"]; password != "" && !auth.IsPasswordHash(password) {
+		logger.RegisterSecret(password)
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return fmt.Errorf("哈希管理员密码失败: %w", err)
+		}
+		configs["admin_password"] = hash
+	}
 
 	// 更新数据库配置
 	for key, value := range configs {
@@ -105,7 +521,7 @@ func syncConfigToDatabase(database *config.Database) error {
 		}
 	}
 
-	log.Printf("✅ config.json同步完成")
+	log.Printf("✅ 配置同步完成")
 	return nil
 }
 
@@ -115,15 +531,62 @@ func main() {
 	fmt.Println("╚════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
-	// 初始化数据库配置
-	dbPath := "config.db"
+	// 作为系统服务运行：nofx service install|uninstall
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		os.Exit(runServiceCommand(os.Args[2:]))
+	}
+
+	// 离线重放回放日志：nofx replay
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplayCommand(os.Args[2:]))
+	}
+
+	// 检查测试网连通性及测试资金：nofx testnet
+	if len(os.Args) > 1 && os.Args[1] == "testnet" {
+		os.Exit(runTestnetCommand(os.Args[2:]))
+	}
+
+	// 批量平仓：nofx close-all
+	if len(os.Args) > 1 && os.Args[1] == "close-all" {
+		os.Exit(runCloseAllCommand(os.Args[2:]))
+	}
+
+	// 作为Windows服务运行时先向服务控制管理器报告已启动（初始化超过30秒会被判定启动失败），停止请求触发退出流程
+	sigChan := make(chan os.Signal, 1)
+	watchServiceControl(sigChan)
+
+	// 日志按配置语言翻译，并在输出前屏蔽API密钥、签名、私钥等敏感信息
+	// 设置 NOFX_LOG_FILE 时同时写入按大小滚动的日志文件（系统服务默认开启）
+	var logOutput io.Writer = os.Stderr
+	if logFile := os.Getenv("NOFX_LOG_FILE"); logFile != "" {
+		maxMB, _ := strconv.Atoi(envOrDefault("NOFX_LOG_MAX_MB", "50"))
+		backups, _ := strconv.Atoi(envOrDefault("NOFX_LOG_BACKUPS", "5"))
+		if rotating, err := logger.NewRotatingFile(logFile, int64(maxMB)<<20, backups); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v，日志只输出到标准错误\n", err)
+		} else {
+			defer rotating.Close()
+			logOutput = io.MultiWriter(os.Stderr, rotating)
+		}
+	}
+	log.SetOutput(i18n.NewWriter(logger.NewRedactWriter(logOutput)))
+
+	// 数据目录（容器中建议挂载为数据卷，默认当前目录）
+	dataDir := envOrDefault("NOFX_DATA_DIR", ".")
+	logger.SetLogRoot(prepareWritableDir(filepath.Join(dataDir, "decision_logs")))
+	pool.SetCacheDir(prepareWritableDir(filepath.Join(dataDir, "coin_pool_cache")))
+
+	// 初始化数据库配置（命令行参数 > NOFX_DATABASE_URL > NOFX_DB_PATH > 数据目录下的config.db）
+	dbPath := defaultDBPath(dataDir)
 	if len(os.Args) > 1 {
 		dbPath = os.Args[1]
 	}
 
-	log.Printf("📋 初始化配置数据库: %s", dbPath)
+	log.Printf("📋 初始化配置数据库: %s", config.RedactDSN(dbPath))
 	database, err := config.NewDatabase(dbPath)
 	if err != nil {
+		if !strings.Contains(dbPath, "://") && !isWritableDir(filepath.Dir(dbPath)) {
+			log.Fatalf("❌ 初始化数据库失败: %v（%s 所在目录不可写，请通过 NOFX_DB_PATH 或 NOFX_DATA_DIR 指向可写的挂载卷）", err, dbPath)
+		}
 		log.Fatalf("❌ 初始化数据库失败: %v", err)
 	}
 	defer database.Close()
@@ -133,10 +596,22 @@ func main() {
 		log.Printf("⚠️  同步config.json到数据库失败: %v", err)
 	}
 
+	// 日志及错误信息语言
+	if locale, _ := database.GetSystemConfig("locale"); locale != "" {
+		if err := i18n.SetLocale(locale); err != nil {
+			log.Printf("⚠️  %v，使用默认语言 zh", err)
+		}
+	}
+
+	// 调试日志开关
+	debugLogStr, _ := database.GetSystemConfig("debug_log")
+	logger.SetDebug(debugLogStr == "true")
+
 	// 获取系统配置
 	useDefaultCoinsStr, _ := database.GetSystemConfig("use_default_coins")
 	useDefaultCoins := useDefaultCoinsStr == "true"
 	apiPortStr, _ := database.GetSystemConfig("api_server_port")
+	grpcPortStr, _ := database.GetSystemConfig("grpc_port")
 
 	// 获取管理员模式配置
 	adminModeStr, _ := database.GetSystemConfig("admin_mode")
@@ -149,17 +624,41 @@ func main() {
 		log.Printf("⚠️  使用默认JWT密钥，建议在生产环境中配置")
 	}
 	auth.SetJWTSecret(jwtSecret)
+	logger.RegisterSecret(jwtSecret)
 
 	// 在管理员模式下，确保admin用户存在
 	if adminMode {
+		adminPassword, _ := database.GetSystemConfig("admin_password")
+		if adminPassword != "" && !auth.IsPasswordHash(adminPassword) {
+			// 旧版本以明文保存，迁移为哈希
+			logger.RegisterSecret(adminPassword)
+			if hash, err := auth.HashPassword(adminPassword); err != nil {
+				log.Printf("⚠️  哈希管理员密码失败: %v", err)
+			} else if err := database.SetSystemConfig("admin_password", hash); err != nil {
+				log.Printf("⚠️  保存管理员密码哈希失败: %v", err)
+			} else {
+				adminPassword = hash
+			}
+		}
+		auth.SetAdminPasswordHash(adminPassword)
 		err := database.EnsureAdminUser()
-		if err != nil {
+		switch {
+		case err != nil:
 			log.Printf("⚠️  创建admin用户失败: %v", err)
-		} else {
+		case adminPassword != "":
+			log.Printf("✓ 管理员模式已启用，需使用管理员密码登录 (POST /api/admin-login)")
+		default:
 			log.Printf("✓ 管理员模式已启用，无需登录")
+			log.Printf("⚠️  未设置admin_password，任何能访问API端口的人都可以操作交易员，暴露端口前请设置管理员密码")
 		}
 		auth.SetAdminMode(true)
 	}
+	configureAPITokens(database)
+
+	// 环境变量中的AI模型、交易所凭证及交易员（容器部署）
+	if err := syncEnvRecords(database); err != nil {
+		log.Printf("⚠️  从环境变量同步交易员配置失败: %v", err)
+	}
 
 	log.Printf("✓ 配置数据库初始化成功")
 	fmt.Println()
@@ -202,8 +701,32 @@ func main() {
 		log.Printf("✓ 已配置OI Top API")
 	}
 
+	// 禁止开仓窗口（静态窗口 + 经济日历）
+	configureBlackout(database)
+
+	// 交易日切换时区（需在创建交易员之前设置）
+	configureDayLocation(database)
+
+	// 报告币种及汇率来源
+	configureQuoteCurrency(database)
+
+	// 稳定币脱锚保护
+	configureDepeg(database)
+
+	// 交易所维护窗口
+	configureMaintenance(database)
+
+	// AI特征源
+	configureFeatures(database)
+
+	// 交易链路追踪（需在创建交易员之前启用）
+	stopTracing := configureTracing(database)
+
 	// 创建TraderManager
 	traderManager := manager.NewTraderManager()
+	traderManager.SetExecutionRecorder(manager.NewExecutionRecorder(database)) // 记录开仓执行质量
+	traderManager.SetOrderJournal(manager.NewOrderJournal(database))           // 下单前写入意图，重启后对账
+	jobQueue := startJobQueue(database, traderManager)                         // 慢操作移出交易周期
 
 	// 从数据库加载所有交易员到内存
 	err = traderManager.LoadTradersFromDatabase(database)
@@ -211,6 +734,13 @@ func main() {
 		log.Fatalf("❌ 加载交易员失败: %v", err)
 	}
 
+	// API Key预检（权限、IP白名单）
+	configurePreflight(database, traderManager)
+	configureTwoPersonRule(database, traderManager)
+	configurePanicActions(database, traderManager)
+	instanceID, _ := database.GetSystemConfig("instance_id")
+	traderManager.SetInstanceID(instanceID)
+
 	// 获取数据库中的所有交易员配置（用于显示，使用default用户）
 	traders, err := database.GetTraders("default")
 	if err != nil {
@@ -257,17 +787,137 @@ func main() {
 
 	// 创建并启动API服务器
 	apiServer := api.NewServer(traderManager, database, apiPort)
+	tlsCert, _ := database.GetSystemConfig("api_tls_cert")
+	tlsKey, _ := database.GetSystemConfig("api_tls_key")
+	apiServer.SetTLS(tlsCert, tlsKey)
 	go func() {
 		if err := apiServer.Start(); err != nil {
 			log.Printf("❌ API服务器错误: %v", err)
 		}
 	}()
 
+	// 创建并启动gRPC服务器（可选）
+	var grpcServer *rpc.Server
+	if grpcPort, _ := strconv.Atoi(grpcPortStr); grpcPort > 0 {
+		grpcServer = rpc.NewServer(traderManager, database, grpcPort)
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				log.Printf("❌ gRPC服务器错误: %v", err)
+			}
+		}()
+	}
+
+	// 外部信号接入（可选）
+	signalConsumer := startSignalConsumer(database, traderManager)
+
+	// 执行事件导出（可选）
+	eventExporter := startEventExporter(database, traderManager)
+
+	// 已平仓交易推送到交易日志webhook（可选）
+	stopJournalExport := startJournalExport(database, traderManager)
+
+	// 定投调度器
+	var recurringScheduler *recurring.Scheduler
+	if enabled, _ := database.GetSystemConfig("recurring_scheduler_enabled"); enabled != "false" {
+		recurringScheduler = recurring.NewScheduler(database, traderManager)
+		recurringScheduler.Start()
+	}
+
+	// 稳定币脱锚告警（可选）
+	var stopDepegMonitor func()
+	if depeg.Enabled() {
+		stopDepegMonitor = traderManager.StartDepegMonitor(time.Minute)
+	}
+
+	// 交易所维护窗口告警及对冲（可选）
+	var stopMaintenanceMonitor func()
+	if maintenance.Enabled() {
+		hedgeCfg := manager.MaintenanceHedgeConfig{Leverage: 2}
+		hedgeCfg.TraderID, _ = database.GetSystemConfig("maintenance_hedge_trader_id")
+		leverageStr, _ := database.GetSystemConfig("maintenance_hedge_leverage")
+		if val, err := strconv.Atoi(leverageStr); err == nil && val > 0 {
+			hedgeCfg.Leverage = val
+		}
+		stopMaintenanceMonitor = traderManager.StartMaintenanceMonitor(time.Minute, hedgeCfg)
+	}
+
+	// 合约下架/交割监控
+	stopDelistingMonitor := traderManager.StartDelistingMonitor(10*time.Minute, delistingConfig(database))
+
+	// 合约交易规则后台刷新（各策略共用，规则变化时通知）
+	stopSpecRefresh := traderManager.StartSpecRefresh(time.Hour)
+
+	// 资金费率历史记录（期现套利及报表统计）
+	stopFundingRecorder := startFundingRecorder(database, traderManager)
+
+	// 新上线合约扫描（可选）
+	stopListingScanner := startListingScanner(database, traderManager)
+
+	// 期权Delta对冲（可选）
+	hedgers := startDeltaHedgers(database, traderManager)
+
+	// 期现套利（可选）
+	basisStrategies := startBasisStrategies(database, traderManager)
+
+	// 做市（可选）
+	marketMakers := startMarketMakers(database, traderManager)
+
+	// 动态选币（可选）
+	selectors := startUniverseSelectors(database, traderManager)
+
+	// Telegram运维机器人（可选）
+	telegramBot := startTelegramBot(database, traderManager)
+
+	// 邮件通知（可选）
+	stopEmail := startEmailNotifier(database, traderManager)
+
+	// 紧急告警呼叫（可选）
+	stopPagers := startPagers(database, traderManager)
+
+	// 事件webhook（可选）
+	stopWebhook := startEventWebhook(database, traderManager)
+
+	// 每日策略排行榜通知
+	var stopLeaderboard func()
+	hourStr, _ := database.GetSystemConfig("leaderboard_summary_hour")
+	if hour, err := strconv.Atoi(hourStr); err == nil && hour >= 0 && hour < 24 {
+		stopLeaderboard = traderManager.StartDailyLeaderboard(hour)
+	}
+
+	// 定期存活通知
+	var stopHeartbeat func()
+	heartbeatStr, _ := database.GetSystemConfig("heartbeat_interval_minutes")
+	if minutes, err := strconv.Atoi(heartbeatStr); err == nil && minutes > 0 {
+		stopHeartbeat = traderManager.StartHeartbeat(time.Duration(minutes) * time.Minute)
+	}
+
+	// 业绩日报/周报
+	var stopDigest func()
+	digestStr, _ := database.GetSystemConfig("performance_digest")
+	if schedule, err := manager.ParseDigestSchedule(digestStr); err != nil {
+		log.Printf("⚠️ %v，不发布业绩摘要", err)
+	} else if schedule != nil {
+		dashboardURL, _ := database.GetSystemConfig("dashboard_url")
+		stopDigest = traderManager.StartPerformanceDigest(database, schedule, dashboardURL)
+	}
+
+	// 每日开仓执行质量报告
+	var stopExecutionReport func()
+	hourStr, _ = database.GetSystemConfig("execution_report_hour")
+	if hour, err := strconv.Atoi(hourStr); err == nil && hour >= 0 && hour < 24 {
+		stopExecutionReport = traderManager.StartDailyExecutionReport(database, hour)
+	}
+
+	// 每日余额对账
+	var stopReconciler func()
+	if configureBalanceReconcile(database, traderManager) {
+		stopReconciler = traderManager.StartBalanceReconciler()
+	}
+
 	// 启动流行情数据 - 默认使用所有交易员设置的币种 如果没有设置币种 则优先使用系统默认
 	go market.NewWSMonitor(150).Start(database.GetCustomCoins())
 	//go market.NewWSMonitor(150).Start([]string{}) //这里是一个使用方式 传入空的话 则使用market市场的所有币种
 	// 设置优雅退出
-	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	// TODO: 启动数据库中配置为运行状态的交易员
@@ -278,8 +928,739 @@ func main() {
 	fmt.Println()
 	fmt.Println()
 	log.Println("📛 收到退出信号，正在停止所有trader...")
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+	if signalConsumer != nil {
+		signalConsumer.Stop()
+	}
+	if recurringScheduler != nil {
+		recurringScheduler.Stop()
+	}
+	for _, h := range hedgers {
+		h.Stop()
+	}
+	for _, s := range basisStrategies {
+		s.Stop()
+	}
+	for _, m := range marketMakers {
+		m.Stop()
+	}
+	for _, s := range selectors {
+		s.Stop()
+	}
+	if stopLeaderboard != nil {
+		stopLeaderboard()
+	}
+	if stopExecutionReport != nil {
+		stopExecutionReport()
+	}
+	if stopReconciler != nil {
+		stopReconciler()
+	}
+	if stopHeartbeat != nil {
+		stopHeartbeat()
+	}
+	if stopDigest != nil {
+		stopDigest()
+	}
+	if stopDepegMonitor != nil {
+		stopDepegMonitor()
+	}
+	if stopMaintenanceMonitor != nil {
+		stopMaintenanceMonitor()
+	}
+	stopDelistingMonitor()
+	stopSpecRefresh()
+	if stopFundingRecorder != nil {
+		stopFundingRecorder()
+	}
+	if stopJournalExport != nil {
+		stopJournalExport()
+	}
+	if stopListingScanner != nil {
+		stopListingScanner()
+	}
+	if stopEmail != nil {
+		stopEmail()
+	}
+	for _, stop := range stopPagers {
+		stop()
+	}
+	if stopWebhook != nil {
+		stopWebhook()
+	}
+	if stopTracing != nil {
+		stopTracing()
+	}
+	if telegramBot != nil {
+		telegramBot.Stop()
+	}
 	traderManager.StopAll()
+	if jobQueue != nil {
+		jobQueue.Stop()
+	}
+	if eventExporter != nil {
+		eventExporter.Stop()
+	}
+	traderManager.EventBus().Close() // 等待事件订阅者处理完剩余事件
 
 	fmt.Println()
 	fmt.Println("👋 感谢使用AI交易系统！")
 }
+
+// startSignalConsumer 按配置启动外部信号订阅（未配置信号源时返回nil）
+func startSignalConsumer(database config.Store, traderManager *manager.TraderManager) *signals.Consumer {
+	ingestURL, _ := database.GetSystemConfig("signal_ingest_url")
+	if ingestURL == "" {
+		return nil
+	}
+	topic, _ := database.GetSystemConfig("signal_ingest_topic")
+	source, err := signals.NewSource(ingestURL, topic)
+	if err != nil {
+		log.Printf("⚠️  %v，外部信号接入未启动", err)
+		return nil
+	}
+	consumer := signals.NewConsumer(source, traderManager)
+	consumer.Start()
+	return consumer
+}
+
+// startEventExporter 按配置启动执行事件导出（未配置导出地址时返回nil）
+func startEventExporter(database config.Store, traderManager *manager.TraderManager) *export.Exporter {
+	exportURL, _ := database.GetSystemConfig("event_export_url")
+	if exportURL == "" {
+		return nil
+	}
+	topic, _ := database.GetSystemConfig("event_export_topic")
+	publisher, err := export.NewPublisher(exportURL, topic)
+	if err != nil {
+		log.Printf("⚠️  %v，执行事件导出未启动", err)
+		return nil
+	}
+	return export.Start(traderManager.EventBus(), publisher)
+}
+
+// startJobQueue 按配置的worker数启动后台任务队列（worker数为0时返回nil）
+func startJobQueue(database config.Store, traderManager *manager.TraderManager) *jobs.Queue {
+	workersStr, _ := database.GetSystemConfig("job_workers")
+	workers, err := strconv.Atoi(workersStr)
+	if err != nil {
+		workers = 2
+	}
+	if workers <= 0 {
+		log.Printf("ℹ️  后台任务队列已关闭，慢操作在交易周期内同步执行")
+		return nil
+	}
+	q := jobs.NewQueue(workers, 100)
+	traderManager.SetJobQueue(q)
+	log.Printf("✓ 后台任务队列已启动（%d 个worker）", workers)
+	return q
+}
+
+// startJournalExport 按配置启动已平仓交易推送（未配置webhook地址时返回nil）
+func startJournalExport(database config.Store, traderManager *manager.TraderManager) func() {
+	webhookURL, _ := database.GetSystemConfig("journal_webhook_url")
+	if webhookURL == "" {
+		return nil
+	}
+	webhook, err := export.NewJournalWebhook(webhookURL)
+	if err != nil {
+		log.Printf("⚠️  %v，交易日志推送未启动", err)
+		return nil
+	}
+	return traderManager.StartJournalExport(database, webhook, time.Minute)
+}
+
+// startDeltaHedgers 按配置启动期权Delta对冲
+func startDeltaHedgers(database config.Store, traderManager *manager.TraderManager) []*hedge.Hedger {
+	hedgesJSON, _ := database.GetSystemConfig("delta_hedges")
+	configs, err := hedge.ParseConfigs(hedgesJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，Delta对冲未启动", err)
+		return nil
+	}
+
+	var hedgers []*hedge.Hedger
+	for _, cfg := range configs {
+		at, err := traderManager.GetTrader(cfg.TraderID)
+		if err != nil {
+			log.Printf("⚠️  Delta对冲 %s: %v", cfg.Underlying, err)
+			continue
+		}
+		if at.IsWatchOnly() {
+			log.Printf("⚠️  Delta对冲 %s: 交易员 %s 为观察模式，跳过", cfg.Underlying, at.GetName())
+			continue
+		}
+		if cfg.OptionsFile == "" {
+			log.Printf("⚠️  Delta对冲 %s 未配置 options_file，跳过", cfg.Underlying)
+			continue
+		}
+		perp := at.NewStrategyTrader("hedge:"+cfg.Underlying, trader.OrderPurposeHedge)
+		if err := perp.ClaimPositions(cfg.PerpSymbol); err != nil {
+			log.Printf("⚠️  Delta对冲 %s: %v，跳过", cfg.Underlying, err)
+			continue
+		}
+		h := hedge.NewHedger(cfg, perp, &hedge.FileSource{Path: cfg.OptionsFile})
+		h.Start()
+		at.OnHalt(h.Stop)
+		hedgers = append(hedgers, h)
+	}
+	return hedgers
+}
+
+// startBasisStrategies 按配置启动期现套利
+func startBasisStrategies(database config.Store, traderManager *manager.TraderManager) []*basis.Strategy {
+	basisJSON, _ := database.GetSystemConfig("basis_strategies")
+	configs, err := basis.ParseConfigs(basisJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，期现套利未启动", err)
+		return nil
+	}
+
+	var strategies []*basis.Strategy
+	for _, cfg := range configs {
+		at, err := traderManager.GetTrader(cfg.TraderID)
+		if err != nil {
+			log.Printf("⚠️  期现套利 %s: %v", cfg.Symbol, err)
+			continue
+		}
+		if at.IsWatchOnly() {
+			log.Printf("⚠️  期现套利 %s: 交易员 %s 为观察模式，跳过", cfg.Symbol, at.GetName())
+			continue
+		}
+		perp := at.NewStrategyTrader("basis:"+cfg.Symbol, trader.OrderPurposeBasis)
+		s, err := basis.NewStrategy(cfg, perp)
+		if err != nil {
+			log.Printf("⚠️  期现套利 %s: %v", cfg.Symbol, err)
+			continue
+		}
+		if err := perp.ClaimPositions(cfg.Symbol); err != nil {
+			log.Printf("⚠️  期现套利 %s: %v，跳过", cfg.Symbol, err)
+			continue
+		}
+		s.SetFundingHistory(database)
+		s.Start()
+		at.OnHalt(s.Stop)
+		strategies = append(strategies, s)
+	}
+	return strategies
+}
+
+// startFundingRecorder 记录配置的交易对及期现套利交易对的资金费率，没有交易对时不启动
+func startFundingRecorder(database config.Store, traderManager *manager.TraderManager) func() {
+	symbolsStr, _ := database.GetSystemConfig("funding_history_symbols")
+	basisJSON, _ := database.GetSystemConfig("basis_strategies")
+	basisConfigs, _ := basis.ParseConfigs(basisJSON)
+	for _, cfg := range basisConfigs {
+		symbolsStr += "," + cfg.Symbol
+	}
+
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, s := range strings.Split(symbolsStr, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if s = market.Normalize(s); !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil
+	}
+	return traderManager.StartFundingRecorder(database, symbols, time.Hour)
+}
+
+// startListingScanner 按配置启动新上线合约扫描
+func startListingScanner(database config.Store, traderManager *manager.TraderManager) func() {
+	exchangesStr, _ := database.GetSystemConfig("new_listing_exchanges")
+	var exchanges []string
+	for _, ex := range strings.Split(exchangesStr, ",") {
+		if ex = strings.TrimSpace(ex); ex != "" {
+			exchanges = append(exchanges, ex)
+		}
+	}
+	if len(exchanges) == 0 {
+		return nil
+	}
+	scanCfg := listings.Config{Exchanges: exchanges, Interval: 10 * time.Minute, Recent: 7 * 24 * time.Hour}
+	intervalStr, _ := database.GetSystemConfig("new_listing_scan_minutes")
+	if val, err := strconv.Atoi(intervalStr); err == nil && val > 0 {
+		scanCfg.Interval = time.Duration(val) * time.Minute
+	}
+
+	// 安全默认值：上线满24小时、每个交易员最多3个、杠杆不超过3倍、单笔不超过100 USDT
+	cfg := manager.ListingConfig{
+		MinAge:       24 * time.Hour,
+		MaxPerTrader: 3,
+		Limits:       trader.CandidateLimits{MaxLeverage: 3, MaxPositionUSD: 100},
+	}
+	tradersStr, _ := database.GetSystemConfig("new_listing_auto_add_traders")
+	for _, id := range strings.Split(tradersStr, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			cfg.AutoAddTraders = append(cfg.AutoAddTraders, id)
+		}
+	}
+	minAgeStr, _ := database.GetSystemConfig("new_listing_min_age_hours")
+	if val, err := strconv.Atoi(minAgeStr); err == nil && val >= 0 {
+		cfg.MinAge = time.Duration(val) * time.Hour
+	}
+	maxPerTraderStr, _ := database.GetSystemConfig("new_listing_max_per_trader")
+	if val, err := strconv.Atoi(maxPerTraderStr); err == nil && val > 0 {
+		cfg.MaxPerTrader = val
+	}
+	leverageStr, _ := database.GetSystemConfig("new_listing_max_leverage")
+	if val, err := strconv.Atoi(leverageStr); err == nil && val > 0 {
+		cfg.Limits.MaxLeverage = val
+	}
+	positionStr, _ := database.GetSystemConfig("new_listing_max_position_usd")
+	if val, err := strconv.ParseFloat(positionStr, 64); err == nil && val >= 0 {
+		cfg.Limits.MaxPositionUSD = val
+	}
+
+	stop, err := traderManager.StartListingScanner(scanCfg, cfg)
+	if err != nil {
+		log.Printf("⚠️  启动新上线合约扫描失败: %v", err)
+		return nil
+	}
+	return stop
+}
+
+// startMarketMakers 按配置启动做市
+func startMarketMakers(database config.Store, traderManager *manager.TraderManager) []*marketmaker.Maker {
+	makersJSON, _ := database.GetSystemConfig("market_makers")
+	configs, err := marketmaker.ParseConfigs(makersJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，做市未启动", err)
+		return nil
+	}
+
+	var makers []*marketmaker.Maker
+	for _, cfg := range configs {
+		at, err := traderManager.GetTrader(cfg.TraderID)
+		if err != nil {
+			log.Printf("⚠️  做市 %s: %v", cfg.Symbol, err)
+			continue
+		}
+		if at.IsWatchOnly() {
+			log.Printf("⚠️  做市 %s: 交易员 %s 为观察模式，跳过", cfg.Symbol, at.GetName())
+			continue
+		}
+		// 做市挂单直接提交到交易所，模拟交易的交易员不能做市
+		if at.IsDryRun() {
+			log.Printf("⚠️  做市 %s: 交易员 %s 为模拟交易，跳过", cfg.Symbol, at.GetName())
+			continue
+		}
+		quotes := at.NewStrategyTrader("maker:"+cfg.Symbol, trader.OrderPurposeQuote)
+		m, err := marketmaker.NewMaker(cfg, quotes)
+		if err == nil {
+			err = m.Start()
+		}
+		if err != nil {
+			log.Printf("⚠️  做市 %s: %v", cfg.Symbol, err)
+			continue
+		}
+		at.OnHalt(m.Stop)
+		makers = append(makers, m)
+	}
+	return makers
+}
+
+// startUniverseSelectors 按配置启动动态选币
+func startUniverseSelectors(database config.Store, traderManager *manager.TraderManager) []*universe.Selector {
+	universesJSON, _ := database.GetSystemConfig("universes")
+	configs, err := universe.ParseConfigs(universesJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，动态选币未启动", err)
+		return nil
+	}
+
+	var selectors []*universe.Selector
+	for _, cfg := range configs {
+		at, err := traderManager.GetTrader(cfg.TraderID)
+		if err != nil {
+			log.Printf("⚠️  动态选币 %s: %v", cfg.TraderID, err)
+			continue
+		}
+		s := universe.NewSelector(cfg, at)
+		s.Start()
+		selectors = append(selectors, s)
+	}
+	return selectors
+}
+
+// startTelegramBot 按配置启动Telegram运维机器人（未配置Token时返回nil）
+func startTelegramBot(database config.Store, traderManager *manager.TraderManager) *telegram.Bot {
+	token, _ := database.GetSystemConfig("telegram_bot_token")
+	if token == "" {
+		return nil
+	}
+	idsStr, _ := database.GetSystemConfig("telegram_operator_ids")
+	operatorIDs, err := telegram.ParseOperatorIDs(idsStr)
+	if err != nil {
+		log.Printf("⚠️  %v，Telegram机器人未启动", err)
+		return nil
+	}
+	bot, err := telegram.NewBot(token, operatorIDs, traderManager)
+	if err != nil {
+		log.Printf("⚠️  %v，Telegram机器人未启动", err)
+		return nil
+	}
+	usersStr, _ := database.GetSystemConfig("telegram_operator_users")
+	operatorUsers, err := telegram.ParseOperatorUsers(usersStr)
+	if err != nil {
+		log.Printf("⚠️  %v，Telegram运维人员未关联用户（不能发起或确认两人规则订单）", err)
+	}
+	bot.SetOperatorUsers(operatorUsers, func(userID, traderID string) bool {
+		traders, err := database.GetTraders(userID)
+		if err != nil {
+			return false
+		}
+		for _, t := range traders {
+			if t.ID == traderID {
+				return true
+			}
+		}
+		return false
+	})
+	bot.Start()
+	traderManager.SetApprover(bot) // 开启人工确认的交易员通过机器人确认决策
+	return bot
+}
+
+// startEmailNotifier 按配置启动邮件通知（未配置SMTP地址时返回nil），返回停止函数
+func startEmailNotifier(database config.Store, traderManager *manager.TraderManager) func() {
+	smtpURL, _ := database.GetSystemConfig("smtp_url")
+	if smtpURL == "" {
+		return nil
+	}
+	from, _ := database.GetSystemConfig("smtp_from")
+	toStr, _ := database.GetSystemConfig("smtp_to")
+	notifier, err := notify.NewSMTPNotifier(notify.SMTPConfig{URL: smtpURL, From: from, To: strings.Split(toStr, ",")})
+	if err != nil {
+		log.Printf("⚠️  %v，邮件通知未启动", err)
+		return nil
+	}
+	typesStr, _ := database.GetSystemConfig("smtp_events")
+	types, err := notify.ParseTypes(typesStr)
+	if err != nil {
+		log.Printf("⚠️  %v，邮件通知未启动", err)
+		return nil
+	}
+	log.Printf("📧 邮件通知已启动（收件人 %d 人）", notifier.Recipients())
+	return notify.Subscribe(traderManager.EventBus(), notifier, types...)
+}
+
+// startPagers 按配置启动PagerDuty/Opsgenie紧急告警（只订阅紧急事件），返回停止函数
+func startPagers(database config.Store, traderManager *manager.TraderManager) []func() {
+	var pagers []notify.Notifier
+	if key, _ := database.GetSystemConfig("pagerduty_routing_key"); key != "" {
+		if p, err := notify.NewPagerDutyNotifier(key, traderManager.InstanceID()); err != nil {
+			log.Printf("⚠️  %v，PagerDuty告警未启动", err)
+		} else {
+			pagers = append(pagers, p)
+		}
+	}
+	if key, _ := database.GetSystemConfig("opsgenie_api_key"); key != "" {
+		apiURL, _ := database.GetSystemConfig("opsgenie_api_url")
+		if o, err := notify.NewOpsgenieNotifier(key, apiURL, traderManager.InstanceID()); err != nil {
+			log.Printf("⚠️  %v，Opsgenie告警未启动", err)
+		} else {
+			pagers = append(pagers, o)
+		}
+	}
+
+	stops := make([]func(), 0, len(pagers))
+	for _, p := range pagers {
+		stops = append(stops, notify.Subscribe(traderManager.EventBus(), p, notify.CriticalTypes...))
+		log.Printf("📟 %s 紧急告警已启动（强平风险、紧急停止、连续API认证失败）", p.Name())
+	}
+	return stops
+}
+
+// startEventWebhook 按配置启动事件webhook（未配置地址时返回nil），返回停止函数
+func startEventWebhook(database config.Store, traderManager *manager.TraderManager) func() {
+	webhookURL, _ := database.GetSystemConfig("webhook_url")
+	if webhookURL == "" {
+		return nil
+	}
+	secret, _ := database.GetSystemConfig("webhook_secret")
+	webhook, err := notify.NewWebhookNotifier(webhookURL, secret)
+	if err != nil {
+		log.Printf("⚠️  %v，事件webhook未启动", err)
+		return nil
+	}
+	if secret == "" {
+		log.Printf("⚠️  未配置 webhook_secret，事件webhook请求不签名")
+	}
+	log.Printf("🪝 执行事件推送到 %s", webhook.Target())
+	return notify.SubscribeAll(traderManager.EventBus(), webhook)
+}
+
+// configureTracing 按配置启用OpenTelemetry交易链路追踪（未配置地址时返回nil），返回关闭函数
+func configureTracing(database config.Store) func() {
+	endpoint, _ := database.GetSystemConfig("otel_endpoint")
+	if strings.TrimSpace(endpoint) == "" {
+		return nil
+	}
+	ratio := 1.0
+	if ratioStr, _ := database.GetSystemConfig("otel_sample_ratio"); ratioStr != "" {
+		if v, err := strconv.ParseFloat(ratioStr, 64); err == nil {
+			ratio = v
+		}
+	}
+	instanceID, _ := database.GetSystemConfig("instance_id")
+	if instanceID == "" {
+		instanceID, _ = os.Hostname()
+	}
+	shutdown, err := tracing.Setup(tracing.Config{Endpoint: endpoint, InstanceID: instanceID, SampleRatio: ratio})
+	if err != nil {
+		log.Printf("⚠️  %v，交易链路追踪未启用", err)
+		return nil
+	}
+	log.Printf("🔭 交易链路追踪导出到 %s（采样比例 %g）", endpoint, ratio)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("⚠️  导出剩余追踪数据失败: %v", err)
+		}
+	}
+}
+
+// configureQuoteCurrency 从数据库读取报告币种及汇率来源配置
+func configureQuoteCurrency(database config.Store) {
+	currency, _ := database.GetSystemConfig("quote_currency")
+	fx.SetQuoteCurrency(currency)
+
+	staticRates, _ := database.GetSystemConfig("fx_static_rates")
+	if strings.TrimSpace(staticRates) != "" {
+		rates, err := fx.ParseStaticRates(staticRates)
+		if err != nil {
+			log.Printf("⚠️  %v，忽略固定汇率", err)
+		} else {
+			fx.SetSource(rates)
+			log.Printf("✓ 报告币种: %s（固定汇率 %d 个）", fx.QuoteCurrency(), len(rates))
+			return
+		}
+	}
+
+	rateURL, _ := database.GetSystemConfig("fx_rate_url")
+	if rateURL == "" {
+		rateURL = fx.DefaultRateURL
+	}
+	fx.SetSource(&fx.HTTPSource{URL: rateURL})
+	if fx.QuoteCurrency() != fx.BaseCurrency {
+		log.Printf("✓ 报告币种: %s（汇率来源: %s）", fx.QuoteCurrency(), rateURL)
+	}
+}
+
+// configureDepeg 从数据库读取稳定币脱锚保护配置
+func configureDepeg(database config.Store) {
+	cfg := depeg.Config{}
+	coinsStr, _ := database.GetSystemConfig("depeg_coins")
+	for _, coin := range strings.Split(coinsStr, ",") {
+		if coin = strings.TrimSpace(coin); coin != "" {
+			cfg.Coins = append(cfg.Coins, coin)
+		}
+	}
+	alertStr, _ := database.GetSystemConfig("depeg_alert_pct")
+	cfg.AlertPct, _ = strconv.ParseFloat(alertStr, 64)
+	reduceAtStr, _ := database.GetSystemConfig("depeg_reduce_at_pct")
+	cfg.ReduceAtPct, _ = strconv.ParseFloat(reduceAtStr, 64)
+	reduceStr, _ := database.GetSystemConfig("depeg_reduce_pct")
+	cfg.ReducePct, _ = strconv.ParseFloat(reduceStr, 64)
+	depeg.SetConfig(cfg)
+
+	if cfg.AlertPct > 0 {
+		log.Printf("✓ 已启用稳定币脱锚保护（偏离 ≥%.2f%% 暂停开仓，≥%.2f%% 减仓 %.0f%%）", cfg.AlertPct, cfg.ReduceAtPct, cfg.ReducePct)
+	}
+}
+
+// configureFeatures 从数据库读取AI特征源配置
+func configureFeatures(database config.Store) {
+	providersJSON, _ := database.GetSystemConfig("feature_providers")
+	configs, err := features.ParseConfigs(providersJSON)
+	if err == nil {
+		err = features.SetProviders(configs)
+	}
+	if err != nil {
+		log.Printf("⚠️  %v，AI特征源未启用", err)
+		return
+	}
+	if len(configs) > 0 {
+		log.Printf("✓ 已启用 %d 个AI特征源", len(configs))
+	}
+}
+
+// configurePreflight 从数据库读取API Key预检配置，并检查所有已加载交易员的API Key
+func configurePreflight(database config.Store, traderManager *manager.TraderManager) {
+	mode, _ := database.GetSystemConfig("api_key_preflight")
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = trader.PreflightStrict
+	}
+	refuseStr, _ := database.GetSystemConfig("refuse_withdrawal_keys")
+	cfg := trader.PreflightConfig{Mode: mode, RefuseWithdrawals: refuseStr != "false"}
+	trader.SetPreflightConfig(cfg)
+	if mode == trader.PreflightOff && !cfg.RefuseWithdrawals {
+		return
+	}
+
+	failed, withdrawable := 0, 0
+	for _, report := range traderManager.PreflightAll() {
+		if report.Err() != nil {
+			failed++
+		}
+		if report.Withdrawals {
+			withdrawable++
+		}
+	}
+	if withdrawable > 0 && cfg.RefuseWithdrawals {
+		log.Printf("❌ %d 个交易员的API Key开启了提现权限，这些交易员将拒绝启动（关闭提现权限，或设置 refuse_withdrawal_keys=false）", withdrawable)
+	}
+	if failed > 0 && mode == trader.PreflightStrict {
+		log.Printf("❌ %d 个交易员的API Key预检未通过，修复前将拒绝启动（可设置 api_key_preflight=warn 只告警）", failed)
+	}
+}
+
+// configureAPITokens 从数据库读取静态API Token（按角色限制HTTP/gRPC控制接口的权限）
+func configureAPITokens(database config.Store) {
+	tokensJSON, _ := database.GetSystemConfig("api_tokens")
+	tokens, err := auth.ParseAPITokens(tokensJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，不启用API Token", err)
+		return
+	}
+	for _, t := range tokens {
+		logger.RegisterSecret(t.Token)
+	}
+	auth.SetAPITokens(tokens)
+	if len(tokens) > 0 {
+		log.Printf("✓ 已加载 %d 个API Token", len(tokens))
+	}
+}
+
+// configurePanicActions 从数据库读取紧急按钮默认执行的动作
+func configurePanicActions(database config.Store, traderManager *manager.TraderManager) {
+	spec, _ := database.GetSystemConfig("panic_actions")
+	actions, err := trader.ParsePanicActions(spec)
+	if err != nil {
+		log.Printf("⚠️  %v，紧急按钮执行全部动作", err)
+		return
+	}
+	traderManager.SetPanicActions(actions)
+}
+
+// configureTwoPersonRule 从数据库读取大额人工订单的两人规则阈值
+func configureTwoPersonRule(database config.Store, traderManager *manager.TraderManager) {
+	thresholdStr, _ := database.GetSystemConfig("two_person_threshold_usd")
+	if val, err := strconv.ParseFloat(thresholdStr, 64); err == nil && val > 0 {
+		traderManager.SetTwoPersonThreshold(val)
+	}
+}
+
+// configureMaintenance 从数据库读取交易所维护窗口配置
+func configureMaintenance(database config.Store) {
+	windowsJSON, _ := database.GetSystemConfig("maintenance_windows")
+	windows, err := maintenance.ParseWindows(windowsJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，忽略静态维护窗口", err)
+	}
+	leadMinutes := 15
+	leadStr, _ := database.GetSystemConfig("maintenance_lead_minutes")
+	if val, err := strconv.Atoi(leadStr); err == nil && val >= 0 {
+		leadMinutes = val
+	}
+	maintenance.SetConfig(maintenance.Config{Windows: windows, Lead: time.Duration(leadMinutes) * time.Minute})
+
+	var sources []maintenance.Source
+	feedURL, _ := database.GetSystemConfig("maintenance_feed_url")
+	if feedURL = strings.TrimSpace(feedURL); feedURL != "" {
+		sources = append(sources, &maintenance.FeedSource{URL: feedURL})
+	}
+	if enabled, _ := database.GetSystemConfig("maintenance_binance_status"); enabled == "true" {
+		sources = append(sources, &maintenance.BinanceStatusSource{URL: maintenance.BinanceStatusURL})
+	}
+	maintenance.SetSources(sources...)
+
+	if len(windows) > 0 || len(sources) > 0 {
+		log.Printf("✓ 已配置交易所维护窗口（静态窗口 %d 个，公告来源 %d 个，提前 %d 分钟暂停开仓）", len(windows), len(sources), leadMinutes)
+	}
+}
+
+// delistingConfig 从数据库读取合约下架处理配置
+func delistingConfig(database config.Store) manager.DelistingConfig {
+	cfg := manager.DelistingConfig{CloseLead: 24 * time.Hour, MigrateLeverage: 2}
+	hoursStr, _ := database.GetSystemConfig("delisting_close_hours")
+	if val, err := strconv.Atoi(hoursStr); err == nil {
+		cfg.CloseLead = time.Duration(val) * time.Hour
+	}
+	cfg.MigrateTraderID, _ = database.GetSystemConfig("delisting_migrate_trader_id")
+	leverageStr, _ := database.GetSystemConfig("delisting_migrate_leverage")
+	if val, err := strconv.Atoi(leverageStr); err == nil && val > 0 {
+		cfg.MigrateLeverage = val
+	}
+	return cfg
+}
+
+// configureBalanceReconcile 从数据库读取每日余额对账容差，返回是否开启每日对账
+func configureBalanceReconcile(database config.Store, traderManager *manager.TraderManager) bool {
+	spec, _ := database.GetSystemConfig("balance_reconcile_tolerance")
+	tolerance, err := trader.ParseReconcileTolerance(spec)
+	if err != nil {
+		log.Printf("⚠️  %v，每日余额对账未开启", err)
+		return false
+	}
+	traderManager.SetReconcileTolerance(tolerance)
+	return tolerance != nil
+}
+
+// configureDayLocation 从数据库读取交易日切换时区（日盈亏、每日AI预算及业绩日报按该时区零点切换）
+func configureDayLocation(database config.Store) {
+	name, _ := database.GetSystemConfig("day_reset_timezone")
+	loc, err := calendar.LoadDayLocation(name)
+	if err != nil {
+		log.Printf("⚠️  %v，交易日按UTC切换", err)
+		loc = time.UTC
+	}
+	calendar.SetDayLocation(loc)
+	if loc != time.UTC {
+		log.Printf("✓ 交易日切换时区: %s（当前交易日 %s）", loc, calendar.DayKey(time.Now()))
+	}
+}
+
+// configureBlackout 从数据库读取禁止开仓窗口配置
+func configureBlackout(database config.Store) {
+	windowsJSON, _ := database.GetSystemConfig("blackout_windows")
+	windows, err := calendar.ParseWindows(windowsJSON)
+	if err != nil {
+		log.Printf("⚠️  %v，忽略静态禁止开仓窗口", err)
+	}
+	calendar.SetWindows(windows)
+
+	paddingMinutes := 30
+	paddingStr, _ := database.GetSystemConfig("blackout_padding_minutes")
+	if val, err := strconv.Atoi(paddingStr); err == nil && val >= 0 {
+		paddingMinutes = val
+	}
+	reducePctStr, _ := database.GetSystemConfig("blackout_reduce_pct")
+	reducePct, _ := strconv.ParseFloat(reducePctStr, 64)
+	calendar.SetCalendarWindow(time.Duration(paddingMinutes)*time.Minute, reducePct)
+
+	calendarURL, _ := database.GetSystemConfig("economic_calendar_url")
+	countriesStr, _ := database.GetSystemConfig("economic_calendar_countries")
+	var countries []string
+	for _, c := range strings.Split(countriesStr, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			countries = append(countries, c)
+		}
+	}
+	calendar.SetCalendarSource(calendarURL, countries)
+
+	if len(windows) > 0 || calendarURL != "" {
+		log.Printf("✓ 已配置禁止开仓窗口（静态窗口 %d 个，经济日历: %t）", len(windows), calendarURL != "")
+	}
+}
@@ -0,0 +1,282 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"nofx/market"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BinanceStatusURL 币安系统状态接口（status=1 表示系统维护中）
+const BinanceStatusURL = "https://api.binance.com/sapi/v1/system/status"
+
+// Window 交易所维护窗口
+type Window struct {
+	Exchange string    `json:"exchange"`          // binance / hyperliquid / aster ...
+	Name     string    `json:"name"`              // 公告标题
+	Start    time.Time `json:"start"`             // 开始时间
+	End      time.Time `json:"end"`               // 结束时间
+	Symbols  []string  `json:"symbols,omitempty"` // 受影响的合约（为空表示全部合约）
+	Source   string    `json:"source,omitempty"`  // static / feed / status
+}
+
+// Covers 窗口（含提前量）是否覆盖指定交易所的合约
+func (w Window) Covers(exchange, symbol string, now time.Time, lead time.Duration) bool {
+	if !strings.EqualFold(w.Exchange, exchange) {
+		return false
+	}
+	if now.Before(w.Start.Add(-lead)) || !now.Before(w.End) {
+		return false
+	}
+	if len(w.Symbols) == 0 || symbol == "" {
+		return true
+	}
+	symbol = market.Normalize(symbol)
+	for _, s := range w.Symbols {
+		if market.Normalize(s) == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// Key 窗口唯一标识（用于对冲/告警去重）
+func (w Window) Key() string {
+	return w.Exchange + "|" + w.Name + "|" + w.Start.UTC().Format(time.RFC3339)
+}
+
+// Source 维护公告来源
+type Source interface {
+	Windows(ctx context.Context) ([]Window, error)
+}
+
+// FeedSource 从JSON公告源读取维护窗口，格式同 maintenance_windows：
+// [{"exchange":"binance","name":"合约系统升级","start":"2025-03-01T02:00:00Z","end":"2025-03-01T04:00:00Z","symbols":["BTCUSDT"]}]
+type FeedSource struct {
+	URL string
+}
+
+// Windows 实现 Source
+func (s *FeedSource) Windows(ctx context.Context) ([]Window, error) {
+	body, err := get(ctx, s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("请求维护公告失败: %w", err)
+	}
+	windows, err := ParseWindows(string(body))
+	if err != nil {
+		return nil, err
+	}
+	for i := range windows {
+		windows[i].Source = "feed"
+	}
+	return windows, nil
+}
+
+// BinanceStatusSource 轮询币安系统状态，维护中时生成一个持续到下次轮询之后的窗口
+type BinanceStatusSource struct {
+	URL string
+
+	mu    sync.Mutex
+	since time.Time // 本次维护的开始时间（保持窗口标识稳定）
+}
+
+// Windows 实现 Source
+func (s *BinanceStatusSource) Windows(ctx context.Context) ([]Window, error) {
+	body, err := get(ctx, s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("请求币安系统状态失败: %w", err)
+	}
+	var status struct {
+		Status int    `json:"status"` // 0=正常，1=系统维护
+		Msg    string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("解析币安系统状态失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status.Status == 0 {
+		s.since = time.Time{}
+		return nil, nil
+	}
+
+	now := time.Now()
+	if s.since.IsZero() {
+		s.since = now
+	}
+	mu.RLock()
+	refresh := config.Refresh
+	mu.RUnlock()
+	return []Window{{
+		Exchange: "binance",
+		Name:     "系统维护",
+		Start:    s.since,
+		End:      now.Add(2 * refresh),
+		Source:   "status",
+	}}, nil
+}
+
+// get 发送GET请求并读取响应
+func get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// ParseWindows 解析JSON格式的维护窗口
+func ParseWindows(raw string) ([]Window, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var windows []Window
+	if err := json.Unmarshal([]byte(raw), &windows); err != nil {
+		return nil, fmt.Errorf("解析维护窗口失败: %w", err)
+	}
+	for i, w := range windows {
+		if w.Exchange == "" {
+			return nil, fmt.Errorf("维护窗口 #%d 缺少 exchange", i+1)
+		}
+		if !w.End.After(w.Start) {
+			return nil, fmt.Errorf("维护窗口 %s 的结束时间必须晚于开始时间", w.Name)
+		}
+		if windows[i].Source == "" {
+			windows[i].Source = "static"
+		}
+	}
+	return windows, nil
+}
+
+// Config 维护窗口配置
+type Config struct {
+	Windows []Window      // 静态配置的维护窗口
+	Lead    time.Duration // 维护开始前提前暂停开仓的时长
+	Refresh time.Duration // 公告/状态轮询间隔
+}
+
+var (
+	mu      sync.RWMutex
+	config  = Config{Refresh: time.Minute}
+	sources []Source
+
+	// 公告缓存
+	polled    []Window
+	fetchedAt time.Time
+)
+
+// SetConfig 设置静态维护窗口及提前量
+func SetConfig(cfg Config) {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = time.Minute
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	config = cfg
+	fetchedAt = time.Time{}
+}
+
+// SetSources 设置维护公告来源
+func SetSources(srcs ...Source) {
+	mu.Lock()
+	defer mu.Unlock()
+	sources = srcs
+	polled, fetchedAt = nil, time.Time{}
+}
+
+// Enabled 是否配置了任何维护窗口或公告来源
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(config.Windows) > 0 || len(sources) > 0
+}
+
+// Active 返回当前（含提前量）覆盖指定交易所合约的维护窗口
+func Active(exchange, symbol string, now time.Time) (Window, bool) {
+	mu.RLock()
+	lead := config.Lead
+	mu.RUnlock()
+	for _, w := range Windows() {
+		if w.Covers(exchange, symbol, now, lead) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// Lead 维护开始前提前暂停开仓的时长
+func Lead() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.Lead
+}
+
+// Windows 获取所有未结束的维护窗口（公告按轮询间隔缓存，请求失败时沿用上次结果）
+func Windows() []Window {
+	mu.RLock()
+	cfg, srcs, cached := config, sources, polled
+	fresh := time.Since(fetchedAt) < cfg.Refresh
+	mu.RUnlock()
+
+	if !fresh && len(srcs) > 0 {
+		cached = poll(srcs, cached)
+	}
+
+	now := time.Now()
+	var result []Window
+	for _, w := range append(append([]Window{}, cfg.Windows...), cached...) {
+		if now.Before(w.End) {
+			result = append(result, w)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+// poll 请求所有公告来源并更新缓存
+func poll(srcs []Source, previous []Window) []Window {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var windows []Window
+	failed := false
+	for _, src := range srcs {
+		ws, err := src.Windows(ctx)
+		if err != nil {
+			log.Printf("⚠️  获取交易所维护公告失败: %v", err)
+			failed = true
+			continue
+		}
+		windows = append(windows, ws...)
+	}
+	// 有来源失败且本次没有任何结果时沿用上次数据，避免网络抖动导致提前恢复开仓
+	if failed && len(windows) == 0 {
+		windows = previous
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	polled, fetchedAt = windows, time.Now()
+	return windows
+}
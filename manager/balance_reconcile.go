@@ -0,0 +1,157 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/calendar"
+	"nofx/events"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reconcileDelay 交易日切换后延迟对账，等待前一交易日最后一个决策周期写入日志
+const reconcileDelay = 10 * time.Minute
+
+// SetReconcileTolerance 设置每日余额对账容差
+func (tm *TraderManager) SetReconcileTolerance(tolerance *trader.ReconcileTolerance) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.reconcileTolerance = tolerance
+}
+
+// ReconcileTolerance 余额对账容差（未配置时为默认容差）
+func (tm *TraderManager) ReconcileTolerance() trader.ReconcileTolerance {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if tm.reconcileTolerance == nil {
+		return trader.DefaultReconcileTolerance
+	}
+	return *tm.reconcileTolerance
+}
+
+// ReconcileBalances 对账day所在交易日各账户的余额变动（traderIDs为空时为全部交易员；指定交易员时对账其所在的账户）
+// 共用账户的交易员只对账一次（由ID最小的交易员代表，持仓快照包含账户的全部持仓；合并全部交易员记录的交易、资金费及手续费），模拟交易员不参与
+func (tm *TraderManager) ReconcileBalances(traderIDs []string, day time.Time) ([]*trader.BalanceReconciliation, map[string]string, error) {
+	traders, err := tm.closeAllTraders(traderIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	errs := make(map[string]string)
+	requested := make(map[string]bool)
+	for _, at := range traders {
+		if at.IsDryRun() {
+			errs[at.GetID()] = "模拟交易不参与余额对账"
+			continue
+		}
+		requested[at.GetID()] = true
+	}
+	// 共用账户的全部交易员一起对账（包括未指定的交易员）
+	var live []*trader.AutoTrader
+	for _, at := range tm.GetAllTraders() {
+		if !at.IsDryRun() {
+			live = append(live, at)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].GetID() < live[j].GetID() })
+
+	tolerance := tm.ReconcileTolerance()
+	results := []*trader.BalanceReconciliation{}
+	for _, group := range trader.GroupByAccount(live) {
+		if !containsRequested(group, requested) {
+			continue
+		}
+		at := group[0]
+		r, err := at.ReconcileBalance(day, tolerance, group[1:]...)
+		if err != nil {
+			errs[at.GetID()] = err.Error()
+			continue
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].TraderID < results[j].TraderID })
+	return results, errs, nil
+}
+
+// containsRequested 账户分组中是否有指定对账的交易员
+func containsRequested(group []*trader.AutoTrader, requested map[string]bool) bool {
+	for _, at := range group {
+		if requested[at.GetID()] {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTradingDay 解析交易日（YYYY-MM-DD，按交易日时区），为空时为前一交易日
+func ParseTradingDay(s string) (time.Time, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return calendar.DayStart(time.Now()).AddDate(0, 0, -1), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, calendar.DayLocation())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的交易日: %s（格式 YYYY-MM-DD）", s)
+	}
+	return day, nil
+}
+
+// StartBalanceReconciler 每个交易日切换后对账前一交易日的余额变动，差额超过容差时发布 BalanceMismatch 告警，返回停止函数
+func (tm *TraderManager) StartBalanceReconciler() func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			now := time.Now()
+			next := calendar.DayStart(now).Add(reconcileDelay)
+			if !next.After(now) {
+				next = calendar.NextDayStart(now).Add(reconcileDelay)
+			}
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			day := calendar.DayStart(next).Add(-time.Hour) // 前一交易日
+			results, errs, err := tm.ReconcileBalances(nil, day)
+			if err != nil {
+				log.Printf("⚠️ 余额对账失败: %v", err)
+				continue
+			}
+			for id, msg := range errs {
+				log.Printf("⚠️ [%s] 余额对账跳过: %s", id, msg)
+			}
+			for _, r := range results {
+				summary := trader.FormatBalanceReconciliation(r)
+				log.Printf("%s", summary)
+				if r.Mismatch {
+					tm.eventBus.Publish(events.Event{Type: events.BalanceMismatch, TraderID: r.TraderID, Exchange: r.Exchange, PnL: r.Unexplained, Message: summary, Payload: r})
+				}
+			}
+		}
+	}()
+	log.Printf("✓ 每日余额对账已启动（容差 %s，交易日切换 %s 后执行）", tm.ReconcileTolerance(), reconcileDelay)
+	return func() { close(stop) }
+}
+
+// FormatBalanceReconciliations 多个账户的余额对账结果（用于Telegram）
+func FormatBalanceReconciliations(results []*trader.BalanceReconciliation, errs map[string]string) string {
+	var parts []string
+	for _, r := range results {
+		parts = append(parts, trader.FormatBalanceReconciliation(r))
+	}
+	ids := make([]string, 0, len(errs))
+	for id := range errs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("❌ [%s] %s", id, errs[id]))
+	}
+	if len(parts) == 0 {
+		return "没有需要对账的账户"
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,187 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CloseAllReport 批量平仓结果
+type CloseAllReport struct {
+	Filter    trader.CloseFilter   `json:"filter"`
+	TraderIDs []string             `json:"trader_ids"`
+	Results   []trader.CloseResult `json:"results"`
+	Errors    map[string]string    `json:"errors,omitempty"` // 交易员ID -> 无法执行的原因（如获取持仓失败）
+	Closed    int                  `json:"closed"`
+	Failed    int                  `json:"failed"`
+}
+
+// closeAllTraders 按ID选择交易员（为空时为全部交易员）
+func (tm *TraderManager) closeAllTraders(traderIDs []string) ([]*trader.AutoTrader, error) {
+	if len(traderIDs) == 0 {
+		all := tm.GetAllTraders()
+		traders := make([]*trader.AutoTrader, 0, len(all))
+		for _, at := range all {
+			traders = append(traders, at)
+		}
+		return traders, nil
+	}
+	traders := make([]*trader.AutoTrader, 0, len(traderIDs))
+	for _, id := range traderIDs {
+		at, err := tm.GetTrader(id)
+		if err != nil {
+			return nil, err
+		}
+		traders = append(traders, at)
+	}
+	return traders, nil
+}
+
+// CloseAllPositions 并发平掉多个交易员符合过滤条件的持仓（traderIDs为空时为全部交易员）
+// 不同账户并发执行，共用同一账户的交易员依次执行（后执行的交易员按最新持仓跳过已平掉的持仓）
+func (tm *TraderManager) CloseAllPositions(traderIDs []string, filter trader.CloseFilter, reason string) (*CloseAllReport, error) {
+	filter, err := filter.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	traders, err := tm.closeAllTraders(traderIDs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(traders, func(i, j int) bool { return traders[i].GetID() < traders[j].GetID() })
+
+	report := &CloseAllReport{Filter: filter, TraderIDs: []string{}, Results: []trader.CloseResult{}, Errors: make(map[string]string)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, group := range trader.GroupByAccount(traders) {
+		wg.Add(1)
+		go func(group []*trader.AutoTrader) {
+			defer wg.Done()
+			for _, at := range group {
+				results, err := at.CloseAllPositions(filter, reason)
+				mu.Lock()
+				report.TraderIDs = append(report.TraderIDs, at.GetID())
+				if err != nil {
+					log.Printf("❌ [%s] 批量平仓失败: %v", at.GetName(), err)
+					report.Errors[at.GetID()] = err.Error()
+				} else {
+					report.Results = append(report.Results, results...)
+				}
+				mu.Unlock()
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	sort.Strings(report.TraderIDs)
+	sort.Slice(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.TraderID != b.TraderID {
+			return a.TraderID < b.TraderID
+		}
+		return a.Symbol+a.Side < b.Symbol+b.Side
+	})
+	for _, r := range report.Results {
+		if r.Error == "" {
+			report.Closed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// SubmitCloseAll 人工渠道（HTTP、Telegram）发起批量平仓：符合条件持仓的名义价值合计达到两人规则阈值时需另一位运维人员确认
+// 需确认时返回 pending=true，确认后结果通过 /confirm 的回复返回；identity 为发起人的运维人员身份（见 OperatorIdentity）
+func (tm *TraderManager) SubmitCloseAll(traderIDs []string, filter trader.CloseFilter, source, operator, identity string) (*CloseAllReport, bool, error) {
+	filter, err := filter.Normalize()
+	if err != nil {
+		return nil, false, err
+	}
+	traders, err := tm.closeAllTraders(traderIDs)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(traders) == 0 {
+		return nil, false, fmt.Errorf("没有已加载的交易员")
+	}
+	ids := make([]string, 0, len(traders))
+	notional, unknown := 0.0, false
+	for _, at := range traders {
+		ids = append(ids, at.GetID())
+		positions, err := at.GetPositions()
+		if err != nil {
+			// 无法获取持仓时名义价值未知，按达到阈值处理
+			log.Printf("⚠️ [%s] 获取持仓失败，批量平仓名义价值未知: %v", at.GetID(), err)
+			unknown = true
+			continue
+		}
+		for _, pos := range positions {
+			symbol, _ := pos["symbol"].(string)
+			side, _ := pos["side"].(string)
+			if !filter.Match(symbol, side) {
+				continue
+			}
+			quantity, _ := pos["quantity"].(float64)
+			markPrice, _ := pos["mark_price"].(float64)
+			notional += math.Abs(quantity) * markPrice
+		}
+	}
+	sort.Strings(ids)
+
+	var report *CloseAllReport
+	_, pending, err := tm.SubmitManualOrder(&ManualOrder{
+		TraderIDs:       ids,
+		Symbol:          filter.String(),
+		Action:          "close_all",
+		NotionalUSD:     notional,
+		NotionalUnknown: unknown,
+		Source:          source,
+		Operator:        operator,
+		Identity:        identity,
+		Execute: func() (string, error) {
+			var err error
+			if report, err = tm.CloseAllPositions(ids, filter, source+"批量平仓"); err != nil {
+				return "", err
+			}
+			return FormatCloseAllReport(report), nil
+		},
+	})
+	return report, pending, err
+}
+
+// FormatCloseAllReport 批量平仓结果的文本形式（用于命令行和Telegram）
+func FormatCloseAllReport(report *CloseAllReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧹 批量平仓（%s）：%d 个交易员，成功 %d，失败 %d\n", report.Filter, len(report.TraderIDs), report.Closed, report.Failed)
+	for _, r := range report.Results {
+		status := "✅"
+		switch {
+		case r.Error != "":
+			status = "❌"
+		case r.DryRun:
+			status = "🧪"
+		}
+		fmt.Fprintf(&b, "%s %s %s %s %.4f", status, r.TraderID, r.Symbol, r.Side, r.Quantity)
+		if r.Error != "" {
+			fmt.Fprintf(&b, " - %s", r.Error)
+		}
+		b.WriteString("\n")
+	}
+	ids := make([]string, 0, len(report.Errors))
+	for id := range report.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(&b, "❌ %s: %s\n", id, report.Errors[id])
+	}
+	if len(report.Results) == 0 && len(report.Errors) == 0 {
+		b.WriteString("没有符合条件的持仓\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,16 @@
+package manager
+
+import (
+	"nofx/events"
+	"nofx/trader"
+	"strings"
+	"time"
+)
+
+// StartSpecRefresh 后台刷新各交易所共用的合约交易规则，价格步进、最小下单量等变化时发布事件，返回停止函数
+func (tm *TraderManager) StartSpecRefresh(interval time.Duration) func() {
+	return trader.StartSpecRefresh(interval, func(c trader.SpecChange) {
+		exchange, _, _ := strings.Cut(c.Exchange, "-") // gate-testnet -> gate
+		tm.eventBus.Publish(events.Event{Type: events.ContractSpecChanged, Exchange: exchange, Symbol: c.Symbol, Message: c.String()})
+	})
+}
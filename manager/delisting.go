@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/events"
+	"nofx/trader"
+	"strings"
+	"time"
+)
+
+// DelistingConfig 合约下架/交割处理配置
+type DelistingConfig struct {
+	CloseLead       time.Duration // 计划下架前多久强制平仓（<0=只告警不平仓）
+	MigrateTraderID string        // 平仓后在该交易员账户重新开立同向仓位（为空不迁移）
+	MigrateLeverage int           // 迁移仓位杠杆
+}
+
+// StartDelistingMonitor 定期检查持仓合约的下架/交割计划，发现时告警，临近结算前强制平仓（可选迁移），返回停止函数
+// 各交易员在决策周期内自行拒绝开仓，这里按账户统一处理持仓（同一账户的多个交易员只处理一次）
+func (tm *TraderManager) StartDelistingMonitor(interval time.Duration, cfg DelistingConfig) func() {
+	stop := make(chan struct{})
+	go func() {
+		alerted := make(map[string]bool) // 账户|合约
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			tm.checkDelistings(cfg, alerted, time.Now())
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("✓ 合约下架监控已启动（每 %s 检查一次）", interval)
+	return func() { close(stop) }
+}
+
+// checkDelistings 检查所有账户持仓的下架计划
+func (tm *TraderManager) checkDelistings(cfg DelistingConfig, alerted map[string]bool, now time.Time) {
+	accounts := make(map[string]bool)
+	held := make(map[string]bool)
+	for _, at := range tm.GetAllTraders() {
+		account := at.GetAccountKey()
+		if accounts[account] {
+			continue
+		}
+		accounts[account] = true
+
+		exchangeTrader := at.GetExchangeTrader()
+		delistings := trader.Delistings(exchangeTrader, at.GetExchange())
+		if len(delistings) == 0 {
+			continue
+		}
+		positions, err := exchangeTrader.GetPositions()
+		if err != nil {
+			log.Printf("⚠️  [下架监控] 获取 %s 持仓失败: %v", at.GetName(), err)
+			continue
+		}
+
+		for _, pos := range positions {
+			symbol, _ := pos["symbol"].(string)
+			info, ok := delistings[strings.ToUpper(strings.ReplaceAll(symbol, "_", ""))]
+			if !ok {
+				continue
+			}
+			side, _ := pos["side"].(string)
+			amt, _ := pos["positionAmt"].(float64)
+			key := account + "|" + symbol + "|" + side
+			held[key] = true
+
+			when := "时间未公布"
+			if !info.DelistingTime.IsZero() {
+				when = info.DelistingTime.Local().Format("01-02 15:04")
+			}
+			if !alerted[key] {
+				alerted[key] = true
+				tm.publishDelisting(at, symbol, fmt.Sprintf("📤 %s 持仓合约 %s 已安排下架/交割（%s），已暂停开仓", at.GetName(), symbol, when))
+			}
+
+			// 未公布时间视为随时可能结算，立即平仓
+			if cfg.CloseLead < 0 || (!info.DelistingTime.IsZero() && now.Before(info.DelistingTime.Add(-cfg.CloseLead))) {
+				continue
+			}
+			tm.closeDelisted(cfg, at, symbol, side, math.Abs(amt), when)
+		}
+	}
+	for key := range alerted {
+		if !held[key] {
+			delete(alerted, key)
+		}
+	}
+}
+
+// closeDelisted 强制平掉下架合约的持仓，配置了迁移交易员时在其账户重新开立同向仓位
+func (tm *TraderManager) closeDelisted(cfg DelistingConfig, at *trader.AutoTrader, symbol, side string, quantity float64, when string) {
+	if at.IsWatchOnly() {
+		tm.publishDelisting(at, symbol, fmt.Sprintf("⚠️ %s 合约 %s 将于 %s 下架/交割，观察模式不会强制平仓，请手动处理%s仓", at.GetName(), symbol, when, side))
+		return
+	}
+	if _, err := trader.PlaceOrder(at.GetExchangeTrader(), symbol, "close_"+side, quantity, 0); err != nil {
+		tm.publishDelisting(at, symbol, fmt.Sprintf("⚠️ %s 下架合约 %s %s仓强制平仓失败: %v", at.GetName(), symbol, side, err))
+		return
+	}
+	msg := fmt.Sprintf("📤 %s 合约 %s 将于 %s 下架/交割，已强制平掉%s仓 %.6f", at.GetName(), symbol, when, side, quantity)
+
+	if cfg.MigrateTraderID != "" && cfg.MigrateTraderID != at.GetID() {
+		if target, err := tm.GetTrader(cfg.MigrateTraderID); err != nil {
+			msg += fmt.Sprintf("；迁移失败: %v", err)
+		} else if target.IsWatchOnly() {
+			msg += fmt.Sprintf("；%s 为观察模式，未迁移", target.GetName())
+		} else if err := migratePosition(target.GetExchangeTrader(), symbol, side, quantity, cfg.MigrateLeverage); err != nil {
+			msg += fmt.Sprintf("；迁移到 %s 失败: %v", target.GetName(), err)
+		} else {
+			msg += fmt.Sprintf("，已迁移到 %s", target.GetName())
+		}
+	}
+	tm.publishDelisting(at, symbol, msg)
+}
+
+// migratePosition 在目标账户开立同向仓位
+func migratePosition(t trader.Trader, symbol, side string, quantity float64, leverage int) error {
+	symbol = strings.ReplaceAll(symbol, "_", "")
+	_, err := trader.PlaceOrder(t, symbol, "open_"+side, quantity, leverage)
+	return err
+}
+
+// publishDelisting 记录并发布下架事件
+func (tm *TraderManager) publishDelisting(at *trader.AutoTrader, symbol, msg string) {
+	log.Printf("%s", msg)
+	tm.eventBus.Publish(events.Event{
+		Type:     events.ContractDelisting,
+		TraderID: at.GetID(),
+		Exchange: at.GetExchange(),
+		Symbol:   symbol,
+		Message:  msg,
+	})
+}
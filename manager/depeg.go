@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/depeg"
+	"nofx/events"
+	"sort"
+	"time"
+)
+
+// StartDepegMonitor 定期检查稳定币价格，脱锚及恢复时发布告警事件，返回停止函数
+// 各交易员在决策周期内自行拒绝开仓/减仓，这里只负责统一告警（避免每个交易员重复通知）
+func (tm *TraderManager) StartDepegMonitor(interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		alerted := make(map[string]bool)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			statuses := depeg.Statuses()
+			coins := make([]string, 0, len(statuses))
+			for coin := range statuses {
+				coins = append(coins, coin)
+			}
+			sort.Strings(coins)
+
+			for _, coin := range coins {
+				s := statuses[coin]
+				if s.Alert == alerted[coin] {
+					continue
+				}
+				alerted[coin] = s.Alert
+				var msg string
+				if s.Alert {
+					msg = fmt.Sprintf("🪙 稳定币 %s 脱锚: %.4f USD（偏离 %.2f%%），已暂停开仓", coin, s.Price, s.DeviationPct)
+					if s.Reduce {
+						msg += "并减仓"
+					}
+				} else {
+					msg = fmt.Sprintf("🪙 稳定币 %s 已恢复锚定: %.4f USD，恢复开仓", coin, s.Price)
+				}
+				log.Printf("%s", msg)
+				tm.eventBus.Publish(events.Event{Type: events.StablecoinDepeg, Message: msg, Price: s.Price})
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("✓ 稳定币脱锚监控已启动（每 %s 检查一次）", interval)
+	return func() { close(stop) }
+}
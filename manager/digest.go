@@ -0,0 +1,267 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/calendar"
+	"nofx/config"
+	"nofx/events"
+	"nofx/export"
+	"nofx/fx"
+	"nofx/trader"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 业绩摘要周期
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// TraderDigest 单个交易员在摘要周期内的业绩
+type TraderDigest struct {
+	*trader.PerformanceSummary
+	Wins       int                  `json:"wins"`
+	Losses     int                  `json:"losses"`
+	WinRatePct float64              `json:"win_rate_pct"`    // 盈利平仓笔数 / 平仓笔数（无平仓时为0）
+	Best       *export.JournalTrade `json:"best,omitempty"`  // 净盈亏最高的已平仓交易
+	Worst      *export.JournalTrade `json:"worst,omitempty"` // 净盈亏最低的已平仓交易
+}
+
+// DigestSchedule 业绩摘要发布计划
+type DigestSchedule struct {
+	Daily   bool         // 发布日报
+	Weekly  bool         // 发布周报
+	Hour    int          // 发布时刻（交易日时区的小时，默认UTC）
+	Weekday time.Weekday // 周报发布日
+}
+
+// String 计划摘要
+func (s *DigestSchedule) String() string {
+	var parts []string
+	if s.Daily {
+		parts = append(parts, fmt.Sprintf("日报每天 %02d:00", s.Hour))
+	}
+	if s.Weekly {
+		parts = append(parts, fmt.Sprintf("周报每%s %02d:00", weekdayNames[s.Weekday], s.Hour))
+	}
+	if len(parts) > 0 {
+		parts[len(parts)-1] += fmt.Sprintf("（%s）", calendar.DayLocation())
+	}
+	return strings.Join(parts, "，")
+}
+
+var weekdayNames = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// ParseDigestSchedule 解析业绩摘要计划，空字符串返回nil（关闭）
+// 格式: daily / weekly 及可选参数，如 daily,weekly,hour=8,weekday=mon（hour默认0，weekday默认周一）
+func ParseDigestSchedule(spec string) (*DigestSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	s := &DigestSchedule{Weekday: time.Monday}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		key, value, _ := strings.Cut(item, "=")
+		switch key {
+		case "":
+		case DigestDaily:
+			s.Daily = true
+		case DigestWeekly:
+			s.Weekly = true
+		case "hour":
+			hour, err := strconv.Atoi(value)
+			if err != nil || hour < 0 || hour > 23 {
+				return nil, fmt.Errorf("无效的业绩摘要发布时刻: %s（交易日时区的小时 0-23）", value)
+			}
+			s.Hour = hour
+		case "weekday":
+			found := false
+			for d := time.Sunday; d <= time.Saturday; d++ {
+				name := strings.ToLower(d.String())
+				if value == name || value == name[:3] || value == strconv.Itoa(int(d)) {
+					s.Weekday, found = d, true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("无效的周报发布日: %s（如 monday、mon 或 0-6，0=周日）", value)
+			}
+		default:
+			return nil, fmt.Errorf("业绩摘要不支持参数: %s（可选: daily, weekly, hour, weekday）", item)
+		}
+	}
+	if !s.Daily && !s.Weekly {
+		return nil, fmt.Errorf("业绩摘要需要指定 daily 和/或 weekly")
+	}
+	return s, nil
+}
+
+// Digest 业绩摘要（日报/周报，金额均为USDT）
+type Digest struct {
+	Period       string         `json:"period"`
+	Since        time.Time      `json:"since"`
+	Until        time.Time      `json:"until"`
+	Traders      []TraderDigest `json:"traders"` // 按区间收益率排序
+	PnL          float64        `json:"pnl"`
+	Fees         float64        `json:"fees"`
+	FundingPnL   float64        `json:"funding_pnl"`
+	Trades       int            `json:"trades"`
+	WinRatePct   float64        `json:"win_rate_pct"`
+	Best         *TradeRef      `json:"best,omitempty"`
+	Worst        *TradeRef      `json:"worst,omitempty"`
+	DashboardURL string         `json:"dashboard_url,omitempty"`
+}
+
+// TradeRef 摘要中列出的单笔交易（附交易员名称）
+type TradeRef struct {
+	TraderName string `json:"trader_name"`
+	export.JournalTrade
+}
+
+// DigestSince 摘要周期的起点（按交易日时区的日历日回溯，夏令时切换当天的日报不是24小时）
+func DigestSince(period string, until time.Time) time.Time {
+	until = until.In(calendar.DayLocation())
+	if period == DigestWeekly {
+		return until.AddDate(0, 0, -7)
+	}
+	return until.AddDate(0, 0, -1)
+}
+
+// GetDigest 汇总各交易员在周期内的盈亏、手续费、资金费、胜率及最大盈利/亏损交易
+func (tm *TraderManager) GetDigest(store config.Store, period, dashboardURL string) *Digest {
+	until := time.Now()
+	digest := &Digest{Period: period, Since: DigestSince(period, until), Until: until, Traders: make([]TraderDigest, 0), DashboardURL: dashboardURL}
+	wins := 0
+	for _, s := range tm.GetLeaderboard(digest.Since) {
+		at, err := tm.GetTrader(s.TraderID)
+		if err != nil {
+			continue
+		}
+		td := TraderDigest{PerformanceSummary: s}
+		trades, err := ClosedTrades(store, at, digest.Since)
+		if err != nil {
+			log.Printf("⚠️ [业绩摘要] %s: %v", at.GetName(), err)
+		}
+		for i := range trades {
+			t := &trades[i]
+			if t.NetPnL > 0 {
+				td.Wins++
+			} else {
+				td.Losses++
+			}
+			if td.Best == nil || t.NetPnL > td.Best.NetPnL {
+				td.Best = t
+			}
+			if td.Worst == nil || t.NetPnL < td.Worst.NetPnL {
+				td.Worst = t
+			}
+		}
+		if n := td.Wins + td.Losses; n > 0 {
+			td.WinRatePct = float64(td.Wins) / float64(n) * 100
+		}
+		if td.Best != nil && (digest.Best == nil || td.Best.NetPnL > digest.Best.NetPnL) {
+			digest.Best = &TradeRef{TraderName: s.TraderName, JournalTrade: *td.Best}
+		}
+		if td.Worst != nil && (digest.Worst == nil || td.Worst.NetPnL < digest.Worst.NetPnL) {
+			digest.Worst = &TradeRef{TraderName: s.TraderName, JournalTrade: *td.Worst}
+		}
+
+		digest.PnL += s.PnL
+		digest.Fees += s.Fees
+		digest.FundingPnL += s.FundingPnL
+		digest.Trades += td.Wins + td.Losses
+		wins += td.Wins
+		digest.Traders = append(digest.Traders, td)
+	}
+	if digest.Trades > 0 {
+		digest.WinRatePct = float64(wins) / float64(digest.Trades) * 100
+	}
+	sort.SliceStable(digest.Traders, func(i, j int) bool { return digest.Traders[i].ReturnPct > digest.Traders[j].ReturnPct })
+	return digest
+}
+
+// Title 摘要标题（日期为周期覆盖的交易日：零点发布的日报是前一个交易日的日报）
+func (d *Digest) Title() string {
+	last := d.Until.Add(-time.Nanosecond)
+	if d.Period == DigestWeekly {
+		return fmt.Sprintf("📊 业绩周报 %s ~ %s", calendar.DayKey(d.Since)[5:], calendar.DayKey(last)[5:])
+	}
+	return fmt.Sprintf("📊 业绩日报 %s", calendar.DayKey(last))
+}
+
+// DashboardLink 仪表盘地址（未配置时为空）
+func (d *Digest) DashboardLink() string {
+	if d.DashboardURL == "" {
+		return ""
+	}
+	return strings.TrimRight(d.DashboardURL, "/") + "/#trader"
+}
+
+// FormatDigest 生成业绩摘要文本（用于通知）
+func FormatDigest(d *Digest) string {
+	quote := fx.Current()
+	var sb strings.Builder
+	sb.WriteString(d.Title())
+	if len(d.Traders) == 0 {
+		sb.WriteString("\n暂无交易员数据")
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("\n合计盈亏 %s | 手续费 -%s | 资金费 %s | 平仓 %d 笔 | 胜率 %.1f%%",
+		quote.FormatSigned(d.PnL), quote.Format(d.Fees), quote.FormatSigned(d.FundingPnL), d.Trades, d.WinRatePct))
+	if d.Best != nil && d.Best.NetPnL > 0 {
+		sb.WriteString(fmt.Sprintf("\n🥇 最大盈利: %s %s %s %s (%+.2f%%)", d.Best.TraderName, d.Best.Symbol, d.Best.Side, quote.FormatSigned(d.Best.NetPnL), d.Best.PnLPct))
+	}
+	if d.Worst != nil && d.Worst.NetPnL < 0 {
+		sb.WriteString(fmt.Sprintf("\n🩸 最大亏损: %s %s %s %s (%+.2f%%)", d.Worst.TraderName, d.Worst.Symbol, d.Worst.Side, quote.FormatSigned(d.Worst.NetPnL), d.Worst.PnLPct))
+	}
+	for _, t := range d.Traders {
+		sb.WriteString(fmt.Sprintf("\n• %s 收益 %+.2f%% (%s) | 平仓 %d 笔 胜率 %.1f%% | 手续费 -%s 资金费 %s",
+			t.TraderName, t.ReturnPct, quote.FormatSigned(t.PnL), t.Wins+t.Losses, t.WinRatePct, quote.Format(t.Fees), quote.FormatSigned(t.FundingPnL)))
+	}
+	if link := d.DashboardLink(); link != "" {
+		sb.WriteString("\n🔗 " + link)
+	}
+	return sb.String()
+}
+
+// StartPerformanceDigest 按计划在交易日时区的指定时刻发布业绩日报/周报，返回停止函数
+func (tm *TraderManager) StartPerformanceDigest(store config.Store, schedule *DigestSchedule, dashboardURL string) func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			now := time.Now()
+			next := calendar.NextAt(now, schedule.Hour) // 星期按交易日时区判断
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			var periods []string
+			if schedule.Daily {
+				periods = append(periods, DigestDaily)
+			}
+			if schedule.Weekly && next.Weekday() == schedule.Weekday {
+				periods = append(periods, DigestWeekly)
+			}
+			for _, period := range periods {
+				digest := tm.GetDigest(store, period, dashboardURL)
+				if len(digest.Traders) == 0 {
+					continue
+				}
+				summary := FormatDigest(digest)
+				log.Printf("%s", summary)
+				tm.eventBus.Publish(events.Event{Type: events.PerformanceDigest, Action: period, Message: summary, Payload: digest})
+			}
+		}
+	}()
+	log.Printf("✓ 业绩摘要: %s", schedule)
+	return func() { close(stop) }
+}
@@ -0,0 +1,43 @@
+package manager
+
+import (
+	"log"
+	"nofx/config"
+	"nofx/trader"
+	"strings"
+)
+
+// applyEnsemble 为交易员配置多模型投票（附加模型须属于同一用户且已启用）
+func (tm *TraderManager) applyEnsemble(traderCfg *config.TraderRecord, aiModels []*config.AIModelConfig) {
+	at, ok := tm.traders[traderCfg.ID]
+	if !ok || strings.TrimSpace(traderCfg.EnsembleModelIDs) == "" {
+		return
+	}
+
+	var members []trader.EnsembleModel
+	for _, id := range strings.Split(traderCfg.EnsembleModelIDs, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || id == traderCfg.AIModelID {
+			continue
+		}
+		var model *config.AIModelConfig
+		for _, m := range aiModels {
+			if m.ID == id {
+				model = m
+				break
+			}
+		}
+		if model == nil || !model.Enabled {
+			log.Printf("⚠️  交易员 %s 的投票模型 %s 不存在或未启用，跳过", traderCfg.Name, id)
+			continue
+		}
+		members = append(members, trader.EnsembleModel{
+			Name:            model.Name,
+			Provider:        model.Provider,
+			APIKey:          model.APIKey,
+			CustomAPIURL:    model.CustomAPIURL,
+			CustomModelName: model.CustomModelName,
+		})
+	}
+	at.SetEnsemble(members, traderCfg.EnsembleMode)
+}
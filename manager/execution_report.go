@@ -0,0 +1,198 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/calendar"
+	"nofx/config"
+	"nofx/events"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"time"
+)
+
+// storeExecutionRecorder 把开仓执行结果写入数据库
+type storeExecutionRecorder struct {
+	store config.Store
+}
+
+// NewExecutionRecorder 创建写入数据库的执行质量记录通道
+func NewExecutionRecorder(store config.Store) trader.ExecutionRecorder {
+	return &storeExecutionRecorder{store: store}
+}
+
+// RecordExecution 实现 trader.ExecutionRecorder
+func (r *storeExecutionRecorder) RecordExecution(traderID, exchange string, result *trader.ExecutionResult, execErr error) {
+	rec := &config.ExecutionRecord{
+		TraderID:     traderID,
+		Exchange:     exchange,
+		Symbol:       result.Symbol,
+		Side:         result.Side,
+		Policy:       result.Policy,
+		RequestedQty: result.Requested,
+		FilledQty:    result.Filled,
+		MakerQty:     result.MakerQty(),
+		ArrivalPrice: result.ArrivalPrice,
+		AvgPrice:     result.AvgPrice,
+		SlippageBps:  result.SlippageBps(),
+		DurationMs:   result.Duration().Milliseconds(),
+	}
+	if execErr != nil {
+		rec.Error = execErr.Error()
+	}
+	if err := r.store.RecordExecution(rec); err != nil {
+		log.Printf("⚠️ 记录执行质量失败 (%s %s): %v", traderID, result.Symbol, err)
+	}
+}
+
+// ExecutionStats 一组开仓执行的质量统计
+type ExecutionStats struct {
+	TraderID         string  `json:"trader_id,omitempty"`
+	Exchange         string  `json:"exchange"`
+	Policy           string  `json:"policy"`
+	Executions       int     `json:"executions"`
+	Failed           int     `json:"failed"`               // 一笔都未成交的执行
+	FillRatePct      float64 `json:"fill_rate_pct"`        // 成交数量占请求数量的平均百分比
+	AvgTimeToFillSec float64 `json:"avg_time_to_fill_sec"` // 有成交的执行平均耗时
+	AvgSlippageBps   float64 `json:"avg_slippage_bps"`     // 按成交名义价值加权的平均滑点（正数=不利）
+	SlippageCostUSD  float64 `json:"slippage_cost_usd"`    // 滑点造成的成本（负数=价格改善）
+	MakerRatioPct    float64 `json:"maker_ratio_pct"`      // 以maker成交的名义价值占比
+	FilledNotional   float64 `json:"filled_notional"`
+}
+
+// ExecutionReport 执行质量报告
+type ExecutionReport struct {
+	Since    time.Time         `json:"since"`
+	ByTrader []*ExecutionStats `json:"by_trader"` // 按交易员、交易所、执行算法分组
+	ByPolicy []*ExecutionStats `json:"by_policy"` // 按交易所、执行算法分组（比较执行算法）
+}
+
+// BuildExecutionReport 统计执行质量记录
+func BuildExecutionReport(since time.Time, records []*config.ExecutionRecord) *ExecutionReport {
+	return &ExecutionReport{
+		Since: since,
+		ByTrader: aggregateExecutions(records, func(rec *config.ExecutionRecord) ExecutionStats {
+			return ExecutionStats{TraderID: rec.TraderID, Exchange: rec.Exchange, Policy: rec.Policy}
+		}),
+		ByPolicy: aggregateExecutions(records, func(rec *config.ExecutionRecord) ExecutionStats {
+			return ExecutionStats{Exchange: rec.Exchange, Policy: rec.Policy}
+		}),
+	}
+}
+
+// aggregateExecutions 按group返回的分组键（TraderID/Exchange/Policy）汇总执行质量
+func aggregateExecutions(records []*config.ExecutionRecord, group func(*config.ExecutionRecord) ExecutionStats) []*ExecutionStats {
+	type accumulator struct {
+		stats       *ExecutionStats
+		fillRateSum float64
+		durationSum float64
+		filled      int
+		slippageSum float64 // 滑点 × 成交名义价值
+		makerSum    float64
+	}
+	groups := make(map[ExecutionStats]*accumulator)
+	var order []ExecutionStats
+	for _, rec := range records {
+		key := group(rec)
+		acc, ok := groups[key]
+		if !ok {
+			stats := key
+			acc = &accumulator{stats: &stats}
+			groups[key] = acc
+			order = append(order, key)
+		}
+		acc.stats.Executions++
+		if rec.RequestedQty > 0 {
+			acc.fillRateSum += rec.FilledQty / rec.RequestedQty
+		}
+		if rec.FilledQty <= 0 {
+			acc.stats.Failed++
+			continue
+		}
+		notional := rec.FilledQty * rec.AvgPrice
+		acc.filled++
+		acc.durationSum += float64(rec.DurationMs) / 1000
+		acc.slippageSum += rec.SlippageBps * notional
+		acc.makerSum += rec.MakerQty * rec.AvgPrice
+		acc.stats.FilledNotional += notional
+	}
+
+	result := make([]*ExecutionStats, 0, len(order))
+	for _, key := range order {
+		acc := groups[key]
+		s := acc.stats
+		s.FillRatePct = acc.fillRateSum / float64(s.Executions) * 100
+		if acc.filled > 0 {
+			s.AvgTimeToFillSec = acc.durationSum / float64(acc.filled)
+		}
+		if s.FilledNotional > 0 {
+			s.AvgSlippageBps = acc.slippageSum / s.FilledNotional
+			s.SlippageCostUSD = acc.slippageSum / 1e4
+			s.MakerRatioPct = acc.makerSum / s.FilledNotional * 100
+		}
+		result = append(result, s)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Exchange != result[j].Exchange {
+			return result[i].Exchange < result[j].Exchange
+		}
+		return result[i].AvgSlippageBps < result[j].AvgSlippageBps
+	})
+	return result
+}
+
+// GetExecutionReport 统计since之后的执行质量（traderIDs为空时统计全部交易员）
+func GetExecutionReport(store config.Store, since time.Time, traderIDs ...string) (*ExecutionReport, error) {
+	records, err := store.GetExecutionRecords(since, traderIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("获取执行质量记录失败: %w", err)
+	}
+	return BuildExecutionReport(since, records), nil
+}
+
+// FormatExecutionReport 生成执行质量报告文本（用于通知，按交易所比较各执行算法）
+func FormatExecutionReport(title string, report *ExecutionReport) string {
+	var sb strings.Builder
+	sb.WriteString(title)
+	if len(report.ByPolicy) == 0 {
+		sb.WriteString("\n暂无开仓执行记录")
+		return sb.String()
+	}
+	for _, s := range report.ByPolicy {
+		sb.WriteString(fmt.Sprintf("\n%s/%s: %d 笔（失败 %d）| 成交率 %.1f%% | 平均耗时 %.1fs | 滑点 %+.2f bps (%+.2f USDT) | maker %.1f%%",
+			s.Exchange, s.Policy, s.Executions, s.Failed, s.FillRatePct, s.AvgTimeToFillSec, s.AvgSlippageBps, s.SlippageCostUSD, s.MakerRatioPct))
+	}
+	return sb.String()
+}
+
+// StartDailyExecutionReport 每天在交易日时区的指定时刻发布过去24小时的执行质量报告，返回停止函数
+func (tm *TraderManager) StartDailyExecutionReport(store config.Store, hour int) func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			now := time.Now()
+			timer := time.NewTimer(calendar.NextAt(now, hour).Sub(now))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			report, err := GetExecutionReport(store, time.Now().Add(-24*time.Hour))
+			if err != nil {
+				log.Printf("⚠️ 生成执行质量报告失败: %v", err)
+				continue
+			}
+			if len(report.ByPolicy) == 0 {
+				continue
+			}
+			summary := FormatExecutionReport("📐 过去24小时开仓执行质量", report)
+			log.Printf("%s", summary)
+			tm.eventBus.Publish(events.Event{Type: events.ExecutionReport, Message: summary})
+		}
+	}()
+	log.Printf("✓ 每日执行质量报告将于 %02d:00（%s）发布", hour, calendar.DayLocation())
+	return func() { close(stop) }
+}
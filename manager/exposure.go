@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"log"
+	"nofx/trader"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SymbolExposure 按交易对汇总的敞口（跨交易员、跨交易所）
+type SymbolExposure struct {
+	Symbol            string  `json:"symbol"`
+	LongNotional      float64 `json:"long_notional"`
+	ShortNotional     float64 `json:"short_notional"` // 空头名义价值（正数）
+	NetNotional       float64 `json:"net_notional"`
+	MinLiqDistancePct float64 `json:"min_liq_distance_pct"` // 该交易对所有持仓中最近的强平距离（0=均无强平价格）
+	VaR95             float64 `json:"var_95"`               // 各持仓VaR之和
+}
+
+// InstanceExposure 本实例的敞口快照：各交易员明细及按交易对汇总
+type InstanceExposure struct {
+	InstanceID    string                     `json:"instance_id"`
+	GeneratedAt   time.Time                  `json:"generated_at"`
+	Equity        float64                    `json:"equity"`
+	GrossExposure float64                    `json:"gross_exposure"`
+	NetExposure   float64                    `json:"net_exposure"`
+	VaR95         float64                    `json:"var_95"` // 各账户VaR之和（不考虑账户间分散效应，偏保守）
+	VaR99         float64                    `json:"var_99"`
+	Symbols       []SymbolExposure           `json:"symbols"`
+	Traders       []*trader.ExposureSnapshot `json:"traders"`
+	Errors        map[string]string          `json:"errors,omitempty"` // 获取失败的交易员ID及原因
+}
+
+// SetInstanceID 设置敞口快照中的实例标识（为空时使用主机名）
+func (tm *TraderManager) SetInstanceID(id string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.instanceID = strings.TrimSpace(id)
+}
+
+// InstanceID 实例标识
+func (tm *TraderManager) InstanceID() string {
+	tm.mu.RLock()
+	id := tm.instanceID
+	tm.mu.RUnlock()
+	if id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "nofx"
+	}
+	return hostname
+}
+
+// GetExposure 实时查询交易员持仓并生成敞口快照（traderIDs为空时统计全部已加载的交易员）
+// 共享同一交易所账户的交易员只查询一次，其余交易员记录在 SharedWith 中
+func (tm *TraderManager) GetExposure(traderIDs ...string) *InstanceExposure {
+	tm.mu.RLock()
+	traders := make([]*trader.AutoTrader, 0, len(tm.traders))
+	if len(traderIDs) == 0 {
+		for _, t := range tm.traders {
+			traders = append(traders, t)
+		}
+	} else {
+		for _, id := range traderIDs {
+			if t, ok := tm.traders[id]; ok {
+				traders = append(traders, t)
+			}
+		}
+	}
+	tm.mu.RUnlock()
+	sort.Slice(traders, func(i, j int) bool { return traders[i].GetID() < traders[j].GetID() })
+
+	exposure := &InstanceExposure{
+		InstanceID:  tm.InstanceID(),
+		GeneratedAt: time.Now(),
+		Symbols:     make([]SymbolExposure, 0),
+		Traders:     make([]*trader.ExposureSnapshot, 0, len(traders)),
+	}
+	accounts := make(map[string]*trader.ExposureSnapshot)
+	symbols := make(map[string]*SymbolExposure)
+	for _, at := range traders {
+		account := at.GetAccountKey()
+		if primary, ok := accounts[account]; ok {
+			primary.SharedWith = append(primary.SharedWith, at.GetID())
+			continue
+		}
+		snapshot, err := at.GetExposureSnapshot()
+		if err != nil {
+			log.Printf("⚠️ 获取交易员 %s 敞口失败: %v", at.GetID(), err)
+			if exposure.Errors == nil {
+				exposure.Errors = make(map[string]string)
+			}
+			exposure.Errors[at.GetID()] = err.Error()
+			continue
+		}
+		accounts[account] = snapshot
+		exposure.Traders = append(exposure.Traders, snapshot)
+
+		exposure.Equity += snapshot.Equity
+		exposure.GrossExposure += snapshot.GrossExposure
+		exposure.NetExposure += snapshot.NetExposure
+		exposure.VaR95 += snapshot.VaR95
+		exposure.VaR99 += snapshot.VaR99
+		for _, p := range snapshot.Positions {
+			symbol := strings.ToUpper(strings.ReplaceAll(p.Symbol, "_", ""))
+			s, ok := symbols[symbol]
+			if !ok {
+				s = &SymbolExposure{Symbol: symbol}
+				symbols[symbol] = s
+			}
+			if p.Notional >= 0 {
+				s.LongNotional += p.Notional
+			} else {
+				s.ShortNotional -= p.Notional
+			}
+			s.NetNotional += p.Notional
+			s.VaR95 += p.VaR95
+			if p.LiquidationPrice > 0 && (s.MinLiqDistancePct == 0 || p.LiqDistancePct < s.MinLiqDistancePct) {
+				s.MinLiqDistancePct = p.LiqDistancePct
+			}
+		}
+	}
+
+	for _, s := range symbols {
+		exposure.Symbols = append(exposure.Symbols, *s)
+	}
+	sort.Slice(exposure.Symbols, func(i, j int) bool {
+		return exposure.Symbols[i].LongNotional+exposure.Symbols[i].ShortNotional >
+			exposure.Symbols[j].LongNotional+exposure.Symbols[j].ShortNotional
+	})
+	return exposure
+}
@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/market"
+	"time"
+)
+
+// fundingBackfill 首次记录某交易对时回补的资金费率历史
+const fundingBackfill = 30 * 24 * time.Hour
+
+// StartFundingRecorder 定期保存交易对已结算的资金费率（首次回补最近30天），供期现套利及报表统计，返回停止函数
+func (tm *TraderManager) StartFundingRecorder(store config.Store, symbols []string, interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for _, symbol := range symbols {
+				if err := RecordFundingRates(store, symbol); err != nil {
+					log.Printf("⚠️  记录 %s 资金费率失败: %v", symbol, err)
+				}
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("✓ 资金费率记录已启动: %v（每 %s 同步一次）", symbols, interval)
+	return func() { close(stop) }
+}
+
+// RecordFundingRates 从交易所拉取上次记录之后的资金费率并保存
+func RecordFundingRates(store config.Store, symbol string) error {
+	latest, err := store.GetLatestFundingTime(symbol)
+	if err != nil {
+		return fmt.Errorf("查询最近结算时间失败: %w", err)
+	}
+	start := time.Now().Add(-fundingBackfill)
+	if !latest.IsZero() {
+		start = latest.Add(time.Millisecond)
+	}
+	rates, err := market.GetFundingHistory(symbol, start)
+	if err != nil {
+		return err
+	}
+	saved, err := store.SaveFundingRates(rates)
+	if err != nil {
+		return fmt.Errorf("保存资金费率失败: %w", err)
+	}
+	if saved > 0 && latest.IsZero() {
+		log.Printf("💸 已回补 %s 资金费率 %d 条", symbol, saved)
+	}
+	return nil
+}
+
+// GetFundingStats 统计交易对since之后的资金费率（平均费率、年化、累计收取）
+func GetFundingStats(store config.Store, symbol string, since time.Time) (market.FundingStats, []market.FundingRate, error) {
+	rates, err := store.GetFundingRates(symbol, since)
+	if err != nil {
+		return market.FundingStats{}, nil, fmt.Errorf("获取资金费率历史失败: %w", err)
+	}
+	return market.ComputeFundingStats(symbol, rates), rates, nil
+}
@@ -0,0 +1,159 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/fx"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// heartbeatLookback 查找最近成交及统计决策周期时读取的决策记录数
+const heartbeatLookback = 500
+
+// TraderHeartbeat 单个交易员的存活摘要
+type TraderHeartbeat struct {
+	TraderID      string    `json:"trader_id"`
+	Name          string    `json:"name"`
+	Running       bool      `json:"running"`
+	PausedUntil   time.Time `json:"paused_until,omitempty"`
+	Equity        float64   `json:"equity"`
+	OpenPositions int       `json:"open_positions"`
+	LastTradeTime time.Time `json:"last_trade_time,omitempty"` // 最近一次成功开/平仓（零值=近期无成交）
+	Cycles        int       `json:"cycles"`                    // 上次心跳以来的决策周期数
+	Errors        int       `json:"errors"`                    // 上次心跳以来的执行错误数
+	AccountError  string    `json:"account_error,omitempty"`   // 获取账户/持仓失败的原因
+}
+
+// heartbeatErrors 统计各交易员两次心跳之间的错误事件
+type heartbeatErrors struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (h *heartbeatErrors) handle(event events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[event.TraderID]++
+}
+
+// take 取出并清零错误计数
+func (h *heartbeatErrors) take() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	counts := h.counts
+	h.counts = make(map[string]int)
+	return counts
+}
+
+// GetHeartbeat 各交易员的存活摘要（按ID排序），since为统计决策周期的起点
+func (tm *TraderManager) GetHeartbeat(since time.Time, errorCounts map[string]int) []TraderHeartbeat {
+	all := tm.GetAllTraders()
+	beats := make([]TraderHeartbeat, 0, len(all))
+	for _, at := range all {
+		beat := TraderHeartbeat{TraderID: at.GetID(), Name: at.GetName(), PausedUntil: at.PausedUntil(), Errors: errorCounts[at.GetID()]}
+		if running, _ := at.GetStatus()["is_running"].(bool); running {
+			beat.Running = true
+		}
+		if account, err := at.GetAccountInfo(); err != nil {
+			beat.AccountError = err.Error()
+		} else {
+			beat.Equity, _ = account["total_equity"].(float64)
+		}
+		if positions, err := at.GetPositions(); err != nil {
+			beat.AccountError = err.Error()
+		} else {
+			beat.OpenPositions = len(positions)
+		}
+		beat.LastTradeTime, beat.Cycles = recentActivity(at, since)
+		beats = append(beats, beat)
+	}
+	sort.Slice(beats, func(i, j int) bool { return beats[i].TraderID < beats[j].TraderID })
+	return beats
+}
+
+// recentActivity 从决策日志中查找最近一次成功下单的时间及since之后的决策周期数
+func recentActivity(at *trader.AutoTrader, since time.Time) (time.Time, int) {
+	records, err := at.GetDecisionLogger().GetLatestRecords(heartbeatLookback)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	var lastTrade time.Time
+	cycles := 0
+	for _, record := range records {
+		if record.Timestamp.After(since) {
+			cycles++
+		}
+		for _, d := range record.Decisions {
+			if d.Success && !d.DryRun && (strings.HasPrefix(d.Action, "open_") || strings.HasPrefix(d.Action, "close_")) && d.Timestamp.After(lastTrade) {
+				lastTrade = d.Timestamp
+			}
+		}
+	}
+	return lastTrade, cycles
+}
+
+// FormatHeartbeat 心跳通知文本
+func FormatHeartbeat(title string, beats []TraderHeartbeat) string {
+	quote := fx.Current()
+	var sb strings.Builder
+	sb.WriteString(title)
+	for _, b := range beats {
+		state := "▶️"
+		switch {
+		case !b.PausedUntil.IsZero():
+			state = "⏸"
+		case !b.Running:
+			state = "⏹"
+		}
+		sb.WriteString(fmt.Sprintf("\n%s %s (%s)", state, b.TraderID, b.Name))
+		if b.AccountError != "" {
+			sb.WriteString(" ⚠️ 账户查询失败: " + b.AccountError)
+		} else {
+			sb.WriteString(fmt.Sprintf(" 净值 %s | 持仓 %d", quote.Format(b.Equity), b.OpenPositions))
+		}
+		lastTrade := "无"
+		if !b.LastTradeTime.IsZero() {
+			lastTrade = b.LastTradeTime.Format("01-02 15:04")
+		}
+		sb.WriteString(fmt.Sprintf(" | 最近成交 %s | 周期 %d | 错误 %d", lastTrade, b.Cycles, b.Errors))
+	}
+	if len(beats) == 0 {
+		sb.WriteString("\n没有已加载的交易员")
+	}
+	return sb.String()
+}
+
+// StartHeartbeat 定期发布存活通知（净值、持仓数、最近成交时间、错误数），用于区分"没有交易机会"与"程序已停止"，返回停止函数
+func (tm *TraderManager) StartHeartbeat(interval time.Duration) func() {
+	counter := &heartbeatErrors{counts: make(map[string]int)}
+	unsubscribe := tm.eventBus.Subscribe("heartbeat", counter.handle, events.Error)
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		since := time.Now()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			now := time.Now()
+			summary := FormatHeartbeat(fmt.Sprintf("💓 %s 运行中（过去 %s）", tm.InstanceID(), interval), tm.GetHeartbeat(since, counter.take()))
+			since = now
+			log.Printf("%s", summary)
+			tm.eventBus.Publish(events.Event{Type: events.Heartbeat, Message: summary})
+		}
+	}()
+	log.Printf("✓ 存活通知每 %s 发布一次", interval)
+	return func() {
+		close(stop)
+		unsubscribe()
+	}
+}
@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"nofx/config"
+	"nofx/jobs"
+	"nofx/market"
+	"nofx/trader"
+	"strings"
+	"time"
+)
+
+// 可通过API提交的后台任务类型
+const (
+	JobSpecRefresh     = "spec_refresh"     // 重新加载交易所合约交易规则
+	JobFundingHistory  = "funding_history"  // 下载交易对资金费率历史（首次回补最近30天）
+	JobRiskReport      = "risk_report"      // 重新生成交易员风险报告
+	JobExecutionReport = "execution_report" // 生成开仓执行质量报告
+)
+
+// JobRequest 提交后台任务的参数
+type JobRequest struct {
+	Type      string
+	Owner     string            // 提交任务的用户
+	Symbol    string            // funding_history
+	TraderID  string            // risk_report
+	TraderIDs []string          // execution_report 的统计范围
+	Since     time.Time         // execution_report 的统计起点
+	Params    map[string]string // 原始请求参数（记录在任务中）
+}
+
+// SetJobQueue 设置后台任务队列（交易规则刷新、风险报告等慢操作改为后台执行）
+func (tm *TraderManager) SetJobQueue(q *jobs.Queue) {
+	tm.mu.Lock()
+	tm.jobs = q
+	tm.mu.Unlock()
+	trader.SetJobQueue(q)
+}
+
+// JobQueue 后台任务队列（未启动时为nil）
+func (tm *TraderManager) JobQueue() *jobs.Queue {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.jobs
+}
+
+// SubmitJob 提交后台任务，同类型同参数的任务排队或执行中时返回已有任务
+func (tm *TraderManager) SubmitJob(store config.Store, req JobRequest) (jobs.Job, error) {
+	q := tm.JobQueue()
+	if q == nil {
+		return jobs.Job{}, fmt.Errorf("后台任务队列未启动")
+	}
+
+	var key string
+	var fn jobs.Func
+	switch req.Type {
+	case JobSpecRefresh:
+		key = JobSpecRefresh
+		fn = func(ctx context.Context) (interface{}, error) {
+			n, err := trader.RefreshSharedSpecs(ctx)
+			return map[string]int{"exchanges": n}, err
+		}
+	case JobFundingHistory:
+		if strings.TrimSpace(req.Symbol) == "" {
+			return jobs.Job{}, fmt.Errorf("缺少symbol参数")
+		}
+		symbol := market.Normalize(req.Symbol)
+		key = JobFundingHistory + ":" + symbol
+		fn = func(context.Context) (interface{}, error) {
+			if err := RecordFundingRates(store, symbol); err != nil {
+				return nil, err
+			}
+			stats, _, err := GetFundingStats(store, symbol, time.Now().Add(-fundingBackfill))
+			return stats, err
+		}
+	case JobRiskReport:
+		at, err := tm.GetTrader(req.TraderID)
+		if err != nil {
+			return jobs.Job{}, err
+		}
+		key = JobRiskReport + ":" + at.GetID()
+		fn = func(context.Context) (interface{}, error) {
+			return at.GetRiskReport(true)
+		}
+	case JobExecutionReport:
+		traderIDs, since := req.TraderIDs, req.Since
+		key = fmt.Sprintf("%s:%s:%s", JobExecutionReport, req.Owner, req.Params["period"])
+		fn = func(context.Context) (interface{}, error) {
+			if len(traderIDs) == 0 {
+				return BuildExecutionReport(since, nil), nil
+			}
+			return GetExecutionReport(store, since, traderIDs...)
+		}
+	default:
+		return jobs.Job{}, fmt.Errorf("不支持的任务类型: %s（可选: %s, %s, %s, %s）", req.Type,
+			JobSpecRefresh, JobFundingHistory, JobRiskReport, JobExecutionReport)
+	}
+	return q.Submit(jobs.Job{Type: req.Type, Key: key, Owner: req.Owner, Params: req.Params}, fn)
+}
@@ -0,0 +1,116 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/export"
+	"nofx/logger"
+	"nofx/trader"
+	"sort"
+	"time"
+)
+
+const (
+	// journalReplayLookback 重建已平仓交易时读取的决策记录数（3分钟周期约20天）
+	journalReplayLookback = 10000
+	// journalCursorKey 各交易员已推送到交易日志webhook的最后平仓时间（系统配置，JSON）
+	journalCursorKey = "journal_export_cursor"
+)
+
+// ClosedTrades 交易员since之后平仓的交易（按平仓时间升序，附带复盘笔记）
+func ClosedTrades(store config.Store, at *trader.AutoTrader, since time.Time) ([]export.JournalTrade, error) {
+	lifecycles, err := at.GetDecisionLogger().ReplayPositions(journalReplayLookback)
+	if err != nil {
+		return nil, fmt.Errorf("重建持仓历史失败: %w", err)
+	}
+	closed := make([]*logger.PositionLifecycle, 0, len(lifecycles))
+	for _, lc := range lifecycles {
+		if lc.Status == "closed" && lc.CloseTime.After(since) {
+			closed = append(closed, lc)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].CloseTime.Before(closed[j].CloseTime) })
+
+	notes := make(map[string][]logger.TradeNote)
+	if len(closed) > 0 {
+		records, err := store.GetTradeNotes(at.GetID(), "")
+		if err != nil {
+			log.Printf("⚠️  获取 %s 复盘笔记失败: %v", at.GetName(), err)
+		}
+		for _, n := range records {
+			notes[n.PositionID] = append(notes[n.PositionID], logger.TradeNote{ID: n.ID, PositionID: n.PositionID, Author: n.Author, Note: n.Note, CreatedAt: n.CreatedAt})
+		}
+	}
+
+	trades := make([]export.JournalTrade, 0, len(closed))
+	for _, lc := range closed {
+		lc.Notes = notes[lc.ID]
+		trades = append(trades, export.NewJournalTrade(at.GetID(), at.GetName(), at.GetExchange(), lc))
+	}
+	return trades, nil
+}
+
+// StartJournalExport 定期把新平仓的交易推送到交易日志webhook，返回停止函数
+// 推送进度保存在系统配置中，重启后从上次位置继续；首次启动只推送之后平仓的交易（历史交易可通过API下载CSV导入）
+func (tm *TraderManager) StartJournalExport(store config.Store, webhook *export.JournalWebhook, interval time.Duration) func() {
+	cursor := make(map[string]time.Time)
+	if raw, _ := store.GetSystemConfig(journalCursorKey); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cursor); err != nil {
+			log.Printf("⚠️  解析交易日志推送进度失败，从当前时间开始: %v", err)
+		}
+	}
+	started := time.Now()
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if tm.pushClosedTrades(store, webhook, cursor, started) {
+				if data, err := json.Marshal(cursor); err == nil {
+					if err := store.SetSystemConfig(journalCursorKey, string(data)); err != nil {
+						log.Printf("⚠️  保存交易日志推送进度失败: %v", err)
+					}
+				}
+			}
+		}
+	}()
+	log.Printf("📒 已平仓交易推送到 %s（每 %s 检查一次）", webhook.Name(), interval)
+	return func() { close(stop) }
+}
+
+// pushClosedTrades 按平仓时间顺序推送各交易员新平仓的交易，某笔失败时该交易员停止推送、下次重试，返回进度是否有更新
+func (tm *TraderManager) pushClosedTrades(store config.Store, webhook *export.JournalWebhook, cursor map[string]time.Time, started time.Time) bool {
+	updated := false
+	for id, at := range tm.GetAllTraders() {
+		since, ok := cursor[id]
+		if !ok {
+			since = started
+		}
+		trades, err := ClosedTrades(store, at, since)
+		if err != nil {
+			log.Printf("⚠️  [交易日志] %s: %v", at.GetName(), err)
+			continue
+		}
+		for _, trade := range trades {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			err := webhook.Push(ctx, trade)
+			cancel()
+			if err != nil {
+				log.Printf("⚠️  [交易日志] 推送 %s %s 失败（下次重试）: %v", at.GetName(), trade.PositionID, err)
+				break
+			}
+			cursor[id] = trade.ExitTime
+			updated = true
+		}
+	}
+	return updated
+}
@@ -0,0 +1,96 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/calendar"
+	"nofx/events"
+	"nofx/fx"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GetLeaderboard 统计since之后各交易员的表现并按收益率排序（traderIDs为空时统计全部已加载的交易员）
+func (tm *TraderManager) GetLeaderboard(since time.Time, traderIDs ...string) []*trader.PerformanceSummary {
+	tm.mu.RLock()
+	traders := make([]*trader.AutoTrader, 0, len(tm.traders))
+	if len(traderIDs) == 0 {
+		for _, t := range tm.traders {
+			traders = append(traders, t)
+		}
+	} else {
+		for _, id := range traderIDs {
+			if t, ok := tm.traders[id]; ok {
+				traders = append(traders, t)
+			}
+		}
+	}
+	tm.mu.RUnlock()
+
+	board := make([]*trader.PerformanceSummary, 0, len(traders))
+	for _, t := range traders {
+		summary, err := t.GetPerformanceSummary(since)
+		if err != nil {
+			log.Printf("⚠️ 统计交易员 %s 表现失败: %v", t.GetID(), err)
+			continue
+		}
+		board = append(board, summary)
+	}
+	sort.SliceStable(board, func(i, j int) bool { return board[i].ReturnPct > board[j].ReturnPct })
+	return board
+}
+
+// FormatLeaderboard 生成排行榜文本（用于通知，金额按各条目的币种显示）
+func FormatLeaderboard(title string, board []*trader.PerformanceSummary) string {
+	var sb strings.Builder
+	sb.WriteString(title)
+	if len(board) == 0 {
+		sb.WriteString("\n暂无交易员数据")
+		return sb.String()
+	}
+	for i, s := range board {
+		name := s.TraderName
+		if s.DryRun {
+			name += "(预演)"
+		}
+		sb.WriteString(fmt.Sprintf("\n%d. %s 收益 %+.2f%% (%+.2f %s) | 最大回撤 %.2f%% | 平仓 %d 笔 | 价格 %+.2f 资金费 %+.2f 手续费 -%.2f",
+			i+1, name, s.ReturnPct, s.PnL, s.Currency, s.MaxDrawdownPct, s.Trades, s.PricePnL, s.FundingPnL, s.Fees))
+		if s.LLMCostUSD > 0 {
+			sb.WriteString(fmt.Sprintf(" | AI成本 $%.2f", s.LLMCostUSD))
+		}
+	}
+	return sb.String()
+}
+
+// StartDailyLeaderboard 每天在交易日时区的指定时刻发布过去24小时的排行榜通知，返回停止函数
+func (tm *TraderManager) StartDailyLeaderboard(hour int) func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			now := time.Now()
+			timer := time.NewTimer(calendar.NextAt(now, hour).Sub(now))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			board := tm.GetLeaderboard(time.Now().Add(-24 * time.Hour))
+			if len(board) == 0 {
+				continue
+			}
+			quote := fx.Current()
+			for i, s := range board {
+				board[i] = s.InQuote(quote)
+			}
+			summary := FormatLeaderboard("🏆 过去24小时策略排行榜", board)
+			log.Printf("%s", summary)
+			tm.eventBus.Publish(events.Event{Type: events.LeaderboardSummary, Message: summary})
+		}
+	}()
+	log.Printf("✓ 每日策略排行榜将于 %02d:00（%s）发布", hour, calendar.DayLocation())
+	return func() { close(stop) }
+}
@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/listings"
+	"nofx/trader"
+	"strings"
+	"time"
+)
+
+// listingSource 新上线合约在候选池中的来源标记
+const listingSource = "new_listing"
+
+// ListingConfig 新上线合约的处理配置
+type ListingConfig struct {
+	AutoAddTraders []string               // 自动加入候选池的交易员（为空则只通知）
+	MinAge         time.Duration          // 上线满该时长后才加入候选池，避开上线初期的剧烈波动
+	MaxPerTrader   int                    // 每个交易员最多自动加入的新合约数
+	Limits         trader.CandidateLimits // 新合约的开仓杠杆/仓位上限
+}
+
+// StartListingScanner 启动新上线合约扫描：发现时发布通知，满足上线时长后加入指定交易员的候选池，返回停止函数
+// 自动加入的合约只在内存中生效，重启后由扫描器按上线时间重新发现
+func (tm *TraderManager) StartListingScanner(scanCfg listings.Config, cfg ListingConfig) (func(), error) {
+	scanner, err := listings.NewScanner(scanCfg, func(l listings.Listing) {
+		msg := fmt.Sprintf("🆕 %s 新上线永续合约 %s", l.Exchange, l.Symbol)
+		if !l.ListedAt.IsZero() {
+			msg += fmt.Sprintf("（上线时间 %s）", l.ListedAt.Local().Format("01-02 15:04"))
+		}
+		tm.eventBus.Publish(events.Event{Type: events.NewListing, Exchange: l.Exchange, Symbol: l.Symbol, Message: msg})
+	})
+	if err != nil {
+		return nil, err
+	}
+	scanner.Start()
+	if len(cfg.AutoAddTraders) == 0 {
+		return scanner.Stop, nil
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				tm.addListings(scanner.Listings(), cfg, time.Now())
+			}
+		}
+	}()
+	log.Printf("🆕 新上线合约满 %s 后自动加入交易员候选池: %v（每个最多 %d 个，杠杆上限 %d 倍）",
+		cfg.MinAge, cfg.AutoAddTraders, cfg.MaxPerTrader, cfg.Limits.MaxLeverage)
+	return func() {
+		close(stop)
+		<-done
+		scanner.Stop()
+	}, nil
+}
+
+// addListings 将满足上线时长的新合约加入同交易所交易员的候选池
+func (tm *TraderManager) addListings(found []listings.Listing, cfg ListingConfig, now time.Time) {
+	for _, l := range found {
+		if l.Since(now) < cfg.MinAge {
+			continue
+		}
+		for _, id := range cfg.AutoAddTraders {
+			at, err := tm.GetTrader(id)
+			if err != nil || !strings.EqualFold(at.GetExchange(), l.Exchange) {
+				continue
+			}
+			if len(at.CandidateSymbols(listingSource)) >= cfg.MaxPerTrader {
+				continue
+			}
+			if !at.AddCandidateSymbol(l.Symbol, listingSource, cfg.Limits) {
+				continue
+			}
+			msg := fmt.Sprintf("🆕 新合约 %s 已加入 %s 的候选币种（杠杆上限 %d 倍）", l.Symbol, at.GetName(), cfg.Limits.MaxLeverage)
+			log.Printf("%s", msg)
+			tm.eventBus.Publish(events.Event{Type: events.NewListing, TraderID: at.GetID(), Exchange: l.Exchange, Symbol: l.Symbol, Message: msg})
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/events"
+	"nofx/maintenance"
+	"nofx/market"
+	"nofx/trader"
+	"strings"
+	"time"
+)
+
+// MaintenanceHedgeConfig 维护期间的跨交易所对冲配置
+type MaintenanceHedgeConfig struct {
+	TraderID string // 执行对冲的交易员（应为专用账户，不参与AI交易），为空则不对冲
+	Leverage int    // 对冲仓位杠杆
+}
+
+// maintenanceHedge 一笔维护期间的对冲仓位
+type maintenanceHedge struct {
+	window   string // 所属维护窗口
+	symbol   string
+	side     string // 对冲仓位方向（与被对冲的净持仓相反）
+	quantity float64
+}
+
+// StartMaintenanceMonitor 定期检查交易所维护窗口，进入/结束时发布告警事件，返回停止函数
+// 配置了对冲交易员时，维护开始前（提前量内）在对冲账户建立与受影响持仓相反的仓位，窗口结束后平掉
+// 各交易员在决策周期内自行拒绝开仓，这里只负责统一告警及对冲
+func (tm *TraderManager) StartMaintenanceMonitor(interval time.Duration, hedgeCfg MaintenanceHedgeConfig) func() {
+	stop := make(chan struct{})
+	go func() {
+		alerted := make(map[string]maintenance.Window)
+		hedges := make(map[string]*maintenanceHedge) // 账户|合约 -> 对冲仓位
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			now := time.Now()
+			active := tm.activeMaintenance(now)
+
+			for key, w := range active {
+				if _, ok := alerted[key]; ok {
+					continue
+				}
+				alerted[key] = w
+				msg := fmt.Sprintf("🛠️ %s 维护窗口 %s（%s - %s），已暂停相关合约开仓",
+					w.Exchange, w.Name, w.Start.Local().Format("01-02 15:04"), w.End.Local().Format("01-02 15:04"))
+				log.Printf("%s", msg)
+				tm.eventBus.Publish(events.Event{Type: events.ExchangeMaintenance, Exchange: w.Exchange, Message: msg})
+			}
+			for key, w := range alerted {
+				if _, ok := active[key]; ok {
+					continue
+				}
+				delete(alerted, key)
+				msg := fmt.Sprintf("🛠️ %s 维护窗口 %s 已结束，恢复开仓", w.Exchange, w.Name)
+				log.Printf("%s", msg)
+				tm.eventBus.Publish(events.Event{Type: events.ExchangeMaintenance, Exchange: w.Exchange, Message: msg})
+			}
+
+			if hedgeCfg.TraderID != "" {
+				tm.syncMaintenanceHedges(hedgeCfg, active, hedges, now)
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("✓ 交易所维护窗口监控已启动（每 %s 检查一次）", interval)
+	return func() { close(stop) }
+}
+
+// activeMaintenance 当前（含提前量）影响运行中交易员所在交易所的维护窗口
+func (tm *TraderManager) activeMaintenance(now time.Time) map[string]maintenance.Window {
+	exchanges := make(map[string]bool)
+	for _, at := range tm.GetAllTraders() {
+		exchanges[at.GetExchange()] = true
+	}
+	lead := maintenance.Lead()
+	active := make(map[string]maintenance.Window)
+	for _, w := range maintenance.Windows() {
+		for exchange := range exchanges {
+			if w.Covers(exchange, "", now, lead) {
+				active[w.Key()] = w
+			}
+		}
+	}
+	return active
+}
+
+// syncMaintenanceHedges 为维护中的交易所持仓建立对冲，并平掉已结束窗口的对冲
+func (tm *TraderManager) syncMaintenanceHedges(cfg MaintenanceHedgeConfig, active map[string]maintenance.Window, hedges map[string]*maintenanceHedge, now time.Time) {
+	hedgeTrader, err := tm.GetTrader(cfg.TraderID)
+	if err != nil {
+		log.Printf("⚠️  维护对冲交易员不可用: %v", err)
+		return
+	}
+	if hedgeTrader.IsWatchOnly() {
+		log.Printf("⚠️  维护对冲交易员 %s 为观察模式，无法建立对冲", hedgeTrader.GetName())
+		return
+	}
+	hedgeExchange := hedgeTrader.GetExchangeTrader()
+
+	// 窗口结束：平掉对冲仓位
+	for key, h := range hedges {
+		if _, ok := active[h.window]; ok {
+			continue
+		}
+		if _, err := trader.PlaceOrder(hedgeExchange, h.symbol, "close_"+h.side, h.quantity, 0); err != nil {
+			log.Printf("⚠️  [维护对冲] 平掉 %s %s %.6f 失败: %v", h.symbol, h.side, h.quantity, err)
+			continue
+		}
+		log.Printf("🛠️ [维护对冲] 维护结束，已平掉 %s %s %.6f", h.symbol, h.side, h.quantity)
+		delete(hedges, key)
+	}
+
+	// 窗口生效：对冲受影响账户的净持仓（同一窗口下每个账户只统计一次）
+	accounts := make(map[string]bool)
+	for _, w := range active {
+		if strings.EqualFold(w.Exchange, hedgeTrader.GetExchange()) {
+			continue // 对冲账户本身在维护，无法对冲
+		}
+		for _, at := range tm.GetAllTraders() {
+			account := at.GetAccountKey()
+			if at.GetID() == cfg.TraderID || accounts[w.Key()+account] || !strings.EqualFold(at.GetExchange(), w.Exchange) {
+				continue
+			}
+			accounts[w.Key()+account] = true
+			tm.hedgeAccount(cfg, hedgeTrader, at, w, account, hedges, now)
+		}
+	}
+}
+
+// hedgeAccount 在对冲账户上建立与指定账户净持仓相反的仓位（已对冲的合约跳过）
+func (tm *TraderManager) hedgeAccount(cfg MaintenanceHedgeConfig, hedgeTrader, at *trader.AutoTrader, w maintenance.Window, account string, hedges map[string]*maintenanceHedge, now time.Time) {
+	positions, err := at.GetExchangeTrader().GetPositions()
+	if err != nil {
+		log.Printf("⚠️  [维护对冲] 获取 %s 持仓失败: %v", at.GetName(), err)
+		return
+	}
+	hedgeExchange := hedgeTrader.GetExchangeTrader()
+	net := make(map[string]float64)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		symbol = market.Normalize(symbol)
+		if !w.Covers(w.Exchange, symbol, now, maintenance.Lead()) {
+			continue
+		}
+		amt, _ := pos["positionAmt"].(float64)
+		if side, _ := pos["side"].(string); side == "short" {
+			net[symbol] -= math.Abs(amt)
+		} else {
+			net[symbol] += math.Abs(amt)
+		}
+	}
+
+	for symbol, qty := range net {
+		key := account + "|" + symbol
+		if _, ok := hedges[key]; ok || qty == 0 {
+			continue
+		}
+		h := &maintenanceHedge{window: w.Key(), symbol: symbol, quantity: math.Abs(qty)}
+		if qty > 0 {
+			h.side = "short"
+		} else {
+			h.side = "long"
+		}
+		_, err = trader.PlaceOrder(hedgeExchange, symbol, "open_"+h.side, h.quantity, cfg.Leverage)
+		if err != nil {
+			log.Printf("⚠️  [维护对冲] %s 对冲 %s %.6f 失败: %v", symbol, h.side, h.quantity, err)
+			continue
+		}
+		hedges[key] = h
+		log.Printf("🛠️ [维护对冲] %s 维护期间在 %s 建立 %s %s %.6f", w.Exchange, hedgeTrader.GetName(), symbol, h.side, h.quantity)
+	}
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"nofx/config"
+	"nofx/trader"
+)
+
+// storeOrderJournal 把下单意图写入数据库
+type storeOrderJournal struct {
+	store config.Store
+}
+
+// NewOrderJournal 创建写入数据库的下单意图日志
+func NewOrderJournal(store config.Store) trader.OrderJournal {
+	return &storeOrderJournal{store: store}
+}
+
+// RecordIntent 实现 trader.OrderJournal
+func (j *storeOrderJournal) RecordIntent(intent *trader.OrderIntent) error {
+	return j.store.CreateOrderIntent(&config.OrderIntent{
+		TraderID:      intent.TraderID,
+		Exchange:      intent.Exchange,
+		Symbol:        intent.Symbol,
+		Action:        intent.Action,
+		Quantity:      intent.Quantity,
+		ClientOrderID: intent.ClientOrderID,
+		Status:        intent.Status,
+	})
+}
+
+// UpdateIntent 实现 trader.OrderJournal
+func (j *storeOrderJournal) UpdateIntent(clientOrderID, status string, orderID int64, errMsg string) error {
+	return j.store.UpdateOrderIntent(clientOrderID, status, orderID, errMsg)
+}
+
+// PendingIntents 实现 trader.OrderJournal
+func (j *storeOrderJournal) PendingIntents(traderID string) ([]*trader.OrderIntent, error) {
+	records, err := j.store.GetPendingOrderIntents(traderID)
+	if err != nil {
+		return nil, err
+	}
+	intents := make([]*trader.OrderIntent, 0, len(records))
+	for _, r := range records {
+		intents = append(intents, &trader.OrderIntent{
+			ID:            r.ID,
+			TraderID:      r.TraderID,
+			Exchange:      r.Exchange,
+			Symbol:        r.Symbol,
+			Action:        r.Action,
+			Quantity:      r.Quantity,
+			ClientOrderID: r.ClientOrderID,
+			Status:        r.Status,
+			OrderID:       r.OrderID,
+			Error:         r.Error,
+			CreatedAt:     r.CreatedAt,
+			UpdatedAt:     r.UpdatedAt,
+		})
+	}
+	return intents, nil
+}
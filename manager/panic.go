@@ -0,0 +1,193 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TraderPanicResult 单个交易员的紧急按钮结果（预演时为将要执行的操作）
+type TraderPanicResult struct {
+	TraderID  string               `json:"trader_id"`
+	Halted    bool                 `json:"halted"`
+	Orders    []trader.BookOrder   `json:"orders,omitempty"`         // 撤单前的挂单（交易所支持查询挂单时）
+	Symbols   []string             `json:"cancel_symbols,omitempty"` // 撤单的币种
+	Positions []trader.CloseResult `json:"positions,omitempty"`      // 平仓结果
+	Errors    []string             `json:"errors,omitempty"`
+}
+
+// PanicReport 紧急按钮结果
+type PanicReport struct {
+	Preview  bool                 `json:"preview"`
+	Actions  []string             `json:"actions"`
+	Operator string               `json:"operator"`
+	Traders  []*TraderPanicResult `json:"traders"`
+	Closed   int                  `json:"closed"`
+	Failed   int                  `json:"failed"` // 平仓失败的持仓及撤单/平仓出错的交易员
+}
+
+// SetPanicActions 设置紧急按钮默认执行的动作（为空时执行全部动作）
+func (tm *TraderManager) SetPanicActions(actions []string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.panicActions = actions
+}
+
+// PanicActions 紧急按钮默认执行的动作
+func (tm *TraderManager) PanicActions() []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if len(tm.panicActions) == 0 {
+		return trader.PanicActions
+	}
+	return tm.panicActions
+}
+
+// Panic 紧急按钮：先暂停全部交易员，再按账户并发撤销全部挂单、市价平掉全部持仓，并呼叫值班人员
+// traderIDs为空时为全部交易员，actions为空时使用配置的动作；preview=true 时只返回将要执行的操作
+func (tm *TraderManager) Panic(traderIDs, actions []string, preview bool, operator string) (*PanicReport, error) {
+	if len(actions) == 0 {
+		actions = tm.PanicActions()
+	}
+	actions, err := trader.ParsePanicActions(strings.Join(actions, ","))
+	if err != nil {
+		return nil, err
+	}
+	traders, err := tm.closeAllTraders(traderIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(traders) == 0 {
+		return nil, fmt.Errorf("没有已加载的交易员")
+	}
+	sort.Slice(traders, func(i, j int) bool { return traders[i].GetID() < traders[j].GetID() })
+	has := make(map[string]bool, len(actions))
+	for _, a := range actions {
+		has[a] = true
+	}
+
+	report := &PanicReport{Preview: preview, Actions: actions, Operator: operator, Traders: []*TraderPanicResult{}}
+	results := make(map[string]*TraderPanicResult, len(traders))
+	for _, at := range traders {
+		result := &TraderPanicResult{TraderID: at.GetID(), Halted: has[trader.PanicDisableTrading]}
+		results[at.GetID()] = result
+		report.Traders = append(report.Traders, result)
+	}
+	if preview {
+		log.Printf("🧪 %s 预演紧急按钮 %v（%d 个交易员）", operator, actions, len(traders))
+	} else {
+		log.Printf("🚨 %s 触发紧急按钮 %v（%d 个交易员）", operator, actions, len(traders))
+		if has[trader.PanicPage] {
+			tm.eventBus.Publish(events.Event{Type: events.KillSwitch, Message: fmt.Sprintf("%s 触发紧急按钮（%s），正在处理 %d 个交易员", operator, strings.Join(actions, ","), len(traders))})
+		}
+	}
+
+	// 先暂停全部交易员，避免撤单平仓过程中决策周期重新开仓
+	if has[trader.PanicDisableTrading] && !preview {
+		for _, at := range traders {
+			at.Halt()
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, group := range trader.GroupByAccount(traders) {
+		wg.Add(1)
+		go func(group []*trader.AutoTrader) {
+			defer wg.Done()
+			seen := make(map[string]bool) // 预演时共用账户的挂单/持仓只列出一次（实际执行时后面的交易员已查不到）
+			for _, at := range group {
+				result := results[at.GetID()]
+				if has[trader.PanicCancelOrders] {
+					orders, symbols, err := at.CancelAllOpenOrders(preview)
+					result.Symbols = symbols
+					for _, o := range orders {
+						if key := fmt.Sprintf("order:%d", o.OrderID); !preview || !seen[key] {
+							seen[key] = true
+							result.Orders = append(result.Orders, o)
+						}
+					}
+					if err != nil {
+						result.Errors = append(result.Errors, err.Error())
+					}
+				}
+				if has[trader.PanicClosePositions] {
+					var positions []trader.CloseResult
+					var err error
+					if preview {
+						positions, err = at.PreviewCloseAll(trader.CloseFilter{})
+					} else {
+						positions, err = at.CloseAllPositions(trader.CloseFilter{}, "紧急平仓")
+					}
+					for _, pos := range positions {
+						if key := "position:" + pos.Symbol + "_" + pos.Side; !preview || !seen[key] {
+							seen[key] = true
+							result.Positions = append(result.Positions, pos)
+						}
+					}
+					if err != nil {
+						result.Errors = append(result.Errors, err.Error())
+					}
+				}
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	for _, result := range report.Traders {
+		if len(result.Errors) > 0 {
+			report.Failed++
+		}
+		for _, p := range result.Positions {
+			if p.Error == "" {
+				report.Closed++
+			} else {
+				report.Failed++
+			}
+		}
+	}
+	if !preview {
+		log.Printf("%s", FormatPanicReport(report))
+		if has[trader.PanicPage] {
+			tm.eventBus.Publish(events.Event{Type: events.KillSwitch, Message: FormatPanicReport(report)})
+		}
+	}
+	return report, nil
+}
+
+// FormatPanicReport 紧急按钮结果的文本形式（用于Telegram及告警）
+func FormatPanicReport(report *PanicReport) string {
+	var b strings.Builder
+	if report.Preview {
+		fmt.Fprintf(&b, "🧪 紧急按钮预演（%s）：%d 个交易员\n", strings.Join(report.Actions, ","), len(report.Traders))
+	} else {
+		fmt.Fprintf(&b, "🚨 紧急按钮已执行（%s，%s）：%d 个交易员，平仓成功 %d，失败 %d\n", strings.Join(report.Actions, ","), report.Operator, len(report.Traders), report.Closed, report.Failed)
+	}
+	for _, r := range report.Traders {
+		var parts []string
+		if r.Halted {
+			parts = append(parts, "暂停交易")
+		}
+		if len(r.Orders) > 0 {
+			parts = append(parts, fmt.Sprintf("撤销 %d 个挂单", len(r.Orders)))
+		} else if len(r.Symbols) > 0 {
+			parts = append(parts, fmt.Sprintf("撤销 %s 的挂单", strings.Join(r.Symbols, ",")))
+		}
+		for _, p := range r.Positions {
+			desc := fmt.Sprintf("平仓 %s %s %.4f", p.Symbol, p.Side, p.Quantity)
+			if p.Error != "" {
+				desc += "失败: " + p.Error
+			}
+			parts = append(parts, desc)
+		}
+		parts = append(parts, r.Errors...)
+		if len(parts) == 0 {
+			parts = append(parts, "无操作")
+		}
+		fmt.Fprintf(&b, "• %s: %s\n", r.TraderID, strings.Join(parts, "；"))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,40 @@
+package manager
+
+import (
+	"log"
+	"nofx/trader"
+	"sort"
+	"sync"
+)
+
+// PreflightAll 并发检查所有已加载交易员的API Key（权限、提现、IP白名单）并记录结果
+func (tm *TraderManager) PreflightAll() []*trader.PreflightReport {
+	traders := tm.GetAllTraders()
+	reports := make([]*trader.PreflightReport, 0, len(traders))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, at := range traders {
+		if at.GetExchange() == "paper" {
+			continue
+		}
+		wg.Add(1)
+		go func(at *trader.AutoTrader) {
+			defer wg.Done()
+			report := at.Preflight(true)
+			if err := report.Err(); err != nil {
+				log.Printf("❌ [%s] %v", at.GetName(), err)
+			} else {
+				log.Printf("✓ [%s] %s API Key预检通过", at.GetName(), report.Exchange)
+			}
+			for _, w := range report.Warnings {
+				log.Printf("⚠️  [%s] API Key预检: %s", at.GetName(), w)
+			}
+			mu.Lock()
+			reports = append(reports, report)
+			mu.Unlock()
+		}(at)
+	}
+	wg.Wait()
+	sort.Slice(reports, func(i, j int) bool { return reports[i].TraderID < reports[j].TraderID })
+	return reports
+}
@@ -0,0 +1,231 @@
+package manager
+
+import (
+	"encoding/json"
+	"log"
+	"nofx/config"
+	"nofx/trader"
+	"strconv"
+	"time"
+)
+
+// SystemSettings 所有交易员共享的系统级交易配置（来自system_config表）
+type SystemSettings struct {
+	MaxDailyLoss       float64
+	MaxDrawdown        float64
+	StopTradingMinutes int
+	DefaultCoins       []string
+
+	// 保证金自动补充
+	MarginTopUpThreshold float64 // 保证金占用率超过该百分比时从现货划转（0=关闭）
+	MarginTopUpAmount    float64 // 每次划转的USDT金额
+
+	// 相关性分组敞口限制
+	CorrelationGroups   map[string]string // symbol -> 分组名
+	MaxGroupExposurePct float64           // 同一分组方向性敞口上限（净值百分比，0=不限制）
+
+	AIMaxPositionUSD float64 // AI单笔开仓名义价值硬上限（0=只按净值倍数限制）
+
+	// AI调用成本预算
+	LLMDailyBudgetUSD float64                    // 每个交易员每个交易日AI调用成本上限（0=不限制）
+	LLMPrices         map[string]trader.LLMPrice // 模型单价覆盖
+
+	RiskReportIntervalMinutes int // 风险报告生成间隔（分钟，0=关闭）
+
+	ReplayLog bool // 记录回放日志
+
+	FaultInjection *trader.FaultConfig // 故障注入（nil=关闭，只用于测试环境）
+
+	MarketGuard      *trader.MarketGuard // 市价开仓前的行情保护（nil=关闭）
+	MaxSlippageTicks int                 // 市价单最大滑点（价格步进数，0=普通市价单）
+
+	RequestBudget *trader.RequestBudget // 交易所请求频率预算（nil=不限制）
+
+	LiquidationAlertPct float64 // 距强平价格低于该百分比时发送紧急告警（0=关闭）
+
+	ShadowBookReconcile time.Duration // 影子账本REST对账间隔（0=关闭）
+}
+
+// LoadSystemSettings 从数据库读取并解析系统级交易配置
+func LoadSystemSettings(database config.Store) SystemSettings {
+	settings := SystemSettings{
+		MaxDailyLoss:       10.0, // 默认值
+		MaxDrawdown:        20.0, // 默认值
+		StopTradingMinutes: 60,   // 默认值
+
+		RiskReportIntervalMinutes: 60,
+	}
+
+	maxDailyLossStr, _ := database.GetSystemConfig("max_daily_loss")
+	if val, err := strconv.ParseFloat(maxDailyLossStr, 64); err == nil {
+		settings.MaxDailyLoss = val
+	}
+
+	maxDrawdownStr, _ := database.GetSystemConfig("max_drawdown")
+	if val, err := strconv.ParseFloat(maxDrawdownStr, 64); err == nil {
+		settings.MaxDrawdown = val
+	}
+
+	stopTradingMinutesStr, _ := database.GetSystemConfig("stop_trading_minutes")
+	if val, err := strconv.Atoi(stopTradingMinutesStr); err == nil {
+		settings.StopTradingMinutes = val
+	}
+
+	// 解析默认币种列表
+	defaultCoinsStr, _ := database.GetSystemConfig("default_coins")
+	if defaultCoinsStr != "" {
+		if err := json.Unmarshal([]byte(defaultCoinsStr), &settings.DefaultCoins); err != nil {
+			log.Printf("⚠️ 解析默认币种配置失败: %v，使用空列表", err)
+			settings.DefaultCoins = []string{}
+		}
+	}
+
+	marginTopUpThresholdStr, _ := database.GetSystemConfig("margin_topup_threshold")
+	if val, err := strconv.ParseFloat(marginTopUpThresholdStr, 64); err == nil {
+		settings.MarginTopUpThreshold = val
+	}
+
+	marginTopUpAmountStr, _ := database.GetSystemConfig("margin_topup_amount")
+	if val, err := strconv.ParseFloat(marginTopUpAmountStr, 64); err == nil {
+		settings.MarginTopUpAmount = val
+	}
+
+	correlationGroupsStr, _ := database.GetSystemConfig("correlation_groups")
+	groups, err := trader.ParseCorrelationGroups(correlationGroupsStr)
+	if err != nil {
+		log.Printf("⚠️ %v，不启用相关性分组限制", err)
+	}
+	settings.CorrelationGroups = groups
+
+	maxGroupExposureStr, _ := database.GetSystemConfig("max_group_exposure_pct")
+	if val, err := strconv.ParseFloat(maxGroupExposureStr, 64); err == nil {
+		settings.MaxGroupExposurePct = val
+	}
+
+	aiMaxPositionStr, _ := database.GetSystemConfig("ai_max_position_usd")
+	if val, err := strconv.ParseFloat(aiMaxPositionStr, 64); err == nil {
+		settings.AIMaxPositionUSD = val
+	}
+
+	llmBudgetStr, _ := database.GetSystemConfig("llm_daily_budget_usd")
+	if val, err := strconv.ParseFloat(llmBudgetStr, 64); err == nil {
+		settings.LLMDailyBudgetUSD = val
+	}
+
+	llmPricesStr, _ := database.GetSystemConfig("llm_prices")
+	prices, err := trader.ParseLLMPrices(llmPricesStr)
+	if err != nil {
+		log.Printf("⚠️ %v，使用内置模型单价", err)
+	}
+	settings.LLMPrices = prices
+
+	riskReportIntervalStr, _ := database.GetSystemConfig("risk_report_interval_minutes")
+	if val, err := strconv.Atoi(riskReportIntervalStr); err == nil {
+		settings.RiskReportIntervalMinutes = val
+	}
+
+	replayLogStr, _ := database.GetSystemConfig("replay_log")
+	settings.ReplayLog = replayLogStr == "true"
+
+	faultStr, _ := database.GetSystemConfig("fault_injection")
+	fault, err := trader.ParseFaultConfig(faultStr)
+	if err != nil {
+		log.Printf("⚠️ %v，不注入故障", err)
+	}
+	settings.FaultInjection = fault
+
+	guardStr, _ := database.GetSystemConfig("market_order_guard")
+	guard, err := trader.ParseMarketGuard(guardStr)
+	if err != nil {
+		log.Printf("⚠️ %v，不启用市价单行情保护", err)
+	}
+	settings.MarketGuard = guard
+
+	maxSlippageStr, _ := database.GetSystemConfig("max_slippage_ticks")
+	if val, err := strconv.Atoi(maxSlippageStr); err == nil && val >= 0 {
+		settings.MaxSlippageTicks = val
+	}
+
+	budgetStr, _ := database.GetSystemConfig("request_budget")
+	budget, err := trader.ParseRequestBudget(budgetStr)
+	if err != nil {
+		log.Printf("⚠️ %v，不限制交易所请求频率", err)
+	}
+	settings.RequestBudget = budget
+
+	liqAlertStr, _ := database.GetSystemConfig("liquidation_alert_pct")
+	if val, err := strconv.ParseFloat(liqAlertStr, 64); err == nil && val >= 0 {
+		settings.LiquidationAlertPct = val
+	}
+
+	if bookStr, _ := database.GetSystemConfig("shadow_book_reconcile"); bookStr != "" {
+		if val, err := time.ParseDuration(bookStr); err != nil || val < time.Second {
+			log.Printf("⚠️ 无效的影子账本对账间隔: %s（如 30s，不低于1秒），不启用影子账本", bookStr)
+		} else {
+			settings.ShadowBookReconcile = val
+		}
+	}
+
+	return settings
+}
+
+// Apply 将系统级配置写入交易员配置
+func (s SystemSettings) Apply(cfg *trader.AutoTraderConfig) {
+	cfg.MaxDailyLoss = s.MaxDailyLoss
+	cfg.MaxDrawdown = s.MaxDrawdown
+	cfg.StopTradingTime = time.Duration(s.StopTradingMinutes) * time.Minute
+	cfg.DefaultCoins = s.DefaultCoins
+	cfg.MarginTopUpThreshold = s.MarginTopUpThreshold
+	cfg.MarginTopUpAmount = s.MarginTopUpAmount
+	cfg.CorrelationGroups = s.CorrelationGroups
+	cfg.MaxGroupExposurePct = s.MaxGroupExposurePct
+	cfg.AIMaxPositionUSD = s.AIMaxPositionUSD
+	cfg.LLMDailyBudgetUSD = s.LLMDailyBudgetUSD
+	cfg.LLMPrices = s.LLMPrices
+	cfg.RiskReportInterval = time.Duration(s.RiskReportIntervalMinutes) * time.Minute
+	cfg.ReplayLog = s.ReplayLog
+	cfg.FaultInjection = s.FaultInjection
+	cfg.MarketGuard = s.MarketGuard
+	cfg.MaxSlippageTicks = s.MaxSlippageTicks
+	cfg.RequestBudget = s.RequestBudget
+	cfg.LiquidationAlertPct = s.LiquidationAlertPct
+	cfg.ShadowBookReconcile = s.ShadowBookReconcile
+}
+
+// applyTraderOptions 将交易员级别的扩展配置写入交易员配置
+func applyTraderOptions(traderCfg *config.TraderRecord, cfg *trader.AutoTraderConfig) {
+	modes, err := trader.ParseSymbolMarginModes(traderCfg.SymbolMarginModes)
+	if err != nil {
+		log.Printf("⚠️ 交易员 %s 的按币种仓位模式配置无效: %v，使用默认模式", traderCfg.Name, err)
+	}
+	cfg.SymbolMarginModes = modes
+	cfg.DryRun = traderCfg.DryRun
+	cfg.WatchOnly = traderCfg.WatchOnly
+	cfg.OrderTagPrefix = traderCfg.OrderTagPrefix
+	cfg.AllocationPct = traderCfg.AllocationPct
+	cfg.NettingRule = traderCfg.NettingRule
+	cfg.MaxTradesPerHour = traderCfg.MaxTradesPerHour
+	cfg.MaxTradesPerDay = traderCfg.MaxTradesPerDay
+	cfg.MaxEntriesPerSymbolPerDay = traderCfg.MaxEntriesPerSymbolPerDay
+	cfg.StopLossCooldown = time.Duration(traderCfg.StopLossCooldownMinutes) * time.Minute
+	cfg.FundingAvoidWindow = time.Duration(traderCfg.FundingAvoidMinutes) * time.Minute
+	cfg.FundingAdverseThreshold = traderCfg.FundingAdverseThreshold
+	cfg.TrailingStopMode = traderCfg.TrailingStopMode
+	cfg.TrailingInterval = traderCfg.TrailingInterval
+	cfg.TrailingLookback = traderCfg.TrailingLookback
+	cfg.TrailingATRMultiplier = traderCfg.TrailingATRMultiplier
+	cfg.RequireApproval = traderCfg.RequireApproval
+	cfg.ApprovalTimeout = time.Duration(traderCfg.ApprovalTimeoutSeconds) * time.Second
+	cfg.SymbolWhitelist = trader.ParseSymbolList(traderCfg.SymbolWhitelist)
+	cfg.SymbolBlacklist = trader.ParseSymbolList(traderCfg.SymbolBlacklist)
+	caps, err := trader.ParseSymbolNotionalCaps(traderCfg.SymbolMaxNotional)
+	if err != nil {
+		log.Printf("⚠️ 交易员 %s 的币种名义价值上限配置无效: %v，不限制", traderCfg.Name, err)
+	}
+	cfg.SymbolMaxNotional = caps
+	executor, err := trader.ParseExecutionPolicy(traderCfg.ExecutionPolicy)
+	if err != nil {
+		log.Printf("⚠️ 交易员 %s 的开仓执行算法配置无效: %v，使用市价单", traderCfg.Name, err)
+	}
+	cfg.EntryExecutor = executor
+}
@@ -1,12 +1,12 @@
 package manager
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"nofx/config"
+	"nofx/events"
+	"nofx/jobs"
 	"nofx/trader"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,17 +16,74 @@ import (
 type TraderManager struct {
 	traders map[string]*trader.AutoTrader // key: trader ID
 	mu      sync.RWMutex
+
+	eventBus      *events.Bus      // 所有trader共享的执行事件总线
+	eventRecorder *events.Recorder // 最近事件（供仪表盘查询）
+	approver      trader.Approver  // 人工确认通道（开启确认模式的trader使用）
+
+	executionRecorder  trader.ExecutionRecorder   // 开仓执行质量记录通道
+	orderJournal       trader.OrderJournal        // 下单意图日志（崩溃恢复对账）
+	twoPerson          *twoPersonRule             // 大额人工订单的两人规则
+	instanceID         string                     // 敞口快照中的实例标识（为空时使用主机名）
+	jobs               *jobs.Queue                // 慢操作的后台任务队列
+	panicActions       []string                   // 紧急按钮默认执行的动作
+	reconcileTolerance *trader.ReconcileTolerance // 每日余额对账容差（nil=未开启每日对账）
 }
 
 // NewTraderManager 创建trader管理器
 func NewTraderManager() *TraderManager {
-	return &TraderManager{
-		traders: make(map[string]*trader.AutoTrader),
+	tm := &TraderManager{
+		traders:       make(map[string]*trader.AutoTrader),
+		eventBus:      events.NewBus(),
+		eventRecorder: events.NewRecorder(500),
+		twoPerson:     &twoPersonRule{pending: make(map[string]*pendingManualOrder)},
+	}
+	tm.eventBus.Subscribe("dashboard", tm.eventRecorder.Handle)
+	return tm
+}
+
+// EventBus 获取执行事件总线（用于注册通知、存储、指标等订阅者）
+func (tm *TraderManager) EventBus() *events.Bus {
+	return tm.eventBus
+}
+
+// SetApprover 设置人工确认通道（同时应用于已加载的trader）
+func (tm *TraderManager) SetApprover(approver trader.Approver) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.approver = approver
+	for _, at := range tm.traders {
+		at.SetApprover(approver)
 	}
 }
 
+// SetExecutionRecorder 设置开仓执行质量记录通道（同时应用于已加载的trader）
+func (tm *TraderManager) SetExecutionRecorder(recorder trader.ExecutionRecorder) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.executionRecorder = recorder
+	for _, at := range tm.traders {
+		at.SetExecutionRecorder(recorder)
+	}
+}
+
+// SetOrderJournal 设置下单意图日志（同时应用于已加载的trader）
+func (tm *TraderManager) SetOrderJournal(journal trader.OrderJournal) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.orderJournal = journal
+	for _, at := range tm.traders {
+		at.SetOrderJournal(journal)
+	}
+}
+
+// GetRecentEvents 获取最近的执行事件，traderID为空时返回全部
+func (tm *TraderManager) GetRecentEvents(traderID string, limit int) []events.Event {
+	return tm.eventRecorder.Recent(traderID, limit)
+}
+
 // LoadTradersFromDatabase 从数据库加载所有交易员到内存
-func (tm *TraderManager) LoadTradersFromDatabase(database *config.Database) error {
+func (tm *TraderManager) LoadTradersFromDatabase(database config.Store) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -53,35 +110,7 @@ func (tm *TraderManager) LoadTradersFromDatabase(database *config.Database) erro
 	log.Printf("📋 总共加载 %d 个交易员配置", len(allTraders))
 
 	// 获取系统配置（不包含信号源，信号源现在为用户级别）
-	maxDailyLossStr, _ := database.GetSystemConfig("max_daily_loss")
-	maxDrawdownStr, _ := database.GetSystemConfig("max_drawdown")
-	stopTradingMinutesStr, _ := database.GetSystemConfig("stop_trading_minutes")
-	defaultCoinsStr, _ := database.GetSystemConfig("default_coins")
-
-	// 解析配置
-	maxDailyLoss := 10.0 // 默认值
-	if val, err := strconv.ParseFloat(maxDailyLossStr, 64); err == nil {
-		maxDailyLoss = val
-	}
-
-	maxDrawdown := 20.0 // 默认值
-	if val, err := strconv.ParseFloat(maxDrawdownStr, 64); err == nil {
-		maxDrawdown = val
-	}
-
-	stopTradingMinutes := 60 // 默认值
-	if val, err := strconv.Atoi(stopTradingMinutesStr); err == nil {
-		stopTradingMinutes = val
-	}
-
-	// 解析默认币种列表
-	var defaultCoins []string
-	if defaultCoinsStr != "" {
-		if err := json.Unmarshal([]byte(defaultCoinsStr), &defaultCoins); err != nil {
-			log.Printf("⚠️ 解析默认币种配置失败: %v，使用空列表", err)
-			defaultCoins = []string{}
-		}
-	}
+	settings := LoadSystemSettings(database)
 
 	// 为每个交易员获取AI模型和交易所配置
 	for _, traderCfg := range allTraders {
@@ -157,11 +186,12 @@ func (tm *TraderManager) LoadTradersFromDatabase(database *config.Database) erro
 		}
 
 		// 添加到TraderManager
-		err = tm.addTraderFromDB(traderCfg, aiModelCfg, exchangeCfg, coinPoolURL, oiTopURL, maxDailyLoss, maxDrawdown, stopTradingMinutes, defaultCoins)
+		err = tm.addTraderFromDB(traderCfg, aiModelCfg, exchangeCfg, coinPoolURL, oiTopURL, settings)
 		if err != nil {
 			log.Printf("❌ 添加交易员 %s 失败: %v", traderCfg.Name, err)
 			continue
 		}
+		tm.applyEnsemble(traderCfg, aiModels)
 	}
 
 	log.Printf("✓ 成功加载 %d 个交易员到内存", len(tm.traders))
@@ -169,7 +199,7 @@ func (tm *TraderManager) LoadTradersFromDatabase(database *config.Database) erro
 }
 
 // addTraderFromConfig 内部方法：从配置添加交易员（不加锁，因为调用方已加锁）
-func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModelCfg *config.AIModelConfig, exchangeCfg *config.ExchangeConfig, coinPoolURL, oiTopURL string, maxDailyLoss, maxDrawdown float64, stopTradingMinutes int, defaultCoins []string) error {
+func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModelCfg *config.AIModelConfig, exchangeCfg *config.ExchangeConfig, coinPoolURL, oiTopURL string, settings SystemSettings) error {
 	if _, exists := tm.traders[traderCfg.ID]; exists {
 		return fmt.Errorf("trader ID '%s' 已存在", traderCfg.ID)
 	}
@@ -189,7 +219,7 @@ func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModel
 
 	// 如果没有指定交易币种，使用默认币种
 	if len(tradingCoins) == 0 {
-		tradingCoins = defaultCoins
+		tradingCoins = settings.DefaultCoins
 	}
 
 	// 根据交易员配置决定是否使用信号源
@@ -219,15 +249,13 @@ func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModel
 		InitialBalance:        traderCfg.InitialBalance,
 		BTCETHLeverage:        traderCfg.BTCETHLeverage,
 		AltcoinLeverage:       traderCfg.AltcoinLeverage,
-		MaxDailyLoss:          maxDailyLoss,
-		MaxDrawdown:           maxDrawdown,
-		StopTradingTime:       time.Duration(stopTradingMinutes) * time.Minute,
 		IsCrossMargin:         traderCfg.IsCrossMargin,
-		DefaultCoins:          defaultCoins,
 		TradingCoins:          tradingCoins,
 		SystemPromptTemplate:  traderCfg.SystemPromptTemplate, // 系统提示词模板
 		GateUseTestNet:        exchangeCfg.Testnet,
 	}
+	settings.Apply(&traderConfig)
+	applyTraderOptions(traderCfg, &traderConfig)
 
 	// 根据交易所类型设置API密钥
 	if exchangeCfg.ID == "binance" {
@@ -269,6 +297,10 @@ func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModel
 		}
 	}
 
+	at.SetEventBus(tm.eventBus)
+	at.SetApprover(tm.approver)
+	at.SetExecutionRecorder(tm.executionRecorder)
+	at.SetOrderJournal(tm.orderJournal)
 	tm.traders[traderCfg.ID] = at
 	log.Printf("✓ Trader '%s' (%s + %s) 已加载到内存", traderCfg.Name, aiModelCfg.Provider, exchangeCfg.ID)
 	return nil
@@ -277,7 +309,7 @@ func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModel
 // AddTrader 从数据库配置添加trader (移除旧版兼容性)
 
 // AddTraderFromDB 从数据库配置添加trader
-func (tm *TraderManager) AddTraderFromDB(traderCfg *config.TraderRecord, aiModelCfg *config.AIModelConfig, exchangeCfg *config.ExchangeConfig, coinPoolURL, oiTopURL string, maxDailyLoss, maxDrawdown float64, stopTradingMinutes int, defaultCoins []string) error {
+func (tm *TraderManager) AddTraderFromDB(traderCfg *config.TraderRecord, aiModelCfg *config.AIModelConfig, exchangeCfg *config.ExchangeConfig, coinPoolURL, oiTopURL string, settings SystemSettings) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -300,7 +332,7 @@ func (tm *TraderManager) AddTraderFromDB(traderCfg *config.TraderRecord, aiModel
 
 	// 如果没有指定交易币种，使用默认币种
 	if len(tradingCoins) == 0 {
-		tradingCoins = defaultCoins
+		tradingCoins = settings.DefaultCoins
 	}
 
 	// 根据交易员配置决定是否使用信号源
@@ -330,13 +362,11 @@ func (tm *TraderManager) AddTraderFromDB(traderCfg *config.TraderRecord, aiModel
 		InitialBalance:        traderCfg.InitialBalance,
 		BTCETHLeverage:        traderCfg.BTCETHLeverage,
 		AltcoinLeverage:       traderCfg.AltcoinLeverage,
-		MaxDailyLoss:          maxDailyLoss,
-		MaxDrawdown:           maxDrawdown,
-		StopTradingTime:       time.Duration(stopTradingMinutes) * time.Minute,
 		IsCrossMargin:         traderCfg.IsCrossMargin,
-		DefaultCoins:          defaultCoins,
 		TradingCoins:          tradingCoins,
 	}
+	settings.Apply(&traderConfig)
+	applyTraderOptions(traderCfg, &traderConfig)
 
 	// 根据交易所类型设置API密钥
 	if exchangeCfg.ID == "binance" {
@@ -375,6 +405,10 @@ func (tm *TraderManager) AddTraderFromDB(traderCfg *config.TraderRecord, aiModel
 		}
 	}
 
+	at.SetEventBus(tm.eventBus)
+	at.SetApprover(tm.approver)
+	at.SetExecutionRecorder(tm.executionRecorder)
+	at.SetOrderJournal(tm.orderJournal)
 	tm.traders[traderCfg.ID] = at
 	log.Printf("✓ Trader '%s' (%s + %s) 已添加", traderCfg.Name, aiModelCfg.Provider, exchangeCfg.ID)
 	return nil
@@ -566,7 +600,7 @@ func containsUserPrefix(traderID string) bool {
 }
 
 // LoadUserTraders 为特定用户加载交易员到内存
-func (tm *TraderManager) LoadUserTraders(database *config.Database, userID string) error {
+func (tm *TraderManager) LoadUserTraders(database config.Store, userID string) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -579,10 +613,7 @@ func (tm *TraderManager) LoadUserTraders(database *config.Database, userID strin
 	log.Printf("📋 为用户 %s 加载交易员配置: %d 个", userID, len(traders))
 
 	// 获取系统配置（不包含信号源，信号源现在为用户级别）
-	maxDailyLossStr, _ := database.GetSystemConfig("max_daily_loss")
-	maxDrawdownStr, _ := database.GetSystemConfig("max_drawdown")
-	stopTradingMinutesStr, _ := database.GetSystemConfig("stop_trading_minutes")
-	defaultCoinsStr, _ := database.GetSystemConfig("default_coins")
+	settings := LoadSystemSettings(database)
 
 	// 获取用户信号源配置
 	var coinPoolURL, oiTopURL string
@@ -594,31 +625,6 @@ func (tm *TraderManager) LoadUserTraders(database *config.Database, userID strin
 		log.Printf("🔍 用户 %s 暂未配置信号源", userID)
 	}
 
-	// 解析配置
-	maxDailyLoss := 10.0 // 默认值
-	if val, err := strconv.ParseFloat(maxDailyLossStr, 64); err == nil {
-		maxDailyLoss = val
-	}
-
-	maxDrawdown := 20.0 // 默认值
-	if val, err := strconv.ParseFloat(maxDrawdownStr, 64); err == nil {
-		maxDrawdown = val
-	}
-
-	stopTradingMinutes := 60 // 默认值
-	if val, err := strconv.Atoi(stopTradingMinutesStr); err == nil {
-		stopTradingMinutes = val
-	}
-
-	// 解析默认币种列表
-	var defaultCoins []string
-	if defaultCoinsStr != "" {
-		if err := json.Unmarshal([]byte(defaultCoinsStr), &defaultCoins); err != nil {
-			log.Printf("⚠️ 解析默认币种配置失败: %v，使用空列表", err)
-			defaultCoins = []string{}
-		}
-	}
-
 	// 为每个交易员获取AI模型和交易所配置
 	for _, traderCfg := range traders {
 		// 检查是否已经加载过这个交易员
@@ -689,17 +695,19 @@ func (tm *TraderManager) LoadUserTraders(database *config.Database, userID strin
 		}
 
 		// 使用现有的方法加载交易员
-		err = tm.loadSingleTrader(traderCfg, aiModelCfg, exchangeCfg, coinPoolURL, oiTopURL, maxDailyLoss, maxDrawdown, stopTradingMinutes, defaultCoins)
+		err = tm.loadSingleTrader(traderCfg, aiModelCfg, exchangeCfg, coinPoolURL, oiTopURL, settings)
 		if err != nil {
 			log.Printf("⚠️ 加载交易员 %s 失败: %v", traderCfg.Name, err)
+			continue
 		}
+		tm.applyEnsemble(traderCfg, aiModels)
 	}
 
 	return nil
 }
 
 // loadSingleTrader 加载单个交易员（从现有代码提取的公共逻辑）
-func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiModelCfg *config.AIModelConfig, exchangeCfg *config.ExchangeConfig, coinPoolURL, oiTopURL string, maxDailyLoss, maxDrawdown float64, stopTradingMinutes int, defaultCoins []string) error {
+func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiModelCfg *config.AIModelConfig, exchangeCfg *config.ExchangeConfig, coinPoolURL, oiTopURL string, settings SystemSettings) error {
 	// 处理交易币种列表
 	var tradingCoins []string
 	if traderCfg.TradingSymbols != "" {
@@ -715,7 +723,7 @@ func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiMode
 
 	// 如果没有指定交易币种，使用默认币种
 	if len(tradingCoins) == 0 {
-		tradingCoins = defaultCoins
+		tradingCoins = settings.DefaultCoins
 	}
 
 	// 根据交易员配置决定是否使用信号源
@@ -739,14 +747,12 @@ func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiMode
 		CustomAPIURL:         aiModelCfg.CustomAPIURL,    // 自定义API URL
 		CustomModelName:      aiModelCfg.CustomModelName, // 自定义模型名称
 		UseQwen:              aiModelCfg.Provider == "qwen",
-		MaxDailyLoss:         maxDailyLoss,
-		MaxDrawdown:          maxDrawdown,
-		StopTradingTime:      time.Duration(stopTradingMinutes) * time.Minute,
 		IsCrossMargin:        traderCfg.IsCrossMargin,
-		DefaultCoins:         defaultCoins,
 		TradingCoins:         tradingCoins,
 		SystemPromptTemplate: traderCfg.SystemPromptTemplate, // 系统提示词模板
 	}
+	settings.Apply(&traderConfig)
+	applyTraderOptions(traderCfg, &traderConfig)
 
 	// 根据交易所类型设置API密钥
 	if exchangeCfg.ID == "binance" {
@@ -785,6 +791,10 @@ func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiMode
 		}
 	}
 
+	at.SetEventBus(tm.eventBus)
+	at.SetApprover(tm.approver)
+	at.SetExecutionRecorder(tm.executionRecorder)
+	at.SetOrderJournal(tm.orderJournal)
 	tm.traders[traderCfg.ID] = at
 	log.Printf("✓ Trader '%s' (%s + %s) 已为用户加载到内存", traderCfg.Name, aiModelCfg.Provider, exchangeCfg.ID)
 	return nil
@@ -0,0 +1,204 @@
+package manager
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// manualOrderConfirmTTL 大额人工订单等待第二人确认的有效期
+const manualOrderConfirmTTL = 5 * time.Minute
+
+// ManualOrder 人工下单/平仓请求（HTTP、Telegram、gRPC）
+type ManualOrder struct {
+	TraderIDs       []string               `json:"trader_ids"`
+	Symbol          string                 `json:"symbol"`
+	Action          string                 `json:"action"`                     // open_long / close_short 等
+	NotionalUSD     float64                `json:"notional_usd"`               // 名义价值（平仓为持仓名义价值）
+	NotionalUnknown bool                   `json:"notional_unknown,omitempty"` // 无法获取持仓，名义价值未知（按达到阈值处理）
+	Source          string                 `json:"source"`                     // HTTP / Telegram / gRPC
+	Operator        string                 `json:"operator"`                   // 发起人（渠道:身份，如 Telegram:@alice，仅用于展示）
+	Identity        string                 `json:"-"`                          // 发起人的运维人员身份（OperatorIdentity；空=无法确认）
+	Execute         func() (string, error) `json:"-"`                          // 实际执行，返回结果摘要
+}
+
+// OperatorIdentity 两人规则比较的运维人员身份：按登录邮箱、API Token名称（token:名称）或Telegram账号区分，
+// 与交易员归属无关（同一用户的不同Token、管理员模式下的不同Token视为不同运维人员）；operator为空时返回空
+func OperatorIdentity(operator string) string {
+	operator = strings.ToLower(strings.TrimSpace(operator))
+	if operator == "" {
+		return ""
+	}
+	return "operator:" + operator
+}
+
+// pendingManualOrder 等待第二人确认的人工订单
+type pendingManualOrder struct {
+	ref       string // 公开的编号（通知中只包含编号，不包含确认码）
+	order     *ManualOrder
+	expiresAt time.Time
+}
+
+// PendingManualOrder 可由当前用户确认的待确认人工订单
+type PendingManualOrder struct {
+	Ref       string       `json:"ref"`
+	Token     string       `json:"token"`
+	Order     *ManualOrder `json:"order"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// twoPersonRule 两人规则：名义价值达到阈值的人工订单需另一位运维人员凭确认码确认后才执行
+type twoPersonRule struct {
+	mu           sync.Mutex
+	thresholdUSD float64 // 0=关闭
+	pending      map[string]*pendingManualOrder
+}
+
+// SetTwoPersonThreshold 设置两人规则的名义价值阈值（USDT，0=关闭）
+func (tm *TraderManager) SetTwoPersonThreshold(thresholdUSD float64) {
+	tm.twoPerson.mu.Lock()
+	tm.twoPerson.thresholdUSD = thresholdUSD
+	tm.twoPerson.mu.Unlock()
+	if thresholdUSD > 0 {
+		log.Printf("✓ 两人规则已开启: 名义价值 ≥ %.2f USDT 的人工订单需另一位运维人员确认", thresholdUSD)
+	}
+}
+
+// SubmitManualOrder 提交人工订单：未达阈值时直接执行；达到阈值（或名义价值未知）时登记待确认并通知其他运维人员，返回 pending=true
+// 通知及日志只包含公开编号，确认码只通过 PendingManualOrders 提供给有权确认的其他用户
+func (tm *TraderManager) SubmitManualOrder(order *ManualOrder) (result string, pending bool, err error) {
+	rule := tm.twoPerson
+	rule.mu.Lock()
+	threshold := rule.thresholdUSD
+	if threshold <= 0 || (!order.NotionalUnknown && order.NotionalUSD < threshold) {
+		rule.mu.Unlock()
+		result, err = order.Execute()
+		return result, false, err
+	}
+	if order.Identity == "" {
+		rule.mu.Unlock()
+		return "", false, fmt.Errorf("两人规则: 无法确认发起人 %s 的身份（Telegram运维人员需通过 telegram_operator_users 关联用户），拒绝执行", order.Operator)
+	}
+	token, err := newConfirmToken()
+	if err != nil {
+		rule.mu.Unlock()
+		return "", false, err
+	}
+	ref, err := newConfirmToken()
+	if err != nil {
+		rule.mu.Unlock()
+		return "", false, err
+	}
+	ref = ref[:6]
+	now := time.Now()
+	for t, p := range rule.pending {
+		if now.After(p.expiresAt) {
+			delete(rule.pending, t)
+		}
+	}
+	rule.pending[token] = &pendingManualOrder{ref: ref, order: order, expiresAt: now.Add(manualOrderConfirmTTL)}
+	rule.mu.Unlock()
+
+	notional := fmt.Sprintf("%.2f USDT", order.NotionalUSD)
+	if order.NotionalUnknown {
+		notional = "未知（无法获取持仓）"
+	}
+	msg := fmt.Sprintf("🔐 两人规则: %s 通过%s请求 %s %s 名义价值 %s（交易员 %v，阈值 %.2f USDT，编号 %s），需另一位运维人员在 %s 内确认:\n"+
+		"通过 Telegram /pending 或 GET /api/manual-orders/pending 获取确认码后 /confirm 确认码 或 POST /api/manual-orders/confirm",
+		order.Operator, order.Source, order.Symbol, order.Action, notional, order.TraderIDs, threshold, ref, manualOrderConfirmTTL)
+	log.Printf("%s", msg)
+	tm.eventBus.Publish(events.Event{Type: events.ManualOrderPending, Symbol: order.Symbol, Action: order.Action, Message: msg})
+	return "", true, nil
+}
+
+// canConfirm 运维人员身份identity能否确认订单：不是发起人，且其所属用户有权操作订单涉及的全部交易员
+func (p *pendingManualOrder) canConfirm(identity string, allowed func(traderID string) bool) error {
+	if identity == "" {
+		return fmt.Errorf("无法确认确认人的身份")
+	}
+	if identity == p.order.Identity {
+		return fmt.Errorf("两人规则要求由发起人以外的运维人员确认")
+	}
+	for _, id := range p.order.TraderIDs {
+		if allowed == nil || !allowed(id) {
+			return fmt.Errorf("无权确认交易员 %s 的订单", id)
+		}
+	}
+	return nil
+}
+
+// PendingManualOrders 运维人员身份identity可以确认的待确认人工订单（含确认码，发起人本人及无权操作的用户看不到）
+func (tm *TraderManager) PendingManualOrders(identity string, allowed func(traderID string) bool) []PendingManualOrder {
+	rule := tm.twoPerson
+	rule.mu.Lock()
+	defer rule.mu.Unlock()
+	now := time.Now()
+	var result []PendingManualOrder
+	for token, p := range rule.pending {
+		if now.After(p.expiresAt) || p.canConfirm(identity, allowed) != nil {
+			continue
+		}
+		result = append(result, PendingManualOrder{Ref: p.ref, Token: token, Order: p.order, ExpiresAt: p.expiresAt})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ExpiresAt.Before(result[j].ExpiresAt) })
+	return result
+}
+
+// ConfirmManualOrder 第二位运维人员凭确认码确认并执行人工订单
+// identity 为确认人的运维人员身份（与发起人相同时拒绝），allowed 校验确认人所属用户有权操作订单涉及的全部交易员（nil=均无权）
+func (tm *TraderManager) ConfirmManualOrder(token, operator, identity string, allowed func(traderID string) bool) (*ManualOrder, string, error) {
+	rule := tm.twoPerson
+	rule.mu.Lock()
+	p, ok := rule.pending[token]
+	if !ok || time.Now().After(p.expiresAt) {
+		delete(rule.pending, token)
+		rule.mu.Unlock()
+		return nil, "", fmt.Errorf("确认码无效或已过期")
+	}
+	if err := p.canConfirm(identity, allowed); err != nil {
+		rule.mu.Unlock()
+		return nil, "", err
+	}
+	delete(rule.pending, token)
+	rule.mu.Unlock()
+
+	log.Printf("🔐 两人规则: %s 已确认 %s 的人工订单 %s %s (%.2f USDT)", operator, p.order.Operator, p.order.Symbol, p.order.Action, p.order.NotionalUSD)
+	result, err := p.order.Execute()
+	return p.order, result, err
+}
+
+// PositionNotional 持仓的名义价值（数量 × 标记价格，未找到持仓时返回0；获取持仓失败时返回错误）
+func PositionNotional(at *trader.AutoTrader, symbol, side string) (float64, error) {
+	positions, err := at.GetPositions()
+	if err != nil {
+		return 0, fmt.Errorf("获取持仓失败: %w", err)
+	}
+	for _, pos := range positions {
+		if pos["symbol"] != symbol || pos["side"] != side {
+			continue
+		}
+		qty, _ := pos["quantity"].(float64)
+		price, _ := pos["mark_price"].(float64)
+		if qty < 0 {
+			qty = -qty
+		}
+		return qty * price, nil
+	}
+	return 0, nil
+}
+
+// newConfirmToken 生成随机确认码
+func newConfirmToken() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成确认码失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,45 @@
+package manager
+
+import "testing"
+
+func TestManualOrderConfirmedBySecondHTTPOperator(t *testing.T) {
+	tm := NewTraderManager()
+	tm.SetTwoPersonThreshold(1000)
+	owned := func(traderID string) bool { return traderID == "t1" }
+
+	executed := 0
+	_, pending, err := tm.SubmitManualOrder(&ManualOrder{
+		TraderIDs:   []string{"t1"},
+		Symbol:      "BTCUSDT",
+		Action:      "open_long",
+		NotionalUSD: 5000,
+		Source:      "HTTP",
+		Operator:    "HTTP:alice@example.com",
+		Identity:    OperatorIdentity("alice@example.com"),
+		Execute: func() (string, error) {
+			executed++
+			return "ok", nil
+		},
+	})
+	if err != nil || !pending {
+		t.Fatalf("submit: pending=%v err=%v", pending, err)
+	}
+
+	// 发起人本人看不到确认码；同一用户的另一个API Token可以确认
+	if orders := tm.PendingManualOrders(OperatorIdentity("ALICE@example.com"), owned); len(orders) != 0 {
+		t.Fatalf("initiator sees %d pending orders", len(orders))
+	}
+	orders := tm.PendingManualOrders(OperatorIdentity("token:ops"), owned)
+	if len(orders) != 1 {
+		t.Fatalf("second operator sees %d pending orders", len(orders))
+	}
+	if _, _, err := tm.ConfirmManualOrder(orders[0].Token, "HTTP:alice@example.com", OperatorIdentity("alice@example.com"), owned); err == nil {
+		t.Fatal("initiator confirmed their own order")
+	}
+	if _, result, err := tm.ConfirmManualOrder(orders[0].Token, "HTTP:token:ops", OperatorIdentity("token:ops"), owned); err != nil || result != "ok" {
+		t.Fatalf("confirm: result=%q err=%v", result, err)
+	}
+	if executed != 1 {
+		t.Fatalf("executed %d times", executed)
+	}
+}
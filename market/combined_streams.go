@@ -12,58 +12,73 @@ import (
 )
 
 type CombinedStreamsClient struct {
-	conn        *websocket.Conn
+	ws          *WSManager
 	mu          sync.RWMutex
 	subscribers map[string]chan []byte
-	reconnect   bool
-	done        chan struct{}
-	batchSize   int // 每批订阅的流数量
+	batchSize   int                            // 每批订阅的流数量
+	onGap       func(disconnectedAt time.Time) // 重连后补齐断线期间的数据
 }
 
 func NewCombinedStreamsClient(batchSize int) *CombinedStreamsClient {
-	return &CombinedStreamsClient{
+	c := &CombinedStreamsClient{
 		subscribers: make(map[string]chan []byte),
-		reconnect:   true,
-		done:        make(chan struct{}),
 		batchSize:   batchSize,
 	}
+	// 组合流使用不同的端点
+	c.ws = NewWSManager(WSManagerConfig{
+		Name:      "组合流",
+		URL:       "wss://fstream.binance.com/stream",
+		Subscribe: c.sendSubscribe,
+		OnMessage: c.handleCombinedMessage,
+		OnGap: func(disconnectedAt time.Time) {
+			if c.onGap != nil {
+				c.onGap(disconnectedAt)
+			}
+		},
+	})
+	return c
 }
 
 func (c *CombinedStreamsClient) Connect() error {
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-
-	// 组合流使用不同的端点
-	conn, _, err := dialer.Dial("wss://fstream.binance.com/stream", nil)
-	if err != nil {
-		return fmt.Errorf("组合流WebSocket连接失败: %v", err)
-	}
-
-	c.mu.Lock()
-	c.conn = conn
-	c.mu.Unlock()
-
-	log.Println("组合流WebSocket连接成功")
-	go c.readMessages()
+	return c.ws.Connect()
+}
 
-	return nil
+// OnReconnect 设置重连后的补数回调（参数为断线时间）
+func (c *CombinedStreamsClient) OnReconnect(fn func(disconnectedAt time.Time)) {
+	c.onGap = fn
 }
 
 // BatchSubscribeKlines 批量订阅K线
 func (c *CombinedStreamsClient) BatchSubscribeKlines(symbols []string, interval string) error {
-	// 将symbols分批处理
-	batches := c.splitIntoBatches(symbols, c.batchSize)
+	streams := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		streams[i] = fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval)
+	}
+	return c.subscribeStreams(streams)
+}
+
+// BatchSubscribeBookTickers 批量订阅最优挂单（流名称为 <小写symbol>@bookTicker）
+func (c *CombinedStreamsClient) BatchSubscribeBookTickers(symbols []string) error {
+	streams := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		streams[i] = strings.ToLower(symbol) + "@bookTicker"
+	}
+	return c.subscribeStreams(streams)
+}
+
+// sendSubscribe 分批发送订阅请求（首次连接及重连恢复订阅时共用）
+func (c *CombinedStreamsClient) sendSubscribe(conn *websocket.Conn, streams []string) error {
+	batches := c.splitIntoBatches(streams, c.batchSize)
 
 	for i, batch := range batches {
 		log.Printf("订阅第 %d 批, 数量: %d", i+1, len(batch))
 
-		streams := make([]string, len(batch))
-		for j, symbol := range batch {
-			streams[j] = fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval)
+		subscribeMsg := map[string]interface{}{
+			"method": "SUBSCRIBE",
+			"params": batch,
+			"id":     time.Now().UnixNano(),
 		}
-
-		if err := c.subscribeStreams(streams); err != nil {
+		if err := conn.WriteJSON(subscribeMsg); err != nil {
 			return fmt.Errorf("第 %d 批订阅失败: %v", i+1, err)
 		}
 
@@ -91,50 +106,9 @@ func (c *CombinedStreamsClient) splitIntoBatches(symbols []string, batchSize int
 	return batches
 }
 
-// subscribeStreams 订阅多个流
+// subscribeStreams 订阅多个流（已记录的流在重连后自动恢复订阅）
 func (c *CombinedStreamsClient) subscribeStreams(streams []string) error {
-	subscribeMsg := map[string]interface{}{
-		"method": "SUBSCRIBE",
-		"params": streams,
-		"id":     time.Now().UnixNano(),
-	}
-
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	if c.conn == nil {
-		return fmt.Errorf("WebSocket未连接")
-	}
-
-	log.Printf("订阅流: %v", streams)
-	return c.conn.WriteJSON(subscribeMsg)
-}
-
-func (c *CombinedStreamsClient) readMessages() {
-	for {
-		select {
-		case <-c.done:
-			return
-		default:
-			c.mu.RLock()
-			conn := c.conn
-			c.mu.RUnlock()
-
-			if conn == nil {
-				time.Sleep(1 * time.Second)
-				continue
-			}
-
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Printf("读取组合流消息失败: %v", err)
-				c.handleReconnect()
-				return
-			}
-
-			c.handleCombinedMessage(message)
-		}
-	}
+	return c.ws.Subscribe(streams...)
 }
 
 func (c *CombinedStreamsClient) handleCombinedMessage(message []byte) {
@@ -169,32 +143,12 @@ func (c *CombinedStreamsClient) AddSubscriber(stream string, bufferSize int) <-c
 	return ch
 }
 
-func (c *CombinedStreamsClient) handleReconnect() {
-	if !c.reconnect {
-		return
-	}
-
-	log.Println("组合流尝试重新连接...")
-	time.Sleep(3 * time.Second)
-
-	if err := c.Connect(); err != nil {
-		log.Printf("组合流重新连接失败: %v", err)
-		go c.handleReconnect()
-	}
-}
-
 func (c *CombinedStreamsClient) Close() {
-	c.reconnect = false
-	close(c.done)
+	c.ws.Close()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.conn != nil {
-		c.conn.Close()
-		c.conn = nil
-	}
-
 	for stream, ch := range c.subscribers {
 		close(ch)
 		delete(c.subscribers, stream)
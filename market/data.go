@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Get 获取指定代币的市场数据
@@ -28,6 +29,36 @@ func Get(symbol string) (*Data, error) {
 		return nil, fmt.Errorf("获取4小时K线失败: %v", err)
 	}
 
+	data, err := FromKlines(symbol, klines3m, klines4h)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取OI数据
+	oiData, err := getOpenInterestData(symbol)
+	if err != nil {
+		// OI失败不影响整体,使用默认值
+		oiData = &OIData{Latest: 0, Average: 0}
+	}
+	data.OpenInterest = oiData
+
+	// 获取Funding Rate
+	funding, err := GetFunding(symbol)
+	if err != nil {
+		funding = &FundingInfo{}
+	}
+	data.FundingRate = funding.Rate
+	data.NextFundingTime = funding.NextFundingTime
+
+	// 获取24小时行情统计（失败时prompt中不显示）
+	if stats, err := GetTicker24h(symbol); err == nil {
+		data.Stats24h = stats
+	}
+	return data, nil
+}
+
+// FromKlines 仅根据3分钟及4小时K线计算市场数据（不含持仓量和资金费率，回测回放时使用历史K线）
+func FromKlines(symbol string, klines3m, klines4h []Kline) (*Data, error) {
 	if len(klines3m) == 0 {
 		return nil, fmt.Errorf("3分钟K线数据为空")
 	}
@@ -57,16 +88,6 @@ func Get(symbol string) (*Data, error) {
 		}
 	}
 
-	// 获取OI数据
-	oiData, err := getOpenInterestData(symbol)
-	if err != nil {
-		// OI失败不影响整体,使用默认值
-		oiData = &OIData{Latest: 0, Average: 0}
-	}
-
-	// 获取Funding Rate
-	fundingRate, _ := getFundingRate(symbol)
-
 	// 计算日内系列数据
 	intradayData := calculateIntradaySeries(klines3m)
 
@@ -81,8 +102,6 @@ func Get(symbol string) (*Data, error) {
 		CurrentEMA20:      currentEMA20,
 		CurrentMACD:       currentMACD,
 		CurrentRSI7:       currentRSI7,
-		OpenInterest:      oiData,
-		FundingRate:       fundingRate,
 		IntradaySeries:    intradayData,
 		LongerTermContext: longerTermData,
 	}, nil
@@ -202,6 +221,40 @@ func calculateATR(klines []Kline, period int) float64 {
 	return atr
 }
 
+// GetKlines 获取指定周期的最近K线（3m/4h使用WebSocket缓存的K线，其他周期通过REST获取）
+func GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	symbol = Normalize(symbol)
+	if (interval == "3m" || interval == "4h") && WSMonitorCli != nil {
+		if klines, err := WSMonitorCli.GetCurrentKlines(symbol, interval); err == nil {
+			return klines, nil
+		}
+	}
+	klines, err := NewAPIClient().GetKlines(symbol, interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s K线失败: %v", interval, err)
+	}
+	return klines, nil
+}
+
+// ATR 计算K线的ATR（Wilder平滑，K线不足时返回0）
+func ATR(klines []Kline, period int) float64 {
+	return calculateATR(klines, period)
+}
+
+// GetATR 获取指定周期K线的ATR
+func GetATR(symbol, interval string, period int) (float64, error) {
+	klines, err := GetKlines(symbol, interval, period*3+1)
+	if err != nil {
+		return 0, err
+	}
+
+	atr := calculateATR(klines, period)
+	if atr <= 0 {
+		return 0, fmt.Errorf("%s %s K线不足，无法计算ATR%d", symbol, interval, period)
+	}
+	return atr, nil
+}
+
 // calculateIntradaySeries 计算日内系列数据
 func calculateIntradaySeries(klines []Kline) *IntradayData {
 	data := &IntradayData{
@@ -326,19 +379,19 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 	}, nil
 }
 
-// getFundingRate 获取资金费率
-func getFundingRate(symbol string) (float64, error) {
+// GetFunding 获取资金费率、标记价格及下次结算时间
+func GetFunding(symbol string) (*FundingInfo, error) {
 	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/premiumIndex?symbol=%s", symbol)
 
 	resp, err := http.Get(url)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	var result struct {
@@ -352,11 +405,17 @@ func getFundingRate(symbol string) (float64, error) {
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	rate, _ := strconv.ParseFloat(result.LastFundingRate, 64)
-	return rate, nil
+	markPrice, _ := strconv.ParseFloat(result.MarkPrice, 64)
+	indexPrice, _ := strconv.ParseFloat(result.IndexPrice, 64)
+	info := &FundingInfo{Rate: rate, MarkPrice: markPrice, IndexPrice: indexPrice}
+	if result.NextFundingTime > 0 {
+		info.NextFundingTime = time.UnixMilli(result.NextFundingTime)
+	}
+	return info, nil
 }
 
 // Format 格式化输出市场数据
@@ -376,6 +435,11 @@ func Format(data *Data) string {
 
 	sb.WriteString(fmt.Sprintf("Funding Rate: %.2e\n\n", data.FundingRate))
 
+	if data.Stats24h != nil {
+		sb.WriteString(fmt.Sprintf("24h stats: change %+.2f%%, high %.4f, low %.4f, quote volume %.0f USDT\n\n",
+			data.Stats24h.PriceChangePct, data.Stats24h.HighPrice, data.Stats24h.LowPrice, data.Stats24h.QuoteVolume))
+	}
+
 	if data.IntradaySeries != nil {
 		sb.WriteString("Intraday series (3‑minute intervals, oldest → latest):\n\n")
 
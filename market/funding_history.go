@@ -0,0 +1,92 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// FundingPeriodsPerYear 每年资金费结算次数（8小时一次）
+const FundingPeriodsPerYear = 3 * 365
+
+// fundingHistoryLimit 单次查询资金费率历史的最大条数（币安上限1000）
+const fundingHistoryLimit = 1000
+
+// FundingRate 一次资金费结算
+type FundingRate struct {
+	Symbol    string    `json:"symbol"`
+	Time      time.Time `json:"time"` // 结算时间
+	Rate      float64   `json:"rate"`
+	MarkPrice float64   `json:"mark_price,omitempty"`
+}
+
+// GetFundingHistory 获取start之后（含）已结算的资金费率，按时间升序，最多1000条
+func GetFundingHistory(symbol string, start time.Time) ([]FundingRate, error) {
+	symbol = Normalize(symbol)
+	url := fmt.Sprintf("%s/fapi/v1/fundingRate?symbol=%s&limit=%d", baseURL, symbol, fundingHistoryLimit)
+	if !start.IsZero() {
+		url += fmt.Sprintf("&startTime=%d", start.UnixMilli())
+	}
+	var raw []struct {
+		Symbol      string `json:"symbol"`
+		FundingTime int64  `json:"fundingTime"`
+		FundingRate string `json:"fundingRate"`
+		MarkPrice   string `json:"markPrice"`
+	}
+	if err := getJSON(url, &raw); err != nil {
+		return nil, fmt.Errorf("获取 %s 资金费率历史失败: %w", symbol, err)
+	}
+	rates := make([]FundingRate, 0, len(raw))
+	for _, r := range raw {
+		rate, err := strconv.ParseFloat(r.FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		markPrice, _ := strconv.ParseFloat(r.MarkPrice, 64)
+		rates = append(rates, FundingRate{Symbol: symbol, Time: time.UnixMilli(r.FundingTime), Rate: rate, MarkPrice: markPrice})
+	}
+	return rates, nil
+}
+
+// FundingStats 一段时间内的资金费率统计
+type FundingStats struct {
+	Symbol        string    `json:"symbol"`
+	Samples       int       `json:"samples"` // 结算次数
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	AverageRate   float64   `json:"average_rate"` // 平均每期费率
+	LatestRate    float64   `json:"latest_rate"`
+	MinRate       float64   `json:"min_rate"`
+	MaxRate       float64   `json:"max_rate"`
+	PositivePct   float64   `json:"positive_pct"`   // 正费率（空头收取）的期数占比
+	AnnualizedPct float64   `json:"annualized_pct"` // 平均费率年化（平均费率 × 每年结算次数）
+	CarryPct      float64   `json:"carry_pct"`      // 区间内空头累计收取的资金费（占名义价值）
+}
+
+// ComputeFundingStats 统计资金费率（rates按时间升序），没有数据时Samples为0
+func ComputeFundingStats(symbol string, rates []FundingRate) FundingStats {
+	stats := FundingStats{Symbol: symbol, Samples: len(rates)}
+	if len(rates) == 0 {
+		return stats
+	}
+	stats.From, stats.To = rates[0].Time, rates[len(rates)-1].Time
+	stats.LatestRate = rates[len(rates)-1].Rate
+	stats.MinRate, stats.MaxRate = math.Inf(1), math.Inf(-1)
+
+	var sum float64
+	positive := 0
+	for _, r := range rates {
+		sum += r.Rate
+		stats.MinRate = math.Min(stats.MinRate, r.Rate)
+		stats.MaxRate = math.Max(stats.MaxRate, r.Rate)
+		if r.Rate > 0 {
+			positive++
+		}
+	}
+	stats.AverageRate = sum / float64(len(rates))
+	stats.PositivePct = float64(positive) / float64(len(rates)) * 100
+	stats.AnnualizedPct = stats.AverageRate * FundingPeriodsPerYear * 100
+	stats.CarryPct = sum * 100
+	return stats
+}
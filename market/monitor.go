@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -41,6 +42,7 @@ func NewWSMonitor(batchSize int) *WSMonitor {
 		alertsChan:     make(chan Alert, 1000),
 		batchSize:      batchSize,
 	}
+	WSMonitorCli.combinedClient.OnReconnect(WSMonitorCli.backfillKlines)
 	return WSMonitorCli
 }
 
@@ -232,6 +234,95 @@ func (m *WSMonitor) processKlineUpdate(symbol string, wsData KlineWSData, _time
 	klineDataMap.Store(symbol, klines)
 }
 
+// backfillKlines 重连后通过REST补齐断线期间缺失的K线
+func (m *WSMonitor) backfillKlines(disconnectedAt time.Time) {
+	apiClient := NewAPIClient()
+	gap := time.Since(disconnectedAt)
+
+	for _, st := range subKlineTime {
+		// 断线期间的K线数 + 断线时未收盘及当前未收盘的两根
+		limit := 2
+		if d := klineIntervalDuration(st); d > 0 {
+			limit += int(gap / d)
+		}
+		if limit > 100 {
+			limit = 100
+		}
+
+		klineDataMap := m.getKlineDataMap(st)
+		var symbols []string
+		klineDataMap.Range(func(key, _ interface{}) bool {
+			symbols = append(symbols, key.(string))
+			return true
+		})
+
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, 5) // 限制并发数
+		filled := 0
+		var mu sync.Mutex
+		for _, symbol := range symbols {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(s string) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				fetched, err := apiClient.GetKlines(s, st, limit)
+				if err != nil || len(fetched) == 0 {
+					log.Printf("⚠️  补齐 %s %s K线失败: %v", s, st, err)
+					return
+				}
+				value, _ := klineDataMap.Load(s)
+				existing, _ := value.([]Kline)
+				klineDataMap.Store(s, mergeKlines(existing, fetched, 100))
+				mu.Lock()
+				filled++
+				mu.Unlock()
+			}(symbol)
+		}
+		wg.Wait()
+		log.Printf("✓ 已补齐 %d 个交易对断线期间（%s）的%s K线", filled, gap.Round(time.Second), st)
+	}
+}
+
+// mergeKlines 用REST获取的K线覆盖同一时间段及之后的数据，保留最近maxLen根
+func mergeKlines(existing, fetched []Kline, maxLen int) []Kline {
+	from := fetched[0].OpenTime
+	merged := make([]Kline, 0, len(existing)+len(fetched))
+	for _, k := range existing {
+		if k.OpenTime < from {
+			merged = append(merged, k)
+		}
+	}
+	merged = append(merged, fetched...)
+	if len(merged) > maxLen {
+		merged = merged[len(merged)-maxLen:]
+	}
+	return merged
+}
+
+// klineIntervalDuration K线周期时长（如 3m、4h、1d），无法识别时返回0
+func klineIntervalDuration(interval string) time.Duration {
+	if len(interval) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil {
+		return 0
+	}
+	switch interval[len(interval)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute
+	case 'h':
+		return time.Duration(n) * time.Hour
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour
+	}
+	return 0
+}
+
 func (m *WSMonitor) GetCurrentKlines(symbol string, _time string) ([]Kline, error) {
 	// 对每一个进来的symbol检测是否存在内类 是否的话就订阅它
 	value, exists := m.getKlineDataMap(_time).Load(symbol)
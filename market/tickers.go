@@ -0,0 +1,149 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ticker24h 24小时行情统计
+type Ticker24h struct {
+	Symbol         string  `json:"symbol"`
+	LastPrice      float64 `json:"last_price"`
+	HighPrice      float64 `json:"high_price"`
+	LowPrice       float64 `json:"low_price"`
+	PriceChangePct float64 `json:"price_change_pct"`
+	Volume         float64 `json:"volume"`       // 成交量（标的数量）
+	QuoteVolume    float64 `json:"quote_volume"` // 成交额（USDT）
+}
+
+// Ticker 单个合约的24小时行情及资金费率
+type Ticker struct {
+	Ticker24h
+	MarkPrice       float64   `json:"mark_price"`
+	IndexPrice      float64   `json:"index_price"`
+	FundingRate     float64   `json:"funding_rate"` // 当前资金费率（每个结算周期）
+	NextFundingTime time.Time `json:"next_funding_time"`
+}
+
+// ticker24hRaw 24小时行情接口的原始响应
+type ticker24hRaw struct {
+	Symbol             string `json:"symbol"`
+	LastPrice          string `json:"lastPrice"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	Volume             string `json:"volume"`
+	QuoteVolume        string `json:"quoteVolume"`
+}
+
+// parse 转换为 Ticker24h
+func (r ticker24hRaw) parse() Ticker24h {
+	t := Ticker24h{Symbol: r.Symbol}
+	t.LastPrice, _ = strconv.ParseFloat(r.LastPrice, 64)
+	t.HighPrice, _ = strconv.ParseFloat(r.HighPrice, 64)
+	t.LowPrice, _ = strconv.ParseFloat(r.LowPrice, 64)
+	t.PriceChangePct, _ = strconv.ParseFloat(r.PriceChangePercent, 64)
+	t.Volume, _ = strconv.ParseFloat(r.Volume, 64)
+	t.QuoteVolume, _ = strconv.ParseFloat(r.QuoteVolume, 64)
+	return t
+}
+
+// BookTicker 最优买卖挂单
+type BookTicker struct {
+	Symbol   string  `json:"symbol"`
+	BidPrice float64 `json:"bid_price"`
+	AskPrice float64 `json:"ask_price"`
+}
+
+// SpreadBps 买卖价差（基点，相对中间价）
+func (b BookTicker) SpreadBps() float64 {
+	mid := (b.BidPrice + b.AskPrice) / 2
+	if mid <= 0 {
+		return 0
+	}
+	return (b.AskPrice - b.BidPrice) / mid * 1e4
+}
+
+// GetTickers24h 获取所有合约的24小时行情统计
+func GetTickers24h() ([]Ticker24h, error) {
+	var raw []ticker24hRaw
+	if err := getJSON(baseURL+"/fapi/v1/ticker/24hr", &raw); err != nil {
+		return nil, fmt.Errorf("获取24小时行情失败: %w", err)
+	}
+	tickers := make([]Ticker24h, 0, len(raw))
+	for _, r := range raw {
+		tickers = append(tickers, r.parse())
+	}
+	return tickers, nil
+}
+
+// GetTicker24h 获取单个合约的24小时行情统计
+func GetTicker24h(symbol string) (*Ticker24h, error) {
+	var raw ticker24hRaw
+	if err := getJSON(baseURL+"/fapi/v1/ticker/24hr?symbol="+Normalize(symbol), &raw); err != nil {
+		return nil, fmt.Errorf("获取 %s 24小时行情失败: %w", symbol, err)
+	}
+	t := raw.parse()
+	return &t, nil
+}
+
+// GetTicker 获取单个合约的24小时行情、标记价格及资金费率
+func GetTicker(symbol string) (*Ticker, error) {
+	symbol = Normalize(symbol)
+	stats, err := GetTicker24h(symbol)
+	if err != nil {
+		return nil, err
+	}
+	funding, err := GetFunding(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 资金费率失败: %w", symbol, err)
+	}
+	return &Ticker{
+		Ticker24h:       *stats,
+		MarkPrice:       funding.MarkPrice,
+		IndexPrice:      funding.IndexPrice,
+		FundingRate:     funding.Rate,
+		NextFundingTime: funding.NextFundingTime,
+	}, nil
+}
+
+// GetBookTickers 获取所有合约的最优买卖挂单
+func GetBookTickers() (map[string]BookTicker, error) {
+	var raw []struct {
+		Symbol   string `json:"symbol"`
+		BidPrice string `json:"bidPrice"`
+		AskPrice string `json:"askPrice"`
+	}
+	if err := getJSON(baseURL+"/fapi/v1/ticker/bookTicker", &raw); err != nil {
+		return nil, fmt.Errorf("获取最优挂单失败: %w", err)
+	}
+	books := make(map[string]BookTicker, len(raw))
+	for _, r := range raw {
+		b := BookTicker{Symbol: r.Symbol}
+		b.BidPrice, _ = strconv.ParseFloat(r.BidPrice, 64)
+		b.AskPrice, _ = strconv.ParseFloat(r.AskPrice, 64)
+		books[r.Symbol] = b
+	}
+	return books, nil
+}
+
+// getJSON 请求公开接口并解析JSON
+func getJSON(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, v)
+}
@@ -13,10 +13,20 @@ type Data struct {
 	CurrentRSI7       float64
 	OpenInterest      *OIData
 	FundingRate       float64
+	NextFundingTime   time.Time  // 下次资金费结算时间
+	Stats24h          *Ticker24h // 24小时行情统计（回测回放时为空）
 	IntradaySeries    *IntradayData
 	LongerTermContext *LongerTermData
 }
 
+// FundingInfo 资金费率信息
+type FundingInfo struct {
+	Rate            float64   // 当前资金费率（每个结算周期）
+	MarkPrice       float64   // 标记价格
+	IndexPrice      float64   // 指数价格
+	NextFundingTime time.Time // 下次结算时间
+}
+
 // OIData Open Interest数据
 type OIData struct {
 	Latest  float64
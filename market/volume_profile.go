@@ -0,0 +1,89 @@
+package market
+
+import (
+	"sync"
+	"time"
+)
+
+// volumeProfileTTL 日内成交量分布的缓存时长
+const volumeProfileTTL = 6 * time.Hour
+
+// VolumeProfile 日内成交量分布（按UTC小时，各项之和为1）
+type VolumeProfile [24]float64
+
+// UniformProfile 均匀分布（TWAP，或历史数据不足时使用）
+func UniformProfile() VolumeProfile {
+	var p VolumeProfile
+	for i := range p {
+		p[i] = 1.0 / 24
+	}
+	return p
+}
+
+// BuildVolumeProfile 由1小时K线按UTC小时统计平均成交额占比（没有成交额时返回均匀分布）
+func BuildVolumeProfile(klines []Kline) VolumeProfile {
+	var sums VolumeProfile
+	total := 0.0
+	for _, k := range klines {
+		hour := time.UnixMilli(k.OpenTime).UTC().Hour()
+		sums[hour] += k.QuoteVolume
+		total += k.QuoteVolume
+	}
+	if total <= 0 {
+		return UniformProfile()
+	}
+	for i := range sums {
+		sums[i] /= total
+	}
+	return sums
+}
+
+// Weight 区间 [from, to) 内的成交量占全天的比例（按小时内均匀成交估算）
+func (p VolumeProfile) Weight(from, to time.Time) float64 {
+	weight := 0.0
+	for t := from.UTC(); t.Before(to); {
+		hourEnd := t.Truncate(time.Hour).Add(time.Hour)
+		end := hourEnd
+		if to.Before(end) {
+			end = to
+		}
+		weight += p[t.Hour()] * end.Sub(t).Hours()
+		t = end
+	}
+	return weight
+}
+
+var volumeProfiles = struct {
+	sync.Mutex
+	entries map[string]volumeProfileEntry
+}{entries: make(map[string]volumeProfileEntry)}
+
+// volumeProfileEntry 缓存的成交量分布
+type volumeProfileEntry struct {
+	profile   VolumeProfile
+	fetchedAt time.Time
+}
+
+// GetVolumeProfile 获取最近days天（默认7天）1小时K线统计的日内成交量分布（缓存6小时）
+func GetVolumeProfile(symbol string, days int) (VolumeProfile, error) {
+	if days <= 0 {
+		days = 7
+	}
+	symbol = Normalize(symbol)
+	volumeProfiles.Lock()
+	entry, ok := volumeProfiles.entries[symbol]
+	volumeProfiles.Unlock()
+	if ok && time.Since(entry.fetchedAt) < volumeProfileTTL {
+		return entry.profile, nil
+	}
+
+	klines, err := GetKlines(symbol, "1h", days*24)
+	if err != nil {
+		return VolumeProfile{}, err
+	}
+	profile := BuildVolumeProfile(klines)
+	volumeProfiles.Lock()
+	volumeProfiles.entries[symbol] = volumeProfileEntry{profile: profile, fetchedAt: time.Now()}
+	volumeProfiles.Unlock()
+	return profile, nil
+}
@@ -11,11 +11,9 @@ import (
 )
 
 type WSClient struct {
-	conn        *websocket.Conn
+	ws          *WSManager
 	mu          sync.RWMutex
 	subscribers map[string]chan []byte
-	reconnect   bool
-	done        chan struct{}
 }
 
 type WSMessage struct {
@@ -68,34 +66,33 @@ type TickerWSData struct {
 	Count              int    `json:"n"`
 }
 
+// BookTickerWSData 最优挂单推送（<symbol>@bookTicker）
+type BookTickerWSData struct {
+	EventType string `json:"e"`
+	UpdateID  int64  `json:"u"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	BidPrice  string `json:"b"`
+	BidQty    string `json:"B"`
+	AskPrice  string `json:"a"`
+	AskQty    string `json:"A"`
+}
+
 func NewWSClient() *WSClient {
-	return &WSClient{
+	w := &WSClient{
 		subscribers: make(map[string]chan []byte),
-		reconnect:   true,
-		done:        make(chan struct{}),
 	}
+	w.ws = NewWSManager(WSManagerConfig{
+		Name:      "Binance",
+		URL:       "wss://ws-fapi.binance.com/ws-fapi/v1",
+		Subscribe: w.sendSubscribe,
+		OnMessage: w.handleMessage,
+	})
+	return w
 }
 
 func (w *WSClient) Connect() error {
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-
-	conn, _, err := dialer.Dial("wss://ws-fapi.binance.com/ws-fapi/v1", nil)
-	if err != nil {
-		return fmt.Errorf("WebSocket连接失败: %v", err)
-	}
-
-	w.mu.Lock()
-	w.conn = conn
-	w.mu.Unlock()
-
-	log.Println("WebSocket连接成功")
-
-	// 启动消息读取循环
-	go w.readMessages()
-
-	return nil
+	return w.ws.Connect()
 }
 
 func (w *WSClient) SubscribeKline(symbol, interval string) error {
@@ -113,56 +110,27 @@ func (w *WSClient) SubscribeMiniTicker(symbol string) error {
 	return w.subscribe(stream)
 }
 
+// subscribe 订阅流（已记录的流在重连后自动恢复订阅）
 func (w *WSClient) subscribe(stream string) error {
+	return w.ws.Subscribe(stream)
+}
+
+// sendSubscribe 发送订阅请求
+func (w *WSClient) sendSubscribe(conn *websocket.Conn, streams []string) error {
 	subscribeMsg := map[string]interface{}{
 		"method": "SUBSCRIBE",
-		"params": []string{stream},
+		"params": streams,
 		"id":     time.Now().Unix(),
 	}
 
-	w.mu.RLock()
-	defer w.mu.RUnlock()
-
-	if w.conn == nil {
-		return fmt.Errorf("WebSocket未连接")
-	}
-
-	err := w.conn.WriteJSON(subscribeMsg)
-	if err != nil {
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
 		return err
 	}
 
-	log.Printf("订阅流: %s", stream)
+	log.Printf("订阅流: %v", streams)
 	return nil
 }
 
-func (w *WSClient) readMessages() {
-	for {
-		select {
-		case <-w.done:
-			return
-		default:
-			w.mu.RLock()
-			conn := w.conn
-			w.mu.RUnlock()
-
-			if conn == nil {
-				time.Sleep(1 * time.Second)
-				continue
-			}
-
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Printf("读取WebSocket消息失败: %v", err)
-				w.handleReconnect()
-				return
-			}
-
-			w.handleMessage(message)
-		}
-	}
-}
-
 func (w *WSClient) handleMessage(message []byte) {
 	var wsMsg WSMessage
 	if err := json.Unmarshal(message, &wsMsg); err != nil {
@@ -183,20 +151,6 @@ func (w *WSClient) handleMessage(message []byte) {
 	}
 }
 
-func (w *WSClient) handleReconnect() {
-	if !w.reconnect {
-		return
-	}
-
-	log.Println("尝试重新连接...")
-	time.Sleep(3 * time.Second)
-
-	if err := w.Connect(); err != nil {
-		log.Printf("重新连接失败: %v", err)
-		go w.handleReconnect()
-	}
-}
-
 func (w *WSClient) AddSubscriber(stream string, bufferSize int) <-chan []byte {
 	ch := make(chan []byte, bufferSize)
 	w.mu.Lock()
@@ -212,17 +166,11 @@ func (w *WSClient) RemoveSubscriber(stream string) {
 }
 
 func (w *WSClient) Close() {
-	w.reconnect = false
-	close(w.done)
+	w.ws.Close()
 
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.conn != nil {
-		w.conn.Close()
-		w.conn = nil
-	}
-
 	// 关闭所有订阅者通道
 	for stream, ch := range w.subscribers {
 		close(ch)
@@ -0,0 +1,271 @@
+package market
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WSManagerConfig WebSocket连接管理配置
+type WSManagerConfig struct {
+	Name         string        // 日志中的连接名称
+	URL          string        // 连接地址
+	PingInterval time.Duration // 心跳间隔（默认30秒），超过3个间隔未收到任何数据视为断线
+	MinBackoff   time.Duration // 首次重连等待（默认1秒），之后每次翻倍
+	MaxBackoff   time.Duration // 最长重连等待（默认1分钟）
+
+	// Subscribe 向连接发送订阅请求；连接建立（含重连）后以全部已记录的流调用，用于恢复订阅
+	Subscribe func(conn *websocket.Conn, streams []string) error
+	// OnMessage 收到一条消息
+	OnMessage func(message []byte)
+	// OnGap 重连并恢复订阅后调用（参数为断线时间），用于通过REST补齐断线期间缺失的数据
+	OnGap func(disconnectedAt time.Time)
+}
+
+// WSManager 可复用的WebSocket连接管理：心跳检测、指数退避重连、重连后恢复订阅及断线补数
+// 各交易所的行情流只需提供订阅报文格式和消息处理
+type WSManager struct {
+	cfg WSManagerConfig
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	streams []string
+	known   map[string]bool
+
+	writeMu   sync.Mutex // gorilla/websocket 不支持并发写
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWSManager 创建WebSocket连接管理器
+func NewWSManager(cfg WSManagerConfig) *WSManager {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = time.Minute
+	}
+	return &WSManager{
+		cfg:   cfg,
+		known: make(map[string]bool),
+		done:  make(chan struct{}),
+	}
+}
+
+// Connect 建立首次连接，之后断线由后台自动重连
+func (m *WSManager) Connect() error {
+	conn, err := m.dial()
+	if err != nil {
+		return err
+	}
+	log.Printf("✓ %s WebSocket连接成功", m.cfg.Name)
+	go m.run(conn)
+	return nil
+}
+
+// Subscribe 记录并订阅流（未连接时在连接建立后自动订阅）
+func (m *WSManager) Subscribe(streams ...string) error {
+	m.mu.Lock()
+	var added []string
+	for _, stream := range streams {
+		if !m.known[stream] {
+			m.known[stream] = true
+			m.streams = append(m.streams, stream)
+			added = append(added, stream)
+		}
+	}
+	conn := m.conn
+	m.mu.Unlock()
+
+	if len(added) == 0 || conn == nil {
+		return nil
+	}
+	return m.send(conn, added)
+}
+
+// Streams 当前记录的全部订阅流
+func (m *WSManager) Streams() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.streams...)
+}
+
+// Connected 当前是否已连接
+func (m *WSManager) Connected() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conn != nil
+}
+
+// Close 关闭连接并停止重连
+func (m *WSManager) Close() {
+	m.closeOnce.Do(func() {
+		close(m.done)
+		m.mu.Lock()
+		if m.conn != nil {
+			m.conn.Close()
+			m.conn = nil
+		}
+		m.mu.Unlock()
+	})
+}
+
+// dial 建立连接、设置心跳并恢复全部订阅
+func (m *WSManager) dial() (*websocket.Conn, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+	conn, _, err := dialer.Dial(m.cfg.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s WebSocket连接失败: %w", m.cfg.Name, err)
+	}
+
+	// 收到任何数据（消息/ping/pong）都顺延读超时
+	deadline := 3 * m.cfg.PingInterval
+	conn.SetReadDeadline(time.Now().Add(deadline))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(deadline))
+	})
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(deadline))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(10*time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	streams := m.Streams()
+	if len(streams) > 0 {
+		if err := m.send(conn, streams); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s 恢复订阅失败: %w", m.cfg.Name, err)
+		}
+		log.Printf("✓ %s 已恢复 %d 个订阅流", m.cfg.Name, len(streams))
+	}
+
+	m.mu.Lock()
+	select {
+	case <-m.done:
+		m.mu.Unlock()
+		conn.Close()
+		return nil, fmt.Errorf("%s 已关闭", m.cfg.Name)
+	default:
+	}
+	m.conn = conn
+	missed := append([]string(nil), m.streams[len(streams):]...) // 恢复订阅期间新增的流
+	m.mu.Unlock()
+
+	if len(missed) > 0 {
+		if err := m.send(conn, missed); err != nil {
+			log.Printf("⚠️  %s 订阅失败: %v", m.cfg.Name, err)
+		}
+	}
+	return conn, nil
+}
+
+// send 发送订阅请求
+func (m *WSManager) send(conn *websocket.Conn, streams []string) error {
+	if m.cfg.Subscribe == nil {
+		return nil
+	}
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	return m.cfg.Subscribe(conn, streams)
+}
+
+// run 读取消息，断线后按指数退避重连并触发补数
+func (m *WSManager) run(conn *websocket.Conn) {
+	for {
+		stopPing := make(chan struct{})
+		go m.heartbeat(conn, stopPing)
+		err := m.readLoop(conn)
+		close(stopPing)
+
+		m.mu.Lock()
+		if m.conn == conn {
+			m.conn = nil
+		}
+		m.mu.Unlock()
+		conn.Close()
+
+		select {
+		case <-m.done:
+			return
+		default:
+		}
+
+		disconnectedAt := time.Now()
+		log.Printf("⚠️  %s WebSocket断开: %v", m.cfg.Name, err)
+
+		conn = m.reconnect()
+		if conn == nil {
+			return
+		}
+		log.Printf("✓ %s WebSocket重连成功（断线 %s）", m.cfg.Name, time.Since(disconnectedAt).Round(time.Second))
+		if m.cfg.OnGap != nil {
+			go m.cfg.OnGap(disconnectedAt)
+		}
+	}
+}
+
+// readLoop 持续读取消息直到出错
+func (m *WSManager) readLoop(conn *websocket.Conn) error {
+	deadline := 3 * m.cfg.PingInterval
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(deadline))
+		if m.cfg.OnMessage != nil {
+			m.cfg.OnMessage(message)
+		}
+	}
+}
+
+// heartbeat 定时发送ping
+func (m *WSManager) heartbeat(conn *websocket.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(m.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-m.done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// reconnect 指数退避重连，关闭时返回nil
+func (m *WSManager) reconnect() *websocket.Conn {
+	backoff := m.cfg.MinBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-m.done:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		conn, err := m.dial()
+		if err == nil {
+			return conn
+		}
+		backoff *= 2
+		if backoff > m.cfg.MaxBackoff {
+			backoff = m.cfg.MaxBackoff
+		}
+		log.Printf("⚠️  %s 第 %d 次重连失败: %v（%s 后重试）", m.cfg.Name, attempt, err, backoff)
+	}
+}
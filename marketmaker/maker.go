@@ -0,0 +1,294 @@
+package marketmaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/market"
+	"nofx/trader"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config 做市配置
+type Config struct {
+	TraderID         string  `json:"trader_id"`          // 提供交易所账户的交易员（需支持限价挂单，币安需单向持仓模式）
+	Symbol           string  `json:"symbol"`             // 做市合约
+	OrderQty         float64 `json:"order_qty"`          // 每侧挂单数量
+	MaxInventory     float64 `json:"max_inventory"`      // 最大库存（标的数量），达到后停止该方向的挂单
+	HalfSpreadBps    float64 `json:"half_spread_bps"`    // 相对保留价的半价差（默认10 = 0.1%）
+	SkewBps          float64 `json:"skew_bps"`           // 库存满仓时保留价偏移（默认等于半价差）
+	RequoteBps       float64 `json:"requote_bps"`        // 目标价偏离挂单价超过该值才改单（默认2）
+	MinRequoteMillis int     `json:"min_requote_millis"` // 两次改单的最小间隔（默认1000毫秒）
+	InventorySeconds int     `json:"inventory_seconds"`  // 库存刷新间隔（默认5秒）
+	Leverage         int     `json:"leverage"`           // 杠杆（默认1）
+}
+
+// ParseConfigs 解析做市配置（JSON数组）
+func ParseConfigs(s string) ([]Config, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var configs []Config
+	if err := json.Unmarshal([]byte(s), &configs); err != nil {
+		return nil, fmt.Errorf("解析做市配置失败: %w", err)
+	}
+	for i := range configs {
+		c := &configs[i]
+		c.Symbol = market.Normalize(c.Symbol)
+		if c.TraderID == "" || c.OrderQty <= 0 || c.MaxInventory <= 0 {
+			return nil, fmt.Errorf("做市配置 #%d 缺少 trader_id、order_qty 或 max_inventory", i+1)
+		}
+		if c.HalfSpreadBps <= 0 {
+			c.HalfSpreadBps = 10
+		}
+		if c.SkewBps <= 0 {
+			c.SkewBps = c.HalfSpreadBps
+		}
+		if c.RequoteBps <= 0 {
+			c.RequoteBps = 2
+		}
+		if c.MinRequoteMillis <= 0 {
+			c.MinRequoteMillis = 1000
+		}
+		if c.InventorySeconds <= 0 {
+			c.InventorySeconds = 5
+		}
+		if c.Leverage <= 0 {
+			c.Leverage = 1
+		}
+	}
+	return configs, nil
+}
+
+// quote 一侧的挂单
+type quote struct {
+	side    string
+	orderID int64
+	price   float64
+	qty     float64
+}
+
+// QuoteTrader 做市的下单通道（由交易员的 trader.StrategyTrader 提供，挂单经过观察模式、模拟交易、暂停状态、
+// 持仓归属、维护及下架和资金分配检查，与同一合约的其他下单串行执行并写入下单意图日志）
+type QuoteTrader interface {
+	SupportsLimitOrders() bool
+	IsHalted() bool
+	SetLeverage(symbol string, leverage int) error
+	GetPositions() ([]map[string]interface{}, error)
+	ClaimPositions(symbol string) error
+	PlaceLimitOrder(symbol, side string, quantity, price float64, postOnly bool) (int64, error)
+	AmendOrder(symbol string, orderID int64, side string, quantity, price float64) error
+	CancelOrder(symbol string, orderID int64) error
+}
+
+var _ QuoteTrader = (*trader.StrategyTrader)(nil)
+
+// Maker 库存感知的做市：围绕中间价双边挂Maker单，按库存偏移保留价，
+// 收到最优挂单推送（WebSocket）时改单跟随价格
+// 同一合约不应同时由AI策略交易，否则库存会被打乱
+type Maker struct {
+	config Config
+	trader QuoteTrader
+	stream *market.CombinedStreamsClient
+
+	bid, ask    quote
+	inventory   float64 // 当前净持仓（多为正、空为负）
+	inventoryAt time.Time
+	quotedAt    time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewMaker 创建做市策略（交易所需支持限价挂单）
+func NewMaker(config Config, t QuoteTrader) (*Maker, error) {
+	if !t.SupportsLimitOrders() {
+		return nil, fmt.Errorf("交易员 %s 的交易所不支持限价挂单", config.TraderID)
+	}
+	return &Maker{
+		config: config,
+		trader: t,
+		bid:    quote{side: trader.OrderSideBuy},
+		ask:    quote{side: trader.OrderSideSell},
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+// Start 订阅最优挂单并开始报价
+func (m *Maker) Start() error {
+	if err := m.trader.SetLeverage(m.config.Symbol, m.config.Leverage); err != nil {
+		return err
+	}
+	if err := m.refreshInventory(time.Now()); err != nil {
+		return err
+	}
+
+	m.stream = market.NewCombinedStreamsClient(10)
+	updates := m.stream.AddSubscriber(strings.ToLower(m.config.Symbol)+"@bookTicker", 16)
+	if err := m.stream.Connect(); err != nil {
+		return fmt.Errorf("连接行情WebSocket失败: %w", err)
+	}
+	if err := m.stream.BatchSubscribeBookTickers([]string{m.config.Symbol}); err != nil {
+		m.stream.Close()
+		return fmt.Errorf("订阅最优挂单失败: %w", err)
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case data, ok := <-updates:
+				if !ok {
+					return
+				}
+				var book market.BookTickerWSData
+				if err := json.Unmarshal(data, &book); err != nil {
+					continue
+				}
+				bid, _ := strconv.ParseFloat(book.BidPrice, 64)
+				ask, _ := strconv.ParseFloat(book.AskPrice, 64)
+				if bid > 0 && ask >= bid {
+					m.onBook(bid, ask, time.Now())
+				}
+			}
+		}
+	}()
+	log.Printf("🏦 做市已启动: %s 每侧 %.4f（半价差 %.1fbps，最大库存 %.4f）",
+		m.config.Symbol, m.config.OrderQty, m.config.HalfSpreadBps, m.config.MaxInventory)
+	return nil
+}
+
+// Stop 停止报价并撤销挂单（保留已有库存）
+func (m *Maker) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		m.wg.Wait()
+		if m.stream != nil {
+			m.stream.Close()
+		}
+		m.cancel(&m.bid)
+		m.cancel(&m.ask)
+		log.Printf("🏦 做市已停止: %s（库存 %+.4f）", m.config.Symbol, m.inventory)
+	})
+}
+
+// Quotes 根据最优买卖价和库存计算目标买卖价
+// 保留价 = 中间价 × (1 - 库存占比 × 偏移)，多头库存时整体下移以促成卖出
+func (m *Maker) Quotes(bestBid, bestAsk, inventory float64) (bidPrice, askPrice float64) {
+	mid := (bestBid + bestAsk) / 2
+	ratio := math.Max(-1, math.Min(1, inventory/m.config.MaxInventory))
+	reservation := mid * (1 - ratio*m.config.SkewBps/1e4)
+
+	bidPrice = reservation * (1 - m.config.HalfSpreadBps/1e4)
+	askPrice = reservation * (1 + m.config.HalfSpreadBps/1e4)
+	// Maker单不能穿越盘口
+	return math.Min(bidPrice, bestBid), math.Max(askPrice, bestAsk)
+}
+
+// onBook 处理最优挂单更新
+func (m *Maker) onBook(bestBid, bestAsk float64, now time.Time) {
+	// 紧急停止后撤掉报价且不再挂单
+	if m.trader.IsHalted() {
+		m.cancel(&m.bid)
+		m.cancel(&m.ask)
+		return
+	}
+	if now.Sub(m.quotedAt) < time.Duration(m.config.MinRequoteMillis)*time.Millisecond {
+		return
+	}
+	m.quotedAt = now
+
+	if now.Sub(m.inventoryAt) >= time.Duration(m.config.InventorySeconds)*time.Second {
+		if err := m.refreshInventory(now); err != nil {
+			log.Printf("⚠️  [做市 %s] %v", m.config.Symbol, err)
+			return
+		}
+	}
+
+	bidPrice, askPrice := m.Quotes(bestBid, bestAsk, m.inventory)
+	bidQty, askQty := m.config.OrderQty, m.config.OrderQty
+	if m.inventory >= m.config.MaxInventory {
+		bidQty = 0
+	}
+	if m.inventory <= -m.config.MaxInventory {
+		askQty = 0
+	}
+	m.requote(&m.bid, bidQty, bidPrice)
+	m.requote(&m.ask, askQty, askPrice)
+}
+
+// requote 按目标价格和数量挂单、改单或撤单
+func (m *Maker) requote(q *quote, qty, price float64) {
+	if qty <= 0 {
+		m.cancel(q)
+		return
+	}
+	if q.orderID != 0 {
+		if q.qty == qty && math.Abs(price-q.price)/q.price*1e4 < m.config.RequoteBps {
+			return
+		}
+		err := m.trader.AmendOrder(m.config.Symbol, q.orderID, q.side, qty, price)
+		if err == nil {
+			q.price, q.qty = price, qty
+			return
+		}
+		// 改单失败通常是挂单已成交或被撤销：重新挂单并尽快刷新库存
+		log.Printf("⚠️  [做市 %s] %s 改单失败，重新挂单: %v", m.config.Symbol, q.side, err)
+		q.orderID = 0
+		m.inventoryAt = time.Time{}
+	}
+
+	orderID, err := m.trader.PlaceLimitOrder(m.config.Symbol, q.side, qty, price, true)
+	if err != nil {
+		log.Printf("⚠️  [做市 %s] %s 挂单失败: %v", m.config.Symbol, q.side, err)
+		return
+	}
+	q.orderID, q.price, q.qty = orderID, price, qty
+}
+
+// cancel 撤销一侧的挂单
+func (m *Maker) cancel(q *quote) {
+	if q.orderID == 0 {
+		return
+	}
+	if err := m.trader.CancelOrder(m.config.Symbol, q.orderID); err != nil {
+		log.Printf("⚠️  [做市 %s] 撤销%s单失败: %v", m.config.Symbol, q.side, err)
+	}
+	q.orderID = 0
+}
+
+// refreshInventory 从交易所持仓刷新库存
+func (m *Maker) refreshInventory(now time.Time) error {
+	positions, err := m.trader.GetPositions()
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	var inventory float64
+	for _, pos := range positions {
+		if symbol, _ := pos["symbol"].(string); symbol != m.config.Symbol {
+			continue
+		}
+		amt, _ := pos["positionAmt"].(float64)
+		if side, _ := pos["side"].(string); side == "short" {
+			inventory -= math.Abs(amt)
+		} else {
+			inventory += math.Abs(amt)
+		}
+	}
+	// 挂单成交产生新的库存时认领持仓归属，AI策略不会平掉做市库存
+	if inventory != 0 && m.inventory == 0 {
+		if err := m.trader.ClaimPositions(m.config.Symbol); err != nil {
+			return err
+		}
+	}
+	m.inventory, m.inventoryAt = inventory, now
+	return nil
+}
@@ -28,6 +28,15 @@ type Client struct {
 	Model      string
 	Timeout    time.Duration
 	UseFullURL bool // 是否使用完整URL（不添加/chat/completions）
+
+	OnUsage func(model string, usage Usage) // 每次调用成功后回调本次token用量（可选，用于成本统计）
+}
+
+// Usage 单次调用的token用量（OpenAI兼容的 usage 字段）
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 func New() *Client {
@@ -256,12 +265,17 @@ func (client *Client) callOnce(systemPrompt, userPrompt string) (string, error)
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage Usage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
 		return "", fmt.Errorf("解析响应失败: %w", err)
 	}
 
+	if client.OnUsage != nil {
+		client.OnUsage(client.Model, result.Usage)
+	}
+
 	if len(result.Choices) == 0 {
 		return "", fmt.Errorf("API返回空响应")
 	}
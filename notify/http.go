@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// postAttempts 推送失败（网络错误、429及5xx）时的最多尝试次数
+const postAttempts = 3
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON POST JSON并在可重试的失败时退避重试（1s、2s…），非2xx响应视为失败
+func postJSON(ctx context.Context, url string, payload []byte, headers map[string]string) error {
+	var lastErr error
+	for attempt := 1; attempt <= postAttempts; attempt++ {
+		retry, err := postOnce(ctx, url, payload, headers)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == postAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+	}
+	return lastErr
+}
+
+// postOnce 发送一次请求，返回失败时是否值得重试
+func postOnce(ctx context.Context, url string, payload []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
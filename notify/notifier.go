@@ -0,0 +1,105 @@
+// Package notify 运维通知渠道（Telegram、邮件等）的统一接口：订阅事件总线，把关键执行事件、存活通知及业绩摘要推送给运维人员
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/trader"
+	"strings"
+	"time"
+)
+
+// sendTimeout 单条通知的发送超时
+const sendTimeout = 30 * time.Second
+
+// OperatorTypes 推送给运维人员的事件类型（各通知渠道的默认订阅）
+var OperatorTypes = []events.Type{events.StopLossHit, events.TakeProfitHit, events.Error, events.LeaderboardSummary, events.StablecoinDepeg, events.ExchangeMaintenance, events.ContractDelisting, events.NewListing, events.ExecutionReport, events.ManualOrderPending, events.OrderRecovered, events.ContractSpecChanged, events.Heartbeat, events.PerformanceDigest, events.BalanceMismatch, events.LiquidationRisk, events.KillSwitch, events.AuthFailure}
+
+// Notifier 通知渠道
+type Notifier interface {
+	// Name 渠道名称（用于日志及订阅者名称）
+	Name() string
+	// Notify 发送一条事件通知
+	Notify(ctx context.Context, event events.Event) error
+}
+
+// Subscribe 把通知渠道注册为事件总线订阅者（types为空时订阅 OperatorTypes），返回取消订阅函数
+func Subscribe(bus *events.Bus, n Notifier, types ...events.Type) func() {
+	if len(types) == 0 {
+		types = OperatorTypes
+	}
+	return bus.Subscribe(n.Name(), handler(n), types...)
+}
+
+// SubscribeAll 把通知渠道注册为全部事件的订阅者（如事件webhook），返回取消订阅函数
+func SubscribeAll(bus *events.Bus, n Notifier) func() {
+	return bus.Subscribe(n.Name(), handler(n))
+}
+
+// handler 带超时发送通知，失败时记录日志
+func handler(n Notifier) events.Handler {
+	return func(event events.Event) {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := n.Notify(ctx, event); err != nil {
+			log.Printf("⚠️  %s 发送 %s 通知失败: %v", n.Name(), event.Type, err)
+		}
+	}
+}
+
+// ParseTypes 解析逗号分隔的事件类型（空字符串返回nil，即默认订阅）
+func ParseTypes(s string) ([]events.Type, error) {
+	known := make(map[events.Type]bool, len(OperatorTypes))
+	for _, t := range OperatorTypes {
+		known[t] = true
+	}
+	var types []events.Type
+	for _, part := range strings.Split(s, ",") {
+		t := events.Type(strings.ToLower(strings.TrimSpace(part)))
+		if t == "" {
+			continue
+		}
+		if !known[t] {
+			return nil, fmt.Errorf("不支持通知的事件类型: %s", t)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Text 事件的通知文本（执行类及紧急事件附带交易员、币种及价格）
+func Text(event events.Event) string {
+	var icon string
+	switch event.Type {
+	case events.LiquidationRisk, events.KillSwitch, events.AuthFailure:
+		icon = "🚨 紧急告警"
+		if event.Action == trader.AlertResolved {
+			icon = "✅ 告警已恢复"
+		}
+		if event.TraderID == "" {
+			return icon + " " + event.Message
+		}
+		return fmt.Sprintf("%s [%s] %s", icon, event.TraderID, event.Message)
+	case events.StopLossHit:
+		icon = "🛑 止损触发"
+	case events.TakeProfitHit:
+		icon = "🎯 止盈触发"
+	case events.Error:
+		icon = "❌ 执行错误"
+	default:
+		return event.Message
+	}
+	text := fmt.Sprintf("%s [%s]", icon, event.TraderID)
+	if event.Symbol != "" {
+		text += fmt.Sprintf(" %s %s", event.Symbol, event.Side)
+	}
+	if event.Price > 0 {
+		text += fmt.Sprintf(" @ %.4f", event.Price)
+	}
+	if event.Message != "" {
+		text += "\n" + event.Message
+	}
+	return text
+}
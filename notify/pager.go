@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"nofx/events"
+	"nofx/logger"
+	"nofx/trader"
+	"strings"
+)
+
+// CriticalTypes 需要呼叫值班人员的紧急事件类型（PagerDuty/Opsgenie 只订阅这些事件）
+var CriticalTypes = []events.Type{events.LiquidationRisk, events.KillSwitch, events.AuthFailure}
+
+const (
+	pagerDutyEventsURL    = "https://events.pagerduty.com/v2/enqueue"
+	defaultOpsgenieAPIURL = "https://api.opsgenie.com"
+)
+
+// alertKey 告警去重键：同一持仓/交易员的同类告警合并，恢复事件据此关闭告警
+func alertKey(source string, event events.Event) string {
+	key := fmt.Sprintf("nofx/%s/%s/%s", source, event.Type, event.TraderID)
+	if event.Symbol != "" {
+		key += "/" + event.Symbol + "_" + event.Side
+	}
+	return key
+}
+
+// alertDetails 告警附带的事件字段
+func alertDetails(event events.Event) map[string]interface{} {
+	details := map[string]interface{}{"type": event.Type, "trader_id": event.TraderID, "timestamp": event.Timestamp}
+	if event.Exchange != "" {
+		details["exchange"] = event.Exchange
+	}
+	if event.Symbol != "" {
+		details["symbol"], details["side"] = event.Symbol, event.Side
+	}
+	if event.Price > 0 {
+		details["price"] = event.Price
+	}
+	if event.Quantity > 0 {
+		details["quantity"] = event.Quantity
+	}
+	return details
+}
+
+// PagerDutyNotifier 通过 PagerDuty Events API v2 触发/关闭告警
+type PagerDutyNotifier struct {
+	routingKey string
+	source     string // 告警来源（实例标识）
+}
+
+// NewPagerDutyNotifier 创建PagerDuty告警渠道（routingKey 为服务集成的 Integration Key）
+func NewPagerDutyNotifier(routingKey, source string) (*PagerDutyNotifier, error) {
+	routingKey = strings.TrimSpace(routingKey)
+	if routingKey == "" {
+		return nil, fmt.Errorf("PagerDuty Integration Key不能为空")
+	}
+	logger.RegisterSecret(routingKey)
+	return &PagerDutyNotifier{routingKey: routingKey, source: source}, nil
+}
+
+// Name 实现 Notifier
+func (p *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+// Notify 实现 Notifier：恢复事件关闭对应告警
+func (p *PagerDutyNotifier) Notify(ctx context.Context, event events.Event) error {
+	body := map[string]interface{}{
+		"routing_key":  p.routingKey,
+		"dedup_key":    alertKey(p.source, event),
+		"event_action": "trigger",
+	}
+	if event.Action == trader.AlertResolved {
+		body["event_action"] = "resolve"
+	} else {
+		body["payload"] = map[string]interface{}{
+			"summary":        truncate(Text(event), 1024),
+			"source":         p.source,
+			"severity":       "critical",
+			"component":      event.TraderID,
+			"class":          string(event.Type),
+			"custom_details": alertDetails(event),
+		}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, pagerDutyEventsURL, payload, nil)
+}
+
+// OpsgenieNotifier 通过 Opsgenie Alert API 创建/关闭告警
+type OpsgenieNotifier struct {
+	apiKey string
+	apiURL string // https://api.opsgenie.com（EU账户为 https://api.eu.opsgenie.com）
+	source string
+}
+
+// NewOpsgenieNotifier 创建Opsgenie告警渠道（apiURL为空时使用美区地址）
+func NewOpsgenieNotifier(apiKey, apiURL, source string) (*OpsgenieNotifier, error) {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return nil, fmt.Errorf("Opsgenie API Key不能为空")
+	}
+	if apiURL = strings.TrimRight(strings.TrimSpace(apiURL), "/"); apiURL == "" {
+		apiURL = defaultOpsgenieAPIURL
+	}
+	logger.RegisterSecret(apiKey)
+	return &OpsgenieNotifier{apiKey: apiKey, apiURL: apiURL, source: source}, nil
+}
+
+// Name 实现 Notifier
+func (o *OpsgenieNotifier) Name() string {
+	return "opsgenie"
+}
+
+// Notify 实现 Notifier：恢复事件按alias关闭对应告警
+func (o *OpsgenieNotifier) Notify(ctx context.Context, event events.Event) error {
+	alias := alertKey(o.source, event)
+	headers := map[string]string{"Authorization": "GenieKey " + o.apiKey}
+	if event.Action == trader.AlertResolved {
+		payload, _ := json.Marshal(map[string]string{"source": o.source, "note": event.Message})
+		endpoint := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", o.apiURL, url.PathEscape(alias))
+		return postJSON(ctx, endpoint, payload, headers)
+	}
+
+	text := Text(event)
+	message, _, _ := strings.Cut(text, "\n")
+	details := make(map[string]string)
+	for k, v := range alertDetails(event) {
+		details[k] = fmt.Sprint(v)
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"message":     truncate(message, 130),
+		"alias":       alias,
+		"description": truncate(text, 15000),
+		"source":      o.source,
+		"priority":    "P1",
+		"tags":        []string{"nofx", string(event.Type)},
+		"details":     details,
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, o.apiURL+"/v2/alerts", payload, headers)
+}
+
+// truncate 按字符截断
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,256 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/url"
+	"nofx/events"
+	"nofx/fx"
+	"nofx/logger"
+	"nofx/manager"
+	"strings"
+	"time"
+)
+
+// SMTPConfig 邮件通知配置
+type SMTPConfig struct {
+	URL  string   // smtps://用户名:密码@主机:465（隐式TLS）或 smtp://用户名:密码@主机:587（STARTTLS，必须支持）
+	From string   // 发件人（为空时使用URL中的用户名）
+	To   []string // 收件人
+}
+
+// SMTPNotifier 邮件通知渠道：业绩摘要使用HTML模板渲染，其他事件以纯文本及简单HTML发送
+type SMTPNotifier struct {
+	host     string
+	addr     string
+	implicit bool // 隐式TLS（smtps）
+	auth     smtp.Auth
+	from     string
+	to       []string
+}
+
+// NewSMTPNotifier 创建邮件通知渠道
+func NewSMTPNotifier(cfg SMTPConfig) (*SMTPNotifier, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("无效的SMTP地址（格式 smtps://用户名:密码@主机:465 或 smtp://用户名:密码@主机:587）")
+	}
+	n := &SMTPNotifier{host: u.Hostname(), from: strings.TrimSpace(cfg.From)}
+	port := u.Port()
+	switch u.Scheme {
+	case "smtps":
+		n.implicit = true
+		if port == "" {
+			port = "465"
+		}
+	case "smtp":
+		if port == "" {
+			port = "587"
+		}
+	default:
+		return nil, fmt.Errorf("不支持的SMTP协议: %s（可选: smtps, smtp）", u.Scheme)
+	}
+	n.addr = net.JoinHostPort(n.host, port)
+
+	if u.User != nil {
+		username := u.User.Username()
+		password, _ := u.User.Password()
+		if password != "" {
+			logger.RegisterSecret(password)
+		}
+		n.auth = smtp.PlainAuth("", username, password, n.host)
+		if n.from == "" {
+			n.from = username
+		}
+	}
+	if n.from == "" {
+		return nil, fmt.Errorf("未配置发件人地址")
+	}
+	for _, to := range cfg.To {
+		if to = strings.TrimSpace(to); to != "" {
+			n.to = append(n.to, to)
+		}
+	}
+	if len(n.to) == 0 {
+		return nil, fmt.Errorf("未配置邮件收件人")
+	}
+	return n, nil
+}
+
+// Name 实现 Notifier
+func (n *SMTPNotifier) Name() string {
+	return "email"
+}
+
+// Recipients 收件人数量
+func (n *SMTPNotifier) Recipients() int {
+	return len(n.to)
+}
+
+// Notify 实现 Notifier
+func (n *SMTPNotifier) Notify(ctx context.Context, event events.Event) error {
+	text := Text(event)
+	subject, _, _ := strings.Cut(text, "\n")
+	var html bytes.Buffer
+	var err error
+	if digest, ok := event.Payload.(*manager.Digest); ok {
+		subject = digest.Title()
+		err = digestTemplate.Execute(&html, digest)
+	} else {
+		err = messageTemplate.Execute(&html, map[string]string{"Subject": subject, "Text": text})
+	}
+	if err != nil {
+		return fmt.Errorf("渲染邮件失败: %w", err)
+	}
+	msg, err := n.message(subject, text, html.String())
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, msg)
+}
+
+// message 生成 multipart/alternative 邮件（纯文本 + HTML）
+func (n *SMTPNotifier) message(subject, text, html string) ([]byte, error) {
+	var nonce [12]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	boundary := "nofx-" + hex.EncodeToString(nonce[:])
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, body string }{{"text/plain", text}, {"text/html", html}} {
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: %s; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", boundary, part.contentType)
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+// send 连接SMTP服务器并发送（只在TLS连接上认证）
+func (n *SMTPNotifier) send(ctx context.Context, msg []byte) error {
+	tlsConfig := &tls.Config{ServerName: n.host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if n.implicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", n.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", n.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP握手失败: %w", err)
+	}
+	defer c.Close()
+
+	if !n.implicit {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP服务器不支持STARTTLS，拒绝明文发送")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS失败: %w", err)
+		}
+	}
+	if n.auth != nil {
+		if err := c.Auth(n.auth); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+	if err := c.Mail(n.from); err != nil {
+		return fmt.Errorf("SMTP发件人被拒绝: %w", err)
+	}
+	for _, to := range n.to {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP收件人 %s 被拒绝: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+var templateFuncs = template.FuncMap{
+	"money":  func(usdt float64) string { return fx.Current().FormatSigned(usdt) },
+	"amount": func(usdt float64) string { return fx.Current().Format(usdt) },
+	"pct":    func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+	"rate":   func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"color": func(v float64) string {
+		if v < 0 {
+			return "#c0392b"
+		}
+		return "#1e8449"
+	},
+}
+
+var messageTemplate = template.Must(template.New("message").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html><body style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#222">
+<h3 style="margin:0 0 12px">{{.Subject}}</h3>
+<pre style="font-family:inherit;white-space:pre-wrap;margin:0">{{.Text}}</pre>
+</body></html>
+`))
+
+var digestTemplate = template.Must(template.New("digest").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html><body style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#222">
+<h2 style="margin:0 0 4px">{{.Title}}</h2>
+<p style="margin:0 0 16px;color:#666">{{.Since.UTC.Format "2006-01-02 15:04"}} ~ {{.Until.UTC.Format "2006-01-02 15:04"}} UTC</p>
+<table cellpadding="6" style="border-collapse:collapse;margin-bottom:16px">
+<tr><td>合计盈亏</td><td style="color:{{color .PnL}};font-weight:bold">{{money .PnL}}</td></tr>
+<tr><td>手续费</td><td>-{{amount .Fees}}</td></tr>
+<tr><td>资金费</td><td style="color:{{color .FundingPnL}}">{{money .FundingPnL}}</td></tr>
+<tr><td>平仓笔数</td><td>{{.Trades}}</td></tr>
+<tr><td>胜率</td><td>{{rate .WinRatePct}}</td></tr>
+{{with .Best}}{{if gt .NetPnL 0.0}}<tr><td>最大盈利</td><td>{{.TraderName}} {{.Symbol}} {{.Side}} <span style="color:{{color .NetPnL}}">{{money .NetPnL}} ({{pct .PnLPct}})</span></td></tr>{{end}}{{end}}
+{{with .Worst}}{{if lt .NetPnL 0.0}}<tr><td>最大亏损</td><td>{{.TraderName}} {{.Symbol}} {{.Side}} <span style="color:{{color .NetPnL}}">{{money .NetPnL}} ({{pct .PnLPct}})</span></td></tr>{{end}}{{end}}
+</table>
+<table cellpadding="6" style="border-collapse:collapse;border:1px solid #ddd">
+<tr style="background:#f5f5f5;text-align:left"><th>交易员</th><th>收益率</th><th>盈亏</th><th>盈/亏笔数</th><th>胜率</th><th>手续费</th><th>资金费</th></tr>
+{{range .Traders}}<tr style="border-top:1px solid #ddd">
+<td>{{.TraderName}}{{if .DryRun}}（预演）{{end}}</td>
+<td style="color:{{color .ReturnPct}}">{{pct .ReturnPct}}</td>
+<td style="color:{{color .PnL}}">{{money .PnL}}</td>
+<td>{{.Wins}} / {{.Losses}}</td>
+<td>{{rate .WinRatePct}}</td>
+<td>-{{amount .Fees}}</td>
+<td>{{money .FundingPnL}}</td>
+</tr>
+{{end}}</table>
+{{with .DashboardLink}}<p style="margin-top:16px"><a href="{{.}}">打开仪表盘</a></p>{{end}}
+</body></html>
+`))
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"nofx/events"
+	"nofx/logger"
+	"strconv"
+	"time"
+)
+
+// WebhookNotifier 把每个事件以JSON POST到通用webhook（失败时重试），配置密钥时附带HMAC签名
+// 请求头: X-Nofx-Event 事件类型，X-Nofx-Delivery 投递ID（重试时不变，接收方可据此去重），
+// X-Nofx-Timestamp 秒级时间戳，X-Nofx-Signature sha256=hex(HMAC-SHA256(密钥, 时间戳 + "." + 请求体))
+type WebhookNotifier struct {
+	url    string
+	secret []byte
+}
+
+// NewWebhookNotifier 创建事件webhook（secret为空时不签名）
+func NewWebhookNotifier(rawURL, secret string) (*WebhookNotifier, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("无效的事件webhook地址: %s（需为http/https地址）", rawURL)
+	}
+	if secret != "" {
+		logger.RegisterSecret(secret)
+	}
+	return &WebhookNotifier{url: rawURL, secret: []byte(secret)}, nil
+}
+
+// Name 实现 Notifier
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Target 目标描述（用于日志，不含查询参数中可能携带的token）
+func (w *WebhookNotifier) Target() string {
+	u, _ := url.Parse(w.url)
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+// Notify 实现 Notifier
+func (w *WebhookNotifier) Notify(ctx context.Context, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		"X-Nofx-Event":     string(event.Type),
+		"X-Nofx-Delivery":  hex.EncodeToString(id[:]),
+		"X-Nofx-Timestamp": timestamp,
+	}
+	if len(w.secret) > 0 {
+		headers["X-Nofx-Signature"] = "sha256=" + Sign(w.secret, timestamp, payload)
+	}
+	return postJSON(ctx, w.url, payload, headers)
+}
+
+// Sign 计算webhook签名（接收方用同一密钥校验 X-Nofx-Signature，并拒绝时间戳过旧的请求以防重放）
+func Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
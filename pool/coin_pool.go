@@ -78,6 +78,12 @@ func SetOITopAPI(apiURL string) {
 	oiTopConfig.APIURL = apiURL
 }
 
+// SetCacheDir 设置币种池/OI Top缓存目录
+func SetCacheDir(dir string) {
+	coinPoolConfig.CacheDir = dir
+	oiTopConfig.CacheDir = dir
+}
+
 // SetUseDefaultCoins 设置是否使用默认主流币种
 func SetUseDefaultCoins(useDefault bool) {
 	coinPoolConfig.UseDefaultCoins = useDefault
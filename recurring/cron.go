@@ -0,0 +1,176 @@
+// Package recurring 定投计划：按cron表达式定期以固定名义价值开仓（如每周一买入50 USDT的BTC永续）
+package recurring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron 标准5段cron表达式（分 时 日 月 周），按本地时区计算
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // 位图
+	domAny, dowAny                bool   // 日/周为 * 时，两者按“且”匹配；否则按cron惯例“或”匹配
+}
+
+// cronField 字段取值范围
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "分钟", min: 0, max: 59}
+	hourField   = cronField{name: "小时", min: 0, max: 23}
+	domField    = cronField{name: "日", min: 1, max: 31}
+	monthField  = cronField{name: "月", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "星期", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors 常用简写
+var descriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseCron 解析cron表达式，支持 * , - / 、月份/星期英文缩写及 @hourly/@daily/@weekly/@monthly
+// 例: "0 9 * * MON" 每周一9:00，"*/30 * * * *" 每30分钟
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if full, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = full
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron表达式需要5段（分 时 日 月 周）: %q", expr)
+	}
+
+	c := &Cron{}
+	var err error
+	if c.minute, err = parseCronField(parts[0], minuteField); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(parts[1], hourField); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(parts[2], domField); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(parts[3], monthField); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(parts[4], dowField); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 { // 7 与 0 均表示周日
+		c.dow |= 1
+	}
+	c.domAny = parts[2] == "*" || parts[2] == "?"
+	c.dowAny = parts[4] == "*" || parts[4] == "?"
+	return c, nil
+}
+
+// parseCronField 解析单个字段为位图
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangePart = item[:i]
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s字段步长无效: %q", spec.name, item)
+			}
+			step = n
+		}
+
+		lo, hi := spec.min, spec.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = spec.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if hi, err = spec.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s字段范围无效: %q", spec.name, item)
+			}
+		default:
+			v, err := spec.value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value 解析字段中的单个值（数字或英文缩写）
+func (spec cronField) value(s string) (int, error) {
+	if v, ok := spec.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("%s字段取值无效: %q（范围 %d-%d）", spec.name, s, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// Next 严格晚于t的下一次触发时间（按t的时区计算），5年内无匹配时返回零值
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日期是否匹配日/周字段
+func (c *Cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
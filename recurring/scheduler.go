@@ -0,0 +1,153 @@
+package recurring
+
+import (
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/manager"
+	"nofx/trader"
+	"sync"
+	"time"
+)
+
+const (
+	// pollInterval 检查到期计划的间隔
+	pollInterval = 30 * time.Second
+	// misfireGrace 错过触发时间超过该时长（如程序停机）时不补执行，避免重启后意外开仓
+	misfireGrace = 10 * time.Minute
+)
+
+// 执行结果状态
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Scheduler 定投调度器：定期读取已启用的计划，到期时通过对应交易员开仓并记录结果
+// 多实例共用数据库时只应在一个实例上启用，否则同一计划会被重复执行
+type Scheduler struct {
+	store         config.Store
+	traderManager *manager.TraderManager
+	invalid       map[int64]string // 已告警的无效cron表达式
+	stop          chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewScheduler 创建定投调度器
+func NewScheduler(store config.Store, traderManager *manager.TraderManager) *Scheduler {
+	return &Scheduler{
+		store:         store,
+		traderManager: traderManager,
+		invalid:       make(map[int64]string),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start 启动调度
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case now := <-ticker.C:
+				s.tick(now)
+			}
+		}
+	}()
+	log.Printf("🗓  定投调度器已启动（每 %s 检查一次）", pollInterval)
+}
+
+// Stop 停止调度（等待正在执行的计划完成）
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// tick 执行所有到期的计划
+func (s *Scheduler) tick(now time.Time) {
+	orders, err := s.store.GetEnabledRecurringOrders()
+	if err != nil {
+		log.Printf("⚠️  读取定投计划失败: %v", err)
+		return
+	}
+	for _, order := range orders {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		due, err := NextRun(order)
+		if err != nil {
+			if s.invalid[order.ID] != order.Schedule {
+				s.invalid[order.ID] = order.Schedule
+				log.Printf("⚠️  定投计划 #%d 的cron表达式无效，已跳过: %v", order.ID, err)
+			}
+			continue
+		}
+		if due.IsZero() || due.After(now) {
+			continue
+		}
+		if now.Sub(due) > misfireGrace {
+			s.record(order, now, StatusSkipped, fmt.Sprintf("错过执行时间 %s（超过 %s），跳过本次", due.Format("2006-01-02 15:04"), misfireGrace))
+			continue
+		}
+		s.run(order, now)
+	}
+}
+
+// run 执行一次计划
+func (s *Scheduler) run(order *config.RecurringOrder, now time.Time) {
+	at, err := s.traderManager.GetTrader(order.TraderID)
+	if err != nil {
+		s.record(order, now, StatusFailed, err.Error())
+		return
+	}
+
+	action, err := at.ExecuteRecurringOrder(trader.RecurringEntry{
+		PlanID:      order.ID,
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		NotionalUSD: order.NotionalUSD,
+		Leverage:    order.Leverage,
+	})
+	if err != nil {
+		s.record(order, now, StatusFailed, err.Error())
+		return
+	}
+
+	message := fmt.Sprintf("%s %.6f @ %.4f", action.Action, action.Quantity, action.Price)
+	if action.DryRun {
+		message = action.Preview
+	}
+	s.record(order, now, StatusSuccess, message)
+}
+
+// record 保存执行结果
+func (s *Scheduler) record(order *config.RecurringOrder, runAt time.Time, status, message string) {
+	if status != StatusSuccess {
+		log.Printf("⚠️  定投计划 #%d (%s %s) %s: %s", order.ID, order.TraderID, order.Symbol, status, message)
+	}
+	if err := s.store.RecordRecurringRun(order.ID, runAt, status, message); err != nil {
+		log.Printf("⚠️  保存定投计划 #%d 执行结果失败: %v", order.ID, err)
+	}
+}
+
+// NextRun 计划的下一次触发时间（从上次执行或创建时间起算）
+func NextRun(order *config.RecurringOrder) (time.Time, error) {
+	cron, err := ParseCron(order.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	base := order.CreatedAt
+	if order.LastRunAt != nil {
+		base = *order.LastRunAt
+	}
+	return cron.Next(base.In(time.Local)), nil
+}
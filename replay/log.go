@@ -0,0 +1,222 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"nofx/decision"
+	"nofx/market"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DirName 回放日志子目录（位于交易员决策日志目录下）
+const DirName = "replay"
+
+// maxFrameSize 单条回放记录的最大字节数（包含全部候选币种的K线指标）
+const maxFrameSize = 64 << 20
+
+// Frame 一个决策周期的全部输入（行情、账户、持仓、特征）及AI输出，可离线确定性地重放
+type Frame struct {
+	Time     time.Time `json:"time"` // 决策时刻（prompt中的持仓时长按此计算）
+	TraderID string    `json:"trader_id"`
+	Cycle    int       `json:"cycle"`
+
+	Context     *decision.Context              `json:"context"` // 账户、持仓、候选币种
+	MarketData  map[string]*market.Data        `json:"market_data"`
+	OITop       map[string]*decision.OITopData `json:"oi_top,omitempty"`
+	Performance json.RawMessage                `json:"performance,omitempty"`
+	Features    string                         `json:"features,omitempty"`
+
+	BTCETHLeverage  int     `json:"btc_eth_leverage"`
+	AltcoinLeverage int     `json:"altcoin_leverage"`
+	MaxPositionUSD  float64 `json:"max_position_usd,omitempty"`
+	CustomPrompt    string  `json:"custom_prompt,omitempty"`
+	OverrideBase    bool    `json:"override_base,omitempty"`
+	Template        string  `json:"template"`
+	EnsembleMode    string  `json:"ensemble_mode,omitempty"`
+
+	SystemPrompt string                 `json:"system_prompt"`
+	UserPrompt   string                 `json:"user_prompt"`
+	RawResponse  string                 `json:"raw_response"`
+	Models       []decision.ModelResult `json:"models,omitempty"` // 多模型投票时各模型的原始响应
+	Decisions    []decision.Decision    `json:"decisions"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// NewFrame 从决策上下文及AI决策结果生成回放记录
+func NewFrame(traderID string, ctx *decision.Context, full *decision.FullDecision, decideErr error) *Frame {
+	frame := &Frame{
+		Time:            ctx.Now,
+		TraderID:        traderID,
+		Cycle:           ctx.CallCount,
+		Context:         ctx,
+		MarketData:      ctx.MarketDataMap,
+		OITop:           ctx.OITopDataMap,
+		Features:        ctx.Features,
+		BTCETHLeverage:  ctx.BTCETHLeverage,
+		AltcoinLeverage: ctx.AltcoinLeverage,
+		MaxPositionUSD:  ctx.MaxPositionUSD,
+	}
+	if frame.Time.IsZero() {
+		frame.Time = time.Now()
+	}
+	if ctx.Performance != nil {
+		if data, err := json.Marshal(ctx.Performance); err == nil {
+			frame.Performance = data
+		}
+	}
+	if full != nil {
+		frame.SystemPrompt = full.SystemPrompt
+		frame.UserPrompt = full.UserPrompt
+		frame.RawResponse = full.RawResponse
+		frame.Models = full.Models
+		frame.Decisions = full.Decisions
+	}
+	if decideErr != nil {
+		frame.Error = decideErr.Error()
+	}
+	return frame
+}
+
+// decisionContext 还原决策上下文（离线：不拼接实时特征源，使用录制的特征文本）
+func (f *Frame) decisionContext() (*decision.Context, error) {
+	if f.Context == nil {
+		return nil, fmt.Errorf("回放记录缺少决策上下文")
+	}
+	ctx := *f.Context
+	ctx.MarketDataMap = f.MarketData
+	if ctx.MarketDataMap == nil {
+		ctx.MarketDataMap = make(map[string]*market.Data)
+	}
+	ctx.OITopDataMap = f.OITop
+	if ctx.OITopDataMap == nil {
+		ctx.OITopDataMap = make(map[string]*decision.OITopData)
+	}
+	ctx.Performance = nil
+	if len(f.Performance) > 0 && string(f.Performance) != "null" {
+		var perf map[string]interface{}
+		if err := json.Unmarshal(f.Performance, &perf); err != nil {
+			return nil, fmt.Errorf("解析历史表现失败: %w", err)
+		}
+		ctx.Performance = perf
+	}
+	ctx.BTCETHLeverage = f.BTCETHLeverage
+	ctx.AltcoinLeverage = f.AltcoinLeverage
+	ctx.MaxPositionUSD = f.MaxPositionUSD
+	ctx.Now = f.Time
+	ctx.Features = f.Features
+	ctx.Offline = true
+	return &ctx, nil
+}
+
+// Recorder 追加写入的回放日志（按天分文件：replay_YYYYMMDD.jsonl）
+type Recorder struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewRecorder 创建回放日志记录器，logDir 为交易员的决策日志目录
+func NewRecorder(logDir string) *Recorder {
+	r := &Recorder{dir: filepath.Join(logDir, DirName)}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		fmt.Printf("⚠ 创建回放日志目录失败: %v\n", err)
+	}
+	return r
+}
+
+// Record 追加一条回放记录
+func (r *Recorder) Record(frame *Frame) error {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return fmt.Errorf("序列化回放记录失败: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := filepath.Join(r.dir, fmt.Sprintf("replay_%s.jsonl", frame.Time.UTC().Format("20060102")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开回放日志失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入回放日志失败: %w", err)
+	}
+	return nil
+}
+
+// Load 读取回放日志：path 可以是单个 .jsonl 文件，也可以是回放目录（或其上级的交易员决策日志目录）
+// from/to 非零时只返回该时间范围内的记录
+func Load(path string, from, to time.Time) ([]*Frame, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取回放日志失败: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if sub := filepath.Join(path, DirName); dirExists(sub) {
+			path = sub
+		}
+		files, err = filepath.Glob(filepath.Join(path, "replay_*.jsonl"))
+		if err != nil {
+			return nil, fmt.Errorf("查找回放日志失败: %w", err)
+		}
+		sort.Strings(files)
+	}
+
+	var frames []*Frame
+	for _, file := range files {
+		loaded, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, frame := range loaded {
+			if (!from.IsZero() && frame.Time.Before(from)) || (!to.IsZero() && !frame.Time.Before(to)) {
+				continue
+			}
+			frames = append(frames, frame)
+		}
+	}
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].Time.Before(frames[j].Time) })
+	return frames, nil
+}
+
+// readFile 读取单个回放日志文件
+func readFile(path string) ([]*Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开回放日志失败: %w", err)
+	}
+	defer f.Close()
+
+	var frames []*Frame
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1<<20), maxFrameSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var frame Frame
+		if err := json.Unmarshal([]byte(text), &frame); err != nil {
+			return nil, fmt.Errorf("解析回放日志 %s 第%d行失败: %w", filepath.Base(path), line, err)
+		}
+		frames = append(frames, &frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取回放日志 %s 失败: %w", filepath.Base(path), err)
+	}
+	return frames, nil
+}
+
+// dirExists 目录是否存在
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nofx/decision"
+)
+
+// Result 单个决策周期的回放结果
+type Result struct {
+	Frame    *Frame
+	Decision *decision.FullDecision
+	Err      error
+
+	SystemPromptMatch bool     // 重建的系统提示词与录制时一致
+	UserPromptMatch   bool     // 重建的输入prompt与录制时一致
+	DecisionsMatch    bool     // 解析（含风控、投票）后的决策与录制时一致
+	Diffs             []string // 不一致之处
+}
+
+// Reproduced 是否完全复现录制时的行为
+func (r *Result) Reproduced() bool {
+	return r.SystemPromptMatch && r.UserPromptMatch && r.DecisionsMatch
+}
+
+// recordedResponse 回放录制的AI响应（录制时AI调用失败则返回同样的错误）
+type recordedResponse struct {
+	response string
+	err      string
+}
+
+// CallWithMessages 实现 decision.Caller
+func (r recordedResponse) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	if r.response == "" && r.err != "" {
+		return "", errors.New(r.err)
+	}
+	return r.response, nil
+}
+
+// Play 离线重放一个决策周期：用录制的行情、账户及特征重建prompt，把录制的AI响应交给当前代码解析、风控及投票，
+// 与录制时的prompt和决策逐项比较。不访问交易所、行情接口及AI
+func Play(frame *Frame) *Result {
+	result := &Result{Frame: frame}
+	ctx, err := frame.decisionContext()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if len(frame.Models) > 0 {
+		members := make([]decision.EnsembleMember, len(frame.Models))
+		for i, m := range frame.Models {
+			members[i] = decision.EnsembleMember{Name: m.Model, Client: recordedResponse{response: m.RawResponse, err: m.Error}}
+		}
+		result.Decision, result.Err = decision.GetEnsembleDecisionFromContext(ctx, members, frame.EnsembleMode, frame.CustomPrompt, frame.OverrideBase, frame.Template)
+	} else {
+		caller := recordedResponse{response: frame.RawResponse, err: frame.Error}
+		result.Decision, result.Err = decision.GetFullDecisionFromContext(ctx, caller, frame.CustomPrompt, frame.OverrideBase, frame.Template)
+	}
+	result.compare()
+	return result
+}
+
+// compare 比较重放结果与录制结果
+func (r *Result) compare() {
+	f := r.Frame
+	var replayed decision.FullDecision
+	if r.Decision != nil {
+		replayed = *r.Decision
+	}
+
+	r.SystemPromptMatch = f.SystemPrompt == "" || replayed.SystemPrompt == f.SystemPrompt
+	if !r.SystemPromptMatch {
+		r.Diffs = append(r.Diffs, fmt.Sprintf("系统提示词不一致（录制 %d 字符，重放 %d 字符）", len(f.SystemPrompt), len(replayed.SystemPrompt)))
+	}
+	r.UserPromptMatch = f.UserPrompt == "" || replayed.UserPrompt == f.UserPrompt
+	if !r.UserPromptMatch {
+		r.Diffs = append(r.Diffs, "输入prompt不一致: "+firstDifference(f.UserPrompt, replayed.UserPrompt))
+	}
+
+	recorded, _ := json.Marshal(f.Decisions)
+	got, _ := json.Marshal(replayed.Decisions)
+	if len(f.Decisions) == 0 && len(replayed.Decisions) == 0 {
+		recorded, got = nil, nil
+	}
+	r.DecisionsMatch = string(recorded) == string(got) && (f.Error == "") == (r.Err == nil)
+	if string(recorded) != string(got) {
+		r.Diffs = append(r.Diffs, fmt.Sprintf("决策不一致:\n    录制: %s\n    重放: %s", recorded, got))
+	}
+	if (f.Error == "") != (r.Err == nil) {
+		replayErr := ""
+		if r.Err != nil {
+			replayErr = r.Err.Error()
+		}
+		r.Diffs = append(r.Diffs, fmt.Sprintf("错误不一致:\n    录制: %s\n    重放: %s", f.Error, replayErr))
+	}
+}
+
+// firstDifference 两段文本第一处不同的行
+func firstDifference(a, b string) string {
+	line, start := 1, 0
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return fmt.Sprintf("第%d行\n    录制: %s\n    重放: %s", line, lineAt(a, start), lineAt(b, start))
+		}
+		if a[i] == '\n' {
+			line++
+			start = i + 1
+		}
+	}
+	return fmt.Sprintf("第%d行起长度不同（录制 %d 字符，重放 %d 字符）", line, len(a), len(b))
+}
+
+// lineAt 从start开始的一行
+func lineAt(s string, start int) string {
+	end := start
+	for end < len(s) && s[end] != '\n' {
+		end++
+	}
+	return s[start:end]
+}
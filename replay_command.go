@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"nofx/replay"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const replayUsage = `用法:
+  nofx replay -trader 交易员ID [-date 2006-01-02 | -from 时间 -to 时间] [-v]
+  nofx replay [-date ...] [-v] 回放日志文件或目录
+
+离线重放回放日志（需开启 replay_log）：用录制的行情、账户、持仓及AI响应重建prompt，并交给当前代码解析、风控及投票，
+逐周期比较与录制时的prompt和决策是否一致。不访问交易所、行情接口及AI，不下单
+-date 按UTC日期筛选（默认全部），-from/-to 支持 2006-01-02 或 RFC3339 时间，-v 输出每个周期的决策及不一致之处`
+
+// runReplayCommand 执行 nofx replay 子命令，全部周期复现时返回0
+func runReplayCommand(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	traderID := fs.String("trader", "", "交易员ID（读取 数据目录/decision_logs/交易员ID/replay）")
+	date := fs.String("date", "", "只重放该UTC日期（2006-01-02）")
+	fromStr := fs.String("from", "", "开始时间")
+	toStr := fs.String("to", "", "结束时间（不含）")
+	verbose := fs.Bool("v", false, "输出每个周期的决策及不一致之处")
+	fs.Usage = func() { fmt.Println(replayUsage) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	path := fs.Arg(0)
+	if path == "" && *traderID != "" {
+		path = filepath.Join(envOrDefault("NOFX_DATA_DIR", "."), "decision_logs", *traderID)
+	}
+	if path == "" {
+		fmt.Println(replayUsage)
+		return 2
+	}
+
+	var from, to time.Time
+	var err error
+	if *date != "" {
+		if from, err = time.Parse("2006-01-02", *date); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ 无效的日期: %s\n", *date)
+			return 2
+		}
+		to = from.Add(24 * time.Hour)
+	}
+	if *fromStr != "" {
+		if from, err = parseReplayTime(*fromStr); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+	}
+	if *toStr != "" {
+		if to, err = parseReplayTime(*toStr); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+	}
+
+	frames, err := replay.Load(path, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if len(frames) == 0 {
+		fmt.Println("⚠️  没有可重放的记录（是否已开启 replay_log？）")
+		return 1
+	}
+
+	fmt.Printf("🔁 重放 %d 个决策周期 (%s ~ %s)\n\n", len(frames), frames[0].Time.Format(time.RFC3339), frames[len(frames)-1].Time.Format(time.RFC3339))
+	reproduced := 0
+	for _, frame := range frames {
+		result := replay.Play(frame)
+		status := "✅"
+		if result.Reproduced() {
+			reproduced++
+		} else {
+			status = "❌"
+		}
+		fmt.Printf("%s %s 周期 #%d 决策 %d 个", status, frame.Time.Format("2006-01-02 15:04:05"), frame.Cycle, len(frame.Decisions))
+		if frame.Error != "" {
+			fmt.Printf("（录制时错误: %s）", frame.Error)
+		}
+		fmt.Println()
+		if *verbose && result.Decision != nil {
+			data, _ := json.Marshal(result.Decision.Decisions)
+			fmt.Printf("    决策: %s\n", data)
+		}
+		if *verbose || !result.Reproduced() {
+			for _, diff := range result.Diffs {
+				fmt.Printf("    %s\n", diff)
+			}
+		}
+	}
+
+	fmt.Printf("\n📊 复现 %d / %d 个周期\n", reproduced, len(frames))
+	if reproduced != len(frames) {
+		return 1
+	}
+	return 0
+}
+
+// parseReplayTime 解析 2006-01-02 或 RFC3339 格式的时间
+func parseReplayTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无效的时间: %s（支持 2006-01-02 或 RFC3339）", s)
+}
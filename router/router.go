@@ -0,0 +1,237 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/market"
+	"nofx/trader"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultMinLegUSD 拆单时单笔的最小名义价值（低于该值的剩余部分并入最优交易所）
+const defaultMinLegUSD = 10.0
+
+// Order 待路由的开仓订单
+type Order struct {
+	Symbol      string  `json:"symbol"`
+	Side        string  `json:"side"`         // long / short
+	NotionalUSD float64 `json:"notional_usd"` // 总名义价值
+	Split       bool    `json:"split"`        // 按各交易所的最优挂单量拆单（默认全部发往最优交易所）
+	MinLegUSD   float64 `json:"min_leg_usd"`  // 拆单时单笔最小名义价值（默认10）
+
+	// 执行参数（与AI决策相同，经各交易员的风控流程执行）
+	Leverage      int     `json:"leverage"`
+	StopLoss      float64 `json:"stop_loss,omitempty"`
+	TakeProfit    float64 `json:"take_profit,omitempty"`
+	StopLossPct   float64 `json:"stop_loss_pct,omitempty"`
+	TakeProfitPct float64 `json:"take_profit_pct,omitempty"`
+}
+
+// VenueQuote 某个交易所的报价评估
+type VenueQuote struct {
+	TraderID       string  `json:"trader_id"`
+	Exchange       string  `json:"exchange"`
+	Price          float64 `json:"price"`           // 预计成交价（买入取卖一，卖出取买一）
+	DepthUSD       float64 `json:"depth_usd"`       // 该价位挂单的名义价值（0=未知）
+	FeeRate        float64 `json:"fee_rate"`        // taker费率
+	EffectivePrice float64 `json:"effective_price"` // 计入手续费后的成交价
+	Error          string  `json:"error,omitempty"`
+}
+
+// Leg 拆分到某个交易所的子订单
+type Leg struct {
+	TraderID       string  `json:"trader_id"`
+	Exchange       string  `json:"exchange"`
+	NotionalUSD    float64 `json:"notional_usd"`
+	Price          float64 `json:"price"`
+	EffectivePrice float64 `json:"effective_price"`
+}
+
+// Plan 路由方案
+type Plan struct {
+	Order          Order        `json:"order"`
+	Quotes         []VenueQuote `json:"quotes"` // 按执行质量从优到劣排序
+	Legs           []Leg        `json:"legs"`
+	EffectivePrice float64      `json:"effective_price"` // 各子订单按名义价值加权的含费成交价
+	SavingsUSD     float64      `json:"savings_usd"`     // 相比全部发往最差交易所节省的成本
+}
+
+// LegResult 子订单执行结果
+type LegResult struct {
+	Leg
+	Success bool   `json:"success"`
+	OrderID int64  `json:"order_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// normalize 校验订单参数并填充默认值
+func (o *Order) normalize() error {
+	o.Symbol = market.Normalize(o.Symbol)
+	o.Side = strings.ToLower(o.Side)
+	if o.Side != "long" && o.Side != "short" {
+		return fmt.Errorf("side 必须为 long 或 short")
+	}
+	if o.NotionalUSD <= 0 {
+		return fmt.Errorf("notional_usd 必须大于0")
+	}
+	if o.MinLegUSD <= 0 {
+		o.MinLegUSD = defaultMinLegUSD
+	}
+	return nil
+}
+
+// better 含费成交价a是否优于b（买入越低越好，卖出越高越好）
+func better(side string, a, b float64) bool {
+	if side == "long" {
+		return a < b
+	}
+	return a > b
+}
+
+// quoteVenue 获取单个交易所的最优挂单及费率
+func quoteVenue(at *trader.AutoTrader, symbol, side string) VenueQuote {
+	q := VenueQuote{TraderID: at.GetID(), Exchange: at.GetExchange()}
+	if at.IsWatchOnly() {
+		q.Error = "观察模式，不参与路由"
+		return q
+	}
+	book, err := trader.GetBookQuote(at.GetExchangeTrader(), symbol)
+	if err != nil {
+		q.Error = err.Error()
+		return q
+	}
+	fees := trader.GetFeeSchedule(at.GetExchangeTrader(), at.GetExchange(), symbol)
+	q.FeeRate = fees.Taker
+	if side == "long" {
+		q.Price, q.DepthUSD = book.Ask, book.Ask*book.AskQty
+		q.EffectivePrice = book.Ask * (1 + fees.Taker)
+	} else {
+		q.Price, q.DepthUSD = book.Bid, book.Bid*book.BidQty
+		q.EffectivePrice = book.Bid * (1 - fees.Taker)
+	}
+	if q.Price <= 0 {
+		q.Error = "没有有效报价"
+	}
+	return q
+}
+
+// BuildPlan 并发获取各交易所的最优挂单及费率，选出含费成交价最优的交易所
+// 拆单时依次填满各交易所最优价位的挂单量，超出部分发往最优交易所
+func BuildPlan(order Order, venues []*trader.AutoTrader) (*Plan, error) {
+	if err := order.normalize(); err != nil {
+		return nil, err
+	}
+	if len(venues) == 0 {
+		return nil, fmt.Errorf("没有可路由的交易所")
+	}
+
+	quotes := make([]VenueQuote, len(venues))
+	var wg sync.WaitGroup
+	for i, at := range venues {
+		wg.Add(1)
+		go func(i int, at *trader.AutoTrader) {
+			defer wg.Done()
+			quotes[i] = quoteVenue(at, order.Symbol, order.Side)
+		}(i, at)
+	}
+	wg.Wait()
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if (quotes[i].Error == "") != (quotes[j].Error == "") {
+			return quotes[i].Error == ""
+		}
+		return better(order.Side, quotes[i].EffectivePrice, quotes[j].EffectivePrice)
+	})
+	var valid []VenueQuote
+	for _, q := range quotes {
+		if q.Error == "" {
+			valid = append(valid, q)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("所有交易所均无法获取 %s 的报价", order.Symbol)
+	}
+
+	plan := &Plan{Order: order, Quotes: quotes}
+	best := valid[0]
+	remaining := order.NotionalUSD
+	if order.Split {
+		for _, q := range valid {
+			if remaining < order.MinLegUSD || q.DepthUSD <= 0 {
+				break
+			}
+			size := math.Min(remaining, q.DepthUSD)
+			if size < order.MinLegUSD {
+				continue
+			}
+			plan.Legs = append(plan.Legs, Leg{TraderID: q.TraderID, Exchange: q.Exchange, NotionalUSD: size, Price: q.Price, EffectivePrice: q.EffectivePrice})
+			remaining -= size
+		}
+	}
+	if remaining > 0 {
+		if len(plan.Legs) > 0 && plan.Legs[0].TraderID == best.TraderID {
+			plan.Legs[0].NotionalUSD += remaining
+		} else {
+			plan.Legs = append([]Leg{{TraderID: best.TraderID, Exchange: best.Exchange, NotionalUSD: remaining, Price: best.Price, EffectivePrice: best.EffectivePrice}}, plan.Legs...)
+		}
+	}
+
+	weighted := 0.0
+	for _, leg := range plan.Legs {
+		weighted += leg.EffectivePrice * leg.NotionalUSD
+	}
+	plan.EffectivePrice = weighted / order.NotionalUSD
+	worst := valid[len(valid)-1].EffectivePrice
+	if order.Side == "long" {
+		plan.SavingsUSD = order.NotionalUSD * (worst - plan.EffectivePrice) / worst
+	} else {
+		plan.SavingsUSD = order.NotionalUSD * (plan.EffectivePrice - worst) / worst
+	}
+	return plan, nil
+}
+
+// Execute 按路由方案在各交易员上执行子订单（经外部信号的风控及审批流程，某笔失败不影响其余子订单）
+func Execute(plan *Plan, venues []*trader.AutoTrader) []LegResult {
+	byID := make(map[string]*trader.AutoTrader, len(venues))
+	for _, at := range venues {
+		byID[at.GetID()] = at
+	}
+
+	results := make([]LegResult, 0, len(plan.Legs))
+	for _, leg := range plan.Legs {
+		result := LegResult{Leg: leg}
+		at, ok := byID[leg.TraderID]
+		if !ok {
+			result.Error = "交易员不存在"
+			results = append(results, result)
+			continue
+		}
+		d := decision.Decision{
+			Symbol:          plan.Order.Symbol,
+			Action:          "open_" + plan.Order.Side,
+			Leverage:        plan.Order.Leverage,
+			PositionSizeUSD: leg.NotionalUSD,
+			StopLoss:        plan.Order.StopLoss,
+			TakeProfit:      plan.Order.TakeProfit,
+			StopLossPct:     plan.Order.StopLossPct,
+			TakeProfitPct:   plan.Order.TakeProfitPct,
+			Reasoning:       fmt.Sprintf("智能路由: %s 含费成交价 %.4f", leg.Exchange, leg.EffectivePrice),
+		}
+		action, err := at.ExecuteSignal(d, "smart_router")
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Success = action.Success
+			result.OrderID = action.OrderID
+			result.Error = action.Error
+		}
+		log.Printf("🧭 [智能路由] %s %s %.2f USDT → %s (%s): 成功=%v %s",
+			plan.Order.Symbol, plan.Order.Side, leg.NotionalUSD, leg.TraderID, leg.Exchange, result.Success, result.Error)
+		results = append(results, result)
+	}
+	return results
+}
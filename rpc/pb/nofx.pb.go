@@ -0,0 +1,2087 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: pb/nofx.proto
+
+// nofx gRPC 控制接口：查询账户/持仓/敞口、启停交易员、手动平仓及持仓/执行事件流
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Side int32
+
+const (
+	Side_SIDE_UNSPECIFIED Side = 0
+	Side_SIDE_LONG        Side = 1
+	Side_SIDE_SHORT       Side = 2
+)
+
+// Enum value maps for Side.
+var (
+	Side_name = map[int32]string{
+		0: "SIDE_UNSPECIFIED",
+		1: "SIDE_LONG",
+		2: "SIDE_SHORT",
+	}
+	Side_value = map[string]int32{
+		"SIDE_UNSPECIFIED": 0,
+		"SIDE_LONG":        1,
+		"SIDE_SHORT":       2,
+	}
+)
+
+func (x Side) Enum() *Side {
+	p := new(Side)
+	*p = x
+	return p
+}
+
+func (x Side) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Side) Descriptor() protoreflect.EnumDescriptor {
+	return file_pb_nofx_proto_enumTypes[0].Descriptor()
+}
+
+func (Side) Type() protoreflect.EnumType {
+	return &file_pb_nofx_proto_enumTypes[0]
+}
+
+func (x Side) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Side.Descriptor instead.
+func (Side) EnumDescriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{0}
+}
+
+type TraderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraderRequest) Reset() {
+	*x = TraderRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraderRequest) ProtoMessage() {}
+
+func (x *TraderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraderRequest.ProtoReflect.Descriptor instead.
+func (*TraderRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TraderRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+type ListTradersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTradersRequest) Reset() {
+	*x = ListTradersRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTradersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTradersRequest) ProtoMessage() {}
+
+func (x *ListTradersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTradersRequest.ProtoReflect.Descriptor instead.
+func (*ListTradersRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{1}
+}
+
+type ListTradersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Traders       []*TraderStatus        `protobuf:"bytes,1,rep,name=traders,proto3" json:"traders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTradersResponse) Reset() {
+	*x = ListTradersResponse{}
+	mi := &file_pb_nofx_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTradersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTradersResponse) ProtoMessage() {}
+
+func (x *ListTradersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTradersResponse.ProtoReflect.Descriptor instead.
+func (*ListTradersResponse) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListTradersResponse) GetTraders() []*TraderStatus {
+	if x != nil {
+		return x.Traders
+	}
+	return nil
+}
+
+type TraderStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TraderId       string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	AiModel        string                 `protobuf:"bytes,3,opt,name=ai_model,json=aiModel,proto3" json:"ai_model,omitempty"`
+	Exchange       string                 `protobuf:"bytes,4,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	IsRunning      bool                   `protobuf:"varint,5,opt,name=is_running,json=isRunning,proto3" json:"is_running,omitempty"`
+	DryRun         bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	CallCount      int64                  `protobuf:"varint,7,opt,name=call_count,json=callCount,proto3" json:"call_count,omitempty"`
+	StartTime      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	InitialBalance float64                `protobuf:"fixed64,9,opt,name=initial_balance,json=initialBalance,proto3" json:"initial_balance,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TraderStatus) Reset() {
+	*x = TraderStatus{}
+	mi := &file_pb_nofx_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraderStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraderStatus) ProtoMessage() {}
+
+func (x *TraderStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraderStatus.ProtoReflect.Descriptor instead.
+func (*TraderStatus) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TraderStatus) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetAiModel() string {
+	if x != nil {
+		return x.AiModel
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetIsRunning() bool {
+	if x != nil {
+		return x.IsRunning
+	}
+	return false
+}
+
+func (x *TraderStatus) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *TraderStatus) GetCallCount() int64 {
+	if x != nil {
+		return x.CallCount
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *TraderStatus) GetInitialBalance() float64 {
+	if x != nil {
+		return x.InitialBalance
+	}
+	return 0
+}
+
+type Account struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TraderId         string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	TotalEquity      float64                `protobuf:"fixed64,2,opt,name=total_equity,json=totalEquity,proto3" json:"total_equity,omitempty"`
+	WalletBalance    float64                `protobuf:"fixed64,3,opt,name=wallet_balance,json=walletBalance,proto3" json:"wallet_balance,omitempty"`
+	UnrealizedProfit float64                `protobuf:"fixed64,4,opt,name=unrealized_profit,json=unrealizedProfit,proto3" json:"unrealized_profit,omitempty"`
+	AvailableBalance float64                `protobuf:"fixed64,5,opt,name=available_balance,json=availableBalance,proto3" json:"available_balance,omitempty"`
+	TotalPnl         float64                `protobuf:"fixed64,6,opt,name=total_pnl,json=totalPnl,proto3" json:"total_pnl,omitempty"`
+	TotalPnlPct      float64                `protobuf:"fixed64,7,opt,name=total_pnl_pct,json=totalPnlPct,proto3" json:"total_pnl_pct,omitempty"`
+	InitialBalance   float64                `protobuf:"fixed64,8,opt,name=initial_balance,json=initialBalance,proto3" json:"initial_balance,omitempty"`
+	DailyPnl         float64                `protobuf:"fixed64,9,opt,name=daily_pnl,json=dailyPnl,proto3" json:"daily_pnl,omitempty"`
+	PositionCount    int32                  `protobuf:"varint,10,opt,name=position_count,json=positionCount,proto3" json:"position_count,omitempty"`
+	MarginUsed       float64                `protobuf:"fixed64,11,opt,name=margin_used,json=marginUsed,proto3" json:"margin_used,omitempty"`
+	MarginUsedPct    float64                `protobuf:"fixed64,12,opt,name=margin_used_pct,json=marginUsedPct,proto3" json:"margin_used_pct,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_pb_nofx_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Account) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *Account) GetTotalEquity() float64 {
+	if x != nil {
+		return x.TotalEquity
+	}
+	return 0
+}
+
+func (x *Account) GetWalletBalance() float64 {
+	if x != nil {
+		return x.WalletBalance
+	}
+	return 0
+}
+
+func (x *Account) GetUnrealizedProfit() float64 {
+	if x != nil {
+		return x.UnrealizedProfit
+	}
+	return 0
+}
+
+func (x *Account) GetAvailableBalance() float64 {
+	if x != nil {
+		return x.AvailableBalance
+	}
+	return 0
+}
+
+func (x *Account) GetTotalPnl() float64 {
+	if x != nil {
+		return x.TotalPnl
+	}
+	return 0
+}
+
+func (x *Account) GetTotalPnlPct() float64 {
+	if x != nil {
+		return x.TotalPnlPct
+	}
+	return 0
+}
+
+func (x *Account) GetInitialBalance() float64 {
+	if x != nil {
+		return x.InitialBalance
+	}
+	return 0
+}
+
+func (x *Account) GetDailyPnl() float64 {
+	if x != nil {
+		return x.DailyPnl
+	}
+	return 0
+}
+
+func (x *Account) GetPositionCount() int32 {
+	if x != nil {
+		return x.PositionCount
+	}
+	return 0
+}
+
+func (x *Account) GetMarginUsed() float64 {
+	if x != nil {
+		return x.MarginUsed
+	}
+	return 0
+}
+
+func (x *Account) GetMarginUsedPct() float64 {
+	if x != nil {
+		return x.MarginUsedPct
+	}
+	return 0
+}
+
+type Position struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Symbol           string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side             Side                   `protobuf:"varint,2,opt,name=side,proto3,enum=nofx.v1.Side" json:"side,omitempty"`
+	EntryPrice       float64                `protobuf:"fixed64,3,opt,name=entry_price,json=entryPrice,proto3" json:"entry_price,omitempty"`
+	MarkPrice        float64                `protobuf:"fixed64,4,opt,name=mark_price,json=markPrice,proto3" json:"mark_price,omitempty"`
+	Quantity         float64                `protobuf:"fixed64,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Leverage         int32                  `protobuf:"varint,6,opt,name=leverage,proto3" json:"leverage,omitempty"`
+	UnrealizedPnl    float64                `protobuf:"fixed64,7,opt,name=unrealized_pnl,json=unrealizedPnl,proto3" json:"unrealized_pnl,omitempty"`
+	UnrealizedPnlPct float64                `protobuf:"fixed64,8,opt,name=unrealized_pnl_pct,json=unrealizedPnlPct,proto3" json:"unrealized_pnl_pct,omitempty"`
+	LiquidationPrice float64                `protobuf:"fixed64,9,opt,name=liquidation_price,json=liquidationPrice,proto3" json:"liquidation_price,omitempty"`
+	MarginUsed       float64                `protobuf:"fixed64,10,opt,name=margin_used,json=marginUsed,proto3" json:"margin_used,omitempty"`
+	Strategy         string                 `protobuf:"bytes,11,opt,name=strategy,proto3" json:"strategy,omitempty"` // 持仓归属的交易员ID，空表示非本系统开仓
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_pb_nofx_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Position) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Position) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *Position) GetEntryPrice() float64 {
+	if x != nil {
+		return x.EntryPrice
+	}
+	return 0
+}
+
+func (x *Position) GetMarkPrice() float64 {
+	if x != nil {
+		return x.MarkPrice
+	}
+	return 0
+}
+
+func (x *Position) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Position) GetLeverage() int32 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+func (x *Position) GetUnrealizedPnl() float64 {
+	if x != nil {
+		return x.UnrealizedPnl
+	}
+	return 0
+}
+
+func (x *Position) GetUnrealizedPnlPct() float64 {
+	if x != nil {
+		return x.UnrealizedPnlPct
+	}
+	return 0
+}
+
+func (x *Position) GetLiquidationPrice() float64 {
+	if x != nil {
+		return x.LiquidationPrice
+	}
+	return 0
+}
+
+func (x *Position) GetMarginUsed() float64 {
+	if x != nil {
+		return x.MarginUsed
+	}
+	return 0
+}
+
+func (x *Position) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+type ListPositionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Positions     []*Position            `protobuf:"bytes,2,rep,name=positions,proto3" json:"positions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPositionsResponse) Reset() {
+	*x = ListPositionsResponse{}
+	mi := &file_pb_nofx_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPositionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPositionsResponse) ProtoMessage() {}
+
+func (x *ListPositionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPositionsResponse.ProtoReflect.Descriptor instead.
+func (*ListPositionsResponse) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListPositionsResponse) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *ListPositionsResponse) GetPositions() []*Position {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+type ClosePositionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=nofx.v1.Side" json:"side,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClosePositionRequest) Reset() {
+	*x = ClosePositionRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClosePositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClosePositionRequest) ProtoMessage() {}
+
+func (x *ClosePositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClosePositionRequest.ProtoReflect.Descriptor instead.
+func (*ClosePositionRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ClosePositionRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *ClosePositionRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *ClosePositionRequest) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+type OrderResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=nofx.v1.Side" json:"side,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	OrderId       int64                  `protobuf:"varint,5,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ClientOrderId string                 `protobuf:"bytes,6,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	Price         float64                `protobuf:"fixed64,7,opt,name=price,proto3" json:"price,omitempty"`
+	DryRun        bool                   `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderResult) Reset() {
+	*x = OrderResult{}
+	mi := &file_pb_nofx_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderResult) ProtoMessage() {}
+
+func (x *OrderResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderResult.ProtoReflect.Descriptor instead.
+func (*OrderResult) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OrderResult) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *OrderResult) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *OrderResult) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *OrderResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *OrderResult) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderResult) GetClientOrderId() string {
+	if x != nil {
+		return x.ClientOrderId
+	}
+	return ""
+}
+
+func (x *OrderResult) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderResult) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SetTargetPositionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Target        float64                `protobuf:"fixed64,3,opt,name=target,proto3" json:"target,omitempty"` // 目标净仓位（多为正、空为负、0=全部平仓）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTargetPositionRequest) Reset() {
+	*x = SetTargetPositionRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTargetPositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTargetPositionRequest) ProtoMessage() {}
+
+func (x *SetTargetPositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTargetPositionRequest.ProtoReflect.Descriptor instead.
+func (*SetTargetPositionRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetTargetPositionRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *SetTargetPositionRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *SetTargetPositionRequest) GetTarget() float64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+type TargetOrder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`       // open_long / open_short / close_long / close_short
+	Quantity      float64                `protobuf:"fixed64,2,opt,name=quantity,proto3" json:"quantity,omitempty"` // 平仓0表示全部平仓
+	OrderId       int64                  `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetOrder) Reset() {
+	*x = TargetOrder{}
+	mi := &file_pb_nofx_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetOrder) ProtoMessage() {}
+
+func (x *TargetOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetOrder.ProtoReflect.Descriptor instead.
+func (*TargetOrder) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TargetOrder) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *TargetOrder) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *TargetOrder) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *TargetOrder) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type TargetPositionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Current       float64                `protobuf:"fixed64,3,opt,name=current,proto3" json:"current,omitempty"` // 调整前的净仓位
+	Target        float64                `protobuf:"fixed64,4,opt,name=target,proto3" json:"target,omitempty"`
+	Orders        []*TargetOrder         `protobuf:"bytes,5,rep,name=orders,proto3" json:"orders,omitempty"`
+	DryRun        bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetPositionResult) Reset() {
+	*x = TargetPositionResult{}
+	mi := &file_pb_nofx_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetPositionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetPositionResult) ProtoMessage() {}
+
+func (x *TargetPositionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetPositionResult.ProtoReflect.Descriptor instead.
+func (*TargetPositionResult) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TargetPositionResult) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *TargetPositionResult) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TargetPositionResult) GetCurrent() float64 {
+	if x != nil {
+		return x.Current
+	}
+	return 0
+}
+
+func (x *TargetPositionResult) GetTarget() float64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *TargetPositionResult) GetOrders() []*TargetOrder {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *TargetPositionResult) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type WatchPositionsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TraderId        string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	IntervalSeconds int32                  `protobuf:"varint,2,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"` // 定时推送间隔（0=10秒，最小1秒）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchPositionsRequest) Reset() {
+	*x = WatchPositionsRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchPositionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPositionsRequest) ProtoMessage() {}
+
+func (x *WatchPositionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPositionsRequest.ProtoReflect.Descriptor instead.
+func (*WatchPositionsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchPositionsRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *WatchPositionsRequest) GetIntervalSeconds() int32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+type PositionUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // snapshot / interval / 触发推送的事件类型
+	Positions     []*Position            `protobuf:"bytes,4,rep,name=positions,proto3" json:"positions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PositionUpdate) Reset() {
+	*x = PositionUpdate{}
+	mi := &file_pb_nofx_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PositionUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PositionUpdate) ProtoMessage() {}
+
+func (x *PositionUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PositionUpdate.ProtoReflect.Descriptor instead.
+func (*PositionUpdate) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PositionUpdate) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *PositionUpdate) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *PositionUpdate) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PositionUpdate) GetPositions() []*Position {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"` // 空表示当前用户的全部交易员
+	Types         []string               `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`                       // 事件类型过滤，空表示全部
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WatchEventsRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+type ExecutionEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	TraderId      string                 `protobuf:"bytes,2,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Exchange      string                 `protobuf:"bytes,3,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Symbol        string                 `protobuf:"bytes,4,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          Side                   `protobuf:"varint,5,opt,name=side,proto3,enum=nofx.v1.Side" json:"side,omitempty"`
+	Action        string                 `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,7,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price         float64                `protobuf:"fixed64,8,opt,name=price,proto3" json:"price,omitempty"`
+	OrderId       int64                  `protobuf:"varint,9,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ClientOrderId string                 `protobuf:"bytes,10,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	Message       string                 `protobuf:"bytes,11,opt,name=message,proto3" json:"message,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Equity        float64                `protobuf:"fixed64,13,opt,name=equity,proto3" json:"equity,omitempty"` // pnl_snapshot: 账户净值
+	Pnl           float64                `protobuf:"fixed64,14,opt,name=pnl,proto3" json:"pnl,omitempty"`       // pnl_snapshot: 总盈亏
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecutionEvent) Reset() {
+	*x = ExecutionEvent{}
+	mi := &file_pb_nofx_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionEvent) ProtoMessage() {}
+
+func (x *ExecutionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionEvent.ProtoReflect.Descriptor instead.
+func (*ExecutionEvent) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExecutionEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *ExecutionEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ExecutionEvent) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ExecutionEvent) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *ExecutionEvent) GetClientOrderId() string {
+	if x != nil {
+		return x.ClientOrderId
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ExecutionEvent) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *ExecutionEvent) GetPnl() float64 {
+	if x != nil {
+		return x.Pnl
+	}
+	return 0
+}
+
+type ExposureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"` // 空表示当前用户的全部交易员
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExposureRequest) Reset() {
+	*x = ExposureRequest{}
+	mi := &file_pb_nofx_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExposureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExposureRequest) ProtoMessage() {}
+
+func (x *ExposureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExposureRequest.ProtoReflect.Descriptor instead.
+func (*ExposureRequest) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExposureRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+type PositionExposure struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Symbol           string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side             Side                   `protobuf:"varint,2,opt,name=side,proto3,enum=nofx.v1.Side" json:"side,omitempty"`
+	Quantity         float64                `protobuf:"fixed64,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Notional         float64                `protobuf:"fixed64,4,opt,name=notional,proto3" json:"notional,omitempty"` // 名义价值（USDT，空头为负）
+	Leverage         int32                  `protobuf:"varint,5,opt,name=leverage,proto3" json:"leverage,omitempty"`
+	MarkPrice        float64                `protobuf:"fixed64,6,opt,name=mark_price,json=markPrice,proto3" json:"mark_price,omitempty"`
+	LiquidationPrice float64                `protobuf:"fixed64,7,opt,name=liquidation_price,json=liquidationPrice,proto3" json:"liquidation_price,omitempty"`
+	LiqDistancePct   float64                `protobuf:"fixed64,8,opt,name=liq_distance_pct,json=liqDistancePct,proto3" json:"liq_distance_pct,omitempty"` // 标记价格距强平价格的百分比（0=无强平价格）
+	Var95            float64                `protobuf:"fixed64,9,opt,name=var95,proto3" json:"var95,omitempty"`                                           // 单独持仓的1日95% VaR（USDT）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PositionExposure) Reset() {
+	*x = PositionExposure{}
+	mi := &file_pb_nofx_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PositionExposure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PositionExposure) ProtoMessage() {}
+
+func (x *PositionExposure) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PositionExposure.ProtoReflect.Descriptor instead.
+func (*PositionExposure) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PositionExposure) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *PositionExposure) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *PositionExposure) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *PositionExposure) GetNotional() float64 {
+	if x != nil {
+		return x.Notional
+	}
+	return 0
+}
+
+func (x *PositionExposure) GetLeverage() int32 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+func (x *PositionExposure) GetMarkPrice() float64 {
+	if x != nil {
+		return x.MarkPrice
+	}
+	return 0
+}
+
+func (x *PositionExposure) GetLiquidationPrice() float64 {
+	if x != nil {
+		return x.LiquidationPrice
+	}
+	return 0
+}
+
+func (x *PositionExposure) GetLiqDistancePct() float64 {
+	if x != nil {
+		return x.LiqDistancePct
+	}
+	return 0
+}
+
+func (x *PositionExposure) GetVar95() float64 {
+	if x != nil {
+		return x.Var95
+	}
+	return 0
+}
+
+type TraderExposure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Exchange      string                 `protobuf:"bytes,3,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Equity        float64                `protobuf:"fixed64,5,opt,name=equity,proto3" json:"equity,omitempty"`
+	GrossExposure float64                `protobuf:"fixed64,6,opt,name=gross_exposure,json=grossExposure,proto3" json:"gross_exposure,omitempty"`
+	NetExposure   float64                `protobuf:"fixed64,7,opt,name=net_exposure,json=netExposure,proto3" json:"net_exposure,omitempty"`
+	Leverage      float64                `protobuf:"fixed64,8,opt,name=leverage,proto3" json:"leverage,omitempty"` // 有效杠杆（总名义价值/净值）
+	Var95         float64                `protobuf:"fixed64,9,opt,name=var95,proto3" json:"var95,omitempty"`
+	Var99         float64                `protobuf:"fixed64,10,opt,name=var99,proto3" json:"var99,omitempty"`
+	Positions     []*PositionExposure    `protobuf:"bytes,11,rep,name=positions,proto3" json:"positions,omitempty"`
+	SharedWith    []string               `protobuf:"bytes,12,rep,name=shared_with,json=sharedWith,proto3" json:"shared_with,omitempty"` // 共享同一交易所账户的其他交易员（持仓只统计一次）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraderExposure) Reset() {
+	*x = TraderExposure{}
+	mi := &file_pb_nofx_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraderExposure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraderExposure) ProtoMessage() {}
+
+func (x *TraderExposure) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraderExposure.ProtoReflect.Descriptor instead.
+func (*TraderExposure) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TraderExposure) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *TraderExposure) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TraderExposure) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *TraderExposure) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *TraderExposure) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *TraderExposure) GetGrossExposure() float64 {
+	if x != nil {
+		return x.GrossExposure
+	}
+	return 0
+}
+
+func (x *TraderExposure) GetNetExposure() float64 {
+	if x != nil {
+		return x.NetExposure
+	}
+	return 0
+}
+
+func (x *TraderExposure) GetLeverage() float64 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+func (x *TraderExposure) GetVar95() float64 {
+	if x != nil {
+		return x.Var95
+	}
+	return 0
+}
+
+func (x *TraderExposure) GetVar99() float64 {
+	if x != nil {
+		return x.Var99
+	}
+	return 0
+}
+
+func (x *TraderExposure) GetPositions() []*PositionExposure {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *TraderExposure) GetSharedWith() []string {
+	if x != nil {
+		return x.SharedWith
+	}
+	return nil
+}
+
+type SymbolExposure struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Symbol            string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	LongNotional      float64                `protobuf:"fixed64,2,opt,name=long_notional,json=longNotional,proto3" json:"long_notional,omitempty"`
+	ShortNotional     float64                `protobuf:"fixed64,3,opt,name=short_notional,json=shortNotional,proto3" json:"short_notional,omitempty"` // 空头名义价值（正数）
+	NetNotional       float64                `protobuf:"fixed64,4,opt,name=net_notional,json=netNotional,proto3" json:"net_notional,omitempty"`
+	MinLiqDistancePct float64                `protobuf:"fixed64,5,opt,name=min_liq_distance_pct,json=minLiqDistancePct,proto3" json:"min_liq_distance_pct,omitempty"`
+	Var95             float64                `protobuf:"fixed64,6,opt,name=var95,proto3" json:"var95,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SymbolExposure) Reset() {
+	*x = SymbolExposure{}
+	mi := &file_pb_nofx_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolExposure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolExposure) ProtoMessage() {}
+
+func (x *SymbolExposure) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolExposure.ProtoReflect.Descriptor instead.
+func (*SymbolExposure) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SymbolExposure) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *SymbolExposure) GetLongNotional() float64 {
+	if x != nil {
+		return x.LongNotional
+	}
+	return 0
+}
+
+func (x *SymbolExposure) GetShortNotional() float64 {
+	if x != nil {
+		return x.ShortNotional
+	}
+	return 0
+}
+
+func (x *SymbolExposure) GetNetNotional() float64 {
+	if x != nil {
+		return x.NetNotional
+	}
+	return 0
+}
+
+func (x *SymbolExposure) GetMinLiqDistancePct() float64 {
+	if x != nil {
+		return x.MinLiqDistancePct
+	}
+	return 0
+}
+
+func (x *SymbolExposure) GetVar95() float64 {
+	if x != nil {
+		return x.Var95
+	}
+	return 0
+}
+
+type InstanceExposure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InstanceId    string                 `protobuf:"bytes,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Equity        float64                `protobuf:"fixed64,3,opt,name=equity,proto3" json:"equity,omitempty"`
+	GrossExposure float64                `protobuf:"fixed64,4,opt,name=gross_exposure,json=grossExposure,proto3" json:"gross_exposure,omitempty"`
+	NetExposure   float64                `protobuf:"fixed64,5,opt,name=net_exposure,json=netExposure,proto3" json:"net_exposure,omitempty"`
+	Var95         float64                `protobuf:"fixed64,6,opt,name=var95,proto3" json:"var95,omitempty"` // 各账户VaR之和（不考虑账户间分散效应）
+	Var99         float64                `protobuf:"fixed64,7,opt,name=var99,proto3" json:"var99,omitempty"`
+	Symbols       []*SymbolExposure      `protobuf:"bytes,8,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Traders       []*TraderExposure      `protobuf:"bytes,9,rep,name=traders,proto3" json:"traders,omitempty"`
+	Errors        map[string]string      `protobuf:"bytes,10,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 获取失败的交易员ID及原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstanceExposure) Reset() {
+	*x = InstanceExposure{}
+	mi := &file_pb_nofx_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstanceExposure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstanceExposure) ProtoMessage() {}
+
+func (x *InstanceExposure) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_nofx_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstanceExposure.ProtoReflect.Descriptor instead.
+func (*InstanceExposure) Descriptor() ([]byte, []int) {
+	return file_pb_nofx_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *InstanceExposure) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
+func (x *InstanceExposure) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *InstanceExposure) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *InstanceExposure) GetGrossExposure() float64 {
+	if x != nil {
+		return x.GrossExposure
+	}
+	return 0
+}
+
+func (x *InstanceExposure) GetNetExposure() float64 {
+	if x != nil {
+		return x.NetExposure
+	}
+	return 0
+}
+
+func (x *InstanceExposure) GetVar95() float64 {
+	if x != nil {
+		return x.Var95
+	}
+	return 0
+}
+
+func (x *InstanceExposure) GetVar99() float64 {
+	if x != nil {
+		return x.Var99
+	}
+	return 0
+}
+
+func (x *InstanceExposure) GetSymbols() []*SymbolExposure {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *InstanceExposure) GetTraders() []*TraderExposure {
+	if x != nil {
+		return x.Traders
+	}
+	return nil
+}
+
+func (x *InstanceExposure) GetErrors() map[string]string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+var File_pb_nofx_proto protoreflect.FileDescriptor
+
+const file_pb_nofx_proto_rawDesc = "" +
+	"\n" +
+	"\rpb/nofx.proto\x12\anofx.v1\x1a\x1fgoogle/protobuf/timestamp.proto\",\n" +
+	"\rTraderRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\"\x14\n" +
+	"\x12ListTradersRequest\"F\n" +
+	"\x13ListTradersResponse\x12/\n" +
+	"\atraders\x18\x01 \x03(\v2\x15.nofx.v1.TraderStatusR\atraders\"\xb1\x02\n" +
+	"\fTraderStatus\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x19\n" +
+	"\bai_model\x18\x03 \x01(\tR\aaiModel\x12\x1a\n" +
+	"\bexchange\x18\x04 \x01(\tR\bexchange\x12\x1d\n" +
+	"\n" +
+	"is_running\x18\x05 \x01(\bR\tisRunning\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12\x1d\n" +
+	"\n" +
+	"call_count\x18\a \x01(\x03R\tcallCount\x129\n" +
+	"\n" +
+	"start_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x12'\n" +
+	"\x0finitial_balance\x18\t \x01(\x01R\x0einitialBalance\"\xc1\x03\n" +
+	"\aAccount\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12!\n" +
+	"\ftotal_equity\x18\x02 \x01(\x01R\vtotalEquity\x12%\n" +
+	"\x0ewallet_balance\x18\x03 \x01(\x01R\rwalletBalance\x12+\n" +
+	"\x11unrealized_profit\x18\x04 \x01(\x01R\x10unrealizedProfit\x12+\n" +
+	"\x11available_balance\x18\x05 \x01(\x01R\x10availableBalance\x12\x1b\n" +
+	"\ttotal_pnl\x18\x06 \x01(\x01R\btotalPnl\x12\"\n" +
+	"\rtotal_pnl_pct\x18\a \x01(\x01R\vtotalPnlPct\x12'\n" +
+	"\x0finitial_balance\x18\b \x01(\x01R\x0einitialBalance\x12\x1b\n" +
+	"\tdaily_pnl\x18\t \x01(\x01R\bdailyPnl\x12%\n" +
+	"\x0eposition_count\x18\n" +
+	" \x01(\x05R\rpositionCount\x12\x1f\n" +
+	"\vmargin_used\x18\v \x01(\x01R\n" +
+	"marginUsed\x12&\n" +
+	"\x0fmargin_used_pct\x18\f \x01(\x01R\rmarginUsedPct\"\xfc\x02\n" +
+	"\bPosition\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12!\n" +
+	"\x04side\x18\x02 \x01(\x0e2\r.nofx.v1.SideR\x04side\x12\x1f\n" +
+	"\ventry_price\x18\x03 \x01(\x01R\n" +
+	"entryPrice\x12\x1d\n" +
+	"\n" +
+	"mark_price\x18\x04 \x01(\x01R\tmarkPrice\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x01R\bquantity\x12\x1a\n" +
+	"\bleverage\x18\x06 \x01(\x05R\bleverage\x12%\n" +
+	"\x0eunrealized_pnl\x18\a \x01(\x01R\runrealizedPnl\x12,\n" +
+	"\x12unrealized_pnl_pct\x18\b \x01(\x01R\x10unrealizedPnlPct\x12+\n" +
+	"\x11liquidation_price\x18\t \x01(\x01R\x10liquidationPrice\x12\x1f\n" +
+	"\vmargin_used\x18\n" +
+	" \x01(\x01R\n" +
+	"marginUsed\x12\x1a\n" +
+	"\bstrategy\x18\v \x01(\tR\bstrategy\"e\n" +
+	"\x15ListPositionsResponse\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12/\n" +
+	"\tpositions\x18\x02 \x03(\v2\x11.nofx.v1.PositionR\tpositions\"n\n" +
+	"\x14ClosePositionRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12!\n" +
+	"\x04side\x18\x03 \x01(\x0e2\r.nofx.v1.SideR\x04side\"\xef\x01\n" +
+	"\vOrderResult\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12!\n" +
+	"\x04side\x18\x03 \x01(\x0e2\r.nofx.v1.SideR\x04side\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x19\n" +
+	"\border_id\x18\x05 \x01(\x03R\aorderId\x12&\n" +
+	"\x0fclient_order_id\x18\x06 \x01(\tR\rclientOrderId\x12\x14\n" +
+	"\x05price\x18\a \x01(\x01R\x05price\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\"g\n" +
+	"\x18SetTargetPositionRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\x01R\x06target\"r\n" +
+	"\vTargetOrder\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x01R\bquantity\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\x03R\aorderId\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xc4\x01\n" +
+	"\x14TargetPositionResult\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x18\n" +
+	"\acurrent\x18\x03 \x01(\x01R\acurrent\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\x01R\x06target\x12,\n" +
+	"\x06orders\x18\x05 \x03(\v2\x14.nofx.v1.TargetOrderR\x06orders\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\"_\n" +
+	"\x15WatchPositionsRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12)\n" +
+	"\x10interval_seconds\x18\x02 \x01(\x05R\x0fintervalSeconds\"\xb0\x01\n" +
+	"\x0ePositionUpdate\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12/\n" +
+	"\tpositions\x18\x04 \x03(\v2\x11.nofx.v1.PositionR\tpositions\"G\n" +
+	"\x12WatchEventsRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types\"\xa3\x03\n" +
+	"\x0eExecutionEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1b\n" +
+	"\ttrader_id\x18\x02 \x01(\tR\btraderId\x12\x1a\n" +
+	"\bexchange\x18\x03 \x01(\tR\bexchange\x12\x16\n" +
+	"\x06symbol\x18\x04 \x01(\tR\x06symbol\x12!\n" +
+	"\x04side\x18\x05 \x01(\x0e2\r.nofx.v1.SideR\x04side\x12\x16\n" +
+	"\x06action\x18\x06 \x01(\tR\x06action\x12\x1a\n" +
+	"\bquantity\x18\a \x01(\x01R\bquantity\x12\x14\n" +
+	"\x05price\x18\b \x01(\x01R\x05price\x12\x19\n" +
+	"\border_id\x18\t \x01(\x03R\aorderId\x12&\n" +
+	"\x0fclient_order_id\x18\n" +
+	" \x01(\tR\rclientOrderId\x12\x18\n" +
+	"\amessage\x18\v \x01(\tR\amessage\x128\n" +
+	"\ttimestamp\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06equity\x18\r \x01(\x01R\x06equity\x12\x10\n" +
+	"\x03pnl\x18\x0e \x01(\x01R\x03pnl\".\n" +
+	"\x0fExposureRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\"\xad\x02\n" +
+	"\x10PositionExposure\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12!\n" +
+	"\x04side\x18\x02 \x01(\x0e2\r.nofx.v1.SideR\x04side\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x01R\bquantity\x12\x1a\n" +
+	"\bnotional\x18\x04 \x01(\x01R\bnotional\x12\x1a\n" +
+	"\bleverage\x18\x05 \x01(\x05R\bleverage\x12\x1d\n" +
+	"\n" +
+	"mark_price\x18\x06 \x01(\x01R\tmarkPrice\x12+\n" +
+	"\x11liquidation_price\x18\a \x01(\x01R\x10liquidationPrice\x12(\n" +
+	"\x10liq_distance_pct\x18\b \x01(\x01R\x0eliqDistancePct\x12\x14\n" +
+	"\x05var95\x18\t \x01(\x01R\x05var95\"\x9b\x03\n" +
+	"\x0eTraderExposure\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bexchange\x18\x03 \x01(\tR\bexchange\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06equity\x18\x05 \x01(\x01R\x06equity\x12%\n" +
+	"\x0egross_exposure\x18\x06 \x01(\x01R\rgrossExposure\x12!\n" +
+	"\fnet_exposure\x18\a \x01(\x01R\vnetExposure\x12\x1a\n" +
+	"\bleverage\x18\b \x01(\x01R\bleverage\x12\x14\n" +
+	"\x05var95\x18\t \x01(\x01R\x05var95\x12\x14\n" +
+	"\x05var99\x18\n" +
+	" \x01(\x01R\x05var99\x127\n" +
+	"\tpositions\x18\v \x03(\v2\x19.nofx.v1.PositionExposureR\tpositions\x12\x1f\n" +
+	"\vshared_with\x18\f \x03(\tR\n" +
+	"sharedWith\"\xde\x01\n" +
+	"\x0eSymbolExposure\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12#\n" +
+	"\rlong_notional\x18\x02 \x01(\x01R\flongNotional\x12%\n" +
+	"\x0eshort_notional\x18\x03 \x01(\x01R\rshortNotional\x12!\n" +
+	"\fnet_notional\x18\x04 \x01(\x01R\vnetNotional\x12/\n" +
+	"\x14min_liq_distance_pct\x18\x05 \x01(\x01R\x11minLiqDistancePct\x12\x14\n" +
+	"\x05var95\x18\x06 \x01(\x01R\x05var95\"\xdb\x03\n" +
+	"\x10InstanceExposure\x12\x1f\n" +
+	"\vinstance_id\x18\x01 \x01(\tR\n" +
+	"instanceId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06equity\x18\x03 \x01(\x01R\x06equity\x12%\n" +
+	"\x0egross_exposure\x18\x04 \x01(\x01R\rgrossExposure\x12!\n" +
+	"\fnet_exposure\x18\x05 \x01(\x01R\vnetExposure\x12\x14\n" +
+	"\x05var95\x18\x06 \x01(\x01R\x05var95\x12\x14\n" +
+	"\x05var99\x18\a \x01(\x01R\x05var99\x121\n" +
+	"\asymbols\x18\b \x03(\v2\x17.nofx.v1.SymbolExposureR\asymbols\x121\n" +
+	"\atraders\x18\t \x03(\v2\x17.nofx.v1.TraderExposureR\atraders\x12=\n" +
+	"\x06errors\x18\n" +
+	" \x03(\v2%.nofx.v1.InstanceExposure.ErrorsEntryR\x06errors\x1a9\n" +
+	"\vErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*;\n" +
+	"\x04Side\x12\x14\n" +
+	"\x10SIDE_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tSIDE_LONG\x10\x01\x12\x0e\n" +
+	"\n" +
+	"SIDE_SHORT\x10\x022\x84\x06\n" +
+	"\vNofxService\x12H\n" +
+	"\vListTraders\x12\x1b.nofx.v1.ListTradersRequest\x1a\x1c.nofx.v1.ListTradersResponse\x12:\n" +
+	"\tGetStatus\x12\x16.nofx.v1.TraderRequest\x1a\x15.nofx.v1.TraderStatus\x12<\n" +
+	"\vStartTrader\x12\x16.nofx.v1.TraderRequest\x1a\x15.nofx.v1.TraderStatus\x12;\n" +
+	"\n" +
+	"StopTrader\x12\x16.nofx.v1.TraderRequest\x1a\x15.nofx.v1.TraderStatus\x126\n" +
+	"\n" +
+	"GetAccount\x12\x16.nofx.v1.TraderRequest\x1a\x10.nofx.v1.Account\x12G\n" +
+	"\rListPositions\x12\x16.nofx.v1.TraderRequest\x1a\x1e.nofx.v1.ListPositionsResponse\x12D\n" +
+	"\rClosePosition\x12\x1d.nofx.v1.ClosePositionRequest\x1a\x14.nofx.v1.OrderResult\x12U\n" +
+	"\x11SetTargetPosition\x12!.nofx.v1.SetTargetPositionRequest\x1a\x1d.nofx.v1.TargetPositionResult\x12K\n" +
+	"\x0eWatchPositions\x12\x1e.nofx.v1.WatchPositionsRequest\x1a\x17.nofx.v1.PositionUpdate0\x01\x12E\n" +
+	"\vWatchEvents\x12\x1b.nofx.v1.WatchEventsRequest\x1a\x17.nofx.v1.ExecutionEvent0\x01\x12B\n" +
+	"\vGetExposure\x12\x18.nofx.v1.ExposureRequest\x1a\x19.nofx.v1.InstanceExposureB\x10Z\x0enofx/rpc/pb;pbb\x06proto3"
+
+var (
+	file_pb_nofx_proto_rawDescOnce sync.Once
+	file_pb_nofx_proto_rawDescData []byte
+)
+
+func file_pb_nofx_proto_rawDescGZIP() []byte {
+	file_pb_nofx_proto_rawDescOnce.Do(func() {
+		file_pb_nofx_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pb_nofx_proto_rawDesc), len(file_pb_nofx_proto_rawDesc)))
+	})
+	return file_pb_nofx_proto_rawDescData
+}
+
+var file_pb_nofx_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_pb_nofx_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_pb_nofx_proto_goTypes = []any{
+	(Side)(0),                        // 0: nofx.v1.Side
+	(*TraderRequest)(nil),            // 1: nofx.v1.TraderRequest
+	(*ListTradersRequest)(nil),       // 2: nofx.v1.ListTradersRequest
+	(*ListTradersResponse)(nil),      // 3: nofx.v1.ListTradersResponse
+	(*TraderStatus)(nil),             // 4: nofx.v1.TraderStatus
+	(*Account)(nil),                  // 5: nofx.v1.Account
+	(*Position)(nil),                 // 6: nofx.v1.Position
+	(*ListPositionsResponse)(nil),    // 7: nofx.v1.ListPositionsResponse
+	(*ClosePositionRequest)(nil),     // 8: nofx.v1.ClosePositionRequest
+	(*OrderResult)(nil),              // 9: nofx.v1.OrderResult
+	(*SetTargetPositionRequest)(nil), // 10: nofx.v1.SetTargetPositionRequest
+	(*TargetOrder)(nil),              // 11: nofx.v1.TargetOrder
+	(*TargetPositionResult)(nil),     // 12: nofx.v1.TargetPositionResult
+	(*WatchPositionsRequest)(nil),    // 13: nofx.v1.WatchPositionsRequest
+	(*PositionUpdate)(nil),           // 14: nofx.v1.PositionUpdate
+	(*WatchEventsRequest)(nil),       // 15: nofx.v1.WatchEventsRequest
+	(*ExecutionEvent)(nil),           // 16: nofx.v1.ExecutionEvent
+	(*ExposureRequest)(nil),          // 17: nofx.v1.ExposureRequest
+	(*PositionExposure)(nil),         // 18: nofx.v1.PositionExposure
+	(*TraderExposure)(nil),           // 19: nofx.v1.TraderExposure
+	(*SymbolExposure)(nil),           // 20: nofx.v1.SymbolExposure
+	(*InstanceExposure)(nil),         // 21: nofx.v1.InstanceExposure
+	nil,                              // 22: nofx.v1.InstanceExposure.ErrorsEntry
+	(*timestamppb.Timestamp)(nil),    // 23: google.protobuf.Timestamp
+}
+var file_pb_nofx_proto_depIdxs = []int32{
+	4,  // 0: nofx.v1.ListTradersResponse.traders:type_name -> nofx.v1.TraderStatus
+	23, // 1: nofx.v1.TraderStatus.start_time:type_name -> google.protobuf.Timestamp
+	0,  // 2: nofx.v1.Position.side:type_name -> nofx.v1.Side
+	6,  // 3: nofx.v1.ListPositionsResponse.positions:type_name -> nofx.v1.Position
+	0,  // 4: nofx.v1.ClosePositionRequest.side:type_name -> nofx.v1.Side
+	0,  // 5: nofx.v1.OrderResult.side:type_name -> nofx.v1.Side
+	11, // 6: nofx.v1.TargetPositionResult.orders:type_name -> nofx.v1.TargetOrder
+	23, // 7: nofx.v1.PositionUpdate.timestamp:type_name -> google.protobuf.Timestamp
+	6,  // 8: nofx.v1.PositionUpdate.positions:type_name -> nofx.v1.Position
+	0,  // 9: nofx.v1.ExecutionEvent.side:type_name -> nofx.v1.Side
+	23, // 10: nofx.v1.ExecutionEvent.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 11: nofx.v1.PositionExposure.side:type_name -> nofx.v1.Side
+	23, // 12: nofx.v1.TraderExposure.timestamp:type_name -> google.protobuf.Timestamp
+	18, // 13: nofx.v1.TraderExposure.positions:type_name -> nofx.v1.PositionExposure
+	23, // 14: nofx.v1.InstanceExposure.timestamp:type_name -> google.protobuf.Timestamp
+	20, // 15: nofx.v1.InstanceExposure.symbols:type_name -> nofx.v1.SymbolExposure
+	19, // 16: nofx.v1.InstanceExposure.traders:type_name -> nofx.v1.TraderExposure
+	22, // 17: nofx.v1.InstanceExposure.errors:type_name -> nofx.v1.InstanceExposure.ErrorsEntry
+	2,  // 18: nofx.v1.NofxService.ListTraders:input_type -> nofx.v1.ListTradersRequest
+	1,  // 19: nofx.v1.NofxService.GetStatus:input_type -> nofx.v1.TraderRequest
+	1,  // 20: nofx.v1.NofxService.StartTrader:input_type -> nofx.v1.TraderRequest
+	1,  // 21: nofx.v1.NofxService.StopTrader:input_type -> nofx.v1.TraderRequest
+	1,  // 22: nofx.v1.NofxService.GetAccount:input_type -> nofx.v1.TraderRequest
+	1,  // 23: nofx.v1.NofxService.ListPositions:input_type -> nofx.v1.TraderRequest
+	8,  // 24: nofx.v1.NofxService.ClosePosition:input_type -> nofx.v1.ClosePositionRequest
+	10, // 25: nofx.v1.NofxService.SetTargetPosition:input_type -> nofx.v1.SetTargetPositionRequest
+	13, // 26: nofx.v1.NofxService.WatchPositions:input_type -> nofx.v1.WatchPositionsRequest
+	15, // 27: nofx.v1.NofxService.WatchEvents:input_type -> nofx.v1.WatchEventsRequest
+	17, // 28: nofx.v1.NofxService.GetExposure:input_type -> nofx.v1.ExposureRequest
+	3,  // 29: nofx.v1.NofxService.ListTraders:output_type -> nofx.v1.ListTradersResponse
+	4,  // 30: nofx.v1.NofxService.GetStatus:output_type -> nofx.v1.TraderStatus
+	4,  // 31: nofx.v1.NofxService.StartTrader:output_type -> nofx.v1.TraderStatus
+	4,  // 32: nofx.v1.NofxService.StopTrader:output_type -> nofx.v1.TraderStatus
+	5,  // 33: nofx.v1.NofxService.GetAccount:output_type -> nofx.v1.Account
+	7,  // 34: nofx.v1.NofxService.ListPositions:output_type -> nofx.v1.ListPositionsResponse
+	9,  // 35: nofx.v1.NofxService.ClosePosition:output_type -> nofx.v1.OrderResult
+	12, // 36: nofx.v1.NofxService.SetTargetPosition:output_type -> nofx.v1.TargetPositionResult
+	14, // 37: nofx.v1.NofxService.WatchPositions:output_type -> nofx.v1.PositionUpdate
+	16, // 38: nofx.v1.NofxService.WatchEvents:output_type -> nofx.v1.ExecutionEvent
+	21, // 39: nofx.v1.NofxService.GetExposure:output_type -> nofx.v1.InstanceExposure
+	29, // [29:40] is the sub-list for method output_type
+	18, // [18:29] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_pb_nofx_proto_init() }
+func file_pb_nofx_proto_init() {
+	if File_pb_nofx_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pb_nofx_proto_rawDesc), len(file_pb_nofx_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pb_nofx_proto_goTypes,
+		DependencyIndexes: file_pb_nofx_proto_depIdxs,
+		EnumInfos:         file_pb_nofx_proto_enumTypes,
+		MessageInfos:      file_pb_nofx_proto_msgTypes,
+	}.Build()
+	File_pb_nofx_proto = out.File
+	file_pb_nofx_proto_goTypes = nil
+	file_pb_nofx_proto_depIdxs = nil
+}
@@ -0,0 +1,532 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pb/nofx.proto
+
+// nofx gRPC 控制接口：查询账户/持仓/敞口、启停交易员、手动平仓及持仓/执行事件流
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NofxService_ListTraders_FullMethodName       = "/nofx.v1.NofxService/ListTraders"
+	NofxService_GetStatus_FullMethodName         = "/nofx.v1.NofxService/GetStatus"
+	NofxService_StartTrader_FullMethodName       = "/nofx.v1.NofxService/StartTrader"
+	NofxService_StopTrader_FullMethodName        = "/nofx.v1.NofxService/StopTrader"
+	NofxService_GetAccount_FullMethodName        = "/nofx.v1.NofxService/GetAccount"
+	NofxService_ListPositions_FullMethodName     = "/nofx.v1.NofxService/ListPositions"
+	NofxService_ClosePosition_FullMethodName     = "/nofx.v1.NofxService/ClosePosition"
+	NofxService_SetTargetPosition_FullMethodName = "/nofx.v1.NofxService/SetTargetPosition"
+	NofxService_WatchPositions_FullMethodName    = "/nofx.v1.NofxService/WatchPositions"
+	NofxService_WatchEvents_FullMethodName       = "/nofx.v1.NofxService/WatchEvents"
+	NofxService_GetExposure_FullMethodName       = "/nofx.v1.NofxService/GetExposure"
+)
+
+// NofxServiceClient is the client API for NofxService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NofxServiceClient interface {
+	// ListTraders 当前用户的交易员列表
+	ListTraders(ctx context.Context, in *ListTradersRequest, opts ...grpc.CallOption) (*ListTradersResponse, error)
+	// GetStatus 交易员运行状态
+	GetStatus(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error)
+	// StartTrader 启动交易员
+	StartTrader(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error)
+	// StopTrader 停止交易员
+	StopTrader(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error)
+	// GetAccount 账户信息
+	GetAccount(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*Account, error)
+	// ListPositions 持仓列表
+	ListPositions(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ListPositionsResponse, error)
+	// ClosePosition 市价全部平仓（与AI平仓决策走同一执行路径，受预演模式及持仓归属限制）
+	ClosePosition(ctx context.Context, in *ClosePositionRequest, opts ...grpc.CallOption) (*OrderResult, error)
+	// SetTargetPosition 将交易对的净仓位调整到目标数量（加仓走AI开仓的风控检查，受预演模式及两人规则限制）
+	SetTargetPosition(ctx context.Context, in *SetTargetPositionRequest, opts ...grpc.CallOption) (*TargetPositionResult, error)
+	// WatchPositions 持仓推送：订阅时推送一次快照，之后按间隔及成交/止盈止损事件推送
+	WatchPositions(ctx context.Context, in *WatchPositionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PositionUpdate], error)
+	// WatchEvents 执行事件流（下单/成交/撤单/止盈止损/错误/风险报告）
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecutionEvent], error)
+	// GetExposure 敞口快照：各交易员及按交易对汇总的名义价值、杠杆、强平距离、VaR（实时查询交易所持仓，供外部风控系统汇总多个实例）
+	GetExposure(ctx context.Context, in *ExposureRequest, opts ...grpc.CallOption) (*InstanceExposure, error)
+}
+
+type nofxServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNofxServiceClient(cc grpc.ClientConnInterface) NofxServiceClient {
+	return &nofxServiceClient{cc}
+}
+
+func (c *nofxServiceClient) ListTraders(ctx context.Context, in *ListTradersRequest, opts ...grpc.CallOption) (*ListTradersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTradersResponse)
+	err := c.cc.Invoke(ctx, NofxService_ListTraders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) GetStatus(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraderStatus)
+	err := c.cc.Invoke(ctx, NofxService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) StartTrader(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraderStatus)
+	err := c.cc.Invoke(ctx, NofxService_StartTrader_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) StopTrader(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraderStatus)
+	err := c.cc.Invoke(ctx, NofxService_StopTrader_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) GetAccount(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*Account, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Account)
+	err := c.cc.Invoke(ctx, NofxService_GetAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) ListPositions(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ListPositionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPositionsResponse)
+	err := c.cc.Invoke(ctx, NofxService_ListPositions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) ClosePosition(ctx context.Context, in *ClosePositionRequest, opts ...grpc.CallOption) (*OrderResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderResult)
+	err := c.cc.Invoke(ctx, NofxService_ClosePosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) SetTargetPosition(ctx context.Context, in *SetTargetPositionRequest, opts ...grpc.CallOption) (*TargetPositionResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TargetPositionResult)
+	err := c.cc.Invoke(ctx, NofxService_SetTargetPosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nofxServiceClient) WatchPositions(ctx context.Context, in *WatchPositionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PositionUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NofxService_ServiceDesc.Streams[0], NofxService_WatchPositions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchPositionsRequest, PositionUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NofxService_WatchPositionsClient = grpc.ServerStreamingClient[PositionUpdate]
+
+func (c *nofxServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecutionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NofxService_ServiceDesc.Streams[1], NofxService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, ExecutionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NofxService_WatchEventsClient = grpc.ServerStreamingClient[ExecutionEvent]
+
+func (c *nofxServiceClient) GetExposure(ctx context.Context, in *ExposureRequest, opts ...grpc.CallOption) (*InstanceExposure, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstanceExposure)
+	err := c.cc.Invoke(ctx, NofxService_GetExposure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NofxServiceServer is the server API for NofxService service.
+// All implementations must embed UnimplementedNofxServiceServer
+// for forward compatibility.
+type NofxServiceServer interface {
+	// ListTraders 当前用户的交易员列表
+	ListTraders(context.Context, *ListTradersRequest) (*ListTradersResponse, error)
+	// GetStatus 交易员运行状态
+	GetStatus(context.Context, *TraderRequest) (*TraderStatus, error)
+	// StartTrader 启动交易员
+	StartTrader(context.Context, *TraderRequest) (*TraderStatus, error)
+	// StopTrader 停止交易员
+	StopTrader(context.Context, *TraderRequest) (*TraderStatus, error)
+	// GetAccount 账户信息
+	GetAccount(context.Context, *TraderRequest) (*Account, error)
+	// ListPositions 持仓列表
+	ListPositions(context.Context, *TraderRequest) (*ListPositionsResponse, error)
+	// ClosePosition 市价全部平仓（与AI平仓决策走同一执行路径，受预演模式及持仓归属限制）
+	ClosePosition(context.Context, *ClosePositionRequest) (*OrderResult, error)
+	// SetTargetPosition 将交易对的净仓位调整到目标数量（加仓走AI开仓的风控检查，受预演模式及两人规则限制）
+	SetTargetPosition(context.Context, *SetTargetPositionRequest) (*TargetPositionResult, error)
+	// WatchPositions 持仓推送：订阅时推送一次快照，之后按间隔及成交/止盈止损事件推送
+	WatchPositions(*WatchPositionsRequest, grpc.ServerStreamingServer[PositionUpdate]) error
+	// WatchEvents 执行事件流（下单/成交/撤单/止盈止损/错误/风险报告）
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[ExecutionEvent]) error
+	// GetExposure 敞口快照：各交易员及按交易对汇总的名义价值、杠杆、强平距离、VaR（实时查询交易所持仓，供外部风控系统汇总多个实例）
+	GetExposure(context.Context, *ExposureRequest) (*InstanceExposure, error)
+	mustEmbedUnimplementedNofxServiceServer()
+}
+
+// UnimplementedNofxServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNofxServiceServer struct{}
+
+func (UnimplementedNofxServiceServer) ListTraders(context.Context, *ListTradersRequest) (*ListTradersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTraders not implemented")
+}
+func (UnimplementedNofxServiceServer) GetStatus(context.Context, *TraderRequest) (*TraderStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedNofxServiceServer) StartTrader(context.Context, *TraderRequest) (*TraderStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTrader not implemented")
+}
+func (UnimplementedNofxServiceServer) StopTrader(context.Context, *TraderRequest) (*TraderStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopTrader not implemented")
+}
+func (UnimplementedNofxServiceServer) GetAccount(context.Context, *TraderRequest) (*Account, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccount not implemented")
+}
+func (UnimplementedNofxServiceServer) ListPositions(context.Context, *TraderRequest) (*ListPositionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPositions not implemented")
+}
+func (UnimplementedNofxServiceServer) ClosePosition(context.Context, *ClosePositionRequest) (*OrderResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePosition not implemented")
+}
+func (UnimplementedNofxServiceServer) SetTargetPosition(context.Context, *SetTargetPositionRequest) (*TargetPositionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTargetPosition not implemented")
+}
+func (UnimplementedNofxServiceServer) WatchPositions(*WatchPositionsRequest, grpc.ServerStreamingServer[PositionUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPositions not implemented")
+}
+func (UnimplementedNofxServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[ExecutionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedNofxServiceServer) GetExposure(context.Context, *ExposureRequest) (*InstanceExposure, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExposure not implemented")
+}
+func (UnimplementedNofxServiceServer) mustEmbedUnimplementedNofxServiceServer() {}
+func (UnimplementedNofxServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeNofxServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NofxServiceServer will
+// result in compilation errors.
+type UnsafeNofxServiceServer interface {
+	mustEmbedUnimplementedNofxServiceServer()
+}
+
+func RegisterNofxServiceServer(s grpc.ServiceRegistrar, srv NofxServiceServer) {
+	// If the following call pancis, it indicates UnimplementedNofxServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NofxService_ServiceDesc, srv)
+}
+
+func _NofxService_ListTraders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTradersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).ListTraders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_ListTraders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).ListTraders(ctx, req.(*ListTradersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).GetStatus(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_StartTrader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).StartTrader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_StartTrader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).StartTrader(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_StopTrader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).StopTrader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_StopTrader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).StopTrader(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_GetAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).GetAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_GetAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).GetAccount(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_ListPositions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).ListPositions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_ListPositions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).ListPositions(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_ClosePosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClosePositionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).ClosePosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_ClosePosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).ClosePosition(ctx, req.(*ClosePositionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_SetTargetPosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTargetPositionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).SetTargetPosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_SetTargetPosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).SetTargetPosition(ctx, req.(*SetTargetPositionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NofxService_WatchPositions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPositionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NofxServiceServer).WatchPositions(m, &grpc.GenericServerStream[WatchPositionsRequest, PositionUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NofxService_WatchPositionsServer = grpc.ServerStreamingServer[PositionUpdate]
+
+func _NofxService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NofxServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, ExecutionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NofxService_WatchEventsServer = grpc.ServerStreamingServer[ExecutionEvent]
+
+func _NofxService_GetExposure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExposureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NofxServiceServer).GetExposure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NofxService_GetExposure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NofxServiceServer).GetExposure(ctx, req.(*ExposureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NofxService_ServiceDesc is the grpc.ServiceDesc for NofxService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NofxService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nofx.v1.NofxService",
+	HandlerType: (*NofxServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTraders",
+			Handler:    _NofxService_ListTraders_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _NofxService_GetStatus_Handler,
+		},
+		{
+			MethodName: "StartTrader",
+			Handler:    _NofxService_StartTrader_Handler,
+		},
+		{
+			MethodName: "StopTrader",
+			Handler:    _NofxService_StopTrader_Handler,
+		},
+		{
+			MethodName: "GetAccount",
+			Handler:    _NofxService_GetAccount_Handler,
+		},
+		{
+			MethodName: "ListPositions",
+			Handler:    _NofxService_ListPositions_Handler,
+		},
+		{
+			MethodName: "ClosePosition",
+			Handler:    _NofxService_ClosePosition_Handler,
+		},
+		{
+			MethodName: "SetTargetPosition",
+			Handler:    _NofxService_SetTargetPosition_Handler,
+		},
+		{
+			MethodName: "GetExposure",
+			Handler:    _NofxService_GetExposure_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPositions",
+			Handler:       _NofxService_WatchPositions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _NofxService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pb/nofx.proto",
+}
@@ -0,0 +1,681 @@
+// Package rpc 提供gRPC控制接口，供外部系统及其他语言通过强类型协议驱动nofx
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pb/nofx.proto
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"nofx/auth"
+	"nofx/config"
+	"nofx/events"
+	"nofx/logger"
+	"nofx/manager"
+	"nofx/rpc/pb"
+	"nofx/trader"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultWatchInterval 持仓推送默认间隔
+const defaultWatchInterval = 10 * time.Second
+
+type userIDKey struct{}
+
+// operatorKey 调用方的运维人员名称（登录邮箱或 token:名称，两人规则区分发起人）
+type operatorKey struct{}
+
+// Server gRPC服务器
+type Server struct {
+	pb.UnimplementedNofxServiceServer
+
+	traderManager *manager.TraderManager
+	database      config.Store
+	port          int
+	grpcServer    *grpc.Server
+}
+
+// NewServer 创建gRPC服务器（认证与HTTP API一致：Authorization: Bearer <JWT>，管理员模式下免认证）
+func NewServer(traderManager *manager.TraderManager, database config.Store, port int) *Server {
+	s := &Server{
+		traderManager: traderManager,
+		database:      database,
+		port:          port,
+	}
+	s.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+	pb.RegisterNofxServiceServer(s.grpcServer, s)
+	return s
+}
+
+// Start 启动gRPC服务器（阻塞）
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("监听gRPC端口失败: %w", err)
+	}
+	log.Printf("🔌 gRPC服务器启动在 :%d (service nofx.v1.NofxService)", s.port)
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop 停止gRPC服务器（等待进行中的一元调用完成，关闭推送流）
+func (s *Server) Stop() {
+	s.grpcServer.GracefulStop()
+}
+
+// tradeMethods 需要trade角色的方法（其余方法只读，read角色即可调用）
+var tradeMethods = map[string]bool{
+	pb.NofxService_StartTrader_FullMethodName:       true,
+	pb.NofxService_StopTrader_FullMethodName:        true,
+	pb.NofxService_ClosePosition_FullMethodName:     true,
+	pb.NofxService_SetTargetPosition_FullMethodName: true,
+}
+
+// authenticate 从metadata中解析JWT或静态API Token，校验调用方角色后返回用户ID及运维人员名称
+// 管理员模式设置了管理员密码时同样需要admin用户的JWT
+func authenticate(ctx context.Context, method string) (string, string, error) {
+	required := auth.RoleRead
+	if tradeMethods[method] {
+		required = auth.RoleTrade
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) > 0 {
+		if token, ok := auth.LookupAPIToken(strings.TrimPrefix(values[0], "Bearer ")); ok {
+			if !token.Role.Allows(required) {
+				return "", "", status.Errorf(codes.PermissionDenied, "权限不足: %s 需要 %s 角色（当前 %s）", method, required, token.Role)
+			}
+			return token.UserID, "token:" + token.Name, nil
+		}
+	}
+	if auth.IsAdminMode() && !auth.AdminPasswordRequired() {
+		return "admin", "admin@localhost", nil
+	}
+	if len(values) == 0 {
+		return "", "", status.Error(codes.Unauthenticated, "缺少authorization")
+	}
+	tokenParts := strings.Split(values[0], " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		return "", "", status.Error(codes.Unauthenticated, "无效的authorization格式")
+	}
+	claims, err := auth.ValidateJWT(tokenParts[1])
+	if err != nil {
+		return "", "", status.Error(codes.Unauthenticated, "无效的token: "+err.Error())
+	}
+	if auth.IsAdminMode() && claims.UserID != "admin" {
+		return "", "", status.Error(codes.Unauthenticated, "管理员模式下请使用管理员密码登录")
+	}
+	return claims.UserID, claims.Email, nil
+}
+
+// unaryAuth 一元调用认证拦截器
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	userID, operator, err := authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, operatorKey{}, operator)
+	return handler(context.WithValue(ctx, userIDKey{}, userID), req)
+}
+
+// authStream 携带用户ID的服务端流
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamAuth 流式调用认证拦截器
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	userID, operator, err := authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	ctx := context.WithValue(ss.Context(), operatorKey{}, operator)
+	return handler(srv, &authStream{ServerStream: ss, ctx: context.WithValue(ctx, userIDKey{}, userID)})
+}
+
+// userTraderIDs 当前用户可操作的交易员ID（管理员模式下为全部已加载交易员）
+func (s *Server) userTraderIDs(ctx context.Context) ([]string, error) {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+		log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+	}
+	if auth.IsAdminMode() {
+		return s.traderManager.GetTraderIDs(), nil
+	}
+	records, err := s.database.GetTraders(userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "获取交易员列表失败: %v", err)
+	}
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	return ids, nil
+}
+
+// getTrader 按ID获取当前用户的交易员
+func (s *Server) getTrader(ctx context.Context, traderID string) (*trader.AutoTrader, error) {
+	if traderID == "" {
+		return nil, status.Error(codes.InvalidArgument, "缺少trader_id")
+	}
+	ids, err := s.userTraderIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if id == traderID {
+			at, err := s.traderManager.GetTrader(traderID)
+			if err != nil {
+				return nil, status.Error(codes.NotFound, err.Error())
+			}
+			return at, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "交易员不存在")
+}
+
+// ListTraders 当前用户的交易员列表
+func (s *Server) ListTraders(ctx context.Context, _ *pb.ListTradersRequest) (*pb.ListTradersResponse, error) {
+	ids, err := s.userTraderIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListTradersResponse{}
+	for _, id := range ids {
+		if at, err := s.traderManager.GetTrader(id); err == nil {
+			resp.Traders = append(resp.Traders, toTraderStatus(at.GetStatus()))
+		}
+	}
+	return resp, nil
+}
+
+// GetStatus 交易员运行状态
+func (s *Server) GetStatus(ctx context.Context, req *pb.TraderRequest) (*pb.TraderStatus, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	return toTraderStatus(at.GetStatus()), nil
+}
+
+// StartTrader 启动交易员
+func (s *Server) StartTrader(ctx context.Context, req *pb.TraderRequest) (*pb.TraderStatus, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	if isRunning, ok := at.GetStatus()["is_running"].(bool); ok && isRunning {
+		return nil, status.Error(codes.FailedPrecondition, "交易员已在运行中")
+	}
+	if err := at.CheckPreflight(); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	go func() {
+		log.Printf("▶️  [gRPC] 启动交易员 %s (%s)", at.GetID(), at.GetName())
+		if err := at.Run(); err != nil {
+			log.Printf("❌ 交易员 %s 运行错误: %v", at.GetName(), err)
+		}
+	}()
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	if err := s.database.UpdateTraderStatus(userID, at.GetID(), true); err != nil {
+		log.Printf("⚠️  更新交易员状态失败: %v", err)
+	}
+
+	result := toTraderStatus(at.GetStatus())
+	result.IsRunning = true // Run在goroutine中设置运行标志，这里直接返回目标状态
+	return result, nil
+}
+
+// StopTrader 停止交易员
+func (s *Server) StopTrader(ctx context.Context, req *pb.TraderRequest) (*pb.TraderStatus, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	if isRunning, ok := at.GetStatus()["is_running"].(bool); ok && !isRunning {
+		return nil, status.Error(codes.FailedPrecondition, "交易员已停止")
+	}
+
+	at.Stop()
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	if err := s.database.UpdateTraderStatus(userID, at.GetID(), false); err != nil {
+		log.Printf("⚠️  更新交易员状态失败: %v", err)
+	}
+	log.Printf("⏹  [gRPC] 交易员 %s 已停止", at.GetName())
+	return toTraderStatus(at.GetStatus()), nil
+}
+
+// GetAccount 账户信息
+func (s *Server) GetAccount(ctx context.Context, req *pb.TraderRequest) (*pb.Account, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	account, err := at.GetAccountInfo()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "获取账户信息失败: %v", err)
+	}
+	return &pb.Account{
+		TraderId:         at.GetID(),
+		TotalEquity:      toFloat(account["total_equity"]),
+		WalletBalance:    toFloat(account["wallet_balance"]),
+		UnrealizedProfit: toFloat(account["unrealized_profit"]),
+		AvailableBalance: toFloat(account["available_balance"]),
+		TotalPnl:         toFloat(account["total_pnl"]),
+		TotalPnlPct:      toFloat(account["total_pnl_pct"]),
+		InitialBalance:   toFloat(account["initial_balance"]),
+		DailyPnl:         toFloat(account["daily_pnl"]),
+		PositionCount:    int32(toFloat(account["position_count"])),
+		MarginUsed:       toFloat(account["margin_used"]),
+		MarginUsedPct:    toFloat(account["margin_used_pct"]),
+	}, nil
+}
+
+// ListPositions 持仓列表
+func (s *Server) ListPositions(ctx context.Context, req *pb.TraderRequest) (*pb.ListPositionsResponse, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := positionsOf(at)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListPositionsResponse{TraderId: at.GetID(), Positions: positions}, nil
+}
+
+// ClosePosition 市价全部平仓
+func (s *Server) ClosePosition(ctx context.Context, req *pb.ClosePositionRequest) (*pb.OrderResult, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	side := fromSide(req.Side)
+	if req.Symbol == "" || side == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol和side不能为空")
+	}
+	symbol := strings.ToUpper(req.Symbol)
+	operator, _ := ctx.Value(operatorKey{}).(string)
+	notional, notionalErr := manager.PositionNotional(at, symbol, side)
+	var record *logger.DecisionAction
+	_, pending, err := s.traderManager.SubmitManualOrder(&manager.ManualOrder{
+		TraderIDs:       []string{at.GetID()},
+		Symbol:          symbol,
+		Action:          "close_" + side,
+		NotionalUSD:     notional,
+		NotionalUnknown: notionalErr != nil,
+		Source:          "gRPC",
+		Operator:        "gRPC:" + operator,
+		Identity:        manager.OperatorIdentity(operator),
+		Execute: func() (string, error) {
+			var err error
+			if record, err = at.ClosePosition(symbol, side); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("✅ %s 已平仓 %s %s", at.GetID(), symbol, side), nil
+		},
+	})
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if pending {
+		return nil, status.Error(codes.FailedPrecondition, "持仓名义价值超过两人规则阈值，已通知其他运维人员确认（Telegram /confirm 或 POST /api/manual-orders/confirm）")
+	}
+	return &pb.OrderResult{
+		TraderId:      at.GetID(),
+		Symbol:        record.Symbol,
+		Side:          req.Side,
+		Action:        record.Action,
+		OrderId:       record.OrderID,
+		ClientOrderId: record.ClientOrderID,
+		Price:         record.Price,
+		DryRun:        record.DryRun,
+	}, nil
+}
+
+// SetTargetPosition 将交易对的净仓位调整到目标数量（名义价值达到两人规则阈值时需另一位运维人员确认）
+func (s *Server) SetTargetPosition(ctx context.Context, req *pb.SetTargetPositionRequest) (*pb.TargetPositionResult, error) {
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol不能为空")
+	}
+	symbol := strings.ToUpper(req.Symbol)
+	operator, _ := ctx.Value(operatorKey{}).(string)
+	notional, notionalErr := at.TargetNotional(symbol, req.Target)
+	var result *trader.TargetPositionResult
+	_, pending, err := s.traderManager.SubmitManualOrder(&manager.ManualOrder{
+		TraderIDs:       []string{at.GetID()},
+		Symbol:          symbol,
+		Action:          fmt.Sprintf("target %.6f", req.Target),
+		NotionalUSD:     notional,
+		NotionalUnknown: notionalErr != nil,
+		Source:          "gRPC",
+		Operator:        "gRPC:" + operator,
+		Identity:        manager.OperatorIdentity(operator),
+		Execute: func() (string, error) {
+			var err error
+			if result, err = at.SetTargetPosition(symbol, req.Target); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("✅ %s %s 已调整到目标仓位 %.6f（%d 笔订单）", at.GetID(), symbol, req.Target, len(result.Orders)), nil
+		},
+	})
+	if pending {
+		return nil, status.Error(codes.FailedPrecondition, "名义价值超过两人规则阈值，已通知其他运维人员确认（Telegram /confirm 或 POST /api/manual-orders/confirm）")
+	}
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	resp := &pb.TargetPositionResult{TraderId: at.GetID(), Symbol: symbol, Current: result.Current, Target: result.Target, DryRun: result.DryRun}
+	for _, o := range result.Orders {
+		resp.Orders = append(resp.Orders, &pb.TargetOrder{Action: o.Action, Quantity: o.Quantity, OrderId: o.OrderID, Error: o.Error})
+	}
+	return resp, nil
+}
+
+// WatchPositions 持仓推送：先推送快照，之后定时推送，成交及止盈止损事件触发立即推送
+func (s *Server) WatchPositions(req *pb.WatchPositionsRequest, stream pb.NofxService_WatchPositionsServer) error {
+	ctx := stream.Context()
+	at, err := s.getTrader(ctx, req.TraderId)
+	if err != nil {
+		return err
+	}
+	interval := defaultWatchInterval
+	if req.IntervalSeconds > 0 {
+		interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	triggers := make(chan events.Type, 1)
+	unsubscribe := s.traderManager.EventBus().Subscribe("grpc-positions-"+at.GetID(), func(e events.Event) {
+		if e.TraderID != at.GetID() {
+			return
+		}
+		select {
+		case triggers <- e.Type:
+		default: // 已有待推送的更新，合并
+		}
+	}, events.OrderFilled, events.StopLossHit, events.TakeProfitHit)
+	defer unsubscribe()
+
+	send := func(reason string) error {
+		positions, err := positionsOf(at)
+		if err != nil {
+			log.Printf("⚠️  [gRPC] 推送持仓失败 [%s]: %v", at.GetName(), err)
+			return nil // 交易所临时错误不中断订阅
+		}
+		return stream.Send(&pb.PositionUpdate{
+			TraderId:  at.GetID(),
+			Timestamp: timestamppb.Now(),
+			Reason:    reason,
+			Positions: positions,
+		})
+	}
+
+	if err := send("snapshot"); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err = send("interval")
+		case eventType := <-triggers:
+			err = send(string(eventType))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WatchEvents 执行事件流
+func (s *Server) WatchEvents(req *pb.WatchEventsRequest, stream pb.NofxService_WatchEventsServer) error {
+	ctx := stream.Context()
+	allowed := make(map[string]bool)
+	if req.TraderId != "" {
+		at, err := s.getTrader(ctx, req.TraderId)
+		if err != nil {
+			return err
+		}
+		allowed[at.GetID()] = true
+	} else {
+		ids, err := s.userTraderIDs(ctx)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			allowed[id] = true
+		}
+	}
+	var types []events.Type
+	for _, t := range req.Types {
+		types = append(types, events.Type(t))
+	}
+
+	// 总线订阅者在独立goroutine中回调，这里转发到流（慢客户端时丢弃，不阻塞总线）
+	forward := make(chan events.Event, 64)
+	unsubscribe := s.traderManager.EventBus().Subscribe("grpc-events", func(e events.Event) {
+		if !allowed[e.TraderID] {
+			return
+		}
+		select {
+		case forward <- e:
+		default:
+			log.Printf("⚠️  [gRPC] 事件流客户端过慢，丢弃事件 %s", e.Type)
+		}
+	}, types...)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-forward:
+			if err := stream.Send(toExecutionEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetExposure 敞口快照（trader_id为空时为当前用户的全部交易员）
+func (s *Server) GetExposure(ctx context.Context, req *pb.ExposureRequest) (*pb.InstanceExposure, error) {
+	var traderIDs []string
+	if req.TraderId != "" {
+		at, err := s.getTrader(ctx, req.TraderId)
+		if err != nil {
+			return nil, err
+		}
+		traderIDs = []string{at.GetID()}
+	} else {
+		ids, err := s.userTraderIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return &pb.InstanceExposure{InstanceId: s.traderManager.InstanceID(), Timestamp: timestamppb.Now()}, nil
+		}
+		traderIDs = ids
+	}
+	return toInstanceExposure(s.traderManager.GetExposure(traderIDs...)), nil
+}
+
+// positionsOf 获取交易员持仓并转换为protobuf
+func positionsOf(at *trader.AutoTrader) ([]*pb.Position, error) {
+	positions, err := at.GetPositions()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "获取持仓列表失败: %v", err)
+	}
+	result := make([]*pb.Position, 0, len(positions))
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		strategy, _ := pos["strategy"].(string)
+		leverage, _ := pos["leverage"].(int)
+		result = append(result, &pb.Position{
+			Symbol:           symbol,
+			Side:             toSide(side),
+			EntryPrice:       toFloat(pos["entry_price"]),
+			MarkPrice:        toFloat(pos["mark_price"]),
+			Quantity:         toFloat(pos["quantity"]),
+			Leverage:         int32(leverage),
+			UnrealizedPnl:    toFloat(pos["unrealized_pnl"]),
+			UnrealizedPnlPct: toFloat(pos["unrealized_pnl_pct"]),
+			LiquidationPrice: toFloat(pos["liquidation_price"]),
+			MarginUsed:       toFloat(pos["margin_used"]),
+			Strategy:         strategy,
+		})
+	}
+	return result, nil
+}
+
+// toTraderStatus 状态map转换为protobuf
+func toTraderStatus(st map[string]interface{}) *pb.TraderStatus {
+	result := &pb.TraderStatus{
+		TraderId:       fmt.Sprint(st["trader_id"]),
+		Name:           fmt.Sprint(st["trader_name"]),
+		AiModel:        fmt.Sprint(st["ai_model"]),
+		Exchange:       fmt.Sprint(st["exchange"]),
+		InitialBalance: toFloat(st["initial_balance"]),
+		CallCount:      int64(toFloat(st["call_count"])),
+	}
+	result.IsRunning, _ = st["is_running"].(bool)
+	result.DryRun, _ = st["dry_run"].(bool)
+	if startTime, ok := st["start_time"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, startTime); err == nil {
+			result.StartTime = timestamppb.New(t)
+		}
+	}
+	return result
+}
+
+// toExecutionEvent 事件转换为protobuf
+func toExecutionEvent(e events.Event) *pb.ExecutionEvent {
+	return &pb.ExecutionEvent{
+		Type:          string(e.Type),
+		TraderId:      e.TraderID,
+		Exchange:      e.Exchange,
+		Symbol:        e.Symbol,
+		Side:          toSide(e.Side),
+		Action:        e.Action,
+		Quantity:      e.Quantity,
+		Price:         e.Price,
+		OrderId:       e.OrderID,
+		ClientOrderId: e.ClientOrderID,
+		Message:       e.Message,
+		Timestamp:     timestamppb.New(e.Timestamp),
+		Equity:        e.Equity,
+		Pnl:           e.PnL,
+	}
+}
+
+// toInstanceExposure 敞口快照转换为protobuf
+func toInstanceExposure(e *manager.InstanceExposure) *pb.InstanceExposure {
+	result := &pb.InstanceExposure{
+		InstanceId:    e.InstanceID,
+		Timestamp:     timestamppb.New(e.GeneratedAt),
+		Equity:        e.Equity,
+		GrossExposure: e.GrossExposure,
+		NetExposure:   e.NetExposure,
+		Var95:         e.VaR95,
+		Var99:         e.VaR99,
+		Errors:        e.Errors,
+	}
+	for _, s := range e.Symbols {
+		result.Symbols = append(result.Symbols, &pb.SymbolExposure{
+			Symbol:            s.Symbol,
+			LongNotional:      s.LongNotional,
+			ShortNotional:     s.ShortNotional,
+			NetNotional:       s.NetNotional,
+			MinLiqDistancePct: s.MinLiqDistancePct,
+			Var95:             s.VaR95,
+		})
+	}
+	for _, t := range e.Traders {
+		te := &pb.TraderExposure{
+			TraderId:      t.TraderID,
+			Name:          t.TraderName,
+			Exchange:      t.Exchange,
+			Timestamp:     timestamppb.New(t.GeneratedAt),
+			Equity:        t.Equity,
+			GrossExposure: t.GrossExposure,
+			NetExposure:   t.NetExposure,
+			Leverage:      t.Leverage,
+			Var95:         t.VaR95,
+			Var99:         t.VaR99,
+			SharedWith:    t.SharedWith,
+		}
+		for _, p := range t.Positions {
+			te.Positions = append(te.Positions, &pb.PositionExposure{
+				Symbol:           p.Symbol,
+				Side:             toSide(p.Side),
+				Quantity:         p.Quantity,
+				Notional:         p.Notional,
+				Leverage:         int32(p.Leverage),
+				MarkPrice:        p.MarkPrice,
+				LiquidationPrice: p.LiquidationPrice,
+				LiqDistancePct:   p.LiqDistancePct,
+				Var95:            p.VaR95,
+			})
+		}
+		result.Traders = append(result.Traders, te)
+	}
+	return result
+}
+
+// toSide 持仓方向转换为枚举
+func toSide(side string) pb.Side {
+	switch strings.ToLower(side) {
+	case "long":
+		return pb.Side_SIDE_LONG
+	case "short":
+		return pb.Side_SIDE_SHORT
+	}
+	return pb.Side_SIDE_UNSPECIFIED
+}
+
+// fromSide 枚举转换为持仓方向
+func fromSide(side pb.Side) string {
+	switch side {
+	case pb.Side_SIDE_LONG:
+		return "long"
+	case pb.Side_SIDE_SHORT:
+		return "short"
+	}
+	return ""
+}
+
+// toFloat 数值类型统一转换为float64
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
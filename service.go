@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// serviceOptions 系统服务安装参数
+type serviceOptions struct {
+	Name       string   // 服务名
+	Executable string   // nofx可执行文件的绝对路径
+	DataDir    string   // 数据目录（NOFX_DATA_DIR，同时作为工作目录）
+	LogFile    string   // 滚动日志文件（NOFX_LOG_FILE）
+	Args       []string // 传给nofx的参数（如数据库路径）
+}
+
+// Env 服务进程的环境变量
+func (o *serviceOptions) Env() map[string]string {
+	return map[string]string{
+		"NOFX_DATA_DIR":     o.DataDir,
+		"NOFX_LOG_FILE":     o.LogFile,
+		"NOFX_SERVICE_NAME": o.Name,
+	}
+}
+
+const serviceUsage = `用法:
+  nofx service install [-name nofx] [-data-dir 目录] [数据库路径]   安装为系统服务（开机自启、异常退出自动重启、日志滚动）
+  nofx service uninstall [-name nofx]                               停止并卸载系统服务
+
+Linux 使用 systemd（需root），macOS 使用 launchd（root安装为LaunchDaemon，否则为当前用户的LaunchAgent），Windows 使用服务控制管理器（需管理员权限）
+日志写入 数据目录/logs/nofx.log，超过50MB滚动，保留5个旧文件（可通过 NOFX_LOG_MAX_MB / NOFX_LOG_BACKUPS 调整）`
+
+// runServiceCommand 执行 nofx service 子命令，返回进程退出码
+func runServiceCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(serviceUsage)
+		return 2
+	}
+	cwd, _ := os.Getwd()
+	fs := flag.NewFlagSet("service "+args[0], flag.ContinueOnError)
+	name := fs.String("name", "nofx", "服务名")
+	dataDir := fs.String("data-dir", envOrDefault("NOFX_DATA_DIR", cwd), "数据目录（数据库、决策日志、运行日志）")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 获取可执行文件路径失败: %v\n", err)
+		return 1
+	}
+	dir, err := filepath.Abs(*dataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 无效的数据目录: %v\n", err)
+		return 1
+	}
+	opts := &serviceOptions{
+		Name:       *name,
+		Executable: exe,
+		DataDir:    dir,
+		LogFile:    filepath.Join(dir, "logs", "nofx.log"),
+		Args:       fs.Args(),
+	}
+
+	switch args[0] {
+	case "install":
+		err = installService(opts)
+	case "uninstall":
+		err = uninstallService(opts)
+	default:
+		fmt.Println(serviceUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runCommand 执行系统命令，失败时附带命令输出
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("执行 %s %s 失败: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// launchdLabel launchd任务标识
+func launchdLabel(name string) string {
+	return "com.nofx." + name
+}
+
+// launchdPlistPath root安装为LaunchDaemon（开机启动），否则为当前用户的LaunchAgent（登录后启动）
+func launchdPlistPath(name string) (string, error) {
+	if os.Geteuid() == 0 {
+		return filepath.Join("/Library/LaunchDaemons", launchdLabel(name)+".plist"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel(name)+".plist"), nil
+}
+
+// launchdPlist 生成launchd任务配置（开机/登录时启动，异常退出后10秒重启）
+// launchd自身不做日志滚动，stdout/stderr只保留启动失败等少量输出，运行日志由 NOFX_LOG_FILE 滚动写入
+func launchdPlist(opts *serviceOptions) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	sb.WriteString(fmt.Sprintf("\t<key>Label</key>\n\t<string>%s</string>\n", html.EscapeString(launchdLabel(opts.Name))))
+	sb.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{opts.Executable}, opts.Args...) {
+		sb.WriteString(fmt.Sprintf("\t\t<string>%s</string>\n", html.EscapeString(arg)))
+	}
+	sb.WriteString("\t</array>\n")
+	sb.WriteString(fmt.Sprintf("\t<key>WorkingDirectory</key>\n\t<string>%s</string>\n", html.EscapeString(opts.DataDir)))
+	sb.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+	env := opts.Env()
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("\t\t<key>%s</key>\n\t\t<string>%s</string>\n", k, html.EscapeString(env[k])))
+	}
+	sb.WriteString("\t</dict>\n")
+	sb.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	sb.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	sb.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>10</integer>\n")
+	stdoutPath := filepath.Join(filepath.Dir(opts.LogFile), "launchd.log")
+	sb.WriteString(fmt.Sprintf("\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", html.EscapeString(stdoutPath)))
+	sb.WriteString(fmt.Sprintf("\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", html.EscapeString(stdoutPath)))
+	sb.WriteString("</dict>\n</plist>\n")
+	return sb.String()
+}
+
+// installService 写入launchd任务配置并加载
+func installService(opts *serviceOptions) error {
+	plistPath, err := launchdPlistPath(opts.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.LogFile), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return fmt.Errorf("创建 %s 失败: %w", filepath.Dir(plistPath), err)
+	}
+	if err := os.WriteFile(plistPath, []byte(launchdPlist(opts)), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", plistPath, err)
+	}
+	if err := runCommand("launchctl", "load", "-w", plistPath); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已安装launchd服务 %s (%s)\n", launchdLabel(opts.Name), plistPath)
+	fmt.Printf("   查看状态: launchctl list | grep %s\n   查看日志: tail -f %s\n", launchdLabel(opts.Name), opts.LogFile)
+	return nil
+}
+
+// uninstallService 卸载launchd任务并删除配置
+func uninstallService(opts *serviceOptions) error {
+	plistPath, err := launchdPlistPath(opts.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(plistPath); err != nil {
+		return fmt.Errorf("服务 %s 未安装: %w", launchdLabel(opts.Name), err)
+	}
+	if err := runCommand("launchctl", "unload", "-w", plistPath); err != nil {
+		return err
+	}
+	if err := os.Remove(plistPath); err != nil {
+		return fmt.Errorf("删除 %s 失败: %w", plistPath, err)
+	}
+	fmt.Printf("✅ 已卸载launchd服务 %s\n", launchdLabel(opts.Name))
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// systemdUnitDir systemd系统服务单元目录
+const systemdUnitDir = "/etc/systemd/system"
+
+// systemdUnit 生成systemd服务单元（异常退出10秒后重启，stdout/stderr同时进入journald）
+func systemdUnit(opts *serviceOptions) string {
+	var sb strings.Builder
+	sb.WriteString("[Unit]\n")
+	sb.WriteString("Description=NOFX AI trading bot\n")
+	sb.WriteString("After=network-online.target\n")
+	sb.WriteString("Wants=network-online.target\n\n")
+	sb.WriteString("[Service]\n")
+	sb.WriteString("Type=simple\n")
+	sb.WriteString(fmt.Sprintf("WorkingDirectory=%s\n", opts.DataDir))
+	sb.WriteString(fmt.Sprintf("ExecStart=%s\n", systemdCommand(append([]string{opts.Executable}, opts.Args...))))
+	env := opts.Env()
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("Environment=%s\n", strconv.Quote(k+"="+env[k])))
+	}
+	sb.WriteString("Restart=on-failure\n")
+	sb.WriteString("RestartSec=10\n")
+	sb.WriteString("KillSignal=SIGTERM\n")
+	sb.WriteString("TimeoutStopSec=60\n\n")
+	sb.WriteString("[Install]\n")
+	sb.WriteString("WantedBy=multi-user.target\n")
+	return sb.String()
+}
+
+// systemdCommand 拼接ExecStart命令行（含空格的参数加引号）
+func systemdCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t\"'\\") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// installService 写入systemd服务单元并设置开机自启
+func installService(opts *serviceOptions) error {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return fmt.Errorf("未找到systemctl，当前系统不支持systemd: %w", err)
+	}
+	if err := os.MkdirAll(opts.DataDir, 0755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+	unitPath := filepath.Join(systemdUnitDir, opts.Name+".service")
+	if err := os.WriteFile(unitPath, []byte(systemdUnit(opts)), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败（需要root权限）: %w", unitPath, err)
+	}
+	if err := runCommand("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	if err := runCommand("systemctl", "enable", "--now", opts.Name); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已安装systemd服务 %s (%s)\n", opts.Name, unitPath)
+	fmt.Printf("   查看状态: systemctl status %s\n   查看日志: journalctl -u %s -f 或 %s\n", opts.Name, opts.Name, opts.LogFile)
+	return nil
+}
+
+// uninstallService 停止并删除systemd服务
+func uninstallService(opts *serviceOptions) error {
+	unitPath := filepath.Join(systemdUnitDir, opts.Name+".service")
+	if _, err := os.Stat(unitPath); err != nil {
+		return fmt.Errorf("服务 %s 未安装: %w", opts.Name, err)
+	}
+	if err := runCommand("systemctl", "disable", "--now", opts.Name); err != nil {
+		return err
+	}
+	if err := os.Remove(unitPath); err != nil {
+		return fmt.Errorf("删除 %s 失败: %w", unitPath, err)
+	}
+	if err := runCommand("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已卸载systemd服务 %s\n", opts.Name)
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// installService 当前系统不支持自动安装服务
+func installService(opts *serviceOptions) error {
+	return fmt.Errorf("暂不支持在 %s 上安装系统服务", runtime.GOOS)
+}
+
+// uninstallService 当前系统不支持自动安装服务
+func uninstallService(opts *serviceOptions) error {
+	return fmt.Errorf("暂不支持在 %s 上安装系统服务", runtime.GOOS)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// watchServiceControl systemd/launchd通过SIGTERM停止服务，已由信号处理覆盖
+func watchServiceControl(stop chan<- os.Signal) {}
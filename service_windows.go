@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// init 服务控制管理器以System32为工作目录启动服务，切换到安装时指定的数据目录（config.json等相对路径以此为准）
+func init() {
+	if isService, err := svc.IsWindowsService(); err == nil && isService {
+		if dir := os.Getenv("NOFX_DATA_DIR"); dir != "" {
+			os.Chdir(dir)
+		}
+	}
+}
+
+// installService 注册Windows服务（自动启动，异常退出后10秒/10秒/60秒重启）
+func installService(opts *serviceOptions) error {
+	if err := os.MkdirAll(opts.DataDir, 0755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务控制管理器失败（需要管理员权限）: %w", err)
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(opts.Name); err == nil {
+		s.Close()
+		return fmt.Errorf("服务 %s 已存在", opts.Name)
+	}
+
+	s, err := m.CreateService(opts.Name, opts.Executable, mgr.Config{
+		DisplayName:      "NOFX AI trading bot",
+		Description:      "NOFX AI交易系统",
+		StartType:        mgr.StartAutomatic,
+		DelayedAutoStart: true,
+	}, opts.Args...)
+	if err != nil {
+		return fmt.Errorf("创建服务失败: %w", err)
+	}
+	defer s.Close()
+
+	recovery := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 60 * time.Second},
+	}
+	if err := s.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("设置服务失败重启策略失败: %w", err)
+	}
+	if err := setServiceEnv(opts); err != nil {
+		return err
+	}
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("启动服务失败: %w", err)
+	}
+	fmt.Printf("✅ 已安装Windows服务 %s\n", opts.Name)
+	fmt.Printf("   查看状态: sc query %s\n   查看日志: %s\n", opts.Name, opts.LogFile)
+	return nil
+}
+
+// setServiceEnv 通过注册表为服务进程设置环境变量（Windows服务不继承安装时的环境）
+func setServiceEnv(opts *serviceOptions) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+opts.Name, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("打开服务注册表项失败: %w", err)
+	}
+	defer key.Close()
+	env := opts.Env()
+	values := make([]string, 0, len(env))
+	for k, v := range env {
+		values = append(values, k+"="+v)
+	}
+	sort.Strings(values)
+	if err := key.SetStringsValue("Environment", values); err != nil {
+		return fmt.Errorf("设置服务环境变量失败: %w", err)
+	}
+	return nil
+}
+
+// uninstallService 停止并删除Windows服务
+func uninstallService(opts *serviceOptions) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务控制管理器失败（需要管理员权限）: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(opts.Name)
+	if err != nil {
+		return fmt.Errorf("服务 %s 未安装: %w", opts.Name, err)
+	}
+	defer s.Close()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		for deadline := time.Now().Add(60 * time.Second); status.State != svc.Stopped && time.Now().Before(deadline); {
+			time.Sleep(500 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("删除服务失败: %w", err)
+	}
+	fmt.Printf("✅ 已卸载Windows服务 %s\n", opts.Name)
+	return nil
+}
+
+// windowsService 服务控制管理器的停止/关机请求转为退出信号
+type windowsService struct {
+	stop chan<- os.Signal
+}
+
+// Execute 实现 svc.Handler
+func (w *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			changes <- req.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			changes <- svc.Status{State: svc.StopPending}
+			w.stop <- os.Interrupt
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// watchServiceControl 作为Windows服务运行时，把停止请求转发到退出信号通道（服务名为安装时写入的 NOFX_SERVICE_NAME）
+func watchServiceControl(stop chan<- os.Signal) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return
+	}
+	go func() {
+		if err := svc.Run(envOrDefault("NOFX_SERVICE_NAME", "nofx"), &windowsService{stop: stop}); err != nil {
+			fmt.Fprintf(os.Stderr, "Windows服务运行失败: %v\n", err)
+		}
+	}()
+}
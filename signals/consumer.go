@@ -0,0 +1,129 @@
+package signals
+
+import (
+	"context"
+	"log"
+	"nofx/manager"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxAge   = 60 * time.Second // 超过该时长的信号视为过期
+	dedupeWindow    = 10 * time.Minute // 相同id的信号在该窗口内只执行一次
+	maxReconnectGap = time.Minute
+)
+
+// Consumer 外部信号消费者：断线自动重连，按trader_id分发给交易员执行
+type Consumer struct {
+	source        Source
+	traderManager *manager.TraderManager
+	MaxAge        time.Duration // 信号最大延迟（0=60秒；仅对带timestamp的信号生效）
+
+	mu     sync.Mutex
+	seen   map[string]time.Time // 已处理的信号id -> 接收时间
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConsumer 创建信号消费者
+func NewConsumer(source Source, traderManager *manager.TraderManager) *Consumer {
+	return &Consumer{
+		source:        source,
+		traderManager: traderManager,
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// Start 在后台开始消费信号
+func (c *Consumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		backoff := time.Second
+		for {
+			log.Printf("📡 外部信号订阅: %s", c.source.Name())
+			startedAt := time.Now()
+			err := c.source.Run(ctx, c.handle)
+			if ctx.Err() != nil {
+				return
+			}
+			if time.Since(startedAt) > maxReconnectGap {
+				backoff = time.Second // 连接稳定运行过，重置退避
+			}
+			log.Printf("⚠️  外部信号订阅中断: %v，%v 后重连", err, backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > maxReconnectGap {
+				backoff = maxReconnectGap
+			}
+		}
+	}()
+}
+
+// Stop 停止消费并等待后台goroutine退出
+func (c *Consumer) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	<-c.done
+}
+
+// handle 处理一条消息：校验、过期及重复检查，然后交给交易员执行
+func (c *Consumer) handle(payload []byte) {
+	sig, err := Parse(payload)
+	if err != nil {
+		log.Printf("⚠️  忽略无效外部信号: %v", err)
+		return
+	}
+
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultMaxAge
+	}
+	if age := sig.Age(time.Now()); age > maxAge {
+		log.Printf("⚠️  忽略过期外部信号 %s %s %s（延迟 %v）", sig.ID, sig.Symbol, sig.Action, age.Round(time.Second))
+		return
+	}
+	if c.isDuplicate(sig.ID) {
+		log.Printf("⚠️  忽略重复外部信号 %s", sig.ID)
+		return
+	}
+
+	at, err := c.traderManager.GetTrader(sig.TraderID)
+	if err != nil {
+		log.Printf("⚠️  外部信号的交易员不存在: %s", sig.TraderID)
+		return
+	}
+	if _, err := at.ExecuteSignal(sig.Decision(), c.source.Name()); err != nil {
+		log.Printf("❌ [%s] 外部信号执行失败 (%s %s): %v", at.GetName(), sig.Symbol, sig.Action, err)
+	}
+}
+
+// isDuplicate 检查并记录信号id（无id的信号不去重）
+func (c *Consumer) isDuplicate(id string) bool {
+	if id == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, at := range c.seen {
+		if now.Sub(at) > dedupeWindow {
+			delete(c.seen, key)
+		}
+	}
+	if _, ok := c.seen[id]; ok {
+		return true
+	}
+	c.seen[id] = now
+	return false
+}
@@ -0,0 +1,120 @@
+// Package signals 外部交易信号接入：订阅Redis频道或MQTT主题，校验后交给对应交易员执行
+package signals
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"nofx/decision"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema 外部信号的JSON Schema（供信号生成方校验，Parse按同一规则校验）
+//
+//go:embed schema.json
+var Schema []byte
+
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]+USDT$`)
+
+// Signal 外部交易信号
+type Signal struct {
+	ID              string  `json:"id,omitempty"`
+	TraderID        string  `json:"trader_id"`
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Leverage        int     `json:"leverage,omitempty"`
+	PositionSizeUSD float64 `json:"position_size_usd,omitempty"`
+	StopLoss        float64 `json:"stop_loss,omitempty"`
+	TakeProfit      float64 `json:"take_profit,omitempty"`
+	StopLossPct     float64 `json:"stop_loss_pct,omitempty"`   // 止损距成交价的百分比（可替代stop_loss）
+	StopLossATR     float64 `json:"stop_loss_atr,omitempty"`   // 止损距离为ATR14(1h)的倍数（可替代stop_loss）
+	TakeProfitPct   float64 `json:"take_profit_pct,omitempty"` // 止盈距成交价的百分比（可替代take_profit）
+	TakeProfitR     float64 `json:"take_profit_r,omitempty"`   // 止盈为止损距离的R倍（可替代take_profit）
+	Confidence      int     `json:"confidence,omitempty"`
+	Reasoning       string  `json:"reasoning,omitempty"`
+	Timestamp       int64   `json:"timestamp,omitempty"` // Unix毫秒
+}
+
+// Parse 解析并校验信号（不允许未知字段，规则与schema.json一致）
+func Parse(data []byte) (*Signal, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var s Signal
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("解析信号失败: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("解析信号失败: JSON后存在多余内容")
+	}
+
+	if s.TraderID == "" {
+		return nil, fmt.Errorf("缺少trader_id")
+	}
+	if len(s.ID) > 128 {
+		return nil, fmt.Errorf("id长度不能超过128")
+	}
+	if !symbolPattern.MatchString(s.Symbol) {
+		return nil, fmt.Errorf("无效的symbol: %q", s.Symbol)
+	}
+	switch s.Action {
+	case "open_long", "open_short":
+		if s.Leverage < 1 {
+			return nil, fmt.Errorf("开仓信号的leverage必须≥1")
+		}
+		if s.PositionSizeUSD <= 0 {
+			return nil, fmt.Errorf("开仓信号必须提供position_size_usd且大于0")
+		}
+		if s.StopLoss < 0 || s.TakeProfit < 0 || s.StopLossPct < 0 || s.StopLossATR < 0 || s.TakeProfitPct < 0 || s.TakeProfitR < 0 {
+			return nil, fmt.Errorf("止损止盈参数不能为负")
+		}
+		if s.StopLoss == 0 && s.StopLossPct == 0 && s.StopLossATR == 0 {
+			return nil, fmt.Errorf("开仓信号必须提供stop_loss、stop_loss_pct或stop_loss_atr")
+		}
+		if s.TakeProfit == 0 && s.TakeProfitPct == 0 && s.TakeProfitR == 0 {
+			return nil, fmt.Errorf("开仓信号必须提供take_profit、take_profit_pct或take_profit_r")
+		}
+	case "close_long", "close_short":
+	default:
+		return nil, fmt.Errorf("无效的action: %q（可选: open_long, open_short, close_long, close_short）", s.Action)
+	}
+	if s.Confidence < 0 || s.Confidence > 100 {
+		return nil, fmt.Errorf("confidence必须在0-100之间")
+	}
+	if len(s.Reasoning) > 2000 {
+		return nil, fmt.Errorf("reasoning长度不能超过2000")
+	}
+	return &s, nil
+}
+
+// Age 信号生成至今的时长（未提供timestamp时返回0）
+func (s *Signal) Age(now time.Time) time.Duration {
+	if s.Timestamp == 0 {
+		return 0
+	}
+	return now.Sub(time.UnixMilli(s.Timestamp))
+}
+
+// Decision 转换为交易决策
+func (s *Signal) Decision() decision.Decision {
+	reasoning := s.Reasoning
+	if s.ID != "" {
+		reasoning = strings.TrimSpace(fmt.Sprintf("[%s] %s", s.ID, reasoning))
+	}
+	return decision.Decision{
+		Symbol:          s.Symbol,
+		Action:          s.Action,
+		Leverage:        s.Leverage,
+		PositionSizeUSD: s.PositionSizeUSD,
+		StopLoss:        s.StopLoss,
+		TakeProfit:      s.TakeProfit,
+		StopLossPct:     s.StopLossPct,
+		StopLossATR:     s.StopLossATR,
+		TakeProfitPct:   s.TakeProfitPct,
+		TakeProfitR:     s.TakeProfitR,
+		Confidence:      s.Confidence,
+		Reasoning:       reasoning,
+	}
+}
@@ -0,0 +1,204 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/trader"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 确认按钮的回调数据前缀
+const (
+	callbackApprove = "approve:"
+	callbackReject  = "reject:"
+)
+
+// actionNames 决策动作的中文名称
+var actionNames = map[string]string{
+	"open_long":   "开多",
+	"open_short":  "开空",
+	"close_long":  "平多",
+	"close_short": "平空",
+}
+
+// pendingApproval 等待确认的决策
+type pendingApproval struct {
+	text     string
+	messages map[int64]int64 // 已发送的确认消息 (chat ID -> message ID)
+	result   chan bool       // 容量为1，由按钮回调写入
+	operator string          // 做出答复的运维人员
+}
+
+// RequestApproval 将决策连同批准/拒绝按钮发送给所有运维人员，任一人答复即生效
+// 实现 trader.Approver；ctx到期或机器人停止时视为未批准
+func (b *Bot) RequestApproval(ctx context.Context, req trader.ApprovalRequest) (bool, error) {
+	id := strconv.FormatInt(time.Now().UnixNano(), 36)
+	p := &pendingApproval{
+		text:     formatApproval(req),
+		messages: make(map[int64]int64),
+		result:   make(chan bool, 1),
+	}
+	markup := &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "✅ 批准", CallbackData: callbackApprove + id},
+		{Text: "❌ 拒绝", CallbackData: callbackReject + id},
+	}}}
+
+	b.pendingMu.Lock()
+	b.pending[id] = p
+	b.pendingMu.Unlock()
+
+	var sendErr error
+	for chatID := range b.operators {
+		msg, err := b.client.sendMessage(ctx, chatID, p.text, markup)
+		if err != nil {
+			sendErr = err
+			continue
+		}
+		b.pendingMu.Lock()
+		p.messages[chatID] = msg.MessageID
+		b.pendingMu.Unlock()
+	}
+	if len(p.messages) == 0 {
+		b.removePending(id)
+		return false, fmt.Errorf("发送确认消息失败: %w", sendErr)
+	}
+
+	var approved bool
+	var err error
+	select {
+	case approved = <-p.result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-b.ctx.Done():
+		err = fmt.Errorf("Telegram机器人已停止")
+	}
+	if err != nil {
+		if !b.removePending(id) {
+			// 按钮回调已先一步取走请求，以回调结果为准
+			approved, err = <-p.result, nil
+		}
+	}
+
+	var outcome string
+	switch {
+	case err != nil:
+		outcome = "⌛ 超时未确认，未执行"
+	case approved:
+		outcome = "✅ 已由 " + p.operator + " 批准"
+	default:
+		outcome = "❌ 已由 " + p.operator + " 拒绝"
+	}
+	b.finishApproval(p, outcome)
+	return approved, err
+}
+
+// removePending 移除等待中的请求（已被移除时返回false）
+func (b *Bot) removePending(id string) bool {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	if _, ok := b.pending[id]; !ok {
+		return false
+	}
+	delete(b.pending, id)
+	return true
+}
+
+// finishApproval 将所有确认消息更新为最终结果并移除按钮
+func (b *Bot) finishApproval(p *pendingApproval, outcome string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	b.pendingMu.Lock()
+	messages := p.messages
+	b.pendingMu.Unlock()
+	for chatID, messageID := range messages {
+		if err := b.client.editMessageText(ctx, chatID, messageID, p.text+"\n\n"+outcome); err != nil {
+			log.Printf("⚠️  Telegram更新确认消息失败: %v", err)
+		}
+	}
+}
+
+// handleCallback 处理批准/拒绝按钮
+func (b *Bot) handleCallback(query *CallbackQuery) {
+	answer := func(text string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := b.client.answerCallbackQuery(ctx, query.ID, text); err != nil {
+			log.Printf("⚠️  Telegram应答按钮失败: %v", err)
+		}
+	}
+	if !b.operators[query.From.ID] {
+		log.Printf("⚠️  拒绝未授权的Telegram确认操作: 用户 %d (@%s)", query.From.ID, query.From.Username)
+		answer("未授权")
+		return
+	}
+
+	var id string
+	var approved bool
+	switch {
+	case strings.HasPrefix(query.Data, callbackApprove):
+		id, approved = strings.TrimPrefix(query.Data, callbackApprove), true
+	case strings.HasPrefix(query.Data, callbackReject):
+		id = strings.TrimPrefix(query.Data, callbackReject)
+	default:
+		answer("未知操作")
+		return
+	}
+
+	b.pendingMu.Lock()
+	p, ok := b.pending[id]
+	if ok {
+		delete(b.pending, id)
+		p.operator = operatorName(query.From)
+		p.result <- approved
+	}
+	b.pendingMu.Unlock()
+	if !ok {
+		answer("该请求已处理或已过期")
+		return
+	}
+
+	verb := "拒绝"
+	if approved {
+		verb = "批准"
+	}
+	log.Printf("📱 Telegram确认: 用户 %d %s请求 %s", query.From.ID, verb, id)
+	answer("已" + verb)
+}
+
+// operatorName 运维人员显示名称
+func operatorName(user User) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return strconv.FormatInt(user.ID, 10)
+}
+
+// formatApproval 生成确认消息文本
+func formatApproval(req trader.ApprovalRequest) string {
+	d := req.Decision
+	action := actionNames[d.Action]
+	if action == "" {
+		action = d.Action
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🙋 待确认 [%s] %s\n", req.TraderName, req.Source))
+	sb.WriteString(fmt.Sprintf("%s %s", d.Symbol, action))
+	if d.Action == "open_long" || d.Action == "open_short" {
+		sb.WriteString(fmt.Sprintf(" %dx 仓位 %.2f USDT\n止损 %.4f 止盈 %.4f", d.Leverage, d.PositionSizeUSD, d.StopLoss, d.TakeProfit))
+	}
+	if d.Confidence > 0 {
+		sb.WriteString(fmt.Sprintf(" 信心 %d", d.Confidence))
+	}
+	if reasoning := []rune(d.Reasoning); len(reasoning) > 0 {
+		if len(reasoning) > 500 {
+			reasoning = append(reasoning[:500], '…')
+		}
+		sb.WriteString("\n理由: " + string(reasoning))
+	}
+	sb.WriteString(fmt.Sprintf("\n⏱ %s内未确认将自动放弃", req.Timeout))
+	return sb.String()
+}
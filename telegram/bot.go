@@ -0,0 +1,527 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"nofx/events"
+	"nofx/fx"
+	"nofx/logger"
+	"nofx/manager"
+	"nofx/notify"
+	"nofx/trader"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMessageLength Telegram单条消息长度上限
+const maxMessageLength = 4000
+
+// indefinitePause 未指定时长的 /pause 视为暂停到手动 /resume
+const indefinitePause = 365 * 24 * time.Hour
+
+// Bot Telegram运维机器人
+// 只处理白名单用户的指令；多实例共用同一个Bot Token时只能在一个实例上启用（Telegram不允许并发getUpdates）
+type Bot struct {
+	client        *client
+	operators     map[int64]bool
+	operatorUsers map[int64]string                   // Telegram用户ID -> 关联的用户ID（两人规则，见 SetOperatorUsers）
+	ownsTrader    func(userID, traderID string) bool // 关联用户有权操作的交易员
+	traderManager *manager.TraderManager
+	offset        int64
+	pendingMu     sync.Mutex
+	pending       map[string]*pendingApproval // 等待确认的决策 (请求ID -> 状态)
+	unsubscribe   func()
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// ParseOperatorIDs 解析逗号分隔的Telegram用户ID白名单
+func ParseOperatorIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的Telegram用户ID: %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// NewBot 创建机器人（operatorIDs 为允许执行指令的Telegram用户ID，同时也是事件推送对象）
+func NewBot(token string, operatorIDs []int64, traderManager *manager.TraderManager) (*Bot, error) {
+	if token == "" {
+		return nil, fmt.Errorf("Telegram Bot Token不能为空")
+	}
+	if len(operatorIDs) == 0 {
+		return nil, fmt.Errorf("未配置Telegram运维人员白名单")
+	}
+	logger.RegisterSecret(token)
+
+	operators := make(map[int64]bool, len(operatorIDs))
+	for _, id := range operatorIDs {
+		operators[id] = true
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bot{
+		client:        newClient(token),
+		operators:     operators,
+		traderManager: traderManager,
+		pending:       make(map[string]*pendingApproval),
+		ctx:           ctx,
+		cancel:        cancel,
+	}, nil
+}
+
+// Start 开始接收指令并推送事件（开启人工确认的交易员同时通过本机器人确认决策）
+func (b *Bot) Start() {
+	b.unsubscribe = notify.Subscribe(b.traderManager.EventBus(), b)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.poll()
+	}()
+	log.Printf("🤖 Telegram机器人已启动（运维人员 %d 人）", len(b.operators))
+}
+
+// Stop 停止机器人
+func (b *Bot) Stop() {
+	b.cancel()
+	b.wg.Wait()
+	if b.unsubscribe != nil {
+		b.unsubscribe()
+	}
+}
+
+// poll 长轮询接收消息，失败时退避重试
+func (b *Bot) poll() {
+	backoff := time.Second
+	for b.ctx.Err() == nil {
+		updates, err := b.client.getUpdates(b.ctx, b.offset)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			log.Printf("⚠️  Telegram获取消息失败: %v（%s后重试）", err, backoff)
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+
+		for _, update := range updates {
+			b.offset = update.UpdateID + 1
+			if update.CallbackQuery != nil {
+				b.handleCallback(update.CallbackQuery)
+			}
+			if update.Message != nil {
+				// 指令可能需要等待决策周期结束，异步执行以免阻塞确认按钮的处理
+				msg := update.Message
+				b.wg.Add(1)
+				go func() {
+					defer b.wg.Done()
+					b.handleMessage(msg)
+				}()
+			}
+		}
+	}
+}
+
+// handleMessage 校验白名单并执行指令
+func (b *Bot) handleMessage(msg *Message) {
+	text := strings.TrimSpace(msg.Text)
+	if msg.From == nil || !strings.HasPrefix(text, "/") {
+		return
+	}
+	if !b.operators[msg.From.ID] {
+		log.Printf("⚠️  拒绝未授权的Telegram指令: 用户 %d (@%s): %s", msg.From.ID, msg.From.Username, text)
+		b.reply(msg.Chat.ID, fmt.Sprintf("未授权（用户ID %d 不在白名单中）", msg.From.ID))
+		return
+	}
+
+	fields := strings.Fields(text)
+	command := strings.ToLower(fields[0])
+	if i := strings.Index(command, "@"); i >= 0 { // 群组中的 /cmd@botname
+		command = command[:i]
+	}
+	args := fields[1:]
+	log.Printf("📱 Telegram指令 (用户 %d): %s", msg.From.ID, text)
+
+	var reply string
+	switch command {
+	case "/start", "/help":
+		reply = helpText
+	case "/status":
+		reply = b.cmdStatus()
+	case "/positions":
+		reply = b.cmdPositions(args)
+	case "/close":
+		reply = b.cmdClose(args, "Telegram:"+operatorName(*msg.From), b.identity(*msg.From))
+	case "/closeall":
+		reply = b.cmdCloseAll(args, "Telegram:"+operatorName(*msg.From), b.identity(*msg.From))
+	case "/panic":
+		reply = b.cmdPanic(args, "Telegram:"+operatorName(*msg.From))
+	case "/reconcile":
+		reply = b.cmdReconcile(args)
+	case "/pending":
+		reply = b.cmdPending(*msg.From)
+	case "/confirm":
+		reply = b.cmdConfirm(args, *msg.From)
+	case "/pause":
+		reply = b.cmdPause(args)
+	case "/resume":
+		reply = b.cmdResume(args)
+	default:
+		reply = "未知指令，发送 /help 查看可用指令"
+	}
+	b.reply(msg.Chat.ID, reply)
+}
+
+const helpText = `可用指令:
+/status - 交易员运行/暂停状态
+/positions [交易员ID] - 当前持仓
+/close 币种 [long|short] [交易员ID] - 市价平仓（默认平掉所有交易员该币种的全部持仓）
+/closeall [币种...] [long|short] [交易员ID...] - 并发平掉全部或指定币种/方向的持仓并清理止盈止损单（默认全部交易员）
+/panic [preview] [交易员ID...] - 紧急按钮：暂停交易、撤销全部挂单、市价平掉全部持仓并呼叫值班（preview 只预演）
+/reconcile [YYYY-MM-DD] [交易员ID...] - 对账交易日的余额变动与记录的交易、资金费、手续费（默认前一交易日）
+/pending - 列出需要你确认的大额人工订单及确认码（两人规则，需关联用户）
+/confirm 确认码 - 确认其他运维人员发起的大额人工订单（两人规则）
+/pause [交易员ID] [时长如30m/2h] - 暂停决策周期及开仓（默认全部交易员，直到 /resume）
+/resume [交易员ID] - 恢复交易`
+
+// reply 发送回复（超长时截断）
+func (b *Bot) reply(chatID int64, text string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := b.send(ctx, chatID, text); err != nil {
+		log.Printf("⚠️  Telegram发送消息失败: %v", err)
+	}
+}
+
+// send 发送消息（超长时截断）
+func (b *Bot) send(ctx context.Context, chatID int64, text string) error {
+	if len(text) > maxMessageLength {
+		text = text[:maxMessageLength] + "\n…"
+	}
+	_, err := b.client.sendMessage(ctx, chatID, text, nil)
+	return err
+}
+
+// Name 实现 notify.Notifier
+func (b *Bot) Name() string {
+	return "telegram"
+}
+
+// Notify 实现 notify.Notifier：推送事件给所有运维人员
+func (b *Bot) Notify(ctx context.Context, event events.Event) error {
+	return b.broadcast(ctx, notify.Text(event))
+}
+
+// broadcast 发送消息给所有运维人员（返回最后一个发送失败的错误）
+func (b *Bot) broadcast(ctx context.Context, text string) error {
+	var lastErr error
+	for id := range b.operators {
+		if err := b.send(ctx, id, text); err != nil {
+			lastErr = fmt.Errorf("发送给 %d 失败: %w", id, err)
+		}
+	}
+	return lastErr
+}
+
+// selectTraders 按ID选择交易员（为空时返回全部，按ID排序）
+func (b *Bot) selectTraders(traderID string) ([]*trader.AutoTrader, error) {
+	if traderID != "" {
+		at, err := b.traderManager.GetTrader(traderID)
+		if err != nil {
+			return nil, err
+		}
+		return []*trader.AutoTrader{at}, nil
+	}
+	all := b.traderManager.GetAllTraders()
+	traders := make([]*trader.AutoTrader, 0, len(all))
+	for _, at := range all {
+		traders = append(traders, at)
+	}
+	sort.Slice(traders, func(i, j int) bool { return traders[i].GetID() < traders[j].GetID() })
+	return traders, nil
+}
+
+// cmdStatus /status
+func (b *Bot) cmdStatus() string {
+	traders, _ := b.selectTraders("")
+	if len(traders) == 0 {
+		return "没有已加载的交易员"
+	}
+	quote := fx.Current()
+	var sb strings.Builder
+	for _, at := range traders {
+		status := at.GetStatus()
+		state := "⏹ 已停止"
+		if running, _ := status["is_running"].(bool); running {
+			state = "▶️ 运行中"
+		}
+		if until := at.PausedUntil(); !until.IsZero() {
+			state = "⏸ 暂停至 " + until.Format("01-02 15:04")
+		}
+		sb.WriteString(fmt.Sprintf("%s (%s) %s", at.GetID(), at.GetName(), state))
+		if account, err := at.GetAccountInfo(); err == nil {
+			equity, _ := account["total_equity"].(float64)
+			pnl, _ := account["total_pnl"].(float64)
+			pnlPct, _ := account["total_pnl_pct"].(float64)
+			sb.WriteString(fmt.Sprintf(" 净值 %s 盈亏 %s (%+.2f%%)", quote.Format(equity), quote.FormatSigned(pnl), pnlPct))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// cmdPositions /positions [交易员ID]
+func (b *Bot) cmdPositions(args []string) string {
+	traderID := ""
+	if len(args) > 0 {
+		traderID = args[0]
+	}
+	traders, err := b.selectTraders(traderID)
+	if err != nil {
+		return err.Error()
+	}
+
+	quote := fx.Current()
+	var sb strings.Builder
+	for _, at := range traders {
+		positions, err := at.GetPositions()
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("%s: %v\n", at.GetID(), err))
+			continue
+		}
+		for _, pos := range positions {
+			pnl, _ := pos["unrealized_pnl"].(float64)
+			sb.WriteString(fmt.Sprintf("%s %s %s 数量 %.4f 开仓 %.4f 标记 %.4f 盈亏 %s (%+.1f%%)\n",
+				at.GetID(), pos["symbol"], pos["side"], pos["quantity"], pos["entry_price"], pos["mark_price"],
+				quote.FormatSigned(pnl), pos["unrealized_pnl_pct"]))
+		}
+	}
+	if sb.Len() == 0 {
+		return "当前无持仓"
+	}
+	return sb.String()
+}
+
+// cmdClose /close 币种 [long|short] [交易员ID]（名义价值达到两人规则阈值的持仓需另一位运维人员确认）
+func (b *Bot) cmdClose(args []string, operator, identity string) string {
+	if len(args) == 0 {
+		return "用法: /close 币种 [long|short] [交易员ID]"
+	}
+	symbol := strings.ToUpper(args[0])
+	if !strings.HasSuffix(symbol, "USDT") {
+		symbol += "USDT"
+	}
+	side, traderID := "", ""
+	for _, arg := range args[1:] {
+		switch strings.ToLower(arg) {
+		case "long", "short":
+			side = strings.ToLower(arg)
+		default:
+			traderID = arg
+		}
+	}
+	traders, err := b.selectTraders(traderID)
+	if err != nil {
+		return err.Error()
+	}
+
+	var results []string
+	closed := make(map[string]bool) // 多个交易员共用账户时同一持仓只平一次
+	for _, at := range traders {
+		positions, err := at.GetPositions()
+		if err != nil {
+			results = append(results, fmt.Sprintf("❌ %s: %v", at.GetID(), err))
+			continue
+		}
+		for _, pos := range positions {
+			posSide, _ := pos["side"].(string)
+			if pos["symbol"] != symbol || (side != "" && posSide != side) {
+				continue
+			}
+			if owner, _ := pos["strategy"].(string); owner != "" && owner != at.GetID() {
+				continue // 由归属的交易员平仓
+			}
+			key := fmt.Sprintf("%s_%s_%s", at.GetExchange(), symbol, posSide)
+			if closed[key] {
+				continue
+			}
+			at, posSide := at, posSide
+			quantity, _ := pos["quantity"].(float64)
+			markPrice, _ := pos["mark_price"].(float64)
+			result, pending, err := b.traderManager.SubmitManualOrder(&manager.ManualOrder{
+				TraderIDs:   []string{at.GetID()},
+				Symbol:      symbol,
+				Action:      "close_" + posSide,
+				NotionalUSD: math.Abs(quantity) * markPrice,
+				Source:      "Telegram",
+				Operator:    operator,
+				Identity:    identity,
+				Execute: func() (string, error) {
+					if _, err := at.ClosePosition(symbol, posSide); err != nil {
+						return "", err
+					}
+					return fmt.Sprintf("✅ %s 已平仓 %s %s", at.GetID(), symbol, posSide), nil
+				},
+			})
+			switch {
+			case err != nil:
+				results = append(results, fmt.Sprintf("❌ %s %s %s: %v", at.GetID(), symbol, posSide, err))
+			case pending:
+				closed[key] = true
+				results = append(results, fmt.Sprintf("🔐 %s %s %s 超过两人规则阈值，已通知其他运维人员确认", at.GetID(), symbol, posSide))
+			default:
+				closed[key] = true
+				results = append(results, result)
+			}
+		}
+	}
+	if len(results) == 0 {
+		return fmt.Sprintf("未找到 %s 的持仓", symbol)
+	}
+	return strings.Join(results, "\n")
+}
+
+// cmdCloseAll /closeall [币种...] [long|short] [交易员ID...]（参数为已加载交易员的ID时视为交易员，否则视为币种）
+func (b *Bot) cmdCloseAll(args []string, operator, identity string) string {
+	var filter trader.CloseFilter
+	var traderIDs []string
+	for _, arg := range args {
+		switch lower := strings.ToLower(arg); {
+		case lower == "long" || lower == "short":
+			filter.Side = lower
+		case b.isTraderID(arg):
+			traderIDs = append(traderIDs, arg)
+		default:
+			filter.Symbols = append(filter.Symbols, arg)
+		}
+	}
+	report, pending, err := b.traderManager.SubmitCloseAll(traderIDs, filter, "Telegram", operator, identity)
+	switch {
+	case err != nil:
+		return "❌ 批量平仓失败: " + err.Error()
+	case pending:
+		return "🔐 平仓名义价值超过两人规则阈值，已通知其他运维人员确认"
+	}
+	return manager.FormatCloseAllReport(report)
+}
+
+// isTraderID 是否为已加载交易员的ID
+func (b *Bot) isTraderID(id string) bool {
+	_, err := b.traderManager.GetTrader(id)
+	return err == nil
+}
+
+// cmdPanic /panic [preview] [交易员ID...]（执行的动作由 panic_actions 配置）
+func (b *Bot) cmdPanic(args []string, operator string) string {
+	preview := false
+	var traderIDs []string
+	for _, arg := range args {
+		if strings.EqualFold(arg, "preview") {
+			preview = true
+		} else {
+			traderIDs = append(traderIDs, arg)
+		}
+	}
+	report, err := b.traderManager.Panic(traderIDs, nil, preview, operator)
+	if err != nil {
+		return "❌ 紧急按钮执行失败: " + err.Error()
+	}
+	text := manager.FormatPanicReport(report)
+	if preview {
+		text += "\n发送 /panic 执行"
+		if len(traderIDs) > 0 {
+			text += " " + strings.Join(traderIDs, " ")
+		}
+	}
+	return text
+}
+
+// cmdReconcile /reconcile [YYYY-MM-DD] [交易员ID...]
+func (b *Bot) cmdReconcile(args []string) string {
+	date := ""
+	if len(args) > 0 && strings.Count(args[0], "-") == 2 && !b.isTraderID(args[0]) {
+		date, args = args[0], args[1:]
+	}
+	day, err := manager.ParseTradingDay(date)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	results, errs, err := b.traderManager.ReconcileBalances(args, day)
+	if err != nil {
+		return "❌ 余额对账失败: " + err.Error()
+	}
+	return manager.FormatBalanceReconciliations(results, errs)
+}
+
+// cmdPause /pause [交易员ID] [时长]
+func (b *Bot) cmdPause(args []string) string {
+	traderID, duration := "", indefinitePause
+	for _, arg := range args {
+		if d, err := time.ParseDuration(arg); err == nil && d > 0 {
+			duration = d
+		} else {
+			traderID = arg
+		}
+	}
+	traders, err := b.selectTraders(traderID)
+	if err != nil {
+		return err.Error()
+	}
+
+	until := time.Now().Add(duration)
+	var ids []string
+	for _, at := range traders {
+		at.Pause(until)
+		ids = append(ids, at.GetID())
+	}
+	if len(ids) == 0 {
+		return "没有已加载的交易员"
+	}
+	if duration == indefinitePause {
+		return fmt.Sprintf("⏸ 已暂停 %s（直到 /resume）", strings.Join(ids, ", "))
+	}
+	return fmt.Sprintf("⏸ 已暂停 %s 至 %s", strings.Join(ids, ", "), until.Format("01-02 15:04"))
+}
+
+// cmdResume /resume [交易员ID]
+func (b *Bot) cmdResume(args []string) string {
+	traderID := ""
+	if len(args) > 0 {
+		traderID = args[0]
+	}
+	traders, err := b.selectTraders(traderID)
+	if err != nil {
+		return err.Error()
+	}
+	var ids []string
+	for _, at := range traders {
+		at.Resume()
+		ids = append(ids, at.GetID())
+	}
+	if len(ids) == 0 {
+		return "没有已加载的交易员"
+	}
+	return fmt.Sprintf("▶️ 已恢复 %s", strings.Join(ids, ", "))
+}
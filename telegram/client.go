@@ -0,0 +1,164 @@
+// Package telegram Telegram机器人：向运维人员推送关键执行事件，接收 /positions、/close、/pause、/resume 等紧急控制指令，并提供交易决策的人工确认
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// apiBase Telegram Bot API 地址
+const apiBase = "https://api.telegram.org"
+
+// pollTimeout getUpdates长轮询超时
+const pollTimeout = 25 * time.Second
+
+// Update Telegram更新（只解析用到的字段）
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query"`
+}
+
+// CallbackQuery 内联按钮回调
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message"`
+	Data    string   `json:"data"`
+}
+
+// InlineKeyboardButton 内联按钮
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// InlineKeyboardMarkup 内联键盘
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// Message Telegram消息
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// User Telegram用户
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// Chat Telegram会话
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// apiResponse Bot API通用响应
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// client Bot API客户端
+type client struct {
+	token string
+	http  *http.Client
+}
+
+func newClient(token string) *client {
+	return &client{
+		token: token,
+		http:  &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+// call 调用Bot API方法
+func (c *client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", apiBase, c.token, method), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求Telegram %s失败: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var apiResp apiResponse
+	if err := json.Unmarshal(data, &apiResp); err != nil {
+		return fmt.Errorf("解析Telegram %s响应失败: %w", method, err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("Telegram %s失败 (HTTP %d): %s", method, resp.StatusCode, apiResp.Description)
+	}
+	if result != nil {
+		return json.Unmarshal(apiResp.Result, result)
+	}
+	return nil
+}
+
+// getUpdates 长轮询获取新消息
+func (c *client) getUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(pollTimeout.Seconds()),
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+// sendMessage 发送纯文本消息（markup为nil时不带按钮）
+func (c *client) sendMessage(ctx context.Context, chatID int64, text string, markup *InlineKeyboardMarkup) (*Message, error) {
+	params := map[string]interface{}{
+		"chat_id":                  strconv.FormatInt(chatID, 10),
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if markup != nil {
+		params["reply_markup"] = markup
+	}
+	var msg Message
+	if err := c.call(ctx, "sendMessage", params, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// editMessageText 修改已发送消息的文本（同时移除按钮）
+func (c *client) editMessageText(ctx context.Context, chatID, messageID int64, text string) error {
+	return c.call(ctx, "editMessageText", map[string]interface{}{
+		"chat_id":    strconv.FormatInt(chatID, 10),
+		"message_id": messageID,
+		"text":       text,
+	}, nil)
+}
+
+// answerCallbackQuery 应答按钮回调（客户端显示提示文本）
+func (c *client) answerCallbackQuery(ctx context.Context, callbackID, text string) error {
+	return c.call(ctx, "answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": callbackID,
+		"text":              text,
+	}, nil)
+}
@@ -0,0 +1,82 @@
+package telegram
+
+import (
+	"fmt"
+	"nofx/manager"
+	"strconv"
+	"strings"
+)
+
+// ParseOperatorUsers 解析Telegram运维人员与系统用户的关联（逗号分隔的 Telegram用户ID=用户ID）
+func ParseOperatorUsers(s string) (map[int64]string, error) {
+	users := make(map[int64]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		idStr, userID, ok := strings.Cut(part, "=")
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if !ok || err != nil || strings.TrimSpace(userID) == "" {
+			return nil, fmt.Errorf("无效的Telegram用户关联: %q（格式: Telegram用户ID=用户ID）", part)
+		}
+		users[id] = strings.TrimSpace(userID)
+	}
+	return users, nil
+}
+
+// SetOperatorUsers 关联Telegram运维人员与系统用户：两人规则按Telegram账号区分发起人和确认人，
+// 确认时校验关联的用户有权操作订单涉及的交易员（owns）。未关联的运维人员不能发起超过阈值的人工订单，也不能确认
+func (b *Bot) SetOperatorUsers(users map[int64]string, owns func(userID, traderID string) bool) {
+	b.operatorUsers, b.ownsTrader = users, owns
+}
+
+// identity Telegram用户的运维人员身份（未关联用户时为空）
+func (b *Bot) identity(from User) string {
+	if b.operatorUsers[from.ID] == "" {
+		return ""
+	}
+	return manager.OperatorIdentity("telegram:" + strconv.FormatInt(from.ID, 10))
+}
+
+// allowedTraders Telegram用户关联的用户有权操作的交易员（未关联时返回nil）
+func (b *Bot) allowedTraders(from User) func(traderID string) bool {
+	userID := b.operatorUsers[from.ID]
+	if userID == "" || b.ownsTrader == nil {
+		return nil
+	}
+	return func(traderID string) bool { return b.ownsTrader(userID, traderID) }
+}
+
+// cmdPending /pending 列出可以由自己确认的大额人工订单及确认码
+func (b *Bot) cmdPending(from User) string {
+	if b.identity(from) == "" {
+		return "❌ 你的Telegram账号未关联用户（telegram_operator_users），不能确认人工订单"
+	}
+	orders := b.traderManager.PendingManualOrders(b.identity(from), b.allowedTraders(from))
+	if len(orders) == 0 {
+		return "没有需要你确认的人工订单"
+	}
+	var sb strings.Builder
+	for _, p := range orders {
+		fmt.Fprintf(&sb, "🔐 [%s] %s 通过%s请求 %s %s（交易员 %v），%s 前有效\n/confirm %s\n",
+			p.Ref, p.Order.Operator, p.Order.Source, p.Order.Symbol, p.Order.Action, p.Order.TraderIDs,
+			p.ExpiresAt.Local().Format("15:04:05"), p.Token)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// cmdConfirm /confirm 确认码
+func (b *Bot) cmdConfirm(args []string, from User) string {
+	if len(args) == 0 {
+		return "用法: /confirm 确认码（通过 /pending 获取）"
+	}
+	order, result, err := b.traderManager.ConfirmManualOrder(args[0], "Telegram:"+operatorName(from), b.identity(from), b.allowedTraders(from))
+	if err != nil {
+		if order != nil {
+			return fmt.Sprintf("❌ %s %s: %v", order.Symbol, order.Action, err)
+		}
+		return "❌ " + err.Error()
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nofx/config"
+	"nofx/trader"
+	"os"
+)
+
+const testnetUsage = `用法:
+  nofx testnet [-user 用户ID] [-exchange 交易所ID] [-min 最低余额] [-db 数据库]
+
+检查已开启测试网的交易所配置：测试网账户能否访问、API Key权限及余额。余额低于 -min 时，
+交易所提供领取API的自动领取测试资金，否则给出网页领取地址。任一交易所检查未通过时返回非0`
+
+// runTestnetCommand 执行 nofx testnet 子命令，全部检查通过时返回0
+func runTestnetCommand(args []string) int {
+	fs := flag.NewFlagSet("testnet", flag.ContinueOnError)
+	userID := fs.String("user", "", "只检查该用户的交易所（默认全部用户）")
+	exchangeID := fs.String("exchange", "", "只检查该交易所（如 hyperliquid、gate）")
+	minBalance := fs.Float64("min", 100, "最低测试资金（USDT），低于时领取测试资金")
+	dbPath := fs.String("db", defaultDBPath(envOrDefault("NOFX_DATA_DIR", ".")), "配置数据库")
+	fs.Usage = func() { fmt.Println(testnetUsage) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	database, err := config.NewDatabase(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 打开配置数据库失败: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	users := []string{*userID}
+	if *userID == "" {
+		if users, err = database.GetAllUsers(); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ 获取用户列表失败: %v\n", err)
+			return 1
+		}
+	}
+
+	checked, failed := 0, 0
+	for _, uid := range users {
+		exchanges, err := database.GetExchanges(uid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ 获取用户 %s 的交易所配置失败: %v\n", uid, err)
+			return 1
+		}
+		for _, ex := range exchanges {
+			if !ex.Testnet || (*exchangeID != "" && ex.ID != *exchangeID) {
+				continue
+			}
+			checked++
+			if !checkTestnetExchange(uid, ex, *minBalance) {
+				failed++
+			}
+		}
+	}
+
+	if checked == 0 {
+		fmt.Println("⚠️  没有开启测试网的交易所配置（请在交易所设置中勾选测试网）")
+		return 1
+	}
+	fmt.Printf("\n📊 检查 %d 个测试网交易所，%d 个未通过\n", checked, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// checkTestnetExchange 检查单个测试网交易所配置，通过时返回true
+func checkTestnetExchange(userID string, ex *config.ExchangeConfig, minBalance float64) bool {
+	fmt.Printf("\n🧪 %s（用户 %s）\n", ex.Name, userID)
+	faucet, ok := trader.TestnetFaucetFor(ex.ID)
+	if !ok {
+		fmt.Printf("   ❌ %s 暂不支持测试网\n", ex.ID)
+		return false
+	}
+
+	var t trader.Trader
+	var err error
+	switch ex.ID {
+	case "hyperliquid":
+		t, err = trader.NewHyperliquidTrader(ex.APIKey, ex.HyperliquidWalletAddr, true) // hyperliquid用APIKey存储private key
+	case "gate":
+		t, err = trader.NewGateTrader(ex.APIKey, ex.SecretKey, true)
+	}
+	if err != nil {
+		fmt.Printf("   ❌ 初始化测试网交易器失败: %v\n", err)
+		return false
+	}
+
+	report := trader.CheckTestnet(t, ex.ID, minBalance)
+	for _, problem := range report.Preflight.Problems {
+		fmt.Printf("   ❌ %s\n", problem)
+	}
+	if report.Err() != nil {
+		return false
+	}
+	fmt.Printf("   ✅ 测试网连接正常（账户查询 %dms）\n", report.Latency.Milliseconds())
+	for _, warning := range report.Preflight.Warnings {
+		fmt.Printf("   ⚠️  %s\n", warning)
+	}
+	fmt.Printf("   💰 钱包余额 %.2f，可用 %.2f\n", report.Equity, report.Available)
+
+	if report.Funded > 0 {
+		fmt.Printf("   🚰 已领取测试资金 %.2f\n", report.Funded)
+	}
+	if report.FundError != "" {
+		fmt.Printf("   ⚠️  领取测试资金失败: %s\n", report.FundError)
+	}
+	if report.NeedsFunds {
+		fmt.Printf("   🚰 余额低于 %.2f，请在 %s 领取测试资金：%s\n", minBalance, faucet.URL, faucet.Note)
+	}
+	return true
+}
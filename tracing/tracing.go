@@ -0,0 +1,85 @@
+// Package tracing 交易链路的OpenTelemetry追踪：策略决策 → 风控检查 → 下单执行 → 交易所API，按阶段拆分延迟并通过OTLP导出
+//
+// 每个决策周期为一条trace（trading_cycle），子span依次为 build_context、strategy_decision、
+// execute（每个决策）及其下的 risk_check、order_executor 和逐个交易所请求（exchange METHOD path）
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName OTLP资源中的服务名
+const ServiceName = "nofx"
+
+// exportTimeout 单次导出的超时
+const exportTimeout = 10 * time.Second
+
+var enabled atomic.Bool
+
+// Config 追踪配置
+type Config struct {
+	Endpoint    string  // OTLP/HTTP地址，如 http://localhost:4318（路径为空时使用 /v1/traces）
+	InstanceID  string  // 实例标识（service.instance.id）
+	SampleRatio float64 // 采样比例（0或1=全部采样）
+}
+
+// Setup 初始化全局TracerProvider并通过OTLP/HTTP导出，返回关闭函数（关闭时导出剩余span）
+// 请求头可通过标准环境变量 OTEL_EXPORTER_OTLP_HEADERS 配置（如认证token）
+func Setup(cfg Config) (func(context.Context) error, error) {
+	u, err := url.Parse(strings.TrimSpace(cfg.Endpoint))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("无效的OTLP地址: %s（格式 http(s)://主机:4318）", cfg.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("无效的追踪采样比例: %g（0-1）", cfg.SampleRatio)
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(u.String()), otlptracehttp.WithTimeout(exportTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("创建OTLP导出器失败: %w", err)
+	}
+	attrs := []attribute.KeyValue{attribute.String("service.name", ServiceName)}
+	if cfg.InstanceID != "" {
+		attrs = append(attrs, attribute.String("service.instance.id", cfg.InstanceID))
+	}
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+	otel.SetTracerProvider(provider)
+	enabled.Store(true)
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// Enabled 是否已启用追踪（未启用时span为空操作，交易所请求不经过追踪传输层）
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Tracer 返回指定模块的Tracer（未启用时为空操作实现）
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
@@ -0,0 +1,139 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 视为与USD 1:1 的稳定币
+var stableCurrencies = map[string]bool{
+	"USD":   true,
+	"USDT":  true,
+	"USDC":  true,
+	"BUSD":  true,
+	"FDUSD": true,
+}
+
+// CurrencyBalance 单个钱包中某币种的余额
+type CurrencyBalance struct {
+	Wallet        string  `json:"wallet"` // "futures" 或 "spot"
+	Currency      string  `json:"currency"`
+	Total         float64 `json:"total"`     // 钱包余额（不含未实现盈亏）
+	Available     float64 `json:"available"` // 可用余额
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Price         float64 `json:"price"` // 1单位币种折合报告币种的价格
+	Value         float64 `json:"value"` // (Total+UnrealizedPnL) 折合报告币种
+}
+
+// AccountSnapshot 多币种账户快照
+type AccountSnapshot struct {
+	Exchange          string            `json:"exchange"`
+	ReportingCurrency string            `json:"reporting_currency"`
+	Balances          []CurrencyBalance `json:"balances"`
+	FuturesValue      float64           `json:"futures_value"` // 合约钱包总值（报告币种）
+	SpotValue         float64           `json:"spot_value"`    // 现货钱包总值（报告币种）
+	TotalValue        float64           `json:"total_value"`
+	Timestamp         time.Time         `json:"timestamp"`
+}
+
+// AccountSnapshotProvider 支持多币种余额查询的交易器（可选接口）
+type AccountSnapshotProvider interface {
+	// GetCurrencyBalances 获取所有结算币种及现货钱包的原始余额
+	GetCurrencyBalances() ([]CurrencyBalance, error)
+}
+
+// GetAccountSnapshot 获取账户快照并折算为报告币种
+// 交易器不支持多币种查询时，退化为GetBalance返回的USDT合约余额
+func GetAccountSnapshot(t Trader, exchange, reportingCurrency string) (*AccountSnapshot, error) {
+	var balances []CurrencyBalance
+	if provider, ok := t.(AccountSnapshotProvider); ok {
+		result, err := provider.GetCurrencyBalances()
+		if err != nil {
+			return nil, fmt.Errorf("获取多币种余额失败: %w", err)
+		}
+		balances = result
+	} else {
+		balance, err := t.GetBalance()
+		if err != nil {
+			return nil, fmt.Errorf("获取余额失败: %w", err)
+		}
+		entry := CurrencyBalance{Wallet: "futures", Currency: "USDT"}
+		entry.Total, _ = balance["totalWalletBalance"].(float64)
+		entry.Available, _ = balance["availableBalance"].(float64)
+		entry.UnrealizedPnL, _ = balance["totalUnrealizedProfit"].(float64)
+		balances = []CurrencyBalance{entry}
+	}
+
+	return BuildAccountSnapshot(exchange, balances, reportingCurrency, func(currency string) (float64, error) {
+		return t.GetMarketPrice(currency + "USDT")
+	})
+}
+
+// BuildAccountSnapshot 使用priceFn（币种 -> USDT价格）将各币种余额折算为报告币种
+func BuildAccountSnapshot(exchange string, balances []CurrencyBalance, reportingCurrency string, priceFn func(currency string) (float64, error)) (*AccountSnapshot, error) {
+	reportingCurrency = strings.ToUpper(reportingCurrency)
+	if reportingCurrency == "" {
+		reportingCurrency = "USDT"
+	}
+
+	prices := make(map[string]float64) // 币种 -> USD价格
+	usdPrice := func(currency string) (float64, error) {
+		if stableCurrencies[currency] {
+			return 1, nil
+		}
+		if price, ok := prices[currency]; ok {
+			return price, nil
+		}
+		price, err := priceFn(currency)
+		if err != nil {
+			return 0, err
+		}
+		if price <= 0 {
+			return 0, fmt.Errorf("%s 价格无效: %v", currency, price)
+		}
+		prices[currency] = price
+		return price, nil
+	}
+
+	reportingPrice, err := usdPrice(reportingCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("获取报告币种 %s 价格失败: %w", reportingCurrency, err)
+	}
+
+	snapshot := &AccountSnapshot{
+		Exchange:          exchange,
+		ReportingCurrency: reportingCurrency,
+		Balances:          make([]CurrencyBalance, 0, len(balances)),
+		Timestamp:         time.Now(),
+	}
+
+	for _, bal := range balances {
+		bal.Currency = strings.ToUpper(bal.Currency)
+		if bal.Total == 0 && bal.Available == 0 && bal.UnrealizedPnL == 0 {
+			continue
+		}
+
+		price, err := usdPrice(bal.Currency)
+		if err != nil {
+			// 无法定价的小币种不影响整体快照
+			log.Printf("⚠️  无法获取 %s 价格，跳过折算: %v", bal.Currency, err)
+			snapshot.Balances = append(snapshot.Balances, bal)
+			continue
+		}
+
+		bal.Price = price / reportingPrice
+		bal.Value = (bal.Total + bal.UnrealizedPnL) * bal.Price
+		snapshot.Balances = append(snapshot.Balances, bal)
+
+		if bal.Wallet == "spot" {
+			snapshot.SpotValue += bal.Value
+		} else {
+			snapshot.FuturesValue += bal.Value
+		}
+	}
+	snapshot.TotalValue = snapshot.FuturesValue + snapshot.SpotValue
+
+	return snapshot, nil
+}
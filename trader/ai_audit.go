@@ -0,0 +1,49 @@
+package trader
+
+import (
+	"encoding/json"
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"strings"
+)
+
+// auditDecision 将本周期的AI调用（prompt、原始响应、解析出的决策及下单结果）写入审计日志
+// 需在 LogDecision 之后调用，以关联决策记录的周期编号
+func (at *AutoTrader) auditDecision(record *logger.DecisionRecord, full *decision.FullDecision, callErr error) {
+	if at.auditLogger == nil || full == nil {
+		return
+	}
+	audit := &logger.AuditRecord{
+		TraderID:     at.id,
+		CycleNumber:  record.CycleNumber,
+		RequestedAt:  full.RequestedAt,
+		RespondedAt:  full.Timestamp,
+		SystemPrompt: full.SystemPrompt,
+		UserPrompt:   full.UserPrompt,
+		RawResponse:  full.RawResponse,
+		Orders:       record.Decisions,
+	}
+	if len(at.ensemble) > 0 {
+		audit.Provider = "ensemble/" + at.ensembleMode
+		audit.Model = strings.Join(at.ensembleNames(), ",")
+	} else if at.mcpClient != nil {
+		audit.Provider = string(at.mcpClient.Provider)
+		audit.Model = at.mcpClient.Model
+	}
+	if len(full.Decisions) > 0 {
+		data, _ := json.Marshal(full.Decisions)
+		audit.DecisionJSON = string(data)
+	}
+	if callErr != nil {
+		audit.Error = callErr.Error()
+	}
+	if err := at.auditLogger.Append(audit); err != nil {
+		log.Printf("⚠ 保存AI审计记录失败: %v", err)
+	}
+}
+
+// GetAuditLogger 获取AI审计日志记录器
+func (at *AutoTrader) GetAuditLogger() *logger.AuditLogger {
+	return at.auditLogger
+}
@@ -0,0 +1,175 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 净额规则：多个策略共用同一账户时，对同一合约反向开仓的处理方式
+const (
+	NettingReject = "reject" // 拒绝与其他策略持仓方向相反的开仓（默认）
+	NettingAllow  = "allow"  // 允许反向开仓（单向持仓模式下会与其他策略的持仓相互抵消）
+)
+
+// NormalizeNettingRule 规范化净额规则，空值使用默认规则，无效值返回错误
+func NormalizeNettingRule(rule string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(rule)) {
+	case "", NettingReject:
+		return NettingReject, nil
+	case NettingAllow:
+		return NettingAllow, nil
+	default:
+		return "", fmt.Errorf("无效的净额规则: %s（可选: reject, allow）", rule)
+	}
+}
+
+// allocationClaim 某个策略在共享账户中占用的持仓
+type allocationClaim struct {
+	traderID string  // 交易员ID，交易员下的辅助策略为 <trader ID>/<策略名>（见 StrategyTrader）
+	margin   float64 // 开仓时占用的保证金（USDT）
+}
+
+// strategyOwner 交易员下辅助策略的持仓归属标识
+func strategyOwner(traderID, strategy string) string {
+	return traderID + "/" + strategy
+}
+
+// ownedBy 归属标识是否属于该交易员（含其下的辅助策略）
+func ownedBy(owner, traderID string) bool {
+	return owner == traderID || strings.HasPrefix(owner, traderID+"/")
+}
+
+// accountAllocations 记录共享账户中每个持仓归属的策略（account -> symbol_side -> claim）
+type accountAllocations struct {
+	mu     sync.Mutex
+	claims map[string]map[string]allocationClaim
+}
+
+// allocations 进程内共享：同一交易账户下的多个trader通过它互相感知
+var allocations = &accountAllocations{claims: make(map[string]map[string]allocationClaim)}
+
+// owner 获取持仓归属的策略
+func (a *accountAllocations) owner(account, posKey string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	claim, ok := a.claims[account][posKey]
+	return claim.traderID, ok
+}
+
+// usedBy 统计策略在账户中已占用的保证金（交易员的占用包含其下辅助策略的持仓）
+func (a *accountAllocations) usedBy(account, traderID string) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	used := 0.0
+	for _, claim := range a.claims[account] {
+		if ownedBy(claim.traderID, traderID) {
+			used += claim.margin
+		}
+	}
+	return used
+}
+
+// marginOf 持仓已占用的保证金（无归属时为0）
+func (a *accountAllocations) marginOf(account, posKey string) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.claims[account][posKey].margin
+}
+
+// claim 记录持仓归属
+func (a *accountAllocations) claim(account, posKey, traderID string, margin float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.claims[account] == nil {
+		a.claims[account] = make(map[string]allocationClaim)
+	}
+	a.claims[account][posKey] = allocationClaim{traderID: traderID, margin: margin}
+}
+
+// release 释放持仓归属（仅释放属于该策略的持仓，交易员可释放其下辅助策略的持仓）
+func (a *accountAllocations) release(account, posKey, traderID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if claim, ok := a.claims[account][posKey]; ok && ownedBy(claim.traderID, traderID) {
+		delete(a.claims[account], posKey)
+	}
+}
+
+// allocationLimit 策略可用的保证金上限（0=不限制，可使用整个账户）
+func (at *AutoTrader) allocationLimit(totalEquity float64) float64 {
+	if at.config.AllocationPct <= 0 || at.config.AllocationPct >= 100 {
+		return 0
+	}
+	return totalEquity * at.config.AllocationPct / 100
+}
+
+// allocationAvailable 策略剩余可用的保证金（不超过账户可用余额）
+func (at *AutoTrader) allocationAvailable(totalEquity, availableBalance float64) float64 {
+	limit := at.allocationLimit(totalEquity)
+	if limit == 0 {
+		return availableBalance
+	}
+	remaining := limit - allocations.usedBy(at.accountKey(), at.id)
+	if remaining < 0 {
+		remaining = 0
+	}
+	if remaining < availableBalance {
+		return remaining
+	}
+	return availableBalance
+}
+
+// checkAllocation 开仓前检查净额规则及策略资金分配上限
+func (at *AutoTrader) checkAllocation(symbol, side string, margin float64) error {
+	return at.checkAllocationFor(at.id, symbol, side, margin)
+}
+
+// checkAllocationFor 以归属标识owner（交易员或其下的辅助策略）检查净额规则及交易员的资金分配上限
+func (at *AutoTrader) checkAllocationFor(owner, symbol, side string, margin float64) error {
+	account := at.accountKey()
+
+	opposite := "short"
+	if side == "short" {
+		opposite = "long"
+	}
+	if other, ok := allocations.owner(account, symbol+"_"+opposite); ok && other != owner && at.config.NettingRule != NettingAllow {
+		return fmt.Errorf("❌ %s 已有其他策略(%s)持有的%s仓，按净额规则拒绝反向开仓", symbol, other, sideName(opposite))
+	}
+
+	if at.config.AllocationPct <= 0 || at.config.AllocationPct >= 100 {
+		return nil
+	}
+
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return fmt.Errorf("获取账户余额失败: %w", err)
+	}
+	wallet, _ := balance["totalWalletBalance"].(float64)
+	unrealized, _ := balance["totalUnrealizedProfit"].(float64)
+	limit := at.allocationLimit(wallet + unrealized)
+	used := allocations.usedBy(account, at.id)
+	if used+margin > limit {
+		return fmt.Errorf("❌ %s 开仓需占用保证金 %.2f USDT，策略已占用 %.2f / %.2f USDT（分配比例 %.1f%%），拒绝开仓",
+			symbol, margin, used, limit, at.config.AllocationPct)
+	}
+	return nil
+}
+
+// checkCloseOwnership 平仓前检查持仓是否属于其他策略（平仓会平掉账户中该方向的全部持仓）
+func (at *AutoTrader) checkCloseOwnership(symbol, side string) error {
+	if owner, ok := allocations.owner(at.accountKey(), symbol+"_"+side); ok && owner != at.id {
+		return fmt.Errorf("❌ %s %s仓属于其他策略(%s)，拒绝平仓", symbol, sideName(side), owner)
+	}
+	return nil
+}
+
+// claimAllocation 记录本策略开仓占用的保证金
+func (at *AutoTrader) claimAllocation(symbol, side string, margin float64) {
+	allocations.claim(at.accountKey(), symbol+"_"+side, at.id, margin)
+}
+
+// releaseAllocation 释放本策略的持仓占用
+func (at *AutoTrader) releaseAllocation(symbol, side string) {
+	allocations.release(at.accountKey(), symbol+"_"+side, at.id)
+}
@@ -0,0 +1,83 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"time"
+)
+
+// defaultApprovalTimeout 未配置时等待人工确认的超时
+const defaultApprovalTimeout = 5 * time.Minute
+
+// ApprovalRequest 待人工确认的交易决策
+type ApprovalRequest struct {
+	TraderID   string
+	TraderName string
+	Source     string // 决策来源（AI决策 / 外部信号）
+	Decision   decision.Decision
+	Timeout    time.Duration
+}
+
+// Approver 人工确认通道（如Telegram机器人）
+type Approver interface {
+	// RequestApproval 发送待确认决策并阻塞等待运维人员答复，ctx到期时返回ctx.Err()
+	RequestApproval(ctx context.Context, req ApprovalRequest) (bool, error)
+}
+
+// SetApprover 设置人工确认通道
+func (at *AutoTrader) SetApprover(approver Approver) {
+	at.approver = approver
+}
+
+// approvalTimeout 等待人工确认的超时
+func (at *AutoTrader) approvalTimeout() time.Duration {
+	if at.config.ApprovalTimeout > 0 {
+		return at.config.ApprovalTimeout
+	}
+	return defaultApprovalTimeout
+}
+
+// awaitApproval 人工确认模式下等待运维人员批准决策（未批准时返回错误，决策不执行）
+// 观望类决策及预演模式无需确认；调用方持有cycleMu，等待期间释放，手动平仓、暂停等指令不会被阻塞，
+// 批准后重新检查暂停状态
+func (at *AutoTrader) awaitApproval(d *decision.Decision, source string) error {
+	if !at.config.RequireApproval || at.config.DryRun || d.Action == "hold" || d.Action == "wait" {
+		return nil
+	}
+	if at.approver == nil {
+		return fmt.Errorf("已开启人工确认但未配置确认通道（Telegram机器人），决策未执行")
+	}
+
+	timeout := at.approvalTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Printf("⏳ [%s] 等待人工确认: %s %s（%s内）", at.name, d.Symbol, d.Action, timeout)
+	at.cycleMu.Unlock()
+	approved, err := at.approver.RequestApproval(ctx, ApprovalRequest{
+		TraderID:   at.id,
+		TraderName: at.name,
+		Source:     source,
+		Decision:   *d,
+		Timeout:    timeout,
+	})
+	at.cycleMu.Lock()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("人工确认超时（%s），决策未执行", timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("人工确认失败: %w", err)
+	}
+	if !approved {
+		return fmt.Errorf("运维人员拒绝执行")
+	}
+	// 等待确认期间可能已暂停或紧急停止交易
+	if until := at.PausedUntil(); !until.IsZero() && (d.Action == "open_long" || d.Action == "open_short") {
+		return fmt.Errorf("等待确认期间交易已暂停（至 %s），决策未执行", until.Format("01-02 15:04"))
+	}
+	log.Printf("✅ [%s] 人工确认通过: %s %s", at.name, d.Symbol, d.Action)
+	return nil
+}
@@ -36,6 +36,19 @@ type AsterTrader struct {
 	// 缓存交易对精度信息
 	symbolPrecision map[string]SymbolPrecision
 	mu              sync.RWMutex
+
+	// 订单归属标识（写入newClientOrderId）
+	orderTagging
+
+	// 最大滑点保护（市价单改为限价穿价的IOC单）
+	slippageLimit
+}
+
+// tagOrder 为下单参数带上策略归属的clientOrderId
+func (t *AsterTrader) tagOrder(params map[string]interface{}, purpose byte) {
+	if id := t.nextClientOrderID(purpose); id != "" {
+		params["newClientOrderId"] = id
+	}
 }
 
 // SymbolPrecision 交易对精度信息
@@ -174,6 +187,11 @@ func (t *AsterTrader) formatPrice(symbol string, price float64) (float64, error)
 	return math.Round(price*multiplier) / multiplier, nil
 }
 
+// RoundPrice 将价格取整到交易对的tick size
+func (t *AsterTrader) RoundPrice(symbol string, price float64) (float64, error) {
+	return t.formatPrice(symbol, price)
+}
+
 // formatQuantity 格式化数量到正确精度和step size
 func (t *AsterTrader) formatQuantity(symbol string, quantity float64) (float64, error) {
 	prec, err := t.getPrecision(symbol)
@@ -574,6 +592,11 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 		"price":        priceStr,
 	}
 
+	if err := t.withSlippageLimit(params, symbol, true, true); err != nil {
+		return nil, err
+	}
+	t.tagOrder(params, OrderPurposeOpen)
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -641,6 +664,11 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 		"price":        priceStr,
 	}
 
+	if err := t.withSlippageLimit(params, symbol, false, true); err != nil {
+		return nil, err
+	}
+	t.tagOrder(params, OrderPurposeOpen)
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -714,8 +742,14 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 		"timeInForce":  "GTC",
 		"quantity":     qtyStr,
 		"price":        priceStr,
+		"reduceOnly":   "true", // 只平仓，数量大于持仓时不会反向开仓
 	}
 
+	if err := t.withSlippageLimit(params, symbol, false, false); err != nil {
+		return nil, err
+	}
+	t.tagOrder(params, OrderPurposeClose)
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -797,7 +831,13 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 		"timeInForce":  "GTC",
 		"quantity":     qtyStr,
 		"price":        priceStr,
+		"reduceOnly":   "true", // 只平仓，数量大于持仓时不会反向开仓
+	}
+
+	if err := t.withSlippageLimit(params, symbol, true, false); err != nil {
+		return nil, err
 	}
+	t.tagOrder(params, OrderPurposeClose)
 
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
@@ -889,6 +929,99 @@ func (t *AsterTrader) GetMarketPrice(symbol string) (float64, error) {
 	return strconv.ParseFloat(priceStr, 64)
 }
 
+// GetContractSpec 获取合约交易规则（由交易对精度信息转换）
+func (t *AsterTrader) GetContractSpec(symbol string) (ContractSpec, error) {
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return ContractSpec{}, err
+	}
+	return ContractSpec{
+		Symbol:            symbol,
+		PricePrecision:    prec.PricePrecision,
+		QuantityPrecision: prec.QuantityPrecision,
+		TickSize:          prec.TickSize,
+		StepSize:          prec.StepSize,
+		Multiplier:        1,
+	}, nil
+}
+
+// withSlippageLimit 开启最大滑点保护时把模拟市价单（最新价±1%的GTC限价单）改为以对手价±N个价格步进为限价的IOC单，超出限价的部分不成交
+func (t *AsterTrader) withSlippageLimit(params map[string]interface{}, symbol string, buy, opening bool) error {
+	price, ok, err := t.limitThroughPrice(t, t.GetContractSpec, symbol, buy, opening)
+	if ok {
+		params["price"] = price
+		params["timeInForce"] = "IOC"
+	}
+	return err
+}
+
+// GetBookQuote 实现 BookQuoteProvider
+func (t *AsterTrader) GetBookQuote(symbol string) (*BookQuote, error) {
+	resp, err := t.client.Get(fmt.Sprintf("%s/fapi/v1/ticker/bookTicker?symbol=%s", t.baseURL, symbol))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		BidPrice string `json:"bidPrice"`
+		BidQty   string `json:"bidQty"`
+		AskPrice string `json:"askPrice"`
+		AskQty   string `json:"askQty"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	quote := &BookQuote{Symbol: symbol}
+	quote.Bid, _ = strconv.ParseFloat(result.BidPrice, 64)
+	quote.BidQty, _ = strconv.ParseFloat(result.BidQty, 64)
+	quote.Ask, _ = strconv.ParseFloat(result.AskPrice, 64)
+	quote.AskQty, _ = strconv.ParseFloat(result.AskQty, 64)
+	if quote.Bid <= 0 || quote.Ask <= 0 {
+		return nil, errors.New("无法获取挂单")
+	}
+	return quote, nil
+}
+
+// GetPriceByType 实现 PriceTypeProvider（标记价格及指数价格来自 premiumIndex 接口）
+func (t *AsterTrader) GetPriceByType(symbol string, priceType PriceType) (float64, error) {
+	if priceType == PriceLast {
+		return t.GetMarketPrice(symbol)
+	}
+	resp, err := t.client.Get(fmt.Sprintf("%s/fapi/v1/premiumIndex?symbol=%s", t.baseURL, symbol))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, err
+	}
+
+	key := "markPrice"
+	if priceType == PriceIndex {
+		key = "indexPrice"
+	}
+	priceStr, ok := result[key].(string)
+	if !ok {
+		return 0, errors.New("无法获取标记价格")
+	}
+
+	return strconv.ParseFloat(priceStr, 64)
+}
+
 // SetStopLoss 设置止损
 func (t *AsterTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
 	side := "SELL"
@@ -924,8 +1057,11 @@ func (t *AsterTrader) SetStopLoss(symbol string, positionSide string, quantity,
 		"stopPrice":    priceStr,
 		"quantity":     qtyStr,
 		"timeInForce":  "GTC",
+		"workingType":  "MARK_PRICE",
 	}
 
+	t.tagOrder(params, OrderPurposeStopLoss)
+
 	_, err = t.request("POST", "/fapi/v3/order", params)
 	return err
 }
@@ -965,8 +1101,11 @@ func (t *AsterTrader) SetTakeProfit(symbol string, positionSide string, quantity
 		"stopPrice":    priceStr,
 		"quantity":     qtyStr,
 		"timeInForce":  "GTC",
+		"workingType":  "MARK_PRICE",
 	}
 
+	t.tagOrder(params, OrderPurposeTakeProfit)
+
 	_, err = t.request("POST", "/fapi/v3/order", params)
 	return err
 }
@@ -989,3 +1128,65 @@ func (t *AsterTrader) FormatQuantity(symbol string, quantity float64) (string, e
 	}
 	return fmt.Sprintf("%v", formatted), nil
 }
+
+// GetCurrencyBalances 获取合约钱包中所有资产余额
+func (t *AsterTrader) GetCurrencyBalances() ([]CurrencyBalance, error) {
+	body, err := t.request("GET", "/fapi/v3/balance", make(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Asset            string `json:"asset"`
+		Balance          string `json:"balance"`
+		AvailableBalance string `json:"availableBalance"`
+		CrossUnPnl       string `json:"crossUnPnl"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	balances := make([]CurrencyBalance, 0, len(result))
+	for _, bal := range result {
+		total, _ := strconv.ParseFloat(bal.Balance, 64)
+		available, _ := strconv.ParseFloat(bal.AvailableBalance, 64)
+		unrealized, _ := strconv.ParseFloat(bal.CrossUnPnl, 64)
+		balances = append(balances, CurrencyBalance{
+			Wallet:        "futures",
+			Currency:      bal.Asset,
+			Total:         total,
+			Available:     available,
+			UnrealizedPnL: unrealized,
+		})
+	}
+	return balances, nil
+}
+
+// GetFeeSchedule 获取账户在该币种上的实际maker/taker费率
+func (t *AsterTrader) GetFeeSchedule(symbol string) (*FeeSchedule, error) {
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	body, err := t.request("GET", "/fapi/v3/commissionRate", params)
+	if err != nil {
+		return nil, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+
+	var result struct {
+		MakerCommissionRate string `json:"makerCommissionRate"`
+		TakerCommissionRate string `json:"takerCommissionRate"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析手续费率失败: %w", err)
+	}
+
+	maker, err := strconv.ParseFloat(result.MakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析maker费率失败: %w", err)
+	}
+	taker, err := strconv.ParseFloat(result.TakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析taker费率失败: %w", err)
+	}
+	return &FeeSchedule{Symbol: symbol, Maker: maker, Taker: taker}, nil
+}
@@ -0,0 +1,70 @@
+package trader
+
+import (
+	"log"
+	"math"
+)
+
+// attributionLookback 恢复持仓归属时回溯的决策周期数
+const attributionLookback = 500
+
+// positionOwner 持仓归属的策略（trader ID），无法确定时返回空字符串
+func (at *AutoTrader) positionOwner(symbol, side string) string {
+	owner, _ := allocations.owner(at.accountKey(), symbol+"_"+side)
+	return owner
+}
+
+// restoreAttribution 根据决策日志中带本策略标识的开仓单，恢复重启前的持仓归属
+func (at *AutoTrader) restoreAttribution() {
+	records, err := at.decisionLogger.GetLatestRecords(attributionLookback)
+	if err != nil || len(records) == 0 {
+		return
+	}
+
+	// 按时间顺序回放开平仓，得到仍未平仓的本策略开仓
+	opened := make(map[string]float64) // symbol_side -> 开仓占用保证金
+	for _, record := range records {
+		for _, action := range record.Decisions {
+			if !action.Success || action.DryRun {
+				continue
+			}
+			switch action.Action {
+			case "open_long", "open_short":
+				tag, ok := ParseOrderTag(action.ClientOrderID)
+				if !ok || tag.Strategy != at.orderTag.Strategy {
+					continue
+				}
+				margin := 0.0
+				if action.Leverage > 0 {
+					margin = action.Quantity * action.Price / float64(action.Leverage)
+				}
+				opened[action.Symbol+"_"+action.Action[len("open_"):]] = margin
+			case "close_long", "close_short":
+				delete(opened, action.Symbol+"_"+action.Action[len("close_"):])
+			}
+		}
+	}
+	if len(opened) == 0 {
+		return
+	}
+
+	positions, err := at.getPositions()
+	if err != nil {
+		log.Printf("⚠️  [%s] 恢复持仓归属失败: %v", at.name, err)
+		return
+	}
+	restored := 0
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		margin, ok := opened[symbol+"_"+side]
+		if !ok || at.positionOwner(symbol, side) != "" {
+			continue
+		}
+		at.claimAllocation(symbol, side, math.Abs(margin))
+		restored++
+	}
+	if restored > 0 {
+		log.Printf("🏷  [%s] 已恢复 %d 个持仓的策略归属 (标识: %s)", at.name, restored, at.orderTag.Strategy)
+	}
+}
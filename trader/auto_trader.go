@@ -4,13 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"nofx/calendar"
 	"nofx/decision"
+	"nofx/events"
 	"nofx/logger"
 	"nofx/market"
 	"nofx/mcp"
 	"nofx/pool"
+	"nofx/replay"
+	"nofx/tracing"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // AutoTraderConfig 自动交易配置（简化版 - AI全权决策）
@@ -21,7 +31,7 @@ type AutoTraderConfig struct {
 	AIModel string // AI模型: "qwen" 或 "deepseek"
 
 	// 交易平台选择
-	Exchange string // "binance", "hyperliquid" 或 "aster"
+	Exchange string // "binance", "hyperliquid", "aster", "gate" 或 "paper"（模拟盘）
 
 	// 币安API配置
 	BinanceAPIKey    string
@@ -69,8 +79,91 @@ type AutoTraderConfig struct {
 	MaxDrawdown     float64       // 最大回撤百分比（提示）
 	StopTradingTime time.Duration // 触发风控后暂停时长
 
+	// 相关性分组敞口限制（如所有山寨L1视为同一分组）
+	CorrelationGroups   map[string]string // symbol -> 分组名
+	MaxGroupExposurePct float64           // 同一分组方向性敞口上限（净值百分比，0=不限制）
+
+	// AI决策硬上限（超出时收紧到上限，见 decision.ApplyGuardrails）
+	AIMaxPositionUSD float64 // 单笔开仓名义价值上限（0=只按净值倍数限制）
+
+	// AI调用成本：当天（UTC）估算成本达到预算后暂停AI决策至次日
+	LLMDailyBudgetUSD float64             // 每日预算（USD，0=不限制）
+	LLMPrices         map[string]LLMPrice // 模型单价覆盖（model -> 单价，"*" 为未知模型的单价）
+
+	// 开仓执行算法（nil=一笔市价单，可选挂单/TWAP/VWAP，按交易员配置由 ParseExecutionPolicy 解析）
+	EntryExecutor Executor
+
+	// 最大滑点保护：市价单改为以对手价±N个价格步进为限价的IOC单（0=普通市价单，交易所需实现 SlippageLimiter）
+	MaxSlippageTicks int
+
+	// 市价开仓前的行情保护（nil=关闭）：最新价偏离标记价格或盘口价差过大时放弃开仓或改为限价单
+	MarketGuard *MarketGuard
+
+	// 交易所请求频率预算（nil=不限制）：同一交易所的交易员共用，限频紧张时撤单 > 平仓 > 开仓 > 数据查询（交易所需实现 RequestScheduled）
+	RequestBudget *RequestBudget
+
+	// 标记价格距强平价格低于该百分比时发送紧急告警（0=关闭）
+	LiquidationAlertPct float64
+
+	// 影子账本REST对账间隔（0=关闭）：本地维护持仓及挂单，由交易所推送实时更新（交易所需实现 UserDataStreamer），各模块读取时不再查询交易所
+	ShadowBookReconcile time.Duration
+
+	// 风险报告（VaR及压力测试）生成间隔（0=关闭）
+	RiskReportInterval time.Duration
+
+	// 回放日志：记录每个决策周期的全部行情输入及AI响应，可用 nofx replay 离线复现
+	ReplayLog bool
+
+	// 故障注入（nil=关闭）：在交易所调用上注入延迟、5xx错误及响应丢失，只用于测试环境验证容错逻辑
+	FaultInjection *FaultConfig
+
 	// 仓位模式
-	IsCrossMargin bool // true=全仓模式, false=逐仓模式
+	IsCrossMargin     bool            // true=全仓模式, false=逐仓模式
+	SymbolMarginModes map[string]bool // 按币种覆盖的仓位模式 (symbol -> 是否全仓)
+
+	// 币种白名单/黑名单及按币种最大持仓名义价值（无论AI或策略如何决策都会强制执行）
+	SymbolWhitelist   map[string]bool // 为空表示不限制
+	SymbolBlacklist   map[string]bool
+	SymbolMaxNotional map[string]float64 // symbol -> USDT上限，"*" 为默认上限
+
+	// 保证金自动补充（从现货钱包划转到合约钱包）
+	MarginTopUpThreshold float64 // 保证金占用率超过该百分比时自动划转（0=关闭）
+	MarginTopUpAmount    float64 // 每次划转的USDT金额
+
+	// 预演模式：计算并记录决策但不实际下单
+	DryRun bool
+
+	// 观察模式：使用只读API Key，只跟踪余额/持仓/盈亏（仪表盘及通知），不请求AI决策、不下任何订单
+	WatchOnly bool
+
+	// 订单clientOrderId/text前缀（空=nx，Hyperliquid的cloid只能是十六进制，不含前缀）
+	OrderTagPrefix string
+
+	// 交易频率限制（防止信号异常时频繁开仓，0=不限制）
+	MaxTradesPerHour          int // 每小时最多开仓次数
+	MaxTradesPerDay           int // 每24小时最多开仓次数
+	MaxEntriesPerSymbolPerDay int // 单币种每24小时最多开仓次数
+
+	// 止损后冷却：该币种止损触发后在此时长内禁止再次开仓（0=关闭）
+	StopLossCooldown time.Duration
+
+	// 资金费结算规避
+	FundingAvoidWindow      time.Duration // 结算前后该时长内禁止开仓/平仓（0=关闭）
+	FundingAdverseThreshold float64       // 持仓需支付的资金费率超过该百分比时在结算前平仓（0=关闭）
+
+	// 跟踪止损：按K线定期收紧交易所止损单
+	TrailingStopMode      string  // "chandelier" / "swing"，空=关闭
+	TrailingInterval      string  // K线周期（空=1h）
+	TrailingLookback      int     // 回看K线数（0=吊灯22/摆动10）
+	TrailingATRMultiplier float64 // 吊灯止损的ATR倍数（0=3）
+
+	// 人工确认：决策发送给运维人员，批准后才执行
+	RequireApproval bool
+	ApprovalTimeout time.Duration // 等待确认的超时（0=5分钟，超时视为拒绝）
+
+	// 多策略共用账户：资金分配及净额规则
+	AllocationPct float64 // 本策略最多占用的账户净值百分比作为保证金（0=不限制）
+	NettingRule   string  // 与其他策略持仓方向相反时的处理: "reject"（默认）或 "allow"
 
 	// 币种配置
 	DefaultCoins []string // 默认币种列表（从数据库获取）
@@ -87,9 +180,14 @@ type AutoTrader struct {
 	aiModel               string // AI模型名称
 	exchange              string // 交易平台名称
 	config                AutoTraderConfig
-	trader                Trader // 使用Trader接口（支持多平台）
+	trader                Trader   // 使用Trader接口（支持多平台）
+	orderTag              OrderTag // 订单归属标识（写入clientOrderId）
 	mcpClient             *mcp.Client
-	decisionLogger        *logger.DecisionLogger // 决策日志记录器
+	ensemble              []decision.EnsembleMember // 多模型投票成员（为空=只使用 mcpClient）
+	ensembleMode          string                    // 投票方式: majority / confidence
+	decisionLogger        *logger.DecisionLogger    // 决策日志记录器
+	auditLogger           *logger.AuditLogger       // AI调用审计日志（prompt/响应/决策/订单哈希链）
+	replayRecorder        *replay.Recorder          // 回放日志（未开启时为nil）
 	initialBalance        float64
 	dailyPnL              float64
 	customPrompt          string   // 自定义交易策略prompt
@@ -100,9 +198,45 @@ type AutoTrader struct {
 	lastResetTime         time.Time
 	stopUntil             time.Time
 	isRunning             bool
-	startTime             time.Time        // 系统启动时间
-	callCount             int              // AI调用次数
-	positionFirstSeenTime map[string]int64 // 持仓首次出现时间 (symbol_side -> timestamp毫秒)
+	startTime             time.Time                   // 系统启动时间
+	callCount             int                         // AI调用次数
+	positionFirstSeenTime map[string]int64            // 持仓首次出现时间 (symbol_side -> timestamp毫秒)
+	feeSchedules          map[string]feeScheduleEntry // 费率缓存 (symbol -> 费率)
+	lastMarginTopUp       time.Time                   // 上次自动补充保证金时间
+	eventBus              *events.Bus                 // 执行事件总线（可选）
+	approver              Approver                    // 人工确认通道（可选）
+	executionRecorder     ExecutionRecorder           // 执行质量记录通道（可选）
+	trackedPositions      map[string]*trackedPosition // 用于推断成交/止盈止损的持仓跟踪 (symbol_side -> 状态)
+	entryHistory          []entryRecord               // 最近24小时的开仓记录（交易频率限制）
+	symbolCooldowns       map[string]time.Time        // 止损后的冷却截止时间 (symbol -> 时间)
+	blackoutReduced       map[string]bool             // 已执行减仓的禁止开仓窗口
+	depegReduced          bool                        // 本次稳定币脱锚已执行减仓
+	liqMismatchWarned     map[string]bool             // 已告警强平价不一致的持仓 (symbol_side)
+	liqRiskAlerted        map[string]bool             // 已发送强平风险告警的持仓 (symbol_side)
+	authFailures          int                         // 连续API认证失败的周期数
+	authAlerted           bool                        // 已发送API认证失败告警
+	tracer                *PipelineTracer             // 交易链路追踪的当前阶段
+	book                  *ShadowBook                 // 影子账本（nil=关闭）
+	riskReportMu          sync.Mutex
+	lastRiskReport        *RiskReport  // 最近一次风险报告
+	cycleMu               sync.Mutex   // 决策周期与外部指令（手动平仓/外部信号）互斥执行
+	haltUntil             atomic.Int64 // 紧急停止截止时间（UnixNano，0=未停止；不经过cycleMu，进行中的决策周期不会延迟紧急按钮）
+	haltMu                sync.Mutex
+	haltHooks             []func() // 紧急停止时执行（停止做市、对冲等辅助策略）
+	candidateMu           sync.RWMutex
+	extraCandidates       map[string]candidateSymbol // 动态加入候选池的币种（新上线合约等）
+	llmUsageMu            sync.Mutex
+	llmUsage              map[string]*LLMDailyUsage // 按交易日的AI调用用量 (YYYY-MM-DD -> 用量)
+	llmUsagePath          string                    // 用量记录文件
+	llmBudgetNotified     string                    // 已发布预算用尽通知的日期
+	preflightMu           sync.Mutex
+	preflightReport       *PreflightReport // 最近一次API Key预检结果
+}
+
+// feeScheduleEntry 费率缓存项
+type feeScheduleEntry struct {
+	schedule  FeeSchedule
+	fetchedAt time.Time
 }
 
 // NewAutoTrader 创建自动交易器
@@ -122,6 +256,12 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		}
 	}
 
+	// 注册密钥，确保任何日志输出中都被屏蔽
+	logger.RegisterSecret(config.BinanceAPIKey, config.BinanceSecretKey,
+		config.HyperliquidPrivateKey, config.AsterPrivateKey,
+		config.GateAPIKey, config.GateAPISecret,
+		config.DeepSeekKey, config.QwenKey, config.CustomAPIKey)
+
 	mcpClient := mcp.New()
 
 	// 初始化AI
@@ -190,17 +330,59 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		if err != nil {
 			return nil, fmt.Errorf("初始化Gate交易器失败: %w", err)
 		}
+	case "paper":
+		log.Printf("🏦 [%s] 使用模拟盘交易（按实时标记价格模拟成交，初始余额 %.2f USDT）", config.Name, config.InitialBalance)
+		trader = NewPaperTrader(config.InitialBalance)
 	default:
 		return nil, fmt.Errorf("不支持的交易平台: %s", config.Exchange)
 	}
 
+	// 订单标记策略归属（clientOrderId），用于把成交、盈亏和持仓归属到本策略
+	orderTag := NewOrderTag(config.ID, time.Now())
+	if err := ValidateOrderTagPrefix(config.OrderTagPrefix); err != nil {
+		log.Printf("⚠️ [%s] %v，使用默认前缀 %s", config.Name, err, orderTagPrefix)
+	} else {
+		orderTag.Prefix = config.OrderTagPrefix
+	}
+	if tagger, ok := trader.(OrderTagger); ok {
+		tagger.SetOrderTag(orderTag)
+	}
+	if config.MaxSlippageTicks > 0 {
+		if limiter, ok := trader.(SlippageLimiter); ok {
+			limiter.SetMaxSlippageTicks(config.MaxSlippageTicks)
+		} else {
+			log.Printf("⚠️ [%s] 交易所 %s 不支持限价穿价单，max_slippage_ticks 不生效", config.Name, config.Exchange)
+		}
+	}
+	if config.RequestBudget != nil {
+		if scheduled, ok := trader.(RequestScheduled); ok {
+			scheduled.SetRequestScheduler(SharedRequestScheduler(config.Exchange, *config.RequestBudget))
+			log.Printf("🚦 [%s] 交易所请求频率预算: %s", config.Name, config.RequestBudget)
+		} else {
+			log.Printf("⚠️ [%s] 交易所 %s 不支持请求调度，request_budget 不生效", config.Name, config.Exchange)
+		}
+	}
+	pipelineTracer := &PipelineTracer{}
+	if tracing.Enabled() {
+		if traced, ok := trader.(ExchangeTraced); ok {
+			traced.SetPipelineTracer(pipelineTracer)
+		}
+	}
+	if config.FaultInjection != nil {
+		log.Printf("🧪 [%s] 已开启故障注入（%s），请勿用于实盘", config.Name, config.FaultInjection)
+		trader = NewFaultyTrader(trader, config.FaultInjection)
+	}
+	if config.MarketGuard != nil {
+		log.Printf("🛡 [%s] 市价开仓行情保护: %s", config.Name, config.MarketGuard)
+	}
+
 	// 验证初始金额配置
 	if config.InitialBalance <= 0 {
 		return nil, fmt.Errorf("初始金额必须大于0，请在配置中设置InitialBalance")
 	}
 
 	// 初始化决策日志记录器（使用trader ID创建独立目录）
-	logDir := fmt.Sprintf("decision_logs/%s", config.ID)
+	logDir := filepath.Join(logger.LogRoot(), config.ID)
 	decisionLogger := logger.NewDecisionLogger(logDir)
 
 	// 设置默认系统提示词模板
@@ -209,15 +391,17 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		systemPromptTemplate = "default" // 默认使用 default 模板
 	}
 
-	return &AutoTrader{
+	at := &AutoTrader{
 		id:                    config.ID,
 		name:                  config.Name,
 		aiModel:               config.AIModel,
 		exchange:              config.Exchange,
 		config:                config,
 		trader:                trader,
+		orderTag:              orderTag,
 		mcpClient:             mcpClient,
 		decisionLogger:        decisionLogger,
+		auditLogger:           logger.NewAuditLogger(logDir),
 		initialBalance:        config.InitialBalance,
 		systemPromptTemplate:  systemPromptTemplate,
 		defaultCoins:          config.DefaultCoins,
@@ -227,20 +411,65 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		callCount:             0,
 		isRunning:             false,
 		positionFirstSeenTime: make(map[string]int64),
-	}, nil
+		trackedPositions:      make(map[string]*trackedPosition),
+		symbolCooldowns:       make(map[string]time.Time),
+		blackoutReduced:       make(map[string]bool),
+		liqMismatchWarned:     make(map[string]bool),
+		liqRiskAlerted:        make(map[string]bool),
+		tracer:                pipelineTracer,
+		feeSchedules:          make(map[string]feeScheduleEntry),
+		extraCandidates:       make(map[string]candidateSymbol),
+		llmUsage:              loadLLMUsage(filepath.Join(logDir, llmUsageFile)),
+		llmUsagePath:          filepath.Join(logDir, llmUsageFile),
+	}
+	if config.ReplayLog {
+		at.replayRecorder = replay.NewRecorder(logDir)
+	}
+	if config.ShadowBookReconcile > 0 && config.Exchange != "paper" {
+		at.book = NewShadowBook()
+	}
+	mcpClient.OnUsage = at.recordLLMUsage
+	return at, nil
 }
 
 // Run 运行自动交易主循环
 func (at *AutoTrader) Run() error {
+	if err := at.CheckPreflight(); err != nil {
+		return err
+	}
 	at.isRunning = true
 	log.Println("🚀 AI驱动自动交易系统启动")
 	log.Printf("💰 初始余额: %.2f USDT", at.initialBalance)
 	log.Printf("⚙️  扫描间隔: %v", at.config.ScanInterval)
 	log.Println("🤖 AI将全权决定杠杆、仓位大小、止损止盈等参数")
+	if at.config.DryRun {
+		log.Println("🧪 预演模式已开启：只记录将要执行的操作，不会发送任何订单")
+	}
+	if at.config.WatchOnly {
+		log.Println("👀 观察模式已开启：只跟踪余额、持仓及盈亏，不请求AI决策、不发送任何订单")
+	}
 
 	ticker := time.NewTicker(at.config.ScanInterval)
 	defer ticker.Stop()
 
+	// 对账上次运行崩溃时未确认结果的订单（按clientOrderId查询交易所）
+	at.RecoverOrderIntents()
+
+	// 影子账本：用户数据流实时更新，定期对账
+	if at.book != nil {
+		shadowBooks.Store(at.trader, at.book)
+		stopBook := at.book.Start(at.trader, at.name, at.config.ShadowBookReconcile)
+		defer func() {
+			stopBook()
+			shadowBooks.Delete(at.trader)
+		}()
+	}
+
+	// 恢复重启前由本策略开仓的持仓归属
+	at.restoreAttribution()
+	at.restoreEntryHistory()
+	at.restoreCooldowns()
+
 	// 首次立即执行
 	if err := at.runCycle(); err != nil {
 		log.Printf("❌ 执行失败: %v", err)
@@ -264,9 +493,43 @@ func (at *AutoTrader) Stop() {
 	log.Println("⏹ 自动交易系统停止")
 }
 
+// Pause 手动暂停决策周期及开仓（与风控暂停共用，已有持仓及交易所止盈止损单不受影响），等待当前周期结束
+// （或周期进入人工确认等待）后生效
+func (at *AutoTrader) Pause(until time.Time) {
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+	at.stopUntil = until
+	log.Printf("⏸ [%s] 手动暂停交易至 %s", at.name, until.Format("2006-01-02 15:04:05"))
+}
+
+// Resume 解除暂停（包括风控触发的暂停）
+func (at *AutoTrader) Resume() {
+	at.haltUntil.Store(0)
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+	at.stopUntil = time.Time{}
+	log.Printf("▶️ [%s] 已恢复交易", at.name)
+}
+
+// PausedUntil 暂停截止时间（未暂停时返回零值）
+func (at *AutoTrader) PausedUntil() time.Time {
+	until := at.stopUntil
+	if halt := at.haltUntil.Load(); halt != 0 && time.Unix(0, halt).After(until) {
+		until = time.Unix(0, halt)
+	}
+	if time.Now().Before(until) {
+		return until
+	}
+	return time.Time{}
+}
+
 // runCycle 运行一个交易周期（使用AI全权决策）
-func (at *AutoTrader) runCycle() error {
+func (at *AutoTrader) runCycle() (err error) {
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
 	at.callCount++
+	span := at.startSpan("trading_cycle", attribute.Int("cycle", at.callCount))
+	defer func() { span.End(err) }()
 
 	log.Print("\n" + strings.Repeat("=", 70))
 	log.Printf("⏰ %s - AI决策周期 #%d", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
@@ -279,8 +542,8 @@ func (at *AutoTrader) runCycle() error {
 	}
 
 	// 1. 检查是否需要停止交易
-	if time.Now().Before(at.stopUntil) {
-		remaining := at.stopUntil.Sub(time.Now())
+	if until := at.PausedUntil(); !until.IsZero() {
+		remaining := time.Until(until)
 		log.Printf("⏸ 风险控制：暂停交易中，剩余 %.0f 分钟", remaining.Minutes())
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("风险控制暂停中，剩余 %.0f 分钟", remaining.Minutes())
@@ -288,15 +551,18 @@ func (at *AutoTrader) runCycle() error {
 		return nil
 	}
 
-	// 2. 重置日盈亏（每天重置）
-	if time.Since(at.lastResetTime) > 24*time.Hour {
+	// 2. 重置日盈亏（进入新的交易日时重置，交易日按配置的时区切换）
+	if !calendar.SameDay(at.lastResetTime, time.Now()) {
 		at.dailyPnL = 0
 		at.lastResetTime = time.Now()
-		log.Println("📅 日盈亏已重置")
+		log.Printf("📅 日盈亏已重置（交易日 %s）", calendar.DayKey(at.lastResetTime))
 	}
 
 	// 3. 收集交易上下文
+	contextSpan := at.startSpan("build_context")
 	ctx, err := at.buildTradingContext()
+	contextSpan.End(err)
+	at.trackAuthFailure(err)
 	if err != nil {
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("构建交易上下文失败: %v", err)
@@ -334,10 +600,72 @@ func (at *AutoTrader) runCycle() error {
 
 	log.Printf("📊 账户净值: %.2f USDT | 可用: %.2f USDT | 持仓: %d",
 		ctx.Account.TotalEquity, ctx.Account.AvailableBalance, ctx.Account.PositionCount)
+	at.publishEvent(events.Event{
+		Type:    events.PnLSnapshot,
+		Equity:  ctx.Account.TotalEquity,
+		PnL:     ctx.Account.TotalPnL,
+		Message: fmt.Sprintf("净值 %.2f USDT | 盈亏 %+.2f (%+.2f%%)", ctx.Account.TotalEquity, ctx.Account.TotalPnL, ctx.Account.TotalPnLPct),
+	})
+
+	// 观察模式：只记录账户及持仓快照
+	if at.config.WatchOnly {
+		record.ExecutionLog = append(record.ExecutionLog, "👀 观察模式：已记录账户及持仓快照，跳过AI决策")
+		at.decisionLogger.LogDecision(record)
+		return nil
+	}
+
+	// 保证金占用过高时从现货钱包自动补充
+	if msg := at.checkMarginTopUp(ctx.Account.MarginUsedPct); msg != "" {
+		record.ExecutionLog = append(record.ExecutionLog, msg)
+	}
+
+	// 重要经济数据公布前按比例减仓
+	if msg := at.reduceForBlackout(ctx.Positions); msg != "" {
+		record.ExecutionLog = append(record.ExecutionLog, msg)
+	}
+
+	// 结算稳定币脱锚时按比例减仓
+	if msg := at.reduceForDepeg(ctx.Positions); msg != "" {
+		record.ExecutionLog = append(record.ExecutionLog, msg)
+	}
+
+	// 资金费不利的持仓在结算前平仓
+	record.ExecutionLog = append(record.ExecutionLog, at.reduceAdverseFunding(ctx.Positions)...)
+
+	// 校验交易所返回的强平价
+	record.ExecutionLog = append(record.ExecutionLog, at.verifyLiquidationPrices(ctx.Positions)...)
+
+	// 接近强平时发送紧急告警
+	record.ExecutionLog = append(record.ExecutionLog, at.checkLiquidationRisk(ctx.Positions)...)
+
+	// 按最新K线收紧跟踪止损
+	record.ExecutionLog = append(record.ExecutionLog, at.updateTrailingStops(ctx.Positions)...)
+	at.snapshotProtectiveLevels(record.Positions)
+
+	// 定期生成VaR及压力测试报告
+	if msg := at.periodicRiskReport(); msg != "" {
+		record.ExecutionLog = append(record.ExecutionLog, msg)
+	}
+
+	// AI调用成本超出每日预算时跳过本周期的AI决策
+	if msg := at.checkLLMBudget(); msg != "" {
+		log.Printf("%s", msg)
+		record.ExecutionLog = append(record.ExecutionLog, msg)
+		record.Success = false
+		record.ErrorMessage = "AI调用成本已达到每日预算"
+		at.decisionLogger.LogDecision(record)
+		return nil
+	}
 
 	// 4. 调用AI获取完整决策
 	log.Printf("🤖 正在请求AI分析并决策... [模板: %s]", at.systemPromptTemplate)
-	decision, err := decision.GetFullDecisionWithCustomPrompt(ctx, at.mcpClient, at.customPrompt, at.overrideBasePrompt, at.systemPromptTemplate)
+	decisionSpan := at.startSpan("strategy_decision", attribute.String("ai.model", at.aiModel))
+	decision, err := at.requestAIDecision(ctx)
+	if decision != nil {
+		decisionSpan.span.SetAttributes(attribute.Int("decision.count", len(decision.Decisions)))
+	}
+	decisionSpan.End(err)
+	at.recordReplayFrame(ctx, decision, err)
 
 	// 即使有错误，也保存思维链、决策和输入prompt（用于debug）
 	if decision != nil {
@@ -348,6 +676,8 @@ func (at *AutoTrader) runCycle() error {
 			decisionJSON, _ := json.MarshalIndent(decision.Decisions, "", "  ")
 			record.DecisionJSON = string(decisionJSON)
 		}
+		record.ExecutionLog = append(record.ExecutionLog, decision.EnsembleLog...)
+		record.ExecutionLog = append(record.ExecutionLog, decision.GuardrailLog...)
 	}
 
 	if err != nil {
@@ -357,35 +687,36 @@ func (at *AutoTrader) runCycle() error {
 		// 打印系统提示词和AI思维链（即使有错误，也要输出以便调试）
 		if decision != nil {
 			if decision.SystemPrompt != "" {
-				log.Printf("\n" + strings.Repeat("=", 70))
+				log.Print("\n" + strings.Repeat("=", 70))
 				log.Printf("📋 系统提示词 [模板: %s] (错误情况)", at.systemPromptTemplate)
 				log.Println(strings.Repeat("=", 70))
 				log.Println(decision.SystemPrompt)
-				log.Printf(strings.Repeat("=", 70) + "\n")
+				log.Print(strings.Repeat("=", 70) + "\n")
 			}
 
 			if decision.CoTTrace != "" {
-				log.Printf("\n" + strings.Repeat("-", 70))
+				log.Print("\n" + strings.Repeat("-", 70))
 				log.Println("💭 AI思维链分析（错误情况）:")
 				log.Println(strings.Repeat("-", 70))
 				log.Println(decision.CoTTrace)
-				log.Printf(strings.Repeat("-", 70) + "\n")
+				log.Print(strings.Repeat("-", 70) + "\n")
 			}
 		}
 
 		at.decisionLogger.LogDecision(record)
+		at.auditDecision(record, decision, err)
 		return fmt.Errorf("获取AI决策失败: %w", err)
 	}
 
 	// // 5. 打印系统提示词
-	// log.Printf("\n" + strings.Repeat("=", 70))
+	// log.Print("\n" + strings.Repeat("=", 70))
 	// log.Printf("📋 系统提示词 [模板: %s]", at.systemPromptTemplate)
 	// log.Println(strings.Repeat("=", 70))
 	// log.Println(decision.SystemPrompt)
 	// log.Printf(strings.Repeat("=", 70) + "\n")
 
 	// 6. 打印AI思维链
-	// log.Printf("\n" + strings.Repeat("-", 70))
+	// log.Print("\n" + strings.Repeat("-", 70))
 	// log.Println("💭 AI思维链分析:")
 	// log.Println(strings.Repeat("-", 70))
 	// log.Println(decision.CoTTrace)
@@ -422,11 +753,29 @@ func (at *AutoTrader) runCycle() error {
 			Timestamp: time.Now(),
 			Success:   false,
 		}
+		if d.Action != "hold" && d.Action != "wait" {
+			actionRecord.Context = tradeContext("AI决策", d.Reasoning, d.Confidence, d.Symbol, ctx.MarketDataMap[d.Symbol])
+		}
 
-		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
+		if err := at.awaitApproval(&d, "AI决策"); err != nil {
+			log.Printf("🙅 %s %s 未执行: %v", d.Symbol, d.Action, err)
+			actionRecord.Error = err.Error()
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("🙅 %s %s 未执行: %v", d.Symbol, d.Action, err))
+		} else if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
 			log.Printf("❌ 执行决策失败 (%s %s): %v", d.Symbol, d.Action, err)
 			actionRecord.Error = err.Error()
+			at.publishEvent(events.Event{
+				Type:    events.Error,
+				Symbol:  d.Symbol,
+				Action:  d.Action,
+				Message: err.Error(),
+			})
 			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s 失败: %v", d.Symbol, d.Action, err))
+		} else if actionRecord.DryRun {
+			actionRecord.Success = true
+			if actionRecord.Preview != "" {
+				record.ExecutionLog = append(record.ExecutionLog, "🧪 "+actionRecord.Preview)
+			}
 		} else {
 			actionRecord.Success = true
 			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s 成功", d.Symbol, d.Action))
@@ -438,9 +787,11 @@ func (at *AutoTrader) runCycle() error {
 	}
 
 	// 9. 保存决策记录
+	snapshotFundingRates(record.Positions, ctx.MarketDataMap)
 	if err := at.decisionLogger.LogDecision(record); err != nil {
 		log.Printf("⚠ 保存决策记录失败: %v", err)
 	}
+	at.auditDecision(record, decision, nil)
 
 	return nil
 }
@@ -472,7 +823,7 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	totalEquity := totalWalletBalance + totalUnrealizedProfit
 
 	// 2. 获取持仓信息
-	positions, err := at.trader.GetPositions()
+	positions, err := at.getPositions()
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -535,6 +886,9 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 		})
 	}
 
+	// 根据持仓变化发布成交/止盈/止损事件
+	at.observePositions(positions)
+
 	// 清理已平仓的持仓记录
 	for key := range at.positionFirstSeenTime {
 		if !currentPositionKeys[key] {
@@ -547,6 +901,7 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	if err != nil {
 		return nil, fmt.Errorf("获取候选币种失败: %w", err)
 	}
+	candidateCoins = at.filterAllowedCandidates(at.withExtraCandidates(candidateCoins))
 
 	// 4. 计算总盈亏
 	totalPnL := totalEquity - at.initialBalance
@@ -570,15 +925,18 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	}
 
 	// 6. 构建上下文
+	now := time.Now()
 	ctx := &decision.Context{
-		CurrentTime:     time.Now().Format("2006-01-02 15:04:05"),
+		CurrentTime:     now.Format("2006-01-02 15:04:05"),
+		Now:             now,
 		RuntimeMinutes:  int(time.Since(at.startTime).Minutes()),
 		CallCount:       at.callCount,
 		BTCETHLeverage:  at.config.BTCETHLeverage,  // 使用配置的杠杆倍数
 		AltcoinLeverage: at.config.AltcoinLeverage, // 使用配置的杠杆倍数
+		MaxPositionUSD:  at.config.AIMaxPositionUSD,
 		Account: decision.AccountInfo{
 			TotalEquity:      totalEquity,
-			AvailableBalance: availableBalance,
+			AvailableBalance: at.allocationAvailable(totalEquity, availableBalance),
 			TotalPnL:         totalPnL,
 			TotalPnLPct:      totalPnLPct,
 			MarginUsed:       totalMarginUsed,
@@ -594,7 +952,27 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 }
 
 // executeDecisionWithRecord 执行AI决策并记录详细信息
-func (at *AutoTrader) executeDecisionWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
+func (at *AutoTrader) executeDecisionWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) (err error) {
+	if decision.Action != "hold" && decision.Action != "wait" {
+		span := at.startSpan("execute", attribute.String("symbol", decision.Symbol), attribute.String("action", decision.Action), attribute.Bool("dry_run", at.config.DryRun))
+		defer func() { span.End(err) }()
+		if !at.config.DryRun {
+			defer at.book.Invalidate() // 持仓及止盈止损单已变化
+		}
+		if err := at.checkWritable(); err != nil {
+			return err
+		}
+	}
+	if at.config.DryRun {
+		return at.previewDecisionWithRecord(decision, actionRecord)
+	}
+
+	// 同一合约的订单操作串行执行（开仓/平仓/止盈止损/撤单）
+	if decision.Action != "hold" && decision.Action != "wait" {
+		unlock := at.lockSymbol(decision.Symbol)
+		defer unlock()
+	}
+
 	switch decision.Action {
 	case "open_long":
 		return at.executeOpenLongWithRecord(decision, actionRecord)
@@ -617,7 +995,7 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 	log.Printf("  📈 开多仓: %s", decision.Symbol)
 
 	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
-	positions, err := at.trader.GetPositions()
+	positions, err := at.getPositions()
 	if err == nil {
 		for _, pos := range positions {
 			if pos["symbol"] == decision.Symbol && pos["side"] == "long" {
@@ -626,42 +1004,52 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 		}
 	}
 
-	// 获取当前价格
-	marketData, err := market.Get(decision.Symbol)
+	// 风控检查（币种过滤、频率/冷却、交易成本、止损位置、资金分配及敞口）
+	marketData, quantity, err := at.checkOpenRisk(decision, "long", positions, actionRecord)
 	if err != nil {
 		return err
 	}
-
-	// 计算数量
-	quantity := decision.PositionSizeUSD / marketData.CurrentPrice
-	actionRecord.Quantity = quantity
-	actionRecord.Price = marketData.CurrentPrice
+	margin := decision.PositionSizeUSD / float64(decision.Leverage)
 
 	// 设置仓位模式
-	if err := at.trader.SetMarginMode(decision.Symbol, at.config.IsCrossMargin); err != nil {
+	if err := at.trader.SetMarginMode(decision.Symbol, at.isCrossMarginFor(decision.Symbol)); err != nil {
 		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
 		// 继续执行，不影响交易
 	}
 
 	// 开仓
-	order, err := at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
+	order, err := at.openPosition(decision.Symbol, "long", quantity, decision.Leverage)
 	if err != nil {
 		return err
 	}
+	if filled, ok := order["filledQty"].(float64); ok && filled > 0 {
+		quantity = filled
+		actionRecord.Quantity = filled
+	}
 
 	// 记录订单ID
 	if orderID, ok := order["orderId"].(int64); ok {
 		actionRecord.OrderID = orderID
 	}
+	if clientOrderID, ok := order["clientOrderId"].(string); ok {
+		actionRecord.ClientOrderID = clientOrderID
+	}
 
 	log.Printf("  ✓ 开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
 
+	// 百分比/R倍数形式的止损止盈按实际成交价换算
+	at.resolveFillLevels(decision, "long", marketData.CurrentPrice)
+	actionRecord.StopLoss, actionRecord.TakeProfit = decision.StopLoss, decision.TakeProfit
+
 	// 记录开仓时间
 	posKey := decision.Symbol + "_long"
 	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
+	at.claimAllocation(decision.Symbol, "long", margin)
+	at.recordEntry(decision.Symbol, time.Now())
+	at.trackOpenOrder(decision.Symbol, "long", actionRecord.OrderID, actionRecord.ClientOrderID, quantity, marketData.CurrentPrice, decision.StopLoss, decision.TakeProfit)
 
 	// 设置止损止盈
-	if err := at.trader.SetStopLoss(decision.Symbol, "LONG", quantity, decision.StopLoss); err != nil {
+	if err := at.setStopLoss(decision.Symbol, "long", quantity, decision.StopLoss); err != nil {
 		log.Printf("  ⚠ 设置止损失败: %v", err)
 	}
 	if err := at.trader.SetTakeProfit(decision.Symbol, "LONG", quantity, decision.TakeProfit); err != nil {
@@ -676,7 +1064,7 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 	log.Printf("  📉 开空仓: %s", decision.Symbol)
 
 	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
-	positions, err := at.trader.GetPositions()
+	positions, err := at.getPositions()
 	if err == nil {
 		for _, pos := range positions {
 			if pos["symbol"] == decision.Symbol && pos["side"] == "short" {
@@ -685,42 +1073,52 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 		}
 	}
 
-	// 获取当前价格
-	marketData, err := market.Get(decision.Symbol)
+	// 风控检查（币种过滤、频率/冷却、交易成本、止损位置、资金分配及敞口）
+	marketData, quantity, err := at.checkOpenRisk(decision, "short", positions, actionRecord)
 	if err != nil {
 		return err
 	}
-
-	// 计算数量
-	quantity := decision.PositionSizeUSD / marketData.CurrentPrice
-	actionRecord.Quantity = quantity
-	actionRecord.Price = marketData.CurrentPrice
+	margin := decision.PositionSizeUSD / float64(decision.Leverage)
 
 	// 设置仓位模式
-	if err := at.trader.SetMarginMode(decision.Symbol, at.config.IsCrossMargin); err != nil {
+	if err := at.trader.SetMarginMode(decision.Symbol, at.isCrossMarginFor(decision.Symbol)); err != nil {
 		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
 		// 继续执行，不影响交易
 	}
 
 	// 开仓
-	order, err := at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+	order, err := at.openPosition(decision.Symbol, "short", quantity, decision.Leverage)
 	if err != nil {
 		return err
 	}
+	if filled, ok := order["filledQty"].(float64); ok && filled > 0 {
+		quantity = filled
+		actionRecord.Quantity = filled
+	}
 
 	// 记录订单ID
 	if orderID, ok := order["orderId"].(int64); ok {
 		actionRecord.OrderID = orderID
 	}
+	if clientOrderID, ok := order["clientOrderId"].(string); ok {
+		actionRecord.ClientOrderID = clientOrderID
+	}
 
 	log.Printf("  ✓ 开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
 
+	// 百分比/R倍数形式的止损止盈按实际成交价换算
+	at.resolveFillLevels(decision, "short", marketData.CurrentPrice)
+	actionRecord.StopLoss, actionRecord.TakeProfit = decision.StopLoss, decision.TakeProfit
+
 	// 记录开仓时间
 	posKey := decision.Symbol + "_short"
 	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
+	at.claimAllocation(decision.Symbol, "short", margin)
+	at.recordEntry(decision.Symbol, time.Now())
+	at.trackOpenOrder(decision.Symbol, "short", actionRecord.OrderID, actionRecord.ClientOrderID, quantity, marketData.CurrentPrice, decision.StopLoss, decision.TakeProfit)
 
 	// 设置止损止盈
-	if err := at.trader.SetStopLoss(decision.Symbol, "SHORT", quantity, decision.StopLoss); err != nil {
+	if err := at.setStopLoss(decision.Symbol, "short", quantity, decision.StopLoss); err != nil {
 		log.Printf("  ⚠ 设置止损失败: %v", err)
 	}
 	if err := at.trader.SetTakeProfit(decision.Symbol, "SHORT", quantity, decision.TakeProfit); err != nil {
@@ -730,19 +1128,146 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 	return nil
 }
 
+// checkOpenRisk 开仓前的风控检查，通过时返回当前行情及开仓数量
+func (at *AutoTrader) checkOpenRisk(decision *decision.Decision, side string, positions []map[string]interface{}, actionRecord *logger.DecisionAction) (marketData *market.Data, quantity float64, err error) {
+	span := at.startSpan("risk_check", attribute.String("symbol", decision.Symbol), attribute.String("side", side))
+	defer func() { span.End(err) }()
+
+	// 决策周期进行中触发了紧急按钮
+	if err := at.checkHalted(); err != nil {
+		return nil, 0, err
+	}
+
+	// 新上线等动态加入的币种按其安全限制下调杠杆和仓位
+	at.applyCandidateLimits(decision)
+
+	// 币种白名单/黑名单、交易频率限制、止损冷却、禁止开仓窗口、稳定币脱锚、交易所维护、合约下架及资金费结算时间
+	if err := at.checkSymbolFilter(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkTradeThrottle(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkCooldown(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkBlackout(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkDepeg(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkMaintenance(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkDelisting(decision.Symbol); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkFundingWindow(decision.Symbol, "开仓"); err != nil {
+		return nil, 0, err
+	}
+
+	// 获取当前价格
+	marketData, err = market.Get(decision.Symbol)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 计算数量
+	quantity = decision.PositionSizeUSD / marketData.CurrentPrice
+	actionRecord.Quantity = quantity
+	actionRecord.Price = marketData.CurrentPrice
+	actionRecord.FeeRate = at.getFeeSchedule(decision.Symbol).Taker
+
+	// 预估交易成本，成本不低于预期收益时拒绝开仓
+	if err := at.checkExpectedCost(decision, side, quantity, marketData); err != nil {
+		return nil, 0, err
+	}
+
+	// 止损必须在预估强平价之前触发
+	if err := at.checkStopBeforeLiquidation(decision, side, marketData.CurrentPrice, quantity); err != nil {
+		return nil, 0, err
+	}
+
+	// 止损止盈按标记价格触发，不能已经越过标记价格
+	if err := at.checkStopAgainstMark(decision, side, marketData.CurrentPrice); err != nil {
+		return nil, 0, err
+	}
+
+	// 检查净额规则及策略资金分配上限
+	margin := decision.PositionSizeUSD / float64(decision.Leverage)
+	if err := at.checkAllocation(decision.Symbol, side, margin); err != nil {
+		return nil, 0, err
+	}
+
+	// 检查相关性分组的方向性敞口及该币种的名义价值上限
+	if err := at.checkGroupExposure(decision.Symbol, side, decision.PositionSizeUSD, positions); err != nil {
+		return nil, 0, err
+	}
+	if err := at.checkSymbolNotional(decision.Symbol, decision.PositionSizeUSD, positions); err != nil {
+		return nil, 0, err
+	}
+	return marketData, quantity, nil
+}
+
+// getFeeSchedule 获取币种费率（缓存1小时）
+func (at *AutoTrader) getFeeSchedule(symbol string) FeeSchedule {
+	if entry, ok := at.feeSchedules[symbol]; ok && time.Since(entry.fetchedAt) < time.Hour {
+		return entry.schedule
+	}
+
+	schedule := GetFeeSchedule(at.trader, at.exchange, symbol)
+	at.feeSchedules[symbol] = feeScheduleEntry{schedule: schedule, fetchedAt: time.Now()}
+	log.Printf("  💸 %s 费率: maker=%.4f%% taker=%.4f%% (来源: %s)",
+		symbol, schedule.Maker*100, schedule.Taker*100, schedule.Source)
+	return schedule
+}
+
+// checkExpectedCost 对比预估交易成本（手续费+资金费+价差）与止盈预期收益
+func (at *AutoTrader) checkExpectedCost(decision *decision.Decision, side string, quantity float64, marketData *market.Data) error {
+	estimate, err := EstimateFees(FeeOrder{
+		Symbol:      decision.Symbol,
+		Side:        side,
+		Quantity:    quantity,
+		Price:       marketData.CurrentPrice,
+		FundingRate: marketData.FundingRate,
+	}, at.getFeeSchedule(decision.Symbol))
+	if err != nil {
+		return fmt.Errorf("预估交易成本失败: %w", err)
+	}
+
+	expectedEdge := math.Abs(decision.TakeProfit-marketData.CurrentPrice) * quantity
+	log.Printf("  💸 预估成本: %.4f USDT (手续费 %.4f + 资金费 %.4f + 价差 %.4f) | 预期收益: %.4f USDT",
+		estimate.TotalCost, estimate.EntryFee+estimate.ExitFee, estimate.FundingCost, estimate.SpreadCost, expectedEdge)
+
+	if decision.TakeProfit > 0 && estimate.TotalCost >= expectedEdge {
+		return fmt.Errorf("❌ %s 预估交易成本 %.4f USDT 不低于预期收益 %.4f USDT，拒绝开仓",
+			decision.Symbol, estimate.TotalCost, expectedEdge)
+	}
+	return nil
+}
+
 // executeCloseLongWithRecord 执行平多仓并记录详细信息
 func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
 	log.Printf("  🔄 平多仓: %s", decision.Symbol)
 
+	if err := at.checkCloseOwnership(decision.Symbol, "long"); err != nil {
+		return err
+	}
+	if err := at.checkFundingWindow(decision.Symbol, "平仓"); err != nil {
+		return err
+	}
+
 	// 获取当前价格
 	marketData, err := market.Get(decision.Symbol)
 	if err != nil {
 		return err
 	}
 	actionRecord.Price = marketData.CurrentPrice
+	actionRecord.FeeRate = at.getFeeSchedule(decision.Symbol).Taker
 
 	// 平仓
-	order, err := at.trader.CloseLong(decision.Symbol, 0) // 0 = 全部平仓
+	order, err := PlaceOrder(at.trader, decision.Symbol, "close_long", 0, 0) // 0 = 全部平仓
 	if err != nil {
 		return err
 	}
@@ -751,8 +1276,13 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, ac
 	if orderID, ok := order["orderId"].(int64); ok {
 		actionRecord.OrderID = orderID
 	}
+	if clientOrderID, ok := order["clientOrderId"].(string); ok {
+		actionRecord.ClientOrderID = clientOrderID
+	}
 
 	log.Printf("  ✓ 平仓成功")
+	at.trackCloseOrder(decision.Symbol, "long", actionRecord.OrderID, actionRecord.ClientOrderID, marketData.CurrentPrice)
+	at.releaseAllocation(decision.Symbol, "long")
 	return nil
 }
 
@@ -760,15 +1290,23 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, ac
 func (at *AutoTrader) executeCloseShortWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
 	log.Printf("  🔄 平空仓: %s", decision.Symbol)
 
+	if err := at.checkCloseOwnership(decision.Symbol, "short"); err != nil {
+		return err
+	}
+	if err := at.checkFundingWindow(decision.Symbol, "平仓"); err != nil {
+		return err
+	}
+
 	// 获取当前价格
 	marketData, err := market.Get(decision.Symbol)
 	if err != nil {
 		return err
 	}
 	actionRecord.Price = marketData.CurrentPrice
+	actionRecord.FeeRate = at.getFeeSchedule(decision.Symbol).Taker
 
 	// 平仓
-	order, err := at.trader.CloseShort(decision.Symbol, 0) // 0 = 全部平仓
+	order, err := PlaceOrder(at.trader, decision.Symbol, "close_short", 0, 0) // 0 = 全部平仓
 	if err != nil {
 		return err
 	}
@@ -777,8 +1315,13 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *decision.Decision, a
 	if orderID, ok := order["orderId"].(int64); ok {
 		actionRecord.OrderID = orderID
 	}
+	if clientOrderID, ok := order["clientOrderId"].(string); ok {
+		actionRecord.ClientOrderID = clientOrderID
+	}
 
 	log.Printf("  ✓ 平仓成功")
+	at.trackCloseOrder(decision.Symbol, "short", actionRecord.OrderID, actionRecord.ClientOrderID, marketData.CurrentPrice)
+	at.releaseAllocation(decision.Symbol, "short")
 	return nil
 }
 
@@ -827,6 +1370,16 @@ func (at *AutoTrader) GetDecisionLogger() *logger.DecisionLogger {
 	return at.decisionLogger
 }
 
+// GetAccountKey 获取交易账户标识（共享同一账户的交易员相同，含密钥信息，不要输出到日志）
+func (at *AutoTrader) GetAccountKey() string {
+	return at.accountKey()
+}
+
+// GetExchangeTrader 获取底层交易器（供Delta对冲等直接管理交易所仓位的模块使用）
+func (at *AutoTrader) GetExchangeTrader() Trader {
+	return at.trader
+}
+
 // GetStatus 获取系统状态（用于API）
 func (at *AutoTrader) GetStatus() map[string]interface{} {
 	aiProvider := "DeepSeek"
@@ -845,10 +1398,105 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 		"call_count":      at.callCount,
 		"initial_balance": at.initialBalance,
 		"scan_interval":   at.config.ScanInterval.String(),
-		"stop_until":      at.stopUntil.Format(time.RFC3339),
+		"stop_until":      at.PausedUntil().Format(time.RFC3339),
 		"last_reset_time": at.lastResetTime.Format(time.RFC3339),
+		"next_reset_time": calendar.NextDayStart(time.Now()).Format(time.RFC3339),
 		"ai_provider":     aiProvider,
+		"dry_run":         at.config.DryRun,
+		"watch_only":      at.config.WatchOnly,
+		"allocation_pct":  at.config.AllocationPct,
+		"netting_rule":    at.config.NettingRule,
+		"order_tag":       at.orderTag.Strategy,
+		"trade_limits": map[string]int{
+			"max_trades_per_hour":            at.config.MaxTradesPerHour,
+			"max_trades_per_day":             at.config.MaxTradesPerDay,
+			"max_entries_per_symbol_per_day": at.config.MaxEntriesPerSymbolPerDay,
+		},
+		"stop_loss_cooldown": at.config.StopLossCooldown.String(),
+		"funding_guard": map[string]interface{}{
+			"avoid_window":      at.config.FundingAvoidWindow.String(),
+			"adverse_threshold": at.config.FundingAdverseThreshold,
+		},
+		"trailing_stop": at.trailingStatus(),
+		"approval": map[string]interface{}{
+			"required": at.config.RequireApproval,
+			"timeout":  at.approvalTimeout().String(),
+		},
+		"llm_usage": at.llmStatus(),
+	}
+}
+
+// TransferMargin 在现货与合约钱包之间划转资金
+func (at *AutoTrader) TransferMargin(from, to, currency string, amount float64) error {
+	if err := at.checkWritable(); err != nil {
+		return err
+	}
+	transferer, ok := at.trader.(MarginTransferer)
+	if !ok {
+		return fmt.Errorf("交易平台 %s 不支持钱包划转", at.exchange)
+	}
+	return transferer.TransferMargin(from, to, currency, amount)
+}
+
+// ClosePosition 手动市价全部平仓（供外部API调用，与AI平仓决策走同一执行路径）
+func (at *AutoTrader) ClosePosition(symbol, side string) (*logger.DecisionAction, error) {
+	if side != "long" && side != "short" {
+		return nil, fmt.Errorf("无效的持仓方向: %s", side)
+	}
+	d := &decision.Decision{Symbol: symbol, Action: "close_" + side, Reasoning: "手动平仓"}
+	record := &logger.DecisionAction{
+		Action:    d.Action,
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+		Context:   tradeContext("手动平仓", d.Reasoning, 0, symbol, nil),
+	}
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+	if err := at.executeDecisionWithRecord(d, record); err != nil {
+		return nil, fmt.Errorf("平仓失败: %w", err)
+	}
+	record.Success = true
+	log.Printf("✋ [%s] 手动平仓 %s %s", at.name, symbol, side)
+	if err := at.decisionLogger.LogDecision(&logger.DecisionRecord{
+		CycleNumber:  at.callCount,
+		CoTTrace:     "手动平仓",
+		Decisions:    []logger.DecisionAction{*record},
+		ExecutionLog: []string{fmt.Sprintf("✋ 手动平仓 %s %s", symbol, sideName(side))},
+		Success:      true,
+	}); err != nil {
+		log.Printf("⚠ 保存决策记录失败: %v", err)
+	}
+	return record, nil
+}
+
+// checkMarginTopUp 保证金占用率超过阈值时从现货划转USDT到合约钱包（每30分钟最多一次）
+func (at *AutoTrader) checkMarginTopUp(marginUsedPct float64) string {
+	if at.config.MarginTopUpThreshold <= 0 || at.config.MarginTopUpAmount <= 0 {
+		return ""
+	}
+	if marginUsedPct < at.config.MarginTopUpThreshold || time.Since(at.lastMarginTopUp) < 30*time.Minute {
+		return ""
+	}
+
+	at.lastMarginTopUp = time.Now()
+	if at.config.DryRun {
+		msg := fmt.Sprintf("WOULD transfer %.2f USDT spot -> futures (保证金占用率 %.1f%%)",
+			at.config.MarginTopUpAmount, marginUsedPct)
+		log.Printf("🧪 [预演] %s", msg)
+		return "🧪 " + msg
+	}
+	log.Printf("⚠️  保证金占用率 %.1f%% 超过阈值 %.1f%%，从现货划转 %.2f USDT",
+		marginUsedPct, at.config.MarginTopUpThreshold, at.config.MarginTopUpAmount)
+	if err := at.TransferMargin(WalletSpot, WalletFutures, "USDT", at.config.MarginTopUpAmount); err != nil {
+		log.Printf("❌ 自动补充保证金失败: %v", err)
+		return fmt.Sprintf("❌ 自动补充保证金失败: %v", err)
 	}
+	return fmt.Sprintf("✓ 自动补充保证金 %.2f USDT", at.config.MarginTopUpAmount)
+}
+
+// GetAccountSnapshot 获取多币种账户快照（折算为报告币种，用于API）
+func (at *AutoTrader) GetAccountSnapshot(reportingCurrency string) (*AccountSnapshot, error) {
+	return GetAccountSnapshot(at.trader, at.exchange, reportingCurrency)
 }
 
 // GetAccountInfo 获取账户信息（用于API）
@@ -877,13 +1525,15 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	totalEquity := totalWalletBalance + totalUnrealizedProfit
 
 	// 获取持仓计算总保证金
-	positions, err := at.trader.GetPositions()
+	positions, err := at.getPositions()
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
 
 	totalMarginUsed := 0.0
 	totalUnrealizedPnL := 0.0
+	strategyUnrealizedPnL := 0.0 // 归属本策略的持仓未实现盈亏
+	strategyPositionCount := 0
 	for _, pos := range positions {
 		markPrice := pos["markPrice"].(float64)
 		quantity := pos["positionAmt"].(float64)
@@ -892,6 +1542,10 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		}
 		unrealizedPnl := pos["unRealizedProfit"].(float64)
 		totalUnrealizedPnL += unrealizedPnl
+		if at.positionOwner(pos["symbol"].(string), pos["side"].(string)) == at.id {
+			strategyUnrealizedPnL += unrealizedPnl
+			strategyPositionCount++
+		}
 
 		leverage := 10
 		if lev, ok := pos["leverage"].(float64); ok {
@@ -930,12 +1584,16 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		"position_count":  len(positions),  // 持仓数量
 		"margin_used":     totalMarginUsed, // 保证金占用
 		"margin_used_pct": marginUsedPct,   // 保证金使用率
+
+		// 策略归属（共用账户时仅统计本策略开仓的持仓）
+		"strategy_unrealized_pnl": strategyUnrealizedPnL,
+		"strategy_position_count": strategyPositionCount,
 	}, nil
 }
 
 // GetPositions 获取持仓列表（用于API）
 func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
-	positions, err := at.trader.GetPositions()
+	positions, err := at.getPositions()
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -979,6 +1637,7 @@ func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
 			"unrealized_pnl_pct": pnlPct,
 			"liquidation_price":  liquidationPrice,
 			"margin_used":        marginUsed,
+			"strategy":           at.positionOwner(symbol, side),
 		})
 	}
 
@@ -1024,7 +1683,8 @@ func sortDecisionsByPriority(decisions []decision.Decision) []decision.Decision
 
 // getCandidateCoins 获取交易员的候选币种列表
 func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
-	if len(at.tradingCoins) == 0 {
+	tradingCoins := at.getTradingCoins()
+	if len(tradingCoins) == 0 {
 		// 使用数据库配置的默认币种列表
 		var candidateCoins []decision.CandidateCoin
 
@@ -1065,7 +1725,7 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 	} else {
 		// 使用自定义币种列表
 		var candidateCoins []decision.CandidateCoin
-		for _, coin := range at.tradingCoins {
+		for _, coin := range tradingCoins {
 			// 确保币种格式正确（转为大写USDT交易对）
 			symbol := normalizeSymbol(coin)
 			candidateCoins = append(candidateCoins, decision.CandidateCoin{
@@ -1075,7 +1735,7 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 		}
 
 		log.Printf("📋 [%s] 使用自定义币种: %d个币种 %v",
-			at.name, len(candidateCoins), at.tradingCoins)
+			at.name, len(candidateCoins), tradingCoins)
 		return candidateCoins, nil
 	}
 }
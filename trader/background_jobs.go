@@ -0,0 +1,35 @@
+package trader
+
+import (
+	"log"
+	"nofx/jobs"
+	"sync"
+)
+
+var (
+	jobQueueMu sync.RWMutex
+	jobQueue   *jobs.Queue // 慢操作（交易规则刷新、风险报告）的后台任务队列，未设置时由调用方同步执行
+)
+
+// SetJobQueue 设置后台任务队列（nil=关闭，慢操作在交易周期内同步执行）
+func SetJobQueue(q *jobs.Queue) {
+	jobQueueMu.Lock()
+	jobQueue = q
+	jobQueueMu.Unlock()
+}
+
+// runInBackground 提交后台任务（同key任务排队或执行中时不重复提交），
+// 未设置队列或提交失败时返回false，由调用方同步执行
+func runInBackground(jobType, key string, fn jobs.Func) bool {
+	jobQueueMu.RLock()
+	q := jobQueue
+	jobQueueMu.RUnlock()
+	if q == nil {
+		return false
+	}
+	if _, err := q.Submit(jobs.Job{Type: jobType, Key: key}, fn); err != nil {
+		log.Printf("⚠ 提交后台任务 %s 失败，同步执行: %v", key, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/calendar"
+	"nofx/logger"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 资金费来源
+const (
+	FundingFromExchange = "exchange"  // 交易所资金流水中的实际结算
+	FundingEstimated    = "estimated" // 按决策日志记录的资金费率估算（交易所不支持查询资金流水）
+)
+
+// DefaultReconcileTolerance 未配置时余额对账允许的差额
+var DefaultReconcileTolerance = ReconcileTolerance{USD: 1, Pct: 0.5}
+
+// ReconcileTolerance 余额对账允许的差额（取绝对金额与期初钱包余额比例中的较大者）
+type ReconcileTolerance struct {
+	USD float64 `json:"usd"`
+	Pct float64 `json:"pct"`
+}
+
+// ParseReconcileTolerance 解析余额对账容差，如 5（USDT）、0.5%、5,0.5%；空字符串返回nil（关闭每日对账）
+func ParseReconcileTolerance(spec string) (*ReconcileTolerance, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	t := &ReconcileTolerance{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pct := strings.HasSuffix(item, "%")
+		value, err := strconv.ParseFloat(strings.TrimSuffix(item, "%"), 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("无效的余额对账容差: %s（如 5、0.5%%、5,0.5%%）", item)
+		}
+		if pct {
+			t.Pct = value
+		} else {
+			t.USD = value
+		}
+	}
+	return t, nil
+}
+
+// Limit 期初钱包余额为wallet时允许的差额
+func (t ReconcileTolerance) Limit(wallet float64) float64 {
+	return math.Max(t.USD, math.Abs(wallet)*t.Pct/100)
+}
+
+// String 容差摘要
+func (t ReconcileTolerance) String() string {
+	return fmt.Sprintf("%.2f USDT / %.2f%%", t.USD, t.Pct)
+}
+
+// UntrackedIncome 交易所有成交盈亏或手续费、但决策日志中没有交易的币种（疑似账外手动交易）
+type UntrackedIncome struct {
+	Symbol      string  `json:"symbol"`
+	RealizedPnL float64 `json:"realized_pnl"`
+	Commission  float64 `json:"commission"`
+}
+
+// BalanceReconciliation 一个交易日内钱包余额变动与记录的交易、资金费、手续费的对账结果（金额为USDT）
+// 钱包余额=净值-未实现盈亏，区间为当天第一条至最后一条带余额的决策记录，交易所流水按同一区间查询
+type BalanceReconciliation struct {
+	TraderID         string            `json:"trader_id"`
+	Peers            []string          `json:"peers,omitempty"` // 共用账户、合并了决策日志的其他交易员
+	Exchange         string            `json:"exchange"`
+	Day              string            `json:"day"` // 交易日（按配置的时区切换）
+	Since            time.Time         `json:"since"`
+	Until            time.Time         `json:"until"`
+	StartWallet      float64           `json:"start_wallet"`
+	EndWallet        float64           `json:"end_wallet"`
+	Change           float64           `json:"change"`            // 钱包余额变动
+	TradePnL         float64           `json:"trade_pnl"`         // 决策日志中的平仓价格盈亏
+	Fees             float64           `json:"fees"`              // 决策日志中的手续费
+	Funding          float64           `json:"funding"`           // 资金费（正数=收取）
+	FundingSource    string            `json:"funding_source"`    // exchange / estimated
+	EstimatedFunding float64           `json:"estimated_funding"` // 按记录的费率估算的资金费（与实际结算对比）
+	Transfers        float64           `json:"transfers"`         // 划转、充提（交易所流水）
+	OtherIncome      float64           `json:"other_income"`      // 返佣、赠金等其他流水
+	Expected         float64           `json:"expected"`          // 交易盈亏-手续费+资金费+划转+其他
+	Unexplained      float64           `json:"unexplained"`       // 余额变动-预期变动
+	Tolerance        float64           `json:"tolerance"`
+	Mismatch         bool              `json:"mismatch"`
+	Untracked        []UntrackedIncome `json:"untracked,omitempty"`
+	Notes            []string          `json:"notes,omitempty"`
+}
+
+// IsDryRun 是否为模拟交易（模拟成交不影响账户余额，不参与余额对账）
+func (at *AutoTrader) IsDryRun() bool {
+	return at.config.DryRun
+}
+
+// recordWallet 决策记录中的钱包余额（净值减去持仓快照的未实现盈亏）
+func recordWallet(record *logger.DecisionRecord) float64 {
+	wallet := record.AccountState.TotalBalance
+	for _, pos := range record.Positions {
+		wallet -= pos.UnrealizedProfit
+	}
+	return wallet
+}
+
+// ReconcileBalance 对账day所在交易日的钱包余额变动：余额变动应等于记录的交易盈亏-手续费+资金费+划转，
+// 差额超过容差时 Mismatch=true（如在交易所上手动交易、未记录的强平）。共用账户时只需由其中一个交易员对账，
+// peers为共用同一账户的其他交易员：钱包余额取自本交易员的记录，交易、资金费及手续费合并全部交易员的决策日志
+func (at *AutoTrader) ReconcileBalance(day time.Time, tolerance ReconcileTolerance, peers ...*AutoTrader) (*BalanceReconciliation, error) {
+	if at.config.DryRun {
+		return nil, fmt.Errorf("模拟交易不参与余额对账")
+	}
+	start := calendar.DayStart(day)
+	end := start.AddDate(0, 0, 1)
+	records, err := at.decisionLogger.GetLatestRecords(leaderboardMaxRecords)
+	if err != nil {
+		return nil, fmt.Errorf("读取决策记录失败: %w", err)
+	}
+	var first, last *logger.DecisionRecord
+	for _, record := range records {
+		if record.AccountState.TotalBalance <= 0 || record.Timestamp.Before(start) || !record.Timestamp.Before(end) {
+			continue
+		}
+		if first == nil || record.Timestamp.Before(first.Timestamp) {
+			first = record
+		}
+		if last == nil || record.Timestamp.After(last.Timestamp) {
+			last = record
+		}
+	}
+	if first == nil || !last.Timestamp.After(first.Timestamp) {
+		return nil, fmt.Errorf("%s 的余额记录不足，无法对账", calendar.DayKey(start))
+	}
+
+	r := &BalanceReconciliation{
+		TraderID:      at.id,
+		Exchange:      at.exchange,
+		Day:           calendar.DayKey(start),
+		Since:         first.Timestamp,
+		Until:         last.Timestamp,
+		StartWallet:   recordWallet(first),
+		EndWallet:     recordWallet(last),
+		FundingSource: FundingEstimated,
+	}
+	r.Change = r.EndWallet - r.StartWallet
+
+	lifecycles := logger.ReplayRecords(records)
+	journal := logger.SummarizeRealizedBetween(lifecycles, r.Since, r.Until)
+	r.TradePnL = journal.PricePnL
+	r.Fees = journal.Fees
+	r.EstimatedFunding = journal.FundingPnL
+	for _, peer := range peers {
+		peerRecords, err := peer.decisionLogger.GetLatestRecords(leaderboardMaxRecords)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 的决策记录失败: %w", peer.id, err)
+		}
+		peerLifecycles := logger.ReplayRecords(peerRecords)
+		peerJournal := logger.SummarizeRealizedBetween(peerLifecycles, r.Since, r.Until)
+		r.TradePnL += peerJournal.PricePnL
+		r.Fees += peerJournal.Fees
+		r.EstimatedFunding += peerJournal.FundingPnL
+		lifecycles = append(lifecycles, peerLifecycles...)
+		r.Peers = append(r.Peers, peer.id)
+	}
+	r.Funding = r.EstimatedFunding
+
+	if provider, ok := at.trader.(IncomeHistoryProvider); ok {
+		incomes, err := provider.GetIncomeHistory(r.Since, r.Until)
+		if err != nil {
+			log.Printf("⚠️ [%s] %v，资金费按记录的费率估算", at.name, err)
+			r.Notes = append(r.Notes, err.Error())
+		} else {
+			at.applyIncome(r, incomes, lifecycles)
+		}
+	}
+
+	r.Expected = r.TradePnL - r.Fees + r.Funding + r.Transfers + r.OtherIncome
+	r.Unexplained = r.Change - r.Expected
+	r.Tolerance = tolerance.Limit(r.StartWallet)
+	r.Mismatch = math.Abs(r.Unexplained) > r.Tolerance
+	return r, nil
+}
+
+// applyIncome 用交易所资金流水替换估算的资金费，计入划转等账外变动，并找出日志中没有交易记录的币种
+func (at *AutoTrader) applyIncome(r *BalanceReconciliation, incomes []IncomeRecord, lifecycles []*logger.PositionLifecycle) {
+	journaled := make(map[string]bool)
+	bySymbol := make(map[string][]*logger.PositionLifecycle)
+	for _, lc := range lifecycles {
+		bySymbol[lc.Symbol] = append(bySymbol[lc.Symbol], lc)
+	}
+	for symbol, lcs := range bySymbol {
+		journaled[symbol] = logger.SummarizeRealizedBetween(lcs, r.Since, r.Until).Volume > 0
+	}
+
+	r.Funding = 0
+	r.FundingSource = FundingFromExchange
+	prices := map[string]float64{}
+	untracked := make(map[string]*UntrackedIncome)
+	for _, income := range incomes {
+		amount := income.Amount
+		if asset := strings.ToUpper(income.Asset); asset != "" && !stableCurrencies[asset] {
+			price, ok := prices[asset]
+			if !ok {
+				var err error
+				if price, err = at.trader.GetMarketPrice(asset + "USDT"); err != nil {
+					r.Notes = append(r.Notes, fmt.Sprintf("无法折算 %s 流水: %v", asset, err))
+				}
+				prices[asset] = price
+			}
+			amount *= price
+		}
+		switch income.Type {
+		case IncomeFunding:
+			r.Funding += amount
+		case IncomeTransfer:
+			r.Transfers += amount
+		case IncomeOther:
+			r.OtherIncome += amount
+		case IncomeRealizedPnL, IncomeCommission:
+			if income.Symbol == "" || journaled[income.Symbol] {
+				continue
+			}
+			u := untracked[income.Symbol]
+			if u == nil {
+				u = &UntrackedIncome{Symbol: income.Symbol}
+				untracked[income.Symbol] = u
+			}
+			if income.Type == IncomeRealizedPnL {
+				u.RealizedPnL += amount
+			} else {
+				u.Commission += amount
+			}
+		}
+	}
+	for _, u := range untracked {
+		r.Untracked = append(r.Untracked, *u)
+	}
+	sort.Slice(r.Untracked, func(i, j int) bool { return r.Untracked[i].Symbol < r.Untracked[j].Symbol })
+}
+
+// FormatBalanceReconciliation 余额对账结果的文本形式（用于告警）
+func FormatBalanceReconciliation(r *BalanceReconciliation) string {
+	var b strings.Builder
+	icon := "✅"
+	if r.Mismatch {
+		icon = "⚠️"
+	}
+	fmt.Fprintf(&b, "%s [%s] %s 余额对账：钱包 %.2f → %.2f（%+.2f），预期 %+.2f，差额 %+.2f（容差 %.2f）",
+		icon, r.TraderID, r.Day, r.StartWallet, r.EndWallet, r.Change, r.Expected, r.Unexplained, r.Tolerance)
+	fundingSource := "实际结算"
+	if r.FundingSource == FundingEstimated {
+		fundingSource = "估算"
+	}
+	fmt.Fprintf(&b, "\n交易盈亏 %+.2f | 手续费 -%.2f | 资金费 %+.2f（%s） | 划转 %+.2f | 其他 %+.2f",
+		r.TradePnL, r.Fees, r.Funding, fundingSource, r.Transfers, r.OtherIncome)
+	for _, u := range r.Untracked {
+		fmt.Fprintf(&b, "\n• %s 有交易所成交但日志中没有交易（疑似手动交易）：盈亏 %+.2f 手续费 %+.2f", u.Symbol, u.RealizedPnL, u.Commission)
+	}
+	if len(r.Peers) > 0 {
+		fmt.Fprintf(&b, "\n• 共用账户，已合并 %s 的交易记录", strings.Join(r.Peers, ", "))
+	}
+	for _, note := range r.Notes {
+		b.WriteString("\n• " + note)
+	}
+	return b.String()
+}
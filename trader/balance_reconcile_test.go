@@ -0,0 +1,105 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type incomeStub struct {
+	*stubTrader
+	incomes []IncomeRecord
+}
+
+func (s *incomeStub) GetIncomeHistory(start, end time.Time) ([]IncomeRecord, error) {
+	return s.incomes, nil
+}
+
+// writeBalanceRecord 直接写入一条带余额的决策记录（LogDecision 会把时间戳改为当前时间）
+func writeBalanceRecord(t *testing.T, dir string, ts time.Time, equity float64, positions ...logger.PositionSnapshot) {
+	t.Helper()
+	record := logger.DecisionRecord{Timestamp: ts, AccountState: logger.AccountSnapshot{TotalBalance: equity}, Positions: positions, Success: true}
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("decision_%s.json", ts.UTC().Format("20060102_150405"))
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconcileBalance(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	writeBalanceRecord(t, dir, day.Add(-time.Hour), 900) // 前一交易日，不参与对账
+	writeBalanceRecord(t, dir, day.Add(time.Hour), 1000)
+	writeBalanceRecord(t, dir, day.Add(6*time.Hour), 1030, logger.PositionSnapshot{Symbol: "ETHUSDT", Side: "long", PositionAmt: 1, EntryPrice: 2000, MarkPrice: 2010, UnrealizedProfit: 10})
+	writeBalanceRecord(t, dir, day.Add(23*time.Hour), 1070)
+
+	stub := &incomeStub{stubTrader: newStubTrader(), incomes: []IncomeRecord{
+		{Symbol: "ETHUSDT", Type: IncomeFunding, Asset: "USDT", Amount: -1},
+		{Symbol: "BTCUSDT", Type: IncomeRealizedPnL, Asset: "USDT", Amount: 52},
+		{Symbol: "BTCUSDT", Type: IncomeCommission, Asset: "USDT", Amount: -2},
+	}}
+	at := &AutoTrader{id: "t1", name: "t1", trader: stub, decisionLogger: logger.NewDecisionLogger(dir)}
+
+	r, err := at.ReconcileBalance(day.Add(12*time.Hour), ReconcileTolerance{USD: 5})
+	if err != nil {
+		t.Fatalf("ReconcileBalance: %v", err)
+	}
+	if r.Day != "2026-03-01" || r.StartWallet != 1000 || r.EndWallet != 1070 || r.FundingSource != FundingFromExchange || r.Funding != -1 {
+		t.Fatalf("unexpected reconciliation: %+v", r)
+	}
+	// ETH 持仓的平仓盈亏在日志中按标记价估算，BTC 的成交只出现在交易所流水中
+	if want := r.Change - (r.TradePnL - r.Fees + r.Funding); math.Abs(r.Unexplained-want) > 1e-9 || !r.Mismatch {
+		t.Fatalf("unexplained = %v (want %v), mismatch = %v", r.Unexplained, want, r.Mismatch)
+	}
+	if len(r.Untracked) != 1 || r.Untracked[0].Symbol != "BTCUSDT" || r.Untracked[0].RealizedPnL != 52 || r.Untracked[0].Commission != -2 {
+		t.Fatalf("untracked = %+v", r.Untracked)
+	}
+
+	// 划转解释了余额变动
+	stub.incomes = []IncomeRecord{{Type: IncomeTransfer, Asset: "USDT", Amount: r.Change - r.TradePnL + r.Fees}}
+	if r, err = at.ReconcileBalance(day, ReconcileTolerance{USD: 5}); err != nil || r.Mismatch || math.Abs(r.Unexplained) > 1e-9 {
+		t.Fatalf("transfer not reconciled: %+v, %v", r, err)
+	}
+
+	if _, err := at.ReconcileBalance(day.AddDate(0, 0, 1), DefaultReconcileTolerance); err == nil {
+		t.Fatal("expected error for a day without balance records")
+	}
+
+	// 共用账户的其他交易员的交易合并对账
+	peerDir := t.TempDir()
+	writeBalanceRecord(t, peerDir, day.Add(2*time.Hour), 1000, logger.PositionSnapshot{Symbol: "SOLUSDT", Side: "long", PositionAmt: 10, EntryPrice: 150, MarkPrice: 152, UnrealizedProfit: 20})
+	writeBalanceRecord(t, peerDir, day.Add(20*time.Hour), 1050)
+	peer := &AutoTrader{id: "t2", name: "t2", trader: stub, decisionLogger: logger.NewDecisionLogger(peerDir)}
+	merged, err := at.ReconcileBalance(day, ReconcileTolerance{USD: 5}, peer)
+	if err != nil {
+		t.Fatalf("ReconcileBalance with peer: %v", err)
+	}
+	if len(merged.Peers) != 1 || merged.Peers[0] != "t2" || merged.TradePnL <= r.TradePnL {
+		t.Fatalf("peer journal not merged: peers=%v trade_pnl=%v (alone %v)", merged.Peers, merged.TradePnL, r.TradePnL)
+	}
+}
+
+func TestParseReconcileTolerance(t *testing.T) {
+	tol, err := ParseReconcileTolerance("5, 0.5%")
+	if err != nil || tol == nil || tol.USD != 5 || tol.Pct != 0.5 {
+		t.Fatalf("tolerance = %+v, %v", tol, err)
+	}
+	if got := tol.Limit(10000); got != 50 {
+		t.Fatalf("Limit(10000) = %v", got)
+	}
+	if tol, _ := ParseReconcileTolerance(""); tol != nil {
+		t.Fatal("empty spec should disable reconciliation")
+	}
+	if _, err := ParseReconcileTolerance("abc"); err == nil {
+		t.Fatal("invalid spec accepted")
+	}
+}
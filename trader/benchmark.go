@@ -0,0 +1,193 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/market"
+	"sort"
+	"time"
+)
+
+const (
+	benchmarkMaxPoints  = 500  // 曲线最多返回的点数
+	benchmarkMaxKlines  = 1500 // 单次请求的K线上限
+	benchmarkBTCSymbol  = "BTCUSDT"
+	benchmarkHourlySpan = (benchmarkMaxKlines - 10) * time.Hour // 超过该跨度时改用日线
+)
+
+// BenchmarkPoint 基准对比曲线上的一个点（均为相对起点的收益率%）
+type BenchmarkPoint struct {
+	Time     time.Time `json:"time"`
+	Strategy float64   `json:"strategy"`
+	HODL     float64   `json:"hodl"`
+	BTC      float64   `json:"btc"`
+}
+
+// BenchmarkReport 策略净值与买入持有（交易过的币种等权、BTC）的对比
+type BenchmarkReport struct {
+	TraderID               string           `json:"trader_id"`
+	Start                  time.Time        `json:"start"`
+	End                    time.Time        `json:"end"`
+	Symbols                []string         `json:"symbols"` // HODL组合的币种（等权）
+	StrategyReturnPct      float64          `json:"strategy_return_pct"`
+	HODLReturnPct          float64          `json:"hodl_return_pct"`
+	BTCReturnPct           float64          `json:"btc_return_pct"`
+	ExcessVsHODLPct        float64          `json:"excess_vs_hodl_pct"` // 策略收益 - HODL收益
+	ExcessVsBTCPct         float64          `json:"excess_vs_btc_pct"`
+	StrategyMaxDrawdownPct float64          `json:"strategy_max_drawdown_pct"`
+	HODLMaxDrawdownPct     float64          `json:"hodl_max_drawdown_pct"`
+	BTCMaxDrawdownPct      float64          `json:"btc_max_drawdown_pct"`
+	Points                 []BenchmarkPoint `json:"points"`
+}
+
+// GetBenchmarkReport 对比since之后的策略净值曲线与买入持有交易过的币种及BTC（since为零值时使用全部记录）
+func (at *AutoTrader) GetBenchmarkReport(since time.Time) (*BenchmarkReport, error) {
+	records, err := at.decisionLogger.GetLatestRecords(leaderboardMaxRecords)
+	if err != nil {
+		return nil, fmt.Errorf("读取决策记录失败: %w", err)
+	}
+
+	type equityPoint struct {
+		time   time.Time
+		equity float64
+	}
+	var curve []equityPoint
+	traded := make(map[string]bool)
+	for _, record := range records {
+		if record.Timestamp.Before(since) {
+			continue
+		}
+		if record.AccountState.TotalBalance > 0 {
+			curve = append(curve, equityPoint{record.Timestamp, record.AccountState.TotalBalance})
+		}
+		for _, action := range record.Decisions {
+			if action.Success && !action.DryRun && (action.Action == "open_long" || action.Action == "open_short") {
+				traded[action.Symbol] = true
+			}
+		}
+	}
+	if len(curve) < 2 {
+		return nil, fmt.Errorf("净值记录不足，无法生成基准对比")
+	}
+
+	symbols := make([]string, 0, len(traded))
+	for symbol := range traded {
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		coins := at.getTradingCoins()
+		if len(coins) == 0 {
+			coins = at.defaultCoins
+		}
+		for _, coin := range coins {
+			symbols = append(symbols, normalizeSymbol(coin))
+		}
+	}
+	sort.Strings(symbols)
+
+	start, end := curve[0].time, curve[len(curve)-1].time
+	interval, step := "1h", time.Hour
+	if end.Sub(start) > benchmarkHourlySpan {
+		interval, step = "1d", 24*time.Hour
+	}
+	limit := int(time.Since(start)/step) + 2
+	if limit > benchmarkMaxKlines {
+		limit = benchmarkMaxKlines
+	}
+
+	prices := make(map[string][]market.Kline)
+	for _, symbol := range append([]string{benchmarkBTCSymbol}, symbols...) {
+		if _, ok := prices[symbol]; ok {
+			continue
+		}
+		klines, err := market.GetKlines(symbol, interval, limit)
+		if err != nil {
+			return nil, err
+		}
+		prices[symbol] = klines
+	}
+
+	// 等权组合：各币种相对起点的涨跌幅取平均（不重新平衡）
+	hodlReturn := func(t time.Time) float64 {
+		var sum float64
+		var n int
+		for _, symbol := range symbols {
+			p0, p := priceAt(prices[symbol], start), priceAt(prices[symbol], t)
+			if p0 > 0 && p > 0 {
+				sum += (p/p0 - 1) * 100
+				n++
+			}
+		}
+		if n == 0 {
+			return 0
+		}
+		return sum / float64(n)
+	}
+	btc0 := priceAt(prices[benchmarkBTCSymbol], start)
+
+	report := &BenchmarkReport{
+		TraderID: at.id,
+		Start:    start,
+		End:      end,
+		Symbols:  symbols,
+	}
+	var drawdowns [3]drawdownTracker
+	sampleEvery := int(math.Ceil(float64(len(curve)) / benchmarkMaxPoints))
+	for i, p := range curve {
+		point := BenchmarkPoint{
+			Time:     p.time,
+			Strategy: (p.equity/curve[0].equity - 1) * 100,
+			HODL:     hodlReturn(p.time),
+		}
+		if btc := priceAt(prices[benchmarkBTCSymbol], p.time); btc0 > 0 && btc > 0 {
+			point.BTC = (btc/btc0 - 1) * 100
+		}
+		drawdowns[0].add(point.Strategy)
+		drawdowns[1].add(point.HODL)
+		drawdowns[2].add(point.BTC)
+		if i%sampleEvery == 0 || i == len(curve)-1 {
+			report.Points = append(report.Points, point)
+		}
+	}
+
+	last := report.Points[len(report.Points)-1]
+	report.StrategyReturnPct = last.Strategy
+	report.HODLReturnPct = last.HODL
+	report.BTCReturnPct = last.BTC
+	report.ExcessVsHODLPct = last.Strategy - last.HODL
+	report.ExcessVsBTCPct = last.Strategy - last.BTC
+	report.StrategyMaxDrawdownPct = drawdowns[0].max
+	report.HODLMaxDrawdownPct = drawdowns[1].max
+	report.BTCMaxDrawdownPct = drawdowns[2].max
+	return report, nil
+}
+
+// priceAt 返回t时刻所在K线的收盘价（t早于第一根K线时使用其开盘价）
+func priceAt(klines []market.Kline, t time.Time) float64 {
+	if len(klines) == 0 {
+		return 0
+	}
+	ms := t.UnixMilli()
+	i := sort.Search(len(klines), func(i int) bool { return klines[i].OpenTime > ms })
+	if i == 0 {
+		return klines[0].Open
+	}
+	return klines[i-1].Close
+}
+
+// drawdownTracker 按收益率序列计算最大回撤（%）
+type drawdownTracker struct {
+	peak    float64 // 最高净值（起点为1）
+	max     float64
+	started bool
+}
+
+func (d *drawdownTracker) add(returnPct float64) {
+	value := 1 + returnPct/100
+	if !d.started || value > d.peak {
+		d.peak, d.started = value, true
+	}
+	if d.peak > 0 {
+		d.max = math.Max(d.max, (d.peak-value)/d.peak*100)
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"nofx/logger"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
 // FuturesTrader 币安合约交易器
 type FuturesTrader struct {
-	client *futures.Client
+	client     *futures.Client
+	spotClient *binance.Client // 现货账户（多币种余额查询）
 
 	// 余额缓存
 	cachedBalance     map[string]interface{}
@@ -27,6 +30,19 @@ type FuturesTrader struct {
 
 	// 缓存有效期（15秒）
 	cacheDuration time.Duration
+
+	// 持仓模式（nil=尚未检测，true=双向持仓，false=单向持仓）
+	dualSidePosition  *bool
+	positionModeMutex sync.Mutex
+
+	// 合约交易规则缓存（数量/价格精度），所有币安交易器共用
+	specs *SpecRegistry
+
+	// 订单归属标识（写入newClientOrderId）
+	orderTagging
+
+	// 最大滑点保护（市价单改为限价穿价单）
+	slippageLimit
 }
 
 // NewFuturesTrader 创建合约交易器
@@ -34,7 +50,9 @@ func NewFuturesTrader(apiKey, secretKey string) *FuturesTrader {
 	client := futures.NewClient(apiKey, secretKey)
 	return &FuturesTrader{
 		client:        client,
+		spotClient:    binance.NewClient(apiKey, secretKey),
 		cacheDuration: 15 * time.Second, // 15秒缓存
+		specs:         SharedSpecRegistry("binance"),
 	}
 }
 
@@ -58,7 +76,7 @@ func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("获取账户信息失败: %w", err)
 	}
 
-	log.Printf("account %+v", account)
+	logger.Debugf("币安账户信息: %+v", account)
 
 	result := make(map[string]interface{})
 	result["totalWalletBalance"], _ = strconv.ParseFloat(account.TotalWalletBalance, 64)
@@ -133,6 +151,54 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 	return result, nil
 }
 
+// IsDualMode 检测账户是否为双向持仓模式（结果缓存）
+func (t *FuturesTrader) IsDualMode() (bool, error) {
+	t.positionModeMutex.Lock()
+	defer t.positionModeMutex.Unlock()
+
+	if t.dualSidePosition != nil {
+		return *t.dualSidePosition, nil
+	}
+
+	mode, err := t.client.NewGetPositionModeService().Do(context.Background())
+	if err != nil {
+		return false, fmt.Errorf("获取持仓模式失败: %w", err)
+	}
+
+	dual := mode.DualSidePosition
+	t.dualSidePosition = &dual
+	if dual {
+		log.Printf("✓ 币安账户持仓模式: 双向持仓")
+	} else {
+		log.Printf("✓ 币安账户持仓模式: 单向持仓")
+	}
+	return dual, nil
+}
+
+// orderPositionSide 根据账户持仓模式返回下单使用的positionSide
+// 双向持仓使用 LONG/SHORT，单向持仓使用 BOTH（平仓需配合 reduceOnly）
+func (t *FuturesTrader) orderPositionSide(side futures.PositionSideType) (futures.PositionSideType, bool) {
+	dual, err := t.IsDualMode()
+	if err != nil {
+		// 检测失败时沿用双向持仓（与历史行为一致）
+		log.Printf("  ⚠️ %v，按双向持仓处理", err)
+		return side, true
+	}
+	if dual {
+		return side, true
+	}
+	return futures.PositionSideTypeBoth, false
+}
+
+// newOrder 创建下单请求，带上策略归属的clientOrderId
+func (t *FuturesTrader) newOrder(purpose byte) *futures.CreateOrderService {
+	service := t.client.NewCreateOrderService()
+	if id := t.nextClientOrderID(purpose); id != "" {
+		service = service.NewClientOrderID(id)
+	}
+	return service
+}
+
 // SetMarginMode 设置仓位模式
 func (t *FuturesTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	var marginType futures.MarginType
@@ -240,13 +306,17 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	}
 
 	// 创建市价买入订单
-	order, err := t.client.NewCreateOrderService().
+	posSide, _ := t.orderPositionSide(futures.PositionSideTypeLong)
+	orderService := t.newOrder(OrderPurposeOpen).
 		Symbol(symbol).
 		Side(futures.SideTypeBuy).
-		PositionSide(futures.PositionSideTypeLong).
-		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		PositionSide(posSide).
+		Quantity(quantityStr)
+	orderService, err = t.withSlippageLimit(orderService, symbol, futures.SideTypeBuy, true)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
 		return nil, fmt.Errorf("开多仓失败: %w", err)
@@ -257,6 +327,7 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 
 	result := make(map[string]interface{})
 	result["orderId"] = order.OrderID
+	result["clientOrderId"] = order.ClientOrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
 	return result, nil
@@ -283,13 +354,17 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	}
 
 	// 创建市价卖出订单
-	order, err := t.client.NewCreateOrderService().
+	posSide, _ := t.orderPositionSide(futures.PositionSideTypeShort)
+	orderService := t.newOrder(OrderPurposeOpen).
 		Symbol(symbol).
 		Side(futures.SideTypeSell).
-		PositionSide(futures.PositionSideTypeShort).
-		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		PositionSide(posSide).
+		Quantity(quantityStr)
+	orderService, err = t.withSlippageLimit(orderService, symbol, futures.SideTypeSell, true)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
 		return nil, fmt.Errorf("开空仓失败: %w", err)
@@ -300,6 +375,7 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 
 	result := make(map[string]interface{})
 	result["orderId"] = order.OrderID
+	result["clientOrderId"] = order.ClientOrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
 	return result, nil
@@ -333,13 +409,21 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 	}
 
 	// 创建市价卖出订单（平多）
-	order, err := t.client.NewCreateOrderService().
+	posSide, dual := t.orderPositionSide(futures.PositionSideTypeLong)
+	orderService := t.newOrder(OrderPurposeClose).
 		Symbol(symbol).
 		Side(futures.SideTypeSell).
-		PositionSide(futures.PositionSideTypeLong).
-		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		PositionSide(posSide).
+		Quantity(quantityStr)
+	if !dual {
+		// 单向持仓模式下平仓必须只减仓，避免反向开仓
+		orderService = orderService.ReduceOnly(true)
+	}
+	orderService, err = t.withSlippageLimit(orderService, symbol, futures.SideTypeSell, false)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
 		return nil, fmt.Errorf("平多仓失败: %w", err)
@@ -354,6 +438,7 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 
 	result := make(map[string]interface{})
 	result["orderId"] = order.OrderID
+	result["clientOrderId"] = order.ClientOrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
 	return result, nil
@@ -387,13 +472,21 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 	}
 
 	// 创建市价买入订单（平空）
-	order, err := t.client.NewCreateOrderService().
+	posSide, dual := t.orderPositionSide(futures.PositionSideTypeShort)
+	orderService := t.newOrder(OrderPurposeClose).
 		Symbol(symbol).
 		Side(futures.SideTypeBuy).
-		PositionSide(futures.PositionSideTypeShort).
-		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		PositionSide(posSide).
+		Quantity(quantityStr)
+	if !dual {
+		// 单向持仓模式下平仓必须只减仓，避免反向开仓
+		orderService = orderService.ReduceOnly(true)
+	}
+	orderService, err = t.withSlippageLimit(orderService, symbol, futures.SideTypeBuy, false)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
 		return nil, fmt.Errorf("平空仓失败: %w", err)
@@ -408,6 +501,7 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 
 	result := make(map[string]interface{})
 	result["orderId"] = order.OrderID
+	result["clientOrderId"] = order.ClientOrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
 	return result, nil
@@ -446,6 +540,44 @@ func (t *FuturesTrader) GetMarketPrice(symbol string) (float64, error) {
 	return price, nil
 }
 
+// GetBookQuote 实现 BookQuoteProvider
+func (t *FuturesTrader) GetBookQuote(symbol string) (*BookQuote, error) {
+	books, err := t.client.NewListBookTickersService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取最优挂单失败: %w", err)
+	}
+	if len(books) == 0 {
+		return nil, fmt.Errorf("未找到 %s 的挂单", symbol)
+	}
+
+	quote := &BookQuote{Symbol: symbol}
+	quote.Bid, _ = strconv.ParseFloat(books[0].BidPrice, 64)
+	quote.BidQty, _ = strconv.ParseFloat(books[0].BidQuantity, 64)
+	quote.Ask, _ = strconv.ParseFloat(books[0].AskPrice, 64)
+	quote.AskQty, _ = strconv.ParseFloat(books[0].AskQuantity, 64)
+	return quote, nil
+}
+
+// GetPriceByType 实现 PriceTypeProvider（标记价格及指数价格来自 premiumIndex 接口）
+func (t *FuturesTrader) GetPriceByType(symbol string, priceType PriceType) (float64, error) {
+	if priceType == PriceLast {
+		return t.GetMarketPrice(symbol)
+	}
+	indexes, err := t.client.NewPremiumIndexService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("获取标记价格失败: %w", err)
+	}
+	if len(indexes) == 0 {
+		return 0, fmt.Errorf("未找到价格")
+	}
+
+	value := indexes[0].MarkPrice
+	if priceType == PriceIndex {
+		value = indexes[0].IndexPrice
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
 // CalculatePositionSize 计算仓位大小
 func (t *FuturesTrader) CalculatePositionSize(balance, riskPercent, price float64, leverage int) float64 {
 	riskAmount := balance * (riskPercent / 100.0)
@@ -466,6 +598,7 @@ func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity
 		side = futures.SideTypeBuy
 		posSide = futures.PositionSideTypeShort
 	}
+	posSide, _ = t.orderPositionSide(posSide)
 
 	// 格式化数量
 	quantityStr, err := t.FormatQuantity(symbol, quantity)
@@ -473,14 +606,14 @@ func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity
 		return err
 	}
 
-	_, err = t.client.NewCreateOrderService().
+	_, err = t.newOrder(OrderPurposeStopLoss).
 		Symbol(symbol).
 		Side(side).
 		PositionSide(posSide).
 		Type(futures.OrderTypeStopMarket).
 		StopPrice(fmt.Sprintf("%.8f", stopPrice)).
 		Quantity(quantityStr).
-		WorkingType(futures.WorkingTypeContractPrice).
+		WorkingType(futures.WorkingTypeMarkPrice).
 		ClosePosition(true).
 		Do(context.Background())
 
@@ -504,6 +637,7 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 		side = futures.SideTypeBuy
 		posSide = futures.PositionSideTypeShort
 	}
+	posSide, _ = t.orderPositionSide(posSide)
 
 	// 格式化数量
 	quantityStr, err := t.FormatQuantity(symbol, quantity)
@@ -511,14 +645,14 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 		return err
 	}
 
-	_, err = t.client.NewCreateOrderService().
+	_, err = t.newOrder(OrderPurposeTakeProfit).
 		Symbol(symbol).
 		Side(side).
 		PositionSide(posSide).
 		Type(futures.OrderTypeTakeProfitMarket).
 		StopPrice(fmt.Sprintf("%.8f", takeProfitPrice)).
 		Quantity(quantityStr).
-		WorkingType(futures.WorkingTypeContractPrice).
+		WorkingType(futures.WorkingTypeMarkPrice).
 		ClosePosition(true).
 		Do(context.Background())
 
@@ -530,29 +664,48 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 	return nil
 }
 
-// GetSymbolPrecision 获取交易对的数量精度
-func (t *FuturesTrader) GetSymbolPrecision(symbol string) (int, error) {
+// loadContractSpecs 从交易规则接口一次性加载所有合约的LOT_SIZE及PRICE_FILTER
+func (t *FuturesTrader) loadContractSpecs() (map[string]ContractSpec, error) {
 	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
 	if err != nil {
-		return 0, fmt.Errorf("获取交易规则失败: %w", err)
+		return nil, fmt.Errorf("获取交易规则失败: %w", err)
 	}
 
+	specs := make(map[string]ContractSpec, len(exchangeInfo.Symbols))
 	for _, s := range exchangeInfo.Symbols {
-		if s.Symbol == symbol {
-			// 从LOT_SIZE filter获取精度
-			for _, filter := range s.Filters {
-				if filter["filterType"] == "LOT_SIZE" {
-					stepSize := filter["stepSize"].(string)
-					precision := calculatePrecision(stepSize)
-					log.Printf("  %s 数量精度: %d (stepSize: %s)", symbol, precision, stepSize)
-					return precision, nil
-				}
+		spec := ContractSpec{Symbol: s.Symbol, QuantityPrecision: 3, Multiplier: 1}
+		for _, filter := range s.Filters {
+			switch filter["filterType"] {
+			case "LOT_SIZE":
+				stepSize, _ := filter["stepSize"].(string)
+				minQty, _ := filter["minQty"].(string)
+				spec.QuantityPrecision = calculatePrecision(stepSize)
+				spec.StepSize, _ = strconv.ParseFloat(stepSize, 64)
+				spec.MinQty, _ = strconv.ParseFloat(minQty, 64)
+			case "PRICE_FILTER":
+				tickSize, _ := filter["tickSize"].(string)
+				spec.PricePrecision = calculatePrecision(tickSize)
+				spec.TickSize, _ = strconv.ParseFloat(tickSize, 64)
 			}
 		}
+		specs[s.Symbol] = spec
 	}
+	return specs, nil
+}
+
+// GetContractSpec 获取交易对的交易规则（缓存，每小时刷新）
+func (t *FuturesTrader) GetContractSpec(symbol string) (ContractSpec, error) {
+	return t.specs.get(symbol, t.loadContractSpecs)
+}
 
-	log.Printf("  ⚠ %s 未找到精度信息，使用默认精度3", symbol)
-	return 3, nil // 默认精度为3
+// GetSymbolPrecision 获取交易对的数量精度
+func (t *FuturesTrader) GetSymbolPrecision(symbol string) (int, error) {
+	spec, err := t.GetContractSpec(symbol)
+	if err != nil {
+		log.Printf("  ⚠ %s 未找到精度信息，使用默认精度3: %v", symbol, err)
+		return 3, nil // 默认精度为3
+	}
+	return spec.QuantityPrecision, nil
 }
 
 // calculatePrecision 从stepSize计算精度
@@ -600,14 +753,113 @@ func trimTrailingZeros(s string) string {
 
 // FormatQuantity 格式化数量到正确的精度
 func (t *FuturesTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
-	precision, err := t.GetSymbolPrecision(symbol)
+	spec, err := t.GetContractSpec(symbol)
 	if err != nil {
 		// 如果获取失败，使用默认格式
 		return fmt.Sprintf("%.3f", quantity), nil
 	}
+	return spec.FormatQuantity(quantity), nil
+}
+
+// RoundPrice 将价格取整到交易对的tick size（PRICE_FILTER）
+func (t *FuturesTrader) RoundPrice(symbol string, price float64) (float64, error) {
+	spec, err := t.GetContractSpec(symbol)
+	if err != nil {
+		return 0, err
+	}
+	if spec.TickSize <= 0 {
+		return 0, fmt.Errorf("未找到 %s 的价格精度信息", symbol)
+	}
+	return spec.RoundPrice(price), nil
+}
+
+// GetCurrencyBalances 获取合约钱包所有保证金资产及现货钱包余额
+func (t *FuturesTrader) GetCurrencyBalances() ([]CurrencyBalance, error) {
+	account, err := t.client.NewGetAccountService().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取合约账户失败: %w", err)
+	}
+
+	var balances []CurrencyBalance
+	for _, asset := range account.Assets {
+		total, _ := strconv.ParseFloat(asset.WalletBalance, 64)
+		available, _ := strconv.ParseFloat(asset.AvailableBalance, 64)
+		unrealized, _ := strconv.ParseFloat(asset.UnrealizedProfit, 64)
+		balances = append(balances, CurrencyBalance{
+			Wallet:        "futures",
+			Currency:      asset.Asset,
+			Total:         total,
+			Available:     available,
+			UnrealizedPnL: unrealized,
+		})
+	}
+
+	// 现货余额获取失败（如API Key未开通现货权限）不影响合约余额
+	spotAccount, err := t.spotClient.NewGetAccountService().Do(context.Background())
+	if err != nil {
+		log.Printf("⚠️  获取币安现货账户失败: %v", err)
+		return balances, nil
+	}
+	for _, bal := range spotAccount.Balances {
+		free, _ := strconv.ParseFloat(bal.Free, 64)
+		locked, _ := strconv.ParseFloat(bal.Locked, 64)
+		balances = append(balances, CurrencyBalance{
+			Wallet:    "spot",
+			Currency:  bal.Asset,
+			Total:     free + locked,
+			Available: free,
+		})
+	}
+
+	return balances, nil
+}
+
+// TransferMargin 现货与U本位合约钱包之间划转（需要API Key开通万向划转权限）
+func (t *FuturesTrader) TransferMargin(from, to, currency string, amount float64) error {
+	if err := validateTransfer(from, to, amount); err != nil {
+		return err
+	}
+
+	transferType := binance.UserUniversalTransferTypeMainToUmFutures
+	if from == WalletFutures {
+		transferType = binance.UserUniversalTransferTypeUmFuturesToMain
+	}
+
+	result, err := t.spotClient.NewUserUniversalTransferService().
+		Type(transferType).
+		Asset(currency).
+		Amount(strconv.FormatFloat(amount, 'f', -1, 64)).
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("划转失败: %w", err)
+	}
+
+	// 划转后余额变化，清除缓存
+	t.balanceCacheMutex.Lock()
+	t.cachedBalance = nil
+	t.balanceCacheMutex.Unlock()
+
+	log.Printf("✓ 币安划转成功: %s → %s %.4f %s (tranId=%d)", from, to, amount, currency, result.ID)
+	return nil
+}
+
+// GetFeeSchedule 获取账户在该币种上的实际maker/taker费率
+func (t *FuturesTrader) GetFeeSchedule(symbol string) (*FeeSchedule, error) {
+	rate, err := t.client.NewCommissionRateService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+
+	maker, err := strconv.ParseFloat(rate.MakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析maker费率失败: %w", err)
+	}
+	taker, err := strconv.ParseFloat(rate.TakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析taker费率失败: %w", err)
+	}
 
-	format := fmt.Sprintf("%%.%df", precision)
-	return fmt.Sprintf(format, quantity), nil
+	return &FeeSchedule{Symbol: symbol, Maker: maker, Taker: taker}, nil
 }
 
 // 辅助函数
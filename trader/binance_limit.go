@@ -0,0 +1,121 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// PlaceLimitOrder 挂币安限价单（仅支持单向持仓模式，双向持仓下买卖方向无法对应到单一仓位）
+func (t *FuturesTrader) PlaceLimitOrder(symbol, side string, quantity, price float64, postOnly bool) (int64, error) {
+	return t.placeLimitOrder(OrderPurposeQuote, symbol, side, quantity, price, postOnly)
+}
+
+// PlaceEntryOrder 挂只做Maker的开仓限价单
+func (t *FuturesTrader) PlaceEntryOrder(symbol, side string, quantity, price float64) (int64, error) {
+	return t.placeLimitOrder(OrderPurposeOpen, symbol, side, quantity, price, true)
+}
+
+// GetOrderFill 查询订单已成交数量及均价
+func (t *FuturesTrader) GetOrderFill(symbol string, orderID int64) (float64, float64, bool, error) {
+	order, err := t.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("查询订单失败: %w", err)
+	}
+	filled, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	done := order.Status != futures.OrderStatusTypeNew && order.Status != futures.OrderStatusTypePartiallyFilled
+	return filled, avgPrice, done, nil
+}
+
+// placeLimitOrder 按订单用途挂限价单
+func (t *FuturesTrader) placeLimitOrder(purpose byte, symbol, side string, quantity, price float64, postOnly bool) (int64, error) {
+	posSide, dual := t.orderPositionSide(futures.PositionSideTypeBoth)
+	if dual {
+		return 0, fmt.Errorf("限价挂单需要单向持仓模式")
+	}
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return 0, err
+	}
+	priceStr, err := t.formatLimitPrice(symbol, price)
+	if err != nil {
+		return 0, err
+	}
+
+	timeInForce := futures.TimeInForceTypeGTC
+	if postOnly {
+		timeInForce = futures.TimeInForceTypeGTX
+	}
+	order, err := t.newOrder(purpose).
+		Symbol(symbol).
+		Side(futures.SideType(side)).
+		PositionSide(posSide).
+		Type(futures.OrderTypeLimit).
+		TimeInForce(timeInForce).
+		Quantity(quantityStr).
+		Price(priceStr).
+		Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("限价挂单失败: %w", err)
+	}
+	return order.OrderID, nil
+}
+
+// AmendOrder 修改币安限价单的价格和数量
+func (t *FuturesTrader) AmendOrder(symbol string, orderID int64, side string, quantity, price float64) error {
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return err
+	}
+	priceStr, err := t.formatLimitPrice(symbol, price)
+	if err != nil {
+		return err
+	}
+	_, err = t.client.NewModifyOrderService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Side(futures.SideType(side)).
+		Quantity(quantityStr).
+		Price(priceStr).
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("改单失败: %w", err)
+	}
+	return nil
+}
+
+// CancelOrder 撤销币安挂单
+func (t *FuturesTrader) CancelOrder(symbol string, orderID int64) error {
+	_, err := t.client.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("撤单失败: %w", err)
+	}
+	return nil
+}
+
+// withSlippageLimit 设置市价单的订单类型：开启最大滑点保护时为限价穿价的IOC限价单，否则为市价单（开仓无法计算限价时返回错误）
+func (t *FuturesTrader) withSlippageLimit(service *futures.CreateOrderService, symbol string, side futures.SideType, opening bool) (*futures.CreateOrderService, error) {
+	price, ok, err := t.limitThroughPrice(t, t.GetContractSpec, symbol, side == futures.SideTypeBuy, opening)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return service.Type(futures.OrderTypeLimit).TimeInForce(futures.TimeInForceTypeIOC).Price(price), nil
+	}
+	return service.Type(futures.OrderTypeMarket), nil
+}
+
+// formatLimitPrice 按PRICE_FILTER的tickSize格式化价格（交易规则按合约缓存，避免每次改单都请求交易规则）
+func (t *FuturesTrader) formatLimitPrice(symbol string, price float64) (string, error) {
+	spec, err := t.GetContractSpec(symbol)
+	if err != nil {
+		return "", err
+	}
+	if spec.TickSize <= 0 {
+		return "", fmt.Errorf("未找到 %s 的价格精度", symbol)
+	}
+	return spec.FormatPrice(price), nil
+}
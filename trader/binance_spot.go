@@ -0,0 +1,116 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// GetSpotPrice 获取币安现货最新价格
+func (t *FuturesTrader) GetSpotPrice(symbol string) (float64, error) {
+	prices, err := t.spotClient.NewListPricesService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("获取现货价格失败: %w", err)
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("未找到现货价格: %s", symbol)
+	}
+	price, err := strconv.ParseFloat(prices[0].Price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析现货价格失败: %w", err)
+	}
+	return price, nil
+}
+
+// SpotBuy 以USDT金额市价买入币安现货（需要现货钱包有足够USDT）
+func (t *FuturesTrader) SpotBuy(symbol string, quoteAmount float64) (*SpotFill, error) {
+	if quoteAmount <= 0 {
+		return nil, fmt.Errorf("买入金额必须大于0")
+	}
+	order, err := t.spotClient.NewCreateOrderService().
+		Symbol(symbol).
+		Side(binance.SideTypeBuy).
+		Type(binance.OrderTypeMarket).
+		QuoteOrderQty(strconv.FormatFloat(quoteAmount, 'f', 2, 64)).
+		NewOrderRespType(binance.NewOrderRespTypeFULL).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("现货买入失败: %w", err)
+	}
+
+	fill := spotFill(symbol, order)
+	log.Printf("✓ 现货买入成功: %s 数量 %.6f 均价 %.4f (订单ID: %d)", symbol, fill.Quantity, fill.AvgPrice, order.OrderID)
+	return fill, nil
+}
+
+// SpotSell 市价卖出币安现货（数量按LOT_SIZE向下取整）
+func (t *FuturesTrader) SpotSell(symbol string, quantity float64) (*SpotFill, error) {
+	stepSize, err := t.spotStepSize(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if stepSize > 0 {
+		quantity = math.Floor(quantity/stepSize+1e-9) * stepSize
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("卖出数量过小")
+	}
+
+	order, err := t.spotClient.NewCreateOrderService().
+		Symbol(symbol).
+		Side(binance.SideTypeSell).
+		Type(binance.OrderTypeMarket).
+		Quantity(strconv.FormatFloat(quantity, 'f', -1, 64)).
+		NewOrderRespType(binance.NewOrderRespTypeFULL).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("现货卖出失败: %w", err)
+	}
+
+	fill := spotFill(symbol, order)
+	log.Printf("✓ 现货卖出成功: %s 数量 %.6f 均价 %.4f (订单ID: %d)", symbol, fill.Quantity, fill.AvgPrice, order.OrderID)
+	return fill, nil
+}
+
+// spotStepSize 获取现货交易对的数量步长
+func (t *FuturesTrader) spotStepSize(symbol string) (float64, error) {
+	info, err := t.spotClient.NewExchangeInfoService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("获取现货交易规则失败: %w", err)
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol == symbol {
+			if filter := s.LotSizeFilter(); filter != nil {
+				step, _ := strconv.ParseFloat(filter.StepSize, 64)
+				return step, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("未找到现货交易对: %s", symbol)
+}
+
+// spotFill 从下单响应计算实际成交（买入时扣除以基础币种收取的手续费）
+func spotFill(symbol string, order *binance.CreateOrderResponse) *SpotFill {
+	executed, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	quote, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64)
+	fill := &SpotFill{Symbol: symbol, Quantity: executed, Quote: quote}
+	if executed > 0 {
+		fill.AvgPrice = quote / executed
+	}
+	if order.Side == binance.SideTypeBuy {
+		base := strings.TrimSuffix(symbol, "USDT")
+		for _, f := range order.Fills {
+			if f.CommissionAsset == base {
+				commission, _ := strconv.ParseFloat(f.Commission, 64)
+				fill.Quantity -= commission
+			}
+		}
+	}
+	return fill
+}
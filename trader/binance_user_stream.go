@@ -0,0 +1,164 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// binanceListenKeyKeepalive listenKey续期间隔（币安60分钟过期）
+const binanceListenKeyKeepalive = 30 * time.Minute
+
+// StreamUserData 实现 UserDataStreamer：订阅币安合约用户数据流（持仓、订单及杠杆变化）
+func (t *FuturesTrader) StreamUserData(book *ShadowBook, stop <-chan struct{}) error {
+	ctx := context.Background()
+	listenKey, err := t.client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("获取listenKey失败: %w", err)
+	}
+	expired := make(chan struct{}, 1)
+	errC := make(chan error, 1)
+	handler := func(event *futures.WsUserDataEvent) {
+		if event.Event == futures.UserDataEventTypeListenKeyExpired {
+			select {
+			case expired <- struct{}{}:
+			default:
+			}
+			return
+		}
+		applyBinanceUserEvent(book, event)
+	}
+	errHandler := func(err error) {
+		select {
+		case errC <- err:
+		default:
+		}
+	}
+	doneC, stopC, err := futures.WsUserDataServe(listenKey, handler, errHandler)
+	if err != nil {
+		return fmt.Errorf("连接用户数据流失败: %w", err)
+	}
+	book.setStreaming(true)
+	log.Printf("📒 币安用户数据流已连接，影子账本实时更新")
+
+	keepalive := time.NewTicker(binanceListenKeyKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-stop:
+			close(stopC)
+			<-doneC
+			if err := t.client.NewCloseUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				log.Printf("⚠️ 关闭listenKey失败: %v", err)
+			}
+			return nil
+		case <-expired:
+			close(stopC)
+			<-doneC
+			return fmt.Errorf("listenKey已过期")
+		case <-doneC:
+			select {
+			case err := <-errC:
+				return fmt.Errorf("连接已关闭: %w", err)
+			default:
+				return fmt.Errorf("连接已关闭")
+			}
+		case <-keepalive.C:
+			if err := t.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				log.Printf("⚠️ listenKey续期失败: %v", err)
+			}
+		}
+	}
+}
+
+// applyBinanceUserEvent 把币安用户数据推送写入影子账本
+func applyBinanceUserEvent(book *ShadowBook, event *futures.WsUserDataEvent) {
+	eventTime := event.TransactionTime
+	if eventTime == 0 {
+		eventTime = event.Time
+	}
+	switch event.Event {
+	case futures.UserDataEventTypeAccountUpdate:
+		for _, p := range event.AccountUpdate.Positions {
+			amount, _ := strconv.ParseFloat(p.Amount, 64)
+			entryPrice, _ := strconv.ParseFloat(p.EntryPrice, 64)
+			unrealized, _ := strconv.ParseFloat(p.UnrealizedPnL, 64)
+			side := ""
+			switch {
+			case p.Side == futures.PositionSideTypeLong:
+				side = "long"
+			case p.Side == futures.PositionSideTypeShort:
+				side = "short"
+			case amount > 0:
+				side = "long"
+			case amount < 0:
+				side = "short"
+			}
+			book.ApplyPosition(PositionUpdate{Symbol: p.Symbol, Side: side, Amount: amount, EntryPrice: entryPrice, UnrealizedPnL: unrealized, Time: eventTime})
+		}
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		o := event.OrderTradeUpdate
+		order := BookOrder{
+			OrderID:       o.ID,
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        o.Symbol,
+			Side:          string(o.Side),
+			PositionSide:  string(o.PositionSide),
+			Type:          string(o.Type),
+			ReduceOnly:    o.IsReduceOnly || o.IsClosingPosition,
+			UpdateTime:    eventTime,
+		}
+		order.Price, _ = strconv.ParseFloat(o.OriginalPrice, 64)
+		order.StopPrice, _ = strconv.ParseFloat(o.StopPrice, 64)
+		order.Quantity, _ = strconv.ParseFloat(o.OriginalQty, 64)
+		order.FilledQty, _ = strconv.ParseFloat(o.AccumulatedFilledQty, 64)
+		switch o.Status {
+		case futures.OrderStatusTypeNew, futures.OrderStatusTypePartiallyFilled:
+			book.ApplyOrder(order, false)
+		default: // FILLED / CANCELED / EXPIRED / REJECTED 等
+			book.ApplyOrder(order, true)
+		}
+	case futures.UserDataEventTypeAccountConfigUpdate:
+		if c := event.AccountConfigUpdate; c.Symbol != "" && c.Leverage > 0 {
+			book.ApplyLeverage(c.Symbol, float64(c.Leverage))
+		}
+	}
+}
+
+// GetOpenOrders 实现 OpenOrderLister
+func (t *FuturesTrader) GetOpenOrders() ([]BookOrder, error) {
+	orders, err := t.client.NewListOpenOrdersService().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取挂单失败: %w", err)
+	}
+	result := make([]BookOrder, 0, len(orders))
+	for _, o := range orders {
+		order := BookOrder{
+			OrderID:       o.OrderID,
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        o.Symbol,
+			Side:          string(o.Side),
+			PositionSide:  string(o.PositionSide),
+			Type:          string(o.Type),
+			ReduceOnly:    o.ReduceOnly || o.ClosePosition,
+			UpdateTime:    o.UpdateTime,
+		}
+		order.Price, _ = strconv.ParseFloat(o.Price, 64)
+		order.StopPrice, _ = strconv.ParseFloat(o.StopPrice, 64)
+		order.Quantity, _ = strconv.ParseFloat(o.OrigQuantity, 64)
+		order.FilledQty, _ = strconv.ParseFloat(o.ExecutedQuantity, 64)
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+// clearPositionCache 实现 positionCache
+func (t *FuturesTrader) clearPositionCache() {
+	t.positionsCacheMutex.Lock()
+	t.cachedPositions = nil
+	t.positionsCacheMutex.Unlock()
+}
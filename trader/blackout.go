@@ -0,0 +1,52 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/calendar"
+	"nofx/decision"
+	"time"
+)
+
+// checkBlackout 经济日历/静态禁止开仓窗口内拒绝开仓
+func (at *AutoTrader) checkBlackout(symbol string) error {
+	if w, ok := calendar.Active(time.Now()); ok {
+		return fmt.Errorf("❌ 处于禁止开仓窗口 %s（%s - %s），拒绝开仓 %s",
+			w.Name, w.Start.Local().Format("01-02 15:04"), w.End.Local().Format("01-02 15:04"), symbol)
+	}
+	return nil
+}
+
+// reduceForBlackout 进入需要减仓的禁止开仓窗口时，按比例减少本策略的持仓（每个窗口只执行一次）
+func (at *AutoTrader) reduceForBlackout(positions []decision.PositionInfo) string {
+	w, ok := calendar.Active(time.Now())
+	if !ok || w.ReducePct <= 0 || at.blackoutReduced[w.Key()] || len(positions) == 0 {
+		return ""
+	}
+	at.blackoutReduced[w.Key()] = true
+
+	if at.config.DryRun {
+		return fmt.Sprintf("WOULD reduce %d positions by %.0f%% for blackout window %s", len(positions), w.ReducePct, w.Name)
+	}
+
+	log.Printf("📅 进入禁止开仓窗口 %s，持仓减少 %.0f%%", w.Name, w.ReducePct)
+	reduced := 0
+	for _, pos := range positions {
+		if err := at.checkCloseOwnership(pos.Symbol, pos.Side); err != nil {
+			continue
+		}
+		quantity := pos.Quantity * w.ReducePct / 100
+
+		unlock := at.lockSymbol(pos.Symbol)
+		_, err := PlaceOrder(at.trader, pos.Symbol, "close_"+pos.Side, quantity, 0)
+		unlock()
+
+		if err != nil {
+			log.Printf("  ⚠️ %s %s仓减仓失败: %v", pos.Symbol, sideName(pos.Side), err)
+			continue
+		}
+		reduced++
+		log.Printf("  ✓ %s %s仓减仓 %.4f", pos.Symbol, sideName(pos.Side), quantity)
+	}
+	return fmt.Sprintf("📅 禁止开仓窗口 %s：%d 个持仓减仓 %.0f%%", w.Name, reduced, w.ReducePct)
+}
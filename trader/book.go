@@ -0,0 +1,29 @@
+package trader
+
+// BookQuote 合约最优买卖挂单（数量为标的币数量，0=未知）
+type BookQuote struct {
+	Symbol string  `json:"symbol"`
+	Bid    float64 `json:"bid"`
+	BidQty float64 `json:"bid_qty"`
+	Ask    float64 `json:"ask"`
+	AskQty float64 `json:"ask_qty"`
+}
+
+// BookQuoteProvider 可查询最优挂单的交易器（可选接口）
+type BookQuoteProvider interface {
+	// GetBookQuote 获取合约的最优买一/卖一价及挂单量
+	GetBookQuote(symbol string) (*BookQuote, error)
+}
+
+// GetBookQuote 获取最优挂单
+// 交易器不支持时，退化为GetMarketPrice返回的最新价（买卖价相同，挂单量未知）
+func GetBookQuote(t Trader, symbol string) (*BookQuote, error) {
+	if provider, ok := t.(BookQuoteProvider); ok {
+		return provider.GetBookQuote(symbol)
+	}
+	price, err := t.GetMarketPrice(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &BookQuote{Symbol: symbol, Bid: price, Ask: price}, nil
+}
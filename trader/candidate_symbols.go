@@ -0,0 +1,101 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"sort"
+)
+
+// CandidateLimits 动态加入候选池的币种的开仓限制
+type CandidateLimits struct {
+	MaxLeverage    int     // 最大杠杆（0=沿用交易员配置）
+	MaxPositionUSD float64 // 单笔最大名义价值（0=不限制）
+}
+
+// candidateSymbol 动态加入候选池的币种
+type candidateSymbol struct {
+	source string // 来源，如 "new_listing"
+	limits CandidateLimits
+}
+
+// AddCandidateSymbol 将合约加入交易员的候选币种池（在配置币种之外额外提供给AI），已存在时返回false
+func (at *AutoTrader) AddCandidateSymbol(symbol, source string, limits CandidateLimits) bool {
+	symbol = normalizeSymbol(symbol)
+	at.candidateMu.Lock()
+	defer at.candidateMu.Unlock()
+	if _, ok := at.extraCandidates[symbol]; ok {
+		return false
+	}
+	at.extraCandidates[symbol] = candidateSymbol{source: source, limits: limits}
+	return true
+}
+
+// CandidateSymbols 获取指定来源动态加入的币种
+func (at *AutoTrader) CandidateSymbols(source string) []string {
+	at.candidateMu.RLock()
+	defer at.candidateMu.RUnlock()
+	var symbols []string
+	for symbol, c := range at.extraCandidates {
+		if c.source == source {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// SetTradingCoins 替换交易员的交易币种列表（动态选币等模块使用，下一个决策周期生效）
+func (at *AutoTrader) SetTradingCoins(coins []string) {
+	at.candidateMu.Lock()
+	defer at.candidateMu.Unlock()
+	at.tradingCoins = append([]string(nil), coins...)
+}
+
+// getTradingCoins 获取当前交易币种列表
+func (at *AutoTrader) getTradingCoins() []string {
+	at.candidateMu.RLock()
+	defer at.candidateMu.RUnlock()
+	return at.tradingCoins
+}
+
+// withExtraCandidates 在候选币种池中追加动态加入的币种
+func (at *AutoTrader) withExtraCandidates(coins []decision.CandidateCoin) []decision.CandidateCoin {
+	at.candidateMu.RLock()
+	defer at.candidateMu.RUnlock()
+	if len(at.extraCandidates) == 0 {
+		return coins
+	}
+	existing := make(map[string]bool, len(coins))
+	for _, c := range coins {
+		existing[c.Symbol] = true
+	}
+	symbols := make([]string, 0, len(at.extraCandidates))
+	for symbol := range at.extraCandidates {
+		if !existing[symbol] {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		coins = append(coins, decision.CandidateCoin{Symbol: symbol, Sources: []string{at.extraCandidates[symbol].source}})
+	}
+	return coins
+}
+
+// applyCandidateLimits 动态加入的币种开仓时按其限制下调杠杆和仓位
+func (at *AutoTrader) applyCandidateLimits(d *decision.Decision) {
+	at.candidateMu.RLock()
+	c, ok := at.extraCandidates[d.Symbol]
+	at.candidateMu.RUnlock()
+	if !ok {
+		return
+	}
+	if c.limits.MaxLeverage > 0 && d.Leverage > c.limits.MaxLeverage {
+		log.Printf("  🛡️ %s（%s）杠杆 %d 倍下调至 %d 倍", d.Symbol, c.source, d.Leverage, c.limits.MaxLeverage)
+		d.Leverage = c.limits.MaxLeverage
+	}
+	if c.limits.MaxPositionUSD > 0 && d.PositionSizeUSD > c.limits.MaxPositionUSD {
+		log.Printf("  🛡️ %s（%s）仓位 %.2f USDT 下调至 %.2f USDT", d.Symbol, c.source, d.PositionSizeUSD, c.limits.MaxPositionUSD)
+		d.PositionSizeUSD = c.limits.MaxPositionUSD
+	}
+}
@@ -0,0 +1,250 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/logger"
+	"nofx/market"
+	"strings"
+	"time"
+)
+
+// CloseFilter 批量平仓的过滤条件（全部为空时平掉所有持仓）
+type CloseFilter struct {
+	Symbols []string `json:"symbols,omitempty"` // 只平这些币种（如 BTCUSDT，可省略USDT后缀）
+	Side    string   `json:"side,omitempty"`    // 只平该方向（long/short）
+}
+
+// Normalize 校验并标准化过滤条件
+func (f CloseFilter) Normalize() (CloseFilter, error) {
+	side := strings.ToLower(strings.TrimSpace(f.Side))
+	if side != "" && side != "long" && side != "short" {
+		return f, fmt.Errorf("无效的持仓方向: %s", f.Side)
+	}
+	out := CloseFilter{Side: side}
+	for _, s := range f.Symbols {
+		if s = strings.TrimSpace(s); s != "" {
+			out.Symbols = append(out.Symbols, market.Normalize(s))
+		}
+	}
+	return out, nil
+}
+
+// Match 持仓是否符合过滤条件
+func (f CloseFilter) Match(symbol, side string) bool {
+	if f.Side != "" && f.Side != side {
+		return false
+	}
+	if len(f.Symbols) == 0 {
+		return true
+	}
+	for _, s := range f.Symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// String 过滤条件描述（用于日志和决策记录）
+func (f CloseFilter) String() string {
+	desc := "全部币种"
+	if len(f.Symbols) > 0 {
+		desc = strings.Join(f.Symbols, ",")
+	}
+	if f.Side != "" {
+		desc += " " + sideName(f.Side) + "仓"
+	}
+	return desc
+}
+
+// CloseResult 批量平仓中单个持仓的结果
+type CloseResult struct {
+	TraderID string  `json:"trader_id"`
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price,omitempty"`
+	OrderID  int64   `json:"order_id,omitempty"`
+	DryRun   bool    `json:"dry_run,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// GroupByAccount 按交易所账户分组（共用同一账户的交易员需串行平仓，避免重复平同一持仓）
+func GroupByAccount(traders []*AutoTrader) [][]*AutoTrader {
+	index := make(map[string]int)
+	var groups [][]*AutoTrader
+	for _, at := range traders {
+		key := at.accountKey()
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], at)
+	}
+	return groups
+}
+
+// CloseAllPositions 按过滤条件平掉本交易员的持仓，并清理已平完币种残留的止盈止损单
+// 平仓数量以交易所最新持仓为准并以只减仓方式下单（数量偏大时不会反向开仓）；
+// 资金费结算窗口不阻止平仓，属于其他策略的持仓跳过。返回逐个持仓的结果
+func (at *AutoTrader) CloseAllPositions(filter CloseFilter, reason string) ([]CloseResult, error) {
+	return at.closeAllPositions(filter, reason, false)
+}
+
+// closeAllPositions 批量平仓（preview=true 时只返回将要平掉的持仓，不下单）
+func (at *AutoTrader) closeAllPositions(filter CloseFilter, reason string, preview bool) ([]CloseResult, error) {
+	filter, err := filter.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "批量平仓"
+	}
+	if err := at.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	// 持有周期锁，避免批量平仓过程中决策周期又开出新仓
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+
+	// 不使用持仓缓存，避免按过期数量平仓
+	if c, ok := at.trader.(positionCache); ok {
+		c.clearPositionCache()
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	log.Printf("🧹 [%s] %s：%s", at.name, reason, filter)
+	var results []CloseResult
+	remaining := make(map[string]int) // 币种 -> 未平掉的持仓数
+	closed := make(map[string]bool)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		quantity, _ := pos["positionAmt"].(float64)
+		if quantity = math.Abs(quantity); quantity == 0 {
+			continue
+		}
+		// 属于其他策略的持仓由归属的交易员平仓（本交易员下辅助策略的持仓一并平掉）
+		if owner := at.positionOwner(symbol, side); !filter.Match(symbol, side) || (owner != "" && !ownedBy(owner, at.id)) {
+			remaining[symbol]++
+			continue
+		}
+		result := at.flattenPosition(symbol, side, quantity, pos, preview)
+		if result.Error != "" {
+			remaining[symbol]++
+		} else {
+			closed[symbol] = true
+		}
+		results = append(results, result)
+	}
+
+	if preview {
+		return results, nil
+	}
+	if !at.config.DryRun {
+		at.cleanupTriggerOrders(filter, closed, remaining)
+	}
+	at.logCloseAll(reason, filter, results)
+	return results, nil
+}
+
+// flattenPosition 以只减仓市价单平掉单个持仓
+func (at *AutoTrader) flattenPosition(symbol, side string, quantity float64, pos map[string]interface{}, preview bool) CloseResult {
+	result := CloseResult{TraderID: at.id, Symbol: symbol, Side: side, Quantity: quantity, DryRun: preview || at.config.DryRun}
+	result.Price, _ = pos["markPrice"].(float64)
+	if result.DryRun {
+		unrealized, _ := pos["unRealizedProfit"].(float64)
+		log.Printf("  🧪 [预演] WOULD close %s %.4f %s @ market (≈%.4f), 未实现盈亏 %+.2f USDT",
+			side, quantity, symbol, result.Price, unrealized)
+		return result
+	}
+
+	unlock := at.lockSymbol(symbol)
+	defer unlock()
+	defer at.book.Invalidate()
+
+	order, err := PlaceOrder(at.trader, symbol, "close_"+side, quantity, 0)
+	if err != nil {
+		log.Printf("  ❌ 平仓 %s %s 失败: %v", symbol, side, err)
+		result.Error = err.Error()
+		return result
+	}
+	result.OrderID, _ = order["orderId"].(int64)
+	clientOrderID, _ := order["clientOrderId"].(string)
+	log.Printf("  ✓ 已平仓 %s %s %.4f", symbol, sideName(side), quantity)
+	at.trackCloseOrder(symbol, side, result.OrderID, clientOrderID, result.Price)
+	at.releaseAllocation(symbol, side)
+	return result
+}
+
+// cleanupTriggerOrders 取消已平完币种的全部挂单，以及符合过滤条件但已无持仓的币种残留的只减仓挂单
+func (at *AutoTrader) cleanupTriggerOrders(filter CloseFilter, closed map[string]bool, remaining map[string]int) {
+	symbols := make(map[string]bool)
+	for symbol := range closed {
+		if remaining[symbol] == 0 {
+			symbols[symbol] = true
+		}
+	}
+	// 残留的止盈止损单（如持仓已被强平或手动平掉）
+	if lister, ok := at.trader.(OpenOrderLister); ok && filter.Side == "" {
+		if orders, err := lister.GetOpenOrders(); err == nil {
+			for _, o := range orders {
+				if o.ReduceOnly && remaining[o.Symbol] == 0 && filter.Match(o.Symbol, "") {
+					symbols[o.Symbol] = true
+				}
+			}
+		}
+	}
+	for symbol := range symbols {
+		unlock := at.lockSymbol(symbol)
+		if err := at.trader.CancelAllOrders(symbol); err != nil {
+			log.Printf("  ⚠ 取消 %s 挂单失败: %v", symbol, err)
+		}
+		unlock()
+	}
+}
+
+// logCloseAll 把批量平仓写入决策日志
+func (at *AutoTrader) logCloseAll(reason string, filter CloseFilter, results []CloseResult) {
+	if len(results) == 0 {
+		log.Printf("  ℹ️ 没有符合条件的持仓")
+		return
+	}
+	record := &logger.DecisionRecord{
+		CycleNumber: at.callCount,
+		CoTTrace:    reason + "：" + filter.String(),
+		Success:     true,
+	}
+	for _, r := range results {
+		action := logger.DecisionAction{
+			Action:    "close_" + r.Side,
+			Symbol:    r.Symbol,
+			Quantity:  r.Quantity,
+			Price:     r.Price,
+			OrderID:   r.OrderID,
+			Timestamp: time.Now(),
+			Success:   r.Error == "",
+			Error:     r.Error,
+			DryRun:    r.DryRun,
+			Context:   logger.NewTradeContext(reason, reason, 0, map[string]float64{"price": r.Price}), // 标记价格，不再逐个查询行情
+		}
+		msg := fmt.Sprintf("🧹 %s %s %s %.4f", reason, r.Symbol, sideName(r.Side), r.Quantity)
+		if r.Error != "" {
+			msg += "失败: " + r.Error
+			record.Success = false
+		}
+		record.Decisions = append(record.Decisions, action)
+		record.ExecutionLog = append(record.ExecutionLog, msg)
+	}
+	if err := at.decisionLogger.LogDecision(record); err != nil {
+		log.Printf("⚠ 保存决策记录失败: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package trader
+
+import (
+	"nofx/logger"
+	"testing"
+	"time"
+)
+
+type closeAllStub struct {
+	*positionStub
+	closed    map[string]float64
+	cancelled []string
+}
+
+func (s *closeAllStub) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	s.closed[symbol+"_long"] = quantity
+	s.reduce(symbol, "long", quantity)
+	return map[string]interface{}{"orderId": int64(len(s.closed))}, nil
+}
+
+func (s *closeAllStub) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	s.closed[symbol+"_short"] = quantity
+	s.reduce(symbol, "short", quantity)
+	return map[string]interface{}{"orderId": int64(len(s.closed))}, nil
+}
+
+func (s *closeAllStub) CancelAllOrders(symbol string) error {
+	s.cancelled = append(s.cancelled, symbol)
+	return nil
+}
+
+func TestCloseAllPositionsFilter(t *testing.T) {
+	stub := &closeAllStub{positionStub: &positionStub{stubTrader: newStubTrader(), positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5, "markPrice": 60000.0},
+		{"symbol": "BTCUSDT", "side": "short", "positionAmt": -0.2, "markPrice": 60000.0},
+		{"symbol": "SOLUSDT", "side": "long", "positionAmt": 10.0, "markPrice": 150.0},
+		{"symbol": "ETHUSDT", "side": "long", "positionAmt": 3.0, "markPrice": 3000.0},
+	}}, closed: make(map[string]float64)}
+	at := &AutoTrader{id: "t1", name: "t1", trader: stub, decisionLogger: logger.NewDecisionLogger(t.TempDir()), trackedPositions: make(map[string]*trackedPosition)}
+
+	if _, err := at.CloseAllPositions(CloseFilter{Side: "up"}, ""); err == nil {
+		t.Fatal("invalid side accepted")
+	}
+	results, err := at.CloseAllPositions(CloseFilter{Symbols: []string{"btc", "SOLUSDT"}, Side: "long"}, "")
+	if err != nil {
+		t.Fatalf("CloseAllPositions: %v", err)
+	}
+	if len(results) != 2 || len(stub.closed) != 2 {
+		t.Fatalf("results = %+v, closed = %v", results, stub.closed)
+	}
+	// 按持仓数量下单（而非交由交易所按缓存持仓计算）
+	if stub.closed["BTCUSDT_long"] != 0.5 || stub.closed["SOLUSDT_long"] != 10 {
+		t.Fatalf("close quantities = %v", stub.closed)
+	}
+	// BTCUSDT 仍有空仓，只清理已平完的 SOLUSDT 的挂单
+	if len(stub.cancelled) != 1 || stub.cancelled[0] != "SOLUSDT" {
+		t.Fatalf("cancelled = %v", stub.cancelled)
+	}
+}
+
+// partialCloseStub 每次平多只成交 fill 的数量（模拟IOC限价单超出滑点的部分未成交）
+type partialCloseStub struct {
+	*positionStub
+	fill   float64
+	closes int
+}
+
+func (s *partialCloseStub) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	s.closes++
+	s.reduce(symbol, "long", s.fill)
+	return map[string]interface{}{"orderId": int64(s.closes)}, nil
+}
+
+func TestPlaceCloseVerifiesFill(t *testing.T) {
+	defer func(d time.Duration) { closeFillRecheckDelay = d }(closeFillRecheckDelay)
+	closeFillRecheckDelay = 0
+
+	// 部分成交后按剩余数量补单
+	stub := &partialCloseStub{positionStub: &positionStub{stubTrader: newStubTrader(), positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 1.0},
+	}}, fill: 0.5}
+	if _, err := PlaceOrder(stub, "BTCUSDT", "close_long", 0, 0); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if stub.closes != 2 || len(stub.positions) != 0 {
+		t.Fatalf("closes = %d, positions = %v", stub.closes, stub.positions)
+	}
+
+	// 一直未成交时返回错误
+	stub = &partialCloseStub{positionStub: &positionStub{stubTrader: newStubTrader(), positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 1.0},
+	}}, fill: 0.1}
+	if _, err := PlaceOrder(stub, "BTCUSDT", "close_long", 0, 0); err == nil {
+		t.Fatal("unfilled close reported success")
+	}
+	if stub.closes != 1+closeFillRetries {
+		t.Errorf("closes = %d, want %d", stub.closes, 1+closeFillRetries)
+	}
+}
@@ -0,0 +1,84 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// closeFillRetries 平仓未完全成交时按剩余数量补单的次数
+// （开启最大滑点保护时平仓为IOC限价单，超出限价的部分不成交；限价模拟的市价单也可能未成交）
+const closeFillRetries = 2
+
+// closeFillRecheckDelay 发现平仓未完全成交时，补单前等待交易所更新持仓的时间
+var closeFillRecheckDelay = 500 * time.Millisecond
+
+// placeClose 平仓并核对成交：提交前后查询该方向的持仓，剩余数量超过预期时按剩余数量补单，
+// 补单后仍未平掉返回错误（不把未成交的平仓报告为成功）；无法查询持仓时不核对
+func placeClose(t Trader, symbol, action string, quantity float64, purpose byte) (map[string]interface{}, error) {
+	side := strings.TrimPrefix(action, "close_")
+	before, err := positionQuantity(t, symbol, side)
+	if err != nil {
+		log.Printf("  ⚠ %s 获取持仓失败，无法核对平仓成交: %v", symbol, err)
+		return submitOrder(t, symbol, action, quantity, 0, purpose)
+	}
+	expected := 0.0 // 平仓后预期剩余的持仓
+	if quantity > 0 && quantity < before {
+		expected = before - quantity
+	}
+	tolerance := before * 1e-6
+
+	order, err := submitOrder(t, symbol, action, quantity, 0, purpose)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		remaining, err := positionQuantity(t, symbol, side)
+		if err == nil && remaining-expected > tolerance {
+			// 持仓可能尚未更新，等待后再确认一次
+			time.Sleep(closeFillRecheckDelay)
+			remaining, err = positionQuantity(t, symbol, side)
+		}
+		if err != nil {
+			log.Printf("  ⚠ %s 获取持仓失败，无法核对平仓成交: %v", symbol, err)
+			return order, nil
+		}
+		unfilled := remaining - expected
+		if unfilled <= tolerance {
+			return order, nil
+		}
+		if attempt >= closeFillRetries {
+			return nil, fmt.Errorf("%s %s仓平仓未完全成交（剩余 %.6f / %.6f），可能超出最大滑点或流动性不足", symbol, sideName(side), unfilled, before-expected)
+		}
+		log.Printf("  ⚠ %s %s仓平仓未完全成交（剩余 %.6f），按剩余数量补单", symbol, sideName(side), unfilled)
+		if order, err = submitOrder(t, symbol, action, unfilled, 0, purpose); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// positionQuantity 交易所中该方向的持仓数量（无持仓为0；BTC_USDT 与 BTCUSDT 视为同一合约），先清除交易器的持仓缓存
+func positionQuantity(t Trader, symbol, side string) (float64, error) {
+	if cache, ok := t.(positionCache); ok {
+		cache.clearPositionCache()
+	}
+	positions, err := t.GetPositions()
+	if err != nil {
+		return 0, err
+	}
+	for _, pos := range positions {
+		posSymbol, _ := pos["symbol"].(string)
+		if sameSymbol(posSymbol, symbol) && pos["side"] == side {
+			quantity, _ := pos["positionAmt"].(float64)
+			return math.Abs(quantity), nil
+		}
+	}
+	return 0, nil
+}
+
+// sameSymbol 两个交易对是否为同一合约（忽略大小写及下划线）
+func sameSymbol(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "_", ""), strings.ReplaceAll(b, "_", ""))
+}
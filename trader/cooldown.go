@@ -0,0 +1,118 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/logger"
+	"strings"
+	"time"
+)
+
+// setStopLoss 挂出止损单，并把预留的止损用途clientOrderId记到跟踪的持仓上（持仓消失时按它确认止损是否成交）
+func (at *AutoTrader) setStopLoss(symbol, side string, quantity, stopPrice float64) error {
+	jt, ok := at.trader.(journaledTrader)
+	if !ok || jt.tagging().orderTag.IsZero() {
+		return at.trader.SetStopLoss(symbol, strings.ToUpper(side), quantity, stopPrice)
+	}
+	o := jt.tagging()
+	o.placeMu.Lock()
+	defer o.placeMu.Unlock()
+	id := jt.reserveClientOrderID(OrderPurposeStopLoss)
+	o.presetClientOrderID(OrderPurposeStopLoss, id)
+	defer o.presetClientOrderID(OrderPurposeStopLoss, "")
+	if err := at.trader.SetStopLoss(symbol, strings.ToUpper(side), quantity, stopPrice); err != nil {
+		return err
+	}
+	if tracked, ok := at.trackedPositions[symbol+"_"+side]; ok {
+		tracked.stopOrderID = id
+	}
+	return nil
+}
+
+// stopOrderFilled 持仓消失后按止损单的clientOrderId向交易所确认止损单是否成交
+func (at *AutoTrader) stopOrderFilled(symbol string, tracked *trackedPosition) bool {
+	if tracked.stopOrderID == "" {
+		return false
+	}
+	lookup, ok := at.trader.(ClientOrderLookup)
+	if !ok {
+		return false
+	}
+	order, found, err := lookup.GetOrderByClientID(symbol, tracked.stopOrderID)
+	if err != nil {
+		log.Printf("  ⚠ [%s] 查询 %s 止损单失败: %v", at.name, symbol, err)
+		return false
+	}
+	return found && order.FilledQty > 0
+}
+
+// recordStopOut 止损单成交：开始冷却期，并把止损平仓（带止损单的clientOrderId）写入决策日志，重启后据此恢复冷却期
+func (at *AutoTrader) recordStopOut(symbol, side string, tracked *trackedPosition) {
+	at.startStopLossCooldown(symbol, time.Now())
+	record := &logger.DecisionRecord{
+		CycleNumber:  at.callCount,
+		Success:      true,
+		ExecutionLog: []string{fmt.Sprintf("🛑 %s %s仓止损单成交 @ %.4f", symbol, sideName(side), tracked.stopLoss)},
+		Decisions: []logger.DecisionAction{{
+			Action:        "close_" + side,
+			Symbol:        symbol,
+			Quantity:      tracked.quantity,
+			Price:         tracked.stopLoss,
+			ClientOrderID: tracked.stopOrderID,
+			Timestamp:     time.Now(),
+			Success:       true,
+			Context:       logger.NewTradeContext(logger.TradeSourceStopLoss, "", 0, map[string]float64{"price": tracked.markPrice}),
+		}},
+	}
+	if err := at.decisionLogger.LogDecision(record); err != nil {
+		log.Printf("⚠ 保存决策记录失败: %v", err)
+	}
+}
+
+// startStopLossCooldown 止损触发后开始该币种的冷却期（stoppedAt为止损成交时间）
+func (at *AutoTrader) startStopLossCooldown(symbol string, stoppedAt time.Time) {
+	if at.config.StopLossCooldown <= 0 {
+		return
+	}
+	until := stoppedAt.Add(at.config.StopLossCooldown)
+	if !until.After(at.symbolCooldowns[symbol]) {
+		return
+	}
+	at.symbolCooldowns[symbol] = until
+	log.Printf("🧊 [%s] %s 触发止损，%s 前禁止再次开仓", at.name, symbol, until.Format("15:04:05"))
+}
+
+// restoreCooldowns 从决策日志中止损用途的平仓记录恢复未结束的冷却期，避免重启后冷却期被重置
+func (at *AutoTrader) restoreCooldowns() {
+	if at.config.StopLossCooldown <= 0 {
+		return
+	}
+	records, err := at.decisionLogger.GetLatestRecords(throttleLookback)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-at.config.StopLossCooldown)
+	for _, record := range records {
+		for _, action := range record.Decisions {
+			if !action.Success || action.DryRun || !action.Timestamp.After(cutoff) || !strings.HasPrefix(action.Action, "close_") {
+				continue
+			}
+			if purpose, ok := OrderPurposeOf(action.ClientOrderID); ok && purpose == OrderPurposeStopLoss {
+				at.startStopLossCooldown(action.Symbol, action.Timestamp)
+			}
+		}
+	}
+}
+
+// checkCooldown 检查币种是否处于止损冷却期
+func (at *AutoTrader) checkCooldown(symbol string) error {
+	until, ok := at.symbolCooldowns[symbol]
+	if !ok {
+		return nil
+	}
+	if remaining := time.Until(until); remaining > 0 {
+		return fmt.Errorf("❌ %s 止损后冷却中，剩余 %.0f 分钟，拒绝开仓", symbol, remaining.Minutes())
+	}
+	delete(at.symbolCooldowns, symbol)
+	return nil
+}
@@ -0,0 +1,54 @@
+package trader
+
+import (
+	"nofx/logger"
+	"testing"
+	"time"
+)
+
+func TestStopLossCooldownFromJournal(t *testing.T) {
+	dir := t.TempDir()
+	stub := newStubTrader()
+	config := AutoTraderConfig{StopLossCooldown: time.Hour}
+	at := &AutoTrader{id: "t1", name: "t1", trader: stub, config: config, decisionLogger: logger.NewDecisionLogger(dir),
+		trackedPositions: make(map[string]*trackedPosition), symbolCooldowns: make(map[string]time.Time)}
+
+	at.trackOpenOrder("BTCUSDT", "long", 1, "", 0.1, 60000, 59000, 0)
+	if err := at.setStopLoss("BTCUSDT", "long", 0.1, 59000); err != nil {
+		t.Fatalf("setStopLoss: %v", err)
+	}
+	open := []map[string]interface{}{{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.1, "entryPrice": 60000.0, "markPrice": 59010.0}}
+	at.observePositions(open)
+	tracked := at.trackedPositions["BTCUSDT_long"]
+	if purpose, ok := OrderPurposeOf(tracked.stopOrderID); !ok || purpose != OrderPurposeStopLoss {
+		t.Fatalf("stop order id = %q", tracked.stopOrderID)
+	}
+
+	// 持仓消失但止损单未成交（如手动平仓）：不进入冷却期
+	at.observePositions(nil)
+	if err := at.checkCooldown("BTCUSDT"); err != nil {
+		t.Fatalf("cooldown without a stop fill: %v", err)
+	}
+
+	at.trackOpenOrder("BTCUSDT", "long", 2, "", 0.1, 60000, 59000, 0)
+	if err := at.setStopLoss("BTCUSDT", "long", 0.1, 59000); err != nil {
+		t.Fatalf("setStopLoss: %v", err)
+	}
+	at.observePositions(open)
+	stub.orders[at.trackedPositions["BTCUSDT_long"].stopOrderID] = &ClientOrder{Status: "FILLED", FilledQty: 0.1}
+	at.observePositions(nil)
+	if err := at.checkCooldown("BTCUSDT"); err == nil {
+		t.Fatal("no cooldown after the stop order filled")
+	}
+
+	// 重启后从决策日志恢复冷却期
+	restarted := &AutoTrader{id: "t1", name: "t1", trader: stub, config: config, decisionLogger: logger.NewDecisionLogger(dir),
+		symbolCooldowns: make(map[string]time.Time)}
+	restarted.restoreCooldowns()
+	if err := restarted.checkCooldown("BTCUSDT"); err == nil {
+		t.Fatal("cooldown not restored from the decision log")
+	}
+	if err := restarted.checkCooldown("ETHUSDT"); err != nil {
+		t.Fatalf("unrelated symbol in cooldown: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ParseCorrelationGroups 解析相关性分组，如 {"alt_l1":["SOLUSDT","AVAXUSDT"],"meme":["DOGEUSDT","PEPEUSDT"]}
+// 返回 symbol -> 分组名，同一币种只能属于一个分组
+func ParseCorrelationGroups(raw string) (map[string]string, error) {
+	groupOf := make(map[string]string)
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return groupOf, nil
+	}
+
+	var groups map[string][]string
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return groupOf, fmt.Errorf("解析相关性分组失败: %w", err)
+	}
+	for group, symbols := range groups {
+		for _, symbol := range symbols {
+			symbol = normalizeSymbol(symbol)
+			if existing, ok := groupOf[symbol]; ok && existing != group {
+				return groupOf, fmt.Errorf("币种 %s 同时属于分组 %s 和 %s", symbol, existing, group)
+			}
+			groupOf[symbol] = group
+		}
+	}
+	return groupOf, nil
+}
+
+// checkGroupExposure 检查开仓后同一相关性分组的方向性敞口（多头名义价值 - 空头名义价值）是否超限
+func (at *AutoTrader) checkGroupExposure(symbol, side string, notional float64, positions []map[string]interface{}) error {
+	limitPct := at.config.MaxGroupExposurePct
+	group, ok := at.config.CorrelationGroups[symbol]
+	if limitPct <= 0 || !ok {
+		return nil
+	}
+
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return fmt.Errorf("获取账户余额失败: %w", err)
+	}
+	wallet, _ := balance["totalWalletBalance"].(float64)
+	unrealized, _ := balance["totalUnrealizedProfit"].(float64)
+	limit := (wallet + unrealized) * limitPct / 100
+
+	exposure := 0.0
+	for _, pos := range positions {
+		posSymbol, _ := pos["symbol"].(string)
+		if at.config.CorrelationGroups[posSymbol] != group {
+			continue
+		}
+		quantity, _ := pos["positionAmt"].(float64)
+		markPrice, _ := pos["markPrice"].(float64)
+		value := math.Abs(quantity) * markPrice
+		if pos["side"] == "short" {
+			value = -value
+		}
+		exposure += value
+	}
+
+	after := exposure + notional
+	if side == "short" {
+		after = exposure - notional
+	}
+	// 只拦截扩大敞口的开仓，对冲方向的开仓始终允许
+	if math.Abs(after) > limit && math.Abs(after) > math.Abs(exposure) {
+		return fmt.Errorf("❌ 相关性分组 %s 开仓后方向性敞口 %.2f USDT 超过上限 %.2f USDT（净值的 %.1f%%），拒绝开仓 %s",
+			group, after, limit, limitPct, symbol)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/events"
+	"strings"
+)
+
+// authFailureThreshold 连续多少个周期因API认证失败无法获取账户时发送紧急告警
+const authFailureThreshold = 3
+
+// AlertResolved 紧急告警恢复时事件的 Action（告警渠道据此关闭对应告警）
+const AlertResolved = "resolved"
+
+// authErrorMarkers 各交易所API认证失败的错误特征（密钥无效/过期、签名错误、IP不在白名单、权限不足）
+var authErrorMarkers = []string{
+	"code=-2014", "code=-2015", "code=-1022", "invalid api-key", "api-key format invalid", "signature for this request is not valid", // 币安/Aster
+	"invalid_key", "invalid_signature", "forbidden", "unauthorized", "http 401", "http 403", // Gate及HTTP状态码
+	"user or api wallet", // Hyperliquid: User or API Wallet does not exist
+}
+
+// IsAuthError 是否为API认证失败（按错误信息匹配，不区分大小写）
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range authErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackAuthFailure 记录获取账户的结果：连续认证失败达到阈值时发布 AuthFailure 告警，恢复后发布恢复事件
+func (at *AutoTrader) trackAuthFailure(err error) {
+	if !IsAuthError(err) {
+		if at.authAlerted {
+			at.authAlerted = false
+			at.publishEvent(events.Event{Type: events.AuthFailure, Action: AlertResolved, Message: fmt.Sprintf("%s API认证已恢复", at.name)})
+		}
+		at.authFailures = 0
+		return
+	}
+	at.authFailures++
+	if at.authFailures < authFailureThreshold || at.authAlerted {
+		return
+	}
+	at.authAlerted = true
+	msg := fmt.Sprintf("%s 连续 %d 个周期API认证失败，无法获取账户及持仓（请检查API密钥、IP白名单及权限）: %v", at.name, at.authFailures, err)
+	log.Printf("🚨 %s", msg)
+	at.publishEvent(events.Event{Type: events.AuthFailure, Message: msg})
+}
+
+// checkLiquidationRisk 标记价格距强平价格低于 LiquidationAlertPct 时发布 LiquidationRisk 告警（每个持仓告警一次，恢复后发布恢复事件）
+func (at *AutoTrader) checkLiquidationRisk(positions []decision.PositionInfo) []string {
+	threshold := at.config.LiquidationAlertPct
+	if threshold <= 0 {
+		return nil
+	}
+	var messages []string
+	open := make(map[string]bool, len(positions))
+	for _, pos := range positions {
+		posKey := pos.Symbol + "_" + pos.Side
+		open[posKey] = true
+		if pos.LiquidationPrice <= 0 || pos.MarkPrice <= 0 {
+			continue
+		}
+		distance := LiquidationDistancePct(pos.Side, pos.MarkPrice, pos.LiquidationPrice)
+		if distance >= threshold {
+			if at.liqRiskAlerted[posKey] {
+				delete(at.liqRiskAlerted, posKey)
+				at.publishEvent(events.Event{Type: events.LiquidationRisk, Action: AlertResolved, Symbol: pos.Symbol, Side: pos.Side, Price: pos.MarkPrice,
+					Message: fmt.Sprintf("%s %s仓距强平价已恢复至 %.2f%%", pos.Symbol, sideName(pos.Side), distance)})
+			}
+			continue
+		}
+		if at.liqRiskAlerted[posKey] {
+			continue
+		}
+		at.liqRiskAlerted[posKey] = true
+		msg := fmt.Sprintf("%s %s仓接近强平: 标记价格 %.4f，强平价格 %.4f，距离 %.2f%%（告警阈值 %.2f%%）",
+			pos.Symbol, sideName(pos.Side), pos.MarkPrice, pos.LiquidationPrice, distance, threshold)
+		log.Printf("🚨 %s", msg)
+		messages = append(messages, "🚨 "+msg)
+		at.publishEvent(events.Event{Type: events.LiquidationRisk, Symbol: pos.Symbol, Side: pos.Side, Price: pos.MarkPrice, Quantity: pos.Quantity, Message: msg})
+	}
+	// 已平仓的持仓关闭告警
+	for posKey := range at.liqRiskAlerted {
+		if open[posKey] {
+			continue
+		}
+		delete(at.liqRiskAlerted, posKey)
+		i := strings.LastIndex(posKey, "_")
+		symbol, side := posKey[:i], posKey[i+1:]
+		at.publishEvent(events.Event{Type: events.LiquidationRisk, Action: AlertResolved, Symbol: symbol, Side: side, Message: fmt.Sprintf("%s %s仓已平仓", symbol, sideName(side))})
+	}
+	return messages
+}
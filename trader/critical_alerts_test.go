@@ -0,0 +1,65 @@
+package trader
+
+import (
+	"errors"
+	"nofx/decision"
+	"nofx/events"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIsAuthError(t *testing.T) {
+	for _, msg := range []string{
+		"<APIError> code=-2015, msg=Invalid API-key, IP, or permissions for action.",
+		"获取余额失败: HTTP 401: {\"code\":-2014}",
+		"INVALID_KEY: Invalid key provided",
+		"User or API Wallet 0xabc does not exist.",
+	} {
+		if !IsAuthError(errors.New(msg)) {
+			t.Errorf("IsAuthError(%q) = false", msg)
+		}
+	}
+	for _, err := range []error{nil, errors.New("dial tcp: i/o timeout"), errors.New("<APIError> code=-2011, msg=Unknown order sent.")} {
+		if IsAuthError(err) {
+			t.Errorf("IsAuthError(%v) = true", err)
+		}
+	}
+}
+
+func TestCheckLiquidationRisk(t *testing.T) {
+	bus := events.NewBus()
+	var mu sync.Mutex
+	var got []events.Event
+	bus.Subscribe("test", func(e events.Event) {
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+	}, events.LiquidationRisk)
+
+	at := &AutoTrader{id: "t1", config: AutoTraderConfig{LiquidationAlertPct: 5}, eventBus: bus, liqRiskAlerted: make(map[string]bool)}
+	near := []decision.PositionInfo{{Symbol: "BTCUSDT", Side: "long", MarkPrice: 100, LiquidationPrice: 97}}
+	if msgs := at.checkLiquidationRisk(near); len(msgs) != 1 {
+		t.Fatalf("expected one alert, got %v", msgs)
+	}
+	if msgs := at.checkLiquidationRisk(near); len(msgs) != 0 {
+		t.Fatalf("alert should not repeat, got %v", msgs)
+	}
+	at.checkLiquidationRisk(nil) // 已平仓
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 || got[0].Action != "" || got[1].Action != AlertResolved || got[1].Symbol != "BTCUSDT" || got[1].Side != "long" {
+		t.Fatalf("events = %+v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package trader
+
+import (
+	"nofx/calendar"
+	"testing"
+	"time"
+)
+
+func TestLLMBudgetFollowsDayLocation(t *testing.T) {
+	loc, err := calendar.LoadDayLocation("+08:00")
+	if err != nil {
+		t.Fatalf("LoadDayLocation: %v", err)
+	}
+	calendar.SetDayLocation(loc)
+	defer calendar.SetDayLocation(time.UTC)
+
+	now := time.Now()
+	today := now.In(loc).Format("2006-01-02")
+	yesterday := now.In(loc).AddDate(0, 0, -1).Format("2006-01-02")
+	at := &AutoTrader{
+		config: AutoTraderConfig{LLMDailyBudgetUSD: 1},
+		llmUsage: map[string]*LLMDailyUsage{
+			yesterday: {Date: yesterday, LLMModelUsage: LLMModelUsage{CostUSD: 5}},
+		},
+	}
+	if exceeded, _ := at.llmBudgetExceeded(); exceeded {
+		t.Fatal("previous trading day's spend counted against today's budget")
+	}
+	at.llmUsage[today] = &LLMDailyUsage{Date: today, LLMModelUsage: LLMModelUsage{CostUSD: 2}}
+	if exceeded, spent := at.llmBudgetExceeded(); !exceeded || spent != 2 {
+		t.Fatalf("exceeded=%v spent=%v", exceeded, spent)
+	}
+	if cost := at.llmCostSince(calendar.DayStart(now)); cost != 2 {
+		t.Fatalf("llmCostSince(today) = %v", cost)
+	}
+
+	// UTC 16:30 已是 +08:00 的次日 00:30
+	late := time.Date(2026, 3, 1, 16, 30, 0, 0, time.UTC)
+	if calendar.DayKey(late) != "2026-03-02" || calendar.SameDay(late, late.Add(-time.Hour)) {
+		t.Fatalf("DayKey(%v) = %s", late, calendar.DayKey(late))
+	}
+	if next := calendar.NextAt(late, 8); !next.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NextAt = %v", next)
+	}
+}
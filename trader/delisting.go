@@ -0,0 +1,132 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// delistingRefresh 下架计划缓存时长
+const delistingRefresh = 10 * time.Minute
+
+// binancePerpetualDeliveryDate 币安永续合约的默认交割日（2100-12-25），早于该日期表示已安排下架
+const binancePerpetualDeliveryDate = 4133404800000
+
+// DelistingInfo 合约下架/交割计划
+type DelistingInfo struct {
+	Symbol        string    `json:"symbol"`
+	Status        string    `json:"status"`
+	DelistingTime time.Time `json:"delisting_time"` // 计划下架/结算时间（零值=未公布，视为随时可能结算）
+}
+
+// DelistingProvider 可查询合约下架/交割计划的交易器（可选接口）
+type DelistingProvider interface {
+	// GetDelistings 获取所有已安排下架或交割的合约
+	GetDelistings() ([]DelistingInfo, error)
+}
+
+// GetDelistings 获取Gate标记为下架中（in_delisting）的合约
+func (t *GateTrader) GetDelistings() ([]DelistingInfo, error) {
+	contracts, _, err := t.client.FuturesApi.ListFuturesContracts(t.getClientCtx(), "usdt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取合约列表失败: %w", err)
+	}
+	var result []DelistingInfo
+	for _, c := range contracts {
+		if !c.InDelisting && c.DelistingTime == 0 {
+			continue
+		}
+		info := DelistingInfo{Symbol: c.Name, Status: c.Status}
+		if c.DelistingTime > 0 {
+			info.DelistingTime = time.Unix(c.DelistingTime, 0)
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// GetDelistings 获取币安已安排交割日或处于结算状态的合约
+func (t *FuturesTrader) GetDelistings() ([]DelistingInfo, error) {
+	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取交易规则失败: %w", err)
+	}
+	var result []DelistingInfo
+	for _, s := range exchangeInfo.Symbols {
+		scheduled := s.DeliveryDate > 0 && s.DeliveryDate < binancePerpetualDeliveryDate
+		if !scheduled && (s.Status == "TRADING" || s.Status == "PENDING_TRADING") {
+			continue
+		}
+		info := DelistingInfo{Symbol: s.Symbol, Status: s.Status}
+		if scheduled {
+			info.DelistingTime = time.UnixMilli(s.DeliveryDate)
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// delistingCacheEntry 单个交易所的下架计划缓存
+type delistingCacheEntry struct {
+	fetchedAt time.Time
+	infos     map[string]DelistingInfo
+}
+
+var delistingCache = struct {
+	sync.Mutex
+	entries map[string]*delistingCacheEntry
+}{entries: make(map[string]*delistingCacheEntry)}
+
+// contractKey 统一 BTC_USDT / btcusdt 等写法
+func contractKey(symbol string) string {
+	return strings.ToUpper(strings.ReplaceAll(symbol, "_", ""))
+}
+
+// Delistings 获取交易所的下架计划（按合约索引，同一交易所共享缓存，请求失败时沿用上次结果）
+// 交易器不支持 DelistingProvider 时返回nil
+func Delistings(t Trader, exchange string) map[string]DelistingInfo {
+	provider, ok := t.(DelistingProvider)
+	if !ok {
+		return nil
+	}
+
+	delistingCache.Lock()
+	defer delistingCache.Unlock()
+	entry := delistingCache.entries[exchange]
+	if entry != nil && time.Since(entry.fetchedAt) < delistingRefresh {
+		return entry.infos
+	}
+	if entry == nil {
+		entry = &delistingCacheEntry{}
+		delistingCache.entries[exchange] = entry
+	}
+	entry.fetchedAt = time.Now() // 失败后同样等待一个刷新间隔再重试
+
+	list, err := provider.GetDelistings()
+	if err != nil {
+		log.Printf("⚠️  获取 %s 合约下架计划失败: %v", exchange, err)
+		return entry.infos
+	}
+	infos := make(map[string]DelistingInfo, len(list))
+	for _, info := range list {
+		infos[contractKey(info.Symbol)] = info
+	}
+	entry.infos = infos
+	return infos
+}
+
+// checkDelisting 合约已安排下架或交割时拒绝开仓
+func (at *AutoTrader) checkDelisting(symbol string) error {
+	info, ok := Delistings(at.trader, at.exchange)[contractKey(symbol)]
+	if !ok {
+		return nil
+	}
+	when := "时间未公布"
+	if !info.DelistingTime.IsZero() {
+		when = info.DelistingTime.Local().Format("01-02 15:04")
+	}
+	return fmt.Errorf("❌ %s 已安排下架/交割（%s），拒绝开仓", symbol, when)
+}
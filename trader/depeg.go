@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/depeg"
+)
+
+// settlementCoin 保证金结算币种（Hyperliquid为USDC，其余交易所为USDT）
+func (at *AutoTrader) settlementCoin() string {
+	if at.exchange == "hyperliquid" {
+		return "USDC"
+	}
+	return "USDT"
+}
+
+// checkDepeg 结算稳定币脱锚时拒绝开仓
+func (at *AutoTrader) checkDepeg(symbol string) error {
+	if s, ok := depeg.Check(at.settlementCoin()); ok && s.Alert {
+		return fmt.Errorf("❌ 结算币种 %s 脱锚（%.4f USD，偏离 %.2f%%），拒绝开仓 %s", s.Coin, s.Price, s.DeviationPct, symbol)
+	}
+	return nil
+}
+
+// reduceForDepeg 结算稳定币偏离超过减仓阈值时，按比例减少本策略的持仓（每次脱锚只执行一次，恢复后重置）
+func (at *AutoTrader) reduceForDepeg(positions []decision.PositionInfo) string {
+	s, ok := depeg.Check(at.settlementCoin())
+	if !ok || !s.Reduce {
+		at.depegReduced = false
+		return ""
+	}
+	reducePct := depeg.ReducePct()
+	if at.depegReduced || reducePct <= 0 || len(positions) == 0 {
+		return ""
+	}
+	at.depegReduced = true
+
+	if at.config.DryRun {
+		return fmt.Sprintf("WOULD reduce %d positions by %.0f%% for %s depeg (%.4f USD)", len(positions), reducePct, s.Coin, s.Price)
+	}
+
+	log.Printf("🪙 结算币种 %s 脱锚（%.4f USD，偏离 %.2f%%），持仓减少 %.0f%%", s.Coin, s.Price, s.DeviationPct, reducePct)
+	reduced := 0
+	for _, pos := range positions {
+		if err := at.checkCloseOwnership(pos.Symbol, pos.Side); err != nil {
+			continue
+		}
+		quantity := pos.Quantity * reducePct / 100
+
+		unlock := at.lockSymbol(pos.Symbol)
+		_, err := PlaceOrder(at.trader, pos.Symbol, "close_"+pos.Side, quantity, 0)
+		unlock()
+
+		if err != nil {
+			log.Printf("  ⚠️ %s %s仓减仓失败: %v", pos.Symbol, sideName(pos.Side), err)
+			continue
+		}
+		reduced++
+		log.Printf("  ✓ %s %s仓减仓 %.4f", pos.Symbol, sideName(pos.Side), quantity)
+	}
+	return fmt.Sprintf("🪙 %s 脱锚（%.4f USD）：%d 个持仓减仓 %.0f%%", s.Coin, s.Price, reduced, reducePct)
+}
@@ -0,0 +1,111 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"time"
+)
+
+// previewDecisionWithRecord 预演模式：按实盘逻辑计算决策结果并记录，但不发送任何订单
+func (at *AutoTrader) previewDecisionWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
+	actionRecord.DryRun = true
+
+	switch decision.Action {
+	case "open_long", "open_short":
+		return at.previewOpen(decision, actionRecord)
+	case "close_long", "close_short":
+		return at.previewClose(decision, actionRecord)
+	case "hold", "wait":
+		return nil
+	default:
+		return fmt.Errorf("未知的action: %s", decision.Action)
+	}
+}
+
+// previewOpen 预演开仓（与实盘相同的持仓叠加和成本检查）
+func (at *AutoTrader) previewOpen(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
+	side := "long"
+	if decision.Action == "open_short" {
+		side = "short"
+	}
+
+	positions, err := at.getPositions()
+	if err == nil {
+		for _, pos := range positions {
+			if pos["symbol"] == decision.Symbol && pos["side"] == side {
+				return fmt.Errorf("❌ %s 已有%s仓，实盘将拒绝开仓", decision.Symbol, sideName(side))
+			}
+		}
+	}
+
+	marketData, err := market.Get(decision.Symbol)
+	if err != nil {
+		return err
+	}
+
+	quantity := decision.PositionSizeUSD / marketData.CurrentPrice
+	actionRecord.Quantity = quantity
+	actionRecord.Price = marketData.CurrentPrice
+
+	if err := at.checkExpectedCost(decision, side, quantity, marketData); err != nil {
+		return err
+	}
+
+	marginMode := "逐仓"
+	if at.isCrossMarginFor(decision.Symbol) {
+		marginMode = "全仓"
+	}
+	actionRecord.Preview = fmt.Sprintf("WOULD open %s %.4f %s @ market (≈%.4f), %dx %s, SL %.4f, TP %.4f",
+		side, quantity, decision.Symbol, marketData.CurrentPrice, decision.Leverage, marginMode,
+		decision.StopLoss, decision.TakeProfit)
+	log.Printf("  🧪 [预演] %s", actionRecord.Preview)
+
+	// 记录开仓时间，使预演中的持仓时长统计与实盘一致
+	at.positionFirstSeenTime[decision.Symbol+"_"+side] = time.Now().UnixMilli()
+	return nil
+}
+
+// previewClose 预演平仓
+func (at *AutoTrader) previewClose(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
+	side := "long"
+	if decision.Action == "close_short" {
+		side = "short"
+	}
+
+	positions, err := at.getPositions()
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	for _, pos := range positions {
+		if pos["symbol"] != decision.Symbol || pos["side"] != side {
+			continue
+		}
+
+		quantity, _ := pos["positionAmt"].(float64)
+		quantity = math.Abs(quantity)
+		markPrice, _ := pos["markPrice"].(float64)
+		unrealized, _ := pos["unRealizedProfit"].(float64)
+		actionRecord.Quantity = quantity
+		actionRecord.Price = markPrice
+
+		actionRecord.Preview = fmt.Sprintf("WOULD close %s %.4f %s @ market (≈%.4f), 未实现盈亏 %+.2f USDT",
+			side, quantity, decision.Symbol, markPrice, unrealized)
+		log.Printf("  🧪 [预演] %s", actionRecord.Preview)
+		return nil
+	}
+
+	return fmt.Errorf("没有找到 %s 的%s仓", decision.Symbol, sideName(side))
+}
+
+// sideName 持仓方向的中文名称
+func sideName(side string) string {
+	if side == "short" {
+		return "空"
+	}
+	return "多"
+}
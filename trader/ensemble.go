@@ -0,0 +1,64 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/mcp"
+	"strings"
+)
+
+// EnsembleModel 参与投票的附加AI模型
+type EnsembleModel struct {
+	Name            string
+	Provider        string // deepseek / qwen / custom
+	APIKey          string
+	CustomAPIURL    string
+	CustomModelName string
+}
+
+// SetEnsemble 配置多模型投票（主模型始终参与投票），models 为空时关闭
+func (at *AutoTrader) SetEnsemble(models []EnsembleModel, mode string) {
+	at.ensembleMode = mode
+	at.ensemble = nil
+	if len(models) == 0 {
+		return
+	}
+
+	at.ensemble = []decision.EnsembleMember{{Name: at.aiModel + "/" + at.mcpClient.Model, Client: at.mcpClient}}
+	for _, m := range models {
+		logger.RegisterSecret(m.APIKey)
+		client := mcp.New()
+		switch m.Provider {
+		case "custom":
+			client.SetCustomAPI(m.CustomAPIURL, m.APIKey, m.CustomModelName)
+		case "qwen":
+			client.SetQwenAPIKey(m.APIKey, m.CustomAPIURL, m.CustomModelName)
+		default:
+			client.SetDeepSeekAPIKey(m.APIKey, m.CustomAPIURL, m.CustomModelName)
+		}
+		client.OnUsage = at.recordLLMUsage
+		at.ensemble = append(at.ensemble, decision.EnsembleMember{Name: m.Name + "/" + client.Model, Client: client})
+	}
+	if at.ensembleMode == "" {
+		at.ensembleMode = decision.EnsembleMajority
+	}
+	log.Printf("🗳️ [%s] 多模型投票已启用（%s）: %s", at.name, at.ensembleMode, strings.Join(at.ensembleNames(), ", "))
+}
+
+// ensembleNames 参与投票的模型名称
+func (at *AutoTrader) ensembleNames() []string {
+	names := make([]string, 0, len(at.ensemble))
+	for _, m := range at.ensemble {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// requestAIDecision 获取AI决策（配置了多模型投票时合并各模型的决策）
+func (at *AutoTrader) requestAIDecision(ctx *decision.Context) (*decision.FullDecision, error) {
+	if len(at.ensemble) > 0 {
+		return decision.GetEnsembleDecision(ctx, at.ensemble, at.ensembleMode, at.customPrompt, at.overrideBasePrompt, at.systemPromptTemplate)
+	}
+	return decision.GetFullDecisionWithCustomPrompt(ctx, at.mcpClient, at.customPrompt, at.overrideBasePrompt, at.systemPromptTemplate)
+}
@@ -0,0 +1,262 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/market"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ChildOrder 执行算法下达的一笔子订单
+type ChildOrder struct {
+	Time          time.Time `json:"time"`
+	Quantity      float64   `json:"quantity"`
+	Price         float64   `json:"price"` // 成交均价（交易所不支持查询时为下单时的参考价）
+	OrderID       int64     `json:"order_id,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	Maker         bool      `json:"maker"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// ExecutionResult 一笔开仓的执行结果
+type ExecutionResult struct {
+	Policy       string       `json:"policy"`
+	Symbol       string       `json:"symbol"`
+	Side         string       `json:"side"`
+	Requested    float64      `json:"requested"`
+	Filled       float64      `json:"filled"`
+	AvgPrice     float64      `json:"avg_price"`     // 子订单参考价按数量加权
+	ArrivalPrice float64      `json:"arrival_price"` // 开始执行时的价格
+	StartedAt    time.Time    `json:"started_at"`
+	FinishedAt   time.Time    `json:"finished_at"`
+	Children     []ChildOrder `json:"children"`
+}
+
+// addChild 记录一笔子订单
+func (r *ExecutionResult) addChild(child ChildOrder) {
+	r.Children = append(r.Children, child)
+	if child.Error != "" || child.Quantity <= 0 {
+		return
+	}
+	if child.Price > 0 {
+		r.AvgPrice = (r.AvgPrice*r.Filled + child.Price*child.Quantity) / (r.Filled + child.Quantity)
+	}
+	r.Filled += child.Quantity
+}
+
+// orderMap 转换为交易器下单接口的返回格式（订单ID取最后一笔子订单）
+func (r *ExecutionResult) orderMap() map[string]interface{} {
+	order := map[string]interface{}{"symbol": r.Symbol, "filledQty": r.Filled}
+	for i := len(r.Children) - 1; i >= 0; i-- {
+		if r.Children[i].Error == "" && r.Children[i].OrderID != 0 {
+			order["orderId"] = r.Children[i].OrderID
+			order["clientOrderId"] = r.Children[i].ClientOrderID
+			break
+		}
+	}
+	return order
+}
+
+// Executor 开仓执行算法（side: long / short）
+type Executor interface {
+	Name() string
+	Execute(t Trader, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error)
+}
+
+// MarketExecutor 一笔市价单立即成交（默认执行方式）
+type MarketExecutor struct{}
+
+// Name 实现 Executor
+func (MarketExecutor) Name() string { return "market" }
+
+// Execute 实现 Executor
+func (MarketExecutor) Execute(t Trader, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error) {
+	result := &ExecutionResult{Policy: "market", Symbol: symbol, Side: side, Requested: quantity, StartedAt: time.Now()}
+	result.ArrivalPrice, _ = t.GetMarketPrice(symbol)
+	child, err := marketChild(t, symbol, side, quantity, leverage, result.ArrivalPrice)
+	result.addChild(child)
+	result.FinishedAt = time.Now()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TWAPExecutor 在Duration内等时间间隔分Slices笔市价单执行
+type TWAPExecutor struct {
+	Duration time.Duration
+	Slices   int
+}
+
+// Name 实现 Executor
+func (e *TWAPExecutor) Name() string { return "twap" }
+
+// Execute 实现 Executor
+func (e *TWAPExecutor) Execute(t Trader, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error) {
+	return executeSlices(e.Name(), t, symbol, side, quantity, leverage, e.Duration, e.Slices, market.UniformProfile())
+}
+
+// VWAPExecutor 在Duration内分Slices笔市价单执行，每笔数量与该时段的历史日内成交量成正比
+type VWAPExecutor struct {
+	Duration     time.Duration
+	Slices       int
+	LookbackDays int // 统计成交量分布的历史天数（默认7）
+}
+
+// Name 实现 Executor
+func (e *VWAPExecutor) Name() string { return "vwap" }
+
+// Execute 实现 Executor
+func (e *VWAPExecutor) Execute(t Trader, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error) {
+	profile, err := market.GetVolumeProfile(symbol, e.LookbackDays)
+	if err != nil {
+		log.Printf("  ⚠ 获取 %s 成交量分布失败，按TWAP均匀执行: %v", symbol, err)
+		profile = market.UniformProfile()
+	}
+	return executeSlices(e.Name(), t, symbol, side, quantity, leverage, e.Duration, e.Slices, profile)
+}
+
+// sliceWeights 按成交量分布计算从start开始每个时段的数量占比
+func sliceWeights(profile market.VolumeProfile, start time.Time, duration time.Duration, slices int) []float64 {
+	step := duration / time.Duration(slices)
+	weights := make([]float64, slices)
+	total := 0.0
+	for i := range weights {
+		from := start.Add(step * time.Duration(i))
+		weights[i] = profile.Weight(from, from.Add(step))
+		total += weights[i]
+	}
+	for i := range weights {
+		if total > 0 {
+			weights[i] /= total
+		} else {
+			weights[i] = 1 / float64(slices)
+		}
+	}
+	return weights
+}
+
+// executeSlices 按权重分时段下达市价子订单（数量按交易所精度取整后为0的部分并入下一笔，最后一笔补齐剩余数量）
+// 子订单失败时停止执行，已成交部分作为结果返回；一笔都未成交时返回错误
+func executeSlices(policy string, t Trader, symbol, side string, quantity float64, leverage int, duration time.Duration, slices int, profile market.VolumeProfile) (*ExecutionResult, error) {
+	if slices <= 1 || duration <= 0 {
+		return MarketExecutor{}.Execute(t, symbol, side, quantity, leverage)
+	}
+	start := time.Now()
+	result := &ExecutionResult{Policy: policy, Symbol: symbol, Side: side, Requested: quantity, StartedAt: start}
+	result.ArrivalPrice, _ = t.GetMarketPrice(symbol)
+	weights := sliceWeights(profile, start, duration, slices)
+	step := duration / time.Duration(slices)
+	log.Printf("  ⏱ %s 执行 %s %s %.6f：%d 笔，间隔 %s", policy, symbol, sideName(side), quantity, slices, step)
+
+	pending := 0.0
+	for i, weight := range weights {
+		if i > 0 {
+			time.Sleep(time.Until(start.Add(step * time.Duration(i))))
+		}
+		size := pending + quantity*weight
+		if i == len(weights)-1 {
+			size = quantity - result.Filled
+		}
+		if formatted, err := t.FormatQuantity(symbol, size); err == nil {
+			if rounded, _ := strconv.ParseFloat(formatted, 64); rounded <= 0 {
+				pending = size
+				continue
+			}
+		}
+		pending = 0
+
+		price, _ := t.GetMarketPrice(symbol)
+		child, err := marketChild(t, symbol, side, size, leverage, price)
+		result.addChild(child)
+		if err != nil {
+			log.Printf("  ⚠ %s 第 %d/%d 笔子订单失败，停止执行: %v", policy, i+1, slices, err)
+			break
+		}
+	}
+	result.FinishedAt = time.Now()
+	if result.Filled <= 0 {
+		return nil, fmt.Errorf("%s 执行失败，没有成交", policy)
+	}
+	log.Printf("  ✓ %s 执行完成：成交 %.6f / %.6f，均价 %.4f（开始时 %.4f）", policy, result.Filled, quantity, result.AvgPrice, result.ArrivalPrice)
+	return result, nil
+}
+
+// marketChild 下达一笔市价开仓子订单
+func marketChild(t Trader, symbol, side string, quantity float64, leverage int, price float64) (ChildOrder, error) {
+	child := ChildOrder{Time: time.Now(), Quantity: quantity, Price: price}
+	order, err := PlaceOrder(t, symbol, "open_"+side, quantity, leverage)
+	if err != nil {
+		child.Error = err.Error()
+		return child, err
+	}
+	child.OrderID, _ = order["orderId"].(int64)
+	child.ClientOrderID, _ = order["clientOrderId"].(string)
+	filled, known := orderFill(t, symbol, order, &child)
+	// 开启最大滑点保护时市价单为IOC限价单，超出限价的部分不成交
+	if known && filled < quantity {
+		child.Quantity = filled
+		if filled <= 0 {
+			err := fmt.Errorf("%s 超出最大滑点，订单未成交", symbol)
+			child.Error = err.Error()
+			return child, err
+		}
+		log.Printf("  ⚠ %s 超出最大滑点，成交 %.6f / %.6f", symbol, filled, quantity)
+	}
+	return child, nil
+}
+
+// orderFill 已结束订单的成交数量：下单结果包含 filledQty（如Gate的IOC单）时直接使用，否则按订单ID查询（同时更新成交均价）
+// 无法确定时返回 known=false
+func orderFill(t Trader, symbol string, order map[string]interface{}, child *ChildOrder) (float64, bool) {
+	if filled, ok := order["filledQty"].(float64); ok {
+		return filled, true
+	}
+	orders, ok := t.(PassiveOrderTrader)
+	if !ok || child.OrderID == 0 {
+		return 0, false
+	}
+	filled, avgPrice, done, err := orders.GetOrderFill(symbol, child.OrderID)
+	if err != nil {
+		return 0, false
+	}
+	if avgPrice > 0 {
+		child.Price = avgPrice
+	}
+	return filled, done
+}
+
+// openPosition 按配置的执行算法开仓（未配置时一笔市价单）并记录执行质量，返回值中 filledQty 为实际成交数量
+func (at *AutoTrader) openPosition(symbol, side string, quantity float64, leverage int) (map[string]interface{}, error) {
+	executor := at.config.EntryExecutor
+	if executor == nil {
+		executor = MarketExecutor{}
+	}
+	startedAt := time.Now()
+	var result *ExecutionResult
+	var err error
+	if guard := at.config.MarketGuard; guard != nil {
+		reading, checkErr := guard.Check(at.trader, symbol)
+		if checkErr != nil {
+			log.Printf("  ⚠ %s 行情保护检查失败，按原方式执行: %v", symbol, checkErr)
+		} else if reading.Reason != "" {
+			if guard.Action != GuardActionLimit {
+				return nil, fmt.Errorf("❌ %s %s，放弃市价开仓", symbol, reading.Reason)
+			}
+			log.Printf("  🛡 %s %s，改为限价开仓", symbol, reading.Reason)
+			executor = guardLimitExecutor{guard: guard, reading: reading}
+		}
+	}
+	span := at.startSpan("order_executor", attribute.String("symbol", symbol), attribute.String("side", side), attribute.String("executor", executor.Name()))
+	result, err = executor.Execute(at.trader, symbol, side, quantity, leverage)
+	span.End(err)
+	if err != nil {
+		at.recordExecution(&ExecutionResult{Policy: executor.Name(), Symbol: symbol, Side: side, Requested: quantity, StartedAt: startedAt, FinishedAt: time.Now()}, err)
+		return nil, err
+	}
+	at.recordExecution(result, nil)
+	return result.orderMap(), nil
+}
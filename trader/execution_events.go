@@ -0,0 +1,180 @@
+package trader
+
+import (
+	"math"
+	"nofx/events"
+)
+
+// trackedPosition 用于从持仓变化推断成交和止盈止损触发
+type trackedPosition struct {
+	quantity      float64
+	markPrice     float64
+	stopLoss      float64
+	takeProfit    float64
+	clientOrderID string // 开仓单的clientOrderId（策略归属）
+	stopOrderID   string // 止损单的clientOrderId（持仓消失时据此确认是否止损离场）
+	pendingFill   bool   // 已下开仓单，尚未在持仓中观察到
+	closing       bool   // 已下平仓单，等待持仓消失
+}
+
+// SetEventBus 设置执行事件总线
+func (at *AutoTrader) SetEventBus(bus *events.Bus) {
+	at.eventBus = bus
+}
+
+// publishEvent 发布执行事件（未设置事件总线时忽略）
+func (at *AutoTrader) publishEvent(event events.Event) {
+	if at.eventBus == nil {
+		return
+	}
+	event.TraderID = at.id
+	event.Exchange = at.exchange
+	at.eventBus.Publish(event)
+}
+
+// trackOpenOrder 记录开仓单并发布下单事件
+func (at *AutoTrader) trackOpenOrder(symbol, side string, orderID int64, clientOrderID string, quantity, price, stopLoss, takeProfit float64) {
+	at.trackedPositions[symbol+"_"+side] = &trackedPosition{
+		clientOrderID: clientOrderID,
+		quantity:      quantity,
+		markPrice:     price,
+		stopLoss:      stopLoss,
+		takeProfit:    takeProfit,
+		pendingFill:   true,
+	}
+	at.publishEvent(events.Event{
+		Type:          events.OrderPlaced,
+		Symbol:        symbol,
+		Side:          side,
+		Action:        "open_" + side,
+		Quantity:      quantity,
+		Price:         price,
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+	})
+}
+
+// trackCloseOrder 记录平仓单并发布下单及撤单事件（平仓后交易器会撤销止盈止损挂单）
+func (at *AutoTrader) trackCloseOrder(symbol, side string, orderID int64, clientOrderID string, price float64) {
+	posKey := symbol + "_" + side
+	tracked, ok := at.trackedPositions[posKey]
+	if !ok {
+		tracked = &trackedPosition{markPrice: price}
+		at.trackedPositions[posKey] = tracked
+	}
+	tracked.closing = true
+	if clientOrderID != "" {
+		tracked.clientOrderID = clientOrderID
+	}
+
+	at.publishEvent(events.Event{
+		Type:          events.OrderPlaced,
+		Symbol:        symbol,
+		Side:          side,
+		Action:        "close_" + side,
+		Quantity:      tracked.quantity,
+		Price:         price,
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+	})
+	at.publishEvent(events.Event{
+		Type:    events.OrderCancelled,
+		Symbol:  symbol,
+		Side:    side,
+		Message: "平仓后撤销止盈止损挂单",
+	})
+}
+
+// observePositions 对比本周期与上周期持仓，发布成交、止盈、止损事件
+func (at *AutoTrader) observePositions(positions []map[string]interface{}) {
+	current := make(map[string]bool)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		quantity, _ := pos["positionAmt"].(float64)
+		entryPrice, _ := pos["entryPrice"].(float64)
+		markPrice, _ := pos["markPrice"].(float64)
+		posKey := symbol + "_" + side
+		current[posKey] = true
+
+		tracked, ok := at.trackedPositions[posKey]
+		if !ok {
+			// 外部开仓或程序重启前已有的持仓
+			tracked = &trackedPosition{}
+			at.trackedPositions[posKey] = tracked
+		}
+		if tracked.pendingFill {
+			tracked.pendingFill = false
+			at.publishEvent(events.Event{
+				Type:          events.OrderFilled,
+				Symbol:        symbol,
+				Side:          side,
+				Action:        "open_" + side,
+				Quantity:      math.Abs(quantity),
+				Price:         entryPrice,
+				ClientOrderID: tracked.clientOrderID,
+			})
+		}
+		tracked.quantity = math.Abs(quantity)
+		tracked.markPrice = markPrice
+	}
+
+	for posKey, tracked := range at.trackedPositions {
+		if current[posKey] || tracked.pendingFill {
+			continue
+		}
+		delete(at.trackedPositions, posKey)
+
+		symbol, side := splitPositionKey(posKey)
+		at.releaseAllocation(symbol, side)
+		event := events.Event{
+			Symbol:        symbol,
+			Side:          side,
+			Quantity:      tracked.quantity,
+			Price:         tracked.markPrice,
+			ClientOrderID: tracked.clientOrderID,
+		}
+		switch {
+		case tracked.closing:
+			event.Type = events.OrderFilled
+			event.Action = "close_" + side
+		case at.stopOrderFilled(symbol, tracked):
+			event.Type = events.StopLossHit
+			event.Message = "止损单成交"
+			event.ClientOrderID = tracked.stopOrderID
+			at.recordStopOut(symbol, side, tracked)
+		case stopLossLikely(tracked):
+			// 无法确认止损单成交（交易所查询失败等），只通知，不开始冷却期
+			event.Type = events.StopLossHit
+			event.Message = "持仓消失，最后标记价格接近止损价（未确认止损单成交）"
+		case tracked.takeProfit > 0:
+			event.Type = events.TakeProfitHit
+			event.Message = "持仓消失，最后标记价格接近止盈价"
+		default:
+			// 未知止盈止损价格（外部平仓），无法判断原因
+			continue
+		}
+		at.publishEvent(event)
+	}
+}
+
+// stopLossLikely 根据持仓消失前的最后标记价格判断更可能触发的是止损
+func stopLossLikely(tracked *trackedPosition) bool {
+	if tracked.stopLoss <= 0 {
+		return false
+	}
+	if tracked.takeProfit <= 0 {
+		return true
+	}
+	return math.Abs(tracked.markPrice-tracked.stopLoss) <= math.Abs(tracked.markPrice-tracked.takeProfit)
+}
+
+// splitPositionKey 拆分 symbol_side 格式的持仓key
+func splitPositionKey(posKey string) (string, string) {
+	for i := len(posKey) - 1; i >= 0; i-- {
+		if posKey[i] == '_' {
+			return posKey[:i], posKey[i+1:]
+		}
+	}
+	return posKey, ""
+}
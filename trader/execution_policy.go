@@ -0,0 +1,122 @@
+package trader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 执行算法配置的默认参数
+const (
+	defaultPassiveWait   = 30 * time.Second
+	defaultSliceDuration = 30 * time.Minute
+	defaultSliceCount    = 6
+)
+
+// ParseExecutionPolicy 解析开仓执行算法配置，空字符串返回nil（一笔市价单）
+// 格式: 算法[:参数=值,...]，如 market、passive:wait=30s,offset_bps=1、twap:duration=30m,slices=6、vwap:duration=2h,slices=12,lookback_days=7
+func ParseExecutionPolicy(spec string) (Executor, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	name, rawParams, _ := strings.Cut(spec, ":")
+	params := make(map[string]string)
+	for _, item := range strings.Split(rawParams, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的执行算法参数: %s（格式应为 参数=值）", item)
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	var executor Executor
+	var err error
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "market":
+		executor = MarketExecutor{}
+	case "passive":
+		e := &PassiveExecutor{}
+		if e.Wait, err = policyDuration(params, "wait", defaultPassiveWait); err != nil {
+			return nil, err
+		}
+		if e.OffsetBps, err = policyFloat(params, "offset_bps", 0); err != nil {
+			return nil, err
+		}
+		executor = e
+	case "twap":
+		e := &TWAPExecutor{}
+		if e.Duration, err = policyDuration(params, "duration", defaultSliceDuration); err != nil {
+			return nil, err
+		}
+		if e.Slices, err = policyInt(params, "slices", defaultSliceCount); err != nil {
+			return nil, err
+		}
+		executor = e
+	case "vwap":
+		e := &VWAPExecutor{}
+		if e.Duration, err = policyDuration(params, "duration", defaultSliceDuration); err != nil {
+			return nil, err
+		}
+		if e.Slices, err = policyInt(params, "slices", defaultSliceCount); err != nil {
+			return nil, err
+		}
+		if e.LookbackDays, err = policyInt(params, "lookback_days", 7); err != nil {
+			return nil, err
+		}
+		executor = e
+	default:
+		return nil, fmt.Errorf("无效的执行算法: %s（可选: market, passive, twap, vwap）", name)
+	}
+	for key := range params {
+		return nil, fmt.Errorf("执行算法 %s 不支持参数: %s", executor.Name(), key)
+	}
+	return executor, nil
+}
+
+// policyDuration 读取并删除时长参数（如 30s、5m）
+func policyDuration(params map[string]string, key string, def time.Duration) (time.Duration, error) {
+	value, ok := params[key]
+	if !ok {
+		return def, nil
+	}
+	delete(params, key)
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的执行算法参数 %s: %s（如 30s、5m、1h）", key, value)
+	}
+	return d, nil
+}
+
+// policyInt 读取并删除正整数参数
+func policyInt(params map[string]string, key string, def int) (int, error) {
+	value, ok := params[key]
+	if !ok {
+		return def, nil
+	}
+	delete(params, key)
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("无效的执行算法参数 %s: %s（必须为正整数）", key, value)
+	}
+	return n, nil
+}
+
+// policyFloat 读取并删除非负数参数
+func policyFloat(params map[string]string, key string, def float64) (float64, error) {
+	value, ok := params[key]
+	if !ok {
+		return def, nil
+	}
+	delete(params, key)
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("无效的执行算法参数 %s: %s（必须为非负数）", key, value)
+	}
+	return f, nil
+}
@@ -0,0 +1,53 @@
+package trader
+
+import "time"
+
+// ExecutionRecorder 开仓执行质量的记录通道（可选，如写入数据库供执行质量报告统计）
+type ExecutionRecorder interface {
+	// RecordExecution 记录一笔开仓的执行结果（execErr非空表示执行失败，result中只有请求参数）
+	RecordExecution(traderID, exchange string, result *ExecutionResult, execErr error)
+}
+
+// SetExecutionRecorder 设置执行质量记录通道
+func (at *AutoTrader) SetExecutionRecorder(recorder ExecutionRecorder) {
+	at.executionRecorder = recorder
+}
+
+// MakerQty 以maker成交的数量
+func (r *ExecutionResult) MakerQty() float64 {
+	qty := 0.0
+	for _, child := range r.Children {
+		if child.Maker && child.Error == "" {
+			qty += child.Quantity
+		}
+	}
+	return qty
+}
+
+// SlippageBps 成交均价相对开始执行时价格的滑点（基点，正数=不利：做多买贵/做空卖便宜）
+func (r *ExecutionResult) SlippageBps() float64 {
+	if r.ArrivalPrice <= 0 || r.AvgPrice <= 0 {
+		return 0
+	}
+	slippage := (r.AvgPrice - r.ArrivalPrice) / r.ArrivalPrice * 1e4
+	if r.Side == "short" {
+		return -slippage
+	}
+	return slippage
+}
+
+// Duration 开始执行到执行完成的耗时
+func (r *ExecutionResult) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// recordExecution 异步记录执行结果（未设置记录通道时忽略）
+func (at *AutoTrader) recordExecution(result *ExecutionResult, execErr error) {
+	if at.executionRecorder == nil {
+		return
+	}
+	go at.executionRecorder.RecordExecution(at.id, at.exchange, result, execErr)
+}
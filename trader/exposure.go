@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"time"
+)
+
+// PositionExposure 单个持仓的标准化敞口
+type PositionExposure struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"`
+	Quantity         float64 `json:"quantity"`
+	Notional         float64 `json:"notional"` // 名义价值（USDT，空头为负）
+	Leverage         int     `json:"leverage"`
+	MarkPrice        float64 `json:"mark_price"`
+	LiquidationPrice float64 `json:"liquidation_price"`
+	LiqDistancePct   float64 `json:"liq_distance_pct"` // 标记价格距强平价格的百分比（交易所未返回强平价格时为0）
+	VaR95            float64 `json:"var_95"`           // 单独持仓的1日95% VaR（USDT）
+}
+
+// ExposureSnapshot 交易员的标准化敞口快照（供外部风控系统汇总多个实例的风险）
+type ExposureSnapshot struct {
+	TraderID      string             `json:"trader_id"`
+	TraderName    string             `json:"trader_name"`
+	Exchange      string             `json:"exchange"`
+	GeneratedAt   time.Time          `json:"generated_at"`
+	Equity        float64            `json:"equity"`
+	GrossExposure float64            `json:"gross_exposure"` // 总名义价值
+	NetExposure   float64            `json:"net_exposure"`   // 净名义价值（多-空）
+	Leverage      float64            `json:"leverage"`       // 有效杠杆（总名义价值/净值）
+	VaR95         float64            `json:"var_95"`         // 组合1日95% VaR（USDT）
+	VaR99         float64            `json:"var_99"`
+	Positions     []PositionExposure `json:"positions"`
+	SharedWith    []string           `json:"shared_with,omitempty"` // 共享同一交易所账户的其他交易员（持仓只统计一次）
+}
+
+// GetExposureSnapshot 按当前持仓重新计算风险报告并转换为敞口快照
+func (at *AutoTrader) GetExposureSnapshot() (*ExposureSnapshot, error) {
+	report, err := at.GetRiskReport(true)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &ExposureSnapshot{
+		TraderID:      at.id,
+		TraderName:    at.name,
+		Exchange:      at.exchange,
+		GeneratedAt:   report.GeneratedAt,
+		Equity:        report.Equity,
+		GrossExposure: report.GrossExposure,
+		NetExposure:   report.NetExposure,
+		VaR95:         report.VaR95,
+		VaR99:         report.VaR99,
+		Positions:     make([]PositionExposure, 0, len(report.Positions)),
+	}
+	if report.Equity > 0 {
+		snapshot.Leverage = report.GrossExposure / report.Equity
+	}
+	for _, p := range report.Positions {
+		snapshot.Positions = append(snapshot.Positions, PositionExposure{
+			Symbol:           p.Symbol,
+			Side:             p.Side,
+			Quantity:         p.Quantity,
+			Notional:         p.Notional,
+			Leverage:         p.Leverage,
+			MarkPrice:        p.MarkPrice,
+			LiquidationPrice: p.LiquidationPrice,
+			LiqDistancePct:   LiquidationDistancePct(p.Side, p.MarkPrice, p.LiquidationPrice),
+			VaR95:            p.VaR95,
+		})
+	}
+	return snapshot, nil
+}
+
+// LiquidationDistancePct 标记价格距强平价格的百分比（多头为下跌幅度、空头为上涨幅度，已越过强平价格时为负）
+func LiquidationDistancePct(side string, markPrice, liqPrice float64) float64 {
+	if markPrice <= 0 || liqPrice <= 0 {
+		return 0
+	}
+	if side == "short" {
+		return (liqPrice - markPrice) / markPrice * 100
+	}
+	return (markPrice - liqPrice) / markPrice * 100
+}
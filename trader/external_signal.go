@@ -0,0 +1,95 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/events"
+	"nofx/logger"
+	"nofx/market"
+	"time"
+)
+
+// ExecuteSignal 执行外部信号（校验规则及执行路径与AI决策一致，结果写入决策日志）
+// 仅在交易员运行中时执行；与决策周期互斥，周期进行中时等待周期结束
+func (at *AutoTrader) ExecuteSignal(d decision.Decision, source string) (*logger.DecisionAction, error) {
+	if !at.isRunning {
+		return nil, fmt.Errorf("交易员 %s 未运行，忽略外部信号", at.name)
+	}
+
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+
+	isOpen := d.Action == "open_long" || d.Action == "open_short"
+	if until := at.PausedUntil(); isOpen && !until.IsZero() {
+		return nil, fmt.Errorf("风控暂停中（至 %s），拒绝外部开仓信号", until.Format("15:04:05"))
+	}
+
+	account, err := at.GetAccountInfo()
+	if err != nil {
+		return nil, err
+	}
+	equity, _ := account["total_equity"].(float64)
+	if isOpen && d.HasRelativeLevels() {
+		marketData, err := market.Get(d.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.ResolveLevels(marketData.CurrentPrice); err != nil {
+			return nil, fmt.Errorf("外部信号验证失败: %w", err)
+		}
+	}
+	if err := decision.ValidateDecision(&d, equity, at.config.BTCETHLeverage, at.config.AltcoinLeverage); err != nil {
+		return nil, fmt.Errorf("外部信号验证失败: %w", err)
+	}
+
+	log.Printf("📡 [%s] 外部信号 (%s): %s %s", at.name, source, d.Symbol, d.Action)
+	if err := at.awaitApproval(&d, "外部信号 ("+source+")"); err != nil {
+		return nil, fmt.Errorf("外部信号未执行: %w", err)
+	}
+	decisionJSON, _ := json.Marshal([]decision.Decision{d})
+	record := &logger.DecisionRecord{
+		Timestamp:    time.Now(),
+		CycleNumber:  at.callCount,
+		CoTTrace:     fmt.Sprintf("外部信号 (%s): %s", source, d.Reasoning),
+		DecisionJSON: string(decisionJSON),
+	}
+	action := logger.DecisionAction{
+		Action:    d.Action,
+		Symbol:    d.Symbol,
+		Leverage:  d.Leverage,
+		Timestamp: time.Now(),
+		Context:   tradeContext("外部信号 ("+source+")", d.Reasoning, d.Confidence, d.Symbol, nil),
+	}
+
+	execErr := at.executeDecisionWithRecord(&d, &action)
+	if execErr != nil {
+		action.Error = execErr.Error()
+		at.publishEvent(events.Event{
+			Type:    events.Error,
+			Symbol:  d.Symbol,
+			Action:  d.Action,
+			Message: "外部信号: " + execErr.Error(),
+		})
+		record.ErrorMessage = execErr.Error()
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s 失败: %v", d.Symbol, d.Action, execErr))
+	} else {
+		action.Success = true
+		record.Success = true
+		if action.DryRun && action.Preview != "" {
+			record.ExecutionLog = append(record.ExecutionLog, "🧪 "+action.Preview)
+		} else {
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s 成功", d.Symbol, d.Action))
+		}
+	}
+	record.Decisions = append(record.Decisions, action)
+	if err := at.decisionLogger.LogDecision(record); err != nil {
+		log.Printf("⚠ 保存决策记录失败: %v", err)
+	}
+
+	if execErr != nil {
+		return &action, execErr
+	}
+	return &action, nil
+}
@@ -0,0 +1,324 @@
+package trader
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FaultConfig 故障注入配置（混沌测试：在集成测试及预发布环境中验证超时、重试、下单对账等容错逻辑）
+type FaultConfig struct {
+	MinDelay  time.Duration // 每次调用前的最小延迟
+	MaxDelay  time.Duration // 每次调用前的最大延迟（在 MinDelay~MaxDelay 间均匀随机）
+	ErrorRate float64       // 不调用交易所、直接返回模拟5xx错误的概率
+	DropRate  float64       // 调用交易所成功后丢弃响应、返回超时错误的概率（订单实际已提交）
+	Seed      int64         // 随机数种子（0=按当前时间）
+}
+
+// ParseFaultConfig 解析故障注入配置，空字符串返回nil（关闭）
+// 格式: 参数=值,...，如 delay=200ms-2s,error=0.05,drop=0.02,seed=42（delay 也可以是固定值如 500ms）
+func ParseFaultConfig(spec string) (*FaultConfig, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	cfg := &FaultConfig{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的故障注入参数: %s（格式应为 参数=值）", item)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "delay":
+			minStr, maxStr, isRange := strings.Cut(value, "-")
+			if cfg.MinDelay, err = time.ParseDuration(minStr); err == nil {
+				cfg.MaxDelay = cfg.MinDelay
+				if isRange {
+					cfg.MaxDelay, err = time.ParseDuration(maxStr)
+				}
+			}
+			if err != nil || cfg.MinDelay < 0 || cfg.MaxDelay < cfg.MinDelay {
+				return nil, fmt.Errorf("无效的故障注入延迟: %s（如 500ms 或 200ms-2s）", value)
+			}
+		case "error", "drop":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return nil, fmt.Errorf("无效的故障注入概率 %s: %s（0~1）", key, value)
+			}
+			if key == "error" {
+				cfg.ErrorRate = rate
+			} else {
+				cfg.DropRate = rate
+			}
+		case "seed":
+			if cfg.Seed, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("无效的故障注入随机数种子: %s", value)
+			}
+		default:
+			return nil, fmt.Errorf("故障注入不支持参数: %s（可选: delay, error, drop, seed）", key)
+		}
+	}
+	return cfg, nil
+}
+
+// String 配置摘要
+func (c *FaultConfig) String() string {
+	return fmt.Sprintf("延迟 %v~%v，5xx概率 %.2f，丢弃响应概率 %.2f", c.MinDelay, c.MaxDelay, c.ErrorRate, c.DropRate)
+}
+
+// InjectedFault 注入的故障
+type InjectedFault struct {
+	Method  string
+	Status  int  // 模拟的HTTP状态码（丢弃响应时为0）
+	Dropped bool // 请求已被交易所处理，但响应被丢弃
+}
+
+// Error 实现 error
+func (e *InjectedFault) Error() string {
+	if e.Dropped {
+		return fmt.Sprintf("故障注入: %s 响应丢失（请求已发送）: i/o timeout", e.Method)
+	}
+	return fmt.Sprintf("故障注入: %s 返回 HTTP %d", e.Method, e.Status)
+}
+
+// injectedStatuses 模拟的5xx状态码
+var injectedStatuses = []int{500, 502, 503, 504}
+
+// FaultStats 故障注入统计
+type FaultStats struct {
+	Calls   int64 `json:"calls"`   // 经过包装的调用次数
+	Errors  int64 `json:"errors"`  // 注入的5xx错误
+	Dropped int64 `json:"dropped"` // 丢弃的响应
+}
+
+// FaultyTrader 在 Trader 外层按配置注入延迟、5xx错误及响应丢失的包装器
+// 下单意图日志、clientOrderId标记及按clientOrderId查询会转发给内层交易器（查询同样会被注入故障），
+// 其他可选能力（挂单、手续费查询等）在包装后不可用
+type FaultyTrader struct {
+	inner Trader
+	cfg   FaultConfig
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	sleep    func(time.Duration) // 可替换，便于测试
+	fallback orderTagging        // 内层交易器不支持订单标记时使用
+
+	calls, errors, dropped int64
+}
+
+// NewFaultyTrader 创建故障注入包装器
+func NewFaultyTrader(inner Trader, cfg *FaultConfig) *FaultyTrader {
+	f := &FaultyTrader{inner: inner, sleep: time.Sleep}
+	if cfg != nil {
+		f.cfg = *cfg
+	}
+	seed := f.cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	f.rng = rand.New(rand.NewSource(seed))
+	return f
+}
+
+// Unwrap 内层交易器
+func (f *FaultyTrader) Unwrap() Trader {
+	return f.inner
+}
+
+// Stats 故障注入统计
+func (f *FaultyTrader) Stats() FaultStats {
+	return FaultStats{
+		Calls:   atomic.LoadInt64(&f.calls),
+		Errors:  atomic.LoadInt64(&f.errors),
+		Dropped: atomic.LoadInt64(&f.dropped),
+	}
+}
+
+// roll 按概率抽签，同时返回随机延迟及状态码
+func (f *FaultyTrader) roll() (delay time.Duration, fail bool, drop bool, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delay = f.cfg.MinDelay
+	if span := f.cfg.MaxDelay - f.cfg.MinDelay; span > 0 {
+		delay += time.Duration(f.rng.Int63n(int64(span) + 1))
+	}
+	fail = f.rng.Float64() < f.cfg.ErrorRate
+	drop = f.rng.Float64() < f.cfg.DropRate
+	status = injectedStatuses[f.rng.Intn(len(injectedStatuses))]
+	return
+}
+
+// call 注入延迟及故障后调用内层交易器
+func (f *FaultyTrader) call(method string, fn func() error) error {
+	atomic.AddInt64(&f.calls, 1)
+	delay, fail, drop, status := f.roll()
+	if delay > 0 {
+		f.sleep(delay)
+	}
+	if fail {
+		atomic.AddInt64(&f.errors, 1)
+		return &InjectedFault{Method: method, Status: status}
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if drop {
+		atomic.AddInt64(&f.dropped, 1)
+		return &InjectedFault{Method: method, Dropped: true}
+	}
+	return nil
+}
+
+// callMap 包装返回 map 的调用
+func (f *FaultyTrader) callMap(method string, fn func() (map[string]interface{}, error)) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := f.call(method, func() (err error) {
+		result, err = fn()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBalance 实现 Trader
+func (f *FaultyTrader) GetBalance() (map[string]interface{}, error) {
+	return f.callMap("GetBalance", f.inner.GetBalance)
+}
+
+// GetPositions 实现 Trader
+func (f *FaultyTrader) GetPositions() ([]map[string]interface{}, error) {
+	var positions []map[string]interface{}
+	err := f.call("GetPositions", func() (err error) {
+		positions, err = f.inner.GetPositions()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// OpenLong 实现 Trader
+func (f *FaultyTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return f.callMap("OpenLong", func() (map[string]interface{}, error) { return f.inner.OpenLong(symbol, quantity, leverage) })
+}
+
+// OpenShort 实现 Trader
+func (f *FaultyTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return f.callMap("OpenShort", func() (map[string]interface{}, error) { return f.inner.OpenShort(symbol, quantity, leverage) })
+}
+
+// CloseLong 实现 Trader
+func (f *FaultyTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return f.callMap("CloseLong", func() (map[string]interface{}, error) { return f.inner.CloseLong(symbol, quantity) })
+}
+
+// CloseShort 实现 Trader
+func (f *FaultyTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return f.callMap("CloseShort", func() (map[string]interface{}, error) { return f.inner.CloseShort(symbol, quantity) })
+}
+
+// SetLeverage 实现 Trader
+func (f *FaultyTrader) SetLeverage(symbol string, leverage int) error {
+	return f.call("SetLeverage", func() error { return f.inner.SetLeverage(symbol, leverage) })
+}
+
+// SetMarginMode 实现 Trader
+func (f *FaultyTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
+	return f.call("SetMarginMode", func() error { return f.inner.SetMarginMode(symbol, isCrossMargin) })
+}
+
+// GetMarketPrice 实现 Trader
+func (f *FaultyTrader) GetMarketPrice(symbol string) (float64, error) {
+	var price float64
+	err := f.call("GetMarketPrice", func() (err error) {
+		price, err = f.inner.GetMarketPrice(symbol)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return price, nil
+}
+
+// SetStopLoss 实现 Trader
+func (f *FaultyTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	return f.call("SetStopLoss", func() error { return f.inner.SetStopLoss(symbol, positionSide, quantity, stopPrice) })
+}
+
+// SetTakeProfit 实现 Trader
+func (f *FaultyTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return f.call("SetTakeProfit", func() error { return f.inner.SetTakeProfit(symbol, positionSide, quantity, takeProfitPrice) })
+}
+
+// CancelAllOrders 实现 Trader
+func (f *FaultyTrader) CancelAllOrders(symbol string) error {
+	return f.call("CancelAllOrders", func() error { return f.inner.CancelAllOrders(symbol) })
+}
+
+// FormatQuantity 实现 Trader（本地计算，不注入故障）
+func (f *FaultyTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	return f.inner.FormatQuantity(symbol, quantity)
+}
+
+// SetOrderTag 实现 OrderTagger
+func (f *FaultyTrader) SetOrderTag(tag OrderTag) {
+	if tagger, ok := f.inner.(OrderTagger); ok {
+		tagger.SetOrderTag(tag)
+	}
+}
+
+// SetMaxSlippageTicks 实现 SlippageLimiter
+func (f *FaultyTrader) SetMaxSlippageTicks(ticks int) {
+	if limiter, ok := f.inner.(SlippageLimiter); ok {
+		limiter.SetMaxSlippageTicks(ticks)
+	}
+}
+
+// tagging 实现 journaledTrader（转发给内层交易器，保证下单意图日志在故障注入下照常工作）
+func (f *FaultyTrader) tagging() *orderTagging {
+	if jt, ok := f.inner.(journaledTrader); ok {
+		return jt.tagging()
+	}
+	return &f.fallback
+}
+
+// reserveClientOrderID 实现 journaledTrader
+func (f *FaultyTrader) reserveClientOrderID(purpose byte) string {
+	if jt, ok := f.inner.(journaledTrader); ok {
+		return jt.reserveClientOrderID(purpose)
+	}
+	return ""
+}
+
+// GetOrderByClientID 实现 ClientOrderLookup
+func (f *FaultyTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	lookup, ok := f.inner.(ClientOrderLookup)
+	if !ok {
+		return nil, false, fmt.Errorf("交易器不支持按clientOrderId查询订单")
+	}
+	var order *ClientOrder
+	var found bool
+	err := f.call("GetOrderByClientID", func() (err error) {
+		order, found, err = lookup.GetOrderByClientID(symbol, clientOrderID)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return order, found, nil
+}
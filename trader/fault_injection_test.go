@@ -0,0 +1,213 @@
+package trader
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubTrader 记录调用次数的内存交易器，按clientOrderId保存已提交的订单
+type stubTrader struct {
+	orderTagging
+
+	calls  int
+	orders map[string]*ClientOrder
+	onOpen func()
+}
+
+func newStubTrader() *stubTrader {
+	s := &stubTrader{orders: make(map[string]*ClientOrder)}
+	s.SetOrderTag(NewOrderTag("chaos", time.Unix(1700000000, 0)))
+	return s
+}
+
+func (s *stubTrader) GetBalance() (map[string]interface{}, error) {
+	s.calls++
+	return map[string]interface{}{"totalWalletBalance": 1000.0}, nil
+}
+
+func (s *stubTrader) GetPositions() ([]map[string]interface{}, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *stubTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	s.calls++
+	if s.onOpen != nil {
+		s.onOpen()
+	}
+	id := s.nextClientOrderID(OrderPurposeOpen)
+	s.orders[id] = &ClientOrder{OrderID: int64(len(s.orders) + 1), Status: "FILLED", FilledQty: quantity}
+	return map[string]interface{}{"orderId": s.orders[id].OrderID, "clientOrderId": id}, nil
+}
+
+func (s *stubTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return s.OpenLong(symbol, quantity, leverage)
+}
+
+func (s *stubTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	s.calls++
+	return map[string]interface{}{}, nil
+}
+
+func (s *stubTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	s.calls++
+	return map[string]interface{}{}, nil
+}
+
+func (s *stubTrader) SetLeverage(symbol string, leverage int) error           { s.calls++; return nil }
+func (s *stubTrader) SetMarginMode(symbol string, isCrossMargin bool) error   { s.calls++; return nil }
+func (s *stubTrader) GetMarketPrice(symbol string) (float64, error)           { s.calls++; return 100, nil }
+func (s *stubTrader) CancelAllOrders(symbol string) error                     { s.calls++; return nil }
+func (s *stubTrader) FormatQuantity(symbol string, q float64) (string, error) { return "1", nil }
+
+func (s *stubTrader) SetStopLoss(symbol, positionSide string, quantity, stopPrice float64) error {
+	s.calls++
+	return nil
+}
+
+func (s *stubTrader) SetTakeProfit(symbol, positionSide string, quantity, takeProfitPrice float64) error {
+	s.calls++
+	return nil
+}
+
+func (s *stubTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	order, ok := s.orders[clientOrderID]
+	return order, ok, nil
+}
+
+// memoryJournal 内存下单意图日志
+type memoryJournal struct {
+	intents map[string]*OrderIntent
+}
+
+func (m *memoryJournal) RecordIntent(intent *OrderIntent) error {
+	copied := *intent
+	m.intents[intent.ClientOrderID] = &copied
+	return nil
+}
+
+func (m *memoryJournal) UpdateIntent(clientOrderID, status string, orderID int64, errMsg string) error {
+	intent := m.intents[clientOrderID]
+	intent.Status, intent.OrderID, intent.Error = status, orderID, errMsg
+	return nil
+}
+
+func (m *memoryJournal) PendingIntents(traderID string) ([]*OrderIntent, error) {
+	return nil, nil
+}
+
+func TestParseFaultConfig(t *testing.T) {
+	cfg, err := ParseFaultConfig("delay=200ms-2s, error=0.05, drop=0.02, seed=42")
+	if err != nil {
+		t.Fatalf("ParseFaultConfig: %v", err)
+	}
+	want := FaultConfig{MinDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second, ErrorRate: 0.05, DropRate: 0.02, Seed: 42}
+	if *cfg != want {
+		t.Fatalf("got %+v, want %+v", *cfg, want)
+	}
+
+	cfg, err = ParseFaultConfig("delay=500ms")
+	if err != nil || cfg.MinDelay != 500*time.Millisecond || cfg.MaxDelay != cfg.MinDelay {
+		t.Fatalf("fixed delay: %+v, %v", cfg, err)
+	}
+
+	if cfg, err := ParseFaultConfig(""); cfg != nil || err != nil {
+		t.Fatalf("empty spec should disable injection, got %+v, %v", cfg, err)
+	}
+
+	for _, spec := range []string{"delay=2s-1s", "error=1.5", "drop=-0.1", "timeout=1s", "error"} {
+		if _, err := ParseFaultConfig(spec); err == nil {
+			t.Errorf("ParseFaultConfig(%q) should fail", spec)
+		}
+	}
+}
+
+func TestFaultyTraderInjectsServerErrors(t *testing.T) {
+	inner := newStubTrader()
+	f := NewFaultyTrader(inner, &FaultConfig{ErrorRate: 1, Seed: 1})
+
+	_, err := f.GetBalance()
+	var fault *InjectedFault
+	if !errors.As(err, &fault) || fault.Dropped || fault.Status < 500 || fault.Status > 599 {
+		t.Fatalf("expected injected 5xx, got %v", err)
+	}
+	if inner.calls != 0 {
+		t.Fatalf("injected 5xx should not reach the exchange, got %d calls", inner.calls)
+	}
+	if stats := f.Stats(); stats.Calls != 1 || stats.Errors != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestFaultyTraderDropsResponses(t *testing.T) {
+	inner := newStubTrader()
+	f := NewFaultyTrader(inner, &FaultConfig{DropRate: 1, Seed: 1})
+
+	if err := f.SetLeverage("BTCUSDT", 5); err == nil {
+		t.Fatal("expected dropped response error")
+	}
+	if inner.calls != 1 {
+		t.Fatalf("dropped request should still reach the exchange, got %d calls", inner.calls)
+	}
+	if stats := f.Stats(); stats.Dropped != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestFaultyTraderDelays(t *testing.T) {
+	f := NewFaultyTrader(newStubTrader(), &FaultConfig{MinDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond, Seed: 7})
+	var delays []time.Duration
+	f.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	for i := 0; i < 20; i++ {
+		if _, err := f.GetMarketPrice("BTCUSDT"); err != nil {
+			t.Fatalf("GetMarketPrice: %v", err)
+		}
+	}
+	if len(delays) != 20 {
+		t.Fatalf("expected 20 delays, got %d", len(delays))
+	}
+	for _, d := range delays {
+		if d < 100*time.Millisecond || d > 300*time.Millisecond {
+			t.Fatalf("delay %v out of range", d)
+		}
+	}
+}
+
+func TestPlaceOrderThroughFaultyTrader(t *testing.T) {
+	inner := newStubTrader()
+	journal := &memoryJournal{intents: make(map[string]*OrderIntent)}
+	f := NewFaultyTrader(inner, &FaultConfig{DropRate: 1, Seed: 1})
+	f.tagging().bindJournal(journal, "chaos", "binance")
+
+	// 下单响应丢失且无法确认时，意图保持pending，等待重启对账
+	if _, err := PlaceOrder(f, "BTCUSDT", "open_long", 1, 5); err == nil {
+		t.Fatal("expected error when both order response and lookup are dropped")
+	}
+	if len(journal.intents) != 1 {
+		t.Fatalf("expected 1 intent, got %d", len(journal.intents))
+	}
+	for id, intent := range journal.intents {
+		if intent.Status != OrderIntentPending {
+			t.Fatalf("intent should stay pending, got %s", intent.Status)
+		}
+		if _, ok := inner.orders[id]; !ok {
+			t.Fatalf("order should have been submitted with the journaled clientOrderId %s", id)
+		}
+	}
+
+	// 只丢失下单响应时，按clientOrderId查到订单，按已提交处理
+	journal.intents = make(map[string]*OrderIntent)
+	inner.onOpen = func() { f.cfg.DropRate = 0 }
+	f.cfg.DropRate = 1
+	order, err := PlaceOrder(f, "BTCUSDT", "open_long", 1, 5)
+	if err != nil {
+		t.Fatalf("dropped response should be recovered by lookup: %v", err)
+	}
+	for id, intent := range journal.intents {
+		if intent.Status != OrderIntentSubmitted || order["clientOrderId"] != id {
+			t.Fatalf("unexpected intent %+v / order %v", intent, order)
+		}
+	}
+}
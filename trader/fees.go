@@ -0,0 +1,131 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+)
+
+// 默认费率（获取账户实际费率失败时使用，均为各交易所普通用户档位）
+var defaultFeeSchedules = map[string]FeeSchedule{
+	"binance":     {Exchange: "binance", Maker: 0.0002, Taker: 0.0005},
+	"hyperliquid": {Exchange: "hyperliquid", Maker: 0.00015, Taker: 0.00045},
+	"aster":       {Exchange: "aster", Maker: 0.0001, Taker: 0.00035},
+	"gate":        {Exchange: "gate", Maker: 0.0002, Taker: 0.0005},
+}
+
+const (
+	defaultSpreadRate    = 0.0002 // 默认买卖价差（相对价格的比例，2bps）
+	defaultHoldingHours  = 8.0    // 默认预计持仓时长（小时）
+	fundingIntervalHours = 8.0    // 资金费结算周期（小时）
+)
+
+// FeeSchedule 账户手续费档位（费率为小数，如0.0005表示0.05%）
+type FeeSchedule struct {
+	Exchange string  `json:"exchange"`
+	Symbol   string  `json:"symbol,omitempty"`
+	Maker    float64 `json:"maker"`
+	Taker    float64 `json:"taker"`
+	Source   string  `json:"source"` // "api"=从交易所获取, "default"=默认费率
+}
+
+// FeeProvider 可查询账户实际手续费档位的交易器（可选接口）
+type FeeProvider interface {
+	// GetFeeSchedule 获取指定币种的maker/taker费率
+	GetFeeSchedule(symbol string) (*FeeSchedule, error)
+}
+
+// FeeOrder 用于费用预估的订单描述
+type FeeOrder struct {
+	Symbol       string
+	Side         string  // "long" 或 "short"
+	Quantity     float64 // 币数量
+	Price        float64 // 预计成交价
+	IsMaker      bool    // 是否以maker挂单成交（默认taker）
+	FundingRate  float64 // 当前资金费率（每个结算周期）
+	HoldingHours float64 // 预计持仓时长（小时），0=使用默认值
+	SpreadRate   float64 // 买卖价差比例，0=使用默认值
+}
+
+// FeeEstimate 预估交易成本（单位USDT）
+type FeeEstimate struct {
+	Notional    float64 `json:"notional"`
+	FeeRate     float64 `json:"fee_rate"`
+	EntryFee    float64 `json:"entry_fee"`
+	ExitFee     float64 `json:"exit_fee"`
+	FundingCost float64 `json:"funding_cost"` // 正数=支付，负数=收取
+	SpreadCost  float64 `json:"spread_cost"`
+	TotalCost   float64 `json:"total_cost"`
+}
+
+// DefaultFeeSchedule 获取交易所默认费率
+func DefaultFeeSchedule(exchange string) FeeSchedule {
+	if schedule, ok := defaultFeeSchedules[exchange]; ok {
+		schedule.Source = "default"
+		return schedule
+	}
+	schedule := defaultFeeSchedules["binance"]
+	schedule.Exchange = exchange
+	schedule.Source = "default"
+	return schedule
+}
+
+// GetFeeSchedule 获取交易器的费率档位，不支持查询或查询失败时返回默认费率
+func GetFeeSchedule(t Trader, exchange, symbol string) FeeSchedule {
+	if provider, ok := t.(FeeProvider); ok {
+		schedule, err := provider.GetFeeSchedule(symbol)
+		if err == nil && schedule != nil {
+			schedule.Exchange = exchange
+			schedule.Source = "api"
+			return *schedule
+		}
+	}
+	schedule := DefaultFeeSchedule(exchange)
+	schedule.Symbol = symbol
+	return schedule
+}
+
+// EstimateFees 预估一笔交易从开仓到平仓的总成本（手续费+资金费+价差）
+func EstimateFees(order FeeOrder, schedule FeeSchedule) (*FeeEstimate, error) {
+	if order.Quantity <= 0 || order.Price <= 0 {
+		return nil, fmt.Errorf("数量和价格必须大于0")
+	}
+
+	notional := order.Quantity * order.Price
+	feeRate := schedule.Taker
+	if order.IsMaker {
+		feeRate = schedule.Maker
+	}
+
+	holdingHours := order.HoldingHours
+	if holdingHours <= 0 {
+		holdingHours = defaultHoldingHours
+	}
+	spreadRate := order.SpreadRate
+	if spreadRate <= 0 {
+		spreadRate = defaultSpreadRate
+	}
+
+	// 资金费：正费率时多头支付、空头收取
+	fundingPeriods := math.Ceil(holdingHours / fundingIntervalHours)
+	fundingCost := notional * order.FundingRate * fundingPeriods
+	if order.Side == "short" {
+		fundingCost = -fundingCost
+	}
+
+	// 价差：开仓和平仓各穿越半个价差（maker挂单不计价差）
+	spreadCost := 0.0
+	if !order.IsMaker {
+		spreadCost = notional * spreadRate
+	}
+
+	estimate := &FeeEstimate{
+		Notional:    notional,
+		FeeRate:     feeRate,
+		EntryFee:    notional * feeRate,
+		ExitFee:     notional * feeRate,
+		FundingCost: fundingCost,
+		SpreadCost:  spreadCost,
+	}
+	estimate.TotalCost = estimate.EntryFee + estimate.ExitFee + estimate.FundingCost + estimate.SpreadCost
+	return estimate, nil
+}
@@ -0,0 +1,84 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/market"
+	"time"
+)
+
+// defaultFundingLead 仅设置了不利资金费阈值时，结算前多久开始平仓
+const defaultFundingLead = 10 * time.Minute
+
+// fundingLead 资金费结算前的规避时长
+func (at *AutoTrader) fundingLead() time.Duration {
+	if at.config.FundingAvoidWindow > 0 {
+		return at.config.FundingAvoidWindow
+	}
+	return defaultFundingLead
+}
+
+// checkFundingWindow 资金费结算前后N分钟内禁止开仓/平仓（交易所的止盈止损单不受影响）
+func (at *AutoTrader) checkFundingWindow(symbol, action string) error {
+	window := at.config.FundingAvoidWindow
+	if window <= 0 {
+		return nil
+	}
+	funding, err := market.GetFunding(symbol)
+	if err != nil || funding.NextFundingTime.IsZero() {
+		return nil // 获取失败时不阻止交易
+	}
+
+	untilFunding := time.Until(funding.NextFundingTime)
+	// 结算时间刚过时，下次结算时间已更新为下一个周期，需同时检查上一次结算（默认8小时周期）
+	sinceFunding := 8*time.Hour - untilFunding
+	if untilFunding <= window || sinceFunding <= window {
+		return fmt.Errorf("❌ %s 资金费结算时间 %s 前后 %.0f 分钟内禁止%s",
+			symbol, funding.NextFundingTime.Local().Format("15:04"), window.Minutes(), action)
+	}
+	return nil
+}
+
+// reduceAdverseFunding 资金费结算前平掉资金费不利且超过阈值的持仓（多仓付正费率、空仓付负费率）
+func (at *AutoTrader) reduceAdverseFunding(positions []decision.PositionInfo) []string {
+	threshold := at.config.FundingAdverseThreshold
+	if threshold <= 0 || len(positions) == 0 {
+		return nil
+	}
+
+	var messages []string
+	for _, pos := range positions {
+		funding, err := market.GetFunding(pos.Symbol)
+		if err != nil || funding.NextFundingTime.IsZero() || time.Until(funding.NextFundingTime) > at.fundingLead() {
+			continue
+		}
+		// 费率按百分比配置，如 0.05 表示 0.05%
+		ratePct := funding.Rate * 100
+		adverse := (pos.Side == "long" && ratePct >= threshold) || (pos.Side == "short" && -ratePct >= threshold)
+		if !adverse {
+			continue
+		}
+		if err := at.checkCloseOwnership(pos.Symbol, pos.Side); err != nil {
+			continue
+		}
+
+		if at.config.DryRun {
+			messages = append(messages, fmt.Sprintf("WOULD close %s %s before funding (rate %.4f%%)", pos.Symbol, pos.Side, ratePct))
+			continue
+		}
+
+		log.Printf("💸 %s %s仓资金费率 %.4f%% 超过阈值 %.4f%%，结算前平仓", pos.Symbol, sideName(pos.Side), ratePct, threshold)
+		unlock := at.lockSymbol(pos.Symbol)
+		_, err = PlaceOrder(at.trader, pos.Symbol, "close_"+pos.Side, 0, 0)
+		unlock()
+		if err != nil {
+			log.Printf("  ⚠️ 平仓失败: %v", err)
+			continue
+		}
+		at.trackCloseOrder(pos.Symbol, pos.Side, 0, "", pos.MarkPrice)
+		at.releaseAllocation(pos.Symbol, pos.Side)
+		messages = append(messages, fmt.Sprintf("💸 资金费结算前平仓 %s %s仓（费率 %.4f%%）", pos.Symbol, sideName(pos.Side), ratePct))
+	}
+	return messages
+}
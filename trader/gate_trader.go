@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"nofx/logger"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/adshao/go-binance/v2/futures"
+	"github.com/antihax/optional"
 	"github.com/gateio/gateapi-go/v7"
 )
 
@@ -32,7 +33,7 @@ func NewGateConfig(apiKey string, apiSecret string, useTestNet bool) *GateConfig
 		config.BaseUrl = "https://api-testnet.gateapi.io/api/v4"
 		// config.BaseUrl = "https://fx-api-testnet.gateio.ws/api/v4"
 	}
-	log.Printf("config %+v", config)
+	logger.Debugf("Gate配置: %+v", config)
 
 	return config
 }
@@ -53,6 +54,36 @@ type GateTrader struct {
 
 	// 缓存有效期（15秒）
 	cacheDuration time.Duration
+
+	// 全市场行情快照（一次请求获取所有合约的ticker，按币种查询时复用）
+	cachedTickers       map[string]gateapi.FuturesTicker
+	tickersCacheTime    time.Time
+	tickersCacheMutex   sync.Mutex
+	tickerCacheDuration time.Duration
+
+	// 持仓模式（nil=尚未检测，true=双向持仓，false=单向持仓）
+	dualMode          *bool
+	positionModeMutex sync.Mutex
+
+	// 切换杠杆后的冷却等待时间
+	leverageCooldown time.Duration
+
+	// 合约交易规则缓存（价格精度、最小张数、合约乘数），同一环境的Gate交易器共用
+	specs *SpecRegistry
+
+	// 订单归属标识（写入text字段）
+	orderTagging
+
+	// 最大滑点保护（市价单改为限价穿价单）
+	slippageLimit
+}
+
+// orderText 订单text字段（Gate要求以 t- 开头）：设置了归属标识时使用 t-<clientOrderId>，否则使用 t-<前缀>-<label>
+func (t *GateTrader) orderText(purpose byte, label string) string {
+	if id := t.nextClientOrderID(purpose); id != "" {
+		return "t-" + id
+	}
+	return t.orderTag.untaggedText(label)
 }
 
 func NewGateTrader(apiKey, secretKey string, useTestNet bool) (*GateTrader, error) {
@@ -61,13 +92,44 @@ func NewGateTrader(apiKey, secretKey string, useTestNet bool) (*GateTrader, erro
 	clientConfig := gateapi.NewConfiguration()
 	clientConfig.BasePath = config.BaseUrl
 	client := gateapi.NewAPIClient(clientConfig)
+	specsKey := "gate"
+	if useTestNet {
+		specsKey = "gate-testnet"
+	}
 	return &GateTrader{
-		client:        client,
-		config:        config,
-		cacheDuration: 15 * time.Second, // 15秒缓存
+		client:              client,
+		config:              config,
+		specs:               SharedSpecRegistry(specsKey),
+		cacheDuration:       15 * time.Second, // 15秒缓存
+		tickerCacheDuration: 2 * time.Second,
+		leverageCooldown:    5 * time.Second,
 	}, nil
 }
 
+// IsDualMode 检测USDT结算合约账户是否为双向持仓模式（结果缓存）
+func (t *GateTrader) IsDualMode() (bool, error) {
+	t.positionModeMutex.Lock()
+	defer t.positionModeMutex.Unlock()
+
+	if t.dualMode != nil {
+		return *t.dualMode, nil
+	}
+
+	account, _, err := t.client.FuturesApi.ListFuturesAccounts(t.getClientCtx(), "usdt")
+	if err != nil {
+		return false, fmt.Errorf("获取持仓模式失败: %w", err)
+	}
+
+	dual := account.InDualMode
+	t.dualMode = &dual
+	if dual {
+		log.Printf("✓ Gate账户持仓模式: 双向持仓")
+	} else {
+		log.Printf("✓ Gate账户持仓模式: 单向持仓")
+	}
+	return dual, nil
+}
+
 func (t *GateTrader) getClientCtx() context.Context {
 	ctx := context.WithValue(context.Background(),
 		gateapi.ContextGateAPIV4,
@@ -78,25 +140,212 @@ func (t *GateTrader) getClientCtx() context.Context {
 	return ctx
 }
 
-// GetMarketPrice 获取市场价格
-func (t *GateTrader) GetMarketPrice(symbol string) (float64, error) {
-	symbol = formatSymbolToContract(symbol)
+// getTickers 获取USDT结算合约的全部ticker（快照有效期内直接返回缓存，并发调用只发起一次请求）
+func (t *GateTrader) getTickers() (map[string]gateapi.FuturesTicker, error) {
+	t.tickersCacheMutex.Lock()
+	defer t.tickersCacheMutex.Unlock()
 
-	settle := "usdt"
-	ticker, _, err := t.client.FuturesApi.GetFuturesContract(t.getClientCtx(), settle, symbol)
+	if t.cachedTickers != nil && time.Since(t.tickersCacheTime) < t.tickerCacheDuration {
+		return t.cachedTickers, nil
+	}
+
+	tickers, _, err := t.client.FuturesApi.ListFuturesTickers(t.getClientCtx(), "usdt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取行情失败: %w", err)
+	}
+	snapshot := make(map[string]gateapi.FuturesTicker, len(tickers))
+	for _, ticker := range tickers {
+		snapshot[ticker.Contract] = ticker
+	}
+	t.cachedTickers = snapshot
+	t.tickersCacheTime = time.Now()
+	return snapshot, nil
+}
+
+// GetMarketPrices 批量获取市场价格（一次请求获取全部ticker，返回 symbol -> 最新价，没有行情的币种不在结果中）
+func (t *GateTrader) GetMarketPrices(symbols []string) (map[string]float64, error) {
+	return t.getPrices(symbols, PriceLast)
+}
+
+// getPrices 从全市场行情快照中批量获取指定类型的价格
+func (t *GateTrader) getPrices(symbols []string, priceType PriceType) (map[string]float64, error) {
+	tickers, err := t.getTickers()
 	if err != nil {
-		return 0, fmt.Errorf("获取行情失败: %w", err)
+		return nil, err
 	}
 
-	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	prices := make(map[string]float64, len(symbols))
+	for _, symbol := range symbols {
+		ticker, ok := tickers[formatSymbolToContract(symbol)]
+		if !ok {
+			continue
+		}
+		value := ticker.Last
+		switch priceType {
+		case PriceMark:
+			value = ticker.MarkPrice
+		case PriceIndex:
+			value = ticker.IndexPrice
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		prices[symbol] = price
+	}
+	return prices, nil
+}
+
+// GetMarketPrice 获取市场价格（从全市场行情快照中查询）
+func (t *GateTrader) GetMarketPrice(symbol string) (float64, error) {
+	prices, err := t.GetMarketPrices([]string{symbol})
 	if err != nil {
-		return 0, fmt.Errorf("解析价格失败: %w", err)
+		return 0, err
+	}
+	price, ok := prices[symbol]
+	if !ok {
+		return 0, fmt.Errorf("未找到合约 %s 的行情", formatSymbolToContract(symbol))
 	}
 
-	log.Printf("📈 %s 当前市价: %.2f", symbol, price)
+	log.Printf("📈 %s 当前市价: %.2f", formatSymbolToContract(symbol), price)
 	return price, nil
 }
 
+// GetBookQuote 实现 BookQuoteProvider（来自全市场行情快照，挂单张数换算为标的数量）
+func (t *GateTrader) GetBookQuote(symbol string) (*BookQuote, error) {
+	tickers, err := t.getTickers()
+	if err != nil {
+		return nil, err
+	}
+	ticker, ok := tickers[formatSymbolToContract(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("未找到合约 %s 的行情", formatSymbolToContract(symbol))
+	}
+
+	_, _, quanto, _ := t.GetSymbolPrecision(symbol)
+	quote := &BookQuote{Symbol: symbol}
+	quote.Bid, _ = strconv.ParseFloat(ticker.HighestBid, 64)
+	quote.Ask, _ = strconv.ParseFloat(ticker.LowestAsk, 64)
+	bidSize, _ := strconv.ParseFloat(ticker.HighestSize, 64)
+	askSize, _ := strconv.ParseFloat(ticker.LowestSize, 64)
+	quote.BidQty, quote.AskQty = bidSize*quanto, askSize*quanto
+	if quote.Bid <= 0 || quote.Ask <= 0 {
+		return nil, fmt.Errorf("合约 %s 没有挂单", formatSymbolToContract(symbol))
+	}
+	return quote, nil
+}
+
+// GetPriceByType 实现 PriceTypeProvider（标记价格及指数价格同样来自全市场行情快照）
+func (t *GateTrader) GetPriceByType(symbol string, priceType PriceType) (float64, error) {
+	prices, err := t.getPrices([]string{symbol}, priceType)
+	if err != nil {
+		return 0, err
+	}
+	price, ok := prices[symbol]
+	if !ok {
+		return 0, fmt.Errorf("未找到合约 %s 的%s价格", formatSymbolToContract(symbol), priceType)
+	}
+	return price, nil
+}
+
+// GetCurrencyBalances 获取USDT/BTC结算合约账户及现货钱包余额
+func (t *GateTrader) GetCurrencyBalances() ([]CurrencyBalance, error) {
+	var balances []CurrencyBalance
+	for _, settle := range []string{"usdt", "btc"} {
+		account, _, err := t.client.FuturesApi.ListFuturesAccounts(t.getClientCtx(), settle)
+		if err != nil {
+			if settle == "usdt" {
+				return nil, fmt.Errorf("获取合约账户失败: %w", err)
+			}
+			// 未开通的结算币种账户直接跳过
+			log.Printf("⚠️  获取Gate %s结算合约账户失败: %v", settle, err)
+			continue
+		}
+		total, _ := strconv.ParseFloat(account.Total, 64)
+		available, _ := strconv.ParseFloat(account.Available, 64)
+		unrealized, _ := strconv.ParseFloat(account.UnrealisedPnl, 64)
+		balances = append(balances, CurrencyBalance{
+			Wallet:        "futures",
+			Currency:      strings.ToUpper(settle),
+			Total:         total,
+			Available:     available,
+			UnrealizedPnL: unrealized,
+		})
+	}
+
+	spotAccounts, _, err := t.client.SpotApi.ListSpotAccounts(t.getClientCtx(), nil)
+	if err != nil {
+		log.Printf("⚠️  获取Gate现货账户失败: %v", err)
+		return balances, nil
+	}
+	for _, acc := range spotAccounts {
+		available, _ := strconv.ParseFloat(acc.Available, 64)
+		locked, _ := strconv.ParseFloat(acc.Locked, 64)
+		balances = append(balances, CurrencyBalance{
+			Wallet:    "spot",
+			Currency:  acc.Currency,
+			Total:     available + locked,
+			Available: available,
+		})
+	}
+
+	return balances, nil
+}
+
+// TransferMargin 现货与USDT结算合约钱包之间划转
+func (t *GateTrader) TransferMargin(from, to, currency string, amount float64) error {
+	if err := validateTransfer(from, to, amount); err != nil {
+		return err
+	}
+
+	result, _, err := t.client.WalletApi.Transfer(t.getClientCtx(), gateapi.Transfer{
+		Currency: strings.ToUpper(currency),
+		From:     from,
+		To:       to,
+		Amount:   strconv.FormatFloat(amount, 'f', -1, 64),
+		Settle:   "usdt",
+	})
+	if err != nil {
+		return fmt.Errorf("划转失败: %w", err)
+	}
+
+	// 划转后余额变化，清除缓存
+	t.balanceCacheMutex.Lock()
+	t.cachedBalance = nil
+	t.balanceCacheMutex.Unlock()
+
+	log.Printf("✓ Gate划转成功: %s → %s %.4f %s (tx_id=%d)", from, to, amount, currency, result.TxId)
+	return nil
+}
+
+// GetFeeSchedule 获取账户在该合约上的实际maker/taker费率
+func (t *GateTrader) GetFeeSchedule(symbol string) (*FeeSchedule, error) {
+	contract := formatSymbolToContract(symbol)
+
+	fees, _, err := t.client.FuturesApi.GetFuturesFee(t.getClientCtx(), "usdt", &gateapi.GetFuturesFeeOpts{
+		Contract: optional.NewString(contract),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+
+	fee, ok := fees[contract]
+	if !ok {
+		return nil, fmt.Errorf("未找到 %s 的手续费率", contract)
+	}
+
+	maker, err := strconv.ParseFloat(fee.MakerFee, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析maker费率失败: %w", err)
+	}
+	taker, err := strconv.ParseFloat(fee.TakerFee, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析taker费率失败: %w", err)
+	}
+
+	return &FeeSchedule{Symbol: symbol, Maker: maker, Taker: taker}, nil
+}
+
 // GetBalance 获取账户余额（带缓存）
 func (t *GateTrader) GetBalance() (map[string]interface{}, error) {
 	// 先检查缓存是否有效
@@ -171,11 +420,18 @@ func (t *GateTrader) GetPositions() ([]map[string]interface{}, error) {
 		posMap["leverage"], _ = strconv.ParseFloat(pos.Leverage, 64)
 		posMap["liquidationPrice"], _ = strconv.ParseFloat(pos.LiqPrice, 64)
 
-		// 判断方向
-		if posAmt > 0 {
+		// 判断方向（双向持仓以mode为准，单向持仓按数量正负）
+		switch pos.Mode {
+		case "dual_long":
 			posMap["side"] = "long"
-		} else {
+		case "dual_short":
 			posMap["side"] = "short"
+		default:
+			if posAmt > 0 {
+				posMap["side"] = "long"
+			} else {
+				posMap["side"] = "short"
+			}
 		}
 
 		result = append(result, posMap)
@@ -214,26 +470,31 @@ func (t *GateTrader) SetLeverage(symbol string, leverage int) error {
 		return nil
 	}
 
-	// 切换杠杆
+	dual, err := t.IsDualMode()
+	if err != nil {
+		return fmt.Errorf("设置杠杆失败: %w", err)
+	}
+
+	// 切换杠杆（双向持仓需使用dual_comp接口，单向持仓接口在双向模式下会返回数组）
 	settle := "usdt"
 	strLeverage := strconv.Itoa(leverage)
 	log.Printf("🔄 切换 %s 杠杆: %dx -> %dx", symbol, currentLeverage, leverage)
-	_, _, err = t.client.FuturesApi.UpdatePositionLeverage(t.getClientCtx(), settle, symbol, strLeverage, nil)
-
+	if dual {
+		_, _, err = t.client.FuturesApi.UpdateDualModePositionLeverage(t.getClientCtx(), settle, symbol, strLeverage, nil)
+	} else {
+		_, _, err = t.client.FuturesApi.UpdatePositionLeverage(t.getClientCtx(), settle, symbol, strLeverage, nil)
+	}
 	if err != nil {
-		// SDK 有bug 先忽略
-		if contains(err.Error(), "json: cannot unmarshal array into") {
-			log.Print("  ✓ 忽略杠杆设置错误（Gate SDK有bug）")
-			return nil
-		}
 		return fmt.Errorf("设置杠杆失败: %w", err)
 	}
 
 	log.Printf("  ✓ %s 杠杆已切换为 %dx", symbol, leverage)
 
-	// 切换杠杆后等待5秒（避免冷却期错误）
-	log.Printf("  ⏱ 等待5秒冷却期...")
-	time.Sleep(5 * time.Second)
+	// 切换杠杆后等待冷却期（避免冷却期错误）
+	if t.leverageCooldown > 0 {
+		log.Printf("  ⏱ 等待%.0f秒冷却期...", t.leverageCooldown.Seconds())
+		time.Sleep(t.leverageCooldown)
+	}
 
 	return nil
 }
@@ -253,33 +514,45 @@ func (t *GateTrader) CancelAllOrders(symbol string) error {
 	return nil
 }
 
-// GetSymbolPrecision 获取合约交易对的精度信息（价格精度、小数单位、最小下单量、每张合约乘数）
-func (t *GateTrader) GetSymbolPrecision(symbol string) (pricePrecision int, sizeMin float64, quanto float64, err error) {
-	symbol = formatSymbolToContract(symbol)
-
+// loadContractSpecs 一次性加载所有USDT结算合约的交易规则
+func (t *GateTrader) loadContractSpecs() (map[string]ContractSpec, error) {
 	contracts, _, err := t.client.FuturesApi.ListFuturesContracts(t.getClientCtx(), "usdt", nil)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("获取合约交易规则失败: %w", err)
+		return nil, fmt.Errorf("获取合约交易规则失败: %w", err)
 	}
 
+	specs := make(map[string]ContractSpec, len(contracts))
 	for _, c := range contracts {
-		if strings.EqualFold(c.Name, symbol) {
-			pricePrecision = getPrecisionFromRound(c.OrderPriceRound)
-			sizeMin = float64(c.OrderSizeMin)
-
-			quanto, _ = strconv.ParseFloat(c.QuantoMultiplier, 64)
-			if quanto == 0 {
-				quanto = 1 // 安全兜底
-			}
-
-			log.Printf("✅ %s 精度信息: 价格精度=%d, 最小下单量=%.0f张, QuantoMultiplier=%f",
-				symbol, pricePrecision, sizeMin, quanto)
-			return pricePrecision, sizeMin, quanto, nil
+		spec := ContractSpec{
+			Symbol:            strings.ToUpper(c.Name),
+			PricePrecision:    getPrecisionFromRound(c.OrderPriceRound),
+			QuantityPrecision: getPrecisionFromRound(c.QuantoMultiplier), // 数量为张数×乘数
+			MinQty:            float64(c.OrderSizeMin),
+		}
+		spec.TickSize, _ = strconv.ParseFloat(c.OrderPriceRound, 64)
+		spec.Multiplier, _ = strconv.ParseFloat(c.QuantoMultiplier, 64)
+		if spec.Multiplier == 0 {
+			spec.Multiplier = 1 // 安全兜底
 		}
+		spec.StepSize = spec.Multiplier
+		specs[spec.Symbol] = spec
 	}
+	return specs, nil
+}
 
-	log.Printf("⚠ 未找到 %s 的精度信息，使用默认精度(价格精度3, 最小下单量1, 乘数1)", symbol)
-	return 3, 1, 1, nil
+// GetContractSpec 获取合约交易规则（缓存，每小时刷新）
+func (t *GateTrader) GetContractSpec(symbol string) (ContractSpec, error) {
+	return t.specs.get(strings.ToUpper(formatSymbolToContract(symbol)), t.loadContractSpecs)
+}
+
+// GetSymbolPrecision 获取合约交易对的精度信息（价格精度、小数单位、最小下单量、每张合约乘数）
+func (t *GateTrader) GetSymbolPrecision(symbol string) (pricePrecision int, sizeMin float64, quanto float64, err error) {
+	spec, err := t.GetContractSpec(symbol)
+	if err != nil {
+		log.Printf("⚠ %v，使用默认精度(价格精度3, 最小下单量1, 乘数1)", err)
+		return 3, 1, 1, nil
+	}
+	return spec.PricePrecision, spec.MinQty, spec.Multiplier, nil
 }
 
 // getPrecisionFromRound 根据字符串 "0.001" 推算小数位数
@@ -291,14 +564,22 @@ func getPrecisionFromRound(round string) int {
 	return len(decimals)
 }
 
+// RoundPrice 将价格取整到合约的价格精度
+func (t *GateTrader) RoundPrice(symbol string, price float64) (float64, error) {
+	spec, err := t.GetContractSpec(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return spec.RoundPrice(price), nil
+}
+
 // FormatQuantity 仅用于日志输出格式化
 func (t *GateTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
-	precision, _, _, err := t.GetSymbolPrecision(symbol)
+	spec, err := t.GetContractSpec(symbol)
 	if err != nil {
-		precision = 3 // fallback
+		return fmt.Sprintf("%.3f", quantity), nil // fallback
 	}
-	format := fmt.Sprintf("%%.%df", precision)
-	return fmt.Sprintf(format, quantity), nil
+	return spec.FormatQuantity(quantity), nil
 }
 
 // quantityToContractSize 将标的币数量换算为合约张数（向下取整）
@@ -340,18 +621,33 @@ func formatSymbolToContract(symbol string) string {
 
 // SetMarginMode 设置仓位模式
 func (t *GateTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
-	var marginType futures.MarginType
-	if isCrossMargin {
-		marginType = futures.MarginTypeCrossed
-	} else {
-		marginType = futures.MarginTypeIsolated
+	symbol = formatSymbolToContract(symbol)
+
+	// Gate仓位模式取值: CROSS(全仓) / ISOLATED(逐仓)
+	marginType := "CROSS"
+	if !isCrossMargin {
+		marginType = "ISOLATED"
+	}
+	dual, err := t.IsDualMode()
+	if err != nil {
+		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
+		// 不返回错误，让交易继续
+		return nil
 	}
+
+	// 尝试设置仓位模式（双向持仓使用dual_comp接口）
 	settle := "usdt"
-	_, _, err := t.client.FuturesApi.UpdateDualCompPositionCrossMode(t.getClientCtx(), settle, gateapi.InlineObject{
-		Contract: symbol,
-		Mode:     string(marginType),
-	})
-	// 尝试设置仓位模式
+	if dual {
+		_, _, err = t.client.FuturesApi.UpdateDualCompPositionCrossMode(t.getClientCtx(), settle, gateapi.InlineObject{
+			Contract: symbol,
+			Mode:     marginType,
+		})
+	} else {
+		_, _, err = t.client.FuturesApi.UpdatePositionCrossMode(t.getClientCtx(), settle, gateapi.FuturesPositionCrossMode{
+			Contract: symbol,
+			Mode:     marginType,
+		})
+	}
 
 	marginModeStr := "全仓"
 	if !isCrossMargin {
@@ -378,6 +674,24 @@ func (t *GateTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	return nil
 }
 
+// filledQuantity IOC订单已成交的标的数量（张数×合约乘数；开启最大滑点保护时超出限价的部分不成交）
+func (t *GateTrader) filledQuantity(symbol string, order gateapi.FuturesOrder) float64 {
+	contracts := math.Abs(float64(order.Size)) - math.Abs(float64(order.Left))
+	if spec, err := t.GetContractSpec(symbol); err == nil && spec.Multiplier > 0 {
+		return contracts * spec.Multiplier
+	}
+	return contracts
+}
+
+// marketOrderPrice 市价单的price字段：开启最大滑点保护时为限价穿价价格，否则为 "0"（市价）
+func (t *GateTrader) marketOrderPrice(contract string, buy, opening bool) (string, error) {
+	price, ok, err := t.limitThroughPrice(t, t.GetContractSpec, contract, buy, opening)
+	if err != nil || !ok {
+		return "0", err
+	}
+	return price, nil
+}
+
 // OpenLong 开多仓（市价单）
 func (t *GateTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
 	settle := "usdt"
@@ -393,28 +707,26 @@ func (t *GateTrader) OpenLong(symbol string, quantity float64, leverage int) (ma
 		return nil, fmt.Errorf("设置杠杆失败: %w", err)
 	}
 
-	// 3️⃣ 设置逐仓模式
-	_, _, err := t.client.FuturesApi.UpdateDualCompPositionCrossMode(t.getClientCtx(), settle, gateapi.InlineObject{
-		Contract: symbol,
-		Mode:     "ISOLATED",
-	})
-	if err != nil {
-		log.Printf("⚠️ 设置逐仓模式失败: %v（可能已是逐仓模式）", err)
-	}
+	// 注意：仓位模式应该由调用方（AutoTrader）在开仓前通过 SetMarginMode 设置
 
-	// 4️⃣ 换算数量为合约张数
+	// 3️⃣ 换算数量为合约张数
 	sizeInt, err := t.quantityToContractSize(symbol, quantity)
 	if err != nil {
 		return nil, fmt.Errorf("换算下单张数失败: %w", err)
 	}
 
-	// 5️⃣ 创建市价多单
+	price, err := t.marketOrderPrice(symbol, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4️⃣ 创建市价多单
 	order := gateapi.FuturesOrder{
 		Contract: symbol,
 		Size:     sizeInt, // 正数 = 开多
-		Price:    "0",     // 市价单
+		Price:    price,   // 市价单（开启最大滑点保护时为限价穿价单）
 		Tif:      "ioc",   // 立即成交或取消
-		Text:     "t-open_long",
+		Text:     t.orderText(OrderPurposeOpen, "open_long"),
 	}
 
 	resp, _, err := t.client.FuturesApi.CreateFuturesOrder(t.getClientCtx(), settle, order, nil)
@@ -426,11 +738,13 @@ func (t *GateTrader) OpenLong(symbol string, quantity float64, leverage int) (ma
 		symbol, quantity, sizeInt, leverage, resp.Id)
 
 	result := map[string]interface{}{
-		"orderId": resp.Id,
-		"symbol":  resp.Contract,
-		"status":  resp.Status,
-		"price":   resp.Price,
-		"size":    resp.Size,
+		"orderId":       resp.Id,
+		"clientOrderId": resp.Text,
+		"symbol":        resp.Contract,
+		"status":        resp.Status,
+		"price":         resp.Price,
+		"size":          resp.Size,
+		"filledQty":     t.filledQuantity(symbol, resp),
 	}
 	return result, nil
 }
@@ -478,13 +792,18 @@ func (t *GateTrader) CloseLong(symbol string, quantity float64) (map[string]inte
 		return nil, fmt.Errorf("无效的平仓数量: %.6f (计算后张数=%d)", quantity, sizeInt)
 	}
 
+	price, err := t.marketOrderPrice(symbol, false, false)
+	if err != nil {
+		return nil, err
+	}
+
 	// 4️⃣ 构建市价平多单（负数代表平多）
 	order := gateapi.FuturesOrder{
 		Contract:   symbol,
-		Size:       -sizeInt,       // ❗负数代表平多仓（卖出）
-		Price:      "0",            // 市价单
-		Tif:        "ioc",          // 立即成交或取消
-		Text:       "t-close_long", // Gate要求text以`t-`开头
+		Size:       -sizeInt,                                     // ❗负数代表平多仓（卖出）
+		Price:      price,                                        // 市价单（开启最大滑点保护时为限价穿价单）
+		Tif:        "ioc",                                        // 立即成交或取消
+		Text:       t.orderText(OrderPurposeClose, "close_long"), // Gate要求text以`t-`开头
 		ReduceOnly: true,
 	}
 
@@ -504,9 +823,10 @@ func (t *GateTrader) CloseLong(symbol string, quantity float64) (map[string]inte
 
 	// 7️⃣ 封装结果返回
 	result := map[string]interface{}{
-		"orderId": resp.Id,
-		"symbol":  resp.Contract,
-		"status":  resp.Status,
+		"orderId":       resp.Id,
+		"clientOrderId": resp.Text,
+		"symbol":        resp.Contract,
+		"status":        resp.Status,
 	}
 
 	return result, nil
@@ -526,15 +846,8 @@ func (t *GateTrader) OpenShort(symbol string, quantity float64, leverage int) (m
 		return nil, err
 	}
 
-	// 3️⃣ 设置逐仓模式
+	// 注意：仓位模式应该由调用方（AutoTrader）在开仓前通过 SetMarginMode 设置
 	settle := "usdt"
-	_, _, err := t.client.FuturesApi.UpdateDualCompPositionCrossMode(t.getClientCtx(), settle, gateapi.InlineObject{
-		Contract: symbol,
-		Mode:     "ISOLATED",
-	})
-	if err != nil {
-		log.Printf("⚠️ 设置逐仓模式失败: %v（可能已是逐仓模式）", err)
-	}
 
 	// 换算数量为合约张数
 	sizeInt, err := t.quantityToContractSize(symbol, quantity)
@@ -542,13 +855,18 @@ func (t *GateTrader) OpenShort(symbol string, quantity float64, leverage int) (m
 		return nil, fmt.Errorf("换算下单张数失败: %w", err)
 	}
 
+	price, err := t.marketOrderPrice(symbol, false, true)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建市价空单
 	order := gateapi.FuturesOrder{
 		Contract: symbol,
 		Size:     -sizeInt, // 负数 = 开空
-		Price:    "0",      // 市价单
+		Price:    price,    // 市价单（开启最大滑点保护时为限价穿价单）
 		Tif:      "ioc",    // 立即成交或取消
-		Text:     "t-open_short",
+		Text:     t.orderText(OrderPurposeOpen, "open_short"),
 	}
 
 	respOrder, _, err := t.client.FuturesApi.CreateFuturesOrder(t.getClientCtx(), settle, order, nil)
@@ -561,8 +879,10 @@ func (t *GateTrader) OpenShort(symbol string, quantity float64, leverage int) (m
 
 	result := make(map[string]interface{})
 	result["orderId"] = respOrder.Id
+	result["clientOrderId"] = respOrder.Text
 	result["symbol"] = symbol
 	result["status"] = respOrder.Status
+	result["filledQty"] = t.filledQuantity(symbol, respOrder)
 	return result, nil
 }
 
@@ -607,13 +927,18 @@ func (t *GateTrader) CloseShort(symbol string, quantity float64) (map[string]int
 		return nil, fmt.Errorf("无效的平仓数量: %.6f (计算后张数=%d)", quantity, sizeInt)
 	}
 
+	price, err := t.marketOrderPrice(symbol, true, false)
+	if err != nil {
+		return nil, err
+	}
+
 	// 4️⃣ 构建市价平空单（正数代表平空）
 	order := gateapi.FuturesOrder{
 		Contract:   symbol,
-		Size:       sizeInt,         // ❗正数代表平空仓（买入）
-		Price:      "0",             // 市价单
-		Tif:        "ioc",           // 立即成交或取消
-		Text:       "t-close_short", // Gate要求text以`t-`开头
+		Size:       sizeInt,                                       // ❗正数代表平空仓（买入）
+		Price:      price,                                         // 市价单（开启最大滑点保护时为限价穿价单）
+		Tif:        "ioc",                                         // 立即成交或取消
+		Text:       t.orderText(OrderPurposeClose, "close_short"), // Gate要求text以`t-`开头
 		ReduceOnly: true,
 	}
 
@@ -628,6 +953,7 @@ func (t *GateTrader) CloseShort(symbol string, quantity float64) (map[string]int
 
 	result := make(map[string]interface{})
 	result["orderId"] = resp.Id
+	result["clientOrderId"] = resp.Text
 	result["symbol"] = symbol
 	result["status"] = resp.Status
 	return result, nil
@@ -702,7 +1028,7 @@ func (t *GateTrader) SetStopLoss(symbol string, positionSide string, quantity, s
 		Price:      "0",         // 市价单
 		Tif:        "ioc",       // 立即成交
 		Close:      isFullClose, // 全部平仓
-		Text:       fmt.Sprintf("t-stoploss-%s-%d", side, time.Now().Unix()),
+		Text:       t.orderText(OrderPurposeStopLoss, "stoploss-"+side+"-"+strconv.FormatInt(time.Now().Unix(), 36)),
 		ReduceOnly: true,
 	}
 
@@ -794,7 +1120,7 @@ func (t *GateTrader) SetTakeProfit(symbol string, positionSide string, quantity,
 		Price:      "0",         // 市价单
 		Tif:        "ioc",       // 立即成交
 		Close:      isFullClose, // 平仓
-		Text:       fmt.Sprintf("t-takeprofit-%s-%d", side, time.Now().Unix()),
+		Text:       t.orderText(OrderPurposeTakeProfit, "takeprofit-"+side+"-"+strconv.FormatInt(time.Now().Unix(), 36)),
 		ReduceOnly: true,
 	}
 
@@ -812,3 +1138,10 @@ func (t *GateTrader) SetTakeProfit(symbol string, positionSide string, quantity,
 	log.Printf("  止盈价设置: %.4f", takeProfitPrice)
 	return nil
 }
+
+// clearPositionCache 实现 positionCache
+func (t *GateTrader) clearPositionCache() {
+	t.positionsCacheMutex.Lock()
+	t.cachedPositions = nil
+	t.positionsCacheMutex.Unlock()
+}
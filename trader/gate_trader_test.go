@@ -6,13 +6,14 @@ import (
 	"testing"
 )
 
-func getConfig() *config.TraderConfig {
+// getConfig 读取 ../config.json 中的Gate测试网密钥（未配置时跳过，离线测试见 gate_fixture_test.go）
+func getConfig(t *testing.T) *config.TraderConfig {
 	configFile := "../config.json"
 
 	log.Printf("📋 加载配置文件: %s", configFile)
 	cfg, err := config.LoadConfig(configFile)
 	if err != nil {
-		log.Fatalf("❌ 加载配置失败: %v", err)
+		t.Skipf("跳过Gate测试网测试（加载配置失败: %v）", err)
 	}
 
 	for _, traderCfg := range cfg.Traders {
@@ -20,11 +21,12 @@ func getConfig() *config.TraderConfig {
 			return &traderCfg
 		}
 	}
+	t.Skip("跳过Gate测试网测试（config.json中没有Gate交易员）")
 	return nil
 }
 func TestGateGetBalance(t *testing.T) {
 
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	balance, err := trader.GetBalance()
 	if err != nil {
@@ -35,7 +37,7 @@ func TestGateGetBalance(t *testing.T) {
 }
 
 func TestGateListPositions(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	positions, err := trader.GetPositions()
 	if err != nil {
@@ -46,7 +48,7 @@ func TestGateListPositions(t *testing.T) {
 }
 
 func TestGetMarketPrice(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	result, err := trader.GetMarketPrice("ETHUSDT")
 	if err != nil {
@@ -56,7 +58,7 @@ func TestGetMarketPrice(t *testing.T) {
 }
 
 func TestOpenLong(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	result, err := trader.OpenLong("ETH_USDT", 0.1, 5)
 	if err != nil {
@@ -66,7 +68,7 @@ func TestOpenLong(t *testing.T) {
 }
 
 func TestCloseLong(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	result, err := trader.CloseLong("ETH_USDT", 0.1)
 	if err != nil {
@@ -76,7 +78,7 @@ func TestCloseLong(t *testing.T) {
 }
 
 func TestOpenShort(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	result, err := trader.OpenShort("ETH_USDT", 0.1, 5)
 	if err != nil {
@@ -86,7 +88,7 @@ func TestOpenShort(t *testing.T) {
 }
 
 func TestCloseShort(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	result, err := trader.CloseShort("ETH_USDT", 0.1)
 	if err != nil {
@@ -96,7 +98,7 @@ func TestCloseShort(t *testing.T) {
 }
 
 func TestSetTakeProfit(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	err := trader.SetTakeProfit("ETH_USDT", "SHORT", 0.01, 3700)
 	if err != nil {
@@ -105,7 +107,7 @@ func TestSetTakeProfit(t *testing.T) {
 }
 
 func TestSetStopLoss(t *testing.T) {
-	conf := getConfig()
+	conf := getConfig(t)
 	trader, _ := NewGateTrader(conf.GateAPIKey, conf.GateAPISecret, true)
 	err := trader.SetStopLoss("SOL_USDT", "LONG", 1, 150)
 	if err != nil {
@@ -16,8 +16,26 @@ type HyperliquidTrader struct {
 	exchange      *hyperliquid.Exchange
 	ctx           context.Context
 	walletAddr    string
+	signerAddr    string            // 私钥对应的地址（与walletAddr不同时为API代理钱包）
 	meta          *hyperliquid.Meta // 缓存meta信息（包含精度等）
 	isCrossMargin bool             // 是否为全仓模式
+
+	// 订单归属标识（写入cloid）
+	orderTagging
+}
+
+// cloid 生成策略归属的cloid（未设置归属标识时返回nil）
+func (t *HyperliquidTrader) cloid(purpose byte) *string {
+	id := t.nextCloid(purpose)
+	if id == "" {
+		return nil
+	}
+	return &id
+}
+
+// reserveClientOrderID 预留cloid格式的clientOrderId（覆盖 orderTagging 的文本格式）
+func (t *HyperliquidTrader) reserveClientOrderID(purpose byte) string {
+	return t.orderTag.Cloid(purpose)
 }
 
 // NewHyperliquidTrader 创建Hyperliquid交易器
@@ -67,6 +85,7 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 		exchange:      exchange,
 		ctx:           ctx,
 		walletAddr:    walletAddr,
+		signerAddr:    crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
 		meta:          meta,
 		isCrossMargin: true, // 默认使用全仓模式
 	}, nil
@@ -257,7 +276,8 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 				Tif: hyperliquid.TifIoc, // Immediate or Cancel (类似市价单)
 			},
 		},
-		ReduceOnly: false,
+		ReduceOnly:    false,
+		ClientOrderID: t.cloid(OrderPurposeOpen),
 	}
 
 	_, err = t.exchange.Order(t.ctx, order, nil)
@@ -315,7 +335,8 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 				Tif: hyperliquid.TifIoc,
 			},
 		},
-		ReduceOnly: false,
+		ReduceOnly:    false,
+		ClientOrderID: t.cloid(OrderPurposeOpen),
 	}
 
 	_, err = t.exchange.Order(t.ctx, order, nil)
@@ -382,7 +403,8 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 				Tif: hyperliquid.TifIoc,
 			},
 		},
-		ReduceOnly: true, // 只平仓，不开新仓
+		ReduceOnly:    true, // 只平仓，不开新仓
+		ClientOrderID: t.cloid(OrderPurposeClose),
 	}
 
 	_, err = t.exchange.Order(t.ctx, order, nil)
@@ -454,7 +476,8 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 				Tif: hyperliquid.TifIoc,
 			},
 		},
-		ReduceOnly: true,
+		ReduceOnly:    true,
+		ClientOrderID: t.cloid(OrderPurposeClose),
 	}
 
 	_, err = t.exchange.Order(t.ctx, order, nil)
@@ -548,7 +571,8 @@ func (t *HyperliquidTrader) SetStopLoss(symbol string, positionSide string, quan
 				Tpsl:      "sl", // stop loss
 			},
 		},
-		ReduceOnly: true,
+		ReduceOnly:    true,
+		ClientOrderID: t.cloid(OrderPurposeStopLoss),
 	}
 
 	_, err := t.exchange.Order(t.ctx, order, nil)
@@ -585,7 +609,8 @@ func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, qu
 				Tpsl:      "tp", // take profit
 			},
 		},
-		ReduceOnly: true,
+		ReduceOnly:    true,
+		ClientOrderID: t.cloid(OrderPurposeTakeProfit),
 	}
 
 	_, err := t.exchange.Order(t.ctx, order, nil)
@@ -639,6 +664,11 @@ func (t *HyperliquidTrader) roundToSzDecimals(coin string, quantity float64) flo
 	return float64(int(quantity*multiplier+0.5)) / multiplier
 }
 
+// RoundPrice 将价格取整到Hyperliquid要求的5位有效数字
+func (t *HyperliquidTrader) RoundPrice(symbol string, price float64) (float64, error) {
+	return t.roundPriceToSigfigs(price), nil
+}
+
 // roundPriceToSigfigs 将价格四舍五入到5位有效数字
 // Hyperliquid要求价格使用5位有效数字（significant figures）
 func (t *HyperliquidTrader) roundPriceToSigfigs(price float64) float64 {
@@ -677,6 +707,38 @@ func (t *HyperliquidTrader) roundPriceToSigfigs(price float64) float64 {
 	return rounded
 }
 
+// GetCurrencyBalances 获取永续合约（USDC）及现货钱包余额
+func (t *HyperliquidTrader) GetCurrencyBalances() ([]CurrencyBalance, error) {
+	balance, err := t.GetBalance()
+	if err != nil {
+		return nil, err
+	}
+
+	perp := CurrencyBalance{Wallet: "futures", Currency: "USDC"}
+	perp.Total, _ = balance["totalWalletBalance"].(float64)
+	perp.Available, _ = balance["availableBalance"].(float64)
+	perp.UnrealizedPnL, _ = balance["totalUnrealizedProfit"].(float64)
+	balances := []CurrencyBalance{perp}
+
+	spotState, err := t.exchange.Info().SpotUserState(t.ctx, t.walletAddr)
+	if err != nil {
+		log.Printf("⚠️  获取Hyperliquid现货账户失败: %v", err)
+		return balances, nil
+	}
+	for _, bal := range spotState.Balances {
+		total, _ := strconv.ParseFloat(bal.Total, 64)
+		hold, _ := strconv.ParseFloat(bal.Hold, 64)
+		balances = append(balances, CurrencyBalance{
+			Wallet:    "spot",
+			Currency:  bal.Coin,
+			Total:     total,
+			Available: total - hold,
+		})
+	}
+
+	return balances, nil
+}
+
 // convertSymbolToHyperliquid 将标准symbol转换为Hyperliquid格式
 // 例如: "BTCUSDT" -> "BTC"
 func convertSymbolToHyperliquid(symbol string) string {
@@ -687,6 +749,25 @@ func convertSymbolToHyperliquid(symbol string) string {
 	return symbol
 }
 
+// GetFeeSchedule 获取账户实际maker/taker费率（Hyperliquid按账户统一费率）
+func (t *HyperliquidTrader) GetFeeSchedule(symbol string) (*FeeSchedule, error) {
+	fees, err := t.exchange.Info().UserFees(t.ctx, t.walletAddr)
+	if err != nil {
+		return nil, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+
+	maker, err := strconv.ParseFloat(fees.UserAddRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析maker费率失败: %w", err)
+	}
+	taker, err := strconv.ParseFloat(fees.UserCrossRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析taker费率失败: %w", err)
+	}
+
+	return &FeeSchedule{Symbol: symbol, Maker: maker, Taker: taker}, nil
+}
+
 // absFloat 返回浮点数的绝对值
 func absFloat(x float64) float64 {
 	if x < 0 {
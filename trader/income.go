@@ -0,0 +1,112 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// 资金流水类型
+const (
+	IncomeRealizedPnL = "realized_pnl" // 平仓已实现盈亏
+	IncomeCommission  = "commission"   // 手续费（负数=支付）
+	IncomeFunding     = "funding_fee"  // 资金费结算（负数=支付）
+	IncomeTransfer    = "transfer"     // 划转、充提
+	IncomeOther       = "other"        // 其他（返佣、赠金、强平清算费等）
+)
+
+// incomePageSize 单次查询资金流水的最大条数
+const incomePageSize = 1000
+
+// IncomeRecord 合约账户的一条资金流水（USDT等结算币种）
+type IncomeRecord struct {
+	Time   time.Time `json:"time"`
+	Symbol string    `json:"symbol,omitempty"`
+	Type   string    `json:"type"`
+	Asset  string    `json:"asset"`
+	Amount float64   `json:"amount"`
+	Info   string    `json:"info,omitempty"` // 交易所原始类型
+}
+
+// IncomeHistoryProvider 支持查询资金流水的交易器（可选接口，用于余额对账时取得实际结算的资金费及账外变动）
+type IncomeHistoryProvider interface {
+	// GetIncomeHistory 获取 [start, end) 内的全部资金流水
+	GetIncomeHistory(start, end time.Time) ([]IncomeRecord, error)
+}
+
+// incomeType 交易所资金流水类型归类
+func incomeType(raw string) string {
+	switch strings.ToUpper(raw) {
+	case "REALIZED_PNL":
+		return IncomeRealizedPnL
+	case "COMMISSION":
+		return IncomeCommission
+	case "FUNDING_FEE":
+		return IncomeFunding
+	case "TRANSFER", "INTERNAL_TRANSFER", "CROSS_COLLATERAL_TRANSFER", "COIN_SWAP_DEPOSIT", "COIN_SWAP_WITHDRAW":
+		return IncomeTransfer
+	default:
+		return IncomeOther
+	}
+}
+
+// newIncomeRecord 解析交易所返回的资金流水
+func newIncomeRecord(millis int64, symbol, raw, asset, amount string) IncomeRecord {
+	r := IncomeRecord{Time: time.UnixMilli(millis), Symbol: symbol, Type: incomeType(raw), Asset: asset, Info: raw}
+	r.Amount, _ = strconv.ParseFloat(amount, 64)
+	return r
+}
+
+// GetIncomeHistory 实现 IncomeHistoryProvider
+func (t *FuturesTrader) GetIncomeHistory(start, end time.Time) ([]IncomeRecord, error) {
+	var result []IncomeRecord
+	for from := start.UnixMilli(); ; {
+		page, err := t.client.NewGetIncomeHistoryService().
+			StartTime(from).
+			EndTime(end.UnixMilli() - 1).
+			Limit(incomePageSize).
+			Do(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("获取资金流水失败: %w", err)
+		}
+		for _, r := range page {
+			result = append(result, newIncomeRecord(r.Time, r.Symbol, r.IncomeType, r.Asset, r.Income))
+		}
+		if len(page) < incomePageSize {
+			return result, nil
+		}
+		from = page[len(page)-1].Time + 1
+	}
+}
+
+// GetIncomeHistory 实现 IncomeHistoryProvider
+func (t *AsterTrader) GetIncomeHistory(start, end time.Time) ([]IncomeRecord, error) {
+	var result []IncomeRecord
+	for from := start.UnixMilli(); ; {
+		params := map[string]interface{}{
+			"startTime": strconv.FormatInt(from, 10),
+			"endTime":   strconv.FormatInt(end.UnixMilli()-1, 10),
+			"limit":     strconv.Itoa(incomePageSize),
+		}
+		body, err := t.request("GET", "/fapi/v3/income", params)
+		if err != nil {
+			return nil, fmt.Errorf("获取资金流水失败: %w", err)
+		}
+		var page []futures.IncomeHistory
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("解析资金流水失败: %w", err)
+		}
+		for _, r := range page {
+			result = append(result, newIncomeRecord(r.Time, r.Symbol, r.IncomeType, r.Asset, r.Income))
+		}
+		if len(page) < incomePageSize {
+			return result, nil
+		}
+		from = page[len(page)-1].Time + 1
+	}
+}
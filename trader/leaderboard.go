@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/fx"
+	"nofx/logger"
+	"time"
+)
+
+// leaderboardMaxRecords 计算排行榜时最多读取的决策记录数（3分钟周期约20天）
+const leaderboardMaxRecords = 10000
+
+// PerformanceSummary 交易员在统计区间内的表现（用于多策略排行榜）
+type PerformanceSummary struct {
+	TraderID       string    `json:"trader_id"`
+	TraderName     string    `json:"trader_name"`
+	AIModel        string    `json:"ai_model"`
+	Exchange       string    `json:"exchange"`
+	DryRun         bool      `json:"dry_run"`
+	Currency       string    `json:"currency"`         // 金额字段的币种（默认USDT）
+	StartEquity    float64   `json:"start_equity"`     // 区间起始净值（全部区间为初始余额）
+	Equity         float64   `json:"equity"`           // 最新净值
+	PnL            float64   `json:"pnl"`              // 区间盈亏
+	ReturnPct      float64   `json:"return_pct"`       // 区间收益率
+	MaxDrawdownPct float64   `json:"max_drawdown_pct"` // 区间内最大回撤
+	Since          time.Time `json:"since"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	LLMCostUSD     float64   `json:"llm_cost_usd"` // 区间内AI调用估算成本（USD，按交易日统计，不随报告币种换算）
+
+	logger.RealizedBreakdown // 区间内已实现盈亏拆分（价格盈亏、资金费、手续费）及交易次数
+}
+
+// GetPerformanceSummary 根据决策日志统计since之后的收益、回撤、交易次数及已实现盈亏拆分（since为零值时统计全部记录）
+func (at *AutoTrader) GetPerformanceSummary(since time.Time) (*PerformanceSummary, error) {
+	records, err := at.decisionLogger.GetLatestRecords(leaderboardMaxRecords)
+	if err != nil {
+		return nil, fmt.Errorf("读取决策记录失败: %w", err)
+	}
+
+	summary := &PerformanceSummary{
+		TraderID:   at.id,
+		TraderName: at.name,
+		AIModel:    at.aiModel,
+		Exchange:   at.exchange,
+		DryRun:     at.config.DryRun,
+		Currency:   fx.BaseCurrency,
+		Since:      since,
+		UpdatedAt:  time.Now(),
+	}
+	if since.IsZero() {
+		summary.StartEquity = at.initialBalance
+	}
+
+	peak := summary.StartEquity
+	for _, record := range records {
+		if record.Timestamp.Before(since) {
+			continue
+		}
+
+		if equity := record.AccountState.TotalBalance; equity > 0 {
+			if summary.StartEquity <= 0 {
+				summary.StartEquity = equity
+			}
+			summary.Equity = equity
+			if equity > peak {
+				peak = equity
+			}
+			if peak > 0 {
+				if dd := (peak - equity) / peak * 100; dd > summary.MaxDrawdownPct {
+					summary.MaxDrawdownPct = dd
+				}
+			}
+		}
+	}
+	summary.RealizedBreakdown = logger.SummarizeRealized(logger.ReplayRecords(records), since)
+	summary.LLMCostUSD = at.llmCostSince(since)
+
+	if summary.StartEquity > 0 && summary.Equity > 0 {
+		summary.PnL = summary.Equity - summary.StartEquity
+		summary.ReturnPct = summary.PnL / summary.StartEquity * 100
+	}
+	return summary, nil
+}
+
+// InQuote 返回金额字段折算为报告币种后的副本（收益率、回撤不变）
+func (s *PerformanceSummary) InQuote(q fx.Quote) *PerformanceSummary {
+	converted := *s
+	converted.Currency = q.Currency
+	converted.StartEquity = q.Convert(s.StartEquity)
+	converted.Equity = q.Convert(s.Equity)
+	converted.PnL = q.Convert(s.PnL)
+	converted.PricePnL = q.Convert(s.PricePnL)
+	converted.FundingPnL = q.Convert(s.FundingPnL)
+	converted.Fees = q.Convert(s.Fees)
+	converted.NetPnL = q.Convert(s.NetPnL)
+	converted.Volume = q.Convert(s.Volume)
+	return &converted
+}
@@ -0,0 +1,19 @@
+package trader
+
+// 限价单方向
+const (
+	OrderSideBuy  = "BUY"
+	OrderSideSell = "SELL"
+)
+
+// LimitOrderTrader 支持限价挂单、改单和撤单的交易器（可选接口，用于做市等需要挂单的策略）
+type LimitOrderTrader interface {
+	// PlaceLimitOrder 挂限价单（postOnly=true时只做Maker，会立即成交的订单被交易所拒绝），返回订单ID
+	PlaceLimitOrder(symbol, side string, quantity, price float64, postOnly bool) (int64, error)
+
+	// AmendOrder 修改挂单的价格和数量（订单已成交或已撤销时返回错误）
+	AmendOrder(symbol string, orderID int64, side string, quantity, price float64) error
+
+	// CancelOrder 撤销单个挂单
+	CancelOrder(symbol string, orderID int64) error
+}
@@ -0,0 +1,149 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+)
+
+// liqMismatchPct 交易所强平价与本地估算相差超过标记价格的该百分比时告警
+const liqMismatchPct = 2.0
+
+// MaintenanceTier 维持保证金档位（按持仓名义价值分档）
+type MaintenanceTier struct {
+	MaxNotional float64 // 档位名义价值上限（USDT，最后一档为+Inf）
+	Rate        float64 // 维持保证金率
+	Amount      float64 // 速算扣除数（维持保证金 = 名义价值 × Rate - Amount）
+}
+
+// tierTable 档位定义（上限, 维持保证金率），速算扣除数由 buildTiers 自动计算
+type tierTable [][2]float64
+
+// 各交易所的维持保证金档位（取自公开文档的常见档位，个别币种可能不同，仅用于估算和校验）
+var maintenanceTables = map[string]map[string]tierTable{
+	"binance": {
+		"BTCUSDT": {{50e3, 0.004}, {500e3, 0.005}, {8e6, 0.01}, {50e6, 0.025}, {80e6, 0.05}, {100e6, 0.1}, {120e6, 0.125}, {200e6, 0.15}, {300e6, 0.25}, {math.Inf(1), 0.5}},
+		"ETHUSDT": {{50e3, 0.005}, {500e3, 0.0065}, {8e6, 0.01}, {50e6, 0.02}, {80e6, 0.05}, {100e6, 0.1}, {120e6, 0.125}, {200e6, 0.15}, {math.Inf(1), 0.25}},
+		"":        {{5e3, 0.01}, {25e3, 0.025}, {100e3, 0.05}, {250e3, 0.1}, {1e6, 0.125}, {3e6, 0.25}, {math.Inf(1), 0.5}},
+	},
+	"gate": {
+		"BTCUSDT": {{1e6, 0.004}, {5e6, 0.0065}, {10e6, 0.009}, {20e6, 0.0115}, {math.Inf(1), 0.02}},
+		"ETHUSDT": {{1e6, 0.005}, {5e6, 0.0075}, {10e6, 0.01}, {math.Inf(1), 0.02}},
+		"":        {{50e3, 0.01}, {200e3, 0.02}, {1e6, 0.05}, {math.Inf(1), 0.1}},
+	},
+	// Hyperliquid: 维持保证金率 = 1 / (2 × 最大杠杆)，不分档
+	"hyperliquid": {
+		"BTCUSDT": {{math.Inf(1), 1.0 / (2 * 40)}},
+		"ETHUSDT": {{math.Inf(1), 1.0 / (2 * 25)}},
+		"":        {{math.Inf(1), 1.0 / (2 * 10)}},
+	},
+}
+
+func init() {
+	// Aster 与币安使用相同的合约规则
+	maintenanceTables["aster"] = maintenanceTables["binance"]
+}
+
+// buildTiers 根据档位定义计算每档的速算扣除数，保证档位边界处维持保证金连续
+func buildTiers(table tierTable) []MaintenanceTier {
+	tiers := make([]MaintenanceTier, 0, len(table))
+	prevMax, prevRate, amount := 0.0, 0.0, 0.0
+	for i, row := range table {
+		if i > 0 {
+			amount += prevMax * (row[1] - prevRate)
+		}
+		tiers = append(tiers, MaintenanceTier{MaxNotional: row[0], Rate: row[1], Amount: amount})
+		prevMax, prevRate = row[0], row[1]
+	}
+	return tiers
+}
+
+// MaintenanceTierFor 获取持仓名义价值对应的维持保证金档位（未知交易所按币安规则）
+func MaintenanceTierFor(exchange, symbol string, notional float64) MaintenanceTier {
+	tables, ok := maintenanceTables[exchange]
+	if !ok {
+		tables = maintenanceTables["binance"]
+	}
+	table, ok := tables[symbol]
+	if !ok {
+		table = tables[""]
+	}
+	tiers := buildTiers(table)
+	for _, tier := range tiers {
+		if notional <= tier.MaxNotional {
+			return tier
+		}
+	}
+	return tiers[len(tiers)-1]
+}
+
+// EstimateLiquidationPrice 本地估算强平价
+// margin 为可用于承担该持仓亏损的保证金：逐仓为持仓保证金，全仓为账户可用权益
+// 强平条件: margin + 持仓盈亏 = 维持保证金，即 margin + s·Q·(P-EP) = Q·P·rate - amount
+func EstimateLiquidationPrice(exchange, symbol, side string, entryPrice, quantity, margin float64) float64 {
+	quantity = math.Abs(quantity)
+	if entryPrice <= 0 || quantity <= 0 {
+		return 0
+	}
+	tier := MaintenanceTierFor(exchange, symbol, quantity*entryPrice)
+
+	s := 1.0
+	if side == "short" {
+		s = -1.0
+	}
+	price := (s*quantity*entryPrice - margin - tier.Amount) / (quantity * (s - tier.Rate))
+	if price < 0 {
+		return 0
+	}
+	return price
+}
+
+// checkStopBeforeLiquidation 开仓前按逐仓估算强平价，止损价必须在强平价之前触发
+// 全仓模式的实际强平价取决于整个账户权益，按逐仓估算更保守
+func (at *AutoTrader) checkStopBeforeLiquidation(d *decision.Decision, side string, price, quantity float64) error {
+	if d.Leverage <= 0 || d.StopLoss <= 0 {
+		return nil
+	}
+	liqPrice := EstimateLiquidationPrice(at.exchange, d.Symbol, side, price, quantity, d.PositionSizeUSD/float64(d.Leverage))
+	if liqPrice <= 0 {
+		return nil
+	}
+	log.Printf("  🧮 预估强平价: %.4f（%dx，止损 %.4f）", liqPrice, d.Leverage, d.StopLoss)
+
+	if (side == "long" && d.StopLoss <= liqPrice) || (side == "short" && d.StopLoss >= liqPrice) {
+		return fmt.Errorf("❌ %s %s仓止损价 %.4f 在预估强平价 %.4f 之外（%dx杠杆），止损前就会被强平，拒绝开仓",
+			d.Symbol, sideName(side), d.StopLoss, liqPrice, d.Leverage)
+	}
+	return nil
+}
+
+// verifyLiquidationPrices 校验逐仓持仓的交易所强平价与本地估算是否一致（全仓强平价取决于账户整体，不校验）
+func (at *AutoTrader) verifyLiquidationPrices(positions []decision.PositionInfo) []string {
+	var messages []string
+	for _, pos := range positions {
+		posKey := pos.Symbol + "_" + pos.Side
+		if at.isCrossMarginFor(pos.Symbol) || pos.LiquidationPrice <= 0 || pos.Leverage <= 0 || pos.MarkPrice <= 0 {
+			delete(at.liqMismatchWarned, posKey)
+			continue
+		}
+
+		margin := pos.EntryPrice * pos.Quantity / float64(pos.Leverage)
+		local := EstimateLiquidationPrice(at.exchange, pos.Symbol, pos.Side, pos.EntryPrice, pos.Quantity, margin)
+		diffPct := math.Abs(pos.LiquidationPrice-local) / pos.MarkPrice * 100
+		if diffPct <= liqMismatchPct {
+			delete(at.liqMismatchWarned, posKey)
+			continue
+		}
+		if at.liqMismatchWarned[posKey] {
+			continue
+		}
+		at.liqMismatchWarned[posKey] = true
+
+		msg := fmt.Sprintf("⚠️ %s %s仓强平价校验不一致: 交易所 %.4f，本地估算 %.4f（相差标记价格的 %.1f%%），请检查保证金/杠杆设置",
+			pos.Symbol, sideName(pos.Side), pos.LiquidationPrice, local, diffPct)
+		log.Print(msg)
+		messages = append(messages, msg)
+	}
+	return messages
+}
@@ -0,0 +1,282 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/calendar"
+	"nofx/events"
+	"nofx/mcp"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// llmUsageFile AI调用用量记录文件（位于交易员决策日志目录下）
+	llmUsageFile = "llm_usage.json"
+	// llmUsageKeepDays 保留的按天用量记录天数
+	llmUsageKeepDays = 90
+)
+
+// LLMPrice 模型单价（USD / 百万token）
+type LLMPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// defaultLLMPrices 内置的模型公开价格（可通过 llm_prices 覆盖，未知模型按 "*" 计价，未配置时不计成本）
+var defaultLLMPrices = map[string]LLMPrice{
+	"deepseek-chat":     {Input: 0.27, Output: 1.10},
+	"deepseek-reasoner": {Input: 0.55, Output: 2.19},
+	"qwen-turbo":        {Input: 0.05, Output: 0.20},
+	"qwen-plus":         {Input: 0.40, Output: 1.20},
+	"qwen-max":          {Input: 1.60, Output: 6.40},
+}
+
+// ParseLLMPrices 解析模型单价配置，格式: "deepseek-chat=0.27/1.10,*=1/3"（输入/输出，USD每百万token）
+func ParseLLMPrices(s string) (map[string]LLMPrice, error) {
+	prices := make(map[string]LLMPrice)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的模型单价配置: %s（格式: 模型=输入/输出）", item)
+		}
+		model := strings.TrimSpace(parts[0])
+		values := strings.SplitN(parts[1], "/", 2)
+		input, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+		if err != nil || input < 0 {
+			return nil, fmt.Errorf("无效的模型单价: %s", item)
+		}
+		output := input
+		if len(values) == 2 {
+			if output, err = strconv.ParseFloat(strings.TrimSpace(values[1]), 64); err != nil || output < 0 {
+				return nil, fmt.Errorf("无效的模型单价: %s", item)
+			}
+		}
+		prices[model] = LLMPrice{Input: input, Output: output}
+	}
+	return prices, nil
+}
+
+// LLMModelUsage 单个模型的用量
+type LLMModelUsage struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"` // 估算成本
+}
+
+// LLMDailyUsage 某个交易日的AI调用用量
+type LLMDailyUsage struct {
+	Date string `json:"date"` // YYYY-MM-DD（交易日，按配置的时区切换）
+	LLMModelUsage
+	Models map[string]*LLMModelUsage `json:"models"`
+}
+
+// clone 复制用量记录（返回给调用方，避免与后续累计并发读写）
+func (d *LLMDailyUsage) clone() *LLMDailyUsage {
+	copied := &LLMDailyUsage{Date: d.Date, LLMModelUsage: d.LLMModelUsage, Models: make(map[string]*LLMModelUsage, len(d.Models))}
+	for model, u := range d.Models {
+		m := *u
+		copied.Models[model] = &m
+	}
+	return copied
+}
+
+// LLMUsageReport AI调用用量及预算（用于API）
+type LLMUsageReport struct {
+	TraderID       string           `json:"trader_id"`
+	DailyBudgetUSD float64          `json:"daily_budget_usd"` // 0=不限制
+	BudgetExceeded bool             `json:"budget_exceeded"`  // 今日已超出预算（AI决策暂停至下一个交易日）
+	Today          LLMDailyUsage    `json:"today"`
+	History        []*LLMDailyUsage `json:"history"` // 按日期倒序
+}
+
+// llmPrice 查询模型单价（配置优先，其次内置价格，最后 "*"）
+func (at *AutoTrader) llmPrice(model string) (LLMPrice, bool) {
+	if p, ok := at.config.LLMPrices[model]; ok {
+		return p, true
+	}
+	if p, ok := defaultLLMPrices[model]; ok {
+		return p, true
+	}
+	p, ok := at.config.LLMPrices["*"]
+	return p, ok
+}
+
+// recordLLMUsage 累计一次AI调用的token用量及估算成本（mcp.Client.OnUsage 回调，多模型投票时会并发调用）
+func (at *AutoTrader) recordLLMUsage(model string, usage mcp.Usage) {
+	cost := 0.0
+	if price, ok := at.llmPrice(model); ok {
+		cost = (float64(usage.PromptTokens)*price.Input + float64(usage.CompletionTokens)*price.Output) / 1e6
+	}
+
+	at.llmUsageMu.Lock()
+	date := calendar.DayKey(time.Now())
+	day, ok := at.llmUsage[date]
+	if !ok {
+		day = &LLMDailyUsage{Date: date, Models: make(map[string]*LLMModelUsage)}
+		at.llmUsage[date] = day
+	}
+	m, ok := day.Models[model]
+	if !ok {
+		m = &LLMModelUsage{}
+		day.Models[model] = m
+	}
+	for _, u := range []*LLMModelUsage{&day.LLMModelUsage, m} {
+		u.Calls++
+		u.PromptTokens += int64(usage.PromptTokens)
+		u.CompletionTokens += int64(usage.CompletionTokens)
+		u.CostUSD += cost
+	}
+	total := day.CostUSD
+	err := at.saveLLMUsageLocked()
+	at.llmUsageMu.Unlock()
+
+	if err != nil {
+		log.Printf("⚠ [%s] 保存AI用量记录失败: %v", at.name, err)
+	}
+	log.Printf("💰 [%s] AI调用 %s: 输入 %d / 输出 %d tokens，约 $%.4f（今日累计 $%.4f）",
+		at.name, model, usage.PromptTokens, usage.CompletionTokens, cost, total)
+}
+
+// llmBudgetExceeded 今日AI调用成本是否已达到预算
+func (at *AutoTrader) llmBudgetExceeded() (bool, float64) {
+	if at.config.LLMDailyBudgetUSD <= 0 {
+		return false, 0
+	}
+	at.llmUsageMu.Lock()
+	defer at.llmUsageMu.Unlock()
+	spent := 0.0
+	if day, ok := at.llmUsage[calendar.DayKey(time.Now())]; ok {
+		spent = day.CostUSD
+	}
+	return spent >= at.config.LLMDailyBudgetUSD, spent
+}
+
+// checkLLMBudget 预算用尽时返回暂停原因（每天只发布一次通知）
+func (at *AutoTrader) checkLLMBudget() string {
+	exceeded, spent := at.llmBudgetExceeded()
+	if !exceeded {
+		return ""
+	}
+	msg := fmt.Sprintf("💸 今日AI调用成本 $%.4f 已达到预算 $%.2f，AI决策暂停至 %s（持仓保护及止损不受影响）", spent, at.config.LLMDailyBudgetUSD,
+		calendar.NextDayStart(time.Now()).Format("2006-01-02 15:04 MST"))
+	if today := calendar.DayKey(time.Now()); at.llmBudgetNotified != today {
+		at.llmBudgetNotified = today
+		at.publishEvent(events.Event{Type: events.Error, Message: msg})
+	}
+	return msg
+}
+
+// llmCostSince 统计since所在交易日起的AI调用成本
+func (at *AutoTrader) llmCostSince(since time.Time) float64 {
+	from := calendar.DayKey(since)
+	at.llmUsageMu.Lock()
+	defer at.llmUsageMu.Unlock()
+	total := 0.0
+	for date, day := range at.llmUsage {
+		if since.IsZero() || date >= from {
+			total += day.CostUSD
+		}
+	}
+	return total
+}
+
+// GetLLMUsage 获取最近days天的AI调用用量及预算状态
+func (at *AutoTrader) GetLLMUsage(days int) *LLMUsageReport {
+	exceeded, _ := at.llmBudgetExceeded()
+	report := &LLMUsageReport{
+		TraderID:       at.id,
+		DailyBudgetUSD: at.config.LLMDailyBudgetUSD,
+		BudgetExceeded: exceeded,
+	}
+
+	at.llmUsageMu.Lock()
+	defer at.llmUsageMu.Unlock()
+	today := calendar.DayKey(time.Now())
+	report.Today = LLMDailyUsage{Date: today, Models: map[string]*LLMModelUsage{}}
+	if day, ok := at.llmUsage[today]; ok {
+		report.Today = *day.clone()
+	}
+	dates := make([]string, 0, len(at.llmUsage))
+	for date := range at.llmUsage {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	for i, date := range dates {
+		if i >= days {
+			break
+		}
+		report.History = append(report.History, at.llmUsage[date].clone())
+	}
+	return report
+}
+
+// llmStatus 今日AI调用用量摘要（用于状态接口）
+func (at *AutoTrader) llmStatus() map[string]interface{} {
+	report := at.GetLLMUsage(0)
+	return map[string]interface{}{
+		"calls":            report.Today.Calls,
+		"tokens":           report.Today.PromptTokens + report.Today.CompletionTokens,
+		"cost_usd":         report.Today.CostUSD,
+		"daily_budget_usd": report.DailyBudgetUSD,
+		"budget_exceeded":  report.BudgetExceeded,
+	}
+}
+
+// loadLLMUsage 读取已保存的AI用量记录（不存在时返回空记录）
+func loadLLMUsage(path string) map[string]*LLMDailyUsage {
+	usage := make(map[string]*LLMDailyUsage)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return usage
+	}
+	var days []*LLMDailyUsage
+	if err := json.Unmarshal(data, &days); err != nil {
+		log.Printf("⚠ 解析AI用量记录失败: %v，重新开始统计", err)
+		return usage
+	}
+	for _, day := range days {
+		if day.Models == nil {
+			day.Models = make(map[string]*LLMModelUsage)
+		}
+		usage[day.Date] = day
+	}
+	return usage
+}
+
+// saveLLMUsageLocked 保存AI用量记录（需持有 llmUsageMu），超过保留天数的记录被丢弃
+func (at *AutoTrader) saveLLMUsageLocked() error {
+	cutoff := calendar.DayKey(time.Now().AddDate(0, 0, -llmUsageKeepDays))
+	days := make([]*LLMDailyUsage, 0, len(at.llmUsage))
+	for date, day := range at.llmUsage {
+		if date < cutoff {
+			delete(at.llmUsage, date)
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	data, err := json.MarshalIndent(days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化AI用量记录失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(at.llmUsagePath), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp := at.llmUsagePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入AI用量记录失败: %w", err)
+	}
+	return os.Rename(tmp, at.llmUsagePath)
+}
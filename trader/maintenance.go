@@ -0,0 +1,16 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/maintenance"
+	"time"
+)
+
+// checkMaintenance 交易所公告的维护窗口内（含提前量）拒绝开仓
+func (at *AutoTrader) checkMaintenance(symbol string) error {
+	if w, ok := maintenance.Active(at.exchange, symbol, time.Now()); ok {
+		return fmt.Errorf("❌ %s 维护窗口 %s（%s - %s），拒绝开仓 %s",
+			w.Exchange, w.Name, w.Start.Local().Format("01-02 15:04"), w.End.Local().Format("01-02 15:04"), symbol)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSymbolMarginModes 解析按币种配置的仓位模式
+// 格式: "BTCUSDT:cross,ETHUSDT:isolated"，返回 symbol -> 是否全仓
+func ParseSymbolMarginModes(s string) (map[string]bool, error) {
+	modes := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的仓位模式配置: %s（格式应为 SYMBOL:cross 或 SYMBOL:isolated）", item)
+		}
+
+		symbol := strings.ToUpper(strings.TrimSpace(parts[0]))
+		switch strings.ToLower(strings.TrimSpace(parts[1])) {
+		case "cross", "crossed":
+			modes[symbol] = true
+		case "isolated":
+			modes[symbol] = false
+		default:
+			return nil, fmt.Errorf("无效的仓位模式: %s（仅支持 cross 或 isolated）", parts[1])
+		}
+	}
+	return modes, nil
+}
+
+// isCrossMarginFor 获取该币种应使用的仓位模式（未单独配置时使用交易员默认模式）
+func (at *AutoTrader) isCrossMarginFor(symbol string) bool {
+	if isCross, ok := at.config.SymbolMarginModes[symbol]; ok {
+		return isCross
+	}
+	return at.config.IsCrossMargin
+}
@@ -0,0 +1,215 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 行情异常时的处理方式
+const (
+	GuardActionAbort = "abort" // 放弃开仓
+	GuardActionLimit = "limit" // 改为以标记价格为上限的限价单，等待后撤销未成交部分
+)
+
+// defaultGuardLimitWait 改为限价单时的默认等待时间
+const defaultGuardLimitWait = 30 * time.Second
+
+// MarketGuard 市价开仓前的行情保护：最新价偏离标记价格或盘口价差过大（插针、流动性枯竭）时放弃或改为限价单
+type MarketGuard struct {
+	MaxDeviationBps float64       // 最新价相对标记价格的最大偏离（基点，0=不检查）
+	MaxSpreadBps    float64       // 买一卖一最大价差（基点，0=不检查）
+	Action          string        // abort / limit
+	LimitWait       time.Duration // 限价单等待成交时间
+}
+
+// ParseMarketGuard 解析市价单行情保护配置，空字符串返回nil（关闭）
+// 格式: 参数=值,...，如 deviation_bps=50,spread_bps=20,action=limit,wait=30s
+func ParseMarketGuard(spec string) (*MarketGuard, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	g := &MarketGuard{Action: GuardActionAbort, LimitWait: defaultGuardLimitWait}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的行情保护参数: %s（格式应为 参数=值）", item)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "deviation_bps", "spread_bps":
+			bps, err := strconv.ParseFloat(value, 64)
+			if err != nil || bps < 0 {
+				return nil, fmt.Errorf("无效的行情保护阈值 %s: %s（必须为非负数）", key, value)
+			}
+			if key == "deviation_bps" {
+				g.MaxDeviationBps = bps
+			} else {
+				g.MaxSpreadBps = bps
+			}
+		case "action":
+			action := strings.ToLower(value)
+			if action != GuardActionAbort && action != GuardActionLimit {
+				return nil, fmt.Errorf("无效的行情保护处理方式: %s（可选: abort, limit）", value)
+			}
+			g.Action = action
+		case "wait":
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("无效的行情保护等待时间: %s（如 30s、1m）", value)
+			}
+			g.LimitWait = d
+		default:
+			return nil, fmt.Errorf("行情保护不支持参数: %s（可选: deviation_bps, spread_bps, action, wait）", key)
+		}
+	}
+	if g.MaxDeviationBps == 0 && g.MaxSpreadBps == 0 {
+		return nil, fmt.Errorf("行情保护至少需要设置 deviation_bps 或 spread_bps")
+	}
+	return g, nil
+}
+
+// String 配置摘要
+func (g *MarketGuard) String() string {
+	return fmt.Sprintf("偏离标记价格 ≤%.0fbps，价差 ≤%.0fbps，超出时 %s", g.MaxDeviationBps, g.MaxSpreadBps, g.Action)
+}
+
+// GuardReading 一次行情保护检查
+type GuardReading struct {
+	Last         float64
+	Mark         float64
+	Bid          float64
+	Ask          float64
+	DeviationBps float64 // 最新价相对标记价格的偏离
+	SpreadBps    float64 // 买一卖一价差（相对中间价）
+	Reason       string  // 超出阈值的原因（为空表示正常）
+}
+
+// Check 检查最新价与标记价格的偏离及盘口价差
+// 查询失败的项不检查（只在拿到行情数据并确认异常时拦截）
+func (g *MarketGuard) Check(t Trader, symbol string) (*GuardReading, error) {
+	r := &GuardReading{}
+	var err error
+	if r.Last, err = t.GetMarketPrice(symbol); err != nil {
+		return nil, fmt.Errorf("获取最新价失败: %w", err)
+	}
+	var reasons []string
+
+	if g.MaxDeviationBps > 0 {
+		if mark, err := GetPrice(t, symbol, PriceMark); err == nil && mark > 0 {
+			r.Mark = mark
+			r.DeviationBps = math.Abs(r.Last-mark) / mark * 1e4
+			if r.DeviationBps > g.MaxDeviationBps {
+				reasons = append(reasons, fmt.Sprintf("最新价 %.4f 偏离标记价格 %.4f %.1fbps > %.0fbps", r.Last, mark, r.DeviationBps, g.MaxDeviationBps))
+			}
+		}
+	}
+	if g.MaxSpreadBps > 0 {
+		if book, err := GetBookQuote(t, symbol); err == nil && book.Bid > 0 && book.Ask > 0 {
+			r.Bid, r.Ask = book.Bid, book.Ask
+			r.SpreadBps = (book.Ask - book.Bid) / ((book.Ask + book.Bid) / 2) * 1e4
+			if r.SpreadBps > g.MaxSpreadBps {
+				reasons = append(reasons, fmt.Sprintf("盘口价差 %.1fbps > %.0fbps（买一 %.4f / 卖一 %.4f）", r.SpreadBps, g.MaxSpreadBps, book.Bid, book.Ask))
+			}
+		}
+	}
+	r.Reason = strings.Join(reasons, "；")
+	return r, nil
+}
+
+// limitPrice 限价单价格：做多不高于标记价格上方允许偏离，做空不低于下方允许偏离
+func (g *MarketGuard) limitPrice(r *GuardReading, side string) float64 {
+	ref := r.Mark
+	if ref <= 0 {
+		ref = r.Last
+	}
+	band := g.MaxDeviationBps
+	if band <= 0 {
+		band = g.MaxSpreadBps / 2
+	}
+	if side == "long" {
+		price := ref * (1 + band/1e4)
+		if r.Ask > 0 && r.Ask < price {
+			price = r.Ask
+		}
+		return price
+	}
+	price := ref * (1 - band/1e4)
+	if r.Bid > price {
+		price = r.Bid
+	}
+	return price
+}
+
+// executeLimit 以限价单开仓，等待LimitWait后撤销未成交部分（不追市价）
+func (g *MarketGuard) executeLimit(t Trader, r *GuardReading, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error) {
+	limits, ok := t.(LimitOrderTrader)
+	fills, canQuery := t.(PassiveOrderTrader)
+	if !ok || !canQuery {
+		return nil, fmt.Errorf("交易所不支持限价开仓，放弃开仓")
+	}
+
+	price := g.limitPrice(r, side)
+	if rounder, ok := t.(priceRounder); ok {
+		if rounded, err := rounder.RoundPrice(symbol, price); err == nil && rounded > 0 {
+			price = rounded
+		}
+	}
+	orderSide := OrderSideBuy
+	if side == "short" {
+		orderSide = OrderSideSell
+	}
+
+	result := &ExecutionResult{Policy: "guard_limit", Symbol: symbol, Side: side, Requested: quantity, ArrivalPrice: r.Last, StartedAt: time.Now()}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+	orderID, err := limits.PlaceLimitOrder(symbol, orderSide, quantity, price, false)
+	if err != nil {
+		return nil, fmt.Errorf("限价开仓失败: %w", err)
+	}
+	log.Printf("  🛡 %s 限价开仓 %s %.6f @ %.4f，等待 %s", symbol, orderSide, quantity, price, g.LimitWait)
+
+	filled, avgPrice := (&PassiveExecutor{Wait: g.LimitWait}).awaitFill(fills, symbol, orderID)
+	if filled < quantity {
+		if err := limits.CancelOrder(symbol, orderID); err != nil {
+			log.Printf("  ⚠ %s 撤单失败: %v", symbol, err)
+		}
+		if f, p, _, err := fills.GetOrderFill(symbol, orderID); err == nil {
+			filled, avgPrice = f, p
+		}
+	}
+	if avgPrice <= 0 {
+		avgPrice = price
+	}
+	result.addChild(ChildOrder{Time: result.StartedAt, Quantity: filled, Price: avgPrice, OrderID: orderID})
+	result.FinishedAt = time.Now()
+	if result.Filled <= 0 {
+		return nil, fmt.Errorf("限价单 %s 内未成交，已撤单", g.LimitWait)
+	}
+	log.Printf("  ✓ %s 限价开仓成交 %.6f / %.6f，均价 %.4f", symbol, result.Filled, quantity, result.AvgPrice)
+	return result, nil
+}
+
+// guardLimitExecutor 行情异常时替代原执行算法的限价开仓
+type guardLimitExecutor struct {
+	guard   *MarketGuard
+	reading *GuardReading
+}
+
+// Name 实现 Executor
+func (e guardLimitExecutor) Name() string { return "guard_limit" }
+
+// Execute 实现 Executor
+func (e guardLimitExecutor) Execute(t Trader, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error) {
+	return e.guard.executeLimit(t, e.reading, symbol, side, quantity, leverage)
+}
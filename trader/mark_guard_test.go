@@ -0,0 +1,88 @@
+package trader
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// quoteStub 可设置标记价格及盘口的交易器
+type quoteStub struct {
+	*stubTrader
+	mark     float64
+	bid, ask float64
+}
+
+func (q *quoteStub) GetPriceByType(symbol string, priceType PriceType) (float64, error) {
+	return q.mark, nil
+}
+
+func (q *quoteStub) GetBookQuote(symbol string) (*BookQuote, error) {
+	return &BookQuote{Symbol: symbol, Bid: q.bid, Ask: q.ask}, nil
+}
+
+func TestParseMarketGuard(t *testing.T) {
+	g, err := ParseMarketGuard("deviation_bps=50, spread_bps=20, action=limit, wait=10s")
+	if err != nil {
+		t.Fatalf("ParseMarketGuard: %v", err)
+	}
+	want := MarketGuard{MaxDeviationBps: 50, MaxSpreadBps: 20, Action: GuardActionLimit, LimitWait: 10 * time.Second}
+	if *g != want {
+		t.Fatalf("got %+v, want %+v", *g, want)
+	}
+	if g, err := ParseMarketGuard("spread_bps=15"); err != nil || g.Action != GuardActionAbort || g.LimitWait != defaultGuardLimitWait {
+		t.Fatalf("defaults: %+v, %v", g, err)
+	}
+	if g, err := ParseMarketGuard(""); g != nil || err != nil {
+		t.Fatalf("empty spec should disable the guard, got %+v, %v", g, err)
+	}
+	for _, spec := range []string{"action=abort", "deviation_bps=-1", "action=retry,spread_bps=5", "wait=0s,spread_bps=5", "slippage=5"} {
+		if _, err := ParseMarketGuard(spec); err == nil {
+			t.Errorf("ParseMarketGuard(%q) should fail", spec)
+		}
+	}
+}
+
+func TestMarketGuardCheck(t *testing.T) {
+	g := &MarketGuard{MaxDeviationBps: 50, MaxSpreadBps: 20, Action: GuardActionLimit}
+	tests := []struct {
+		name     string
+		mark     float64
+		bid, ask float64
+		reason   string
+	}{
+		{"正常行情", 100.2, 99.99, 100.01, ""},
+		{"插针偏离标记价格", 101, 99.99, 100.01, "偏离标记价格"},
+		{"盘口价差过大", 100, 99.8, 100.2, "盘口价差"},
+		{"标记价格未知时不检查偏离", 0, 99.99, 100.01, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &quoteStub{stubTrader: newStubTrader(), mark: tt.mark, bid: tt.bid, ask: tt.ask}
+			r, err := g.Check(q, "BTCUSDT") // 最新价固定为100
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if (tt.reason == "") != (r.Reason == "") || !strings.Contains(r.Reason, tt.reason) {
+				t.Errorf("reason = %q, want %q", r.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMarketGuardLimitPrice(t *testing.T) {
+	g := &MarketGuard{MaxDeviationBps: 50}
+	r := &GuardReading{Last: 103, Mark: 100, Bid: 102.9, Ask: 103.1}
+	if got := g.limitPrice(r, "long"); math.Abs(got-100.5) > 1e-9 {
+		t.Errorf("long limit = %v, want 100.5（不高于标记价格+50bps）", got)
+	}
+	if got := g.limitPrice(r, "short"); got != 102.9 {
+		t.Errorf("short limit = %v, want 102.9（不低于买一）", got)
+	}
+
+	// 不支持限价单的交易器放弃开仓
+	if _, err := g.executeLimit(newStubTrader(), r, "BTCUSDT", "long", 1, 5); err == nil {
+		t.Error("executeLimit should fail without limit order support")
+	}
+}
@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/sonirico/go-hyperliquid"
+)
+
+// GetOpenOrders 实现 OpenOrderLister
+func (t *AsterTrader) GetOpenOrders() ([]BookOrder, error) {
+	body, err := t.request("GET", "/fapi/v3/openOrders", make(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("获取挂单失败: %w", err)
+	}
+	var orders []struct {
+		OrderID       int64  `json:"orderId"`
+		ClientOrderID string `json:"clientOrderId"`
+		Symbol        string `json:"symbol"`
+		Side          string `json:"side"`
+		PositionSide  string `json:"positionSide"`
+		Type          string `json:"type"`
+		ReduceOnly    bool   `json:"reduceOnly"`
+		ClosePosition bool   `json:"closePosition"`
+		Price         string `json:"price"`
+		StopPrice     string `json:"stopPrice"`
+		OrigQty       string `json:"origQty"`
+		ExecutedQty   string `json:"executedQty"`
+		UpdateTime    int64  `json:"updateTime"`
+	}
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("解析挂单失败: %w", err)
+	}
+	result := make([]BookOrder, 0, len(orders))
+	for _, o := range orders {
+		order := BookOrder{
+			OrderID:       o.OrderID,
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        o.Symbol,
+			Side:          o.Side,
+			PositionSide:  o.PositionSide,
+			Type:          o.Type,
+			ReduceOnly:    o.ReduceOnly || o.ClosePosition,
+			UpdateTime:    o.UpdateTime,
+		}
+		order.Price, _ = strconv.ParseFloat(o.Price, 64)
+		order.StopPrice, _ = strconv.ParseFloat(o.StopPrice, 64)
+		order.Quantity, _ = strconv.ParseFloat(o.OrigQty, 64)
+		order.FilledQty, _ = strconv.ParseFloat(o.ExecutedQty, 64)
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+// GetOpenOrders 实现 OpenOrderLister（含止盈止损触发单）
+func (t *HyperliquidTrader) GetOpenOrders() ([]BookOrder, error) {
+	orders, err := t.exchange.Info().FrontendOpenOrders(t.ctx, t.walletAddr)
+	if err != nil {
+		return nil, fmt.Errorf("获取挂单失败: %w", err)
+	}
+	result := make([]BookOrder, 0, len(orders))
+	for _, o := range orders {
+		side := "BUY"
+		if o.Side == hyperliquid.OrderSideAsk {
+			side = "SELL"
+		}
+		result = append(result, BookOrder{
+			OrderID:    o.Oid,
+			Symbol:     o.Coin + "USDT",
+			Side:       side,
+			Type:       o.OrderType,
+			ReduceOnly: o.ReduceOnly || o.IsPositionTpSl,
+			Price:      o.LimitPx,
+			StopPrice:  o.TriggerPx,
+			Quantity:   o.OrigSz,
+			FilledQty:  o.OrigSz - o.Sz,
+			UpdateTime: o.Timestamp,
+		})
+	}
+	return result, nil
+}
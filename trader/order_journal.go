@@ -0,0 +1,246 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"strings"
+	"time"
+)
+
+// 下单意图状态
+const (
+	OrderIntentPending      = "pending"       // 已写入意图，尚未确认交易所结果（崩溃后需对账）
+	OrderIntentSubmitted    = "submitted"     // 交易所已接受
+	OrderIntentFailed       = "failed"        // 下单失败且交易所无此订单
+	OrderIntentRecovered    = "recovered"     // 重启对账时在交易所找到了订单
+	OrderIntentNotSubmitted = "not_submitted" // 重启对账时确认交易所无此订单
+	OrderIntentUnverified   = "unverified"    // 交易所不支持按clientOrderId查询，无法确认
+)
+
+// OrderIntent 下单意图：提交订单前写入，提交后回写结果
+type OrderIntent struct {
+	ID            int64     `json:"id"`
+	TraderID      string    `json:"trader_id"`
+	Exchange      string    `json:"exchange"`
+	Symbol        string    `json:"symbol"`
+	Action        string    `json:"action"`   // open_long / open_short / close_long / close_short
+	Quantity      float64   `json:"quantity"` // 平仓时0表示全部平仓
+	ClientOrderID string    `json:"client_order_id"`
+	Status        string    `json:"status"`
+	OrderID       int64     `json:"order_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// OrderJournal 下单意图日志（如写入数据库），保证“已发送但未记录”的订单在重启后可以按clientOrderId找回
+type OrderJournal interface {
+	// RecordIntent 提交订单前写入意图（状态为pending）
+	RecordIntent(intent *OrderIntent) error
+
+	// UpdateIntent 回写意图的结果
+	UpdateIntent(clientOrderID, status string, orderID int64, errMsg string) error
+
+	// PendingIntents 获取交易员仍处于pending状态的意图
+	PendingIntents(traderID string) ([]*OrderIntent, error)
+}
+
+// ClientOrder 按clientOrderId查到的交易所订单
+type ClientOrder struct {
+	OrderID   int64
+	Status    string
+	FilledQty float64
+}
+
+// ClientOrderLookup 支持按clientOrderId查询订单的交易器（可选接口，用于下单意图对账）
+type ClientOrderLookup interface {
+	// GetOrderByClientID 查询订单，交易所无此订单时返回 found=false
+	GetOrderByClientID(symbol, clientOrderID string) (order *ClientOrder, found bool, err error)
+}
+
+// journaledTrader 嵌入了 orderTagging、可以预留clientOrderId并写入下单意图的交易器
+type journaledTrader interface {
+	tagging() *orderTagging
+	reserveClientOrderID(purpose byte) string
+}
+
+// SetOrderJournal 设置下单意图日志（模拟盘不记录）
+func (at *AutoTrader) SetOrderJournal(journal OrderJournal) {
+	if journal == nil || at.exchange == "paper" {
+		return
+	}
+	if jt, ok := at.trader.(journaledTrader); ok {
+		jt.tagging().bindJournal(journal, at.id, at.exchange)
+	}
+}
+
+// PlaceOrder 按动作下市价单（open_long/open_short/close_long/close_short，平仓quantity=0表示全部平仓）
+// 交易器绑定了下单意图日志时，先写入带预留clientOrderId的意图再提交，并回写提交结果；
+// 同一交易器的记录下单串行执行，保证预留的clientOrderId被本次下单使用
+func PlaceOrder(t Trader, symbol, action string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return placeOrder(t, symbol, action, quantity, leverage, 0)
+}
+
+// placeOrder 同 PlaceOrder；purpose非0时clientOrderId使用该订单用途（辅助策略的订单，未绑定下单意图日志时也标记）
+// 平仓单提交后核对成交数量（见 placeClose）
+func placeOrder(t Trader, symbol, action string, quantity float64, leverage int, purpose byte) (map[string]interface{}, error) {
+	if strings.HasPrefix(action, "close_") {
+		return placeClose(t, symbol, action, quantity, purpose)
+	}
+	return submitOrder(t, symbol, action, quantity, leverage, purpose)
+}
+
+// submitOrder 写入下单意图并提交一笔订单
+func submitOrder(t Trader, symbol, action string, quantity float64, leverage int, purpose byte) (map[string]interface{}, error) {
+	defer invalidateShadowBook(t)
+	submit := func() (map[string]interface{}, error) {
+		switch action {
+		case "open_long":
+			return t.OpenLong(symbol, quantity, leverage)
+		case "open_short":
+			return t.OpenShort(symbol, quantity, leverage)
+		case "close_long":
+			return t.CloseLong(symbol, quantity)
+		case "close_short":
+			return t.CloseShort(symbol, quantity)
+		}
+		return nil, fmt.Errorf("不支持的下单动作: %s", action)
+	}
+	// 交易器按开仓/平仓取预留的clientOrderId
+	slot := byte(OrderPurposeOpen)
+	if strings.HasPrefix(action, "close_") {
+		slot = OrderPurposeClose
+	}
+	return submitJournaled(t, symbol, action, quantity, purpose, slot, submit)
+}
+
+// submitJournaled 预留clientOrderId（交易器按slot用途取用）并写入下单意图后调用submit提交订单，回写提交结果
+// purpose为写入clientOrderId的订单用途（0表示与slot相同）
+func submitJournaled(t Trader, symbol, action string, quantity float64, purpose, slot byte, submit func() (map[string]interface{}, error)) (map[string]interface{}, error) {
+	jt, ok := t.(journaledTrader)
+	if !ok {
+		return submit()
+	}
+	o := jt.tagging()
+	if o.orderTag.IsZero() || (o.journal == nil && purpose == 0) {
+		return submit()
+	}
+	if purpose == 0 {
+		purpose = slot
+	}
+
+	o.placeMu.Lock()
+	defer o.placeMu.Unlock()
+
+	if o.journal == nil {
+		o.presetClientOrderID(slot, jt.reserveClientOrderID(purpose))
+		defer o.presetClientOrderID(slot, "")
+		return submit()
+	}
+
+	intent := &OrderIntent{
+		TraderID:      o.journalTraderID,
+		Exchange:      o.journalExchange,
+		Symbol:        symbol,
+		Action:        action,
+		Quantity:      quantity,
+		ClientOrderID: jt.reserveClientOrderID(purpose),
+		Status:        OrderIntentPending,
+	}
+	if err := o.journal.RecordIntent(intent); err != nil {
+		return nil, fmt.Errorf("写入下单意图失败，已取消下单: %w", err)
+	}
+
+	o.presetClientOrderID(slot, intent.ClientOrderID)
+	order, err := submit()
+	o.presetClientOrderID(slot, "")
+
+	if err == nil {
+		orderID, _ := order["orderId"].(int64)
+		o.updateIntent(intent, OrderIntentSubmitted, orderID, "")
+		return order, nil
+	}
+
+	// 下单返回错误（如超时）时订单可能已被交易所接受，按clientOrderId确认
+	lookup, ok := t.(ClientOrderLookup)
+	if !ok {
+		o.updateIntent(intent, OrderIntentFailed, 0, err.Error())
+		return nil, err
+	}
+	found, exists, lookupErr := lookup.GetOrderByClientID(symbol, intent.ClientOrderID)
+	switch {
+	case lookupErr != nil:
+		// 无法确认，保持pending，由重启对账处理
+		log.Printf("⚠️ [%s] %s %s 下单失败且无法确认订单是否已提交 (%s): %v", intent.TraderID, symbol, action, intent.ClientOrderID, lookupErr)
+		return nil, err
+	case exists:
+		log.Printf("⚠️ [%s] %s %s 下单返回错误，但交易所已存在订单 %d (%s)，按已提交处理: %v", intent.TraderID, symbol, action, found.OrderID, intent.ClientOrderID, err)
+		o.updateIntent(intent, OrderIntentSubmitted, found.OrderID, err.Error())
+		return map[string]interface{}{
+			"orderId":       found.OrderID,
+			"clientOrderId": intent.ClientOrderID,
+			"symbol":        symbol,
+			"status":        found.Status,
+		}, nil
+	default:
+		o.updateIntent(intent, OrderIntentFailed, 0, err.Error())
+		return nil, err
+	}
+}
+
+// RecoverOrderIntents 启动时对账上次运行遗留的pending下单意图：
+// 按clientOrderId在交易所查询，确认订单是否已提交，避免崩溃导致未知持仓
+func (at *AutoTrader) RecoverOrderIntents() {
+	jt, ok := at.trader.(journaledTrader)
+	if !ok || jt.tagging().journal == nil {
+		return
+	}
+	journal := jt.tagging().journal
+	intents, err := journal.PendingIntents(at.id)
+	if err != nil {
+		log.Printf("⚠️ [%s] 读取未完成的下单意图失败: %v", at.name, err)
+		return
+	}
+	if len(intents) == 0 {
+		return
+	}
+	log.Printf("🔎 [%s] 发现 %d 条上次运行未确认结果的下单意图，开始对账", at.name, len(intents))
+
+	lookup, canLookup := at.trader.(ClientOrderLookup)
+	recovered := 0
+	for _, intent := range intents {
+		if !canLookup {
+			log.Printf("⚠️ [%s] %s 不支持按clientOrderId查询，无法确认 %s %s (%s)，请人工核对持仓", at.name, at.exchange, intent.Symbol, intent.Action, intent.ClientOrderID)
+			at.updateIntentStatus(journal, intent, OrderIntentUnverified, 0, "")
+			continue
+		}
+		order, found, err := lookup.GetOrderByClientID(intent.Symbol, intent.ClientOrderID)
+		if err != nil {
+			log.Printf("⚠️ [%s] 查询订单 %s 失败，下次启动重试: %v", at.name, intent.ClientOrderID, err)
+			continue
+		}
+		if !found {
+			log.Printf("  ✓ [%s] %s %s (%s) 未提交到交易所", at.name, intent.Symbol, intent.Action, intent.ClientOrderID)
+			at.updateIntentStatus(journal, intent, OrderIntentNotSubmitted, 0, "")
+			continue
+		}
+
+		recovered++
+		msg := fmt.Sprintf("🔁 [%s] 崩溃恢复: 找回已提交但未记录的订单 %s %s 订单ID %d 状态 %s 成交 %.6f (%s)",
+			at.name, intent.Symbol, intent.Action, order.OrderID, order.Status, order.FilledQty, intent.ClientOrderID)
+		log.Print(msg)
+		at.updateIntentStatus(journal, intent, OrderIntentRecovered, order.OrderID, "")
+		at.publishEvent(events.Event{Type: events.OrderRecovered, Symbol: intent.Symbol, Action: intent.Action, Message: msg})
+	}
+	if recovered > 0 {
+		log.Printf("🔁 [%s] 对账完成: 找回 %d 笔已提交的订单，持仓将以交易所为准同步", at.name, recovered)
+	}
+}
+
+// updateIntentStatus 回写对账结果
+func (at *AutoTrader) updateIntentStatus(journal OrderJournal, intent *OrderIntent, status string, orderID int64, errMsg string) {
+	if err := journal.UpdateIntent(intent.ClientOrderID, status, orderID, errMsg); err != nil {
+		log.Printf("⚠️ [%s] 更新下单意图 %s 失败: %v", at.name, intent.ClientOrderID, err)
+	}
+}
@@ -0,0 +1,91 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/gateio/gateapi-go/v7"
+	"github.com/sonirico/go-hyperliquid"
+)
+
+// binanceOrderNotFound 币安/Aster “订单不存在”错误码
+const binanceOrderNotFound = -2013
+
+// GetOrderByClientID 按clientOrderId查询币安订单
+func (t *FuturesTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	order, err := t.client.NewGetOrderService().Symbol(symbol).OrigClientOrderID(clientOrderID).Do(context.Background())
+	if err != nil {
+		var apiErr *common.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == binanceOrderNotFound {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("查询订单失败: %w", err)
+	}
+	filled, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	return &ClientOrder{OrderID: order.OrderID, Status: string(order.Status), FilledQty: filled}, true, nil
+}
+
+// GetOrderByClientID 按clientOrderId查询Aster订单
+func (t *AsterTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	body, err := t.request("GET", "/fapi/v3/order", map[string]interface{}{
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), strconv.Itoa(binanceOrderNotFound)) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("查询订单失败: %w", err)
+	}
+	var order struct {
+		OrderID     int64  `json:"orderId"`
+		Status      string `json:"status"`
+		ExecutedQty string `json:"executedQty"`
+	}
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, false, fmt.Errorf("解析订单失败: %w", err)
+	}
+	filled, _ := strconv.ParseFloat(order.ExecutedQty, 64)
+	return &ClientOrder{OrderID: order.OrderID, Status: order.Status, FilledQty: filled}, true, nil
+}
+
+// GetOrderByClientID 按text（t-<clientOrderId>）查询Gate订单（Gate仅支持查询创建后30分钟内的自定义ID）
+func (t *GateTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	order, _, err := t.client.FuturesApi.GetFuturesOrder(t.getClientCtx(), "usdt", "t-"+clientOrderID)
+	if err != nil {
+		var apiErr gateapi.GateAPIError
+		if errors.As(err, &apiErr) && apiErr.Label == "ORDER_NOT_FOUND" {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("查询订单失败: %w", err)
+	}
+	size, left := order.Size, order.Left
+	if size < 0 {
+		size, left = -size, -left
+	}
+	filled, err := t.contractSizeToQuantity(formatSymbolToContract(symbol), size-left)
+	if err != nil {
+		filled = float64(size - left)
+	}
+	return &ClientOrder{OrderID: order.Id, Status: order.Status, FilledQty: filled}, true, nil
+}
+
+// GetOrderByClientID 按cloid查询Hyperliquid订单
+func (t *HyperliquidTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	result, err := t.exchange.Info().QueryOrderByCloid(t.ctx, t.walletAddr, clientOrderID)
+	if err != nil {
+		return nil, false, fmt.Errorf("查询订单失败: %w", err)
+	}
+	if result.Status != hyperliquid.OrderQueryStatusSuccess {
+		return nil, false, nil
+	}
+	order := result.Order.Order
+	origSz, _ := strconv.ParseFloat(order.OrigSz, 64)
+	sz, _ := strconv.ParseFloat(order.Sz, 64)
+	return &ClientOrder{OrderID: order.Oid, Status: string(result.Order.Status), FilledQty: origSz - sz}, true, nil
+}
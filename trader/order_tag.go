@@ -0,0 +1,225 @@
+package trader
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 订单用途（写入clientOrderId，便于区分开仓/平仓/止损/止盈）
+const (
+	OrderPurposeOpen       = 'o'
+	OrderPurposeClose      = 'c'
+	OrderPurposeStopLoss   = 's'
+	OrderPurposeTakeProfit = 'p'
+	OrderPurposeQuote      = 'q' // 做市挂单
+	OrderPurposeHedge      = 'h' // Delta对冲
+	OrderPurposeBasis      = 'b' // 期现套利
+)
+
+// orderTagPrefix 本系统下单的默认clientOrderId前缀
+const orderTagPrefix = "nx"
+
+// maxOrderTagPrefixLen 自定义前缀最大长度（Gate的text去掉 t- 后最长28位）
+const maxOrderTagPrefixLen = 4
+
+// OrderTag 订单归属标识：前缀 + 策略（trader）+ 运行批次
+type OrderTag struct {
+	Prefix   string // clientOrderId前缀（空=nx），可按策略配置以区分不同系统/账户的订单
+	Strategy string // 策略标识（trader ID的8位哈希）
+	Run      string // 运行批次（启动时间的8位十六进制）
+}
+
+// ValidateOrderTagPrefix 校验自定义前缀：1-4位小写字母或数字，以字母开头（空表示使用默认前缀）
+func ValidateOrderTagPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if len(prefix) > maxOrderTagPrefixLen {
+		return fmt.Errorf("订单前缀最长%d位: %s", maxOrderTagPrefixLen, prefix)
+	}
+	for i, c := range prefix {
+		if !(c >= 'a' && c <= 'z') && !(i > 0 && c >= '0' && c <= '9') {
+			return fmt.Errorf("订单前缀只能包含小写字母和数字且以字母开头: %s", prefix)
+		}
+	}
+	return nil
+}
+
+// NewOrderTag 根据trader ID和启动时间生成订单归属标识
+func NewOrderTag(traderID string, start time.Time) OrderTag {
+	return OrderTag{Strategy: StrategyTag(traderID), Run: fmt.Sprintf("%08x", uint32(start.Unix()))}
+}
+
+// StrategyTag 策略标识（trader ID的8位十六进制哈希，满足交易所clientOrderId长度限制）
+func StrategyTag(traderID string) string {
+	h := fnv.New32a()
+	h.Write([]byte(traderID))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// IsZero 是否未设置
+func (t OrderTag) IsZero() bool {
+	return t.Strategy == ""
+}
+
+// prefix 生效的clientOrderId前缀
+func (t OrderTag) prefix() string {
+	if t.Prefix == "" {
+		return orderTagPrefix
+	}
+	return t.Prefix
+}
+
+// orderSeq 进程内订单序号，保证同一秒内的clientOrderId不重复
+var orderSeq uint64
+
+// ClientOrderID 生成文本格式的clientOrderId，如 nx1a2b3c4d.65f0a1b2.o1f（Binance/Aster最长36位，Gate最长28位）
+func (t OrderTag) ClientOrderID(purpose byte) string {
+	if t.IsZero() {
+		return ""
+	}
+	seq := atomic.AddUint64(&orderSeq, 1)
+	return fmt.Sprintf("%s%s.%s.%c%s", t.prefix(), t.Strategy, t.Run, purpose, strconv.FormatUint(seq, 36))
+}
+
+// Cloid 生成Hyperliquid格式的clientOrderId（0x + 32位十六进制: 策略8位 + 批次8位 + 用途2位 + 序号14位）
+// cloid只能是十六进制，不包含前缀，归属仍由策略和批次标识
+func (t OrderTag) Cloid(purpose byte) string {
+	if t.IsZero() {
+		return ""
+	}
+	seq := atomic.AddUint64(&orderSeq, 1)
+	return fmt.Sprintf("0x%s%s%02x%014x", t.Strategy, t.Run, purpose, seq&0xffffffffffffff)
+}
+
+// ParseOrderTag 从clientOrderId（含Gate的 t- 前缀、Hyperliquid的cloid）解析订单归属，非本系统订单返回false
+func ParseOrderTag(clientOrderID string) (OrderTag, bool) {
+	id := strings.TrimPrefix(clientOrderID, "t-")
+
+	if strings.HasPrefix(id, "0x") && len(id) == 34 {
+		return OrderTag{Strategy: id[2:10], Run: id[10:18]}, true
+	}
+
+	// 文本格式: <前缀><策略8位>.<批次8位>.<用途><序号>
+	parts := strings.Split(id, ".")
+	if len(parts) != 3 || len(parts[0]) <= 8 || len(parts[1]) != 8 || parts[2] == "" {
+		return OrderTag{}, false
+	}
+	prefix := parts[0][:len(parts[0])-8]
+	if ValidateOrderTagPrefix(prefix) != nil {
+		return OrderTag{}, false
+	}
+	tag := OrderTag{Strategy: parts[0][len(prefix):], Run: parts[1]}
+	if prefix != orderTagPrefix {
+		tag.Prefix = prefix
+	}
+	return tag, true
+}
+
+// OrderPurposeOf 解析clientOrderId中的订单用途（如 OrderPurposeStopLoss），非本系统格式时返回false
+func OrderPurposeOf(clientOrderID string) (byte, bool) {
+	id := strings.TrimPrefix(clientOrderID, "t-")
+	if strings.HasPrefix(id, "0x") && len(id) == 34 {
+		purpose, err := strconv.ParseUint(id[18:20], 16, 8)
+		return byte(purpose), err == nil
+	}
+	if _, ok := ParseOrderTag(clientOrderID); !ok {
+		return 0, false
+	}
+	return id[strings.LastIndex(id, ".")+1], true
+}
+
+// untaggedText 未设置归属标识时的Gate订单text（t-<前缀>-<说明>）
+func (t OrderTag) untaggedText(label string) string {
+	return "t-" + t.prefix() + "-" + label
+}
+
+// OrderTagger 支持在订单上标记策略归属的交易器（通过交易所的 text/clientOrderId 字段）
+type OrderTagger interface {
+	SetOrderTag(tag OrderTag)
+}
+
+// orderTagging 可嵌入交易器的订单标记实现
+type orderTagging struct {
+	orderTag OrderTag
+
+	// 下单意图日志（见 PlaceOrder）
+	journal         OrderJournal
+	journalTraderID string
+	journalExchange string
+	placeMu         sync.Mutex // 串行化记录下单，保证预留的clientOrderId被本次下单使用
+	presetMu        sync.Mutex
+	preset          map[byte]string // 按订单用途预留的clientOrderId
+}
+
+// SetOrderTag 设置后续订单使用的归属标识
+func (o *orderTagging) SetOrderTag(tag OrderTag) {
+	o.orderTag = tag
+}
+
+// tagging 实现 journaledTrader
+func (o *orderTagging) tagging() *orderTagging {
+	return o
+}
+
+// bindJournal 绑定下单意图日志
+func (o *orderTagging) bindJournal(journal OrderJournal, traderID, exchange string) {
+	o.journal, o.journalTraderID, o.journalExchange = journal, traderID, exchange
+}
+
+// reserveClientOrderID 预先生成文本格式的clientOrderId（Hyperliquid覆盖为cloid格式）
+func (o *orderTagging) reserveClientOrderID(purpose byte) string {
+	return o.orderTag.ClientOrderID(purpose)
+}
+
+// presetClientOrderID 设置（id为空时清除）该用途下一笔订单使用的clientOrderId
+func (o *orderTagging) presetClientOrderID(purpose byte, id string) {
+	o.presetMu.Lock()
+	defer o.presetMu.Unlock()
+	if id == "" {
+		delete(o.preset, purpose)
+		return
+	}
+	if o.preset == nil {
+		o.preset = make(map[byte]string)
+	}
+	o.preset[purpose] = id
+}
+
+// takePreset 取出并清除该用途预留的clientOrderId
+func (o *orderTagging) takePreset(purpose byte) string {
+	o.presetMu.Lock()
+	defer o.presetMu.Unlock()
+	id := o.preset[purpose]
+	delete(o.preset, purpose)
+	return id
+}
+
+// nextClientOrderID 下一笔订单的文本clientOrderId（优先使用预留值）
+func (o *orderTagging) nextClientOrderID(purpose byte) string {
+	if id := o.takePreset(purpose); id != "" {
+		return id
+	}
+	return o.orderTag.ClientOrderID(purpose)
+}
+
+// nextCloid 下一笔订单的Hyperliquid cloid（优先使用预留值）
+func (o *orderTagging) nextCloid(purpose byte) string {
+	if id := o.takePreset(purpose); id != "" {
+		return id
+	}
+	return o.orderTag.Cloid(purpose)
+}
+
+// updateIntent 回写下单意图结果（失败只记录日志，不影响下单结果）
+func (o *orderTagging) updateIntent(intent *OrderIntent, status string, orderID int64, errMsg string) {
+	if err := o.journal.UpdateIntent(intent.ClientOrderID, status, orderID, errMsg); err != nil {
+		log.Printf("⚠️ [%s] 更新下单意图 %s 失败: %v", intent.TraderID, intent.ClientOrderID, err)
+	}
+}
@@ -0,0 +1,150 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 紧急按钮动作
+const (
+	PanicDisableTrading = "disable_trading" // 暂停交易直到手动恢复（先于撤单平仓执行，防止决策周期重新开仓）
+	PanicCancelOrders   = "cancel_orders"   // 撤销全部挂单（含止盈止损单）
+	PanicClosePositions = "close_positions" // 市价平掉全部持仓
+	PanicPage           = "page"            // 呼叫值班人员（发布 kill_switch 紧急告警）
+)
+
+// PanicActions 全部紧急按钮动作（按执行顺序，也是未配置时的默认动作）
+var PanicActions = []string{PanicDisableTrading, PanicCancelOrders, PanicClosePositions, PanicPage}
+
+// haltDuration 紧急停止的暂停时长（直到手动恢复）
+const haltDuration = 365 * 24 * time.Hour
+
+// ParsePanicActions 解析逗号分隔的紧急按钮动作（为空时为全部动作），按执行顺序返回
+func ParsePanicActions(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return PanicActions, nil
+	}
+	selected := make(map[string]bool)
+	for _, a := range strings.Split(spec, ",") {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		valid := false
+		for _, known := range PanicActions {
+			valid = valid || a == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("未知的紧急按钮动作: %s（可选 %s）", a, strings.Join(PanicActions, ","))
+		}
+		selected[a] = true
+	}
+	var actions []string
+	for _, a := range PanicActions {
+		if selected[a] {
+			actions = append(actions, a)
+		}
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("紧急按钮动作不能为空")
+	}
+	return actions, nil
+}
+
+// Halt 紧急停止交易（暂停到手动恢复）：不等待进行中的决策周期，并停止注册的做市、对冲等辅助策略
+// （辅助策略在恢复交易后不会自动重启，需重启服务）
+func (at *AutoTrader) Halt() {
+	until := time.Now().Add(haltDuration)
+	at.haltUntil.Store(until.UnixNano())
+	log.Printf("🛑 [%s] 紧急停止交易，直到手动恢复", at.name)
+
+	at.haltMu.Lock()
+	hooks := at.haltHooks
+	at.haltMu.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// IsHalted 是否处于紧急停止状态
+func (at *AutoTrader) IsHalted() bool {
+	return at.haltUntil.Load() != 0
+}
+
+// checkHalted 紧急停止后拒绝开仓及挂单
+func (at *AutoTrader) checkHalted() error {
+	if at.IsHalted() {
+		return fmt.Errorf("❌ [%s] 已紧急停止交易，拒绝下单", at.name)
+	}
+	return nil
+}
+
+// OnHalt 注册紧急停止时执行的操作（如停止使用本交易员账户的做市、对冲策略）
+func (at *AutoTrader) OnHalt(hook func()) {
+	at.haltMu.Lock()
+	defer at.haltMu.Unlock()
+	at.haltHooks = append(at.haltHooks, hook)
+}
+
+// CancelAllOpenOrders 撤销账户的全部挂单（含止盈止损单），返回撤单前的挂单及撤单的币种
+// 交易所支持查询挂单时按挂单所在币种撤单，否则按持仓币种撤单；共用账户时其他策略的挂单同样会被撤销
+// preview=true 时只返回将要撤销的挂单
+func (at *AutoTrader) CancelAllOpenOrders(preview bool) ([]BookOrder, []string, error) {
+	if err := at.checkWritable(); err != nil {
+		return nil, nil, err
+	}
+	symbolSet := make(map[string]bool)
+	var orders []BookOrder
+	listed := false
+	if lister, ok := at.trader.(OpenOrderLister); ok {
+		list, err := lister.GetOpenOrders()
+		if err == nil {
+			listed = true
+			orders = list
+			for _, o := range list {
+				symbolSet[o.Symbol] = true
+			}
+		}
+	}
+	if !listed {
+		positions, err := at.trader.GetPositions()
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取持仓失败: %w", err)
+		}
+		for _, pos := range positions {
+			if symbol, _ := pos["symbol"].(string); symbol != "" {
+				symbolSet[symbol] = true
+			}
+		}
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	if preview || at.config.DryRun {
+		return orders, symbols, nil
+	}
+
+	defer at.book.Invalidate()
+	var failed []string
+	for _, symbol := range symbols {
+		unlock := at.lockSymbol(symbol)
+		if err := at.trader.CancelAllOrders(symbol); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", symbol, err))
+		}
+		unlock()
+	}
+	if len(failed) > 0 {
+		return orders, symbols, fmt.Errorf("撤单失败: %s", strings.Join(failed, "; "))
+	}
+	return orders, symbols, nil
+}
+
+// PreviewCloseAll 列出批量平仓将要平掉的持仓（不下单）
+func (at *AutoTrader) PreviewCloseAll(filter CloseFilter) ([]CloseResult, error) {
+	return at.closeAllPositions(filter, "", true)
+}
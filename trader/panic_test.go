@@ -0,0 +1,56 @@
+package trader
+
+import (
+	"reflect"
+	"testing"
+)
+
+type orderListStub struct {
+	*closeAllStub
+	orders []BookOrder
+}
+
+func (s *orderListStub) GetOpenOrders() ([]BookOrder, error) { return s.orders, nil }
+
+func TestParsePanicActions(t *testing.T) {
+	actions, err := ParsePanicActions("page, Close_Positions")
+	if err != nil {
+		t.Fatalf("ParsePanicActions: %v", err)
+	}
+	if want := []string{PanicClosePositions, PanicPage}; !reflect.DeepEqual(actions, want) {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+	if actions, _ := ParsePanicActions(""); !reflect.DeepEqual(actions, PanicActions) {
+		t.Fatalf("default actions = %v", actions)
+	}
+	if _, err := ParsePanicActions("cancel_orders,withdraw"); err == nil {
+		t.Fatal("unknown action accepted")
+	}
+}
+
+func TestCancelAllOpenOrders(t *testing.T) {
+	stub := &orderListStub{
+		closeAllStub: &closeAllStub{positionStub: &positionStub{stubTrader: newStubTrader()}, closed: make(map[string]float64)},
+		orders: []BookOrder{
+			{OrderID: 1, Symbol: "ETHUSDT", Type: "STOP_MARKET", ReduceOnly: true},
+			{OrderID: 2, Symbol: "BTCUSDT", Type: "LIMIT"},
+			{OrderID: 3, Symbol: "ETHUSDT", Type: "TAKE_PROFIT_MARKET", ReduceOnly: true},
+		},
+	}
+	at := &AutoTrader{id: "t1", trader: stub}
+
+	orders, symbols, err := at.CancelAllOpenOrders(true)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(orders) != 3 || !reflect.DeepEqual(symbols, []string{"BTCUSDT", "ETHUSDT"}) || len(stub.cancelled) != 0 {
+		t.Fatalf("preview orders=%d symbols=%v cancelled=%v", len(orders), symbols, stub.cancelled)
+	}
+
+	if _, _, err := at.CancelAllOpenOrders(false); err != nil {
+		t.Fatalf("CancelAllOpenOrders: %v", err)
+	}
+	if !reflect.DeepEqual(stub.cancelled, []string{"BTCUSDT", "ETHUSDT"}) {
+		t.Fatalf("cancelled = %v", stub.cancelled)
+	}
+}
@@ -0,0 +1,439 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/market"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// paperFeeRate 模拟盘按taker费率收取手续费
+const paperFeeRate = 0.0005
+
+// paperPosition 模拟持仓
+type paperPosition struct {
+	symbol   string
+	side     string // "long" 或 "short"
+	quantity float64
+	entry    float64
+	leverage int
+}
+
+// paperTrigger 模拟的止损/止盈触发单
+type paperTrigger struct {
+	purpose       byte // OrderPurposeStopLoss 或 OrderPurposeTakeProfit
+	clientOrderID string
+	quantity      float64
+	price         float64
+}
+
+// paperTriggers 同一持仓的止损止盈单
+type paperTriggers struct {
+	stopLoss   *paperTrigger
+	takeProfit *paperTrigger
+}
+
+// PaperTrader 模拟盘交易器：按实时标记价格成交，本地模拟止损/止盈触发，不向交易所下单
+type PaperTrader struct {
+	mu        sync.Mutex
+	wallet    float64
+	positions map[string]*paperPosition // symbol_side -> 持仓
+	triggers  map[string]*paperTriggers // symbol_side -> 触发单
+	leverage  map[string]int            // symbol -> 杠杆
+	lastCheck map[string]time.Time      // symbol -> 上次检查触发单的时间
+	triggered map[string]float64        // clientOrderId -> 已触发成交的数量
+	orderID   int64
+
+	// 行情来源（可替换为回测数据）
+	markPrice func(symbol string) (float64, error)
+	klines    func(symbol string, since time.Time) ([]market.Kline, error)
+
+	// 订单归属标识（写入模拟订单的clientOrderId）
+	orderTagging
+}
+
+// NewPaperTrader 创建模拟盘交易器，initialBalance为初始USDT余额
+func NewPaperTrader(initialBalance float64) *PaperTrader {
+	client := market.NewAPIClient()
+	return &PaperTrader{
+		wallet:    initialBalance,
+		positions: make(map[string]*paperPosition),
+		triggers:  make(map[string]*paperTriggers),
+		leverage:  make(map[string]int),
+		lastCheck: make(map[string]time.Time),
+		triggered: make(map[string]float64),
+		markPrice: func(symbol string) (float64, error) {
+			info, err := market.GetFunding(symbol)
+			if err != nil {
+				return 0, err
+			}
+			if info.MarkPrice <= 0 {
+				return client.GetCurrentPrice(symbol)
+			}
+			return info.MarkPrice, nil
+		},
+		klines: func(symbol string, since time.Time) ([]market.Kline, error) {
+			limit := int(time.Since(since)/time.Minute) + 1
+			if limit > 1000 {
+				limit = 1000
+			}
+			return client.GetKlines(symbol, "1m", limit)
+		},
+	}
+}
+
+// InferBarTrigger 根据一根K线推断止损/止盈是否触发及成交价（用于模拟盘两次检查之间及回测）
+// 开盘价跳空越过触发价时按开盘价成交；同一根K线同时触及止损和止盈时，
+// 按常见的路径假设推断先后：阳线 开→低→高→收，阴线 开→高→低→收
+func InferBarTrigger(side string, stopLoss, takeProfit float64, bar market.Kline) (purpose byte, price float64, ok bool) {
+	long := side == "long"
+	hitStop := func(p float64) bool { return stopLoss > 0 && ((long && p <= stopLoss) || (!long && p >= stopLoss)) }
+	hitTake := func(p float64) bool {
+		return takeProfit > 0 && ((long && p >= takeProfit) || (!long && p <= takeProfit))
+	}
+
+	// 开盘跳空
+	if hitStop(bar.Open) {
+		return OrderPurposeStopLoss, bar.Open, true
+	}
+	if hitTake(bar.Open) {
+		return OrderPurposeTakeProfit, bar.Open, true
+	}
+
+	first, second := bar.Low, bar.High
+	if bar.Close < bar.Open {
+		first, second = bar.High, bar.Low
+	}
+	for _, p := range []float64{first, second} {
+		if hitStop(p) {
+			return OrderPurposeStopLoss, stopLoss, true
+		}
+		if hitTake(p) {
+			return OrderPurposeTakeProfit, takeProfit, true
+		}
+	}
+	return 0, 0, false
+}
+
+// checkTriggers 检查触发单：先用上次检查以来的1分钟K线推断盘中触发，再用当前标记价格检查（调用方持有锁）
+func (t *PaperTrader) checkTriggers() {
+	for posKey, trig := range t.triggers {
+		pos, ok := t.positions[posKey]
+		if !ok {
+			delete(t.triggers, posKey)
+			continue
+		}
+		stopLoss, takeProfit := 0.0, 0.0
+		if trig.stopLoss != nil {
+			stopLoss = trig.stopLoss.price
+		}
+		if trig.takeProfit != nil {
+			takeProfit = trig.takeProfit.price
+		}
+		if stopLoss == 0 && takeProfit == 0 {
+			continue
+		}
+
+		since := t.lastCheck[posKey]
+		now := time.Now()
+		t.lastCheck[posKey] = now
+
+		var bars []market.Kline
+		if !since.IsZero() {
+			if all, err := t.klines(pos.symbol, since); err == nil {
+				for _, bar := range all {
+					if bar.CloseTime >= since.UnixMilli() {
+						bars = append(bars, bar)
+					}
+				}
+			}
+		}
+		if mark, err := t.markPrice(pos.symbol); err == nil {
+			bars = append(bars, market.Kline{Open: mark, High: mark, Low: mark, Close: mark})
+		}
+
+		for _, bar := range bars {
+			purpose, price, hit := InferBarTrigger(pos.side, stopLoss, takeProfit, bar)
+			if !hit {
+				continue
+			}
+			name, trigger := "止盈", trig.takeProfit
+			if purpose == OrderPurposeStopLoss {
+				name, trigger = "止损", trig.stopLoss
+			}
+			quantity := math.Min(pos.quantity, trigger.quantity)
+			if trigger.clientOrderID != "" {
+				t.triggered[trigger.clientOrderID] = quantity
+			}
+			pnl := t.fill(posKey, quantity, price)
+			log.Printf("🧪 [模拟盘] %s %s仓%s触发: 成交价 %.4f，数量 %.4f，已实现盈亏 %+.2f USDT",
+				pos.symbol, sideName(pos.side), name, price, quantity, pnl)
+			break
+		}
+	}
+}
+
+// fill 按价格平掉持仓的部分或全部数量，返回已实现盈亏（调用方持有锁）
+func (t *PaperTrader) fill(posKey string, quantity, price float64) float64 {
+	pos := t.positions[posKey]
+	pnl := (price - pos.entry) * quantity
+	if pos.side == "short" {
+		pnl = -pnl
+	}
+	t.wallet += pnl - quantity*price*paperFeeRate
+
+	pos.quantity -= quantity
+	if pos.quantity <= 1e-12 {
+		delete(t.positions, posKey)
+		delete(t.triggers, posKey)
+		delete(t.lastCheck, posKey)
+	}
+	return pnl
+}
+
+// unrealized 持仓未实现盈亏及占用保证金（调用方持有锁）
+func (t *PaperTrader) unrealized() (pnl, margin float64) {
+	for _, pos := range t.positions {
+		mark, err := t.markPrice(pos.symbol)
+		if err != nil {
+			mark = pos.entry
+		}
+		if pos.side == "long" {
+			pnl += (mark - pos.entry) * pos.quantity
+		} else {
+			pnl += (pos.entry - mark) * pos.quantity
+		}
+		margin += pos.quantity * pos.entry / float64(pos.leverage)
+	}
+	return pnl, margin
+}
+
+// GetBalance 获取模拟账户余额
+func (t *PaperTrader) GetBalance() (map[string]interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checkTriggers()
+
+	pnl, margin := t.unrealized()
+	return map[string]interface{}{
+		"totalWalletBalance":    t.wallet,
+		"availableBalance":      t.wallet + math.Min(pnl, 0) - margin,
+		"totalUnrealizedProfit": pnl,
+	}, nil
+}
+
+// GetPositions 获取模拟持仓（先处理已触发的止损止盈）
+func (t *PaperTrader) GetPositions() ([]map[string]interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checkTriggers()
+
+	var result []map[string]interface{}
+	for _, pos := range t.positions {
+		mark, err := t.markPrice(pos.symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取%s标记价格失败: %w", pos.symbol, err)
+		}
+		pnl := (mark - pos.entry) * pos.quantity
+		if pos.side == "short" {
+			pnl = -pnl
+		}
+		margin := pos.quantity * pos.entry / float64(pos.leverage)
+		result = append(result, map[string]interface{}{
+			"symbol":           pos.symbol,
+			"side":             pos.side,
+			"positionAmt":      pos.quantity,
+			"entryPrice":       pos.entry,
+			"markPrice":        mark,
+			"unRealizedProfit": pnl,
+			"leverage":         float64(pos.leverage),
+			"liquidationPrice": EstimateLiquidationPrice("binance", pos.symbol, pos.side, pos.entry, pos.quantity, margin),
+		})
+	}
+	return result, nil
+}
+
+// open 模拟市价开仓（同方向已有持仓时按均价合并）
+func (t *PaperTrader) open(symbol, side string, quantity float64, leverage int) (map[string]interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checkTriggers()
+
+	if quantity <= 0 || leverage <= 0 {
+		return nil, fmt.Errorf("开仓数量和杠杆必须大于0")
+	}
+	price, err := t.markPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s标记价格失败: %w", symbol, err)
+	}
+
+	pnl, margin := t.unrealized()
+	required := quantity * price / float64(leverage)
+	fee := quantity * price * paperFeeRate
+	if available := t.wallet + math.Min(pnl, 0) - margin; required+fee > available {
+		return nil, fmt.Errorf("模拟账户可用余额不足: 需要 %.2f USDT，可用 %.2f USDT", required+fee, available)
+	}
+
+	posKey := symbol + "_" + side
+	if pos, ok := t.positions[posKey]; ok {
+		pos.entry = (pos.entry*pos.quantity + price*quantity) / (pos.quantity + quantity)
+		pos.quantity += quantity
+		pos.leverage = leverage
+	} else {
+		t.positions[posKey] = &paperPosition{symbol: symbol, side: side, quantity: quantity, entry: price, leverage: leverage}
+	}
+	t.wallet -= fee
+	t.leverage[symbol] = leverage
+
+	log.Printf("🧪 [模拟盘] 开%s仓: %s 数量 %.4f @ %.4f，%dx", sideName(side), symbol, quantity, price, leverage)
+	return t.orderResult(symbol, OrderPurposeOpen, quantity, price), nil
+}
+
+// close 模拟市价平仓（quantity=0表示全部平仓）
+func (t *PaperTrader) close(symbol, side string, quantity float64) (map[string]interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checkTriggers()
+
+	posKey := symbol + "_" + side
+	pos, ok := t.positions[posKey]
+	if !ok {
+		return nil, fmt.Errorf("没有找到 %s 的%s仓", symbol, sideName(side))
+	}
+	price, err := t.markPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s标记价格失败: %w", symbol, err)
+	}
+	if quantity <= 0 || quantity > pos.quantity {
+		quantity = pos.quantity
+	}
+
+	pnl := t.fill(posKey, quantity, price)
+	log.Printf("🧪 [模拟盘] 平%s仓: %s 数量 %.4f @ %.4f，已实现盈亏 %+.2f USDT", sideName(side), symbol, quantity, price, pnl)
+	return t.orderResult(symbol, OrderPurposeClose, quantity, price), nil
+}
+
+// orderResult 模拟订单结果（调用方持有锁）
+func (t *PaperTrader) orderResult(symbol string, purpose byte, quantity, price float64) map[string]interface{} {
+	t.orderID++
+	return map[string]interface{}{
+		"orderId":       t.orderID,
+		"clientOrderId": t.orderTag.ClientOrderID(purpose),
+		"symbol":        symbol,
+		"status":        "FILLED",
+		"price":         price,
+		"quantity":      quantity,
+	}
+}
+
+// OpenLong 模拟开多仓
+func (t *PaperTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.open(symbol, "long", quantity, leverage)
+}
+
+// OpenShort 模拟开空仓
+func (t *PaperTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.open(symbol, "short", quantity, leverage)
+}
+
+// CloseLong 模拟平多仓（quantity=0表示全部平仓）
+func (t *PaperTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.close(symbol, "long", quantity)
+}
+
+// CloseShort 模拟平空仓（quantity=0表示全部平仓）
+func (t *PaperTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.close(symbol, "short", quantity)
+}
+
+// SetLeverage 记录杠杆（下次开仓使用）
+func (t *PaperTrader) SetLeverage(symbol string, leverage int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.leverage[symbol] = leverage
+	return nil
+}
+
+// SetMarginMode 模拟盘按逐仓计算，忽略仓位模式
+func (t *PaperTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
+	return nil
+}
+
+// GetMarketPrice 获取标记价格
+func (t *PaperTrader) GetMarketPrice(symbol string) (float64, error) {
+	return t.markPrice(symbol)
+}
+
+// setTrigger 设置止损/止盈触发单
+func (t *PaperTrader) setTrigger(symbol, positionSide string, purpose byte, quantity, price float64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	side := "long"
+	if positionSide == "SHORT" {
+		side = "short"
+	}
+	posKey := symbol + "_" + side
+	if _, ok := t.positions[posKey]; !ok {
+		return fmt.Errorf("没有找到 %s 的%s仓", symbol, sideName(side))
+	}
+	if t.triggers[posKey] == nil {
+		t.triggers[posKey] = &paperTriggers{}
+	}
+	trigger := &paperTrigger{purpose: purpose, clientOrderID: t.nextClientOrderID(purpose), quantity: quantity, price: price}
+	if purpose == OrderPurposeStopLoss {
+		t.triggers[posKey].stopLoss = trigger
+	} else {
+		t.triggers[posKey].takeProfit = trigger
+	}
+	t.lastCheck[posKey] = time.Now()
+	return nil
+}
+
+// SetStopLoss 设置模拟止损单
+func (t *PaperTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	return t.setTrigger(symbol, positionSide, OrderPurposeStopLoss, quantity, stopPrice)
+}
+
+// SetTakeProfit 设置模拟止盈单
+func (t *PaperTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return t.setTrigger(symbol, positionSide, OrderPurposeTakeProfit, quantity, takeProfitPrice)
+}
+
+// GetOrderByClientID 按clientOrderId查询模拟触发单（已触发为FILLED，未触发为NEW）
+func (t *PaperTrader) GetOrderByClientID(symbol, clientOrderID string) (*ClientOrder, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if quantity, ok := t.triggered[clientOrderID]; ok {
+		return &ClientOrder{Status: "FILLED", FilledQty: quantity}, true, nil
+	}
+	for _, side := range []string{"long", "short"} {
+		trig := t.triggers[symbol+"_"+side]
+		if trig == nil {
+			continue
+		}
+		for _, trigger := range []*paperTrigger{trig.stopLoss, trig.takeProfit} {
+			if trigger != nil && trigger.clientOrderID == clientOrderID {
+				return &ClientOrder{Status: "NEW"}, true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// CancelAllOrders 取消该币种的所有模拟触发单
+func (t *PaperTrader) CancelAllOrders(symbol string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.triggers, symbol+"_long")
+	delete(t.triggers, symbol+"_short")
+	return nil
+}
+
+// FormatQuantity 模拟盘不限制数量精度
+func (t *PaperTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	return strconv.FormatFloat(quantity, 'f', -1, 64), nil
+}
@@ -0,0 +1,124 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// passivePollInterval 挂单期间查询成交的间隔
+const passivePollInterval = time.Second
+
+// PassiveOrderTrader 支持挂单开仓并查询成交的交易器（可选接口，用于先挂单后吃单的执行方式）
+type PassiveOrderTrader interface {
+	// PlaceEntryOrder 挂只做Maker的开仓限价单（side: BUY/SELL），返回订单ID
+	PlaceEntryOrder(symbol, side string, quantity, price float64) (int64, error)
+
+	// GetOrderFill 查询订单已成交数量及均价（done=订单已完全成交或已撤销）
+	GetOrderFill(symbol string, orderID int64) (filled, avgPrice float64, done bool, err error)
+
+	// CancelOrder 撤销单个挂单
+	CancelOrder(symbol string, orderID int64) error
+}
+
+// PassiveExecutor 先在盘口挂只做Maker的限价单，等待Wait后撤单，未成交部分用市价单吃单
+// 适合不急于成交的开仓，以maker费率成交的部分可节省手续费；交易器不支持挂单时退化为市价单
+type PassiveExecutor struct {
+	Wait      time.Duration // 挂单等待时间
+	OffsetBps float64       // 挂单价相对买一（做多）/卖一（做空）向盘口外的偏移（基点，0=挂在盘口）
+}
+
+// Name 实现 Executor
+func (e *PassiveExecutor) Name() string { return "passive" }
+
+// Execute 实现 Executor
+func (e *PassiveExecutor) Execute(t Trader, symbol, side string, quantity float64, leverage int) (*ExecutionResult, error) {
+	orders, ok := t.(PassiveOrderTrader)
+	if !ok {
+		log.Printf("  ⚠ 交易所不支持挂单开仓，%s 改为市价单", symbol)
+		return MarketExecutor{}.Execute(t, symbol, side, quantity, leverage)
+	}
+	book, err := GetBookQuote(t, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExecutionResult{Policy: e.Name(), Symbol: symbol, Side: side, Requested: quantity, StartedAt: time.Now()}
+	result.ArrivalPrice = (book.Bid + book.Ask) / 2
+	orderSide, price := OrderSideBuy, book.Bid*(1-e.OffsetBps/1e4)
+	if side == "short" {
+		orderSide, price = OrderSideSell, book.Ask*(1+e.OffsetBps/1e4)
+	}
+	if rounder, ok := t.(priceRounder); ok {
+		if rounded, err := rounder.RoundPrice(symbol, price); err == nil && rounded > 0 {
+			price = rounded
+		}
+	}
+
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+	orderID, err := orders.PlaceEntryOrder(symbol, orderSide, quantity, price)
+	if err != nil {
+		// 挂单被拒（如价格已穿过盘口）时直接吃单
+		log.Printf("  ⚠ %s 挂单失败，改为市价单: %v", symbol, err)
+		return MarketExecutor{}.Execute(t, symbol, side, quantity, leverage)
+	}
+	log.Printf("  🪤 %s 挂单 %s %.6f @ %.4f，等待 %s", symbol, orderSide, quantity, price, e.Wait)
+
+	filled, avgPrice := e.awaitFill(orders, symbol, orderID)
+	if filled < quantity {
+		if err := orders.CancelOrder(symbol, orderID); err != nil {
+			log.Printf("  ⚠ %s 撤单失败: %v", symbol, err)
+		}
+		// 撤单前可能又有成交
+		if f, p, _, err := orders.GetOrderFill(symbol, orderID); err == nil {
+			filled, avgPrice = f, p
+		}
+	}
+	if avgPrice <= 0 {
+		avgPrice = price
+	}
+	result.addChild(ChildOrder{Time: result.StartedAt, Quantity: filled, Price: avgPrice, OrderID: orderID, Maker: true})
+
+	remaining := quantity - filled
+	if formatted, err := t.FormatQuantity(symbol, remaining); err == nil {
+		if rounded, _ := strconv.ParseFloat(formatted, 64); rounded <= 0 {
+			remaining = 0
+		}
+	}
+	if remaining > 0 {
+		log.Printf("  ⚡ %s 挂单成交 %.6f / %.6f，剩余 %.6f 市价吃单", symbol, filled, quantity, remaining)
+		marketPrice, _ := t.GetMarketPrice(symbol)
+		child, err := marketChild(t, symbol, side, remaining, leverage, marketPrice)
+		result.addChild(child)
+		if err != nil && result.Filled <= 0 {
+			return nil, err
+		}
+	}
+	result.FinishedAt = time.Now()
+	if result.Filled <= 0 {
+		return nil, fmt.Errorf("%s 执行失败，没有成交", e.Name())
+	}
+	return result, nil
+}
+
+// awaitFill 等待挂单成交，完全成交或超过等待时间后返回已成交数量及均价
+func (e *PassiveExecutor) awaitFill(orders PassiveOrderTrader, symbol string, orderID int64) (float64, float64) {
+	deadline := time.Now().Add(e.Wait)
+	filled, avgPrice := 0.0, 0.0
+	for {
+		f, p, done, err := orders.GetOrderFill(symbol, orderID)
+		if err == nil {
+			filled, avgPrice = f, p
+			if done {
+				return filled, avgPrice
+			}
+		}
+		if !time.Now().Before(deadline) {
+			return filled, avgPrice
+		}
+		time.Sleep(passivePollInterval)
+	}
+}
@@ -0,0 +1,252 @@
+package trader
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gateio/gateapi-go/v7"
+)
+
+// mockExchange 记录请求并按路径返回预设响应
+type mockExchange struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []*http.Request
+	forms     []map[string]string
+	bodies    []string
+	responses map[string]string // "METHOD path" -> JSON
+	statuses  map[string]int    // "METHOD path" -> HTTP状态码（未设置时为200）
+}
+
+func newMockExchange(t *testing.T, responses map[string]string) *mockExchange {
+	m := &mockExchange{responses: responses}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ParseForm()
+		form := make(map[string]string)
+		for k := range r.Form {
+			form[k] = r.Form.Get(k)
+		}
+
+		m.mu.Lock()
+		m.requests = append(m.requests, r)
+		m.forms = append(m.forms, form)
+		m.bodies = append(m.bodies, string(body))
+		m.mu.Unlock()
+
+		key := r.Method + " " + r.URL.Path
+		resp, ok := m.responses[key]
+		if !ok {
+			t.Errorf("unexpected request: %s", key)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if status := m.statuses[key]; status != 0 {
+			w.WriteHeader(status)
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(m.Close)
+	return m
+}
+
+// requestsTo 返回发往指定路径的请求参数
+func (m *mockExchange) requestsTo(method, path string) []map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []map[string]string
+	for i, r := range m.requests {
+		if r.Method == method && r.URL.Path == path {
+			result = append(result, m.forms[i])
+		}
+	}
+	return result
+}
+
+// lastBody 返回最后一次发往指定路径的请求体
+func (m *mockExchange) lastBody(method, path string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.requests) - 1; i >= 0; i-- {
+		if m.requests[i].Method == method && m.requests[i].URL.Path == path {
+			return m.bodies[i]
+		}
+	}
+	return ""
+}
+
+func newTestGateTrader(srv *mockExchange) *GateTrader {
+	clientConfig := gateapi.NewConfiguration()
+	clientConfig.BasePath = srv.URL
+	return &GateTrader{
+		client:        gateapi.NewAPIClient(clientConfig),
+		config:        &GateConfig{ApiKey: "key", ApiSecret: "secret", BaseUrl: srv.URL},
+		cacheDuration: 15 * time.Second,
+		specs:         NewSpecRegistry("gate"),
+	}
+}
+
+func TestGatePositionModeSingle(t *testing.T) {
+	srv := newMockExchange(t, map[string]string{
+		"GET /futures/usdt/accounts":                     `{"in_dual_mode": false}`,
+		"GET /futures/usdt/positions":                    `[{"contract": "BTC_USDT", "size": -3, "leverage": "5", "mode": "single"}]`,
+		"GET /futures/usdt/contracts":                    `[{"name": "BTC_USDT", "quanto_multiplier": "0.0001"}]`,
+		"POST /futures/usdt/positions/BTC_USDT/leverage": `{"contract": "BTC_USDT", "leverage": "10"}`,
+		"POST /futures/usdt/positions/cross_mode":        `{"contract": "BTC_USDT"}`,
+	})
+	trader := newTestGateTrader(srv)
+
+	dual, err := trader.IsDualMode()
+	if err != nil || dual {
+		t.Fatalf("IsDualMode = %v, %v; want false", dual, err)
+	}
+
+	if err := trader.SetLeverage("BTCUSDT", 10); err != nil {
+		t.Fatalf("SetLeverage failed: %v", err)
+	}
+	if err := trader.SetMarginMode("BTCUSDT", true); err != nil {
+		t.Fatalf("SetMarginMode failed: %v", err)
+	}
+
+	if n := len(srv.requestsTo("POST", "/futures/usdt/positions/BTC_USDT/leverage")); n != 1 {
+		t.Errorf("single-mode leverage calls = %d, want 1", n)
+	}
+	if n := len(srv.requestsTo("POST", "/futures/usdt/positions/cross_mode")); n != 1 {
+		t.Errorf("single-mode cross_mode calls = %d, want 1", n)
+	}
+	if n := len(srv.requestsTo("GET", "/futures/usdt/accounts")); n != 1 {
+		t.Errorf("position mode lookups = %d, want 1 (cached)", n)
+	}
+
+	positions, err := trader.GetPositions()
+	if err != nil {
+		t.Fatalf("GetPositions failed: %v", err)
+	}
+	if len(positions) != 1 || positions[0]["side"] != "short" {
+		t.Errorf("positions = %+v, want one short", positions)
+	}
+}
+
+func TestGatePositionModeDual(t *testing.T) {
+	srv := newMockExchange(t, map[string]string{
+		"GET /futures/usdt/accounts":                               `{"in_dual_mode": true}`,
+		"GET /futures/usdt/positions":                              `[{"contract": "BTC_USDT", "size": 2, "leverage": "5", "mode": "dual_long"}, {"contract": "BTC_USDT", "size": -1, "leverage": "5", "mode": "dual_short"}]`,
+		"GET /futures/usdt/contracts":                              `[{"name": "BTC_USDT", "quanto_multiplier": "0.0001"}]`,
+		"POST /futures/usdt/dual_comp/positions/BTC_USDT/leverage": `[{"contract": "BTC_USDT", "leverage": "10", "mode": "dual_long"}, {"contract": "BTC_USDT", "leverage": "10", "mode": "dual_short"}]`,
+		"POST /futures/usdt/dual_comp/positions/cross_mode":        `[{"contract": "BTC_USDT", "mode": "dual_long"}, {"contract": "BTC_USDT", "mode": "dual_short"}]`,
+	})
+	trader := newTestGateTrader(srv)
+
+	dual, err := trader.IsDualMode()
+	if err != nil || !dual {
+		t.Fatalf("IsDualMode = %v, %v; want true", dual, err)
+	}
+
+	if err := trader.SetLeverage("BTCUSDT", 10); err != nil {
+		t.Fatalf("SetLeverage failed: %v", err)
+	}
+	if err := trader.SetMarginMode("BTCUSDT", false); err != nil {
+		t.Fatalf("SetMarginMode failed: %v", err)
+	}
+
+	if n := len(srv.requestsTo("POST", "/futures/usdt/dual_comp/positions/BTC_USDT/leverage")); n != 1 {
+		t.Errorf("dual-mode leverage calls = %d, want 1", n)
+	}
+	if n := len(srv.requestsTo("POST", "/futures/usdt/dual_comp/positions/cross_mode")); n != 1 {
+		t.Errorf("dual-mode cross_mode calls = %d, want 1", n)
+	}
+
+	positions, err := trader.GetPositions()
+	if err != nil {
+		t.Fatalf("GetPositions failed: %v", err)
+	}
+	if len(positions) != 2 || positions[0]["side"] != "long" || positions[1]["side"] != "short" {
+		t.Errorf("positions = %+v, want long and short", positions)
+	}
+}
+
+const binanceTestExchangeInfo = `{"symbols": [{"symbol": "BTCUSDT", "quantityPrecision": 3, "filters": [{"filterType": "LOT_SIZE", "stepSize": "0.001", "minQty": "0.001", "maxQty": "1000"}]}]}`
+
+func newTestBinanceTrader(srv *mockExchange) *FuturesTrader {
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = srv.URL
+	return &FuturesTrader{
+		client:        client,
+		cacheDuration: 15 * time.Second,
+		specs:         NewSpecRegistry("binance"),
+	}
+}
+
+func newBinanceMock(t *testing.T, dual bool) *mockExchange {
+	mode := `{"dualSidePosition": false}`
+	if dual {
+		mode = `{"dualSidePosition": true}`
+	}
+	return newMockExchange(t, map[string]string{
+		"GET /fapi/v1/positionSide/dual": mode,
+		"GET /fapi/v1/exchangeInfo":      binanceTestExchangeInfo,
+		"GET /fapi/v2/positionRisk":      `[{"symbol": "BTCUSDT", "positionAmt": "0.01", "leverage": "5"}]`,
+		"POST /fapi/v1/order":            `{"orderId": 1, "symbol": "BTCUSDT", "status": "FILLED"}`,
+		"DELETE /fapi/v1/allOpenOrders":  `{"code": 200, "msg": "success"}`,
+	})
+}
+
+func TestBinancePositionModeOneWay(t *testing.T) {
+	srv := newBinanceMock(t, false)
+	trader := newTestBinanceTrader(srv)
+
+	if _, err := trader.OpenLong("BTCUSDT", 0.01, 5); err != nil {
+		t.Fatalf("OpenLong failed: %v", err)
+	}
+	if _, err := trader.CloseShort("BTCUSDT", 0.01); err != nil {
+		t.Fatalf("CloseShort failed: %v", err)
+	}
+
+	orders := srv.requestsTo("POST", "/fapi/v1/order")
+	if len(orders) != 2 {
+		t.Fatalf("order calls = %d, want 2", len(orders))
+	}
+	if orders[0]["positionSide"] != "BOTH" || orders[0]["reduceOnly"] != "" {
+		t.Errorf("open order params = %+v, want positionSide=BOTH without reduceOnly", orders[0])
+	}
+	if orders[1]["positionSide"] != "BOTH" || orders[1]["reduceOnly"] != "true" {
+		t.Errorf("close order params = %+v, want positionSide=BOTH reduceOnly=true", orders[1])
+	}
+	if n := len(srv.requestsTo("GET", "/fapi/v1/positionSide/dual")); n != 1 {
+		t.Errorf("position mode lookups = %d, want 1 (cached)", n)
+	}
+}
+
+func TestBinancePositionModeHedge(t *testing.T) {
+	srv := newBinanceMock(t, true)
+	trader := newTestBinanceTrader(srv)
+
+	if _, err := trader.OpenShort("BTCUSDT", 0.01, 5); err != nil {
+		t.Fatalf("OpenShort failed: %v", err)
+	}
+	if _, err := trader.CloseLong("BTCUSDT", 0.01); err != nil {
+		t.Fatalf("CloseLong failed: %v", err)
+	}
+
+	orders := srv.requestsTo("POST", "/fapi/v1/order")
+	if len(orders) != 2 {
+		t.Fatalf("order calls = %d, want 2", len(orders))
+	}
+	if orders[0]["positionSide"] != "SHORT" {
+		t.Errorf("open order params = %+v, want positionSide=SHORT", orders[0])
+	}
+	if orders[1]["positionSide"] != "LONG" || orders[1]["reduceOnly"] != "" {
+		t.Errorf("close order params = %+v, want positionSide=LONG without reduceOnly", orders[1])
+	}
+}
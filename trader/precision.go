@@ -0,0 +1,272 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// specRefreshInterval 合约交易规则的刷新间隔（交易规则很少变化，下单及格式化时不再逐次请求）
+const specRefreshInterval = time.Hour
+
+// ContractSpec 合约交易规则
+type ContractSpec struct {
+	Symbol            string  `json:"symbol"`
+	PricePrecision    int     `json:"price_precision"`
+	QuantityPrecision int     `json:"quantity_precision"`
+	TickSize          float64 `json:"tick_size"`  // 价格步进值（0=按价格精度取整）
+	StepSize          float64 `json:"step_size"`  // 数量步进值
+	MinQty            float64 `json:"min_qty"`    // 最小下单量（Gate为合约张数）
+	Multiplier        float64 `json:"multiplier"` // 每张合约对应的标的数量（Gate），其他交易所为1
+}
+
+// RoundPrice 将价格取整到tick size
+func (s ContractSpec) RoundPrice(price float64) float64 {
+	if s.TickSize > 0 {
+		price = roundToTickSize(price, s.TickSize)
+	}
+	multiplier := math.Pow10(s.PricePrecision)
+	return math.Round(price*multiplier) / multiplier
+}
+
+// FormatPrice 按价格精度格式化价格（先取整到tick size）
+func (s ContractSpec) FormatPrice(price float64) string {
+	return strconv.FormatFloat(s.RoundPrice(price), 'f', s.PricePrecision, 64)
+}
+
+// FormatQuantity 按数量精度格式化数量
+func (s ContractSpec) FormatQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', s.QuantityPrecision, 64)
+}
+
+// SpecRegistry 交易所全部合约的交易规则缓存（并发安全）：首次使用时一次性加载，过期或后台刷新时重新加载（失败时沿用旧规则），
+// 重新加载时比较新旧规则，价格步进、数量步进、最小下单量等变化时通知 StartSpecRefresh 注册的回调
+type SpecRegistry struct {
+	exchange string
+
+	mu       sync.RWMutex
+	specs    map[string]ContractSpec
+	loadedAt time.Time
+	load     func() (map[string]ContractSpec, error) // 首个调用方传入的加载函数（后台刷新使用）
+
+	refreshMu sync.Mutex // 串行化加载，并发调用只加载一次
+}
+
+// NewSpecRegistry 创建独立的交易规则缓存（不参与后台刷新）
+func NewSpecRegistry(exchange string) *SpecRegistry {
+	return &SpecRegistry{exchange: exchange}
+}
+
+var (
+	sharedSpecsMu      sync.Mutex
+	sharedSpecs        = make(map[string]*SpecRegistry)
+	specChangeHandlers = make(map[int]func(SpecChange))
+	nextSpecHandlerID  int
+)
+
+// SharedSpecRegistry 获取交易所共用的交易规则缓存（同一交易所的所有策略共用，由 StartSpecRefresh 后台刷新）
+// key 区分交易所及环境，如 binance、gate、gate-testnet
+func SharedSpecRegistry(key string) *SpecRegistry {
+	sharedSpecsMu.Lock()
+	defer sharedSpecsMu.Unlock()
+	r, ok := sharedSpecs[key]
+	if !ok {
+		r = NewSpecRegistry(key)
+		sharedSpecs[key] = r
+	}
+	return r
+}
+
+// SpecChange 合约交易规则变化
+type SpecChange struct {
+	Exchange string       `json:"exchange"`
+	Symbol   string       `json:"symbol"`
+	Old      ContractSpec `json:"old"`
+	New      ContractSpec `json:"new"`
+	Changes  []string     `json:"changes"` // 变化的字段，如 价格步进 0.1 → 0.01
+}
+
+// String 变化摘要
+func (c SpecChange) String() string {
+	return fmt.Sprintf("📏 %s %s 交易规则变化: %s", c.Exchange, c.Symbol, strings.Join(c.Changes, "，"))
+}
+
+// diffSpecs 比较新旧交易规则，返回两边都存在且发生变化的合约（新增/下架的合约由上新及下架检测处理）
+func diffSpecs(exchange string, old, updated map[string]ContractSpec) []SpecChange {
+	var changes []SpecChange
+	for symbol, next := range updated {
+		prev, ok := old[symbol]
+		if !ok || prev == next {
+			continue
+		}
+		var fields []string
+		diff := func(name string, a, b float64) {
+			if a != b {
+				fields = append(fields, fmt.Sprintf("%s %s → %s", name, strconv.FormatFloat(a, 'f', -1, 64), strconv.FormatFloat(b, 'f', -1, 64)))
+			}
+		}
+		diff("价格步进", prev.TickSize, next.TickSize)
+		diff("数量步进", prev.StepSize, next.StepSize)
+		diff("最小下单量", prev.MinQty, next.MinQty)
+		diff("合约乘数", prev.Multiplier, next.Multiplier)
+		diff("价格精度", float64(prev.PricePrecision), float64(next.PricePrecision))
+		diff("数量精度", float64(prev.QuantityPrecision), float64(next.QuantityPrecision))
+		if len(fields) > 0 {
+			changes = append(changes, SpecChange{Exchange: exchange, Symbol: symbol, Old: prev, New: next, Changes: fields})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Symbol < changes[j].Symbol })
+	return changes
+}
+
+// get 查询合约交易规则（缓存有效时不发起网络请求）
+// 缓存过期但包含该合约时提交后台刷新并返回缓存的规则，只有首次加载或缺少该合约时才同步加载
+func (r *SpecRegistry) get(symbol string, load func() (map[string]ContractSpec, error)) (ContractSpec, error) {
+	r.mu.RLock()
+	spec, ok := r.specs[symbol]
+	fresh := r.specs != nil && time.Since(r.loadedAt) < specRefreshInterval
+	r.mu.RUnlock()
+	if fresh {
+		if !ok {
+			return ContractSpec{}, fmt.Errorf("未找到 %s 的交易规则", symbol)
+		}
+		return spec, nil
+	}
+	if ok && runInBackground("spec_refresh", fmt.Sprintf("spec_refresh:%s:%p", r.exchange, r), func(context.Context) (interface{}, error) {
+		return nil, r.refresh(load, false)
+	}) {
+		return spec, nil
+	}
+
+	if err := r.refresh(load, false); err != nil {
+		return ContractSpec{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if spec, ok := r.specs[symbol]; ok {
+		return spec, nil
+	}
+	return ContractSpec{}, fmt.Errorf("未找到 %s 的交易规则", symbol)
+}
+
+// refresh 重新加载交易规则（force=false 时缓存有效则跳过；加载期间不阻塞查询）
+func (r *SpecRegistry) refresh(load func() (map[string]ContractSpec, error), force bool) error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	r.mu.Lock()
+	if r.load == nil {
+		r.load = load
+	}
+	if load == nil {
+		load = r.load
+	}
+	old := r.specs
+	fresh := old != nil && time.Since(r.loadedAt) < specRefreshInterval
+	r.mu.Unlock()
+	if (fresh && !force) || load == nil {
+		return nil
+	}
+
+	specs, err := load()
+	if err != nil {
+		if old == nil {
+			return err
+		}
+		// 沿用旧规则，等下一个刷新周期再试
+		log.Printf("⚠ 刷新 %s 合约交易规则失败，沿用缓存: %v", r.exchange, err)
+		r.mu.Lock()
+		r.loadedAt = time.Now()
+		r.mu.Unlock()
+		return nil
+	}
+
+	changes := diffSpecs(r.exchange, old, specs)
+	r.mu.Lock()
+	r.specs, r.loadedAt = specs, time.Now()
+	r.mu.Unlock()
+	if old == nil {
+		log.Printf("📏 已加载 %d 个合约的交易规则", len(specs))
+	}
+	notifySpecChanges(changes)
+	return nil
+}
+
+// notifySpecChanges 通知交易规则变化
+func notifySpecChanges(changes []SpecChange) {
+	if len(changes) == 0 {
+		return
+	}
+	sharedSpecsMu.Lock()
+	handlers := make([]func(SpecChange), 0, len(specChangeHandlers))
+	for _, h := range specChangeHandlers {
+		handlers = append(handlers, h)
+	}
+	sharedSpecsMu.Unlock()
+	for _, change := range changes {
+		log.Printf("%s", change)
+		for _, h := range handlers {
+			h(change)
+		}
+	}
+}
+
+// StartSpecRefresh 启动共享交易规则的后台刷新：每隔interval重新加载已使用过的交易所规则，
+// 规则变化时回调onChange（可为nil），返回停止函数
+func StartSpecRefresh(interval time.Duration, onChange func(SpecChange)) func() {
+	sharedSpecsMu.Lock()
+	id := nextSpecHandlerID
+	nextSpecHandlerID++
+	if onChange != nil {
+		specChangeHandlers[id] = onChange
+	}
+	sharedSpecsMu.Unlock()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				RefreshSharedSpecs(context.Background())
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+		sharedSpecsMu.Lock()
+		delete(specChangeHandlers, id)
+		sharedSpecsMu.Unlock()
+	}
+}
+
+// RefreshSharedSpecs 强制重新加载所有已使用过的交易所共用交易规则（规则变化时通知 StartSpecRefresh 注册的回调）
+func RefreshSharedSpecs(ctx context.Context) (int, error) {
+	sharedSpecsMu.Lock()
+	registries := make([]*SpecRegistry, 0, len(sharedSpecs))
+	for _, r := range sharedSpecs {
+		registries = append(registries, r)
+	}
+	sharedSpecsMu.Unlock()
+	for _, r := range registries {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if err := r.refresh(nil, true); err != nil {
+			log.Printf("⚠ 刷新 %s 合约交易规则失败: %v", r.exchange, err)
+		}
+	}
+	return len(registries), nil
+}
@@ -0,0 +1,256 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API Key预检模式
+const (
+	PreflightStrict = "strict" // 存在问题时拒绝启动交易员（默认）
+	PreflightWarn   = "warn"   // 只记录告警
+	PreflightOff    = "off"    // 不检查
+)
+
+// preflightCacheTTL 预检结果的缓存时长（启动前检查与Run内的检查共用结果）
+const preflightCacheTTL = 10 * time.Minute
+
+// publicIPURL 查询本机公网出口IP的地址（与交易所IP白名单比对）
+const publicIPURL = "https://api.ipify.org"
+
+// PreflightConfig API Key预检配置
+type PreflightConfig struct {
+	Mode              string // strict / warn / off（无效值按strict处理）
+	RefuseWithdrawals bool   // API Key开启了提现权限时拒绝运行（不受Mode影响）
+}
+
+var preflightConfig = struct {
+	sync.RWMutex
+	cfg PreflightConfig
+}{cfg: PreflightConfig{Mode: PreflightStrict, RefuseWithdrawals: true}}
+
+// SetPreflightConfig 设置API Key预检配置
+func SetPreflightConfig(cfg PreflightConfig) {
+	switch cfg.Mode {
+	case PreflightStrict, PreflightWarn, PreflightOff:
+	default:
+		cfg.Mode = PreflightStrict
+	}
+	preflightConfig.Lock()
+	preflightConfig.cfg = cfg
+	preflightConfig.Unlock()
+}
+
+// getPreflightConfig 当前预检配置
+func getPreflightConfig() PreflightConfig {
+	preflightConfig.RLock()
+	defer preflightConfig.RUnlock()
+	return preflightConfig.cfg
+}
+
+// KeyPermissions API Key权限（指针字段为nil表示交易所不提供该信息）
+type KeyPermissions struct {
+	Trading      *bool    `json:"trading,omitempty"`       // 合约交易
+	Withdrawals  *bool    `json:"withdrawals,omitempty"`   // 提现
+	IPRestricted *bool    `json:"ip_restricted,omitempty"` // 是否限制了访问IP
+	IPWhitelist  []string `json:"ip_whitelist,omitempty"`  // IP白名单（交易所提供时）
+}
+
+// KeyPermissionProvider 可查询API Key权限的交易器（可选接口）
+type KeyPermissionProvider interface {
+	// GetKeyPermissions 获取当前API Key的权限及IP限制
+	GetKeyPermissions() (*KeyPermissions, error)
+}
+
+// PreflightReport API Key预检结果
+type PreflightReport struct {
+	TraderID    string          `json:"trader_id"`
+	Exchange    string          `json:"exchange"`
+	Permissions *KeyPermissions `json:"permissions,omitempty"`
+	PublicIP    string          `json:"public_ip,omitempty"`
+	Problems    []string        `json:"problems"`    // 会导致交易失败的问题
+	Withdrawals bool            `json:"withdrawals"` // 已确认API Key开启了提现权限（开启拒绝时为问题之一）
+	Warnings    []string        `json:"warnings"`    // 安全建议
+	CheckedAt   time.Time       `json:"checked_at"`
+}
+
+// Err 存在问题时返回汇总错误
+func (r *PreflightReport) Err() error {
+	if len(r.Problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s API Key预检未通过: %s", r.Exchange, strings.Join(r.Problems, "；"))
+}
+
+// CheckKeyPermissions 检查API Key能否访问账户、是否开通合约交易权限及IP白名单是否包含本机
+// watchOnly=true 时不要求交易权限，反而建议使用只读Key。
+// 开启提现权限拒绝时，无法确认Key是否可提现（查询失败；Gate、Aster 不提供提现权限查询）也记为问题，strict模式下拒绝运行
+func CheckKeyPermissions(t Trader, exchange string, watchOnly bool) *PreflightReport {
+	report := &PreflightReport{Exchange: exchange, Problems: []string{}, Warnings: []string{}, CheckedAt: time.Now()}
+	if _, err := t.GetBalance(); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("API Key无法访问合约账户（请检查密钥、合约权限及IP白名单）: %v", err))
+		return report
+	}
+
+	provider, ok := t.(KeyPermissionProvider)
+	if !ok {
+		report.Warnings = append(report.Warnings, "交易所不支持查询API Key权限，请自行确认已设置IP白名单")
+		unverifiedWithdrawals(report, "交易所不支持查询API Key权限")
+		return report
+	}
+	perms, err := provider.GetKeyPermissions()
+	if err != nil {
+		unverifiedWithdrawals(report, fmt.Sprintf("无法查询API Key权限: %v", err))
+		return report
+	}
+	report.Permissions = perms
+	if perms.Withdrawals == nil {
+		unverifiedWithdrawals(report, "交易所不提供提现权限查询")
+	}
+
+	if perms.Trading != nil {
+		switch {
+		case !*perms.Trading && !watchOnly:
+			report.Problems = append(report.Problems, "API Key未开启合约交易权限")
+		case *perms.Trading && watchOnly:
+			report.Warnings = append(report.Warnings, "观察模式建议使用只读API Key（当前Key可交易）")
+		}
+	}
+	if perms.Withdrawals != nil && *perms.Withdrawals {
+		report.Withdrawals = true
+		if getPreflightConfig().RefuseWithdrawals {
+			report.Problems = append(report.Problems, "API Key开启了提现权限：一旦服务器被入侵，资产可能被直接提走，拒绝运行（请关闭提现权限或改用API代理钱包，或设置 refuse_withdrawal_keys=false）")
+		} else {
+			report.Warnings = append(report.Warnings, "API Key开启了提现权限，建议关闭")
+		}
+	}
+	if perms.IPRestricted != nil && !*perms.IPRestricted {
+		report.Warnings = append(report.Warnings, "API Key未限制访问IP，建议在交易所设置IP白名单")
+	}
+	if len(perms.IPWhitelist) > 0 {
+		ip, err := fetchPublicIP()
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("无法获取本机公网IP，未校验IP白名单: %v", err))
+			return report
+		}
+		report.PublicIP = ip
+		if !containsString(perms.IPWhitelist, ip) {
+			report.Problems = append(report.Problems, fmt.Sprintf("本机公网IP %s 不在API Key的IP白名单中 (%s)", ip, strings.Join(perms.IPWhitelist, ", ")))
+		}
+	}
+	return report
+}
+
+// containsString 列表中是否包含s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchPublicIP 查询本机公网出口IP
+func fetchPublicIP() (string, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(publicIPURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// Preflight 运行API Key预检（10分钟内返回缓存结果，force=true时重新检查）
+func (at *AutoTrader) Preflight(force bool) *PreflightReport {
+	at.preflightMu.Lock()
+	defer at.preflightMu.Unlock()
+	if !force && at.preflightReport != nil && time.Since(at.preflightReport.CheckedAt) < preflightCacheTTL {
+		return at.preflightReport
+	}
+	report := CheckKeyPermissions(at.trader, at.exchange, at.config.WatchOnly)
+	report.TraderID = at.id
+	at.preflightReport = report
+	return report
+}
+
+// unverifiedWithdrawals 无法确认API Key是否开启了提现权限：开启提现权限拒绝时记为问题（不能假定Key不可提现），否则只提醒
+func unverifiedWithdrawals(report *PreflightReport, reason string) {
+	if getPreflightConfig().RefuseWithdrawals {
+		report.Problems = append(report.Problems, fmt.Sprintf("%s，无法确认API Key已关闭提现权限，拒绝运行（确认已关闭提现权限后可设置 refuse_withdrawal_keys=false）", reason))
+		return
+	}
+	report.Warnings = append(report.Warnings, reason+"，请自行确认已关闭提现权限")
+}
+
+// CheckPreflight 按预检模式检查API Key：strict模式下存在问题时返回错误，warn模式只记录日志
+// 开启提现权限拒绝时，无论预检模式如何，API Key可提现都会返回错误；模拟盘没有API Key，不检查
+func (at *AutoTrader) CheckPreflight() error {
+	cfg := getPreflightConfig()
+	if at.exchange == "paper" || (cfg.Mode == PreflightOff && !cfg.RefuseWithdrawals) {
+		return nil
+	}
+	report := at.Preflight(false)
+	if cfg.RefuseWithdrawals && report.Withdrawals {
+		return fmt.Errorf("%s API Key开启了提现权限，拒绝运行交易员 %s（请关闭提现权限，或设置 refuse_withdrawal_keys=false）", at.exchange, at.name)
+	}
+	if cfg.Mode == PreflightOff {
+		return nil
+	}
+	for _, w := range report.Warnings {
+		log.Printf("⚠️  [%s] API Key预检: %s", at.name, w)
+	}
+	err := report.Err()
+	if err == nil {
+		return nil
+	}
+	if cfg.Mode == PreflightWarn {
+		log.Printf("⚠️  [%s] %v", at.name, err)
+		return nil
+	}
+	return err
+}
+
+// GetKeyPermissions 获取币安API Key的权限（合约交易、提现、IP限制）
+func (t *FuturesTrader) GetKeyPermissions() (*KeyPermissions, error) {
+	perm, err := t.spotClient.NewGetAPIKeyPermission().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取API Key权限失败: %w", err)
+	}
+	return &KeyPermissions{
+		Trading:      &perm.EnableFutures,
+		Withdrawals:  &perm.EnableWithdrawals,
+		IPRestricted: &perm.IPRestrict,
+	}, nil
+}
+
+// GetKeyPermissions 获取Gate API Key的IP白名单（Gate不提供交易/提现权限查询）
+func (t *GateTrader) GetKeyPermissions() (*KeyPermissions, error) {
+	detail, _, err := t.client.AccountApi.GetAccountDetail(t.getClientCtx())
+	if err != nil {
+		return nil, fmt.Errorf("获取账户详情失败: %w", err)
+	}
+	restricted := len(detail.IpWhitelist) > 0
+	return &KeyPermissions{IPRestricted: &restricted, IPWhitelist: detail.IpWhitelist}, nil
+}
+
+// GetKeyPermissions Hyperliquid使用API代理钱包时无法提现；直接使用主钱包私钥时拥有全部权限
+func (t *HyperliquidTrader) GetKeyPermissions() (*KeyPermissions, error) {
+	trading := true
+	withdrawals := strings.EqualFold(t.signerAddr, t.walletAddr)
+	return &KeyPermissions{Trading: &trading, Withdrawals: &withdrawals}, nil
+}
@@ -0,0 +1,83 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"strings"
+)
+
+// PriceType 价格类型
+type PriceType string
+
+const (
+	PriceLast  PriceType = "last"  // 最新成交价
+	PriceMark  PriceType = "mark"  // 标记价格（强平、止损止盈触发及未实现盈亏均按标记价格计算）
+	PriceIndex PriceType = "index" // 指数价格（各现货交易所加权价格）
+)
+
+// ParsePriceType 解析价格类型（空=last）
+func ParsePriceType(s string) (PriceType, error) {
+	switch PriceType(strings.ToLower(strings.TrimSpace(s))) {
+	case "", PriceLast:
+		return PriceLast, nil
+	case PriceMark:
+		return PriceMark, nil
+	case PriceIndex:
+		return PriceIndex, nil
+	default:
+		return "", fmt.Errorf("无效的价格类型: %s（可选: last, mark, index）", s)
+	}
+}
+
+// PriceTypeProvider 支持按价格类型查询价格的交易器（可选接口）
+type PriceTypeProvider interface {
+	// GetPriceByType 获取指定类型的价格
+	GetPriceByType(symbol string, priceType PriceType) (float64, error)
+}
+
+// GetPrice 获取指定类型的价格
+// 交易器不支持按类型查询时，退化为GetMarketPrice返回的最新价
+func GetPrice(t Trader, symbol string, priceType PriceType) (float64, error) {
+	if priceType == PriceLast || priceType == "" {
+		return t.GetMarketPrice(symbol)
+	}
+	if provider, ok := t.(PriceTypeProvider); ok {
+		return provider.GetPriceByType(symbol, priceType)
+	}
+	return t.GetMarketPrice(symbol)
+}
+
+// markPrice 获取标记价格（查询失败时使用fallback）
+func (at *AutoTrader) markPrice(symbol string, fallback float64) float64 {
+	price, err := GetPrice(at.trader, symbol, PriceMark)
+	if err != nil || price <= 0 {
+		log.Printf("  ⚠ %s 获取标记价格失败，使用最新价 %.4f: %v", symbol, fallback, err)
+		return fallback
+	}
+	return price
+}
+
+// checkStopAgainstMark 止损止盈按标记价格触发：开仓前确认二者在标记价格的正确一侧，否则挂单后会立即触发
+func (at *AutoTrader) checkStopAgainstMark(d *decision.Decision, side string, lastPrice float64) error {
+	if d.StopLoss <= 0 && d.TakeProfit <= 0 {
+		return nil
+	}
+	mark := at.markPrice(d.Symbol, lastPrice)
+	if side == "long" {
+		if d.StopLoss > 0 && d.StopLoss >= mark {
+			return fmt.Errorf("❌ %s 多仓止损价 %.4f 不低于标记价格 %.4f，挂单后会立即触发，拒绝开仓", d.Symbol, d.StopLoss, mark)
+		}
+		if d.TakeProfit > 0 && d.TakeProfit <= mark {
+			return fmt.Errorf("❌ %s 多仓止盈价 %.4f 不高于标记价格 %.4f，挂单后会立即触发，拒绝开仓", d.Symbol, d.TakeProfit, mark)
+		}
+		return nil
+	}
+	if d.StopLoss > 0 && d.StopLoss <= mark {
+		return fmt.Errorf("❌ %s 空仓止损价 %.4f 不高于标记价格 %.4f，挂单后会立即触发，拒绝开仓", d.Symbol, d.StopLoss, mark)
+	}
+	if d.TakeProfit > 0 && d.TakeProfit >= mark {
+		return fmt.Errorf("❌ %s 空仓止盈价 %.4f 不低于标记价格 %.4f，挂单后会立即触发，拒绝开仓", d.Symbol, d.TakeProfit, mark)
+	}
+	return nil
+}
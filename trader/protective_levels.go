@@ -0,0 +1,79 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+)
+
+// priceRounder 可按交易对价格步进(tick size)取整的交易器
+type priceRounder interface {
+	RoundPrice(symbol string, price float64) (float64, error)
+}
+
+// roundPrice 将价格取整到交易对的tick size（交易器不支持时原样返回）
+func (at *AutoTrader) roundPrice(symbol string, price float64) float64 {
+	rounder, ok := at.trader.(priceRounder)
+	if !ok || price <= 0 {
+		return price
+	}
+	rounded, err := rounder.RoundPrice(symbol, price)
+	if err != nil || rounded <= 0 {
+		log.Printf("  ⚠ %s 价格取整失败，使用原始价格 %.8f: %v", symbol, price, err)
+		return price
+	}
+	return rounded
+}
+
+// fillPrice 获取持仓的实际成交均价（查询失败时使用下单前的参考价）
+func (at *AutoTrader) fillPrice(symbol, side string, fallback float64) float64 {
+	positions, err := at.getPositions()
+	if err != nil {
+		return fallback
+	}
+	for _, pos := range positions {
+		if pos["symbol"] == symbol && pos["side"] == side {
+			if entry, ok := pos["entryPrice"].(float64); ok && entry > 0 {
+				return entry
+			}
+		}
+	}
+	return fallback
+}
+
+// resolveFillLevels 开仓成交后按实际成交价重新换算百分比/R倍数形式的止损止盈，并按tick size取整
+// 换算失败时保留下单前按参考价换算的结果
+func (at *AutoTrader) resolveFillLevels(d *decision.Decision, side string, referencePrice float64) {
+	if !d.HasRelativeLevels() {
+		return
+	}
+	fill := at.fillPrice(d.Symbol, side, referencePrice)
+	resolved := *d
+	if err := resolved.ResolveLevels(fill); err != nil {
+		log.Printf("  ⚠ 按成交价换算止损止盈失败，沿用参考价结果: %v", err)
+		return
+	}
+	d.StopLoss = at.roundPrice(d.Symbol, resolved.StopLoss)
+	d.TakeProfit = at.roundPrice(d.Symbol, resolved.TakeProfit)
+	log.Printf("  📐 按成交价 %.4f 换算: 止损 %.4f, 止盈 %.4f", fill, d.StopLoss, d.TakeProfit)
+}
+
+// snapshotProtectiveLevels 将跟踪中的止盈止损价写入持仓快照（用于持仓生命周期回放）
+func (at *AutoTrader) snapshotProtectiveLevels(snapshots []logger.PositionSnapshot) {
+	for i := range snapshots {
+		if tracked, ok := at.trackedPositions[snapshots[i].Symbol+"_"+snapshots[i].Side]; ok {
+			snapshots[i].StopLoss = tracked.stopLoss
+			snapshots[i].TakeProfit = tracked.takeProfit
+		}
+	}
+}
+
+// snapshotFundingRates 将本周期的资金费率写入持仓快照（用于盈亏的资金费归因）
+func snapshotFundingRates(snapshots []logger.PositionSnapshot, marketData map[string]*market.Data) {
+	for i := range snapshots {
+		if data, ok := marketData[snapshots[i].Symbol]; ok && data != nil {
+			snapshots[i].FundingRate = data.FundingRate
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/events"
+	"nofx/logger"
+	"nofx/market"
+	"strings"
+	"time"
+)
+
+// RecurringEntry 定投计划的一次开仓
+type RecurringEntry struct {
+	PlanID      int64
+	Symbol      string
+	Side        string // long / short
+	NotionalUSD float64
+	Leverage    int
+}
+
+// ExecuteRecurringOrder 执行一次定投开仓（固定名义价值，加仓到已有同向持仓，不设止盈止损）
+// 与AI开仓的区别: 允许叠加本策略的同向持仓，不受交易频率限制；禁止开仓窗口、资金费结算时间、风控暂停、
+// 净额规则及策略资金分配上限仍然生效。结果写入决策日志并发布 recurring_order 事件
+func (at *AutoTrader) ExecuteRecurringOrder(entry RecurringEntry) (*logger.DecisionAction, error) {
+	if !at.isRunning {
+		return nil, fmt.Errorf("交易员 %s 未运行，跳过定投", at.name)
+	}
+	if err := at.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+
+	entry.Symbol = market.Normalize(entry.Symbol)
+	entry.Side = strings.ToLower(entry.Side)
+	label := fmt.Sprintf("定投计划 #%d: %s %s %.2f USDT (%dx)", entry.PlanID, entry.Symbol, sideName(entry.Side), entry.NotionalUSD, entry.Leverage)
+	action := logger.DecisionAction{
+		Action:    "open_" + entry.Side,
+		Symbol:    entry.Symbol,
+		Leverage:  entry.Leverage,
+		Timestamp: time.Now(),
+		Context:   tradeContext("定投计划", label, 0, entry.Symbol, nil),
+	}
+	log.Printf("🗓  [%s] %s", at.name, label)
+
+	execErr := at.executeRecurringEntry(entry, &action)
+
+	decisionJSON, _ := json.Marshal([]decision.Decision{{
+		Symbol:          entry.Symbol,
+		Action:          action.Action,
+		Leverage:        entry.Leverage,
+		PositionSizeUSD: entry.NotionalUSD,
+		Reasoning:       label,
+	}})
+	record := &logger.DecisionRecord{
+		Timestamp:    time.Now(),
+		CycleNumber:  at.callCount,
+		CoTTrace:     label,
+		DecisionJSON: string(decisionJSON),
+	}
+	event := events.Event{
+		Type:     events.RecurringOrder,
+		Symbol:   entry.Symbol,
+		Side:     entry.Side,
+		Action:   action.Action,
+		Quantity: action.Quantity,
+		Price:    action.Price,
+		OrderID:  action.OrderID,
+	}
+	if execErr != nil {
+		action.Error = execErr.Error()
+		record.ErrorMessage = execErr.Error()
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s 失败: %v", label, execErr))
+		event.Message = fmt.Sprintf("%s 失败: %v", label, execErr)
+	} else {
+		action.Success = true
+		record.Success = true
+		if action.DryRun {
+			record.ExecutionLog = append(record.ExecutionLog, "🧪 "+action.Preview)
+			event.Message = label + "（预演）"
+		} else {
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s 成功，数量 %.6f @ %.4f", label, action.Quantity, action.Price))
+			event.Message = label + " 成功"
+		}
+	}
+	record.Decisions = append(record.Decisions, action)
+	if err := at.decisionLogger.LogDecision(record); err != nil {
+		log.Printf("⚠ 保存决策记录失败: %v", err)
+	}
+	at.publishEvent(event)
+
+	if execErr != nil {
+		return &action, execErr
+	}
+	return &action, nil
+}
+
+// executeRecurringEntry 定投开仓的风控检查及下单
+func (at *AutoTrader) executeRecurringEntry(entry RecurringEntry, actionRecord *logger.DecisionAction) error {
+	if entry.Side != "long" && entry.Side != "short" {
+		return fmt.Errorf("无效的方向: %s（可选: long, short）", entry.Side)
+	}
+	if entry.NotionalUSD <= 0 || entry.Leverage <= 0 {
+		return fmt.Errorf("名义价值和杠杆必须大于0")
+	}
+	if err := at.checkLeverageLimit(entry.Symbol, entry.Leverage); err != nil {
+		return err
+	}
+	if until := at.PausedUntil(); !until.IsZero() {
+		return fmt.Errorf("风控暂停中（至 %s），跳过定投", until.Format("15:04:05"))
+	}
+	if err := at.checkSymbolFilter(entry.Symbol); err != nil {
+		return err
+	}
+	if err := at.checkBlackout(entry.Symbol); err != nil {
+		return err
+	}
+	if err := at.checkMaintenance(entry.Symbol); err != nil {
+		return err
+	}
+	if err := at.checkDelisting(entry.Symbol); err != nil {
+		return err
+	}
+	if err := at.checkFundingWindow(entry.Symbol, "开仓"); err != nil {
+		return err
+	}
+	// 不加仓到其他策略的持仓
+	if owner, ok := allocations.owner(at.accountKey(), entry.Symbol+"_"+entry.Side); ok && owner != at.id {
+		return fmt.Errorf("❌ %s %s仓属于其他策略(%s)，拒绝定投加仓", entry.Symbol, sideName(entry.Side), owner)
+	}
+
+	marketData, err := market.Get(entry.Symbol)
+	if err != nil {
+		return err
+	}
+	quantity := entry.NotionalUSD / marketData.CurrentPrice
+	actionRecord.Quantity = quantity
+	actionRecord.Price = marketData.CurrentPrice
+	actionRecord.FeeRate = at.getFeeSchedule(entry.Symbol).Taker
+
+	positions, err := at.getPositions()
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	existingQty := 0.0
+	for _, pos := range positions {
+		if pos["symbol"] == entry.Symbol && pos["side"] == entry.Side {
+			existingQty, _ = pos["positionAmt"].(float64)
+			existingQty = math.Abs(existingQty)
+		}
+	}
+	// 已占用的保证金（含本策略已有的同向持仓）已计入分配上限，只需检查本次新增部分
+	margin := entry.NotionalUSD / float64(entry.Leverage)
+	if err := at.checkAllocation(entry.Symbol, entry.Side, margin); err != nil {
+		return err
+	}
+	if err := at.checkGroupExposure(entry.Symbol, entry.Side, entry.NotionalUSD, positions); err != nil {
+		return err
+	}
+	if err := at.checkSymbolNotional(entry.Symbol, entry.NotionalUSD, positions); err != nil {
+		return err
+	}
+
+	if at.config.DryRun {
+		actionRecord.DryRun = true
+		actionRecord.Preview = fmt.Sprintf("[预演] 定投%s %s %.6f @ %.4f（%.2f USDT, %dx），未下单",
+			sideName(entry.Side), entry.Symbol, quantity, marketData.CurrentPrice, entry.NotionalUSD, entry.Leverage)
+		return nil
+	}
+
+	unlock := at.lockSymbol(entry.Symbol)
+	defer unlock()
+
+	if err := at.trader.SetMarginMode(entry.Symbol, at.isCrossMarginFor(entry.Symbol)); err != nil {
+		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
+	}
+
+	// 开仓会撤销该币种的全部委托，记录已有的止盈止损以便按加仓后的数量重新挂单
+	posKey := entry.Symbol + "_" + entry.Side
+	var stopLoss, takeProfit float64
+	if tracked, ok := at.trackedPositions[posKey]; ok && existingQty > 0 {
+		stopLoss, takeProfit = tracked.stopLoss, tracked.takeProfit
+	}
+
+	order, err := PlaceOrder(at.trader, entry.Symbol, "open_"+entry.Side, quantity, entry.Leverage)
+	if err != nil {
+		return err
+	}
+	if orderID, ok := order["orderId"].(int64); ok {
+		actionRecord.OrderID = orderID
+	}
+	if clientOrderID, ok := order["clientOrderId"].(string); ok {
+		actionRecord.ClientOrderID = clientOrderID
+	}
+	log.Printf("  ✓ 定投开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
+
+	if _, ok := at.positionFirstSeenTime[posKey]; !ok {
+		at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
+	}
+	at.claimAllocation(entry.Symbol, entry.Side, allocations.marginOf(at.accountKey(), posKey)+margin)
+	at.trackOpenOrder(entry.Symbol, entry.Side, actionRecord.OrderID, actionRecord.ClientOrderID, quantity, marketData.CurrentPrice, stopLoss, takeProfit)
+
+	totalQty := existingQty + quantity
+	positionSide := strings.ToUpper(entry.Side)
+	if stopLoss > 0 {
+		if err := at.setStopLoss(entry.Symbol, entry.Side, totalQty, stopLoss); err != nil {
+			log.Printf("  ⚠ 恢复止损失败: %v", err)
+		}
+	}
+	if takeProfit > 0 {
+		if err := at.trader.SetTakeProfit(entry.Symbol, positionSide, totalQty, takeProfit); err != nil {
+			log.Printf("  ⚠ 恢复止盈失败: %v", err)
+		}
+	}
+	return nil
+}
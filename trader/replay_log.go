@@ -0,0 +1,24 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/replay"
+)
+
+// recordReplayFrame 记录本周期的全部决策输入及AI响应（开启回放日志时）
+func (at *AutoTrader) recordReplayFrame(ctx *decision.Context, full *decision.FullDecision, decideErr error) {
+	if at.replayRecorder == nil {
+		return
+	}
+	frame := replay.NewFrame(at.id, ctx, full, decideErr)
+	frame.CustomPrompt = at.customPrompt
+	frame.OverrideBase = at.overrideBasePrompt
+	frame.Template = at.systemPromptTemplate
+	if len(at.ensemble) > 0 {
+		frame.EnsembleMode = at.ensembleMode
+	}
+	if err := at.replayRecorder.Record(frame); err != nil {
+		log.Printf("⚠️ [%s] 写入回放日志失败: %v", at.name, err)
+	}
+}
@@ -0,0 +1,469 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestPriority 交易所请求优先级（数值越小越优先）
+type RequestPriority int
+
+const (
+	PriorityCancel RequestPriority = iota // 撤单
+	PriorityClose                         // 平仓及止盈止损单（降低风险的订单）
+	PriorityOpen                          // 开仓、设置杠杆/保证金模式等
+	PriorityData                          // 行情、账户、持仓及订单查询
+)
+
+// String 优先级名称
+func (p RequestPriority) String() string {
+	switch p {
+	case PriorityCancel:
+		return "cancel"
+	case PriorityClose:
+		return "close"
+	case PriorityOpen:
+		return "open"
+	default:
+		return "data"
+	}
+}
+
+// critical 是否为降低风险的请求（撤单/平仓）：不受预留令牌及限频退避限制，不会超时放弃
+func (p RequestPriority) critical() bool {
+	return p <= PriorityClose
+}
+
+// defaultRequestMaxWait 开仓及数据查询请求的默认最长排队时间
+const defaultRequestMaxWait = 10 * time.Second
+
+// RequestBudget 交易所请求频率预算（令牌桶）
+type RequestBudget struct {
+	Rate    float64       // 每秒补充的请求数
+	Burst   int           // 令牌桶容量
+	Reserve int           // 留给撤单/平仓的令牌数：剩余令牌不超过该值时，开仓及数据查询排队等待
+	MaxWait time.Duration // 开仓及数据查询的最长排队时间，超时返回错误
+}
+
+// ParseRequestBudget 解析请求频率预算，空字符串返回nil（不限制）
+// 格式: 参数=值,...，如 rate=10,burst=20,reserve=5,max_wait=10s（burst 默认为 rate 的2倍，reserve 默认为 burst 的1/4）
+func ParseRequestBudget(spec string) (*RequestBudget, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	b := &RequestBudget{Reserve: -1, MaxWait: defaultRequestMaxWait}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的请求频率参数: %s（格式应为 参数=值）", item)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "rate":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate <= 0 {
+				return nil, fmt.Errorf("无效的请求频率: %s（每秒请求数，必须大于0）", value)
+			}
+			b.Rate = rate
+		case "burst", "reserve":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("无效的请求频率参数 %s: %s（必须为非负整数）", key, value)
+			}
+			if key == "burst" {
+				b.Burst = n
+			} else {
+				b.Reserve = n
+			}
+		case "max_wait":
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("无效的最长排队时间: %s（如 10s）", value)
+			}
+			b.MaxWait = d
+		default:
+			return nil, fmt.Errorf("请求频率不支持参数: %s（可选: rate, burst, reserve, max_wait）", key)
+		}
+	}
+	if b.Rate <= 0 {
+		return nil, fmt.Errorf("请求频率需要设置 rate（每秒请求数）")
+	}
+	if b.Burst == 0 {
+		b.Burst = int(b.Rate * 2)
+	}
+	if b.Burst < 1 {
+		b.Burst = 1
+	}
+	if b.Reserve < 0 {
+		b.Reserve = b.Burst / 4
+	}
+	if b.Reserve >= b.Burst {
+		return nil, fmt.Errorf("预留令牌数 %d 必须小于令牌桶容量 %d", b.Reserve, b.Burst)
+	}
+	return b, nil
+}
+
+// String 配置摘要
+func (b *RequestBudget) String() string {
+	return fmt.Sprintf("%.1f次/秒，突发 %d，为撤单/平仓预留 %d，开仓及查询最长排队 %s", b.Rate, b.Burst, b.Reserve, b.MaxWait)
+}
+
+// RequestSchedulerStats 调度统计（按优先级）
+type RequestSchedulerStats struct {
+	Granted  map[string]int64 `json:"granted"`   // 已放行的请求
+	Queued   map[string]int64 `json:"queued"`    // 曾排队等待的请求
+	TimedOut map[string]int64 `json:"timed_out"` // 排队超时放弃的请求
+	Tokens   float64          `json:"tokens"`    // 当前剩余令牌
+}
+
+type requestWaiter struct {
+	priority RequestPriority
+	ready    chan struct{}
+}
+
+// RequestScheduler 按优先级放行交易所请求的令牌桶：令牌充足时直接放行；
+// 令牌紧张时撤单 > 平仓 > 开仓 > 数据查询，同优先级先到先得，最后 Reserve 个令牌只给撤单/平仓使用；
+// 交易所返回429/418时按Retry-After暂停开仓及数据查询
+type RequestScheduler struct {
+	budget RequestBudget
+
+	mu          sync.Mutex
+	tokens      float64
+	updatedAt   time.Time
+	pausedUntil time.Time
+	waiters     []*requestWaiter // 按到达顺序
+	timer       *time.Timer
+
+	granted, queued, timedOut [PriorityData + 1]int64
+}
+
+// NewRequestScheduler 创建请求调度器（令牌桶初始为满）
+func NewRequestScheduler(budget RequestBudget) *RequestScheduler {
+	return &RequestScheduler{budget: budget, tokens: float64(budget.Burst), updatedAt: time.Now()}
+}
+
+var (
+	requestSchedulersMu sync.Mutex
+	requestSchedulers   = make(map[string]*RequestScheduler)
+)
+
+// SharedRequestScheduler 获取交易所共用的请求调度器（交易所按IP/账户限频，同一交易所的所有策略共用一份预算）
+// 首次创建时使用传入的预算，之后忽略
+func SharedRequestScheduler(key string, budget RequestBudget) *RequestScheduler {
+	requestSchedulersMu.Lock()
+	defer requestSchedulersMu.Unlock()
+	s, ok := requestSchedulers[key]
+	if !ok {
+		s = NewRequestScheduler(budget)
+		requestSchedulers[key] = s
+	}
+	return s
+}
+
+// refillLocked 按经过的时间补充令牌
+func (s *RequestScheduler) refillLocked(now time.Time) {
+	s.tokens += now.Sub(s.updatedAt).Seconds() * s.budget.Rate
+	if max := float64(s.budget.Burst); s.tokens > max {
+		s.tokens = max
+	}
+	s.updatedAt = now
+}
+
+// needLocked 放行该优先级请求所需的最少令牌数（暂停期间的非关键请求返回0表示不可放行）
+func (s *RequestScheduler) needLocked(p RequestPriority, now time.Time) float64 {
+	if p.critical() {
+		return 1
+	}
+	if now.Before(s.pausedUntil) {
+		return 0
+	}
+	return float64(s.budget.Reserve + 1)
+}
+
+// nextWaiterLocked 优先级最高、最早到达的等待者
+func (s *RequestScheduler) nextWaiterLocked() int {
+	best := -1
+	for i, w := range s.waiters {
+		if best < 0 || w.priority < s.waiters[best].priority {
+			best = i
+		}
+	}
+	return best
+}
+
+// Acquire 等待放行：撤单/平仓等待到ctx取消，开仓及数据查询最多等待 MaxWait
+func (s *RequestScheduler) Acquire(ctx context.Context, p RequestPriority) error {
+	s.mu.Lock()
+	now := time.Now()
+	s.refillLocked(now)
+	blocked := false
+	for _, w := range s.waiters {
+		if w.priority <= p {
+			blocked = true
+			break
+		}
+	}
+	if need := s.needLocked(p, now); !blocked && need > 0 && s.tokens >= need {
+		s.tokens--
+		s.granted[p]++
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &requestWaiter{priority: p, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.queued[p]++
+	s.scheduleLocked(now)
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if !p.critical() {
+		t := time.NewTimer(s.budget.MaxWait)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		return s.abandon(w, ctx.Err())
+	case <-timeout:
+		return s.abandon(w, fmt.Errorf("交易所请求频率预算不足，%s 请求排队超过 %s", p, s.budget.MaxWait))
+	}
+}
+
+// abandon 放弃排队（已被放行时视为成功）
+func (s *RequestScheduler) abandon(w *requestWaiter, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, waiter := range s.waiters {
+		if waiter == w {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			s.timedOut[w.priority]++
+			s.scheduleLocked(time.Now()) // 队首被移除后，后面的请求可能可以放行
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduleLocked 放行当前可以放行的等待者，并在下一个等待者所需令牌补充完成时再次调度
+func (s *RequestScheduler) scheduleLocked(now time.Time) {
+	for len(s.waiters) > 0 {
+		i := s.nextWaiterLocked()
+		w := s.waiters[i]
+		need := s.needLocked(w.priority, now)
+		if need == 0 || s.tokens < need {
+			delay := s.pausedUntil.Sub(now)
+			if need > 0 {
+				delay = time.Duration((need - s.tokens) / s.budget.Rate * float64(time.Second))
+			}
+			if delay < time.Millisecond {
+				delay = time.Millisecond
+			}
+			if s.timer != nil {
+				s.timer.Stop()
+			}
+			s.timer = time.AfterFunc(delay, s.dispatch)
+			return
+		}
+		s.tokens--
+		s.granted[w.priority]++
+		s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+		close(w.ready)
+	}
+}
+
+// dispatch 定时器回调
+func (s *RequestScheduler) dispatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.refillLocked(now)
+	s.scheduleLocked(now)
+}
+
+// Backoff 交易所返回限频错误后暂停开仓及数据查询（撤单/平仓仍按令牌放行）
+func (s *RequestScheduler) Backoff(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until := time.Now().Add(d)
+	if until.After(s.pausedUntil) {
+		s.pausedUntil = until
+		log.Printf("⏳ 交易所返回限频错误，暂停开仓及数据查询 %s（撤单/平仓不受影响）", d)
+	}
+}
+
+// Stats 调度统计
+func (s *RequestScheduler) Stats() RequestSchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refillLocked(time.Now())
+	stats := RequestSchedulerStats{Granted: map[string]int64{}, Queued: map[string]int64{}, TimedOut: map[string]int64{}, Tokens: s.tokens}
+	for p := PriorityCancel; p <= PriorityData; p++ {
+		stats.Granted[p.String()] = s.granted[p]
+		stats.Queued[p.String()] = s.queued[p]
+		stats.TimedOut[p.String()] = s.timedOut[p]
+	}
+	return stats
+}
+
+// defaultRateLimitBackoff 限频响应未带Retry-After时的暂停时间
+const defaultRateLimitBackoff = 5 * time.Second
+
+// scheduledTransport 按请求优先级排队的HTTP传输层
+type scheduledTransport struct {
+	base      http.RoundTripper
+	scheduler *RequestScheduler
+	classify  func(*http.Request) RequestPriority
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *scheduledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.scheduler.Acquire(req.Context(), t.classify(req)); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot) {
+		backoff := defaultRateLimitBackoff
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			backoff = time.Duration(secs) * time.Second
+		}
+		t.scheduler.Backoff(backoff)
+	}
+	return resp, err
+}
+
+// withScheduler 返回使用请求调度器的HTTP客户端副本
+func withScheduler(client *http.Client, scheduler *RequestScheduler, classify func(*http.Request) RequestPriority) *http.Client {
+	return withTransport(client, func(base http.RoundTripper) http.RoundTripper {
+		return &scheduledTransport{base: base, scheduler: scheduler, classify: classify}
+	})
+}
+
+// withTransport 返回传输层经wrap包装的HTTP客户端副本（不修改传入的客户端，如 http.DefaultClient）
+func withTransport(client *http.Client, wrap func(http.RoundTripper) http.RoundTripper) *http.Client {
+	wrapped := &http.Client{}
+	if client != nil {
+		*wrapped = *client
+	}
+	base := wrapped.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped.Transport = wrap(base)
+	return wrapped
+}
+
+// requestParams 请求的query及表单参数（通过GetBody读取副本，不消耗请求body）
+func requestParams(req *http.Request) url.Values {
+	params := req.URL.Query()
+	if body := requestBody(req); len(body) > 0 {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for k, v := range form {
+				params[k] = append(params[k], v...)
+			}
+		}
+	}
+	return params
+}
+
+// requestBody 读取请求body的副本（无法重复读取时返回nil）
+func requestBody(req *http.Request) []byte {
+	if req.Body == nil || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, 64<<10))
+	return data
+}
+
+// classifyBinanceRequest 币安及Aster（与币安相同的接口格式）请求的优先级
+// 下单请求中 reduceOnly/closePosition，或双向持仓下 SELL+LONG、BUY+SHORT 视为平仓（含止盈止损单）
+func classifyBinanceRequest(req *http.Request) RequestPriority {
+	switch req.Method {
+	case http.MethodDelete:
+		return PriorityCancel
+	case http.MethodGet:
+		return PriorityData
+	}
+	if !strings.HasSuffix(req.URL.Path, "/order") && !strings.HasSuffix(req.URL.Path, "/batchOrders") {
+		return PriorityOpen
+	}
+	params := requestParams(req)
+	if params.Get("reduceOnly") == "true" || params.Get("closePosition") == "true" {
+		return PriorityClose
+	}
+	side, positionSide := params.Get("side"), params.Get("positionSide")
+	if (positionSide == "LONG" && side == "SELL") || (positionSide == "SHORT" && side == "BUY") {
+		return PriorityClose
+	}
+	return PriorityOpen
+}
+
+// classifyGateRequest Gate请求的优先级：触发单（止盈止损）及 reduce_only/close/auto_size 订单视为平仓
+func classifyGateRequest(req *http.Request) RequestPriority {
+	switch req.Method {
+	case http.MethodDelete:
+		return PriorityCancel
+	case http.MethodGet:
+		return PriorityData
+	}
+	if strings.HasSuffix(req.URL.Path, "/price_orders") {
+		return PriorityClose
+	}
+	if !strings.HasSuffix(req.URL.Path, "/orders") {
+		return PriorityOpen
+	}
+	var order struct {
+		ReduceOnly bool   `json:"reduce_only"`
+		Close      bool   `json:"close"`
+		AutoSize   string `json:"auto_size"`
+	}
+	if err := json.Unmarshal(requestBody(req), &order); err == nil && (order.ReduceOnly || order.Close || order.AutoSize != "") {
+		return PriorityClose
+	}
+	return PriorityOpen
+}
+
+// RequestScheduled 支持按优先级调度交易所请求的交易器（可选接口）
+// Hyperliquid SDK 不暴露HTTP客户端，不实现该接口
+type RequestScheduled interface {
+	// SetRequestScheduler 经由调度器发送交易所请求
+	SetRequestScheduler(s *RequestScheduler)
+}
+
+// SetRequestScheduler 实现 RequestScheduled
+func (t *FuturesTrader) SetRequestScheduler(s *RequestScheduler) {
+	t.client.HTTPClient = withScheduler(t.client.HTTPClient, s, classifyBinanceRequest)
+}
+
+// SetRequestScheduler 实现 RequestScheduled
+func (t *AsterTrader) SetRequestScheduler(s *RequestScheduler) {
+	t.client = withScheduler(t.client, s, classifyBinanceRequest)
+}
+
+// SetRequestScheduler 实现 RequestScheduled
+func (t *GateTrader) SetRequestScheduler(s *RequestScheduler) {
+	cfg := t.client.GetConfig()
+	cfg.HTTPClient = withScheduler(cfg.HTTPClient, s, classifyGateRequest)
+}
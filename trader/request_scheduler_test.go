@@ -0,0 +1,123 @@
+package trader
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRequestBudget(t *testing.T) {
+	b, err := ParseRequestBudget("rate=10, burst=20, reserve=5, max_wait=3s")
+	if err != nil {
+		t.Fatalf("ParseRequestBudget: %v", err)
+	}
+	want := RequestBudget{Rate: 10, Burst: 20, Reserve: 5, MaxWait: 3 * time.Second}
+	if *b != want {
+		t.Fatalf("got %+v, want %+v", *b, want)
+	}
+	if b, err := ParseRequestBudget("rate=8"); err != nil || b.Burst != 16 || b.Reserve != 4 || b.MaxWait != defaultRequestMaxWait {
+		t.Fatalf("defaults: %+v, %v", b, err)
+	}
+	if b, err := ParseRequestBudget(""); b != nil || err != nil {
+		t.Fatalf("empty spec should disable the budget, got %+v, %v", b, err)
+	}
+	for _, spec := range []string{"burst=10", "rate=0", "rate=5,burst=4,reserve=4", "rate=5,max_wait=0s", "rate=5,weight=2"} {
+		if _, err := ParseRequestBudget(spec); err == nil {
+			t.Errorf("ParseRequestBudget(%q) should fail", spec)
+		}
+	}
+}
+
+func TestRequestSchedulerPriority(t *testing.T) {
+	s := NewRequestScheduler(RequestBudget{Rate: 20, Burst: 2, Reserve: 1, MaxWait: time.Second})
+	ctx := context.Background()
+
+	// 剩余令牌等于预留数时，数据查询排队，撤单直接放行
+	if err := s.Acquire(ctx, PriorityData); err != nil {
+		t.Fatalf("first data request: %v", err)
+	}
+	if err := s.Acquire(ctx, PriorityCancel); err != nil {
+		t.Fatalf("cancel with reserved token: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []RequestPriority
+	var wg sync.WaitGroup
+	for _, p := range []RequestPriority{PriorityData, PriorityOpen, PriorityClose} {
+		wg.Add(1)
+		go func(p RequestPriority) {
+			defer wg.Done()
+			if err := s.Acquire(ctx, p); err != nil {
+				t.Errorf("acquire %s: %v", p, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}(p)
+		time.Sleep(5 * time.Millisecond) // 保证按顺序进入队列
+	}
+	wg.Wait()
+
+	want := []RequestPriority{PriorityClose, PriorityOpen, PriorityData}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("grant order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRequestSchedulerBackoff(t *testing.T) {
+	s := NewRequestScheduler(RequestBudget{Rate: 100, Burst: 10, MaxWait: 20 * time.Millisecond})
+	s.Backoff(time.Minute)
+	if err := s.Acquire(context.Background(), PriorityOpen); err == nil {
+		t.Fatal("open request should time out while rate limited")
+	}
+	if err := s.Acquire(context.Background(), PriorityClose); err != nil {
+		t.Fatalf("close request should pass during backoff: %v", err)
+	}
+	if stats := s.Stats(); stats.TimedOut["open"] != 1 || stats.Granted["close"] != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestClassifyRequests(t *testing.T) {
+	form := func(method, path string, params url.Values) *http.Request {
+		req, _ := http.NewRequest(method, "https://fapi.binance.com"+path, strings.NewReader(params.Encode()))
+		return req
+	}
+	binance := []struct {
+		req  *http.Request
+		want RequestPriority
+	}{
+		{form(http.MethodDelete, "/fapi/v1/allOpenOrders", nil), PriorityCancel},
+		{form(http.MethodGet, "/fapi/v2/positionRisk", nil), PriorityData},
+		{form(http.MethodPost, "/fapi/v1/order", url.Values{"side": {"BUY"}, "positionSide": {"LONG"}}), PriorityOpen},
+		{form(http.MethodPost, "/fapi/v1/order", url.Values{"side": {"SELL"}, "positionSide": {"LONG"}}), PriorityClose},
+		{form(http.MethodPost, "/fapi/v1/order", url.Values{"side": {"SELL"}, "closePosition": {"true"}, "type": {"STOP_MARKET"}}), PriorityClose},
+		{form(http.MethodPost, "/fapi/v1/leverage", url.Values{"leverage": {"5"}}), PriorityOpen},
+	}
+	for i, c := range binance {
+		if got := classifyBinanceRequest(c.req); got != c.want {
+			t.Errorf("binance case %d: got %s, want %s", i, got, c.want)
+		}
+	}
+
+	gate := func(path, body string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "https://api.gateio.ws/api/v4"+path, strings.NewReader(body))
+		return req
+	}
+	if got := classifyGateRequest(gate("/futures/usdt/orders", `{"contract":"BTC_USDT","size":1}`)); got != PriorityOpen {
+		t.Errorf("gate open: got %s", got)
+	}
+	if got := classifyGateRequest(gate("/futures/usdt/orders", `{"contract":"BTC_USDT","size":0,"close":true}`)); got != PriorityClose {
+		t.Errorf("gate close: got %s", got)
+	}
+	if got := classifyGateRequest(gate("/futures/usdt/price_orders", `{}`)); got != PriorityClose {
+		t.Errorf("gate trigger order: got %s", got)
+	}
+}
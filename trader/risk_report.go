@@ -0,0 +1,277 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"nofx/events"
+	"nofx/fx"
+	"nofx/market"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDailyVol 无法获取市场数据时使用的日波动率估计
+	defaultDailyVol = 0.05
+	// crossCorrelation 不同分组币种之间假设的收益相关系数（同一币种或同一相关性分组视为完全相关）
+	crossCorrelation = 0.7
+	// 单尾正态分位数
+	zScore95 = 1.645
+	zScore99 = 2.326
+)
+
+// stressScenario 压力情景：返回各币种的价格变动比例
+type stressScenario struct {
+	name  string
+	shock func(symbol string) float64
+}
+
+// stressScenarios 内置压力情景（山寨币按1.5倍BTC beta估计）
+var stressScenarios = []stressScenario{
+	{"BTC跳空-10%", func(symbol string) float64 { return btcShock(symbol, -0.10) }},
+	{"BTC跳空+10%", func(symbol string) float64 { return btcShock(symbol, 0.10) }},
+	{"全市场-20%", func(string) float64 { return -0.20 }},
+	{"山寨币-30%（BTC/ETH不变）", func(symbol string) float64 {
+		if isMajor(symbol) {
+			return 0
+		}
+		return -0.30
+	}},
+}
+
+// btcShock BTC变动move时各币种的估计变动
+func btcShock(symbol string, move float64) float64 {
+	switch {
+	case symbol == "BTCUSDT":
+		return move
+	case symbol == "ETHUSDT":
+		return move * 1.2
+	default:
+		return move * 1.5
+	}
+}
+
+// isMajor 是否为BTC/ETH
+func isMajor(symbol string) bool {
+	return symbol == "BTCUSDT" || symbol == "ETHUSDT"
+}
+
+// PositionRisk 单个持仓的风险
+type PositionRisk struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"`
+	Quantity         float64 `json:"quantity"`
+	Leverage         int     `json:"leverage"`
+	Notional         float64 `json:"notional"` // 名义价值（USDT，空头为负）
+	MarkPrice        float64 `json:"mark_price"`
+	LiquidationPrice float64 `json:"liquidation_price"`
+	DailyVolPct      float64 `json:"daily_vol_pct"` // 估计日波动率（%）
+	VaR95            float64 `json:"var_95"`        // 单独持仓的1日95% VaR（USDT）
+}
+
+// StressResult 压力情景结果
+type StressResult struct {
+	Name       string   `json:"name"`
+	PnL        float64  `json:"pnl"`                  // 情景下的盈亏（USDT）
+	PnLPct     float64  `json:"pnl_pct"`              // 占净值百分比
+	Liquidated []string `json:"liquidated,omitempty"` // 情景下会被强平的持仓
+}
+
+// RiskReport 风险报告：参数法VaR及压力测试
+type RiskReport struct {
+	TraderID      string         `json:"trader_id"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Equity        float64        `json:"equity"`
+	GrossExposure float64        `json:"gross_exposure"` // 总名义价值
+	NetExposure   float64        `json:"net_exposure"`   // 净名义价值（多-空）
+	VaR95         float64        `json:"var_95"`         // 组合1日95% VaR（USDT）
+	VaR99         float64        `json:"var_99"`         // 组合1日99% VaR（USDT）
+	VaR95Pct      float64        `json:"var_95_pct"`     // 占净值百分比
+	VaR99Pct      float64        `json:"var_99_pct"`
+	Positions     []PositionRisk `json:"positions"`
+	Scenarios     []StressResult `json:"scenarios"`
+}
+
+// Summary 报告摘要（用于日志及通知，金额按报告币种显示）
+func (r *RiskReport) Summary() string {
+	if len(r.Positions) == 0 {
+		return "📐 风险报告: 当前无持仓"
+	}
+	worst := r.Scenarios[0]
+	for _, s := range r.Scenarios {
+		if s.PnL < worst.PnL {
+			worst = s
+		}
+	}
+	q := fx.Current()
+	return fmt.Sprintf("📐 风险报告: 敞口 %.0f %s（净 %+.0f）| 1日VaR95 %s (%.1f%%) | VaR99 %s (%.1f%%) | 最差情景[%s] %s (%+.1f%%)",
+		q.Convert(r.GrossExposure), q.Currency, q.Convert(r.NetExposure), q.Format(r.VaR95), r.VaR95Pct, q.Format(r.VaR99), r.VaR99Pct,
+		worst.Name, q.FormatSigned(worst.PnL), worst.PnLPct)
+}
+
+// BuildRiskReport 根据当前持仓计算风险报告
+func (at *AutoTrader) BuildRiskReport() (*RiskReport, error) {
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return nil, fmt.Errorf("获取账户余额失败: %w", err)
+	}
+	wallet, _ := balance["totalWalletBalance"].(float64)
+	unrealized, _ := balance["totalUnrealizedProfit"].(float64)
+
+	positions, err := at.getPositions()
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	report := &RiskReport{
+		TraderID:    at.id,
+		GeneratedAt: time.Now(),
+		Equity:      wallet + unrealized,
+	}
+
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		quantity, _ := pos["positionAmt"].(float64)
+		markPrice, _ := pos["markPrice"].(float64)
+		liqPrice, _ := pos["liquidationPrice"].(float64)
+		leverage, _ := pos["leverage"].(float64)
+
+		notional := math.Abs(quantity) * markPrice
+		if side == "short" {
+			notional = -notional
+		}
+		vol := estimateDailyVol(symbol, markPrice)
+		report.Positions = append(report.Positions, PositionRisk{
+			Symbol:           symbol,
+			Side:             side,
+			Quantity:         math.Abs(quantity),
+			Leverage:         int(leverage),
+			Notional:         notional,
+			MarkPrice:        markPrice,
+			LiquidationPrice: liqPrice,
+			DailyVolPct:      vol * 100,
+			VaR95:            zScore95 * vol * math.Abs(notional),
+		})
+		report.GrossExposure += math.Abs(notional)
+		report.NetExposure += notional
+	}
+
+	// 组合方差: Σi Σj wi wj σi σj ρij
+	variance := 0.0
+	for _, a := range report.Positions {
+		for _, b := range report.Positions {
+			rho := crossCorrelation
+			if a.Symbol == b.Symbol || at.sameCorrelationGroup(a.Symbol, b.Symbol) {
+				rho = 1
+			}
+			variance += a.Notional * b.Notional * a.DailyVolPct / 100 * b.DailyVolPct / 100 * rho
+		}
+	}
+	sigma := math.Sqrt(math.Max(variance, 0))
+	report.VaR95 = zScore95 * sigma
+	report.VaR99 = zScore99 * sigma
+
+	for _, scenario := range stressScenarios {
+		result := StressResult{Name: scenario.name}
+		for _, p := range report.Positions {
+			move := scenario.shock(p.Symbol)
+			result.PnL += p.Notional * move
+			shocked := p.MarkPrice * (1 + move)
+			if p.LiquidationPrice > 0 && ((p.Side == "long" && shocked <= p.LiquidationPrice) ||
+				(p.Side == "short" && shocked >= p.LiquidationPrice)) {
+				result.Liquidated = append(result.Liquidated, p.Symbol+" "+p.Side)
+			}
+		}
+		report.Scenarios = append(report.Scenarios, result)
+	}
+
+	if report.Equity > 0 {
+		report.VaR95Pct = report.VaR95 / report.Equity * 100
+		report.VaR99Pct = report.VaR99 / report.Equity * 100
+		for i := range report.Scenarios {
+			report.Scenarios[i].PnLPct = report.Scenarios[i].PnL / report.Equity * 100
+		}
+	}
+	return report, nil
+}
+
+// sameCorrelationGroup 两个币种是否属于同一相关性分组
+func (at *AutoTrader) sameCorrelationGroup(a, b string) bool {
+	group, ok := at.config.CorrelationGroups[a]
+	return ok && group == at.config.CorrelationGroups[b]
+}
+
+// estimateDailyVol 用4小时ATR14估计日波动率（ATR/价格 × √6）
+func estimateDailyVol(symbol string, price float64) float64 {
+	data, err := market.Get(symbol)
+	if err != nil || data.LongerTermContext == nil || data.LongerTermContext.ATR14 <= 0 || price <= 0 {
+		return defaultDailyVol
+	}
+	return data.LongerTermContext.ATR14 / price * math.Sqrt(6)
+}
+
+// GetRiskReport 获取最近一次风险报告，refresh为true或尚未生成时重新计算
+func (at *AutoTrader) GetRiskReport(refresh bool) (*RiskReport, error) {
+	at.riskReportMu.Lock()
+	report := at.lastRiskReport
+	at.riskReportMu.Unlock()
+	if report != nil && !refresh {
+		return report, nil
+	}
+
+	report, err := at.BuildRiskReport()
+	if err != nil {
+		return nil, err
+	}
+	at.riskReportMu.Lock()
+	at.lastRiskReport = report
+	at.riskReportMu.Unlock()
+	return report, nil
+}
+
+// periodicRiskReport 按配置的间隔生成风险报告并发布通知（设置了后台任务队列时在后台生成，不阻塞交易周期）
+func (at *AutoTrader) periodicRiskReport() string {
+	interval := at.config.RiskReportInterval
+	if interval <= 0 {
+		return ""
+	}
+	at.riskReportMu.Lock()
+	last := at.lastRiskReport
+	at.riskReportMu.Unlock()
+	if last != nil && time.Since(last.GeneratedAt) < interval {
+		return ""
+	}
+
+	if runInBackground("risk_report", "risk_report:"+at.id, func(context.Context) (interface{}, error) {
+		report, err := at.GetRiskReport(true)
+		if err != nil {
+			return nil, err
+		}
+		at.publishRiskReport(report)
+		return report, nil
+	}) {
+		return ""
+	}
+	report, err := at.GetRiskReport(true)
+	if err != nil {
+		log.Printf("⚠️  [%s] 生成风险报告失败: %v", at.name, err)
+		return ""
+	}
+	return at.publishRiskReport(report)
+}
+
+// publishRiskReport 输出风险报告摘要（含压力情景下将被强平的持仓）并发布通知
+func (at *AutoTrader) publishRiskReport(report *RiskReport) string {
+	summary := report.Summary()
+	for _, s := range report.Scenarios {
+		if len(s.Liquidated) > 0 {
+			summary += fmt.Sprintf(" | ⚠️ [%s]将强平: %s", s.Name, strings.Join(s.Liquidated, ", "))
+		}
+	}
+	log.Printf("%s", summary)
+	at.publishEvent(events.Event{Type: events.RiskReport, Message: summary})
+	return summary
+}
@@ -0,0 +1,508 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 影子账本用户数据流断线重连的退避时间
+const (
+	bookStreamMinBackoff = 5 * time.Second
+	bookStreamMaxBackoff = time.Minute
+)
+
+// BookOrder 影子账本中的挂单
+type BookOrder struct {
+	OrderID       int64   `json:"order_id"`
+	ClientOrderID string  `json:"client_order_id,omitempty"`
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`          // BUY / SELL
+	PositionSide  string  `json:"position_side"` // LONG / SHORT / BOTH
+	Type          string  `json:"type"`
+	Price         float64 `json:"price"`
+	StopPrice     float64 `json:"stop_price,omitempty"`
+	Quantity      float64 `json:"quantity"`
+	FilledQty     float64 `json:"filled_qty"`
+	ReduceOnly    bool    `json:"reduce_only"`
+	UpdateTime    int64   `json:"update_time"` // 交易所更新时间（毫秒）
+}
+
+// OpenOrderLister 支持查询全部挂单的交易器（可选接口，用于影子账本对账及批量撤单）
+type OpenOrderLister interface {
+	// GetOpenOrders 查询所有币种的挂单
+	GetOpenOrders() ([]BookOrder, error)
+}
+
+// PositionUpdate 交易所推送的持仓变化
+type PositionUpdate struct {
+	Symbol        string
+	Side          string  // long / short，单向持仓模式下平仓（Amount=0）时为空，表示该币种两个方向均已平仓
+	Amount        float64 // 与 GetPositions 中 positionAmt 相同的符号约定（空仓为负数）
+	EntryPrice    float64
+	UnrealizedPnL float64
+	Time          int64 // 交易所事件时间（毫秒）
+}
+
+// UserDataStreamer 支持订阅账户及订单推送的交易器（可选接口）
+type UserDataStreamer interface {
+	// StreamUserData 连接用户数据流并把更新写入影子账本，阻塞直到stop关闭（返回nil）或连接断开（返回错误）
+	StreamUserData(book *ShadowBook, stop <-chan struct{}) error
+}
+
+// positionCache 带持仓缓存的交易器：对账前清除缓存，保证读到交易所的最新状态
+type positionCache interface {
+	clearPositionCache()
+}
+
+// ShadowBook 影子账本：本地维护的持仓及挂单，由交易所推送实时更新，并定期经REST对账
+// 每条持仓/订单记录最近一次更新的时间（交易所事件时间或REST快照发起时间），晚到的旧推送及早于推送的REST结果不会覆盖较新的状态；
+// 账本每次变化版本号加一，读取方可据此判断两次读取之间状态是否变化
+type ShadowBook struct {
+	mu           sync.RWMutex
+	version      uint64
+	positions    map[string]map[string]interface{} // symbol_side -> 与 Trader.GetPositions 相同格式
+	positionSeq  map[string]int64                  // 持仓最近一次更新的时间（毫秒，平仓后保留，防止旧推送使其复活）
+	leverage     map[string]float64                // 币种杠杆（推送中新出现的持仓使用）
+	orders       map[int64]BookOrder
+	orderSeq     map[int64]int64 // 订单最近一次更新的时间（毫秒，已结束的订单同样保留）
+	loaded       bool            // 已有REST快照
+	ordersLoaded bool            // 挂单已有REST快照（交易所实现 OpenOrderLister）
+	stale        bool            // 本交易员下单后需经REST刷新
+	streaming    bool            // 用户数据流连接正常
+	reconciledAt time.Time
+	eventAt      time.Time
+	events       int // 已应用的推送数
+	drifts       int // 对账时发现账本与交易所不一致的次数（推送丢失）
+
+	reconcileMu sync.Mutex    // 同一时间只有一次REST对账
+	refresh     chan struct{} // 推送中出现新持仓时尽快对账（补全标记价格、强平价格）
+}
+
+// BookSnapshot 影子账本快照
+type BookSnapshot struct {
+	Version      uint64                   `json:"version"`
+	Positions    []map[string]interface{} `json:"positions"`
+	Orders       []BookOrder              `json:"orders"`
+	OrdersLoaded bool                     `json:"orders_loaded"` // 交易所不支持查询挂单时为false，挂单只来自推送
+	Streaming    bool                     `json:"streaming"`
+	Stale        bool                     `json:"stale"`
+	ReconciledAt time.Time                `json:"reconciled_at"`
+	LastEventAt  time.Time                `json:"last_event_at,omitempty"`
+	Events       int                      `json:"events"`
+	Drifts       int                      `json:"drifts"`
+}
+
+// NewShadowBook 创建空的影子账本（首次读取时经REST加载）
+func NewShadowBook() *ShadowBook {
+	return &ShadowBook{
+		positions:   make(map[string]map[string]interface{}),
+		positionSeq: make(map[string]int64),
+		leverage:    make(map[string]float64),
+		orders:      make(map[int64]BookOrder),
+		orderSeq:    make(map[int64]int64),
+		refresh:     make(chan struct{}, 1),
+	}
+}
+
+// Version 账本版本号
+func (b *ShadowBook) Version() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.version
+}
+
+// positionList 账本中的持仓副本，账本未加载或需要刷新时返回 ok=false
+func (b *ShadowBook) positionList() (positions []map[string]interface{}, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if !b.loaded || b.stale {
+		return nil, false
+	}
+	return b.copyPositions(), true
+}
+
+// copyPositions 按币种、方向排序的持仓副本（调用方持有锁）
+func (b *ShadowBook) copyPositions() []map[string]interface{} {
+	keys := make([]string, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	positions := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		positions = append(positions, copyPosition(b.positions[key]))
+	}
+	return positions
+}
+
+// copyPosition 持仓map的副本
+func copyPosition(pos map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(pos))
+	for k, v := range pos {
+		c[k] = v
+	}
+	return c
+}
+
+// Snapshot 账本快照
+func (b *ShadowBook) Snapshot() BookSnapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	s := BookSnapshot{
+		Version:      b.version,
+		Positions:    b.copyPositions(),
+		Orders:       make([]BookOrder, 0, len(b.orders)),
+		OrdersLoaded: b.ordersLoaded,
+		Streaming:    b.streaming,
+		Stale:        b.stale,
+		ReconciledAt: b.reconciledAt,
+		LastEventAt:  b.eventAt,
+		Events:       b.events,
+		Drifts:       b.drifts,
+	}
+	for _, o := range b.orders {
+		s.Orders = append(s.Orders, o)
+	}
+	sort.Slice(s.Orders, func(i, j int) bool { return s.Orders[i].OrderID < s.Orders[j].OrderID })
+	return s
+}
+
+// Invalidate 标记账本需要刷新（本交易员下单、撤单后调用，下次读取经REST对账）
+func (b *ShadowBook) Invalidate() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.stale = true
+	b.mu.Unlock()
+}
+
+// ApplyPosition 应用交易所推送的持仓变化（早于该持仓最近一次更新的推送被忽略）
+func (b *ShadowBook) ApplyPosition(u PositionUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sides := []string{u.Side}
+	if u.Side == "" {
+		sides = []string{"long", "short"}
+	}
+	changed, opened := false, false
+	for _, side := range sides {
+		key := u.Symbol + "_" + side
+		if u.Time < b.positionSeq[key] {
+			continue
+		}
+		b.positionSeq[key] = u.Time
+		if u.Amount == 0 {
+			if _, ok := b.positions[key]; ok {
+				delete(b.positions, key)
+				changed = true
+			}
+			continue
+		}
+		pos, ok := b.positions[key]
+		if !ok {
+			// 新出现的持仓：标记价格暂用开仓均价，强平价格未知（0），随后经REST补全
+			pos = map[string]interface{}{"symbol": u.Symbol, "side": side, "markPrice": u.EntryPrice, "liquidationPrice": 0.0, "leverage": b.leverage[u.Symbol]}
+			b.positions[key] = pos
+			opened = true
+		}
+		pos["positionAmt"] = u.Amount
+		pos["entryPrice"] = u.EntryPrice
+		pos["unRealizedProfit"] = u.UnrealizedPnL
+		changed = true
+	}
+	b.recordEvent(changed)
+	if opened {
+		select {
+		case b.refresh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ApplyLeverage 应用交易所推送的杠杆变化
+func (b *ShadowBook) ApplyLeverage(symbol string, leverage float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.leverage[symbol] = leverage
+	changed := false
+	for _, side := range []string{"long", "short"} {
+		if pos, ok := b.positions[symbol+"_"+side]; ok {
+			pos["leverage"] = leverage
+			changed = true
+		}
+	}
+	b.recordEvent(changed)
+}
+
+// ApplyOrder 应用交易所推送的订单变化，done=true表示订单已结束（成交、撤销、过期）
+func (b *ShadowBook) ApplyOrder(o BookOrder, done bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if o.UpdateTime < b.orderSeq[o.OrderID] {
+		b.recordEvent(false)
+		return
+	}
+	b.orderSeq[o.OrderID] = o.UpdateTime
+	_, existed := b.orders[o.OrderID]
+	if done {
+		delete(b.orders, o.OrderID)
+		b.recordEvent(existed)
+		return
+	}
+	b.orders[o.OrderID] = o
+	b.recordEvent(true)
+}
+
+// recordEvent 记录一次推送（调用方持有锁）
+func (b *ShadowBook) recordEvent(changed bool) {
+	b.events++
+	b.eventAt = time.Now()
+	if changed {
+		b.version++
+	}
+}
+
+// setStreaming 更新用户数据流连接状态，连接建立后立即对账（补上连接之前的变化）
+func (b *ShadowBook) setStreaming(streaming bool) {
+	b.mu.Lock()
+	b.streaming = streaming
+	if streaming {
+		b.stale = true
+	}
+	b.mu.Unlock()
+	if streaming {
+		select {
+		case b.refresh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Reconcile 经REST读取持仓（及挂单）并与账本合并；推送保持连接时，如发现账本与交易所不一致则记录为漂移
+func (b *ShadowBook) Reconcile(t Trader) error {
+	return b.reconcile(t, false)
+}
+
+// reconcile 对账，onlyIfStale=true 时若等待期间其他调用已完成对账则直接返回
+func (b *ShadowBook) reconcile(t Trader, onlyIfStale bool) error {
+	b.reconcileMu.Lock()
+	defer b.reconcileMu.Unlock()
+	if onlyIfStale {
+		b.mu.RLock()
+		fresh := b.loaded && !b.stale
+		b.mu.RUnlock()
+		if fresh {
+			return nil
+		}
+	}
+
+	if cache, ok := t.(positionCache); ok {
+		cache.clearPositionCache()
+	}
+	b.mu.Lock()
+	wasStale := b.stale
+	b.stale = false // 对账期间再次下单会重新标记
+	b.mu.Unlock()
+	startedAt := time.Now().UnixMilli()
+	positions, err := t.GetPositions()
+	if err != nil {
+		if wasStale {
+			b.Invalidate()
+		}
+		return err
+	}
+	var orders []BookOrder
+	lister, hasLister := t.(OpenOrderLister)
+	if hasLister {
+		if orders, err = lister.GetOpenOrders(); err != nil {
+			log.Printf("⚠️ 影子账本查询挂单失败: %v", err)
+			hasLister = false
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	restPositions := make(map[string]map[string]interface{}, len(positions))
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		restPositions[symbol+"_"+side] = copyPosition(pos) // 交易器的持仓缓存可能共用同一个map
+		if lev, ok := pos["leverage"].(float64); ok && lev > 0 {
+			b.leverage[symbol] = lev
+		}
+	}
+	var drift []string
+	merged := make(map[string]map[string]interface{}, len(restPositions))
+	for key, pos := range restPositions {
+		if b.positionSeq[key] > startedAt {
+			continue // 推送比REST结果更新
+		}
+		if cur, ok := b.positions[key]; !ok || !sameAmount(cur["positionAmt"], pos["positionAmt"]) {
+			drift = append(drift, "持仓 "+key)
+		}
+		merged[key] = pos
+		b.positionSeq[key] = startedAt
+	}
+	for key, pos := range b.positions {
+		if b.positionSeq[key] > startedAt {
+			merged[key] = pos
+		} else if _, ok := restPositions[key]; !ok {
+			drift = append(drift, "持仓 "+key)
+			b.positionSeq[key] = startedAt
+		}
+	}
+	changed := len(drift) > 0 || len(merged) != len(b.positions)
+	b.positions = merged
+
+	if hasLister {
+		restOrders := make(map[int64]BookOrder, len(orders))
+		for _, o := range orders {
+			restOrders[o.OrderID] = o
+		}
+		mergedOrders := make(map[int64]BookOrder, len(restOrders))
+		var orderDrift int
+		for id, o := range restOrders {
+			if b.orderSeq[id] > startedAt {
+				continue
+			}
+			if _, ok := b.orders[id]; !ok {
+				orderDrift++
+			}
+			mergedOrders[id] = o
+		}
+		for id, o := range b.orders {
+			if b.orderSeq[id] > startedAt {
+				mergedOrders[id] = o
+			} else if _, ok := restOrders[id]; !ok {
+				orderDrift++
+			}
+		}
+		if orderDrift > 0 {
+			drift = append(drift, fmt.Sprintf("挂单 %d 笔", orderDrift))
+			changed = true
+		}
+		b.orders = mergedOrders
+		// 已结束订单的时间记录只需覆盖推送可能晚到的时间窗口
+		for id, seq := range b.orderSeq {
+			if _, open := b.orders[id]; !open && seq < startedAt-time.Hour.Milliseconds() {
+				delete(b.orderSeq, id)
+			}
+		}
+		b.ordersLoaded = true
+	}
+
+	if b.loaded && b.streaming && !wasStale && len(drift) > 0 {
+		b.drifts++
+		log.Printf("⚠️ 影子账本与交易所不一致（推送可能丢失），已按REST修正: %v", drift)
+	}
+	if changed || !b.loaded {
+		b.version++
+	}
+	b.loaded = true
+	b.reconciledAt = time.Now()
+	return nil
+}
+
+// sameAmount 持仓数量是否相同
+func sameAmount(a, b interface{}) bool {
+	x, _ := a.(float64)
+	y, _ := b.(float64)
+	return math.Abs(x-y) <= 1e-9*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// Start 启动用户数据流（交易所支持时，断线自动重连）及定期对账，返回停止函数
+func (b *ShadowBook) Start(t Trader, name string, interval time.Duration) func() {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	if streamer, ok := t.(UserDataStreamer); ok {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backoff := bookStreamMinBackoff
+			for {
+				connectedAt := time.Now()
+				err := streamer.StreamUserData(b, stop)
+				b.setStreaming(false)
+				if err == nil {
+					return
+				}
+				if time.Since(connectedAt) > bookStreamMaxBackoff {
+					backoff = bookStreamMinBackoff
+				}
+				log.Printf("⚠️ [%s] 用户数据流断开: %v，%s 后重连", name, err, backoff)
+				b.Invalidate() // 断线期间的推送已丢失
+				select {
+				case <-stop:
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, bookStreamMaxBackoff)
+			}
+		}()
+	} else {
+		log.Printf("📒 [%s] 交易所不支持用户数据流，影子账本每 %s 经REST对账（本交易员下单后立即刷新）", name, interval)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := b.Reconcile(t); err != nil {
+				log.Printf("⚠️ [%s] 影子账本对账失败: %v", name, err)
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			case <-b.refresh:
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+// getPositions 读取持仓：启用影子账本时使用账本（未加载或本交易员下单后先经REST对账），否则直接查询交易所
+func (at *AutoTrader) getPositions() ([]map[string]interface{}, error) {
+	if at.book == nil {
+		return at.trader.GetPositions()
+	}
+	if positions, ok := at.book.positionList(); ok {
+		return positions, nil
+	}
+	if err := at.book.reconcile(at.trader, true); err != nil {
+		return nil, err
+	}
+	if positions, ok := at.book.positionList(); ok {
+		return positions, nil
+	}
+	return at.trader.GetPositions() // 对账后又有新的下单
+}
+
+// GetShadowBook 影子账本快照（未启用时返回nil）
+func (at *AutoTrader) GetShadowBook() *BookSnapshot {
+	if at.book == nil {
+		return nil
+	}
+	s := at.book.Snapshot()
+	return &s
+}
+
+// shadowBooks 交易器 -> 影子账本，下单时据此标记账本需要刷新（PlaceOrder 只拿到交易器）
+var shadowBooks sync.Map
+
+// invalidateShadowBook 交易器下单后标记其影子账本需要刷新
+func invalidateShadowBook(t Trader) {
+	if book, ok := shadowBooks.Load(t); ok {
+		book.(*ShadowBook).Invalidate()
+	}
+}
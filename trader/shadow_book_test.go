@@ -0,0 +1,90 @@
+package trader
+
+import (
+	"testing"
+	"time"
+)
+
+func bookAmount(t *testing.T, b *ShadowBook, key string) float64 {
+	t.Helper()
+	for _, pos := range b.Snapshot().Positions {
+		if pos["symbol"].(string)+"_"+pos["side"].(string) == key {
+			return pos["positionAmt"].(float64)
+		}
+	}
+	return 0
+}
+
+func TestShadowBookSequencing(t *testing.T) {
+	stub := &positionStub{stubTrader: newStubTrader(), positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 1.0, "entryPrice": 60000.0, "markPrice": 60100.0, "unRealizedProfit": 100.0, "leverage": 5.0, "liquidationPrice": 50000.0},
+	}}
+	book := NewShadowBook()
+	if _, ok := book.positionList(); ok {
+		t.Fatal("book should not be readable before the first reconcile")
+	}
+	if err := book.Reconcile(stub); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	v := book.Version()
+
+	// 早于REST快照的推送被忽略
+	book.ApplyPosition(PositionUpdate{Symbol: "BTCUSDT", Side: "long", Amount: 5, Time: 1})
+	if got := bookAmount(t, book, "BTCUSDT_long"); got != 1 || book.Version() != v {
+		t.Fatalf("stale update applied: amount=%v version=%d", got, book.Version())
+	}
+
+	// 较新的推送生效，之后发起但返回旧数据的REST不会覆盖它
+	future := time.Now().Add(time.Minute).UnixMilli()
+	book.ApplyPosition(PositionUpdate{Symbol: "BTCUSDT", Side: "long", Amount: 2, EntryPrice: 60500, Time: future})
+	book.ApplyPosition(PositionUpdate{Symbol: "ETHUSDT", Side: "short", Amount: -3, EntryPrice: 3000, Time: future})
+	if book.Version() != v+2 {
+		t.Fatalf("version = %d, want %d", book.Version(), v+2)
+	}
+	if err := book.Reconcile(stub); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if got := bookAmount(t, book, "BTCUSDT_long"); got != 2 {
+		t.Fatalf("REST snapshot overwrote a newer update: amount=%v", got)
+	}
+	if got := bookAmount(t, book, "ETHUSDT_short"); got != -3 {
+		t.Fatalf("position opened by a newer update was dropped: amount=%v", got)
+	}
+
+	// 单向持仓平仓推送关闭两个方向
+	book.ApplyPosition(PositionUpdate{Symbol: "ETHUSDT", Amount: 0, Time: future + 1})
+	if got := bookAmount(t, book, "ETHUSDT_short"); got != 0 {
+		t.Fatalf("closed position still in book: amount=%v", got)
+	}
+}
+
+func TestShadowBookDriftAndInvalidate(t *testing.T) {
+	stub := &positionStub{stubTrader: newStubTrader()}
+	book := NewShadowBook()
+	book.setStreaming(true)
+	if err := book.Reconcile(stub); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if _, ok := book.positionList(); !ok {
+		t.Fatal("book should be readable after reconcile")
+	}
+
+	// 推送丢失：交易所出现账本中没有的持仓
+	stub.positions = []map[string]interface{}{{"symbol": "SOLUSDT", "side": "long", "positionAmt": 10.0, "markPrice": 150.0, "leverage": 3.0}}
+	if err := book.Reconcile(stub); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if s := book.Snapshot(); s.Drifts != 1 || len(s.Positions) != 1 {
+		t.Fatalf("drift not detected: %+v", s)
+	}
+
+	// 经 PlaceOrder 下单后账本需要刷新
+	shadowBooks.Store(Trader(stub), book)
+	defer shadowBooks.Delete(Trader(stub))
+	if _, err := PlaceOrder(stub, "SOLUSDT", "close_long", 0, 0); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if _, ok := book.positionList(); ok {
+		t.Fatal("book should be stale after placing an order")
+	}
+}
@@ -0,0 +1,73 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync/atomic"
+)
+
+// SlippageLimiter 支持最大滑点保护的交易器（可选接口）
+// 开启后市价单改为限价穿价单：以对手方最优价 ± N个价格步进为限价的IOC单，最差成交价有上限，超出部分不成交
+// Hyperliquid 的市价单本身即为最新价±1%的IOC限价单，不实现该接口；Aster 的市价单为最新价±1%的GTC限价单，开启后同样改为IOC单
+// 未完全成交的平仓由 placeClose 按剩余数量补单
+type SlippageLimiter interface {
+	// SetMaxSlippageTicks 设置市价单最多穿过的价格步进数（0=普通市价单）
+	SetMaxSlippageTicks(ticks int)
+}
+
+// slippageLimit 最大滑点配置，嵌入交易器
+type slippageLimit struct {
+	ticks int64
+}
+
+// SetMaxSlippageTicks 实现 SlippageLimiter
+func (s *slippageLimit) SetMaxSlippageTicks(ticks int) {
+	if ticks < 0 {
+		ticks = 0
+	}
+	atomic.StoreInt64(&s.ticks, int64(ticks))
+}
+
+// slippageQuoter 计算限价穿价价格所需的行情（盘口及最新价）
+type slippageQuoter interface {
+	BookQuoteProvider
+	GetMarketPrice(symbol string) (float64, error)
+}
+
+// limitThroughPrice 市价单的限价：买入为卖一 + N个步进，卖出为买一 - N个步进（不低于一个步进）
+// 获取盘口失败时以最新价为基准；未开启时返回false，由调用方按普通市价单下单。
+// 无法计算限价时开仓返回错误（拒绝无滑点保护的开仓），平仓返回false按普通市价单下单（优先保证平仓）
+func (s *slippageLimit) limitThroughPrice(quoter slippageQuoter, getSpec func(string) (ContractSpec, error), symbol string, buy, opening bool) (string, bool, error) {
+	ticks := atomic.LoadInt64(&s.ticks)
+	if ticks <= 0 {
+		return "", false, nil
+	}
+	unbounded := func(reason string, err error) (string, bool, error) {
+		if opening {
+			return "", false, fmt.Errorf("❌ %s %s，无法计算最大滑点限价，拒绝开仓: %v", symbol, reason, err)
+		}
+		log.Printf("  ⚠ %s %s，按市价单平仓（无滑点保护）: %v", symbol, reason, err)
+		return "", false, nil
+	}
+	spec, err := getSpec(symbol)
+	if err != nil || spec.TickSize <= 0 {
+		return unbounded("缺少价格步进", err)
+	}
+	offset := float64(ticks) * spec.TickSize
+	bid, ask := 0.0, 0.0
+	if quote, err := quoter.GetBookQuote(symbol); err == nil && quote.Bid > 0 && quote.Ask > 0 {
+		bid, ask = quote.Bid, quote.Ask
+	} else {
+		last, lastErr := quoter.GetMarketPrice(symbol)
+		if lastErr != nil || last <= 0 {
+			return unbounded("获取盘口及最新价失败", fmt.Errorf("%v; %v", err, lastErr))
+		}
+		log.Printf("  ⚠ %s 获取盘口失败，按最新价 %.8g 计算滑点限价: %v", symbol, last, err)
+		bid, ask = last, last
+	}
+	if buy {
+		return spec.FormatPrice(ask + offset), true, nil
+	}
+	return spec.FormatPrice(math.Max(bid-offset, spec.TickSize)), true, nil
+}
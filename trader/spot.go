@@ -0,0 +1,21 @@
+package trader
+
+// SpotFill 现货市价单的成交结果
+type SpotFill struct {
+	Symbol   string  `json:"symbol"`
+	Quantity float64 `json:"quantity"`  // 成交数量（已扣除以基础币种收取的手续费，即实际到账/卖出数量）
+	AvgPrice float64 `json:"avg_price"` // 成交均价
+	Quote    float64 `json:"quote"`     // 成交额（USDT）
+}
+
+// SpotTrader 支持现货下单的交易器（可选接口，用于期现套利等需要现货腿的策略）
+type SpotTrader interface {
+	// GetSpotPrice 获取现货最新价格
+	GetSpotPrice(symbol string) (float64, error)
+
+	// SpotBuy 以USDT金额市价买入现货
+	SpotBuy(symbol string, quoteAmount float64) (*SpotFill, error)
+
+	// SpotSell 市价卖出指定数量的现货
+	SpotSell(symbol string, quantity float64) (*SpotFill, error)
+}
@@ -0,0 +1,353 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// StrategyTrader 交易员账户上运行的辅助策略（Delta对冲、期现套利）的下单通道（含期现套利的现货腿）
+// 订单与AI策略的订单一样检查观察模式、杠杆上限、维护及下架、净额规则与资金分配上限，
+// 与同一合约的其他下单串行执行并写入下单意图日志；模拟交易只记录预演，不下单。
+// 持仓归属为 <trader ID>/<策略名>：AI策略不会平掉或加仓，占用的保证金计入交易员的分配上限
+type StrategyTrader struct {
+	at       *AutoTrader
+	strategy string
+	purpose  byte
+	leverage map[string]int // 限价挂单按该杠杆计算占用的保证金（见 SetLeverage）
+}
+
+// NewStrategyTrader 创建辅助策略的下单通道（purpose为写入clientOrderId的订单用途，如 OrderPurposeHedge）
+func (at *AutoTrader) NewStrategyTrader(strategy string, purpose byte) *StrategyTrader {
+	return &StrategyTrader{at: at, strategy: strategy, purpose: purpose, leverage: make(map[string]int)}
+}
+
+// owner 策略持仓的归属标识
+func (s *StrategyTrader) owner() string {
+	return strategyOwner(s.at.id, s.strategy)
+}
+
+// IsDryRun 是否为模拟交易（下单只记录预演）
+func (s *StrategyTrader) IsDryRun() bool {
+	return s.at.config.DryRun
+}
+
+// GetPositions 获取账户持仓（共用账户时包含其他策略的持仓）
+func (s *StrategyTrader) GetPositions() ([]map[string]interface{}, error) {
+	return s.at.getPositions()
+}
+
+// GetMarketPrice 获取合约最新价格
+func (s *StrategyTrader) GetMarketPrice(symbol string) (float64, error) {
+	return s.at.trader.GetMarketPrice(symbol)
+}
+
+// ClaimPositions 启动时认领symbol上尚无归属的已有持仓（重启前由本策略开仓），持仓属于其他策略时返回错误
+func (s *StrategyTrader) ClaimPositions(symbol string) error {
+	positions, err := s.at.getPositions()
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	account := s.at.accountKey()
+	for _, pos := range positions {
+		if pos["symbol"] != symbol {
+			continue
+		}
+		side, _ := pos["side"].(string)
+		owner, ok := allocations.owner(account, symbol+"_"+side)
+		if ok && owner != s.owner() {
+			return fmt.Errorf("%s %s仓属于其他策略(%s)", symbol, sideName(side), owner)
+		}
+		if ok {
+			continue
+		}
+		quantity, _ := pos["positionAmt"].(float64)
+		price, _ := pos["markPrice"].(float64)
+		margin := math.Abs(quantity) * price
+		if leverage, _ := pos["leverage"].(float64); leverage > 0 {
+			margin /= leverage
+		}
+		allocations.claim(account, symbol+"_"+side, s.owner(), margin)
+	}
+	return nil
+}
+
+// OpenLong 开多仓
+func (s *StrategyTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return s.place(symbol, "open_long", quantity, leverage)
+}
+
+// OpenShort 开空仓
+func (s *StrategyTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return s.place(symbol, "open_short", quantity, leverage)
+}
+
+// CloseLong 平多仓（quantity=0表示全部平仓）
+func (s *StrategyTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return s.place(symbol, "close_long", quantity, 0)
+}
+
+// CloseShort 平空仓（quantity=0表示全部平仓）
+func (s *StrategyTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return s.place(symbol, "close_short", quantity, 0)
+}
+
+// place 风控检查后下单（模拟交易返回nil订单）
+func (s *StrategyTrader) place(symbol, action string, quantity float64, leverage int) (map[string]interface{}, error) {
+	at := s.at
+	if err := at.checkWritable(); err != nil {
+		return nil, err
+	}
+	side := action[strings.Index(action, "_")+1:]
+	posKey := symbol + "_" + side
+	opening := strings.HasPrefix(action, "open_")
+
+	owner, owned := allocations.owner(at.accountKey(), posKey)
+	if owned && owner != s.owner() {
+		return nil, fmt.Errorf("❌ %s %s仓属于其他策略(%s)，拒绝%s", symbol, sideName(side), owner, strategyActionName(action))
+	}
+
+	var margin float64
+	if opening {
+		if err := at.checkHalted(); err != nil {
+			return nil, err
+		}
+		if until := at.PausedUntil(); !until.IsZero() {
+			return nil, fmt.Errorf("❌ [%s] 交易已暂停（至 %s），拒绝%s", at.name, until.Format("01-02 15:04"), strategyActionName(action))
+		}
+		if leverage <= 0 {
+			return nil, fmt.Errorf("杠杆必须大于0: %d", leverage)
+		}
+		if err := at.checkLeverageLimit(symbol, leverage); err != nil {
+			return nil, err
+		}
+		if err := at.checkMaintenance(symbol); err != nil {
+			return nil, err
+		}
+		if err := at.checkDelisting(symbol); err != nil {
+			return nil, err
+		}
+		price, err := at.trader.GetMarketPrice(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 价格失败: %w", symbol, err)
+		}
+		margin = quantity * price / float64(leverage)
+		if err := at.checkAllocationFor(s.owner(), symbol, side, margin); err != nil {
+			return nil, err
+		}
+	}
+
+	if at.config.DryRun {
+		log.Printf("  🧪 [预演] [%s %s] WOULD %s %s %.6f，未下单", at.name, s.strategy, strategyActionName(action), symbol, quantity)
+		return nil, nil
+	}
+
+	unlock := at.lockSymbol(symbol)
+	defer unlock()
+	defer at.book.Invalidate()
+
+	order, err := placeOrder(at.trader, symbol, action, quantity, leverage, s.purpose)
+	if err != nil {
+		return nil, err
+	}
+	if opening {
+		allocations.claim(at.accountKey(), posKey, s.owner(), allocations.marginOf(at.accountKey(), posKey)+margin)
+	} else if s.remaining(symbol, side) == 0 {
+		allocations.release(at.accountKey(), posKey, s.owner())
+	}
+	return order, nil
+}
+
+// remaining 平仓后该方向剩余的持仓数量（查询失败时按仍有持仓处理，保留归属）
+func (s *StrategyTrader) remaining(symbol, side string) float64 {
+	positions, err := s.at.getPositions()
+	if err != nil {
+		return math.Inf(1)
+	}
+	for _, pos := range positions {
+		if pos["symbol"] == symbol && pos["side"] == side {
+			quantity, _ := pos["positionAmt"].(float64)
+			return math.Abs(quantity)
+		}
+	}
+	return 0
+}
+
+// strategyActionName 下单动作的中文名（如 开多仓）
+func strategyActionName(action string) string {
+	name := "平"
+	if strings.HasPrefix(action, "open_") {
+		name = "开"
+	}
+	return name + sideName(action[strings.Index(action, "_")+1:]) + "仓"
+}
+
+// SupportsSpot 交易所是否支持现货下单（见 SpotTrader）
+func (s *StrategyTrader) SupportsSpot() bool {
+	_, ok := s.at.trader.(SpotTrader)
+	return ok
+}
+
+// spotTrader 检查观察模式及模拟交易后返回交易所的现货下单接口
+func (s *StrategyTrader) spotTrader(symbol, action string) (SpotTrader, error) {
+	spot, ok := s.at.trader.(SpotTrader)
+	if !ok {
+		return nil, fmt.Errorf("交易员 %s 的交易所不支持现货下单", s.at.name)
+	}
+	if err := s.at.checkWritable(); err != nil {
+		return nil, err
+	}
+	if s.at.config.DryRun {
+		return nil, fmt.Errorf("模拟交易，未%s现货 %s", action, symbol)
+	}
+	return spot, nil
+}
+
+// GetSpotPrice 获取现货最新价格
+func (s *StrategyTrader) GetSpotPrice(symbol string) (float64, error) {
+	spot, ok := s.at.trader.(SpotTrader)
+	if !ok {
+		return 0, fmt.Errorf("交易员 %s 的交易所不支持现货下单", s.at.name)
+	}
+	return spot.GetSpotPrice(symbol)
+}
+
+// SpotBuy 以USDT金额市价买入现货（模拟交易返回错误，调用方应先检查 IsDryRun）
+func (s *StrategyTrader) SpotBuy(symbol string, quoteAmount float64) (*SpotFill, error) {
+	spot, err := s.spotTrader(symbol, "买入")
+	if err != nil {
+		return nil, err
+	}
+	unlock := s.at.lockSymbol(symbol)
+	defer unlock()
+	return spot.SpotBuy(symbol, quoteAmount)
+}
+
+// SpotSell 市价卖出指定数量的现货（模拟交易返回错误，调用方应先检查 IsDryRun）
+func (s *StrategyTrader) SpotSell(symbol string, quantity float64) (*SpotFill, error) {
+	spot, err := s.spotTrader(symbol, "卖出")
+	if err != nil {
+		return nil, err
+	}
+	unlock := s.at.lockSymbol(symbol)
+	defer unlock()
+	return spot.SpotSell(symbol, quantity)
+}
+
+// IsHalted 交易员是否处于紧急停止状态
+func (s *StrategyTrader) IsHalted() bool {
+	return s.at.IsHalted()
+}
+
+// SupportsLimitOrders 交易所是否支持限价挂单（见 LimitOrderTrader）
+func (s *StrategyTrader) SupportsLimitOrders() bool {
+	_, ok := s.at.trader.(LimitOrderTrader)
+	return ok
+}
+
+// SetLeverage 检查交易员的杠杆上限后设置合约杠杆（启动时调用，限价挂单按该杠杆计算占用的保证金）
+func (s *StrategyTrader) SetLeverage(symbol string, leverage int) error {
+	if err := s.at.checkWritable(); err != nil {
+		return err
+	}
+	if err := s.at.checkLeverageLimit(symbol, leverage); err != nil {
+		return err
+	}
+	if err := s.at.trader.SetLeverage(symbol, leverage); err != nil {
+		return err
+	}
+	s.leverage[symbol] = leverage
+	return nil
+}
+
+// limitTrader 检查观察模式、模拟交易及暂停状态后返回交易所的限价挂单接口
+func (s *StrategyTrader) limitTrader() (LimitOrderTrader, error) {
+	at := s.at
+	limits, ok := at.trader.(LimitOrderTrader)
+	if !ok {
+		return nil, fmt.Errorf("交易员 %s 的交易所不支持限价挂单", at.name)
+	}
+	if err := at.checkWritable(); err != nil {
+		return nil, err
+	}
+	if at.config.DryRun {
+		return nil, fmt.Errorf("模拟交易，未挂单")
+	}
+	if until := at.PausedUntil(); !until.IsZero() {
+		return nil, fmt.Errorf("❌ [%s] 交易已暂停（至 %s），拒绝挂单", at.name, until.Format("01-02 15:04"))
+	}
+	return limits, nil
+}
+
+// PlaceLimitOrder 风控检查后挂限价单（side为 OrderSideBuy/OrderSideSell），检查持仓归属、维护及下架和资金分配上限，
+// 与同一合约的其他下单串行执行并写入下单意图日志
+func (s *StrategyTrader) PlaceLimitOrder(symbol, side string, quantity, price float64, postOnly bool) (int64, error) {
+	at := s.at
+	limits, err := s.limitTrader()
+	if err != nil {
+		return 0, err
+	}
+	posSide := "long"
+	if side == OrderSideSell {
+		posSide = "short"
+	}
+	for _, ps := range []string{"long", "short"} {
+		if owner, ok := allocations.owner(at.accountKey(), symbol+"_"+ps); ok && owner != s.owner() {
+			return 0, fmt.Errorf("❌ %s %s仓属于其他策略(%s)，拒绝挂单", symbol, sideName(ps), owner)
+		}
+	}
+	if err := at.checkMaintenance(symbol); err != nil {
+		return 0, err
+	}
+	if err := at.checkDelisting(symbol); err != nil {
+		return 0, err
+	}
+	leverage := s.leverage[symbol]
+	if leverage <= 0 {
+		leverage = 1
+	}
+	if err := at.checkAllocationFor(s.owner(), symbol, posSide, quantity*price/float64(leverage)); err != nil {
+		return 0, err
+	}
+
+	unlock := at.lockSymbol(symbol)
+	defer unlock()
+	defer at.book.Invalidate()
+
+	order, err := submitJournaled(at.trader, symbol, "limit_"+strings.ToLower(side), quantity, s.purpose, OrderPurposeQuote, func() (map[string]interface{}, error) {
+		orderID, err := limits.PlaceLimitOrder(symbol, side, quantity, price, postOnly)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"orderId": orderID}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	orderID, _ := order["orderId"].(int64)
+	return orderID, nil
+}
+
+// AmendOrder 修改挂单的价格和数量（与同一合约的其他下单串行执行）
+func (s *StrategyTrader) AmendOrder(symbol string, orderID int64, side string, quantity, price float64) error {
+	limits, err := s.limitTrader()
+	if err != nil {
+		return err
+	}
+	unlock := s.at.lockSymbol(symbol)
+	defer unlock()
+	return limits.AmendOrder(symbol, orderID, side, quantity, price)
+}
+
+// CancelOrder 撤销单个挂单（暂停及紧急停止后仍可撤单）
+func (s *StrategyTrader) CancelOrder(symbol string, orderID int64) error {
+	limits, ok := s.at.trader.(LimitOrderTrader)
+	if !ok {
+		return fmt.Errorf("交易员 %s 的交易所不支持限价挂单", s.at.name)
+	}
+	unlock := s.at.lockSymbol(symbol)
+	defer unlock()
+	defer s.at.book.Invalidate()
+	return limits.CancelOrder(symbol, orderID)
+}
@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrategyTraderOwnership(t *testing.T) {
+	stub := &positionStub{stubTrader: newStubTrader()}
+	at := &AutoTrader{id: "strategy-t1", name: "strategy-t1", trader: stub, config: AutoTraderConfig{BTCETHLeverage: 5}}
+	hedge := at.NewStrategyTrader("hedge:BTC", OrderPurposeHedge)
+
+	if _, err := hedge.OpenShort("BTCUSDT", 0.1, 1); err != nil {
+		t.Fatalf("OpenShort: %v", err)
+	}
+	if len(stub.actions) != 1 || stub.actions[0] != "open_short" {
+		t.Fatalf("actions = %v", stub.actions)
+	}
+	if owner := at.positionOwner("BTCUSDT", "short"); owner != "strategy-t1/hedge:BTC" {
+		t.Fatalf("owner = %q", owner)
+	}
+	// 对冲持仓计入交易员的资金占用，但AI策略不能平掉
+	if used := allocations.usedBy(at.accountKey(), at.id); used != 10 {
+		t.Errorf("usedBy = %v, want 10", used)
+	}
+	if err := at.checkCloseOwnership("BTCUSDT", "short"); err == nil {
+		t.Error("AI close of a hedge position accepted")
+	}
+
+	// 杠杆超过交易员上限时拒绝
+	if _, err := hedge.OpenLong("BTCUSDT", 0.1, 10); err == nil {
+		t.Error("leverage above the trader limit accepted")
+	}
+
+	// 全部平仓后释放归属
+	if _, err := hedge.CloseShort("BTCUSDT", 0.1); err != nil {
+		t.Fatalf("CloseShort: %v", err)
+	}
+	if owner := at.positionOwner("BTCUSDT", "short"); owner != "" {
+		t.Errorf("owner after close = %q", owner)
+	}
+}
+
+func TestStrategyTraderDryRun(t *testing.T) {
+	stub := &positionStub{stubTrader: newStubTrader()}
+	at := &AutoTrader{id: "strategy-t2", name: "strategy-t2", trader: stub, config: AutoTraderConfig{BTCETHLeverage: 5, DryRun: true}}
+	order, err := at.NewStrategyTrader("hedge:BTC", OrderPurposeHedge).OpenShort("BTCUSDT", 0.1, 1)
+	if err != nil || order != nil || len(stub.actions) != 0 {
+		t.Fatalf("dry run placed an order: order=%v err=%v actions=%v", order, err, stub.actions)
+	}
+}
+
+func TestPlaceOrderStrategyPurpose(t *testing.T) {
+	stub := newStubTrader()
+	order, err := placeOrder(stub, "BTCUSDT", "open_short", 0.1, 1, OrderPurposeHedge)
+	if err != nil {
+		t.Fatalf("placeOrder: %v", err)
+	}
+	tag, ok := ParseOrderTag(order["clientOrderId"].(string))
+	if !ok || tag.Strategy != stub.orderTag.Strategy || !strings.Contains(order["clientOrderId"].(string), ".h") {
+		t.Errorf("clientOrderId = %v", order["clientOrderId"])
+	}
+}
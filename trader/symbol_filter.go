@@ -0,0 +1,106 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/decision"
+	"strconv"
+	"strings"
+)
+
+// ParseSymbolList 解析逗号分隔的币种列表（如 "BTC,ETHUSDT"）
+func ParseSymbolList(s string) map[string]bool {
+	symbols := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			symbols[normalizeSymbol(item)] = true
+		}
+	}
+	return symbols
+}
+
+// ParseSymbolNotionalCaps 解析按币种的最大持仓名义价值
+// 格式: "BTCUSDT:5000,ETHUSDT:2000,*:1000"，"*" 为其他币种的默认上限
+func ParseSymbolNotionalCaps(s string) (map[string]float64, error) {
+	caps := make(map[string]float64)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的名义价值上限配置: %s（格式应为 SYMBOL:USDT金额）", item)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("无效的名义价值上限: %s（必须为正数）", parts[1])
+		}
+		symbol := strings.TrimSpace(parts[0])
+		if symbol != "*" {
+			symbol = normalizeSymbol(symbol)
+		}
+		caps[symbol] = value
+	}
+	return caps, nil
+}
+
+// symbolAllowed 币种是否通过白名单/黑名单（白名单为空表示不限制）
+func (at *AutoTrader) symbolAllowed(symbol string) bool {
+	if at.config.SymbolBlacklist[symbol] {
+		return false
+	}
+	return len(at.config.SymbolWhitelist) == 0 || at.config.SymbolWhitelist[symbol]
+}
+
+// checkSymbolFilter 不在白名单或位于黑名单的币种拒绝开仓
+func (at *AutoTrader) checkSymbolFilter(symbol string) error {
+	if at.config.SymbolBlacklist[symbol] {
+		return fmt.Errorf("❌ %s 在黑名单中，拒绝开仓", symbol)
+	}
+	if len(at.config.SymbolWhitelist) > 0 && !at.config.SymbolWhitelist[symbol] {
+		return fmt.Errorf("❌ %s 不在白名单中，拒绝开仓", symbol)
+	}
+	return nil
+}
+
+// checkSymbolNotional 开仓后该币种的持仓名义价值（多空合计）不得超过配置上限
+func (at *AutoTrader) checkSymbolNotional(symbol string, notional float64, positions []map[string]interface{}) error {
+	limit, ok := at.config.SymbolMaxNotional[symbol]
+	if !ok {
+		limit, ok = at.config.SymbolMaxNotional["*"]
+	}
+	if !ok {
+		return nil
+	}
+
+	existing := 0.0
+	for _, pos := range positions {
+		if posSymbol, _ := pos["symbol"].(string); posSymbol != symbol {
+			continue
+		}
+		quantity, _ := pos["positionAmt"].(float64)
+		markPrice, _ := pos["markPrice"].(float64)
+		existing += math.Abs(quantity) * markPrice
+	}
+	if existing+notional > limit {
+		return fmt.Errorf("❌ %s 开仓后持仓名义价值 %.2f USDT 超过上限 %.2f USDT（已有 %.2f），拒绝开仓",
+			symbol, existing+notional, limit, existing)
+	}
+	return nil
+}
+
+// filterAllowedCandidates 从候选币种中剔除不允许交易的币种，避免AI在其上浪费决策
+func (at *AutoTrader) filterAllowedCandidates(coins []decision.CandidateCoin) []decision.CandidateCoin {
+	if len(at.config.SymbolWhitelist) == 0 && len(at.config.SymbolBlacklist) == 0 {
+		return coins
+	}
+	allowed := coins[:0]
+	for _, c := range coins {
+		if at.symbolAllowed(c.Symbol) {
+			allowed = append(allowed, c)
+		}
+	}
+	return allowed
+}
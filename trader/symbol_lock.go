@@ -0,0 +1,59 @@
+package trader
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// symbolLocks 按 账户+合约 维度的互斥锁，保证同一合约的下单/撤单/止盈止损
+// 操作串行执行（例如一个goroutine的撤销全部挂单不会与另一个的止盈下单交错）
+type symbolLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// orderLocks 进程内共享：同一交易账户下的多个trader也会互相串行
+var orderLocks = &symbolLocks{locks: make(map[string]*sync.Mutex)}
+
+// lock 获取指定key的锁，返回解锁函数（label仅用于日志，key中含账户标识不应输出）
+func (l *symbolLocks) lock(key, label string) func() {
+	l.mu.Lock()
+	m, ok := l.locks[key]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[key] = m
+	}
+	l.mu.Unlock()
+
+	start := time.Now()
+	m.Lock()
+	if waited := time.Since(start); waited > time.Second {
+		log.Printf("  ⏳ 等待 %s 的其他订单操作完成（%.1f秒）", label, waited.Seconds())
+	}
+	return m.Unlock
+}
+
+// accountKey 交易账户标识（用于跨trader共享同一账户的订单锁）
+func (at *AutoTrader) accountKey() string {
+	switch at.exchange {
+	case "binance":
+		return "binance:" + at.config.BinanceAPIKey
+	case "hyperliquid":
+		return "hyperliquid:" + strings.ToLower(at.config.HyperliquidWalletAddr)
+	case "aster":
+		return "aster:" + strings.ToLower(at.config.AsterUser)
+	case "gate":
+		return "gate:" + at.config.GateAPIKey
+	default:
+		return at.exchange + ":" + at.id
+	}
+}
+
+// lockSymbol 锁定当前账户下指定合约的订单操作，返回解锁函数
+func (at *AutoTrader) lockSymbol(symbol string) func() {
+	// 统一 BTC_USDT / btcusdt 等写法
+	contract := strings.ToUpper(strings.ReplaceAll(symbol, "_", ""))
+	return orderLocks.lock(at.accountKey()+"|"+contract, contract)
+}
@@ -0,0 +1,209 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TargetLeg 调整到目标仓位需要下达的一笔订单（平仓Quantity=0表示全部平仓）
+type TargetLeg struct {
+	Action   string  `json:"action"` // open_long / open_short / close_long / close_short
+	Quantity float64 `json:"quantity"`
+}
+
+// TargetOrder 已下达的订单
+type TargetOrder struct {
+	TargetLeg
+	OrderID int64  `json:"order_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TargetPositionResult 调整仓位的结果
+type TargetPositionResult struct {
+	Symbol  string        `json:"symbol"`
+	Current float64       `json:"current"` // 调整前的净仓位（多为正、空为负）
+	Target  float64       `json:"target"`
+	Orders  []TargetOrder `json:"orders"`
+	DryRun  bool          `json:"dry_run,omitempty"` // 模拟交易：只返回将要下达的订单
+}
+
+// PlanTargetPosition 计算从当前多/空持仓数量（均为正数）调整到目标净仓位（多为正、空为负）的最少订单
+// 先平掉反方向持仓（双向持仓模式下可能同时持有多空），再对同方向持仓加仓或减仓；方向翻转时为一笔全部平仓加一笔反向开仓
+func PlanTargetPosition(long, short, target float64) []TargetLeg {
+	var legs []TargetLeg
+	side, opposite, held, oppositeHeld, size := "long", "short", long, short, target
+	if target < 0 {
+		side, opposite, held, oppositeHeld, size = "short", "long", short, long, -target
+	}
+	if oppositeHeld > 0 {
+		legs = append(legs, TargetLeg{Action: "close_" + opposite})
+	}
+	switch delta := size - held; {
+	case size == 0 && held > 0:
+		legs = append(legs, TargetLeg{Action: "close_" + side})
+	case delta > 0:
+		legs = append(legs, TargetLeg{Action: "open_" + side, Quantity: delta})
+	case delta < 0:
+		legs = append(legs, TargetLeg{Action: "close_" + side, Quantity: -delta})
+	}
+	return legs
+}
+
+// SetTargetPosition 将交易对的净仓位调整到目标数量（多为正、空为负、0=全部平仓），按 PlanTargetPosition 的顺序下市价单
+// 按交易所精度取整后为0的加减仓跳过；某笔订单失败时停止，返回已下达的订单及错误（先平后开，失败时不会超出目标敞口）
+func SetTargetPosition(t Trader, symbol string, target float64, leverage int) (*TargetPositionResult, error) {
+	return setTargetPosition(t, symbol, target, func(leg TargetLeg) (map[string]interface{}, error) {
+		return PlaceOrder(t, symbol, leg.Action, leg.Quantity, leverage)
+	})
+}
+
+// planTarget 读取当前多/空持仓，返回调整前的结果及按交易所精度取整后需要下达的订单
+func planTarget(t Trader, symbol string, target float64) (*TargetPositionResult, []TargetLeg, error) {
+	positions, err := t.GetPositions()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取持仓失败: %w", err)
+	}
+	var long, short float64
+	for _, pos := range positions {
+		posSymbol, _ := pos["symbol"].(string)
+		if !sameSymbol(posSymbol, symbol) {
+			continue
+		}
+		amt, _ := pos["positionAmt"].(float64)
+		switch pos["side"] {
+		case "long":
+			long += math.Abs(amt)
+		case "short":
+			short += math.Abs(amt)
+		}
+	}
+
+	var legs []TargetLeg
+	for _, leg := range PlanTargetPosition(long, short, target) {
+		if leg.Quantity > 0 {
+			if formatted, err := t.FormatQuantity(symbol, leg.Quantity); err == nil {
+				if rounded, _ := strconv.ParseFloat(formatted, 64); rounded <= 0 {
+					log.Printf("  ⏭ %s %s %.8f 小于最小下单精度，跳过", symbol, leg.Action, leg.Quantity)
+					continue
+				}
+			}
+		}
+		legs = append(legs, leg)
+	}
+	return &TargetPositionResult{Symbol: symbol, Current: long - short, Target: target}, legs, nil
+}
+
+// setTargetPosition 按 planTarget 的订单依次调用place下单，某笔失败时停止
+func setTargetPosition(t Trader, symbol string, target float64, place func(leg TargetLeg) (map[string]interface{}, error)) (*TargetPositionResult, error) {
+	result, legs, err := planTarget(t, symbol, target)
+	if err != nil {
+		return nil, err
+	}
+	for _, leg := range legs {
+		order := TargetOrder{TargetLeg: leg}
+		placed, err := place(leg)
+		if err != nil {
+			order.Error = err.Error()
+			result.Orders = append(result.Orders, order)
+			return result, fmt.Errorf("%s %s 失败: %w", symbol, leg.Action, err)
+		}
+		order.OrderID, _ = placed["orderId"].(int64)
+		result.Orders = append(result.Orders, order)
+	}
+	return result, nil
+}
+
+// SetTargetPosition 将交易对的净仓位调整到目标数量（多为正、空为负、0=全部平仓），方向翻转时一次完成平仓及反向开仓
+// 杠杆使用交易员配置的上限（BTC/ETH与山寨币分别配置），新开仓位不自动设置止损止盈；
+// 加仓与AI开仓走同一风控检查，平仓检查持仓归属；模拟交易时只返回将要下达的订单
+func (at *AutoTrader) SetTargetPosition(symbol string, signedQuantity float64) (*TargetPositionResult, error) {
+	if err := at.checkWritable(); err != nil {
+		return nil, err
+	}
+	leverage := at.config.AltcoinLeverage
+	if symbol == "BTCUSDT" || symbol == "ETHUSDT" {
+		leverage = at.config.BTCETHLeverage
+	}
+
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+	if at.config.DryRun {
+		result, legs, err := planTarget(at.trader, symbol, signedQuantity)
+		if err != nil {
+			return nil, err
+		}
+		result.DryRun = true
+		for _, leg := range legs {
+			result.Orders = append(result.Orders, TargetOrder{TargetLeg: leg})
+		}
+		log.Printf("🧪 [%s] 模拟交易，WOULD 将 %s 调整到目标仓位 %.6f（调整前 %.6f，%d 笔订单），未下单", at.name, symbol, signedQuantity, result.Current, len(legs))
+		return result, nil
+	}
+
+	// 读取持仓到下完全部订单期间锁定该合约，避免与止损、批量平仓及共用账户的其他交易员交错（按过期持仓计算订单）
+	unlock := at.lockSymbol(symbol)
+	defer unlock()
+	defer at.book.Invalidate()
+	result, err := setTargetPosition(at.trader, symbol, signedQuantity, func(leg TargetLeg) (map[string]interface{}, error) {
+		side := strings.TrimPrefix(strings.TrimPrefix(leg.Action, "open_"), "close_")
+		if strings.HasPrefix(leg.Action, "open_") {
+			return at.openTargetLeg(symbol, side, leg.Quantity, leverage)
+		}
+		if err := at.checkCloseOwnership(symbol, side); err != nil {
+			return nil, err
+		}
+		order, err := PlaceOrder(at.trader, symbol, leg.Action, leg.Quantity, leverage)
+		if err == nil && leg.Quantity == 0 {
+			at.releaseAllocation(symbol, side)
+		}
+		return order, err
+	})
+	if result != nil && len(result.Orders) > 0 {
+		log.Printf("🎯 [%s] %s 目标仓位 %.6f（调整前 %.6f），下达 %d 笔订单", at.name, symbol, signedQuantity, result.Current, len(result.Orders))
+	}
+	return result, err
+}
+
+// openTargetLeg 目标仓位的加仓：与AI开仓走同一风控检查（紧急停止、币种过滤、频率/冷却、禁止开仓窗口、维护及下架、
+// 交易成本、资金分配及敞口），通过后市价开仓并记录保证金占用
+func (at *AutoTrader) openTargetLeg(symbol, side string, quantity float64, leverage int) (map[string]interface{}, error) {
+	if until := at.PausedUntil(); !until.IsZero() {
+		return nil, fmt.Errorf("❌ [%s] 交易已暂停（至 %s），拒绝开仓", at.name, until.Format("01-02 15:04"))
+	}
+	price, err := at.trader.GetMarketPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格失败: %w", err)
+	}
+	positions, _ := at.getPositions()
+	d := &decision.Decision{Symbol: symbol, Action: "open_" + side, Leverage: leverage, PositionSizeUSD: quantity * price, Reasoning: "目标仓位"}
+	var record logger.DecisionAction
+	if _, quantity, err = at.checkOpenRisk(d, side, positions, &record); err != nil {
+		return nil, err
+	}
+	order, err := at.openPosition(symbol, side, quantity, d.Leverage)
+	if err != nil {
+		return nil, err
+	}
+	at.claimAllocation(symbol, side, d.PositionSizeUSD/float64(d.Leverage))
+	at.recordEntry(symbol, time.Now())
+	return order, nil
+}
+
+// TargetNotional 调整到目标仓位的名义价值（净仓位变化 × 当前价格，用于两人规则）
+func (at *AutoTrader) TargetNotional(symbol string, target float64) (float64, error) {
+	result, _, err := planTarget(at.trader, symbol, target)
+	if err != nil {
+		return 0, err
+	}
+	price, err := at.trader.GetMarketPrice(symbol)
+	if err != nil {
+		return 0, fmt.Errorf("获取价格失败: %w", err)
+	}
+	return math.Abs(target-result.Current) * price, nil
+}
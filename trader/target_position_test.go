@@ -0,0 +1,86 @@
+package trader
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPlanTargetPosition(t *testing.T) {
+	tests := []struct {
+		name        string
+		long, short float64
+		target      float64
+		want        []TargetLeg
+	}{
+		{"空仓开多", 0, 0, 1, []TargetLeg{{"open_long", 1}}},
+		{"加多", 1, 0, 1.5, []TargetLeg{{"open_long", 0.5}}},
+		{"减多", 1.5, 0, 1, []TargetLeg{{"close_long", 0.5}}},
+		{"已在目标", 1, 0, 1, nil},
+		{"全部平多", 1, 0, 0, []TargetLeg{{"close_long", 0}}},
+		{"多翻空", 1, 0, -2, []TargetLeg{{"close_long", 0}, {"open_short", 2}}},
+		{"空翻多", 0, 2, 0.5, []TargetLeg{{"close_short", 0}, {"open_long", 0.5}}},
+		{"双向持仓只保留空单", 1, 3, -2, []TargetLeg{{"close_long", 0}, {"close_short", 1}}},
+		{"空仓目标为0", 0, 0, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlanTargetPosition(tt.long, tt.short, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanTargetPosition(%v, %v, %v) = %v, want %v", tt.long, tt.short, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+// positionStub 持有固定仓位并记录下单动作的交易器
+type positionStub struct {
+	*stubTrader
+	positions []map[string]interface{}
+	actions   []string
+}
+
+func (p *positionStub) GetPositions() ([]map[string]interface{}, error) { return p.positions, nil }
+
+func (p *positionStub) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	p.actions = append(p.actions, "open_short")
+	return map[string]interface{}{"orderId": int64(2)}, nil
+}
+
+func (p *positionStub) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	p.actions = append(p.actions, "close_long")
+	p.reduce(symbol, "long", quantity)
+	return map[string]interface{}{"orderId": int64(1)}, nil
+}
+
+// reduce 模拟平仓成交（quantity=0为全部平仓）
+func (p *positionStub) reduce(symbol, side string, quantity float64) {
+	kept := p.positions[:0:0]
+	for _, pos := range p.positions {
+		if sameSymbol(pos["symbol"].(string), symbol) && pos["side"] == side {
+			amount := pos["positionAmt"].(float64)
+			if quantity == 0 || quantity >= math.Abs(amount) {
+				continue
+			}
+			pos["positionAmt"] = amount - math.Copysign(quantity, amount)
+		}
+		kept = append(kept, pos)
+	}
+	p.positions = kept
+}
+
+func TestSetTargetPositionFlip(t *testing.T) {
+	p := &positionStub{stubTrader: newStubTrader(), positions: []map[string]interface{}{
+		{"symbol": "BTC_USDT", "side": "long", "positionAmt": 0.5},
+		{"symbol": "ETHUSDT", "side": "short", "positionAmt": -3.0},
+	}}
+	result, err := SetTargetPosition(p, "BTCUSDT", -0.2, 5)
+	if err != nil {
+		t.Fatalf("SetTargetPosition: %v", err)
+	}
+	if !reflect.DeepEqual(p.actions, []string{"close_long", "open_short"}) {
+		t.Errorf("actions = %v", p.actions)
+	}
+	if result.Current != 0.5 || len(result.Orders) != 2 || result.Orders[1].Quantity != 0.2 || result.Orders[1].OrderID != 2 {
+		t.Errorf("result = %+v", result)
+	}
+}
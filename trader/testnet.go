@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"fmt"
+	"time"
+)
+
+// TestnetFaucet 测试网领取测试资金的方式
+type TestnetFaucet struct {
+	URL  string // 领取页面
+	Note string // 领取说明
+}
+
+// testnetFaucets 支持测试网的交易所及其水龙头
+// 目前支持的交易所均未开放领取测试资金的API，只能在网页上领取
+var testnetFaucets = map[string]TestnetFaucet{
+	"hyperliquid": {URL: "https://app.hyperliquid-testnet.xyz/drip", Note: "连接同一钱包地址领取测试USDC（要求该地址在主网有过入金）"},
+	"gate":        {URL: "https://www.gate.com/testnet", Note: "登录后在测试网页面申请测试资金，并在测试网创建API Key"},
+}
+
+// TestnetFaucetFor 获取交易所的测试网水龙头，不支持测试网时返回false
+func TestnetFaucetFor(exchange string) (TestnetFaucet, bool) {
+	faucet, ok := testnetFaucets[exchange]
+	return faucet, ok
+}
+
+// TestnetFunder 可通过API领取测试资金的交易器（可选接口）
+type TestnetFunder interface {
+	// RequestTestnetFunds 领取测试资金，返回到账金额
+	RequestTestnetFunds() (float64, error)
+}
+
+// TestnetReport 测试网连通性检查结果
+type TestnetReport struct {
+	Exchange   string           `json:"exchange"`
+	Latency    time.Duration    `json:"latency"`              // 账户查询耗时
+	Equity     float64          `json:"equity"`               // 钱包余额
+	Available  float64          `json:"available"`            // 可用余额
+	Preflight  *PreflightReport `json:"preflight"`            // API Key检查
+	NeedsFunds bool             `json:"needs_funds"`          // 余额低于要求
+	Funded     float64          `json:"funded,omitempty"`     // 本次领取到账的测试资金
+	FundError  string           `json:"fund_error,omitempty"` // 领取测试资金失败原因
+}
+
+// Err 连通性检查未通过时返回错误
+func (r *TestnetReport) Err() error {
+	return r.Preflight.Err()
+}
+
+// CheckTestnet 检查测试网连通性（账户查询、API Key权限及余额），余额低于minBalance时尝试通过API领取测试资金
+func CheckTestnet(t Trader, exchange string, minBalance float64) *TestnetReport {
+	report := &TestnetReport{Exchange: exchange}
+
+	start := time.Now()
+	balance, err := t.GetBalance()
+	report.Latency = time.Since(start)
+	if err != nil {
+		report.Preflight = &PreflightReport{Exchange: exchange, CheckedAt: time.Now(),
+			Problems: []string{fmt.Sprintf("无法访问测试网账户（请确认使用的是测试网API Key）: %v", err)}}
+		return report
+	}
+	report.Equity, _ = balance["totalWalletBalance"].(float64)
+	report.Available, _ = balance["availableBalance"].(float64)
+	report.Preflight = CheckKeyPermissions(t, exchange, false)
+
+	if report.Equity >= minBalance {
+		return report
+	}
+	report.NeedsFunds = true
+	funder, ok := t.(TestnetFunder)
+	if !ok {
+		return report
+	}
+	funded, err := funder.RequestTestnetFunds()
+	if err != nil {
+		report.FundError = err.Error()
+		return report
+	}
+	report.Funded = funded
+	report.NeedsFunds = report.Equity+funded < minBalance
+	return report
+}
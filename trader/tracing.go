@@ -0,0 +1,137 @@
+package trader
+
+import (
+	"context"
+	"net/http"
+	"nofx/tracing"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName 交易链路span所属的Tracer
+const tracerName = "nofx/trader"
+
+// PipelineTracer 交易员当前所处的交易链路span（决策周期 → 决策 → 风控 → 执行）
+// 交易所接口不传递context，交易所请求通过它找到父span，从而挂在对应的链路阶段下
+type PipelineTracer struct {
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+// current 当前链路阶段的context（不在链路中时为 context.Background()）
+func (p *PipelineTracer) current() context.Context {
+	if p == nil {
+		return context.Background()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// stageSpan 一个链路阶段的span
+type stageSpan struct {
+	tracer *PipelineTracer
+	parent context.Context
+	ctx    context.Context
+	span   trace.Span
+}
+
+// start 在当前阶段下开始子阶段
+func (p *PipelineTracer) start(name string, attrs ...attribute.KeyValue) *stageSpan {
+	parent := p.current()
+	ctx, span := tracing.Tracer(tracerName).Start(parent, name, trace.WithAttributes(attrs...))
+	if p == nil {
+		return &stageSpan{parent: parent, ctx: ctx, span: span}
+	}
+	p.mu.Lock()
+	p.ctx = ctx
+	p.mu.Unlock()
+	return &stageSpan{tracer: p, parent: parent, ctx: ctx, span: span}
+}
+
+// End 结束阶段（err非nil时标记为失败）并恢复父阶段
+func (s *stageSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+	if s.tracer == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	if s.tracer.ctx == s.ctx {
+		s.tracer.ctx = s.parent
+	}
+	s.tracer.mu.Unlock()
+}
+
+// startSpan 开始交易链路阶段（附带交易员标识）
+func (at *AutoTrader) startSpan(name string, attrs ...attribute.KeyValue) *stageSpan {
+	return at.tracer.start(name, append([]attribute.KeyValue{attribute.String("trader.id", at.id), attribute.String("exchange", at.exchange)}, attrs...)...)
+}
+
+// tracedTransport 为交易链路中的交易所请求创建span（含排队等待频率预算的时间）
+type tracedTransport struct {
+	base   http.RoundTripper
+	tracer *PipelineTracer
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *tracedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	parent := t.tracer.current()
+	if !trace.SpanContextFromContext(parent).IsValid() {
+		// 不在交易链路中的请求（后台监控、行情轮询等）不追踪
+		return t.base.RoundTrip(req)
+	}
+	_, span := tracing.Tracer(tracerName).Start(parent, "exchange "+req.Method+" "+req.URL.Path, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("http.request.method", req.Method), attribute.String("server.address", req.URL.Host), attribute.String("url.path", req.URL.Path)))
+	defer span.End()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// ExchangeTraced 支持追踪交易所HTTP请求的交易器（可选接口）
+// Hyperliquid SDK 不暴露HTTP客户端，不实现该接口（其下单阶段仍有span，只是没有逐个请求的子span）
+type ExchangeTraced interface {
+	// SetPipelineTracer 交易所请求挂在交易链路的当前阶段下
+	SetPipelineTracer(p *PipelineTracer)
+}
+
+// SetPipelineTracer 实现 ExchangeTraced
+func (t *FuturesTrader) SetPipelineTracer(p *PipelineTracer) {
+	t.client.HTTPClient = withTracing(t.client.HTTPClient, p)
+}
+
+// SetPipelineTracer 实现 ExchangeTraced
+func (t *AsterTrader) SetPipelineTracer(p *PipelineTracer) {
+	t.client = withTracing(t.client, p)
+}
+
+// SetPipelineTracer 实现 ExchangeTraced
+func (t *GateTrader) SetPipelineTracer(p *PipelineTracer) {
+	cfg := t.client.GetConfig()
+	cfg.HTTPClient = withTracing(cfg.HTTPClient, p)
+}
+
+// withTracing 返回追踪交易所请求的HTTP客户端副本
+func withTracing(client *http.Client, p *PipelineTracer) *http.Client {
+	return withTransport(client, func(base http.RoundTripper) http.RoundTripper {
+		return &tracedTransport{base: base, tracer: p}
+	})
+}
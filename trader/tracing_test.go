@@ -0,0 +1,53 @@
+package trader
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPipelineTracerNesting(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	at := &AutoTrader{id: "t1", exchange: "binance", tracer: &PipelineTracer{}}
+	client := withTracing(nil, at.tracer)
+
+	client.Get(srv.URL + "/fapi/v1/ping") // 不在交易链路中，不追踪
+	cycle := at.startSpan("trading_cycle")
+	execute := at.startSpan("execute")
+	client.Get(srv.URL + "/fapi/v1/order")
+	execute.End(errors.New("rejected"))
+	client.Get(srv.URL + "/fapi/v2/account")
+	cycle.End(nil)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want 4", len(spans))
+	}
+	cycleID := spans["trading_cycle"].SpanContext().SpanID()
+	if got := spans["execute"].Parent().SpanID(); got != cycleID {
+		t.Errorf("execute parent = %s, want trading_cycle", got)
+	}
+	if got := spans["exchange GET /fapi/v1/order"].Parent().SpanID(); got != spans["execute"].SpanContext().SpanID() {
+		t.Errorf("order request parent = %s, want execute", got)
+	}
+	if got := spans["exchange GET /fapi/v2/account"].Parent().SpanID(); got != cycleID {
+		t.Errorf("request after execute ended should attach to trading_cycle, got %s", got)
+	}
+	if spans["execute"].Status().Code != codes.Error {
+		t.Errorf("execute status = %v, want error", spans["execute"].Status())
+	}
+}
@@ -0,0 +1,42 @@
+package trader
+
+import (
+	"nofx/logger"
+	"nofx/market"
+)
+
+// tradeContext 下单时的策略上下文：理由摘要及指标数值（data为空时按币种获取当前行情）
+func tradeContext(source, reasoning string, confidence int, symbol string, data *market.Data) *logger.TradeContext {
+	if data == nil {
+		data, _ = market.Get(symbol)
+	}
+	return logger.NewTradeContext(source, reasoning, confidence, marketIndicators(data))
+}
+
+// marketIndicators 行情数据中的关键指标（与交给AI的数值一致）
+func marketIndicators(data *market.Data) map[string]float64 {
+	if data == nil {
+		return nil
+	}
+	indicators := map[string]float64{
+		"price":           data.CurrentPrice,
+		"price_change_1h": data.PriceChange1h,
+		"price_change_4h": data.PriceChange4h,
+		"ema20_3m":        data.CurrentEMA20,
+		"macd_3m":         data.CurrentMACD,
+		"rsi7_3m":         data.CurrentRSI7,
+		"funding_rate":    data.FundingRate,
+	}
+	if data.OpenInterest != nil {
+		indicators["open_interest"] = data.OpenInterest.Latest
+	}
+	if lt := data.LongerTermContext; lt != nil {
+		indicators["ema20_4h"] = lt.EMA20
+		indicators["ema50_4h"] = lt.EMA50
+		indicators["atr14_4h"] = lt.ATR14
+		if n := len(lt.RSI14Values); n > 0 {
+			indicators["rsi14_4h"] = lt.RSI14Values[n-1]
+		}
+	}
+	return indicators
+}
@@ -0,0 +1,85 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// throttleLookback 启动时从决策日志恢复开仓记录所回溯的周期数
+const throttleLookback = 1000
+
+// entryRecord 一次成功的开仓
+type entryRecord struct {
+	symbol string
+	at     time.Time
+}
+
+// recordEntry 记录开仓，并清理24小时之前的记录
+func (at *AutoTrader) recordEntry(symbol string, when time.Time) {
+	at.entryHistory = append(at.entryHistory, entryRecord{symbol: symbol, at: when})
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	kept := at.entryHistory[:0]
+	for _, entry := range at.entryHistory {
+		if entry.at.After(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	at.entryHistory = kept
+}
+
+// checkTradeThrottle 检查交易频率限制（只限制开仓，平仓不受影响）
+func (at *AutoTrader) checkTradeThrottle(symbol string) error {
+	now := time.Now()
+	hourCount, dayCount, symbolCount := 0, 0, 0
+	for _, entry := range at.entryHistory {
+		if now.Sub(entry.at) > 24*time.Hour {
+			continue
+		}
+		dayCount++
+		if now.Sub(entry.at) <= time.Hour {
+			hourCount++
+		}
+		if entry.symbol == symbol {
+			symbolCount++
+		}
+	}
+
+	if limit := at.config.MaxTradesPerHour; limit > 0 && hourCount >= limit {
+		return fmt.Errorf("❌ 最近1小时已开仓 %d 次，达到每小时上限 %d，拒绝开仓 %s", hourCount, limit, symbol)
+	}
+	if limit := at.config.MaxTradesPerDay; limit > 0 && dayCount >= limit {
+		return fmt.Errorf("❌ 最近24小时已开仓 %d 次，达到每日上限 %d，拒绝开仓 %s", dayCount, limit, symbol)
+	}
+	if limit := at.config.MaxEntriesPerSymbolPerDay; limit > 0 && symbolCount >= limit {
+		return fmt.Errorf("❌ %s 最近24小时已开仓 %d 次，达到单币种每日上限 %d，拒绝开仓", symbol, symbolCount, limit)
+	}
+	return nil
+}
+
+// restoreEntryHistory 从决策日志恢复最近24小时的开仓记录，避免重启后频率限制被重置
+func (at *AutoTrader) restoreEntryHistory() {
+	if at.config.MaxTradesPerHour <= 0 && at.config.MaxTradesPerDay <= 0 && at.config.MaxEntriesPerSymbolPerDay <= 0 {
+		return
+	}
+
+	records, err := at.decisionLogger.GetLatestRecords(throttleLookback)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-24 * time.Hour)
+	for _, record := range records {
+		for _, action := range record.Decisions {
+			if !action.Success || action.DryRun || action.Timestamp.Before(cutoff) {
+				continue
+			}
+			if action.Action == "open_long" || action.Action == "open_short" {
+				at.recordEntry(action.Symbol, action.Timestamp)
+			}
+		}
+	}
+	if len(at.entryHistory) > 0 {
+		log.Printf("⏱  [%s] 已恢复最近24小时的 %d 次开仓记录（交易频率限制）", at.name, len(at.entryHistory))
+	}
+}
@@ -0,0 +1,212 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/events"
+	"nofx/market"
+	"strings"
+	"time"
+)
+
+// 跟踪止损算法
+const (
+	TrailingChandelier = "chandelier" // 吊灯止损：回看期最高价(多)/最低价(空) ∓ N×ATR
+	TrailingSwing      = "swing"      // 摆动结构：回看期最低价(多)/最高价(空)
+)
+
+const (
+	defaultTrailingInterval     = "1h"
+	defaultChandelierLookback   = 22
+	defaultSwingLookback        = 10
+	defaultChandelierMultiplier = 3.0
+)
+
+// trailingIntervals 支持的K线周期
+var trailingIntervals = map[string]bool{
+	"1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true, "1d": true,
+}
+
+// ValidateTrailingStop 校验跟踪止损配置（mode为空表示关闭）
+func ValidateTrailingStop(mode, interval string, lookback int, multiplier float64) error {
+	switch mode {
+	case "", TrailingChandelier, TrailingSwing:
+	default:
+		return fmt.Errorf("无效的跟踪止损算法: %s（可选: %s, %s）", mode, TrailingChandelier, TrailingSwing)
+	}
+	if interval != "" && !trailingIntervals[interval] {
+		return fmt.Errorf("无效的跟踪止损K线周期: %s", interval)
+	}
+	if lookback < 0 || lookback > 500 {
+		return fmt.Errorf("跟踪止损回看K线数必须在0-500之间: %d", lookback)
+	}
+	if multiplier < 0 {
+		return fmt.Errorf("跟踪止损ATR倍数不能为负数")
+	}
+	return nil
+}
+
+// trailingParams 跟踪止损参数（未配置的项使用默认值）
+func (at *AutoTrader) trailingParams() (interval string, lookback int, multiplier float64) {
+	interval = at.config.TrailingInterval
+	if interval == "" {
+		interval = defaultTrailingInterval
+	}
+	lookback = at.config.TrailingLookback
+	if lookback <= 0 {
+		lookback = defaultSwingLookback
+		if at.config.TrailingStopMode == TrailingChandelier {
+			lookback = defaultChandelierLookback
+		}
+	}
+	multiplier = at.config.TrailingATRMultiplier
+	if multiplier <= 0 {
+		multiplier = defaultChandelierMultiplier
+	}
+	return interval, lookback, multiplier
+}
+
+// trailingStatus 跟踪止损配置（用于状态展示）
+func (at *AutoTrader) trailingStatus() map[string]interface{} {
+	if at.config.TrailingStopMode == "" {
+		return map[string]interface{}{"mode": ""}
+	}
+	interval, lookback, multiplier := at.trailingParams()
+	status := map[string]interface{}{
+		"mode":     at.config.TrailingStopMode,
+		"interval": interval,
+		"lookback": lookback,
+	}
+	if at.config.TrailingStopMode == TrailingChandelier {
+		status["atr_multiplier"] = multiplier
+	}
+	return status
+}
+
+// trailingStopLevel 按已收盘K线计算跟踪止损价（K线不足时返回0）
+func trailingStopLevel(mode, side string, klines []market.Kline, lookback int, multiplier float64) float64 {
+	if len(klines) < lookback+1 {
+		return 0
+	}
+	window := klines[len(klines)-lookback:]
+	highest, lowest := window[0].High, window[0].Low
+	for _, k := range window[1:] {
+		highest = math.Max(highest, k.High)
+		lowest = math.Min(lowest, k.Low)
+	}
+
+	switch mode {
+	case TrailingChandelier:
+		atr := market.ATR(klines, lookback)
+		if atr <= 0 {
+			return 0
+		}
+		if side == "long" {
+			return highest - multiplier*atr
+		}
+		return lowest + multiplier*atr
+	case TrailingSwing:
+		if side == "long" {
+			return lowest
+		}
+		return highest
+	}
+	return 0
+}
+
+// updateTrailingStops 每个周期按最新收盘K线收紧持仓的交易所止损单（只向有利方向移动，不放宽）
+// 只处理本策略已知止损价的持仓，避免与未知来源的止损单重复
+func (at *AutoTrader) updateTrailingStops(positions []decision.PositionInfo) []string {
+	mode := at.config.TrailingStopMode
+	if mode == "" || len(positions) == 0 {
+		return nil
+	}
+	interval, lookback, multiplier := at.trailingParams()
+
+	var messages []string
+	for _, pos := range positions {
+		posKey := pos.Symbol + "_" + pos.Side
+		tracked, ok := at.trackedPositions[posKey]
+		if !ok || tracked.stopLoss <= 0 || tracked.closing || pos.Quantity <= 0 {
+			continue
+		}
+
+		klines, err := market.GetKlines(pos.Symbol, interval, lookback*3+2)
+		if err != nil {
+			log.Printf("  ⚠ %s 获取%s K线失败，跳过跟踪止损: %v", pos.Symbol, interval, err)
+			continue
+		}
+		// 只使用已收盘的K线
+		if n := len(klines); n > 0 && klines[n-1].CloseTime > time.Now().UnixMilli() {
+			klines = klines[:n-1]
+		}
+		level := at.roundPrice(pos.Symbol, trailingStopLevel(mode, pos.Side, klines, lookback, multiplier))
+		if level <= 0 {
+			continue
+		}
+
+		tighter := (pos.Side == "long" && level > tracked.stopLoss && level < pos.MarkPrice) ||
+			(pos.Side == "short" && level < tracked.stopLoss && level > pos.MarkPrice)
+		if !tighter {
+			continue
+		}
+
+		if at.config.DryRun {
+			messages = append(messages, fmt.Sprintf("🧪 [预演] %s %s 跟踪止损(%s) %.4f → %.4f，未改单",
+				pos.Symbol, sideName(pos.Side), mode, tracked.stopLoss, level))
+			continue
+		}
+		if err := at.moveStopLoss(pos.Symbol, pos.Side, level); err != nil {
+			log.Printf("  ⚠ %s 跟踪止损更新失败: %v", pos.Symbol, err)
+			messages = append(messages, fmt.Sprintf("⚠ %s %s 跟踪止损更新失败: %v", pos.Symbol, sideName(pos.Side), err))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("📈 %s %s 跟踪止损(%s) 收紧至 %.4f", pos.Symbol, sideName(pos.Side), mode, level))
+	}
+	return messages
+}
+
+// moveStopLoss 将持仓的止损移动到新价格
+// 交易器只支持按币种撤销全部委托，因此撤单后会按跟踪记录重新挂出该币种所有持仓的止盈止损
+func (at *AutoTrader) moveStopLoss(symbol, side string, stopLoss float64) error {
+	unlock := at.lockSymbol(symbol)
+	defer unlock()
+
+	posKey := symbol + "_" + side
+	previous := at.trackedPositions[posKey].stopLoss
+	if err := at.trader.CancelAllOrders(symbol); err != nil {
+		return fmt.Errorf("撤销原止损单失败: %w", err)
+	}
+	at.trackedPositions[posKey].stopLoss = stopLoss
+
+	var firstErr error
+	for _, s := range []string{"long", "short"} {
+		tracked, ok := at.trackedPositions[symbol+"_"+s]
+		if !ok || tracked.quantity <= 0 || tracked.closing {
+			continue
+		}
+		positionSide := strings.ToUpper(s)
+		if tracked.stopLoss > 0 {
+			if err := at.setStopLoss(symbol, s, tracked.quantity, tracked.stopLoss); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("挂出%s止损失败: %w", sideName(s), err)
+			}
+		}
+		if tracked.takeProfit > 0 {
+			if err := at.trader.SetTakeProfit(symbol, positionSide, tracked.quantity, tracked.takeProfit); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("挂出%s止盈失败: %w", sideName(s), err)
+			}
+		}
+	}
+
+	at.publishEvent(events.Event{
+		Type:    events.StopLossMoved,
+		Symbol:  symbol,
+		Side:    side,
+		Price:   stopLoss,
+		Message: fmt.Sprintf("跟踪止损 %.4f → %.4f", previous, stopLoss),
+	})
+	return firstErr
+}
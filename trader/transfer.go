@@ -0,0 +1,31 @@
+package trader
+
+import "fmt"
+
+// 钱包类型
+const (
+	WalletSpot    = "spot"
+	WalletFutures = "futures"
+)
+
+// MarginTransferer 支持现货与合约钱包之间内部划转的交易器（可选接口）
+type MarginTransferer interface {
+	// TransferMargin 在钱包之间划转资金（from/to 取值 WalletSpot 或 WalletFutures）
+	TransferMargin(from, to, currency string, amount float64) error
+}
+
+// validateTransfer 校验划转参数
+func validateTransfer(from, to string, amount float64) error {
+	if amount <= 0 {
+		return fmt.Errorf("划转金额必须大于0")
+	}
+	if from == to {
+		return fmt.Errorf("划转的源钱包和目标钱包不能相同: %s", from)
+	}
+	for _, wallet := range []string{from, to} {
+		if wallet != WalletSpot && wallet != WalletFutures {
+			return fmt.Errorf("不支持的钱包类型: %s", wallet)
+		}
+	}
+	return nil
+}
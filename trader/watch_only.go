@@ -0,0 +1,16 @@
+package trader
+
+import "fmt"
+
+// IsWatchOnly 是否为观察模式（只跟踪余额/持仓/盈亏，不做决策也不下单）
+func (at *AutoTrader) IsWatchOnly() bool {
+	return at.config.WatchOnly
+}
+
+// checkWritable 观察模式下拒绝所有下单、撤单及划转操作
+func (at *AutoTrader) checkWritable() error {
+	if at.config.WatchOnly {
+		return fmt.Errorf("交易员 %s 为观察模式（只读API Key），不允许下单或划转", at.name)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"nofx/market"
+	"strings"
+)
+
+// WhatIfOrder 假设订单（只模拟不下单）
+type WhatIfOrder struct {
+	Symbol          string  `json:"symbol"`
+	Side            string  `json:"side"` // long / short
+	PositionSizeUSD float64 `json:"position_size_usd"`
+	Leverage        int     `json:"leverage"`
+	Price           float64 `json:"price,omitempty"`           // 假设成交价，0=当前价
+	StopLoss        float64 `json:"stop_loss,omitempty"`       // 可选，提供后校验止损/强平关系
+	TakeProfit      float64 `json:"take_profit,omitempty"`     // 可选，提供后校验成本/收益
+	StopLossPct     float64 `json:"stop_loss_pct,omitempty"`   // 可选，止损距成交价的百分比
+	StopLossATR     float64 `json:"stop_loss_atr,omitempty"`   // 可选，止损距离为ATR14(1h)的倍数
+	TakeProfitPct   float64 `json:"take_profit_pct,omitempty"` // 可选，止盈距成交价的百分比
+	TakeProfitR     float64 `json:"take_profit_r,omitempty"`   // 可选，止盈为止损距离的R倍
+}
+
+// WhatIfCheck 单项风控检查结果
+type WhatIfCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// WhatIfResult 模拟结果
+type WhatIfResult struct {
+	Symbol           string      `json:"symbol"`
+	Side             string      `json:"side"`
+	Price            float64     `json:"price"`
+	Quantity         float64     `json:"quantity"`
+	Notional         float64     `json:"notional"`
+	Leverage         int         `json:"leverage"`
+	Margin           float64     `json:"margin"`
+	LiquidationPrice float64     `json:"liquidation_price"`       // 逐仓估算（全仓实际强平价取决于账户整体，通常更远）
+	CrossLiqPrice    float64     `json:"cross_liquidation_price"` // 全仓估算（以可用余额承担亏损）
+	Fees             FeeEstimate `json:"fees"`
+	StopLoss         float64     `json:"stop_loss,omitempty"`   // 换算并取整后的止损价
+	TakeProfit       float64     `json:"take_profit,omitempty"` // 换算并取整后的止盈价
+
+	Equity              float64 `json:"equity"`
+	AvailableBalance    float64 `json:"available_balance"`
+	MarginUsedBefore    float64 `json:"margin_used_before"`
+	MarginUsedAfter     float64 `json:"margin_used_after"`
+	MarginUsedPctBefore float64 `json:"margin_used_pct_before"`
+	MarginUsedPctAfter  float64 `json:"margin_used_pct_after"`
+
+	Checks  []WhatIfCheck `json:"checks"`
+	Allowed bool          `json:"allowed"` // 全部检查通过，实际下单时不会被风控拒绝
+}
+
+// SimulateOrder 模拟假设订单：预估保证金占用、强平价、手续费及各项风控限制，不下单也不改变任何状态
+// 与决策周期互斥（风控检查读取的持仓跟踪等状态由周期更新）
+func (at *AutoTrader) SimulateOrder(order WhatIfOrder) (*WhatIfResult, error) {
+	order.Symbol = market.Normalize(order.Symbol)
+	order.Side = strings.ToLower(order.Side)
+	if order.Side != "long" && order.Side != "short" {
+		return nil, fmt.Errorf("side 必须为 long 或 short: %s", order.Side)
+	}
+	if order.PositionSizeUSD <= 0 || order.Leverage <= 0 {
+		return nil, fmt.Errorf("仓位大小和杠杆必须大于0")
+	}
+
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+
+	marketData, err := market.Get(order.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 行情失败: %w", order.Symbol, err)
+	}
+	if order.Price > 0 {
+		data := *marketData
+		data.CurrentPrice = order.Price
+		marketData = &data
+	}
+	price := marketData.CurrentPrice
+	if price <= 0 {
+		return nil, fmt.Errorf("%s 价格无效: %.4f", order.Symbol, price)
+	}
+
+	account, err := at.GetAccountInfo()
+	if err != nil {
+		return nil, err
+	}
+	positions, err := at.getPositions()
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	quantity := order.PositionSizeUSD / price
+	margin := order.PositionSizeUSD / float64(order.Leverage)
+	result := &WhatIfResult{
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Price:    price,
+		Quantity: quantity,
+		Notional: order.PositionSizeUSD,
+		Leverage: order.Leverage,
+		Margin:   margin,
+	}
+	result.Equity, _ = account["total_equity"].(float64)
+	result.AvailableBalance, _ = account["available_balance"].(float64)
+	result.MarginUsedBefore, _ = account["margin_used"].(float64)
+	result.MarginUsedPctBefore, _ = account["margin_used_pct"].(float64)
+	result.MarginUsedAfter = result.MarginUsedBefore + margin
+	if result.Equity > 0 {
+		result.MarginUsedPctAfter = result.MarginUsedAfter / result.Equity * 100
+	}
+
+	result.LiquidationPrice = EstimateLiquidationPrice(at.exchange, order.Symbol, order.Side, price, quantity, margin)
+	result.CrossLiqPrice = EstimateLiquidationPrice(at.exchange, order.Symbol, order.Side, price, quantity, result.AvailableBalance)
+	if fees, err := EstimateFees(FeeOrder{
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Quantity:    quantity,
+		Price:       price,
+		FundingRate: marketData.FundingRate,
+	}, at.getFeeSchedule(order.Symbol)); err == nil {
+		result.Fees = *fees
+	}
+
+	d := &decision.Decision{
+		Symbol:          order.Symbol,
+		Action:          "open_" + order.Side,
+		Leverage:        order.Leverage,
+		PositionSizeUSD: order.PositionSizeUSD,
+		StopLoss:        order.StopLoss,
+		TakeProfit:      order.TakeProfit,
+		StopLossPct:     order.StopLossPct,
+		StopLossATR:     order.StopLossATR,
+		TakeProfitPct:   order.TakeProfitPct,
+		TakeProfitR:     order.TakeProfitR,
+	}
+	if d.HasRelativeLevels() {
+		if err := d.ResolveLevels(price); err != nil {
+			return nil, err
+		}
+		d.StopLoss = at.roundPrice(order.Symbol, d.StopLoss)
+		d.TakeProfit = at.roundPrice(order.Symbol, d.TakeProfit)
+		order.StopLoss, order.TakeProfit = d.StopLoss, d.TakeProfit
+	}
+	result.StopLoss, result.TakeProfit = order.StopLoss, order.TakeProfit
+	hasBracket := order.StopLoss > 0 && order.TakeProfit > 0
+
+	add := func(name string, err error) {
+		check := WhatIfCheck{Name: name, Passed: err == nil}
+		if err != nil {
+			check.Message = err.Error()
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	skip := func(name, reason string) {
+		result.Checks = append(result.Checks, WhatIfCheck{Name: name, Passed: true, Skipped: true, Message: reason})
+	}
+
+	add("existing_position", func() error {
+		for _, pos := range positions {
+			if pos["symbol"] == order.Symbol && pos["side"] == order.Side {
+				return fmt.Errorf("%s 已有%s仓，实际下单会被拒绝", order.Symbol, sideName(order.Side))
+			}
+		}
+		return nil
+	}())
+	add("risk_pause", func() error {
+		if until := at.PausedUntil(); !until.IsZero() {
+			return fmt.Errorf("风控暂停中（至 %s）", until.Format("15:04:05"))
+		}
+		return nil
+	}())
+	if hasBracket {
+		add("decision_rules", decision.ValidateDecision(d, result.Equity, at.config.BTCETHLeverage, at.config.AltcoinLeverage))
+	} else {
+		add("decision_rules", at.checkLeverageLimit(order.Symbol, order.Leverage))
+	}
+	add("available_margin", func() error {
+		if margin > result.AvailableBalance {
+			return fmt.Errorf("需占用保证金 %.2f USDT，可用余额仅 %.2f USDT", margin, result.AvailableBalance)
+		}
+		return nil
+	}())
+	add("symbol_filter", at.checkSymbolFilter(order.Symbol))
+	add("trade_throttle", at.checkTradeThrottle(order.Symbol))
+	add("cooldown", at.checkCooldown(order.Symbol))
+	add("blackout", at.checkBlackout(order.Symbol))
+	add("maintenance", at.checkMaintenance(order.Symbol))
+	add("delisting", at.checkDelisting(order.Symbol))
+	add("funding_window", at.checkFundingWindow(order.Symbol, "开仓"))
+	if order.TakeProfit > 0 {
+		add("expected_cost", at.checkExpectedCost(d, order.Side, quantity, marketData))
+	} else {
+		skip("expected_cost", "未提供止盈价")
+	}
+	if order.StopLoss > 0 {
+		add("stop_before_liquidation", at.checkStopBeforeLiquidation(d, order.Side, price, quantity))
+	} else {
+		skip("stop_before_liquidation", "未提供止损价")
+	}
+	add("stop_against_mark", at.checkStopAgainstMark(d, order.Side, price))
+	add("allocation", at.checkAllocation(order.Symbol, order.Side, margin))
+	add("group_exposure", at.checkGroupExposure(order.Symbol, order.Side, order.PositionSizeUSD, positions))
+	add("symbol_notional", at.checkSymbolNotional(order.Symbol, order.PositionSizeUSD, positions))
+
+	result.Allowed = true
+	for _, check := range result.Checks {
+		if !check.Passed {
+			result.Allowed = false
+		}
+	}
+	return result, nil
+}
+
+// checkLeverageLimit 检查杠杆是否超过配置上限（BTC/ETH与山寨币分别配置）
+func (at *AutoTrader) checkLeverageLimit(symbol string, leverage int) error {
+	maxLeverage := at.config.AltcoinLeverage
+	if symbol == "BTCUSDT" || symbol == "ETHUSDT" {
+		maxLeverage = at.config.BTCETHLeverage
+	}
+	if leverage > maxLeverage {
+		return fmt.Errorf("杠杆必须在1-%d之间（%s，当前配置上限%d倍）: %d", maxLeverage, symbol, maxLeverage, leverage)
+	}
+	return nil
+}
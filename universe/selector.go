@@ -0,0 +1,232 @@
+package universe
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/market"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config 动态选币配置
+type Config struct {
+	TraderID         string   `json:"trader_id"`
+	Size             int      `json:"size"`              // 选出的币种数量（默认10）
+	MinQuoteVolume   float64  `json:"min_quote_volume"`  // 24小时最低成交额（USDT，默认5000万）
+	MaxSpreadBps     float64  `json:"max_spread_bps"`    // 最大买卖价差（基点，默认10）
+	VolumeWeight     float64  `json:"volume_weight"`     // 成交额权重（默认1）
+	VolatilityWeight float64  `json:"volatility_weight"` // 波动率权重（默认1）
+	SpreadWeight     float64  `json:"spread_weight"`     // 价差权重（默认1，价差越小得分越高）
+	RefreshMinutes   int      `json:"refresh_minutes"`   // 重新选币间隔（默认60分钟）
+	Include          []string `json:"include"`           // 始终包含的币种（不占用 size 名额）
+	Exclude          []string `json:"exclude"`           // 排除的币种
+}
+
+// ParseConfigs 解析动态选币配置（JSON数组）
+func ParseConfigs(s string) ([]Config, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var configs []Config
+	if err := json.Unmarshal([]byte(s), &configs); err != nil {
+		return nil, fmt.Errorf("解析动态选币配置失败: %w", err)
+	}
+	for i := range configs {
+		c := &configs[i]
+		if c.TraderID == "" {
+			return nil, fmt.Errorf("动态选币配置 #%d 缺少 trader_id", i+1)
+		}
+		if c.Size <= 0 {
+			c.Size = 10
+		}
+		if c.MinQuoteVolume <= 0 {
+			c.MinQuoteVolume = 50_000_000
+		}
+		if c.MaxSpreadBps <= 0 {
+			c.MaxSpreadBps = 10
+		}
+		if c.VolumeWeight == 0 && c.VolatilityWeight == 0 && c.SpreadWeight == 0 {
+			c.VolumeWeight, c.VolatilityWeight, c.SpreadWeight = 1, 1, 1
+		}
+		if c.RefreshMinutes <= 0 {
+			c.RefreshMinutes = 60
+		}
+		for j, s := range c.Include {
+			c.Include[j] = market.Normalize(s)
+		}
+		for j, s := range c.Exclude {
+			c.Exclude[j] = market.Normalize(s)
+		}
+	}
+	return configs, nil
+}
+
+// Score 单个合约的选币得分
+type Score struct {
+	Symbol        string  `json:"symbol"`
+	QuoteVolume   float64 `json:"quote_volume"`
+	VolatilityPct float64 `json:"volatility_pct"` // (24h最高-最低)/最新价
+	SpreadBps     float64 `json:"spread_bps"`
+	Score         float64 `json:"score"`
+}
+
+// Rank 按成交额、波动率和价差为合约打分（各指标取排名百分位后加权），返回满足门槛的合约（得分从高到低）
+func Rank(cfg Config, tickers []market.Ticker24h, books map[string]market.BookTicker) []Score {
+	excluded := make(map[string]bool, len(cfg.Exclude))
+	for _, s := range cfg.Exclude {
+		excluded[s] = true
+	}
+
+	var scores []Score
+	for _, t := range tickers {
+		if !strings.HasSuffix(t.Symbol, "USDT") || excluded[t.Symbol] || t.LastPrice <= 0 || t.QuoteVolume < cfg.MinQuoteVolume {
+			continue
+		}
+		book, ok := books[t.Symbol]
+		if !ok || book.BidPrice <= 0 {
+			continue
+		}
+		spread := book.SpreadBps()
+		if spread > cfg.MaxSpreadBps {
+			continue
+		}
+		scores = append(scores, Score{
+			Symbol:        t.Symbol,
+			QuoteVolume:   t.QuoteVolume,
+			VolatilityPct: (t.HighPrice - t.LowPrice) / t.LastPrice * 100,
+			SpreadBps:     spread,
+		})
+	}
+
+	volume := percentiles(scores, func(s Score) float64 { return s.QuoteVolume })
+	volatility := percentiles(scores, func(s Score) float64 { return s.VolatilityPct })
+	spread := percentiles(scores, func(s Score) float64 { return -s.SpreadBps })
+	for i := range scores {
+		scores[i].Score = cfg.VolumeWeight*volume[i] + cfg.VolatilityWeight*volatility[i] + cfg.SpreadWeight*spread[i]
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores
+}
+
+// percentiles 各合约在指定指标上的排名百分位（0~1，越大越好）
+func percentiles(scores []Score, value func(Score) float64) []float64 {
+	result := make([]float64, len(scores))
+	if len(scores) < 2 {
+		for i := range result {
+			result[i] = 1
+		}
+		return result
+	}
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return value(scores[order[a]]) < value(scores[order[b]]) })
+	for rank, idx := range order {
+		result[idx] = float64(rank) / float64(len(scores)-1)
+	}
+	return result
+}
+
+// Select 按得分选出币种（Include 中的币种始终保留在最前面）
+func Select(cfg Config, ranked []Score) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, s := range cfg.Include {
+		if !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+	picked := 0
+	for _, s := range ranked {
+		if picked >= cfg.Size {
+			break
+		}
+		if seen[s.Symbol] {
+			continue
+		}
+		seen[s.Symbol] = true
+		symbols = append(symbols, s.Symbol)
+		picked++
+	}
+	return symbols
+}
+
+// Target 接收选币结果的交易员
+type Target interface {
+	GetName() string
+	SetTradingCoins(coins []string)
+}
+
+// Selector 定期为交易员重新选币
+type Selector struct {
+	config Config
+	target Target
+
+	symbols []string // 最近一次选出的币种
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSelector 创建动态选币器
+func NewSelector(config Config, target Target) *Selector {
+	return &Selector{config: config, target: target, stop: make(chan struct{})}
+}
+
+// Start 立即选币一次并启动定期刷新
+func (s *Selector) Start() {
+	interval := time.Duration(s.config.RefreshMinutes) * time.Minute
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.Refresh(); err != nil {
+				log.Printf("⚠️  [动态选币 %s] %v，沿用当前币种", s.target.GetName(), err)
+			}
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("🧭 动态选币已启动: %s 选出 %d 个币种（每 %s 刷新一次）", s.target.GetName(), s.config.Size, interval)
+}
+
+// Stop 停止刷新（保留最后一次选币结果）
+func (s *Selector) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Refresh 获取最新行情并更新交易员的交易币种
+func (s *Selector) Refresh() error {
+	tickers, err := market.GetTickers24h()
+	if err != nil {
+		return err
+	}
+	books, err := market.GetBookTickers()
+	if err != nil {
+		return err
+	}
+	ranked := Rank(s.config, tickers, books)
+	symbols := Select(s.config, ranked)
+	if len(symbols) == 0 {
+		return fmt.Errorf("没有满足条件的币种（成交额 ≥%.0f，价差 ≤%.1fbps）", s.config.MinQuoteVolume, s.config.MaxSpreadBps)
+	}
+
+	changed := strings.Join(symbols, ",") != strings.Join(s.symbols, ",")
+	s.symbols = symbols
+	if changed {
+		s.target.SetTradingCoins(symbols)
+		log.Printf("🧭 [动态选币 %s] 交易币种更新为 %v", s.target.GetName(), symbols)
+	}
+	return nil
+}